@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/manabie-com/togo/internal/config"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is a subcommand of togo such as togo migrate up. setup adds the flags of the command to fs and
+// returns what runs it once they are parsed, with the arguments left after them
+type command struct {
+	name    string
+	args    string
+	summary string
+	setup   func(fs *flag.FlagSet) func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+// commands are the subcommands of togo, every one takes the settings as flags too, see internal/config
+var commands = []*command{
+	{
+		name:    "serve",
+		summary: "serve the API until it's interrupted, this is what togo does without a command",
+		setup: func(fs *flag.FlagSet) func(context.Context, *config.Config, []string) error {
+			seed := fs.String("seed", "", "seed fixtures on start, \"default\" for demo data or path of a JSON fixtures file, for development only")
+			migrate := fs.String("migrate", "", "deprecated, use togo migrate")
+			return func(ctx context.Context, cfg *config.Config, args []string) error {
+				if *migrate != "" {
+					return migrateCmd(ctx, cfg, []string{*migrate})
+				}
+				return serve(cfg, *seed)
+			}
+		},
+	},
+	{
+		name:    "migrate",
+		args:    "up|down|status",
+		summary: "apply the pending Postgres migrations, roll the last one back or list them",
+		setup: func(fs *flag.FlagSet) func(context.Context, *config.Config, []string) error {
+			return migrateCmd
+		},
+	},
+	{
+		name:    "seed",
+		args:    "[default|FILE]",
+		summary: "seed the demo data or the fixtures of a JSON file, for development only",
+		setup: func(fs *flag.FlagSet) func(context.Context, *config.Config, []string) error {
+			return seedCmd
+		},
+	},
+	{
+		name:    "user create",
+		summary: "create a user, the password is read from stdin unless -password is given",
+		setup: func(fs *flag.FlagSet) func(context.Context, *config.Config, []string) error {
+			usr := &storages.User{}
+			fs.StringVar(&usr.Username, "username", "", "username of the user, required")
+			fs.IntVar(&usr.MaxTodo, "max-todo", storages.DefaultMaxTodo, "daily-limit of the user, 0 for none")
+			role := fs.String("role", string(storages.RoleUser), "role of the user, user or admin")
+			pwd := fs.String("password", "", "password of the user, beware it's kept in the shell history")
+			return func(ctx context.Context, cfg *config.Config, args []string) error {
+				usr.Role = storages.Role(*role)
+				return createUserCmd(ctx, cfg, usr, *pwd, os.Stdin)
+			}
+		},
+	},
+}
+
+// run runs the command of args, serve if args don't start with one so that togo -seed default still serves
+func run(args []string) error {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		printCommands(os.Stdout)
+		return nil
+	}
+	cmd, rest := findCommand(args)
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q, togo help lists them", args[0])
+	}
+
+	// Settings are read from the file of -config or CONFIG_FILE, the environment and flags, see internal/config
+	cfg := config.New(config.Vars)
+	own := flag.NewFlagSet("togo "+cmd.name, flag.ExitOnError)
+	exec := cmd.setup(own)
+	settings := flag.NewFlagSet("settings", flag.ExitOnError)
+	cfg.RegisterFlags(settings)
+	// usage tells the flags of the command apart from those of the settings which every command takes
+	fs := flag.NewFlagSet("togo "+cmd.name, flag.ContinueOnError)
+	for _, set := range []*flag.FlagSet{own, settings} {
+		set.VisitAll(func(f *flag.Flag) {
+			fs.Var(f.Value, f.Name, f.Usage)
+		})
+		set.SetOutput(fs.Output())
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: togo %s [flags] %s\n\n%s\n\n", cmd.name, cmd.args, cmd.summary)
+		if hasFlags(own) {
+			fmt.Fprintln(fs.Output(), "flags:")
+			own.PrintDefaults()
+			fmt.Fprintln(fs.Output())
+		}
+		fmt.Fprintln(fs.Output(), "settings:")
+		settings.PrintDefaults()
+	}
+	// -h prints the usage, unknown or invalid flags print it along with the error
+	switch err := fs.Parse(rest); err {
+	case nil:
+	case flag.ErrHelp:
+		return nil
+	default:
+		return err
+	}
+	if err := cfg.Load(); err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+	if cfg.Printing() {
+		return cfg.Print(os.Stdout)
+	}
+	return exec(context.Background(), cfg, fs.Args())
+}
+
+// findCommand returns the command args start with and the args after its name, serve for args which start
+// with a flag or are empty, nil for unknown commands
+func findCommand(args []string) (*command, []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return commands[0], args
+	}
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):]
+		}
+	}
+	return nil, nil
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	has := false
+	fs.VisitAll(func(*flag.Flag) { has = true })
+	return has
+}
+
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "usage: togo <command> [flags] [args]\n\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-28s %s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+	}
+	fmt.Fprintln(w, "\nrun togo <command> -h for the flags of a command")
+}
+
+// migrateCmd runs the migration command of args on the storage of cfg
+func migrateCmd(ctx context.Context, cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: togo migrate up|down|status")
+	}
+	dbConfig, err := storageConfig(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("error reading storage config: %v", err)
+	}
+	if err := runMigration(ctx, dbConfig, args[0]); err != nil {
+		return fmt.Errorf("migration failed: %v", err)
+	}
+	return nil
+}
+
+// seedCmd seeds the storage of cfg with the fixtures of args, the demo data when none is given
+func seedCmd(ctx context.Context, cfg *config.Config, args []string) error {
+	path := "default"
+	switch len(args) {
+	case 0:
+	case 1:
+		path = args[0]
+	default:
+		return fmt.Errorf("usage: togo seed [default|FILE]")
+	}
+	return withStore(ctx, cfg, func(db storages.Store) error {
+		if err := seedFixtures(ctx, db, path); err != nil {
+			return fmt.Errorf("seeding failed: %v", err)
+		}
+		return nil
+	})
+}
+
+// createUserCmd creates usr in the storage of cfg with pwd, the first line of stdin when pwd is empty. The
+// username and the password are checked like signups check them
+func createUserCmd(ctx context.Context, cfg *config.Config, usr *storages.User, pwd string, stdin io.Reader) error {
+	if err := usr.NormalizeUsername(); err != nil {
+		return fmt.Errorf("invalid -username: %v", err)
+	}
+	if !usr.Role.Valid() {
+		return fmt.Errorf("invalid -role %q, it's user or admin", usr.Role)
+	}
+	if usr.MaxTodo < 0 {
+		return fmt.Errorf("invalid -max-todo %d", usr.MaxTodo)
+	}
+	if pwd == "" {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("error reading password: %v", err)
+		}
+		pwd = strings.TrimRight(line, "\r\n")
+	}
+	if err := password.Validate(pwd); err != nil {
+		return err
+	}
+	pwdHash, err := password.Hash(pwd)
+	if err != nil {
+		return err
+	}
+	usr.PwdHash = pwdHash
+
+	return withStore(ctx, cfg, func(db storages.Store) error {
+		creator, ok := db.(storages.UserCreator)
+		if !ok {
+			return fmt.Errorf("storage does not support creating users")
+		}
+		switch err := creator.CreateUser(ctx, usr); err {
+		case nil:
+		case storages.ErrConflict:
+			return fmt.Errorf("username %s is taken", usr.Username)
+		default:
+			return fmt.Errorf("error creating user: %v", err)
+		}
+		fmt.Printf("created user %d %s\n", usr.Id, usr.Username)
+		return nil
+	})
+}
+
+// withStore calls f with the storage of cfg and closes it afterwards
+func withStore(ctx context.Context, cfg *config.Config, f func(db storages.Store) error) error {
+	dbConfig, err := storageConfig(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("error reading storage config: %v", err)
+	}
+	db, err := storages.Open(ctx, dbConfig)
+	if err != nil {
+		return fmt.Errorf("error opening db: %v", err)
+	}
+	err = f(db)
+	if closeErr := db.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	return err
+}
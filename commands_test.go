@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/manabie-com/togo/internal/config"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfig returns the settings of args, flags such as -storage-driver memory
+func testConfig(t *testing.T, args ...string) *config.Config {
+	cfg := config.New(config.Vars)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	require.NoError(t, fs.Parse(args))
+	require.NoError(t, cfg.Load())
+	return cfg
+}
+
+func TestFindCommand(t *testing.T) {
+	requireTest := require.New(t)
+
+	cmd, rest := findCommand(nil)
+	requireTest.Equal("serve", cmd.name)
+	requireTest.Empty(rest)
+
+	// flags without a command are those of serve
+	cmd, rest = findCommand([]string{"-seed", "default"})
+	requireTest.Equal("serve", cmd.name)
+	requireTest.Equal([]string{"-seed", "default"}, rest)
+
+	cmd, rest = findCommand([]string{"migrate", "up"})
+	requireTest.Equal("migrate", cmd.name)
+	requireTest.Equal([]string{"up"}, rest)
+
+	cmd, rest = findCommand([]string{"user", "create", "-username", "bob"})
+	requireTest.Equal("user create", cmd.name)
+	requireTest.Equal([]string{"-username", "bob"}, rest)
+
+	cmd, _ = findCommand([]string{"user"})
+	requireTest.Nil(cmd)
+	cmd, _ = findCommand([]string{"deploy"})
+	requireTest.Nil(cmd)
+}
+
+func TestRun(t *testing.T) {
+	requireTest := require.New(t)
+
+	err := run([]string{"deploy"})
+	requireTest.Error(err)
+	requireTest.Contains(err.Error(), `unknown command "deploy"`)
+
+	requireTest.Error(run([]string{"seed", "-no-such-flag"}))
+	requireTest.Error(run([]string{"user", "create", "-max-todo", "many"}))
+	requireTest.NoError(run([]string{"migrate", "-h"}), "-h only prints the usage")
+
+	err = run([]string{"user", "create", "-storage-driver", "memory", "-password", "secret123"})
+	requireTest.Error(err)
+	requireTest.Contains(err.Error(), "invalid -username")
+
+	err = run([]string{"seed", "-storage-driver", "memory", "one", "two"})
+	requireTest.Error(err)
+	requireTest.Contains(err.Error(), "usage: togo seed")
+}
+
+func TestCreateUserCmd(t *testing.T) {
+	requireTest := require.New(t)
+	ctx := context.Background()
+	cfg := testConfig(t, "-storage-driver", "memory")
+
+	usr := &storages.User{Username: "  bob ", Role: storages.RoleUser, MaxTodo: 5}
+	requireTest.NoError(createUserCmd(ctx, cfg, usr, "", strings.NewReader("secret123\n")))
+	requireTest.Equal("bob", usr.Username)
+	requireTest.NotEmpty(usr.PwdHash, "the password is read from stdin")
+
+	for name, usr := range map[string]*storages.User{
+		"invalid -username":  {Username: "", Role: storages.RoleUser},
+		"invalid -role":      {Username: "bob", Role: "owner"},
+		"invalid -max-todo":  {Username: "bob", Role: storages.RoleUser, MaxTodo: -1},
+		"firstUser is taken": {Username: "firstUser", Role: storages.RoleUser},
+	} {
+		err := createUserCmd(ctx, cfg, usr, "secret123", strings.NewReader(""))
+		requireTest.Error(err, name)
+		requireTest.Contains(err.Error(), name)
+	}
+
+	requireTest.Error(createUserCmd(ctx, cfg, &storages.User{Username: "bob", Role: storages.RoleUser}, "", strings.NewReader("weak\n")))
+}
+
+func TestServeGivesUpConnecting(t *testing.T) {
+	requireTest := require.New(t)
+
+	// nothing listens on port 1 so connecting is retried until POSTGRES_CONNECT_WAIT is over, then serve fails
+	// and main exits with status 1
+	start := time.Now()
+	err := run([]string{"serve", "-storage-driver", "postgres", "-postgres-host", "127.0.0.1", "-postgres-port", "1",
+		"-postgres-connect-wait", "300ms"})
+	requireTest.Error(err)
+	requireTest.Contains(err.Error(), "error opening db")
+	requireTest.True(time.Since(start) >= 300*time.Millisecond, "retried until the wait is over")
+	requireTest.True(time.Since(start) < 5*time.Second, "gave up once the wait was over")
+}
@@ -3,10 +3,33 @@ module github.com/manabie-com/togo
 go 1.14
 
 require (
+	github.com/99designs/gqlgen v0.13.0
+	github.com/BurntSushi/toml v0.3.1
+	github.com/alicebob/miniredis/v2 v2.14.3
+	github.com/aws/aws-sdk-go v1.37.0
+	github.com/coreos/go-oidc v2.2.1+incompatible
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
-	github.com/google/uuid v1.1.1
+	github.com/go-sql-driver/mysql v1.5.0
+	github.com/golang/protobuf v1.4.3
+	github.com/gomodule/redigo v1.8.3
+	github.com/jackc/pgconn v1.8.0
 	github.com/jackc/pgx/v4 v4.10.1
 	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/pkg/errors v0.9.1
-	github.com/stretchr/testify v1.5.1
+	github.com/pquerna/cachecontrol v0.2.0 // indirect
+	github.com/prometheus/client_golang v1.9.0
+	github.com/stretchr/testify v1.6.1
+	github.com/vektah/gqlparser/v2 v2.1.0
+	go.mongodb.org/mongo-driver v1.4.6
+	go.opentelemetry.io/otel v0.16.0
+	go.opentelemetry.io/otel/exporters/otlp v0.16.0
+	go.opentelemetry.io/otel/sdk v0.16.0
+	go.uber.org/zap v1.16.0
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
+	golang.org/x/oauth2 v0.0.0-20200902213428-5d25da1a8d43
+	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
+	gopkg.in/square/go-jose.v2 v2.5.1 // indirect
+	gopkg.in/yaml.v2 v2.3.0
 )
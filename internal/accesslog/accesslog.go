@@ -0,0 +1,162 @@
+// Package accesslog writes a line for every request served, in the Common or Combined Log Format of web
+// servers or as JSON, apart from the logs of the service so that log pipelines can ingest them as they do
+// those of proxies
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Formats of lines
+const (
+	FormatCommon   = "common"
+	FormatCombined = "combined"
+	FormatJSON     = "json"
+)
+
+// clfTime is the layout of times of the Common Log Format
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+// Entry is a request served
+type Entry struct {
+	Time       time.Time
+	RemoteAddr string
+	// User is who the request was authenticated as, empty if it wasn't
+	User      string
+	Method    string
+	URI       string
+	Proto     string
+	Status    int
+	Size      int64
+	Referer   string
+	UserAgent string
+	Duration  time.Duration
+	RequestID string
+}
+
+// Logger writes entries as lines of its format
+type Logger struct {
+	format string
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New create new Logger instance writing lines of format to w, combined by default
+func New(w io.Writer, format string) (*Logger, error) {
+	switch format {
+	case "":
+		format = FormatCombined
+	case FormatCommon, FormatCombined, FormatJSON:
+	default:
+		return nil, fmt.Errorf("accesslog: unknown format %q", format)
+	}
+	return &Logger{format: format, w: w}, nil
+}
+
+// Log writes the line of entry
+func (l *Logger) Log(entry *Entry) error {
+	var line []byte
+	if l.format == FormatJSON {
+		var err error
+		if line, err = json.Marshal(newJSONEntry(entry)); err != nil {
+			return err
+		}
+	} else {
+		line = []byte(l.clf(entry))
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.w.Write(line)
+	return err
+}
+
+// clf returns entry in the Common Log Format, followed by the referer and user agent for the combined one
+func (l *Logger) clf(entry *Entry) string {
+	var b strings.Builder
+	b.WriteString(orDash(entry.RemoteAddr))
+	b.WriteString(" - ")
+	b.WriteString(orDash(escape(entry.User)))
+	b.WriteString(" [")
+	b.WriteString(entry.Time.Format(clfTime))
+	b.WriteString(`] "`)
+	b.WriteString(escape(entry.Method + " " + entry.URI + " " + entry.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(entry.Status))
+	b.WriteString(" ")
+	if entry.Size > 0 {
+		b.WriteString(strconv.FormatInt(entry.Size, 10))
+	} else {
+		b.WriteString("-")
+	}
+	if l.format == FormatCombined {
+		b.WriteString(` "`)
+		b.WriteString(orDash(escape(entry.Referer)))
+		b.WriteString(`" "`)
+		b.WriteString(orDash(escape(entry.UserAgent)))
+		b.WriteString(`"`)
+	}
+	return b.String()
+}
+
+type jsonEntry struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remote_addr"`
+	User       string  `json:"user,omitempty"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Status     int     `json:"status"`
+	Size       int64   `json:"size"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+	RequestID  string  `json:"request_id,omitempty"`
+}
+
+func newJSONEntry(entry *Entry) jsonEntry {
+	return jsonEntry{
+		Time:       entry.Time.Format(time.RFC3339Nano),
+		RemoteAddr: entry.RemoteAddr,
+		User:       entry.User,
+		Method:     entry.Method,
+		URI:        entry.URI,
+		Proto:      entry.Proto,
+		Status:     entry.Status,
+		Size:       entry.Size,
+		Referer:    entry.Referer,
+		UserAgent:  entry.UserAgent,
+		DurationMs: float64(entry.Duration) / float64(time.Millisecond),
+		RequestID:  entry.RequestID,
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// escape escapes quotes, backslashes and bytes which aren't printable ASCII as \xhh as web servers do, so that
+// clients can't forge lines or fields
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' || c < 0x20 || c > 0x7e {
+			fmt.Fprintf(&b, `\x%02x`, c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Time:       time.Date(2021, 3, 1, 8, 30, 0, 0, time.FixedZone("", 7*3600)),
+		RemoteAddr: "10.0.0.1",
+		User:       "7",
+		Method:     "GET",
+		URI:        "/v1/tasks",
+		Proto:      "HTTP/1.1",
+		Status:     200,
+		Size:       512,
+		Referer:    "https://togo.example/",
+		UserAgent:  `curl/7.68.0 "x"`,
+		Duration:   1500 * time.Microsecond,
+		RequestID:  "id",
+	}
+}
+
+func TestLogger(t *testing.T) {
+	requireTest := require.New(t)
+	var buf bytes.Buffer
+
+	l, err := New(&buf, FormatCommon)
+	requireTest.NoError(err)
+	requireTest.NoError(l.Log(testEntry()))
+	requireTest.Equal("10.0.0.1 - 7 [01/Mar/2021:08:30:00 +0700] \"GET /v1/tasks HTTP/1.1\" 200 512\n", buf.String())
+
+	// combined is the default, quotes of clients are escaped
+	buf.Reset()
+	l, err = New(&buf, "")
+	requireTest.NoError(err)
+	entry := testEntry()
+	entry.User, entry.Size, entry.Referer = "", 0, ""
+	requireTest.NoError(l.Log(entry))
+	requireTest.Equal("10.0.0.1 - - [01/Mar/2021:08:30:00 +0700] \"GET /v1/tasks HTTP/1.1\" 200 - \"-\" \"curl/7.68.0 \\x22x\\x22\"\n", buf.String())
+
+	buf.Reset()
+	l, err = New(&buf, FormatJSON)
+	requireTest.NoError(err)
+	requireTest.NoError(l.Log(testEntry()))
+	var line map[string]interface{}
+	requireTest.NoError(json.Unmarshal(buf.Bytes(), &line))
+	requireTest.Equal("2021-03-01T08:30:00+07:00", line["time"])
+	requireTest.Equal(float64(200), line["status"])
+	requireTest.Equal(1.5, line["duration_ms"])
+	requireTest.Equal("id", line["request_id"])
+
+	_, err = New(&buf, "apache")
+	requireTest.Error(err)
+}
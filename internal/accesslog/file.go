@@ -0,0 +1,141 @@
+package accesslog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// backupTime is the layout of the times rotated files are suffixed with, they sort as they were rotated
+const backupTime = "20060102T150405.000000"
+
+// FileConfig tells where lines are written to and when the file is rotated
+type FileConfig struct {
+	Path string
+	// MaxSize is how many bytes the file has at most before it's rotated, it's not rotated by size if it's zero
+	MaxSize int64
+	// Interval is how often the file is rotated, at multiples of it since the Unix epoch, e.g. at midnight UTC
+	// for 24h. It's not rotated by time if it's zero
+	Interval time.Duration
+	// MaxBackups is how many rotated files are kept, all are if it's zero
+	MaxBackups int
+}
+
+// File is a file written to by appending which is rotated by size and time: it's renamed with the time of the
+// rotation as suffix, path.20210301T000000.000000, and a new one is started at path
+type File struct {
+	config FileConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+}
+
+// OpenFile opens the file of config, appending to it if it exists
+func OpenFile(config FileConfig) (*File, error) {
+	f := &File{config: config, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return errors.Wrap(err, "OpenFile()")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return errors.Wrap(err, "Stat()")
+	}
+	f.file, f.size = file, info.Size()
+	if f.config.Interval > 0 {
+		f.rotateAt = f.now().Truncate(f.config.Interval).Add(f.config.Interval)
+	}
+	return nil
+}
+
+// Write appends p to the file, rotating it first if p would make it larger than the max size or if it's due
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	full := f.config.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.config.MaxSize
+	due := f.config.Interval > 0 && !f.now().Before(f.rotateAt)
+	if full || due {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the file and opens a new one, the oldest rotated files past the max backups are removed
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return errors.Wrap(err, "Close()")
+	}
+	f.file = nil
+	if err := os.Rename(f.config.Path, f.config.Path+"."+f.now().UTC().Format(backupTime)); err != nil {
+		return errors.Wrap(err, "Rename()")
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.removeBackups()
+}
+
+func (f *File) removeBackups() error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+	dir, name := filepath.Split(f.config.Path)
+	if dir == "" {
+		dir = "."
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return errors.Wrap(err, "ReadDir()")
+	}
+	var backups []string
+	for _, info := range infos {
+		if !info.IsDir() && strings.HasPrefix(info.Name(), name+".") {
+			backups = append(backups, info.Name())
+		}
+	}
+	sort.Strings(backups)
+	for i := 0; i < len(backups)-f.config.MaxBackups; i++ {
+		if err := os.Remove(filepath.Join(dir, backups[i])); err != nil {
+			return errors.Wrap(err, "Remove()")
+		}
+	}
+	return nil
+}
+
+// Close closes the file, it's not written to anymore
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
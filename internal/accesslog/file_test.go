@@ -0,0 +1,57 @@
+package accesslog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRotation(t *testing.T) {
+	requireTest := require.New(t)
+	dir, err := ioutil.TempDir("", "accesslog")
+	requireTest.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "access.log")
+
+	now := time.Date(2021, 3, 1, 23, 0, 0, 0, time.UTC)
+	f := &File{config: FileConfig{Path: path, MaxSize: 10, Interval: 24 * time.Hour, MaxBackups: 2}, now: func() time.Time { return now }}
+	requireTest.NoError(f.open())
+	defer f.Close()
+
+	// lines which would make the file larger than the max size go to a new one
+	_, err = f.Write([]byte("12345678\n"))
+	requireTest.NoError(err)
+	now = now.Add(time.Second)
+	_, err = f.Write([]byte("abc\n"))
+	requireTest.NoError(err)
+	backups, err := filepath.Glob(path + ".*")
+	requireTest.NoError(err)
+	requireTest.Equal([]string{path + ".20210301T230001.000000"}, backups)
+
+	// so do lines written past the interval
+	now = time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)
+	_, err = f.Write([]byte("d\n"))
+	requireTest.NoError(err)
+	content, err := ioutil.ReadFile(path)
+	requireTest.NoError(err)
+	requireTest.Equal("d\n", string(content))
+	content, err = ioutil.ReadFile(path + ".20210302T000000.000000")
+	requireTest.NoError(err)
+	requireTest.Equal("abc\n", string(content))
+
+	// only the latest backups are kept
+	now = now.Add(time.Minute)
+	_, err = f.Write([]byte("123456789\n"))
+	requireTest.NoError(err)
+	backups, err = filepath.Glob(path + ".*")
+	requireTest.NoError(err)
+	requireTest.Equal([]string{path + ".20210302T000000.000000", path + ".20210302T000100.000000"}, backups)
+
+	requireTest.NoError(f.Close())
+	_, err = f.Write([]byte("e\n"))
+	requireTest.Error(err)
+}
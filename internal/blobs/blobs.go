@@ -0,0 +1,78 @@
+package blobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned for missing blobs
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key, keys are slash separated paths
+type Store interface {
+	// Put saves everything read from r under key, an existing blob is replaced
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the blob, it returns ErrNotFound for missing blobs
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob, missing blobs are not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// Config selects a blob store backend, DSN format depends on the driver
+type Config struct {
+	Driver string
+	DSN    string
+}
+
+// OpenFunc opens a blob store from the given dsn, an empty dsn means driver's default
+type OpenFunc func(ctx context.Context, dsn string) (Store, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]OpenFunc)
+)
+
+// Register makes a blob store driver available by the provided name,
+// it's meant to be called from init() of the driver package
+func Register(name string, open OpenFunc) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if open == nil {
+		panic("blobs: Register open func is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("blobs: Register called twice for driver " + name)
+	}
+	drivers[name] = open
+}
+
+// Drivers returns a sorted list of the names of the registered drivers
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the blob store of the configured driver
+func Open(ctx context.Context, config *Config) (Store, error) {
+	driversMu.RLock()
+	open, ok := drivers[config.Driver]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("blobs: unknown driver %q (forgotten import?)", config.Driver)
+	}
+	return open(ctx, config.DSN)
+}
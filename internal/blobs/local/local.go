@@ -0,0 +1,100 @@
+package local
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	blobs.Register("local", func(_ context.Context, dsn string) (blobs.Store, error) {
+		if dsn == "" {
+			dsn = "./attachments"
+		}
+		return NewLocal(dsn)
+	})
+}
+
+// Local keeps blobs as files under a directory
+type Local struct {
+	dir string
+}
+
+// NewLocal create new Local instance, dir is created if it does not exist
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "MkdirAll()")
+	}
+	return &Local{dir: dir}, nil
+}
+
+// path maps key onto a file under dir, keys escaping dir are rejected
+func (l *Local) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + filepath.FromSlash(key))
+	if cleaned == string(filepath.Separator) || strings.Contains(key, "..") {
+		return "", errors.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(l.dir, cleaned), nil
+}
+
+// Put writes r into a temporary file which replaces the blob once it's complete
+func (l *Local) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return errors.Wrap(err, "MkdirAll()")
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(path), ".upload-")
+	if err != nil {
+		return errors.Wrap(err, "TempFile()")
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "Copy()")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "Close()")
+	}
+	return errors.Wrap(os.Rename(f.Name(), path), "Rename()")
+}
+
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, blobs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Open()")
+	}
+	return f, nil
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Remove()")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package local
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	requireTest := require.New(t)
+
+	dir, err := ioutil.TempDir("", "blobs")
+	requireTest.NoError(err)
+
+	l, err := NewLocal(dir)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	requireTest.NoError(l.Put(ctx, "tasks/1/a", strings.NewReader("hello")))
+
+	r, err := l.Get(ctx, "tasks/1/a")
+	requireTest.NoError(err)
+	content, err := ioutil.ReadAll(r)
+	requireTest.NoError(err)
+	requireTest.NoError(r.Close())
+	requireTest.Equal("hello", string(content))
+
+	requireTest.NoError(l.Delete(ctx, "tasks/1/a"))
+	requireTest.NoError(l.Delete(ctx, "tasks/1/a"))
+	_, err = l.Get(ctx, "tasks/1/a")
+	requireTest.Equal(blobs.ErrNotFound, err)
+
+	requireTest.Error(l.Put(ctx, "../escape", strings.NewReader("x")))
+}
@@ -0,0 +1,121 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	blobs.Register("s3", func(_ context.Context, dsn string) (blobs.Store, error) {
+		config, err := configFromURL(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3(config)
+	})
+}
+
+type Config struct {
+	Bucket string
+	Region string
+	// Endpoint overrides AWS endpoint, e.g. http://localhost:9000 for MinIO, paths are used instead of virtual hosts then
+	Endpoint string
+	// Prefix is prepended to every key
+	Prefix string
+}
+
+// configFromURL creates config from url in format
+// s3://bucket/prefix?region=ap-southeast-1&endpoint=http://localhost:9000
+func configFromURL(rawURL string) (*Config, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parse()")
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return nil, errors.Errorf("invalid s3 url %q", rawURL)
+	}
+
+	return &Config{
+		Bucket:   u.Host,
+		Region:   u.Query().Get("region"),
+		Endpoint: u.Query().Get("endpoint"),
+		Prefix:   strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// S3 keeps blobs as objects of a bucket, AWS credentials are taken from the environment
+type S3 struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+// NewS3 create new S3 instance
+func NewS3(config *Config) (*S3, error) {
+	awsConfig := aws.NewConfig().WithRegion(config.Region)
+	if config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.Endpoint).WithS3ForcePathStyle(true)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewSession()")
+	}
+
+	client := s3.New(sess)
+	return &S3{
+		client:   client,
+		uploader: s3manager.NewUploaderWithClient(client),
+		bucket:   config.Bucket,
+		prefix:   config.Prefix,
+	}, nil
+}
+
+func (s *S3) key(key string) *string {
+	return aws.String(path.Join(s.prefix, key))
+}
+
+// Put uploads r in parts so it needs not to be seekable
+func (s *S3) Put(ctx context.Context, key string, r io.Reader) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    s.key(key),
+		Body:   r,
+	})
+	return errors.Wrap(err, "UploadWithContext()")
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    s.key(key),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return nil, blobs.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "GetObjectWithContext()")
+	}
+	return out.Body, nil
+}
+
+// Delete removes the object, S3 does not fail for missing objects
+func (s *S3) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    s.key(key),
+	})
+	return errors.Wrap(err, "DeleteObjectWithContext()")
+}
@@ -0,0 +1,19 @@
+package s3
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConfigFromURL(t *testing.T) {
+	requireTest := require.New(t)
+
+	config, err := configFromURL("s3://togo/attachments/?region=us-east-1&endpoint=http://localhost:9000")
+	requireTest.NoError(err)
+	requireTest.Equal(&Config{Bucket: "togo", Region: "us-east-1", Endpoint: "http://localhost:9000", Prefix: "attachments"}, config)
+
+	_, err = configFromURL("file://togo")
+	requireTest.Error(err)
+	_, err = configFromURL("s3:///attachments")
+	requireTest.Error(err)
+}
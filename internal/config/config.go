@@ -0,0 +1,479 @@
+// Package config reads the settings of the service from a YAML or TOML file, the environment and flags, in
+// increasing precedence. Settings are named by their environment variables, such as POSTGRES_HOST, which is
+// postgres.host in files and -postgres-host as a flag. Load validates every setting at start so that a typo
+// fails the start rather than falling back to a default, the effective settings are printed without secrets.
+// Reload reads the file again, the settings marked Reloadable take effect without a restart
+package config
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manabie-com/togo/internal/secrets"
+)
+
+// Kind is the type the value of a setting is parsed as
+type Kind int
+
+// Kinds of settings
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+	KindDuration
+	// KindTime is an RFC 3339 time
+	KindTime
+	// KindList is a comma separated list
+	KindList
+)
+
+// Sources of values
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Var is a setting
+type Var struct {
+	// Name is the environment variable of the setting. A name starting with * matches every setting ending
+	// with the rest of it, such as *_CLIENT_ID for those of identity providers
+	Name    string
+	Kind    Kind
+	Default string
+	Usage   string
+	// Values are the values the setting may take, any if it's empty
+	Values []string
+	// Secret settings are read by secrets.Lookup, they have no flag and are never printed
+	Secret bool
+	// Required settings must be set, RequiredWith ones must be set along with the setting named by it
+	Required     bool
+	RequiredWith string
+	// Reloadable settings are applied again when the file changes, others only on restart
+	Reloadable bool
+}
+
+// Setting is the effective value of a setting and where it comes from
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// Config is the settings of vars read from a file, the environment and flags
+type Config struct {
+	vars   []Var
+	byName map[string]*Var
+	path   string
+	// mu guards file which Reload replaces, it's read from filePath
+	mu       sync.RWMutex
+	file     map[string]string
+	filePath string
+	flags    map[string]string
+	printing bool
+
+	lookupEnv func(key string) (string, bool)
+}
+
+// New create new Config instance of vars
+func New(vars []Var) *Config {
+	c := &Config{
+		vars:      vars,
+		byName:    make(map[string]*Var, len(vars)),
+		file:      make(map[string]string),
+		flags:     make(map[string]string),
+		lookupEnv: os.LookupEnv,
+	}
+	for i := range vars {
+		c.byName[vars[i].Name] = &vars[i]
+	}
+	return c
+}
+
+// RegisterFlags adds to fs -config, the path of the file of settings, -print-config and a flag of every setting
+// which isn't a secret
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.path, "config", "", "path of a YAML or TOML file of settings, CONFIG_FILE by default")
+	fs.BoolVar(&c.printing, "print-config", false, "print the effective settings without secrets and exit")
+	for _, v := range c.vars {
+		if v.Secret || strings.HasPrefix(v.Name, "*") {
+			continue
+		}
+		usage := v.Usage
+		if v.Default != "" {
+			usage += " (default " + v.Default + ")"
+		}
+		fs.Var(&varFlag{name: v.Name, flags: c.flags}, FlagName(v.Name), usage+", "+v.Name)
+	}
+}
+
+// FlagName returns the flag of the setting name, POSTGRES_HOST is -postgres-host
+func FlagName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// varFlag sets the value of a setting from the command line
+type varFlag struct {
+	name  string
+	flags map[string]string
+}
+
+func (f *varFlag) String() string {
+	if f.flags == nil {
+		return ""
+	}
+	return f.flags[f.name]
+}
+
+func (f *varFlag) Set(value string) error {
+	f.flags[f.name] = value
+	return nil
+}
+
+// Load reads the file of -config or of CONFIG_FILE, if either is set, and validates the settings
+func (c *Config) Load() error {
+	path := c.path
+	if path == "" {
+		path, _ = c.lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		file, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		c.file, c.filePath = file, path
+	}
+	return c.Validate()
+}
+
+// Reload reads the file of Load again and replaces the settings of the old one if the new ones are valid, they
+// are kept otherwise. It returns the names of the settings which changed, the Reloadable ones apart from those
+// which take effect on restart. Settings set by flags or the environment don't change
+func (c *Config) Reload() (reloaded, restart []string, err error) {
+	if c.filePath == "" {
+		return nil, nil, nil
+	}
+	file, err := readFile(c.filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	next := &Config{vars: c.vars, byName: c.byName, file: file, flags: c.flags, lookupEnv: c.lookupEnv}
+	if err := next.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	old := c.file
+	c.file = file
+	c.mu.Unlock()
+
+	for _, name := range changed(old, file) {
+		if _, source := c.lookup(name); source == SourceFlag || source == SourceEnv {
+			continue
+		}
+		if v := c.find(name); v != nil && v.Reloadable {
+			reloaded = append(reloaded, name)
+		} else {
+			restart = append(restart, name)
+		}
+	}
+	return reloaded, restart, nil
+}
+
+// changed returns the sorted names of the settings which are different in old and file
+func changed(old, file map[string]string) []string {
+	var names []string
+	for name, value := range file {
+		if oldValue, ok := old[name]; !ok || oldValue != value {
+			names = append(names, name)
+		}
+	}
+	for name := range old {
+		if _, ok := file[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Watch checks the file of Load every interval until ctx is done and sends on the returned channel once it
+// changed, its modification time or its size tell. It returns nil, which never receives, without a file
+func (c *Config) Watch(ctx context.Context, interval time.Duration) <-chan struct{} {
+	if c.filePath == "" || interval <= 0 {
+		return nil
+	}
+	changes := make(chan struct{}, 1)
+	last, _ := os.Stat(c.filePath)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(c.filePath)
+			if err != nil || (last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+				continue
+			}
+			last = info
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changes
+}
+
+// fileValue returns the value of setting name in the file
+func (c *Config) fileValue(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.file[name]
+	return value, ok
+}
+
+// Printing reports whether -print-config was given
+func (c *Config) Printing() bool {
+	return c.printing
+}
+
+// lookup returns the value of setting name and its source, flags take precedence over the environment which
+// takes precedence over the file
+func (c *Config) lookup(name string) (string, string) {
+	if value, ok := c.flags[name]; ok {
+		return value, SourceFlag
+	}
+	if value, ok := c.lookupEnv(name); ok {
+		return value, SourceEnv
+	}
+	if value, ok := c.fileValue(name); ok {
+		return value, SourceFile
+	}
+	if v := c.find(name); v != nil {
+		return v.Default, SourceDefault
+	}
+	return "", SourceDefault
+}
+
+// find returns the var of setting name, nil if none matches it
+func (c *Config) find(name string) *Var {
+	if v, ok := c.byName[name]; ok {
+		return v
+	}
+	for i := range c.vars {
+		if pattern := c.vars[i].Name; strings.HasPrefix(pattern, "*") && strings.HasSuffix(name, pattern[1:]) {
+			return &c.vars[i]
+		}
+	}
+	return nil
+}
+
+// isSet reports whether setting v is set, by a _FILE variable too for secrets
+func (c *Config) isSet(v *Var) bool {
+	if _, source := c.lookup(v.Name); source != SourceDefault {
+		return true
+	}
+	return v.Secret && c.secretInEnv(v.Name)
+}
+
+// secretInEnv reports whether secret name is set in the environment, by itself or by its _FILE variable
+func (c *Config) secretInEnv(name string) bool {
+	if _, ok := c.lookupEnv(name); ok {
+		return true
+	}
+	file, ok := c.lookupEnv(name + "_FILE")
+	return ok && file != ""
+}
+
+// Validate checks that every setting set is of the kind and among the values of its var, that required ones
+// are set and that the file has no unknown setting. It returns all the problems found at once
+func (c *Config) Validate() error {
+	var problems []string
+	c.mu.RLock()
+	for name := range c.file {
+		if c.find(name) == nil {
+			problems = append(problems, fmt.Sprintf("unknown setting %s in file", name))
+		}
+	}
+	c.mu.RUnlock()
+
+	for i := range c.vars {
+		v := &c.vars[i]
+		if strings.HasPrefix(v.Name, "*") {
+			continue
+		}
+		set := c.isSet(v)
+		if v.Required && !set {
+			problems = append(problems, v.Name+" is required")
+		}
+		if with := c.byName[v.RequiredWith]; with != nil && !set && c.isSet(with) {
+			problems = append(problems, v.Name+" is required with "+with.Name)
+		}
+		if v.Secret {
+			continue
+		}
+		if value, source := c.lookup(v.Name); source != SourceDefault {
+			if err := check(v, value); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid %s from %s: %v", v.Name, source, err))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("config: %s", strings.Join(problems, "; "))
+}
+
+// check reports whether value is of the kind and among the values of v, empty values are the default
+func check(v *Var, value string) error {
+	if value == "" {
+		return nil
+	}
+	var err error
+	switch v.Kind {
+	case KindInt:
+		_, err = strconv.Atoi(value)
+	case KindFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case KindBool:
+		_, err = strconv.ParseBool(value)
+	case KindDuration:
+		_, err = time.ParseDuration(value)
+	case KindTime:
+		_, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return err
+	}
+	if len(v.Values) == 0 {
+		return nil
+	}
+	for _, allowed := range v.Values {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not one of %s", value, strings.Join(v.Values, ", "))
+}
+
+// String returns the value of setting name, settings which aren't declared are read from the environment and
+// the file only
+func (c *Config) String(name string) string {
+	value, _ := c.lookup(name)
+	return value
+}
+
+// Int returns the value of setting name as int, the default if it's invalid which Validate reports
+func (c *Config) Int(name string) int {
+	i, err := strconv.Atoi(c.String(name))
+	if err != nil {
+		i, _ = strconv.Atoi(c.defaultOf(name))
+	}
+	return i
+}
+
+// Float returns the value of setting name as float64
+func (c *Config) Float(name string) float64 {
+	f, err := strconv.ParseFloat(c.String(name), 64)
+	if err != nil {
+		f, _ = strconv.ParseFloat(c.defaultOf(name), 64)
+	}
+	return f
+}
+
+// Bool returns the value of setting name as bool
+func (c *Config) Bool(name string) bool {
+	b, err := strconv.ParseBool(c.String(name))
+	if err != nil {
+		b, _ = strconv.ParseBool(c.defaultOf(name))
+	}
+	return b
+}
+
+// Duration returns the value of setting name as time.Duration
+func (c *Config) Duration(name string) time.Duration {
+	d, err := time.ParseDuration(c.String(name))
+	if err != nil {
+		d, _ = time.ParseDuration(c.defaultOf(name))
+	}
+	return d
+}
+
+// Time returns the value of setting name as time.Time, zero if it's empty
+func (c *Config) Time(name string) time.Time {
+	t, _ := time.Parse(time.RFC3339, c.String(name))
+	return t
+}
+
+// List returns the items of setting name, a comma separated list, empty for an empty list
+func (c *Config) List(name string) []string {
+	var items []string
+	for _, item := range strings.Split(c.String(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *Config) defaultOf(name string) string {
+	if v := c.find(name); v != nil {
+		return v.Default
+	}
+	return ""
+}
+
+// Secret returns the secret of setting name. The environment, KEY_FILE included, is read by secrets.Lookup,
+// values of the file are resolved by secrets.Resolve so they are best references such as vault:path#key
+func (c *Config) Secret(ctx context.Context, name string) (string, error) {
+	if value, ok := c.fileValue(name); ok && !c.secretInEnv(name) {
+		return secrets.Resolve(ctx, value)
+	}
+	return secrets.Lookup(ctx, name, c.defaultOf(name))
+}
+
+// Effective returns the settings of the vars in their order, secrets are only told to be set or not
+func (c *Config) Effective() []Setting {
+	settings := make([]Setting, 0, len(c.vars))
+	for i := range c.vars {
+		v := &c.vars[i]
+		if strings.HasPrefix(v.Name, "*") {
+			continue
+		}
+		value, source := c.lookup(v.Name)
+		if v.Secret {
+			value, source = "", SourceDefault
+			if c.secretInEnv(v.Name) {
+				value, source = "<secret>", SourceEnv
+			} else if _, ok := c.fileValue(v.Name); ok {
+				value, source = "<secret>", SourceFile
+			}
+		}
+		settings = append(settings, Setting{Name: v.Name, Value: value, Source: source})
+	}
+	return settings
+}
+
+// Print writes the effective settings to w, one NAME=value line each followed by its source
+func (c *Config) Print(w io.Writer) error {
+	for _, s := range c.Effective() {
+		if _, err := fmt.Fprintf(w, "%s=%s\t# %s\n", s.Name, s.Value, s.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
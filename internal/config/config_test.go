@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testVars = []Var{
+	{Name: "LOG_FORMAT", Default: "json", Values: []string{"json", "console"}},
+	{Name: "POSTGRES_HOST", Default: "localhost"},
+	{Name: "POSTGRES_MAX_CONNS", Kind: KindInt},
+	{Name: "POSTGRES_REPLICAS", Kind: KindList},
+	{Name: "POSTGRES_PASSWORD", Secret: true, Default: "togo"},
+	{Name: "SHUTDOWN_TIMEOUT", Kind: KindDuration, Default: "15s"},
+	{Name: "METRICS_ENABLED", Kind: KindBool, Default: "true"},
+	{Name: "GRPC_TLS_CERT", RequiredWith: "GRPC_TLS_KEY"},
+	{Name: "GRPC_TLS_KEY", RequiredWith: "GRPC_TLS_CERT"},
+	{Name: "RATE_LIMIT_ADDR", Reloadable: true},
+	{Name: "*_CLIENT_ID"},
+}
+
+// newTestConfig returns the config of testVars reading env instead of the environment
+func newTestConfig(env map[string]string) *Config {
+	c := New(append([]Var(nil), testVars...))
+	c.lookupEnv = func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+	return c
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	requireTest := require.New(t)
+
+	path := writeFile(t, "togo.yaml", `
+log_format: console
+postgres:
+  host: file-host
+  max_conns: 20
+  replicas: [r1, r2]
+metrics_enabled: false
+google:
+  client_id: abc
+`)
+	c := newTestConfig(map[string]string{"POSTGRES_HOST": "env-host", "SHUTDOWN_TIMEOUT": "30s"})
+	fs := flag.NewFlagSet("togo", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+	requireTest.NoError(fs.Parse([]string{"-config", path, "-postgres-host", "flag-host"}))
+	requireTest.NoError(c.Load())
+
+	requireTest.Equal("flag-host", c.String("POSTGRES_HOST"))
+	requireTest.Equal("console", c.String("LOG_FORMAT"))
+	requireTest.Equal(20, c.Int("POSTGRES_MAX_CONNS"))
+	requireTest.Equal([]string{"r1", "r2"}, c.List("POSTGRES_REPLICAS"))
+	requireTest.Equal(30*time.Second, c.Duration("SHUTDOWN_TIMEOUT"))
+	requireTest.False(c.Bool("METRICS_ENABLED"))
+	requireTest.Equal("abc", c.String("GOOGLE_CLIENT_ID"))
+	requireTest.Nil(fs.Lookup("postgres-password"), "secrets have no flag")
+
+	settings := c.Effective()
+	requireTest.Contains(settings, Setting{Name: "POSTGRES_HOST", Value: "flag-host", Source: SourceFlag})
+	requireTest.Contains(settings, Setting{Name: "SHUTDOWN_TIMEOUT", Value: "30s", Source: SourceEnv})
+	requireTest.Contains(settings, Setting{Name: "LOG_FORMAT", Value: "console", Source: SourceFile})
+	requireTest.Contains(settings, Setting{Name: "GRPC_TLS_CERT", Value: "", Source: SourceDefault})
+}
+
+func TestConfigTOML(t *testing.T) {
+	requireTest := require.New(t)
+
+	path := writeFile(t, "togo.toml", `
+shutdown_timeout = "1m"
+
+[postgres]
+host = "toml-host"
+max_conns = 5
+`)
+	c := newTestConfig(map[string]string{"CONFIG_FILE": path})
+	requireTest.NoError(c.Load())
+	requireTest.Equal("toml-host", c.String("POSTGRES_HOST"))
+	requireTest.Equal(5, c.Int("POSTGRES_MAX_CONNS"))
+	requireTest.Equal(time.Minute, c.Duration("SHUTDOWN_TIMEOUT"))
+	requireTest.True(c.Bool("METRICS_ENABLED"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	requireTest := require.New(t)
+
+	path := writeFile(t, "togo.yml", "postgres:\n  hots: typo\n")
+	c := newTestConfig(map[string]string{
+		"CONFIG_FILE":        path,
+		"LOG_FORMAT":         "xml",
+		"POSTGRES_MAX_CONNS": "many",
+		"SHUTDOWN_TIMEOUT":   "",
+		"GRPC_TLS_CERT":      "cert.pem",
+	})
+	err := c.Load()
+	requireTest.Error(err)
+	requireTest.Contains(err.Error(), "unknown setting POSTGRES_HOTS in file")
+	requireTest.Contains(err.Error(), `invalid LOG_FORMAT from env: "xml" is not one of json, console`)
+	requireTest.Contains(err.Error(), "invalid POSTGRES_MAX_CONNS from env")
+	requireTest.Contains(err.Error(), "GRPC_TLS_KEY is required with GRPC_TLS_CERT")
+	requireTest.NotContains(err.Error(), "SHUTDOWN_TIMEOUT", "empty values are the default")
+	requireTest.Equal(15*time.Second, c.Duration("SHUTDOWN_TIMEOUT"))
+
+	c = New([]Var{{Name: "JWT_KEY", Secret: true, Required: true}})
+	c.lookupEnv = func(string) (string, bool) { return "", false }
+	requireTest.EqualError(c.Validate(), "config: JWT_KEY is required")
+	c.lookupEnv = func(key string) (string, bool) { return "/run/secrets/jwt_key", key == "JWT_KEY_FILE" }
+	requireTest.NoError(c.Validate())
+
+	_, err = readFile(writeFile(t, "togo.json", "{}"))
+	requireTest.Error(err)
+}
+
+func TestConfigSecrets(t *testing.T) {
+	requireTest := require.New(t)
+
+	secretFile := writeFile(t, "password", "from-file\n")
+	path := writeFile(t, "togo.yaml", "postgres:\n  password: file:"+secretFile+"\n")
+	c := newTestConfig(map[string]string{"CONFIG_FILE": path})
+	requireTest.NoError(c.Load())
+	pwd, err := c.Secret(context.Background(), "POSTGRES_PASSWORD")
+	requireTest.NoError(err)
+	requireTest.Equal("from-file", pwd)
+	requireTest.Contains(c.Effective(), Setting{Name: "POSTGRES_PASSWORD", Value: "<secret>", Source: SourceFile})
+
+	// the environment takes precedence over the file, secrets are never printed
+	requireTest.NoError(os.Setenv("POSTGRES_PASSWORD", "from-env"))
+	defer os.Unsetenv("POSTGRES_PASSWORD")
+	c.lookupEnv = os.LookupEnv
+	pwd, err = c.Secret(context.Background(), "POSTGRES_PASSWORD")
+	requireTest.NoError(err)
+	requireTest.Equal("from-env", pwd)
+
+	var out bytes.Buffer
+	requireTest.NoError(c.Print(&out))
+	requireTest.Contains(out.String(), "POSTGRES_PASSWORD=<secret>\t# env\n")
+	requireTest.Contains(out.String(), "POSTGRES_HOST=localhost\t# default\n")
+	requireTest.NotContains(out.String(), "from-env")
+	requireTest.NotContains(out.String(), "CLIENT_ID")
+}
+
+func TestConfigReload(t *testing.T) {
+	requireTest := require.New(t)
+
+	path := writeFile(t, "togo.yaml", "rate_limit_addr: 10/1m\npostgres:\n  host: db1\n  max_conns: 5\n")
+	c := newTestConfig(map[string]string{"CONFIG_FILE": path, "POSTGRES_MAX_CONNS": "8"})
+	requireTest.NoError(c.Load())
+
+	// an invalid file is not applied
+	requireTest.NoError(ioutil.WriteFile(path, []byte("rate_limit_addr: 20/1m\npostgres:\n  hots: db2\n"), 0o600))
+	_, _, err := c.Reload()
+	requireTest.Error(err)
+	requireTest.Equal("10/1m", c.String("RATE_LIMIT_ADDR"))
+
+	// settings of the environment don't change, those which aren't reloadable are told apart
+	requireTest.NoError(ioutil.WriteFile(path, []byte("rate_limit_addr: 20/1m\npostgres:\n  host: db2\n  max_conns: 6\n"), 0o600))
+	reloaded, restart, err := c.Reload()
+	requireTest.NoError(err)
+	requireTest.Equal([]string{"RATE_LIMIT_ADDR"}, reloaded)
+	requireTest.Equal([]string{"POSTGRES_HOST"}, restart)
+	requireTest.Equal("20/1m", c.String("RATE_LIMIT_ADDR"))
+	requireTest.Equal(8, c.Int("POSTGRES_MAX_CONNS"))
+
+	// a removed setting takes its default again
+	requireTest.NoError(ioutil.WriteFile(path, []byte("postgres:\n  host: db2\n"), 0o600))
+	reloaded, _, err = c.Reload()
+	requireTest.NoError(err)
+	requireTest.Equal([]string{"RATE_LIMIT_ADDR"}, reloaded)
+	requireTest.Equal("", c.String("RATE_LIMIT_ADDR"))
+}
+
+func TestConfigWatch(t *testing.T) {
+	requireTest := require.New(t)
+
+	requireTest.Nil(newTestConfig(nil).Watch(context.Background(), time.Millisecond), "no file to watch")
+
+	path := writeFile(t, "togo.yaml", "rate_limit_addr: 10/1m\n")
+	c := newTestConfig(map[string]string{"CONFIG_FILE": path})
+	requireTest.NoError(c.Load())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := c.Watch(ctx, 10*time.Millisecond)
+
+	requireTest.NoError(ioutil.WriteFile(path, []byte("rate_limit_addr: 100/1m\n"), 0o600))
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		requireTest.Fail("change of the file not seen")
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// readFile returns the settings of the YAML or TOML file of path by name, the format is told by its extension.
+// Sections name settings by their prefix, postgres: {host: db} sets POSTGRES_HOST, and lists are joined by commas
+func readFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "ReadFile()")
+	}
+
+	var doc map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		var m map[interface{}]interface{}
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+		doc = make(map[string]interface{}, len(m))
+		for k, v := range m {
+			doc[fmt.Sprint(k)] = v
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), &doc); err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+	default:
+		return nil, fmt.Errorf("config: unknown format of %s, use .yaml, .yml or .toml", path)
+	}
+
+	settings := make(map[string]string)
+	if err := flatten("", doc, settings); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return settings, nil
+}
+
+// flatten adds the values of section to settings, named by their keys after prefix
+func flatten(prefix string, section map[string]interface{}, settings map[string]string) error {
+	for key, value := range section {
+		name := settingName(prefix, key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := flatten(name, v, settings); err != nil {
+				return err
+			}
+		case map[interface{}]interface{}:
+			sub := make(map[string]interface{}, len(v))
+			for k, item := range v {
+				sub[fmt.Sprint(k)] = item
+			}
+			if err := flatten(name, sub, settings); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, err := scalar(name, item)
+				if err != nil {
+					return err
+				}
+				items = append(items, s)
+			}
+			settings[name] = strings.Join(items, ",")
+		default:
+			s, err := scalar(name, v)
+			if err != nil {
+				return err
+			}
+			settings[name] = s
+		}
+	}
+	return nil
+}
+
+// settingName returns the name of key of the section of prefix, upper-cased with underscores
+func settingName(prefix, key string) string {
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+func scalar(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string, bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	default:
+		return "", fmt.Errorf("config: %s is a %T, not a value or a list of values", name, value)
+	}
+}
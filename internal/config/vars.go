@@ -0,0 +1,133 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/manabie-com/togo/internal/accesslog"
+	"github.com/manabie-com/togo/internal/services"
+)
+
+// Vars are the settings of the service, see README for what each does
+var Vars = []Var{
+	// Logs, error reporting and traces
+	{Name: "LOG_LEVEL", Reloadable: true, Default: "info", Usage: "level of the logs, such as debug or warn"},
+	{Name: "LOG_FORMAT", Default: "json", Usage: "format of the logs", Values: []string{"json", "console"}},
+	{Name: "SENTRY_DSN", Secret: true, Usage: "DSN of the Sentry project errors are reported to"},
+	{Name: "SENTRY_RELEASE", Usage: "release errors are tagged with, the version by default"},
+	{Name: "SENTRY_ENVIRONMENT", Usage: "environment errors are tagged with"},
+	{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Usage: "OTLP collector spans are exported to"},
+	{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Default: "grpc", Usage: "protocol of the OTLP collector", Values: []string{"grpc", "http"}},
+	{Name: "OTEL_EXPORTER_OTLP_INSECURE", Kind: KindBool, Default: "false", Usage: "export spans without TLS"},
+	{Name: "OTEL_EXPORTER_OTLP_HEADERS", Usage: "headers of exports, key=value comma separated"},
+	{Name: "OTEL_SERVICE_NAME", Default: "togo", Usage: "service name of spans"},
+	{Name: "OTEL_TRACES_SAMPLER_ARG", Kind: KindFloat, Default: "1", Usage: "ratio of the traces started here which are sampled"},
+
+	// Storage
+	{Name: "STORAGE_DRIVER", Default: "postgres", Usage: "storage driver"},
+	{Name: "STORAGE_DSN", Secret: true, Usage: "DSN of the storage, built from POSTGRES_* for postgres by default"},
+	{Name: "STORAGE_SECONDARY_DRIVER", Usage: "storage driver written to as well while migrating to it"},
+	{Name: "STORAGE_SECONDARY_DSN", Secret: true, RequiredWith: "STORAGE_SECONDARY_DRIVER", Usage: "DSN of the secondary storage"},
+	{Name: "STORAGE_CONSISTENCY_CHECK", Kind: KindBool, Default: "false", Usage: "compare reads of the primary and the secondary storage"},
+	{Name: "POSTGRES_HOST", Default: "localhost", Usage: "Postgres host"},
+	{Name: "POSTGRES_PORT", Default: "5432", Usage: "Postgres port"},
+	{Name: "POSTGRES_USER", Default: "togo", Usage: "Postgres user"},
+	{Name: "POSTGRES_PASSWORD", Secret: true, Default: "togo", Usage: "Postgres password"},
+	{Name: "POSTGRES_DB", Default: "togo", Usage: "Postgres database"},
+	{Name: "POSTGRES_MAX_CONNS", Kind: KindInt, Usage: "most connections of the pool"},
+	{Name: "POSTGRES_MIN_CONNS", Kind: KindInt, Usage: "connections the pool keeps open"},
+	{Name: "POSTGRES_MAX_CONN_LIFETIME", Kind: KindDuration, Usage: "how long connections are kept at most"},
+	{Name: "POSTGRES_MAX_CONN_IDLE_TIME", Kind: KindDuration, Usage: "how long idle connections are kept at most"},
+	{Name: "POSTGRES_HEALTH_CHECK_PERIOD", Kind: KindDuration, Usage: "how often idle connections are checked"},
+	{Name: "POSTGRES_STATEMENT_TIMEOUT", Kind: KindDuration, Usage: "statements running longer are aborted"},
+	{Name: "POSTGRES_OP_TIMEOUT", Kind: KindDuration, Usage: "bound of each attempt of an operation"},
+	{Name: "POSTGRES_MAX_RETRIES", Kind: KindInt, Usage: "retries of operations failed with transient errors, -1 disables them"},
+	{Name: "POSTGRES_SLOW_QUERY", Kind: KindDuration, Usage: "statements taking longer are logged as slow"},
+	{Name: "POSTGRES_RECREATE_AFTER", Kind: KindInt, Usage: "failed health checks in a row before the pool is re-created, -1 disables it"},
+	{Name: "POSTGRES_CONNECT_WAIT", Kind: KindDuration, Usage: "how long connecting on start is retried while Postgres isn't ready, 30s by default, -1s fails at once"},
+	{Name: "POSTGRES_STATEMENT_CACHE_MODE", Usage: "how statements are cached", Values: []string{"prepare", "describe"}},
+	{Name: "POSTGRES_STATEMENT_CACHE_CAPACITY", Kind: KindInt, Usage: "statements cached per connection"},
+	{Name: "POSTGRES_SSLMODE", Usage: "libpq sslmode"},
+	{Name: "POSTGRES_SSLROOTCERT", Usage: "CA certificates of the server"},
+	{Name: "POSTGRES_SSLCERT", Usage: "client certificate"},
+	{Name: "POSTGRES_SSLKEY", Usage: "key of the client certificate"},
+	{Name: "POSTGRES_REPLICAS", Kind: KindList, Usage: "connection strings of read replicas, comma separated"},
+
+	// Tasks
+	{Name: "QUOTA_POLICY", Reloadable: true, Default: "daily", Usage: "which tasks count towards the limits"},
+	{Name: "QUOTA_BURST", Reloadable: true, Usage: "tasks users may add over their limits once in a while, such as 2/24h"},
+	{Name: "RECURRENCE_INTERVAL", Kind: KindDuration, Usage: "how often recurring tasks are materialized"},
+	{Name: "REMINDER_INTERVAL", Kind: KindDuration, Usage: "how often due reminders are delivered"},
+	{Name: "REMINDER_WEBHOOK_URL", Usage: "webhook reminders are delivered to, they are logged otherwise"},
+	{Name: "REMINDER_WEBHOOK_SECRET", Secret: true, Usage: "key the deliveries of the webhook are signed with"},
+	{Name: "BLOB_DRIVER", Usage: "blob store of attachments, attachments are off unless it's set"},
+	{Name: "BLOB_DSN", Usage: "DSN of the blob store"},
+	{Name: "ATTACHMENT_MAX_SIZE", Kind: KindInt, Default: strconv.Itoa(10 << 20), Usage: "largest attachment in bytes"},
+	{Name: "TRASH_RETENTION", Kind: KindDuration, Usage: "how long deleted tasks are kept in the trash"},
+	{Name: "TRASH_PURGE_INTERVAL", Kind: KindDuration, Usage: "how often the trash is purged"},
+
+	// Authentication
+	{Name: "JWT_KEY", Secret: true, Default: "wqGyEBBfPK9w3Lxw", Usage: "HS256 key of tokens"},
+	{Name: "JWT_KEYS", Secret: true, Usage: "JSON list of the rotated keys of tokens"},
+	{Name: "JWT_PRIVATE_KEY_FILE", Usage: "file of the RS256 private key of tokens"},
+	{Name: "JWT_ALG", Default: "HS256", Usage: "algorithm of tokens", Values: []string{"HS256", "RS256"}},
+	{Name: "JWT_TTL", Kind: KindDuration, Usage: "lifetime of tokens"},
+	{Name: "REFRESH_TOKEN_TTL", Kind: KindDuration, Usage: "lifetime of refresh tokens"},
+	{Name: "SHARE_LINK_KEY", Secret: true, Usage: "key share links are signed with, JWT_KEY by default"},
+	{Name: "AUTH_COOKIES", Kind: KindBool, Default: "false", Usage: "give browsers tokens as cookies too"},
+	{Name: "AUTH_COOKIE_SECURE", Kind: KindBool, Default: "true", Usage: "mark cookies Secure"},
+	{Name: "AUTH_COOKIE_DOMAIN", Usage: "domain of cookies"},
+	{Name: "AUTH_COOKIE_SAMESITE", Default: "lax", Usage: "SameSite of cookies", Values: []string{"lax", "strict", "none"}},
+	{Name: "AUTH_PROVIDERS", Kind: KindList, Usage: "identity providers users may log in at, such as google,github"},
+	{Name: "AUTH_STATE_KEY", Secret: true, Usage: "key the state of logins at providers is signed with, JWT_KEY by default"},
+	{Name: "AUTH_CALLBACK_URL", Default: "http://localhost:5050", Usage: "URL providers redirect back to"},
+	{Name: "*_CLIENT_ID", Usage: "client id at an identity provider"},
+	{Name: "*_CLIENT_SECRET", Secret: true, Usage: "client secret at an identity provider"},
+	{Name: "*_ISSUER", Usage: "OpenID issuer of an identity provider"},
+	{Name: "LOGIN_MAX_FAILURES", Kind: KindInt, Usage: "failed logins before they are slowed down"},
+	{Name: "LOGIN_LOCKOUT", Kind: KindDuration, Usage: "first wait after too many failed logins"},
+	{Name: "LOGIN_MAX_LOCKOUT", Kind: KindDuration, Usage: "longest wait after failed logins"},
+	{Name: "PASSWORD_RESET_MAILER", Usage: "mailer of password resets, they are off unless it's set", Values: []string{"log"}},
+	{Name: "PASSWORD_RESET_TTL", Kind: KindDuration, Usage: "lifetime of password reset tokens"},
+
+	// HTTP and gRPC
+	{Name: "METRICS_ENABLED", Kind: KindBool, Default: "true", Usage: "serve metrics at /metrics"},
+	{Name: "CORS_ALLOWED_ORIGINS", Reloadable: true, Kind: KindList, Usage: "browser origins which may call the API"},
+	{Name: "CORS_ALLOWED_METHODS", Reloadable: true, Kind: KindList, Usage: "methods of cross-origin requests"},
+	{Name: "CORS_ALLOWED_HEADERS", Reloadable: true, Kind: KindList, Usage: "headers of cross-origin requests"},
+	{Name: "CORS_EXPOSED_HEADERS", Reloadable: true, Kind: KindList, Usage: "headers of responses browsers expose"},
+	{Name: "CORS_ALLOW_CREDENTIALS", Reloadable: true, Kind: KindBool, Default: "false", Usage: "allow cross-origin requests with credentials"},
+	{Name: "CORS_MAX_AGE", Reloadable: true, Kind: KindDuration, Usage: "how long preflight responses are cached"},
+	{Name: "LEGACY_API_SUNSET", Kind: KindTime, Usage: "end of the support of unversioned paths, RFC 3339"},
+	{Name: "IDEMPOTENCY_TTL", Kind: KindDuration, Usage: "how long responses of idempotency keys are kept"},
+	{Name: "HTTP_MAX_BODY_SIZE", Kind: KindInt, Default: strconv.Itoa(services.DefaultMaxBodySize), Usage: "largest request body in bytes"},
+	{Name: "HTTP_HANDLER_TIMEOUT", Kind: KindDuration, Default: services.DefaultHandlerTimeout.String(), Usage: "bound of handlers"},
+	{Name: "HTTP_ROUTE_TIMEOUTS", Kind: KindList, Usage: "bounds of the handlers of routes, such as /tasks:batch=1m"},
+	{Name: "HTTP_READ_HEADER_TIMEOUT", Kind: KindDuration, Default: services.DefaultReadHeaderTimeout.String(), Usage: "bound of reading request headers"},
+	{Name: "HTTP_READ_TIMEOUT", Kind: KindDuration, Usage: "bound of reading requests"},
+	{Name: "HTTP_WRITE_TIMEOUT", Kind: KindDuration, Usage: "bound of writing responses"},
+	{Name: "HTTP_IDLE_TIMEOUT", Kind: KindDuration, Default: services.DefaultIdleTimeout.String(), Usage: "how long idle connections are kept"},
+	{Name: "RATE_LIMIT_ADDR", Reloadable: true, Usage: "rate of requests per address, such as 100/1m"},
+	{Name: "RATE_LIMIT_USER", Reloadable: true, Usage: "rate of requests per signed in user"},
+	{Name: "RATE_LIMIT_REDIS_URL", Usage: "Redis instances share rate limits in"},
+	{Name: "HTTP_ADDR", Default: ":5050", Usage: "address the HTTP API is served at"},
+	{Name: "HTTP_TLS_CERT", RequiredWith: "HTTP_TLS_KEY", Usage: "certificate file of the HTTP server"},
+	{Name: "HTTP_TLS_KEY", RequiredWith: "HTTP_TLS_CERT", Usage: "key file of the HTTP server"},
+	{Name: "HTTP_TLS_CLIENT_CA", Usage: "CA file client certificates of the HTTP API must be signed by"},
+	{Name: "ADMIN_ADDR", Usage: "address probes, metrics, profiles and the admin API are served at instead of HTTP_ADDR"},
+	{Name: "ADMIN_TLS_CERT", RequiredWith: "ADMIN_TLS_KEY", Usage: "certificate file of the admin server"},
+	{Name: "ADMIN_TLS_KEY", RequiredWith: "ADMIN_TLS_CERT", Usage: "key file of the admin server"},
+	{Name: "ADMIN_TLS_CLIENT_CA", Usage: "CA file client certificates of the admin server must be signed by"},
+	{Name: "GRPC_ADDR", Usage: "address the gRPC API is served at"},
+	{Name: "GRPC_TLS_CERT", RequiredWith: "GRPC_TLS_KEY", Usage: "certificate file of the gRPC server"},
+	{Name: "GRPC_TLS_KEY", RequiredWith: "GRPC_TLS_CERT", Usage: "key file of the gRPC server"},
+	{Name: "GRPC_TLS_CLIENT_CA", Usage: "CA file client certificates must be signed by"},
+	{Name: "DEBUG_ADDR", Usage: "address profiles are served at"},
+	{Name: "ACCESS_LOG_FILE", Usage: "file requests are written to"},
+	{Name: "ACCESS_LOG_FORMAT", Default: accesslog.FormatCombined, Usage: "format of the access log",
+		Values: []string{accesslog.FormatCommon, accesslog.FormatCombined, accesslog.FormatJSON}},
+	{Name: "ACCESS_LOG_MAX_SIZE", Kind: KindInt, Default: strconv.Itoa(100 << 20), Usage: "bytes before the access log is rotated"},
+	{Name: "ACCESS_LOG_ROTATE", Kind: KindDuration, Default: "24h", Usage: "how often the access log is rotated"},
+	{Name: "ACCESS_LOG_MAX_BACKUPS", Kind: KindInt, Default: "7", Usage: "rotated access logs kept"},
+	{Name: "SHUTDOWN_TIMEOUT", Kind: KindDuration, Default: "15s", Usage: "how long in-flight requests are drained on shutdown"},
+	{Name: "CONFIG_WATCH", Kind: KindDuration, Default: "5s", Usage: "how often the file of settings is checked for changes, 0 disables it"},
+}
@@ -0,0 +1,106 @@
+// Package events carries the changes of tasks to the streams of their owners. The bus is in memory, streams
+// only see the changes made through the same instance
+package events
+
+import (
+	"sync"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// Type tells what happened to a task
+type Type string
+
+const (
+	Created Type = "created"
+	Updated Type = "updated"
+	Deleted Type = "deleted"
+)
+
+// DefaultBuffer is how many events a subscriber may lag behind by default
+const DefaultBuffer = 64
+
+// TaskEvent is a change of a task of UsrId. Task is the task after the change when it's known,
+// subscribers fetch it by TaskId otherwise
+type TaskEvent struct {
+	Type   Type           `json:"type"`
+	UsrId  int            `json:"usr_id"`
+	TaskId int            `json:"task_id"`
+	Task   *storages.Task `json:"task,omitempty"`
+}
+
+// Bus fans events out to the subscribers of their user. Publishing never blocks: a subscriber which lags
+// more than its buffer behind is dropped, its channel is closed so that it can resync and subscribe again
+type Bus struct {
+	mu     sync.Mutex
+	buffer int
+	subs   map[int]map[chan TaskEvent]struct{}
+	closed bool
+}
+
+// NewBus create new Bus instance, a non positive buffer gives DefaultBuffer
+func NewBus(buffer int) *Bus {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Bus{buffer: buffer, subs: map[int]map[chan TaskEvent]struct{}{}}
+}
+
+// Subscribe returns the events of the tasks of usrId until cancel is called or the bus is closed
+func (b *Bus) Subscribe(usrId int) (<-chan TaskEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan TaskEvent, b.buffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subs[usrId] == nil {
+		b.subs[usrId] = map[chan TaskEvent]struct{}{}
+	}
+	b.subs[usrId][ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(usrId, ch)
+	}
+}
+
+// Publish sends e to the subscribers of its user
+func (b *Bus) Publish(e TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[e.UsrId] {
+		select {
+		case ch <- e:
+		default:
+			b.drop(e.UsrId, ch)
+		}
+	}
+}
+
+// Close closes the channels of every subscriber, later subscriptions get closed channels
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for usrId, subs := range b.subs {
+		for ch := range subs {
+			b.drop(usrId, ch)
+		}
+	}
+	b.closed = true
+}
+
+func (b *Bus) drop(usrId int, ch chan TaskEvent) {
+	if _, ok := b.subs[usrId][ch]; !ok {
+		return
+	}
+	delete(b.subs[usrId], ch)
+	if len(b.subs[usrId]) == 0 {
+		delete(b.subs, usrId)
+	}
+	close(ch)
+}
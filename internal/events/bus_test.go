@@ -0,0 +1,41 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	requireTest := require.New(t)
+	b := NewBus(2)
+
+	alice, cancel := b.Subscribe(1)
+	bob, _ := b.Subscribe(2)
+	b.Publish(TaskEvent{Type: Created, UsrId: 1, TaskId: 7})
+	requireTest.Equal(TaskEvent{Type: Created, UsrId: 1, TaskId: 7}, <-alice)
+	requireTest.Len(bob, 0)
+
+	// A subscriber lagging more than its buffer behind is dropped
+	for i := 0; i < 3; i++ {
+		b.Publish(TaskEvent{Type: Updated, UsrId: 2, TaskId: i})
+	}
+	requireTest.Len(bob, 2)
+	<-bob
+	<-bob
+	_, ok := <-bob
+	requireTest.False(ok)
+
+	cancel()
+	_, ok = <-alice
+	requireTest.False(ok)
+	cancel()
+
+	carol, _ := b.Subscribe(3)
+	b.Close()
+	_, ok = <-carol
+	requireTest.False(ok)
+	late, _ := b.Subscribe(3)
+	_, ok = <-late
+	requireTest.False(ok)
+}
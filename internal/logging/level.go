@@ -0,0 +1,159 @@
+package logging
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Level is the level of the loggers built by New, which can be changed while they log, along with the users and
+// routes whose requests are logged at debug level whatever the level until a given time
+type Level struct {
+	zap.AtomicLevel
+	now func() time.Time
+
+	mu     sync.RWMutex
+	users  map[int]time.Time
+	routes map[string]time.Time
+}
+
+// DebugOverride is a user or a route whose requests are logged at debug level until a time
+type DebugOverride struct {
+	UsrId int       `json:"usr_id,omitempty"`
+	Route string    `json:"route,omitempty"`
+	Until time.Time `json:"until"`
+}
+
+func newLevel(level zap.AtomicLevel) *Level {
+	return &Level{
+		AtomicLevel: level,
+		now:         time.Now,
+		users:       make(map[int]time.Time),
+		routes:      make(map[string]time.Time),
+	}
+}
+
+// DebugUser logs the requests of the user at debug level until until, a time which passed stops it
+func (l *Level) DebugUser(usrId int, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until.After(l.now()) {
+		l.users[usrId] = until
+	} else {
+		delete(l.users, usrId)
+	}
+}
+
+// DebugRoute logs the requests of paths starting with route at debug level until until, a time which passed
+// stops it
+func (l *Level) DebugRoute(route string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until.After(l.now()) {
+		l.routes[route] = until
+	} else {
+		delete(l.routes, route)
+	}
+}
+
+// DebugsUser reports whether the requests of the user are logged at debug level
+func (l *Level) DebugsUser(usrId int) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	until, ok := l.users[usrId]
+	return ok && l.now().Before(until)
+}
+
+// DebugsPath reports whether the requests of path are logged at debug level
+func (l *Level) DebugsPath(path string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	now := l.now()
+	for route, until := range l.routes {
+		if strings.HasPrefix(path, route) && now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overrides returns the users and routes logged at debug level, users first, forgetting those which expired
+func (l *Level) Overrides() []DebugOverride {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	overrides := make([]DebugOverride, 0, len(l.users)+len(l.routes))
+	for id, until := range l.users {
+		if !now.Before(until) {
+			delete(l.users, id)
+			continue
+		}
+		overrides = append(overrides, DebugOverride{UsrId: id, Until: until})
+	}
+	for route, until := range l.routes {
+		if !now.Before(until) {
+			delete(l.routes, route)
+			continue
+		}
+		overrides = append(overrides, DebugOverride{Route: route, Until: until})
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].UsrId != overrides[j].UsrId {
+			return overrides[i].UsrId > overrides[j].UsrId
+		}
+		return overrides[i].Route < overrides[j].Route
+	})
+	return overrides
+}
+
+// debugKey is the key of the field by which Debug marks loggers, fields of SkipType aren't encoded
+const debugKey = "logging.debug"
+
+// levelCore filters the entries of a core built at debug level by its Level. Cores with a user_id field let
+// debug entries through while the user is debugged, those of Debug always do
+type levelCore struct {
+	zapcore.Core
+	level *Level
+	usrId int
+	debug bool
+}
+
+func (c *levelCore) Enabled(l zapcore.Level) bool {
+	return c.debug || c.level.Enabled(l) || (c.usrId != 0 && c.level.DebugsUser(c.usrId))
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	with := *c
+	with.Core = c.Core.With(fields)
+	for _, field := range fields {
+		switch {
+		case field.Key == "user_id" && field.Type == zapcore.Int64Type:
+			with.usrId = int(field.Integer)
+		case field.Key == debugKey && field.Type == zapcore.SkipType:
+			with.debug = true
+		}
+	}
+	return &with
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// Debug returns a copy of logger which logs at debug level whatever the level, if it was built by New. Cores
+// teed to it keep their levels
+func Debug(logger *zap.Logger) *zap.Logger {
+	return logger.With(zapcore.Field{Key: debugKey, Type: zapcore.SkipType})
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevel(t *testing.T) {
+	requireTest := require.New(t)
+	core, logs := observer.New(zapcore.DebugLevel)
+	level := newLevel(zap.NewAtomicLevelAt(zapcore.InfoLevel))
+	now := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	level.now = func() time.Time { return now }
+	logger := zap.New(&levelCore{Core: core, level: level})
+
+	logger.Debug("hidden")
+	logger.Info("shown")
+	requireTest.Len(logs.TakeAll(), 1)
+
+	// the level is changed while loggers log
+	level.SetLevel(zapcore.WarnLevel)
+	logger.Info("hidden")
+	requireTest.Empty(logs.TakeAll())
+	level.SetLevel(zapcore.InfoLevel)
+
+	// debugged users are logged at debug level until the time given
+	level.DebugUser(7, now.Add(time.Minute))
+	logger.With(zap.Int("user_id", 7)).Debug("shown")
+	logger.With(zap.Int("user_id", 8)).Debug("hidden")
+	requireTest.Len(logs.TakeAll(), 1)
+	requireTest.True(level.DebugsUser(7))
+
+	level.DebugRoute("/v1/tasks", now.Add(2*time.Minute))
+	requireTest.True(level.DebugsPath("/v1/tasks/1"))
+	requireTest.False(level.DebugsPath("/v1/projects"))
+	requireTest.Equal([]DebugOverride{
+		{UsrId: 7, Until: now.Add(time.Minute)},
+		{Route: "/v1/tasks", Until: now.Add(2 * time.Minute)},
+	}, level.Overrides())
+
+	// so are loggers of Debug, not their fields
+	Debug(logger).Debug("shown")
+	entries := logs.TakeAll()
+	requireTest.Len(entries, 1)
+	requireTest.Empty(entries[0].ContextMap())
+
+	now = now.Add(time.Minute)
+	logger.With(zap.Int("user_id", 7)).Debug("hidden")
+	requireTest.Empty(logs.TakeAll())
+	requireTest.Len(level.Overrides(), 1)
+	level.DebugRoute("/v1/tasks", time.Time{})
+	requireTest.Empty(level.Overrides())
+}
@@ -0,0 +1,67 @@
+// Package logging builds the structured logger of the service and threads it through contexts, so that what
+// storages log about a request carries its request id
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New builds a logger of lines of JSON, or of text for the console format, at level. level is one of debug,
+// info, warn and error, info by default. The returned Level changes it while the logger logs
+func New(level, format string) (*zap.Logger, *Level, error) {
+	config := zap.NewProductionConfig()
+	if format == "console" {
+		config = zap.NewDevelopmentConfig()
+	} else if format != "" && format != "json" {
+		return nil, nil, fmt.Errorf("logging: unknown format %q", format)
+	}
+	atomic := zap.NewAtomicLevelAt(config.Level.Level())
+	if level != "" {
+		if err := atomic.UnmarshalText([]byte(level)); err != nil {
+			return nil, nil, fmt.Errorf("logging: unknown level %q", level)
+		}
+	}
+	config.EncoderConfig.TimeKey = "time"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	// the core logs everything, levelCore filters what it's given by l
+	l := newLevel(atomic)
+	config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	logger, err := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, level: l}
+	}))
+	if err != nil {
+		return nil, nil, err
+	}
+	return logger, l, nil
+}
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx which carries logger
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of ctx, the global logger of zap if it has none
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return zap.L()
+}
+
+// NewRequestID returns a random id of 32 hex digits
+func NewRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNew(t *testing.T) {
+	requireTest := require.New(t)
+	logger, _, err := New("", "")
+	requireTest.NoError(err)
+	requireTest.True(logger.Core().Enabled(zapcore.InfoLevel))
+	requireTest.False(logger.Core().Enabled(zapcore.DebugLevel))
+
+	logger, _, err = New("debug", "console")
+	requireTest.NoError(err)
+	requireTest.True(logger.Core().Enabled(zapcore.DebugLevel))
+
+	_, _, err = New("loud", "json")
+	requireTest.Error(err)
+	_, _, err = New("info", "xml")
+	requireTest.Error(err)
+}
+
+func TestFromContext(t *testing.T) {
+	requireTest := require.New(t)
+	requireTest.Equal(zap.L(), FromContext(context.Background()))
+
+	logger := zap.NewExample()
+	requireTest.Equal(logger, FromContext(NewContext(context.Background(), logger)))
+
+	id, err := NewRequestID()
+	requireTest.NoError(err)
+	requireTest.Len(id, 32)
+}
@@ -0,0 +1,33 @@
+// Package mailer delivers messages to users, the service only depends on Mailer so that
+// deliveries by SMTP, a mailing API or anything else can be plugged in
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// Message is delivered to the user of username To, mailers find out the address of the user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Func adapts a function to Mailer
+type Func func(ctx context.Context, msg *Message) error
+
+func (f Func) Send(ctx context.Context, msg *Message) error {
+	return f(ctx, msg)
+}
+
+// Log only logs messages, it's meant for development since messages may carry secrets
+var Log = Func(func(_ context.Context, msg *Message) error {
+	log.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+})
@@ -0,0 +1,215 @@
+// Package markdown renders the subset of Markdown used by task contents to HTML which is safe
+// to embed in web pages: raw HTML is always escaped and links are restricted to safe schemes
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// safeSchemes are the schemes links may have, links without a scheme are relative ones
+var safeSchemes = map[string]bool{"": true, "http": true, "https": true, "mailto": true}
+
+// Render converts src to HTML. It supports paragraphs, headings, fenced code blocks,
+// block quotes, ordered and unordered lists, code spans, links, emphasis and strong emphasis
+func Render(src string) string {
+	r := &renderer{}
+	r.render(strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"))
+	return r.out.String()
+}
+
+type renderer struct {
+	out strings.Builder
+	// para holds lines of the current paragraph, list is the tag of the open list if any
+	para []string
+	list string
+}
+
+func (r *renderer) render(lines []string) {
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			r.flush()
+			r.out.WriteString("<pre><code>")
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				r.out.WriteString(html.EscapeString(lines[i]))
+				r.out.WriteString("\n")
+			}
+			r.out.WriteString("</code></pre>\n")
+		case trimmed == "":
+			r.flush()
+		case strings.HasPrefix(trimmed, ">"):
+			r.flush()
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(quote, " "))
+			}
+			i--
+			r.out.WriteString("<blockquote>\n")
+			r.out.WriteString(Render(strings.Join(quoted, "\n")))
+			r.out.WriteString("</blockquote>\n")
+		default:
+			if level := headingLevel(trimmed); level > 0 {
+				r.flush()
+				tag := "h" + string(rune('0'+level))
+				r.writeTag(tag, inline(strings.TrimSpace(trimmed[level:])))
+				continue
+			}
+			if tag, item, ok := listItem(trimmed); ok {
+				r.flushPara()
+				if r.list != tag {
+					r.closeList()
+					r.list = tag
+					r.out.WriteString("<" + tag + ">\n")
+				}
+				r.writeTag("li", inline(item))
+				continue
+			}
+			r.closeList()
+			r.para = append(r.para, trimmed)
+		}
+	}
+	r.flush()
+}
+
+func (r *renderer) writeTag(tag, content string) {
+	r.out.WriteString("<" + tag + ">" + content + "</" + tag + ">\n")
+}
+
+// flush ends the open paragraph and list
+func (r *renderer) flush() {
+	r.flushPara()
+	r.closeList()
+}
+
+func (r *renderer) flushPara() {
+	if len(r.para) == 0 {
+		return
+	}
+	r.writeTag("p", inline(strings.Join(r.para, "\n")))
+	r.para = nil
+}
+
+func (r *renderer) closeList() {
+	if r.list == "" {
+		return
+	}
+	r.out.WriteString("</" + r.list + ">\n")
+	r.list = ""
+}
+
+// headingLevel returns the level of an ATX heading line, 0 if line is not a heading
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level == len(line) || line[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// listItem returns the list tag and the content of a list item line
+func listItem(line string) (tag, item string, ok bool) {
+	if len(line) > 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return "ul", strings.TrimSpace(line[2:]), true
+	}
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < 10 && strings.HasPrefix(line[digits:], ". ") {
+		return "ol", strings.TrimSpace(line[digits+2:]), true
+	}
+	return "", "", false
+}
+
+// inline renders spans of a block, everything which is not markup is escaped
+func inline(s string) string {
+	var b strings.Builder
+	text := 0
+	emit := func(i int, markup string) {
+		b.WriteString(html.EscapeString(s[text:i]))
+		b.WriteString(markup)
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '`':
+			if j := strings.IndexByte(s[i+1:], '`'); j >= 0 {
+				emit(i, "<code>"+html.EscapeString(s[i+1:i+1+j])+"</code>")
+				i += j + 2
+				text = i
+				continue
+			}
+		case s[i] == '[':
+			if label, href, n, ok := link(s[i:]); ok {
+				emit(i, `<a href="`+html.EscapeString(href)+`" rel="nofollow noopener">`+inline(label)+"</a>")
+				i += n
+				text = i
+				continue
+			}
+		case strings.HasPrefix(s[i:], "**"):
+			if j := closing(s[i+2:], "**"); j > 0 {
+				emit(i, "<strong>"+inline(s[i+2:i+2+j])+"</strong>")
+				i += j + 4
+				text = i
+				continue
+			}
+		case s[i] == '*':
+			if j := closing(s[i+1:], "*"); j > 0 {
+				emit(i, "<em>"+inline(s[i+1:i+1+j])+"</em>")
+				i += j + 2
+				text = i
+				continue
+			}
+		}
+		i++
+	}
+	b.WriteString(html.EscapeString(s[text:]))
+	return b.String()
+}
+
+// closing returns the index of the delimiter closing an emphasis which starts s, -1 if there is
+// none. Emphasis must not start or end with a space so that e.g. "2 * 3 * 4" is left as it is
+func closing(s, delim string) int {
+	if s == "" || s[0] == ' ' {
+		return -1
+	}
+	for from := 0; ; {
+		j := strings.Index(s[from:], delim)
+		if j < 0 {
+			return -1
+		}
+		j += from
+		if j > 0 && s[j-1] != ' ' {
+			return j
+		}
+		from = j + len(delim)
+	}
+}
+
+// link parses a link "[label](href)" which starts s and returns its length,
+// links with unsafe schemes such as javascript: are not links
+func link(s string) (label, href string, n int, ok bool) {
+	end := strings.Index(s, "](")
+	if end < 0 {
+		return "", "", 0, false
+	}
+	closeParen := strings.IndexByte(s[end+2:], ')')
+	if closeParen < 0 {
+		return "", "", 0, false
+	}
+	label, href = s[1:end], strings.TrimSpace(s[end+2:end+2+closeParen])
+	u, err := url.Parse(href)
+	if err != nil || !safeSchemes[strings.ToLower(u.Scheme)] {
+		return "", "", 0, false
+	}
+	return label, href, end + 3 + closeParen, true
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"paragraphs", "a\nb\n\nc", "<p>a\nb</p>\n<p>c</p>\n"},
+		{"heading", "## Groceries", "<h2>Groceries</h2>\n"},
+		{"not a heading", "#tag", "<p>#tag</p>\n"},
+		{"emphasis", "**milk** and *eggs*", "<p><strong>milk</strong> and <em>eggs</em></p>\n"},
+		{"no emphasis around spaces", "2 * 3 * 4", "<p>2 * 3 * 4</p>\n"},
+		{"code span", "run `rm -rf <dir>`", "<p>run <code>rm -rf &lt;dir&gt;</code></p>\n"},
+		{"unordered list", "- a\n* b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"ordered list", "1. a\n2. b\n\nc", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>\n<p>c</p>\n"},
+		{"code block", "```\n<b>x</b>\n```", "<pre><code>&lt;b&gt;x&lt;/b&gt;\n</code></pre>\n"},
+		{"quote", "> a\n> *b*", "<blockquote>\n<p>a\n<em>b</em></p>\n</blockquote>\n"},
+		{"link", "[docs](https://example.com/?a=1&b=2)", `<p><a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener">docs</a></p>` + "\n"},
+		{"raw html", `<script>alert("x")</script>`, "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>\n"},
+		{"unsafe link", "[x](javascript:alert(1))", "<p>[x](javascript:alert(1))</p>\n"},
+		{"unsafe link case", "[x](JavaScript:alert(1))", "<p>[x](JavaScript:alert(1))</p>\n"},
+		{"quoted href", `[x](https://a.com/"onmouseover="alert(1))`, `<p><a href="https://a.com/&#34;onmouseover=&#34;alert(1" rel="nofollow noopener">x</a>)</p>` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Render(tt.src))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package metrics exposes the metrics of the service to Prometheus: requests by route and status, quota
+// rejections, login failures, the connection pool of the storage and the build running
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics keeps the metrics of the service in a registry of its own, along with those of the Go runtime and
+// of the process. A nil Metrics records nothing
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	durations       *prometheus.HistogramVec
+	quotaRejections prometheus.Counter
+	loginFailures   prometheus.Counter
+	statements      *prometheus.HistogramVec
+}
+
+// New create new Metrics instance
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "togo_http_requests_total",
+			Help: "HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "togo_http_request_duration_seconds",
+			Help:    "How long HTTP requests took to serve by route and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		quotaRejections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "togo_quota_rejections_total",
+			Help: "Tasks rejected because a daily-limit was reached.",
+		}),
+		loginFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "togo_login_failures_total",
+			Help: "Logins rejected for invalid credentials or two-factor codes.",
+		}),
+		statements: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "togo_db_statement_duration_seconds",
+			Help:    "How long statements of the storage took by statement, such as SELECT usr.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"statement"}),
+	}
+	// the build is a gauge of 1 labeled by its version so that other metrics can be joined with it
+	info := version.Get()
+	build := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "togo_build_info",
+		Help: "The build running, always 1.",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"build_time": info.BuildTime,
+			"go_version": info.GoVersion,
+		},
+	})
+	build.Set(1)
+	m.registry.MustRegister(
+		build,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requests, m.durations, m.quotaRejections, m.loginFailures, m.statements,
+	)
+	return m
+}
+
+// Handler serves the metrics in the text format of Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a request to route, the pattern of the mux which served it
+func (m *Metrics) ObserveRequest(route, method string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.durations.WithLabelValues(route, method).Observe(elapsed.Seconds())
+}
+
+// QuotaRejected records n tasks rejected by a daily-limit
+func (m *Metrics) QuotaRejected(n int) {
+	if m == nil {
+		return
+	}
+	m.quotaRejections.Add(float64(n))
+}
+
+// LoginFailed records a failed login
+func (m *Metrics) LoginFailed() {
+	if m == nil {
+		return
+	}
+	m.loginFailures.Inc()
+}
+
+// ObserveStatement records a statement of the storage which took elapsed
+func (m *Metrics) ObserveStatement(statement string, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	m.statements.WithLabelValues(statement).Observe(elapsed.Seconds())
+}
+
+// RegisterPool reports the pool of stater when metrics are collected
+func (m *Metrics) RegisterPool(stater storages.PoolStater) error {
+	return m.registry.Register(&poolCollector{stater: stater})
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type stater storages.PoolStats
+
+func (s stater) PoolStats() storages.PoolStats {
+	return storages.PoolStats(s)
+}
+
+func TestMetrics(t *testing.T) {
+	requireTest := require.New(t)
+	m := New()
+	m.ObserveRequest("/tasks", "GET", 200, time.Millisecond)
+	m.ObserveRequest("/tasks", "GET", 200, time.Millisecond)
+	m.QuotaRejected(2)
+	m.LoginFailed()
+	m.ObserveStatement("SELECT usr", time.Millisecond)
+	requireTest.Equal(2.0, testutil.ToFloat64(m.requests.WithLabelValues("/tasks", "GET", "200")))
+	requireTest.Equal(2.0, testutil.ToFloat64(m.quotaRejections))
+	requireTest.Equal(1.0, testutil.ToFloat64(m.loginFailures))
+	requireTest.Equal(1, testutil.CollectAndCount(m.statements))
+
+	// a nil Metrics records nothing
+	var none *Metrics
+	none.ObserveRequest("/tasks", "GET", 200, time.Millisecond)
+	none.QuotaRejected(1)
+	none.LoginFailed()
+	none.ObserveStatement("SELECT usr", time.Millisecond)
+}
+
+func TestPoolMetrics(t *testing.T) {
+	requireTest := require.New(t)
+	m := New()
+	requireTest.NoError(m.RegisterPool(stater{AcquiredConns: 3, IdleConns: 1, MaxConns: 10, AcquireDuration: 2 * time.Second, Healthy: true, Recreations: 1}))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	requireTest.Contains(body, "togo_db_pool_acquired_conns 3\n")
+	requireTest.Contains(body, "togo_db_pool_idle_conns 1\n")
+	requireTest.Contains(body, "togo_db_pool_max_conns 10\n")
+	requireTest.Contains(body, "togo_db_pool_acquire_wait_seconds_total 2\n")
+	requireTest.Contains(body, "togo_db_pool_healthy 1\n")
+	requireTest.Contains(body, "togo_db_pool_consecutive_failures 0\n")
+	requireTest.Contains(body, "togo_db_pool_recreations_total 1\n")
+	requireTest.Contains(body, "go_goroutines")
+	requireTest.Contains(body, `togo_build_info{build_time="",commit="",go_version="`+runtime.Version()+`",version="dev"} 1`)
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolAcquiredConns = prometheus.NewDesc("togo_db_pool_acquired_conns", "Connections of the pool in use.", nil, nil)
+	poolIdleConns     = prometheus.NewDesc("togo_db_pool_idle_conns", "Idle connections of the pool.", nil, nil)
+	poolTotalConns    = prometheus.NewDesc("togo_db_pool_total_conns", "Connections of the pool.", nil, nil)
+	poolMaxConns      = prometheus.NewDesc("togo_db_pool_max_conns", "Most connections the pool opens.", nil, nil)
+	poolAcquires      = prometheus.NewDesc("togo_db_pool_acquires_total", "Connections acquired from the pool.", nil, nil)
+	poolEmptyAcquires = prometheus.NewDesc("togo_db_pool_empty_acquires_total", "Acquires which waited for a connection as none was idle.", nil, nil)
+	poolAcquireWait   = prometheus.NewDesc("togo_db_pool_acquire_wait_seconds_total", "How long acquiring connections took.", nil, nil)
+	poolHealthy       = prometheus.NewDesc("togo_db_pool_healthy", "Whether the last health check of the pool succeeded.", nil, nil)
+	poolFailures      = prometheus.NewDesc("togo_db_pool_consecutive_failures", "Health checks of the pool failed in a row.", nil, nil)
+	poolRecreations   = prometheus.NewDesc("togo_db_pool_recreations_total", "Times the pool was re-created after failing its health checks.", nil, nil)
+)
+
+// poolCollector reads the pool stats of a storage when metrics are collected
+type poolCollector struct {
+	stater storages.PoolStater
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolAcquiredConns
+	ch <- poolIdleConns
+	ch <- poolTotalConns
+	ch <- poolMaxConns
+	ch <- poolAcquires
+	ch <- poolEmptyAcquires
+	ch <- poolAcquireWait
+	ch <- poolHealthy
+	ch <- poolFailures
+	ch <- poolRecreations
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stater.PoolStats()
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(stats.AcquiredConns))
+	ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(stats.MaxConns))
+	ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(stats.AcquireCount))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(stats.EmptyAcquireCount))
+	ch <- prometheus.MustNewConstMetric(poolAcquireWait, prometheus.CounterValue, stats.AcquireDuration.Seconds())
+	healthy := 0.0
+	if stats.Healthy {
+		healthy = 1
+	}
+	ch <- prometheus.MustNewConstMetric(poolHealthy, prometheus.GaugeValue, healthy)
+	ch <- prometheus.MustNewConstMetric(poolFailures, prometheus.GaugeValue, float64(stats.ConsecutiveFailures))
+	ch <- prometheus.MustNewConstMetric(poolRecreations, prometheus.CounterValue, float64(stats.Recreations))
+}
@@ -0,0 +1,185 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"sort"
+	"time"
+)
+
+const versionTable = "schema_migration"
+
+var ErrNoMigrationToRollback = errors.New("no migration to roll back")
+
+// Migration is a versioned schema change, Up and Down are SQL statements
+// which are executed in a transaction together with version bookkeeping
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// DB is the subset of pgxpool.Pool used by Migrator
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Migrator applies and rolls back migrations, applied versions are kept in schema_migration table
+type Migrator struct {
+	db         DB
+	migrations []Migration
+}
+
+// NewMigrator create new Migrator, migrations must have unique positive versions
+func NewMigrator(db DB, migrations []Migration) (*Migrator, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, errors.Errorf("migration %q has invalid version %d", m.Name, m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, errors.Errorf("duplicated migration version %d", m.Version)
+		}
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+	}, nil
+}
+
+// Up applies all pending migrations in version order and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Migration, 0)
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		stmt := fmt.Sprintf(`INSERT INTO %s (version, name, applied_at) VALUES ($1, $2, now())`, versionTable)
+		err := m.inTx(ctx, migration.Up, stmt, migration.Version, migration.Name)
+		if err != nil {
+			return res, errors.Wrapf(err, "migration %d %s", migration.Version, migration.Name)
+		}
+		res = append(res, migration)
+	}
+
+	return res, nil
+}
+
+// Down rolls back the latest applied migration and returns it
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		stmt := fmt.Sprintf(`DELETE FROM %s WHERE version = $1`, versionTable)
+		if err := m.inTx(ctx, migration.Down, stmt, migration.Version); err != nil {
+			return nil, errors.Wrapf(err, "migration %d %s", migration.Version, migration.Name)
+		}
+		return &migration, nil
+	}
+
+	return nil, ErrNoMigrationToRollback
+}
+
+// Status returns status of every known migration in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		res = append(res, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+	return res, nil
+}
+
+// appliedVersions returns applied versions with their applied time,
+// version table is created if not exists
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]time.Time, error) {
+	stmt := fmt.Sprintf(
+		`
+		CREATE TABLE IF NOT EXISTS %s (
+			version 	int PRIMARY KEY ,
+			name 		text NOT NULL ,
+			applied_at	timestamptz NOT NULL
+		)
+		`, versionTable)
+	if _, err := m.db.Exec(ctx, stmt); err != nil {
+		return nil, errors.Wrap(err, "Exec()")
+	}
+
+	rows, err := m.db.Query(ctx, fmt.Sprintf(`SELECT version, applied_at FROM %s`, versionTable))
+	if err != nil {
+		return nil, errors.Wrap(err, "Query()")
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// inTx executes the migration statement and the bookkeeping statement in a transaction
+func (m *Migrator) inTx(ctx context.Context, migrationStmt, versionStmt string, args ...interface{}) error {
+	tx, err := m.db.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Begin()")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, migrationStmt); err != nil {
+		return errors.Wrap(err, "Exec()")
+	}
+	if _, err := tx.Exec(ctx, versionStmt, args...); err != nil {
+		return errors.Wrap(err, "Exec()")
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewMigratorSortsMigrations(t *testing.T) {
+	requireTest := require.New(t)
+
+	m, err := NewMigrator(nil, []Migration{
+		{Version: 2, Name: "second"},
+		{Version: 1, Name: "first"},
+	})
+	requireTest.NoError(err)
+	requireTest.Equal("first", m.migrations[0].Name)
+	requireTest.Equal("second", m.migrations[1].Name)
+}
+
+func TestNewMigratorInvalidVersions(t *testing.T) {
+	requireTest := require.New(t)
+
+	_, err := NewMigrator(nil, []Migration{{Version: 1}, {Version: 1}})
+	requireTest.Error(err)
+
+	_, err = NewMigrator(nil, []Migration{{Version: 0}})
+	requireTest.Error(err)
+}
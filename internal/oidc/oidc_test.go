@@ -0,0 +1,68 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestStateCodec(t *testing.T) {
+	requireTest := require.New(t)
+	now := time.Date(2020, 7, 29, 0, 0, 0, 0, time.UTC)
+	codec := NewStateCodec([]byte("key"))
+	codec.now = func() time.Time {
+		return now
+	}
+
+	st, cookie, err := codec.New("google", 7)
+	requireTest.NoError(err)
+	parsed, err := codec.Parse("google", cookie, st.Value)
+	requireTest.NoError(err)
+	requireTest.Equal(st, parsed)
+	requireTest.Equal(7, parsed.UsrId)
+	requireTest.NotEqual(st.Value, st.Nonce())
+
+	_, err = codec.Parse("github", cookie, st.Value)
+	requireTest.Equal(ErrInvalidState, err)
+	_, err = codec.Parse("google", cookie, "other")
+	requireTest.Equal(ErrInvalidState, err)
+	_, err = NewStateCodec([]byte("other key")).Parse("google", cookie, st.Value)
+	requireTest.Equal(ErrInvalidState, err)
+	_, err = codec.Parse("google", cookie+"x", st.Value)
+	requireTest.Equal(ErrInvalidState, err)
+
+	now = now.Add(StateTTL + time.Second)
+	_, err = codec.Parse("google", cookie, st.Value)
+	requireTest.Equal(ErrInvalidState, err)
+}
+
+func TestGitHubExchange(t *testing.T) {
+	requireTest := require.New(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(resp http.ResponseWriter, req *http.Request) {
+		requireTest.NoError(req.ParseForm())
+		requireTest.Equal("code", req.Form.Get("code"))
+		resp.Header().Set("Content-Type", "application/json")
+		_, _ = resp.Write([]byte(`{"access_token": "token", "token_type": "bearer"}`))
+	})
+	mux.HandleFunc("/user", func(resp http.ResponseWriter, req *http.Request) {
+		requireTest.Equal("Bearer token", req.Header.Get("Authorization"))
+		_, _ = resp.Write([]byte(`{"id": 42, "login": "octocat", "email": "octocat@example.com"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := NewGitHub(&Config{ClientID: "id", ClientSecret: "secret"}).(*gitHub)
+	p.oauth.Endpoint = oauth2.Endpoint{AuthURL: server.URL + "/authorize", TokenURL: server.URL + "/token"}
+	p.userURL = server.URL + "/user"
+
+	requireTest.Contains(p.AuthCodeURL("state", "nonce"), "state=state")
+	identity, err := p.Exchange(context.Background(), "code", "")
+	requireTest.NoError(err)
+	requireTest.Equal(&Identity{Subject: "42", Username: "octocat", Email: "octocat@example.com"}, identity)
+}
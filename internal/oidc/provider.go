@@ -0,0 +1,169 @@
+// Package oidc logs users in at external identity providers by the OAuth2 authorization code flow,
+// with OpenID Connect for providers which support it such as Google and with the user API for GitHub
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/coreos/go-oidc"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const (
+	// GoogleIssuer is the issuer of Google accounts
+	GoogleIssuer = "https://accounts.google.com"
+	// gitHubUserURL returns the user of an access token
+	gitHubUserURL = "https://api.github.com/user"
+)
+
+var ErrInvalidIdentity = errors.New("identity is not valid")
+
+// Identity is an account at a provider, Subject is its stable id while Username and Email may change
+type Identity struct {
+	Subject  string
+	Username string
+	Email    string
+}
+
+// Provider logs users in at an identity provider. AuthCodeURL returns the page the user is redirected to,
+// the provider redirects back with state and a code which Exchange trades for the identity of the user.
+// Exchange returns ErrInvalidIdentity if the provider vouches for no identity of the nonce
+type Provider interface {
+	AuthCodeURL(state, nonce string) string
+	Exchange(ctx context.Context, code, nonce string) (*Identity, error)
+}
+
+// Config configures a Provider, RedirectURL is the callback of the provider at the service
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// openID logs users in by OpenID Connect, identities come from the verified ID token
+type openID struct {
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOpenID returns a Provider of issuer whose endpoints and keys are discovered through its well-known configuration
+func NewOpenID(ctx context.Context, issuer string, config *Config) (Provider, error) {
+	provider, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewProvider()")
+	}
+	scopes := config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{oidc.ScopeOpenID, "profile", "email"}
+	}
+	return &openID{
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+	}, nil
+}
+
+func (p *openID) AuthCodeURL(state, nonce string) string {
+	return p.oauth.AuthCodeURL(state, oidc.Nonce(nonce))
+}
+
+func (p *openID) Exchange(ctx context.Context, code, nonce string) (*Identity, error) {
+	token, err := p.oauth.Exchange(ctx, code)
+	if err != nil {
+		return nil, errors.Wrap(err, "Exchange()")
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, ErrInvalidIdentity
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil || idToken.Nonce != nonce {
+		return nil, ErrInvalidIdentity
+	}
+
+	claims := &struct {
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+	}{}
+	if err := idToken.Claims(claims); err != nil {
+		return nil, errors.Wrap(err, "Claims()")
+	}
+	identity := &Identity{Subject: idToken.Subject, Username: claims.PreferredUsername}
+	if claims.EmailVerified {
+		identity.Email = claims.Email
+	}
+	return identity, nil
+}
+
+// gitHub logs users in by OAuth2 at GitHub which has no ID tokens, identities come from its user API
+type gitHub struct {
+	oauth   *oauth2.Config
+	userURL string
+}
+
+// NewGitHub returns a Provider of GitHub accounts
+func NewGitHub(config *Config) Provider {
+	return &gitHub{
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     github.Endpoint,
+			Scopes:       config.Scopes,
+		},
+		userURL: gitHubUserURL,
+	}
+}
+
+// AuthCodeURL leaves nonce out, the state alone ties the callback to the login at GitHub
+func (p *gitHub) AuthCodeURL(state, _ string) string {
+	return p.oauth.AuthCodeURL(state)
+}
+
+func (p *gitHub) Exchange(ctx context.Context, code, _ string) (*Identity, error) {
+	token, err := p.oauth.Exchange(ctx, code)
+	if err != nil {
+		return nil, errors.Wrap(err, "Exchange()")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRequestWithContext()")
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	resp, err := p.oauth.Client(ctx, token).Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Do()")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("github user responded %d", resp.StatusCode)
+	}
+
+	user := &struct {
+		Id    int64  `json:"id"`
+		Login string `json:"login"`
+		Email string `json:"email"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(user); err != nil {
+		return nil, errors.Wrap(err, "Decode()")
+	}
+	if user.Id == 0 {
+		return nil, ErrInvalidIdentity
+	}
+	return &Identity{Subject: strconv.FormatInt(user.Id, 10), Username: user.Login, Email: user.Email}, nil
+}
@@ -0,0 +1,93 @@
+package oidc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StateTTL is how long a login at a provider may take
+const StateTTL = 10 * time.Minute
+
+var ErrInvalidState = errors.New("login state is not valid")
+
+// State is kept by a cookie from the redirect to the provider until its callback. Value is sent to the
+// provider which sends it back, UsrId is the user linking the identity or 0 when the user is logging in
+type State struct {
+	Provider string `json:"provider"`
+	Value    string `json:"value"`
+	UsrId    int    `json:"usr_id,omitempty"`
+	IssuedAt int64  `json:"iat"`
+}
+
+// Nonce returns the nonce the ID token of the login must carry, only the holder of the cookie can tell it
+func (st *State) Nonce() string {
+	sum := sha256.Sum256([]byte("nonce:" + st.Value))
+	return hex.EncodeToString(sum[:])
+}
+
+// StateCodec signs states into cookie values so that callbacks can trust them
+type StateCodec struct {
+	key []byte
+	now func() time.Time
+}
+
+// NewStateCodec create new StateCodec instance, every instance of the service must share the key
+func NewStateCodec(key []byte) *StateCodec {
+	return &StateCodec{key: key, now: time.Now}
+}
+
+// New returns a new state of a login at provider along with the cookie value keeping it
+func (c *StateCodec) New(provider string, usrId int) (*State, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", errors.Wrap(err, "Read()")
+	}
+	st := &State{
+		Provider: provider,
+		Value:    base64.RawURLEncoding.EncodeToString(b),
+		UsrId:    usrId,
+		IssuedAt: c.now().Unix(),
+	}
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Marshal()")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return st, encoded + "." + c.sign(encoded), nil
+}
+
+// Parse returns the state kept by cookie, it returns ErrInvalidState unless cookie is signed by the key,
+// keeps the state of value sent back by provider and has not expired
+func (c *StateCodec) Parse(provider, cookie, value string) (*State, error) {
+	parts := strings.Split(cookie, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(c.sign(parts[0]))) {
+		return nil, ErrInvalidState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	st := &State{}
+	if err := json.Unmarshal(payload, st); err != nil {
+		return nil, ErrInvalidState
+	}
+	issuedAt := time.Unix(st.IssuedAt, 0)
+	if st.Provider != provider || st.Value == "" || !hmac.Equal([]byte(st.Value), []byte(value)) || c.now().Sub(issuedAt) > StateTTL {
+		return nil, ErrInvalidState
+	}
+	return st, nil
+}
+
+func (c *StateCodec) sign(encoded string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,120 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinLen and MaxLen bound passwords in bytes, bcrypt ignores bytes after the 72nd
+	MinLen = 8
+	MaxLen = 72
+)
+
+// ErrWeak is returned by Validate for passwords which don't follow the policy
+var ErrWeak = errors.New("password must have 8 to 72 bytes with both letters and digits")
+
+// Validate returns ErrWeak unless pwd has MinLen to MaxLen bytes with both letters and digits
+func Validate(pwd string) error {
+	if len(pwd) < MinLen || len(pwd) > MaxLen {
+		return ErrWeak
+	}
+	var letter, digit bool
+	for _, r := range pwd {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	if !letter || !digit {
+		return ErrWeak
+	}
+	return nil
+}
+
+// argon2id parameters of new hashes, they follow the OWASP recommendation
+const (
+	argonTime    = 2
+	argonMemory  = 19 * 1024 // KiB
+	argonThreads = 1
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+var (
+	argonPrefix = "$argon2id$"
+	argonParams = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, argonMemory, argonTime, argonThreads)
+)
+
+// Hash returns the argon2id hash of the given plaintext password encoded as
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<key> with unpadded base64
+func Hash(pwd string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "Read()")
+	}
+	key := argon2.IDKey([]byte(pwd), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return argonPrefix + argonParams + "$" + base64.RawStdEncoding.EncodeToString(salt) + "$" + base64.RawStdEncoding.EncodeToString(key), nil
+}
+
+// Compare reports whether pwd matches the given hash, hashes are argon2id ones or legacy
+// bcrypt ones made by Hash before or by pgcrypto's crypt(pwd, gen_salt('bf'))
+func Compare(hash, pwd string) bool {
+	if !strings.HasPrefix(hash, argonPrefix) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pwd)) == nil
+	}
+
+	// $argon2id$v=19$m=...,t=...,p=...$salt$key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false
+	}
+	var version int
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+	derived := argon2.IDKey([]byte(pwd), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(derived, key) == 1
+}
+
+// missingHash is compared by CompareMissing, it's made once with the current parameters
+var (
+	missingHash     string
+	missingHashOnce sync.Once
+)
+
+// CompareMissing takes as long as Compare against an argon2id hash and reports false, storages call it for
+// usernames which don't exist so that logins of them can't be told apart from wrong passwords by timing
+func CompareMissing(pwd string) bool {
+	missingHashOnce.Do(func() {
+		missingHash, _ = Hash("missing user")
+	})
+	Compare(missingHash, pwd)
+	return false
+}
+
+// NeedsRehash reports whether hash is not an argon2id hash with the current parameters,
+// storages replace such hashes once the password has been verified
+func NeedsRehash(hash string) bool {
+	return !strings.HasPrefix(hash, argonPrefix+argonParams+"$")
+}
@@ -0,0 +1,50 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashCompare(t *testing.T) {
+	requireTest := require.New(t)
+
+	hash, err := Hash("s3cretpass")
+	requireTest.NoError(err)
+	requireTest.Contains(hash, "$argon2id$v=19$m=19456,t=2,p=1$")
+	requireTest.True(Compare(hash, "s3cretpass"))
+	requireTest.False(Compare(hash, "s3cretpasS"))
+	requireTest.False(NeedsRehash(hash))
+
+	other, err := Hash("s3cretpass")
+	requireTest.NoError(err)
+	requireTest.NotEqual(hash, other)
+
+	requireTest.False(Compare("$argon2id$v=19$m=19456,t=2,p=1$bad", "s3cretpass"))
+	requireTest.False(Compare("$argon2id$v=18$m=19456,t=2,p=1$c2FsdA$a2V5", "s3cretpass"))
+}
+
+func TestCompareMissing(t *testing.T) {
+	require.False(t, CompareMissing("missing user"))
+	require.False(t, CompareMissing("s3cretpass"))
+}
+
+func TestCompareLegacy(t *testing.T) {
+	requireTest := require.New(t)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("example"), bcrypt.MinCost)
+	requireTest.NoError(err)
+	requireTest.True(Compare(string(legacy), "example"))
+	requireTest.False(Compare(string(legacy), "Example"))
+	requireTest.True(NeedsRehash(string(legacy)))
+	requireTest.True(NeedsRehash("$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$a2V5"))
+}
+
+func TestValidate(t *testing.T) {
+	requireTest := require.New(t)
+	requireTest.NoError(Validate("s3cretpass"))
+	requireTest.Equal(ErrWeak, Validate("s3cret"))
+	requireTest.Equal(ErrWeak, Validate("secretpass"))
+	requireTest.Equal(ErrWeak, Validate("1234567890"))
+}
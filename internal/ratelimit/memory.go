@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// bucket holds tokens as of at
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// MemoryLimiter keeps buckets in memory, it only limits the requests of the instance it's in
+type MemoryLimiter struct {
+	rate Rate
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryLimiter create new MemoryLimiter instance
+func NewMemoryLimiter(rate Rate) *MemoryLimiter {
+	return &MemoryLimiter{
+		rate:    rate,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token out of the bucket of key, buckets full again are forgotten once per period
+func (l *MemoryLimiter) Allow(_ context.Context, key string) (Result, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= l.rate.Period {
+		for k, b := range l.buckets {
+			if l.rate.refill(b.tokens, now.Sub(b.at)) >= float64(l.rate.Limit) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.rate.Limit), at: now}
+		l.buckets[key] = b
+	}
+	b.tokens, b.at = l.rate.refill(b.tokens, now.Sub(b.at)), now
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return l.rate.result(allowed, b.tokens), nil
+}
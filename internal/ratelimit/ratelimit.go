@@ -0,0 +1,83 @@
+// Package ratelimit limits how often clients call the API by token buckets, every key has a bucket of
+// Rate.Limit tokens which refills at Rate.Limit tokens per Rate.Period and each request takes one
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is the size of buckets and how fast they refill
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseRate parses a rate such as 100/1m, Limit requests per Period
+func ParseRate(s string) (Rate, error) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return Rate{}, fmt.Errorf("ratelimit: rate %q is not limit/period", s)
+	}
+	limit, err := strconv.Atoi(s[:i])
+	if err != nil || limit <= 0 {
+		return Rate{}, fmt.Errorf("ratelimit: invalid limit of rate %q", s)
+	}
+	period, err := time.ParseDuration(s[i+1:])
+	if err != nil || period <= 0 {
+		return Rate{}, fmt.Errorf("ratelimit: invalid period of rate %q", s)
+	}
+	return Rate{Limit: limit, Period: period}, nil
+}
+
+// Result tells whether a request may go on and the state of its bucket afterwards. Reset is how long until the
+// bucket is full again and RetryAfter how long until the next request is allowed, zero while one is
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter takes a token out of the bucket of key. Keys of users and addresses are made by UserKey and AddrKey
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// UserKey returns the key of the bucket of the user of id
+func UserKey(id int) string {
+	return "user:" + strconv.Itoa(id)
+}
+
+// AddrKey returns the key of the bucket of the address
+func AddrKey(addr string) string {
+	return "addr:" + addr
+}
+
+// refill returns the tokens of a bucket which had tokens elapsed ago
+func (r Rate) refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += float64(r.Limit) * float64(elapsed) / float64(r.Period)
+	}
+	return math.Min(tokens, float64(r.Limit))
+}
+
+// result tells the state of a bucket left with tokens
+func (r Rate) result(allowed bool, tokens float64) Result {
+	perToken := float64(r.Period) / float64(r.Limit)
+	result := Result{
+		Allowed:   allowed,
+		Limit:     r.Limit,
+		Remaining: int(tokens),
+		Reset:     time.Duration(math.Ceil((float64(r.Limit) - tokens) * perToken)),
+	}
+	if tokens < 1 {
+		result.RetryAfter = time.Duration(math.Ceil((1 - tokens) * perToken))
+	}
+	return result
+}
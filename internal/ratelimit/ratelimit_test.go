@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("100/1m")
+	require.NoError(t, err)
+	require.Equal(t, Rate{Limit: 100, Period: time.Minute}, rate)
+
+	for _, s := range []string{"", "100", "0/1m", "x/1m", "100/", "100/-1s"} {
+		_, err := ParseRate(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	requireTest := require.New(t)
+	ctx := context.Background()
+	now := time.Date(2020, 7, 29, 0, 0, 0, 0, time.UTC)
+	l := NewMemoryLimiter(Rate{Limit: 2, Period: 2 * time.Second})
+	l.now = func() time.Time {
+		return now
+	}
+
+	result, err := l.Allow(ctx, UserKey(1))
+	requireTest.NoError(err)
+	requireTest.Equal(Result{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}, result)
+	result, _ = l.Allow(ctx, UserKey(1))
+	requireTest.Equal(Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}, result)
+	result, _ = l.Allow(ctx, UserKey(1))
+	requireTest.False(result.Allowed)
+	requireTest.Equal(time.Second, result.RetryAfter)
+
+	// buckets of other keys are full
+	result, _ = l.Allow(ctx, AddrKey("10.0.0.1"))
+	requireTest.True(result.Allowed)
+
+	// a token is back every second
+	now = now.Add(time.Second)
+	result, _ = l.Allow(ctx, UserKey(1))
+	requireTest.True(result.Allowed)
+	requireTest.Zero(result.Remaining)
+
+	// full buckets are forgotten
+	now = now.Add(time.Minute)
+	_, _ = l.Allow(ctx, UserKey(2))
+	requireTest.Len(l.buckets, 1)
+}
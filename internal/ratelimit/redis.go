@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+)
+
+// allowScript refills the bucket of a key and takes a token out of it if there is one, the bucket expires
+// once it would be full again. It returns 1 or 0 for whether the token was taken and the tokens left.
+// KEYS[1] bucket hash, ARGV[1] limit, ARGV[2] period in milliseconds, ARGV[3] unix time in milliseconds
+var allowScript = redis.NewScript(1, `
+	local limit = tonumber(ARGV[1])
+	local period = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+	local tokens = tonumber(bucket[1]) or limit
+	local at = tonumber(bucket[2]) or now
+	if now > at then
+		tokens = math.min(limit, tokens + (now - at) * limit / period)
+	end
+	local allowed = 0
+	if tokens >= 1 then
+		tokens = tokens - 1
+		allowed = 1
+	end
+	redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'at', now)
+	redis.call('PEXPIRE', KEYS[1], math.ceil((limit - tokens) * period / limit))
+	return {allowed, tostring(tokens)}
+`)
+
+// RedisLimiter keeps buckets in Redis so that all instances share them, instances are expected to have
+// synchronized clocks
+type RedisLimiter struct {
+	pool   *redis.Pool
+	prefix string
+	rate   Rate
+	now    func() time.Time
+}
+
+// NewRedisLimiter create new RedisLimiter instance, buckets are kept at keys starting with prefix
+func NewRedisLimiter(pool *redis.Pool, prefix string, rate Rate) *RedisLimiter {
+	return &RedisLimiter{pool: pool, prefix: prefix, rate: rate, now: time.Now}
+}
+
+// Allow takes a token out of the bucket of key
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	conn, err := l.pool.GetContext(ctx)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	nowMs := l.now().UnixNano() / int64(time.Millisecond)
+	values, err := redis.Values(allowScript.Do(conn, l.prefix+key, l.rate.Limit, int64(l.rate.Period/time.Millisecond), nowMs))
+	if err != nil {
+		return Result{}, errors.Wrap(err, "Do()")
+	}
+	var allowed int
+	var left string
+	if _, err := redis.Scan(values, &allowed, &left); err != nil {
+		return Result{}, errors.Wrap(err, "Scan()")
+	}
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return Result{}, errors.Wrap(err, "ParseFloat()")
+	}
+	return l.rate.result(allowed == 1, tokens), nil
+}
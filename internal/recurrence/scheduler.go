@@ -0,0 +1,63 @@
+package recurrence
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// DefaultInterval is how often recurring tasks are materialized by default
+const DefaultInterval = time.Minute
+
+// Scheduler periodically materializes next occurrences of recurring tasks,
+// several instances may run against the same storage
+type Scheduler struct {
+	recurrer storages.TaskRecurrer
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewScheduler create new Scheduler instance, non positive interval gives DefaultInterval
+func NewScheduler(recurrer storages.TaskRecurrer, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Scheduler{
+		recurrer: recurrer,
+		interval: interval,
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Run materializes recurring tasks once immediately and then every interval until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.materialize(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) materialize(ctx context.Context) {
+	n, err := s.recurrer.MaterializeRecurrences(ctx, s.now())
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Println("materializing recurring tasks failed", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("materialized %d recurring tasks", n)
+	}
+}
@@ -0,0 +1,43 @@
+package recurrence
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	now := time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	db := new(storages.StoreMock)
+	db.On("MaterializeRecurrences", ctx, now).Return(1, nil).Once()
+	db.On("MaterializeRecurrences", ctx, now).Return(0, nil).Run(func(_ mock.Arguments) {
+		cancel()
+	})
+
+	s := NewScheduler(db, time.Millisecond)
+	s.now = func() time.Time {
+		return now
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	db.AssertExpectations(t)
+}
+
+func TestNewSchedulerDefaultInterval(t *testing.T) {
+	require.Equal(t, DefaultInterval, NewScheduler(new(storages.StoreMock), 0).interval)
+}
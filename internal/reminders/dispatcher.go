@@ -0,0 +1,106 @@
+package reminders
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// DefaultInterval is how often due reminders are dispatched by default
+	DefaultInterval = 30 * time.Second
+	// DefaultLease is how long a claimed reminder is left to its dispatcher before it's claimed again
+	DefaultLease = 5 * time.Minute
+
+	// batchSize is how many reminders are claimed at once
+	batchSize = 100
+)
+
+// Notifier delivers the reminder of task to its user, e.g. by webhook, email or push
+type Notifier interface {
+	Notify(ctx context.Context, task *storages.Task) error
+}
+
+// NotifierFunc adapts a function to Notifier
+type NotifierFunc func(ctx context.Context, task *storages.Task) error
+
+func (f NotifierFunc) Notify(ctx context.Context, task *storages.Task) error {
+	return f(ctx, task)
+}
+
+// LogNotifier only logs reminders, it's used when no other notifier is configured
+var LogNotifier = NotifierFunc(func(_ context.Context, task *storages.Task) error {
+	log.Printf("reminder of task %d of user %d: %s", task.Id, task.UsrId, task.Content)
+	return nil
+})
+
+// Dispatcher periodically delivers due reminders by its notifier. Reminders are persisted by the
+// storage and leased while delivered so they survive restarts and several instances may run against
+// the same storage, a reminder is delivered at least once
+type Dispatcher struct {
+	reminder storages.TaskReminder
+	notifier Notifier
+	interval time.Duration
+	lease    time.Duration
+	now      func() time.Time
+}
+
+// NewDispatcher create new Dispatcher instance, non positive interval gives DefaultInterval
+func NewDispatcher(reminder storages.TaskReminder, notifier Notifier, interval time.Duration) *Dispatcher {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Dispatcher{
+		reminder: reminder,
+		notifier: notifier,
+		interval: interval,
+		lease:    DefaultLease,
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Run dispatches due reminders once immediately and then every interval until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch delivers claimed reminders batch by batch, failed ones are retried once their lease expires
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	for {
+		tasks, err := d.reminder.ClaimReminders(ctx, d.now(), d.lease, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Println("claiming reminders failed", err)
+			}
+			return
+		}
+
+		for _, task := range tasks {
+			if err := d.notifier.Notify(ctx, task); err != nil {
+				log.Println("delivering reminder of task", task.Id, "failed", err)
+				continue
+			}
+			if err := d.reminder.AckReminder(ctx, task.Id, *task.RemindAt); err != nil {
+				log.Println("acknowledging reminder of task", task.Id, "failed", err)
+			}
+		}
+		if len(tasks) < batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package reminders
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDispatcherRun(t *testing.T) {
+	now := time.Date(2020, 6, 30, 9, 0, 0, 0, time.UTC)
+	remindAt := now.Add(-time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	db := new(storages.StoreMock)
+	tasks := []*storages.Task{{Id: 1, RemindAt: &remindAt}, {Id: 2, RemindAt: &remindAt}}
+	db.On("ClaimReminders", ctx, now, DefaultLease, batchSize).Return(tasks, nil).Once()
+	db.On("ClaimReminders", ctx, now, DefaultLease, batchSize).Return([]*storages.Task{}, nil).Run(func(_ mock.Arguments) {
+		cancel()
+	})
+	// the failed reminder is not acknowledged so it's claimed again after its lease
+	db.On("AckReminder", ctx, 2, remindAt).Return(nil).Once()
+
+	notified := make([]int, 0)
+	notifier := NotifierFunc(func(_ context.Context, task *storages.Task) error {
+		notified = append(notified, task.Id)
+		if task.Id == 1 {
+			return errors.New("unreachable")
+		}
+		return nil
+	})
+
+	d := NewDispatcher(db, notifier, time.Millisecond)
+	d.now = func() time.Time {
+		return now
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatcher did not stop")
+	}
+	require.Equal(t, []int{1, 2}, notified)
+	db.AssertExpectations(t)
+}
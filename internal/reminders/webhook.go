@@ -0,0 +1,75 @@
+package reminders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// SignatureHeader holds the hex HMAC-SHA256 of the body of webhook requests keyed by the secret
+const SignatureHeader = "X-Togo-Signature"
+
+// webhookTimeout bounds each delivery so a slow endpoint can't hold up other reminders
+const webhookTimeout = 10 * time.Second
+
+// webhookBody is posted to the webhook for each reminder
+type webhookBody struct {
+	Event string         `json:"event"`
+	Task  *storages.Task `json:"task"`
+}
+
+// Webhook posts reminders as JSON to URL, a non 2xx response fails the delivery
+type Webhook struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhook create new Webhook instance, requests are signed when secret is not empty
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (w *Webhook) Notify(ctx context.Context, task *storages.Task) error {
+	body, err := json.Marshal(&webhookBody{Event: "task.reminder", Task: task})
+	if err != nil {
+		return errors.Wrap(err, "Marshal()")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "NewRequestWithContext()")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Do()")
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
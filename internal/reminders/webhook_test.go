@@ -0,0 +1,37 @@
+package reminders
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	requireTest := require.New(t)
+
+	var body []byte
+	var signature string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		body, _ = ioutil.ReadAll(req.Body)
+		signature = req.Header.Get(SignatureHeader)
+		resp.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "secret")
+	requireTest.NoError(webhook.Notify(context.Background(), &storages.Task{Id: 1, Content: "call mom"}))
+	requireTest.Contains(string(body), `"event":"task.reminder"`)
+	requireTest.Equal(sign([]byte("secret"), body), signature)
+
+	status = http.StatusInternalServerError
+	requireTest.Error(webhook.Notify(context.Background(), &storages.Task{Id: 1}))
+
+	status = http.StatusOK
+	requireTest.NoError(NewWebhook(server.URL, "").Notify(context.Background(), &storages.Task{Id: 1}))
+	requireTest.Empty(signature)
+}
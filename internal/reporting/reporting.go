@@ -0,0 +1,132 @@
+// Package reporting reports errors to a service which collects them, such as Sentry. Errors reach reporters by
+// the logs: NewCore is teed to the logger of the service and reports what is logged at error level or above,
+// along with the request id, user and route the logger carries
+package reporting
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Event is an error to report
+type Event struct {
+	Time time.Time
+	// Level is one of debug, info, warning, error and fatal
+	Level   string
+	Message string
+	// Error and ErrorType are the message and the type of the error logged with the event, if one was
+	Error     string
+	ErrorType string
+	// Stack is the stack the error was raised at, of the goroutine which panicked for panics
+	Stack  string
+	UserID string
+	// Tags are values events are searched by, such as the request id, Extra anything else logged with the event
+	Tags  map[string]string
+	Extra map[string]interface{}
+}
+
+// Reporter reports events, Report must not block the caller on the network
+type Reporter interface {
+	Report(event *Event)
+	// Flush waits up to timeout for the events reported so far to be sent, it reports whether they all were
+	Flush(timeout time.Duration) bool
+}
+
+// tagFields are the fields of logs kept as tags of events, the others are kept as extra
+var tagFields = map[string]bool{
+	"request_id": true,
+	"method":     true,
+	"path":       true,
+	"status":     true,
+	"statement":  true,
+}
+
+// core reports entries of the logs it's enabled for with the fields of its logger
+type core struct {
+	zapcore.LevelEnabler
+	reporter Reporter
+	fields   []zapcore.Field
+}
+
+// NewCore returns a core reporting the entries enabled by level to reporter, it's meant to be teed to the core
+// of a logger by zap.WrapCore
+func NewCore(reporter Reporter, level zapcore.LevelEnabler) zapcore.Core {
+	return &core{LevelEnabler: level, reporter: reporter}
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	with := *c
+	with.fields = append(append(make([]zapcore.Field, 0, len(c.fields)+len(fields)), c.fields...), fields...)
+	return &with
+}
+
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	c.reporter.Report(newEvent(entry, append(append([]zapcore.Field{}, c.fields...), fields...)))
+	return nil
+}
+
+func (c *core) Sync() error {
+	return nil
+}
+
+// newEvent returns the event of a log entry, the error and stack fields are its error and stack
+func newEvent(entry zapcore.Entry, fields []zapcore.Field) *Event {
+	enc := zapcore.NewMapObjectEncoder()
+	event := &Event{
+		Time:    entry.Time,
+		Level:   level(entry.Level),
+		Message: entry.Message,
+		Stack:   entry.Stack,
+		Tags:    make(map[string]string),
+		Extra:   make(map[string]interface{}),
+	}
+	for _, field := range fields {
+		if field.Type == zapcore.ErrorType && field.Key == "error" {
+			if err, ok := field.Interface.(error); ok {
+				event.ErrorType = fmt.Sprintf("%T", err)
+			}
+		}
+		field.AddTo(enc)
+	}
+
+	for key, value := range enc.Fields {
+		switch {
+		case key == "error":
+			event.Error = fmt.Sprint(value)
+		case key == "stack":
+			event.Stack = fmt.Sprint(value)
+		case key == "user_id":
+			event.UserID = fmt.Sprint(value)
+		case tagFields[key]:
+			event.Tags[key] = fmt.Sprint(value)
+		default:
+			event.Extra[key] = value
+		}
+	}
+	return event
+}
+
+// level returns the name events have for l
+func level(l zapcore.Level) string {
+	switch {
+	case l < zapcore.InfoLevel:
+		return "debug"
+	case l == zapcore.InfoLevel:
+		return "info"
+	case l == zapcore.WarnLevel:
+		return "warning"
+	case l == zapcore.ErrorLevel:
+		return "error"
+	default:
+		return "fatal"
+	}
+}
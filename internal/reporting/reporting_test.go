@@ -0,0 +1,52 @@
+package reporting
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// recorder keeps the events reported to it
+type recorder struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (r *recorder) Report(event *Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) Flush(time.Duration) bool {
+	return true
+}
+
+func TestCore(t *testing.T) {
+	requireTest := require.New(t)
+	r := &recorder{}
+	logger := zap.New(NewCore(r, zapcore.ErrorLevel)).With(zap.String("request_id", "id"), zap.Int("user_id", 7))
+
+	logger.Warn("slow postgres statement")
+	requireTest.Empty(r.events)
+
+	logger.Error("request", zap.String("method", "GET"), zap.Int("status", 500), zap.Error(errors.New("connection refused")), zap.Int("attempts", 3))
+	requireTest.Len(r.events, 1)
+	event := r.events[0]
+	requireTest.Equal("error", event.Level)
+	requireTest.Equal("request", event.Message)
+	requireTest.Equal("connection refused", event.Error)
+	requireTest.Equal("*errors.fundamental", event.ErrorType)
+	requireTest.Equal("7", event.UserID)
+	requireTest.Equal(map[string]string{"request_id": "id", "method": "GET", "status": "500"}, event.Tags)
+	requireTest.Equal(int64(3), event.Extra["attempts"])
+	requireTest.Contains(event.Extra, "errorVerbose")
+
+	logger.Error("request", zap.String("stack", "goroutine 1"))
+	requireTest.Equal("goroutine 1", r.events[1].Stack)
+}
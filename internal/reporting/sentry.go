@@ -0,0 +1,213 @@
+package reporting
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sentryQueueSize is how many events wait to be sent at most, events reported while it's full are dropped
+const sentryQueueSize = 100
+
+// SentryConfig tells where and as what events are sent
+type SentryConfig struct {
+	// DSN is the client key of the project such as https://key@o1.ingest.sentry.io/2
+	DSN string
+	// Release and Environment tag events with the version and the deployment of the service
+	Release     string
+	Environment string
+	ServerName  string
+	Timeout     time.Duration
+}
+
+// Sentry sends events to the store endpoint of a project of Sentry or of a service compatible with it,
+// one at a time in the background
+type Sentry struct {
+	config   SentryConfig
+	endpoint string
+	auth     string
+	client   *http.Client
+
+	queue   chan *Event
+	pending sync.WaitGroup
+}
+
+// NewSentry create new Sentry instance which sends events to the project of the DSN of config
+func NewSentry(config SentryConfig) (*Sentry, error) {
+	endpoint, auth, err := parseDSN(config.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	s := &Sentry{
+		config:   config,
+		endpoint: endpoint,
+		auth:     auth,
+		client:   &http.Client{Timeout: config.Timeout},
+		queue:    make(chan *Event, sentryQueueSize),
+	}
+	go s.send()
+	return s, nil
+}
+
+// parseDSN returns the store endpoint of the project of dsn and the X-Sentry-Auth header of its key
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User == nil || u.User.Username() == "" {
+		return "", "", fmt.Errorf("reporting: invalid sentry DSN")
+	}
+	i := strings.LastIndex(u.Path, "/")
+	project := u.Path[i+1:]
+	if i < 0 || project == "" {
+		return "", "", fmt.Errorf("reporting: sentry DSN has no project")
+	}
+
+	auth := "Sentry sentry_version=7, sentry_client=togo/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path[:i] + "/api/" + project + "/store/"}
+	return endpoint.String(), auth, nil
+}
+
+// Report queues event to be sent, it's dropped if the queue is full
+func (s *Sentry) Report(event *Event) {
+	s.pending.Add(1)
+	select {
+	case s.queue <- event:
+	default:
+		s.pending.Done()
+		log.Println("reporting: queue full, event dropped")
+	}
+}
+
+// Flush waits up to timeout for the queue to be sent
+func (s *Sentry) Flush(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (s *Sentry) send() {
+	for event := range s.queue {
+		// logging failures here at error level would report them again
+		if err := s.post(event); err != nil {
+			log.Println("reporting:", err)
+		}
+		s.pending.Done()
+	}
+}
+
+// sentryEvent is the payload of an event of the store endpoint
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger"`
+	Message     string                 `json:"message,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+	User        *sentryUser            `json:"user,omitempty"`
+	Exception   *sentryExceptions      `json:"exception,omitempty"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// newSentryEvent returns the payload of event, the stack of which is sent as extra
+func (s *Sentry) newSentryEvent(event *Event) (*sentryEvent, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	payload := &sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   event.Time.UTC().Format(time.RFC3339Nano),
+		Level:       event.Level,
+		Platform:    "go",
+		Logger:      "togo",
+		Message:     event.Message,
+		Release:     s.config.Release,
+		Environment: s.config.Environment,
+		ServerName:  s.config.ServerName,
+		Tags:        event.Tags,
+		Extra:       event.Extra,
+	}
+	if event.UserID != "" {
+		payload.User = &sentryUser{ID: event.UserID}
+	}
+	if event.Error != "" {
+		payload.Exception = &sentryExceptions{Values: []sentryException{{Type: event.ErrorType, Value: event.Error}}}
+	}
+	if event.Stack != "" {
+		extra := make(map[string]interface{}, len(event.Extra)+1)
+		for k, v := range event.Extra {
+			extra[k] = v
+		}
+		extra["stack"] = event.Stack
+		payload.Extra = extra
+	}
+	return payload, nil
+}
+
+func (s *Sentry) post(event *Event) error {
+	payload, err := s.newSentryEvent(event)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry answered %s", resp.Status)
+	}
+	return nil
+}
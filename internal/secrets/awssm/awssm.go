@@ -0,0 +1,50 @@
+package awssm
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/manabie-com/togo/internal/secrets"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	secrets.Register("awssm", func(ctx context.Context, ref string) (string, error) {
+		sess, err := session.NewSession()
+		if err != nil {
+			return "", errors.Wrap(err, "NewSession()")
+		}
+		return Secret(ctx, secretsmanager.New(sess), ref)
+	})
+}
+
+// Secret returns the secret of ref "id" or "id#field" of AWS Secrets Manager, the field of a secret of
+// JSON key/value pairs. AWS credentials and region are taken from the environment
+func Secret(ctx context.Context, client secretsmanageriface.SecretsManagerAPI, ref string) (string, error) {
+	id, field := secrets.SplitRef(ref)
+	if id == "" {
+		return "", errors.Errorf("invalid aws secret %q", ref)
+	}
+
+	out, err := client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", errors.Wrap(err, "GetSecretValue()")
+	}
+	value := aws.StringValue(out.SecretString)
+	if out.SecretString == nil {
+		value = string(out.SecretBinary)
+	}
+	if field == "" {
+		return value, nil
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", errors.Wrap(err, "Unmarshal()")
+	}
+	return secrets.Field(fields, field)
+}
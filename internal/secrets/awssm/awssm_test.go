@@ -0,0 +1,48 @@
+package awssm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	secretsmanageriface.SecretsManagerAPI
+	secrets map[string]string
+}
+
+func (c *fakeClient) GetSecretValueWithContext(_ aws.Context, input *secretsmanager.GetSecretValueInput,
+	_ ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	value, ok := c.secrets[aws.StringValue(input.SecretId)]
+	if !ok {
+		return nil, &secretsmanager.ResourceNotFoundException{}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil
+}
+
+func TestSecret(t *testing.T) {
+	requireTest := require.New(t)
+	client := &fakeClient{secrets: map[string]string{
+		"togo/jwt": "key",
+		"togo/db":  `{"username": "togo", "password": "s3cret"}`,
+	}}
+
+	secret, err := Secret(context.Background(), client, "togo/jwt")
+	requireTest.NoError(err)
+	requireTest.Equal("key", secret)
+	secret, err = Secret(context.Background(), client, "togo/db#password")
+	requireTest.NoError(err)
+	requireTest.Equal("s3cret", secret)
+
+	_, err = Secret(context.Background(), client, "togo/db#port")
+	requireTest.Error(err)
+	_, err = Secret(context.Background(), client, "togo/jwt#key")
+	requireTest.Error(err)
+	_, err = Secret(context.Background(), client, "togo/missing")
+	requireTest.Error(err)
+}
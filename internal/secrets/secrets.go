@@ -0,0 +1,113 @@
+// Package secrets reads secrets such as passwords and signing keys configured by env vars. The secret of KEY
+// is read from the file of KEY_FILE when it's set, e.g. a mounted Docker or Kubernetes secret, or else KEY is
+// the secret itself or a reference "scheme:ref" to it which is resolved by the resolver registered for scheme.
+// "file:/path" references are always known, external managers register their schemes from init()
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ResolveFunc returns the secret of ref, the reference without its scheme
+type ResolveFunc func(ctx context.Context, ref string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]ResolveFunc{"file": readFile}
+)
+
+// Register makes a resolver of secrets available by the provided scheme,
+// it's meant to be called from init() of the resolver package
+func Register(scheme string, resolve ResolveFunc) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	if resolve == nil {
+		panic("secrets: Register resolve func is nil")
+	}
+	if _, dup := resolvers[scheme]; dup {
+		panic("secrets: Register called twice for scheme " + scheme)
+	}
+	resolvers[scheme] = resolve
+}
+
+// Schemes returns a sorted list of the registered schemes
+func Schemes() []string {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+
+	schemes := make([]string, 0, len(resolvers))
+	for scheme := range resolvers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Lookup returns the secret of env key, defaultVal is used when neither key nor key_FILE is set.
+// Values which don't start with a registered scheme are returned as is
+func Lookup(ctx context.Context, key, defaultVal string) (string, error) {
+	if file, ok := os.LookupEnv(key + "_FILE"); ok && file != "" {
+		secret, err := readFile(ctx, file)
+		return secret, errors.Wrap(err, key+"_FILE")
+	}
+
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		value = defaultVal
+	}
+	secret, err := Resolve(ctx, value)
+	return secret, errors.Wrap(err, key)
+}
+
+// Resolve returns the secret value refers to, value itself unless it starts with a registered scheme
+func Resolve(ctx context.Context, value string) (string, error) {
+	i := strings.Index(value, ":")
+	if i <= 0 {
+		return value, nil
+	}
+	resolversMu.RLock()
+	resolve, ok := resolvers[value[:i]]
+	resolversMu.RUnlock()
+	if !ok {
+		return value, nil
+	}
+	return resolve(ctx, value[i+1:])
+}
+
+// SplitRef splits ref into the name of a secret and a field of it, e.g. "togo/db#password"
+func SplitRef(ref string) (name, field string) {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Field returns field of the fields of a secret as a string
+func Field(fields map[string]interface{}, field string) (string, error) {
+	value, ok := fields[field]
+	if !ok {
+		return "", errors.Errorf("secret has no field %q", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// readFile returns the content of the file at path without its trailing newline
+func readFile(_ context.Context, path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "ReadFile()")
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	requireTest := require.New(t)
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "secrets")
+	requireTest.NoError(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "password")
+	requireTest.NoError(ioutil.WriteFile(file, []byte("from file\n"), 0o600))
+
+	secret, err := Lookup(ctx, "TOGO_TEST_SECRET", "default")
+	requireTest.NoError(err)
+	requireTest.Equal("default", secret)
+
+	os.Setenv("TOGO_TEST_SECRET", "plain")
+	defer os.Unsetenv("TOGO_TEST_SECRET")
+	secret, err = Lookup(ctx, "TOGO_TEST_SECRET", "default")
+	requireTest.NoError(err)
+	requireTest.Equal("plain", secret)
+
+	os.Setenv("TOGO_TEST_SECRET", "file:"+file)
+	secret, err = Lookup(ctx, "TOGO_TEST_SECRET", "")
+	requireTest.NoError(err)
+	requireTest.Equal("from file", secret)
+
+	// unknown schemes are values such as DSNs
+	os.Setenv("TOGO_TEST_SECRET", "postgres://togo@localhost/togo")
+	secret, err = Lookup(ctx, "TOGO_TEST_SECRET", "")
+	requireTest.NoError(err)
+	requireTest.Equal("postgres://togo@localhost/togo", secret)
+
+	os.Setenv("TOGO_TEST_SECRET_FILE", file)
+	defer os.Unsetenv("TOGO_TEST_SECRET_FILE")
+	secret, err = Lookup(ctx, "TOGO_TEST_SECRET", "")
+	requireTest.NoError(err)
+	requireTest.Equal("from file", secret)
+
+	os.Setenv("TOGO_TEST_SECRET_FILE", filepath.Join(dir, "missing"))
+	_, err = Lookup(ctx, "TOGO_TEST_SECRET", "")
+	requireTest.Error(err)
+}
+
+func TestSplitRef(t *testing.T) {
+	name, field := SplitRef("secret/data/togo#jwt_key")
+	require.Equal(t, "secret/data/togo", name)
+	require.Equal(t, "jwt_key", field)
+	name, field = SplitRef("togo/db")
+	require.Equal(t, "togo/db", name)
+	require.Equal(t, "", field)
+}
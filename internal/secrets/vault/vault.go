@@ -0,0 +1,89 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/secrets"
+	"github.com/manabie-com/togo/internal/util"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	secrets.Register("vault", func(ctx context.Context, ref string) (string, error) {
+		token, err := secrets.Lookup(ctx, "VAULT_TOKEN", "")
+		if err != nil {
+			return "", err
+		}
+		v := NewVault(&Config{
+			Addr:      util.GetEnv("VAULT_ADDR", "http://127.0.0.1:8200"),
+			Token:     token,
+			Namespace: util.GetEnv("VAULT_NAMESPACE", ""),
+		})
+		return v.Secret(ctx, ref)
+	})
+}
+
+type Config struct {
+	Addr      string
+	Token     string
+	Namespace string
+}
+
+// Vault reads secrets of the KV engines of a Vault server
+type Vault struct {
+	config *Config
+	client *http.Client
+}
+
+// NewVault create new Vault instance
+func NewVault(config *Config) *Vault {
+	return &Vault{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Secret returns the field of the secret at path of ref "path#field", e.g. "secret/data/togo#jwt_key".
+// Paths of version 2 engines include "data/"
+func (v *Vault) Secret(ctx context.Context, ref string) (string, error) {
+	path, field := secrets.SplitRef(ref)
+	if path == "" || field == "" {
+		return "", errors.Errorf("invalid vault secret %q", ref)
+	}
+
+	url := strings.TrimSuffix(v.config.Addr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "NewRequest()")
+	}
+	req.Header.Set("X-Vault-Token", v.config.Token)
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "Do()")
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("vault answered %s for %q", resp.Status, path)
+	}
+
+	body := &struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(body); err != nil {
+		return "", errors.Wrap(err, "Decode()")
+	}
+	// version 2 engines nest the fields along with metadata
+	if fields, ok := body.Data["data"].(map[string]interface{}); ok {
+		if _, ok := body.Data["metadata"]; ok {
+			return secrets.Field(fields, field)
+		}
+	}
+	return secrets.Field(body.Data, field)
+}
@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultSecret(t *testing.T) {
+	requireTest := require.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "token" {
+			resp.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch req.URL.Path {
+		case "/v1/secret/data/togo":
+			_, _ = resp.Write([]byte(`{"data": {"data": {"jwt_key": "v2 key"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/togo":
+			_, _ = resp.Write([]byte(`{"data": {"jwt_key": "v1 key"}}`))
+		default:
+			resp.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	v := NewVault(&Config{Addr: server.URL, Token: "token"})
+	secret, err := v.Secret(context.Background(), "secret/data/togo#jwt_key")
+	requireTest.NoError(err)
+	requireTest.Equal("v2 key", secret)
+	secret, err = v.Secret(context.Background(), "kv/togo#jwt_key")
+	requireTest.NoError(err)
+	requireTest.Equal("v1 key", secret)
+
+	_, err = v.Secret(context.Background(), "kv/togo#password")
+	requireTest.Error(err)
+	_, err = v.Secret(context.Background(), "kv/missing#jwt_key")
+	requireTest.Error(err)
+	_, err = v.Secret(context.Background(), "kv/togo")
+	requireTest.Error(err)
+	_, err = NewVault(&Config{Addr: server.URL}).Secret(context.Background(), "kv/togo#jwt_key")
+	requireTest.Error(err)
+}
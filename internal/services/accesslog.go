@@ -0,0 +1,50 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/manabie-com/togo/internal/accesslog"
+	"go.uber.org/zap"
+)
+
+// WithAccessLog writes a line for every request to l besides the request logs
+func WithAccessLog(l *accesslog.Logger) Option {
+	return func(s *ToDoService) {
+		s.accessLog = l
+	}
+}
+
+// accessLogHandler writes the line of every request next serves to the access log. Queries are left out of
+// the URI as those of logins carry codes, and so are tokens of share links
+func (s *ToDoService) accessLogHandler(next http.Handler) http.Handler {
+	if s.accessLog == nil {
+		return next
+	}
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		w := &statusWriter{ResponseWriter: resp, status: http.StatusOK}
+		next.ServeHTTP(w, req)
+
+		entry := &accesslog.Entry{
+			Time:       start,
+			RemoteAddr: clientIP(req),
+			Method:     req.Method,
+			URI:        logPath(req.URL.Path),
+			Proto:      req.Proto,
+			Status:     w.status,
+			Size:       w.size,
+			Referer:    req.Referer(),
+			UserAgent:  req.UserAgent(),
+			Duration:   time.Since(start),
+			RequestID:  resp.Header().Get(requestIDHeader),
+		}
+		if usr, ok := req.Context().Value(requestUserKey{}).(*requestUser); ok && usr.id != 0 {
+			entry.User = strconv.Itoa(usr.id)
+		}
+		if err := s.accessLog.Log(entry); err != nil {
+			s.log().Warn("writing access log", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/accesslog"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAccessLog(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	var buf bytes.Buffer
+	l, err := accesslog.New(&buf, accesslog.FormatJSON)
+	requireTest.NoError(err)
+	s := NewToDoService(testJWTKey, ":6000", m, WithLogger(zap.NewNop()), WithAccessLog(l))
+	token, err := s.createToken(&storages.User{Id: 1, MaxTodo: 5})
+	requireTest.NoError(err)
+
+	req := httptest.NewRequest("GET", "/v1/projects?x=1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", "curl")
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	requireTest.Equal(http.StatusOK, w.Code)
+
+	requireTest.Contains(buf.String(), `"user":"1","method":"GET","uri":"/v1/projects","proto":"HTTP/1.1","status":200,`)
+	requireTest.Contains(buf.String(), `"size":`+strconv.Itoa(w.Body.Len())+`,"user_agent":"curl"`)
+	requireTest.Contains(buf.String(), `"request_id":"`+w.Header().Get(requestIDHeader)+`"`)
+
+	// probes are not logged
+	buf.Reset()
+	s.server.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	requireTest.Empty(buf.String())
+}
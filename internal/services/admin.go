@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// userResult is a user as seen by admins, passwords hashes are never returned
+type userResult struct {
+	Id       int           `json:"id"`
+	Username string        `json:"username"`
+	MaxTodo  int           `json:"max_todo"`
+	Role     storages.Role `json:"role"`
+	Plan     string        `json:"plan,omitempty"`
+}
+
+func newUserResult(usr *storages.User) userResult {
+	role := usr.Role
+	if role == "" {
+		role = storages.RoleUser
+	}
+	return userResult{Id: usr.Id, Username: usr.Username, MaxTodo: usr.MaxTodo, Role: role, Plan: usr.Plan}
+}
+
+// adminUsersHandler lists all users at GET /admin/users
+func (s *ToDoService) adminUsersHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	admin, ok := s.store.(storages.UserAdmin)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	users, err := admin.ListUsers(req.Context())
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	results := make([]userResult, 0, len(users))
+	for _, usr := range users {
+		results = append(results, newUserResult(usr))
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(results)); err != nil {
+		log.Println(err)
+	}
+}
+
+// adminUserHandler changes the role and the daily-limit of a user at PATCH /admin/users/{id},
+// the changes apply to tokens issued afterwards. Quota resets and plans are handled by adminQuotaResetHandler
+// and adminUserPlanHandler
+func (s *ToDoService) adminUserHandler(resp http.ResponseWriter, req *http.Request) {
+	switch {
+	case strings.HasSuffix(req.URL.Path, quotaResetSuffix):
+		s.adminQuotaResetHandler(resp, req)
+		return
+	case strings.HasSuffix(req.URL.Path, userPlanSuffix):
+		s.adminUserPlanHandler(resp, req)
+		return
+	}
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	id, action, ok := parseItemPath("/admin/users/", req.URL.Path)
+	if !ok || action != "" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodPatch {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	admin, ok := s.store.(storages.UserAdmin)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	patch := &storages.UserPatch{}
+	if !decodeBody(resp, req, patch, maxJsonSize) {
+		return
+	}
+
+	usr, err := admin.UpdateUser(req.Context(), id, patch)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	actorId, _ := userIDFromCtx(req.Context())
+	if patch.Role != nil {
+		s.audit(req, storages.AuditRoleChanged, actorId, id, "role="+string(*patch.Role))
+	}
+	if patch.MaxTodo != nil {
+		s.audit(req, storages.AuditQuotaChanged, actorId, id, "max_todo="+strconv.Itoa(*patch.MaxTodo))
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(newUserResult(usr))); err != nil {
+		log.Println(err)
+	}
+}
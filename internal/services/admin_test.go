@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminRequest(t *testing.T, s *ToDoService, method, path, body string, role storages.Role) *http.Request {
+	token, err := s.createToken(&storages.User{Id: 1, MaxTodo: 5, Role: role})
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestAdminOnly(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	w := httptest.NewRecorder()
+	s.adminHandler(s.adminUsersHandler)(w, newAdminRequest(t, s, "GET", "/admin/users", "", storages.RoleUser))
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminUsersHandler)(w, httptest.NewRequest("GET", "/admin/users", nil))
+	requireTest.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+
+	db.AssertNotCalled(t, "ListUsers", mock.Anything)
+}
+
+func TestAdminUsers(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	db.On("ListUsers", mock.Anything).Return([]*storages.User{
+		{Id: 1, Username: "firstUser", MaxTodo: 5, Role: storages.RoleAdmin},
+		{Id: 2, Username: "legacy", MaxTodo: 5},
+	}, nil)
+	w := httptest.NewRecorder()
+	s.adminHandler(s.adminUsersHandler)(w, newAdminRequest(t, s, "GET", "/admin/users", "", storages.RoleAdmin))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	requireTest.JSONEq(`{"data": [
+		{"id": 1, "username": "firstUser", "max_todo": 5, "role": "admin"},
+		{"id": 2, "username": "legacy", "max_todo": 5, "role": "user"}
+	]}`, w.Body.String())
+
+	maxTodo := 10
+	db.On("UpdateUser", mock.Anything, 2, &storages.UserPatch{MaxTodo: &maxTodo}).Return(&storages.User{Id: 2, Username: "legacy", MaxTodo: 10}, nil)
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminUserHandler)(w, newAdminRequest(t, s, "PATCH", "/admin/users/2", `{"max_todo": 10}`, storages.RoleAdmin))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	requireTest.JSONEq(`{"data": {"id": 2, "username": "legacy", "max_todo": 10, "role": "user"}}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminUserHandler)(w, newAdminRequest(t, s, "PATCH", "/admin/users/2", `{"role": "root"}`, storages.RoleAdmin))
+	requireTest.Equal(http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
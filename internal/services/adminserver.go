@@ -0,0 +1,61 @@
+package services
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// WithTLS serves the API over TLS by tlsConfig, the gRPC API too when it's served at the same port
+func WithTLS(tlsConfig *tls.Config) Option {
+	return func(s *ToDoService) {
+		s.server.TLSConfig = tlsConfig
+	}
+}
+
+// WithAdminServer serves /healthz, /readyz, /version, /metrics, the profiles of WithDebugServer and the admin API
+// (/admin/ and /users/{id}, under /v1 or not) on addr, over TLS unless tlsConfig is nil, so that the port of the
+// API never exposes them. The admin API still requires the tokens of admins, the profiles don't
+func WithAdminServer(addr string, tlsConfig *tls.Config) Option {
+	return func(s *ToDoService) {
+		s.adminServer = &http.Server{
+			Addr:              addr,
+			TLSConfig:         tlsConfig,
+			ReadHeaderTimeout: DefaultReadHeaderTimeout,
+			IdleTimeout:       DefaultIdleTimeout,
+		}
+	}
+}
+
+// adminRoutes returns the routes of the admin server. Its requests are traced, measured, bounded, logged and
+// recovered like those of the API but they are neither rate limited nor answered CORS headers, browsers have no
+// business there. The server has no write timeout as CPU profiles and traces take as long as they're asked
+func (s *ToDoService) adminRoutes() http.Handler {
+	routes := http.NewServeMux()
+	s.handleAdminRoutes(routes)
+	routes.HandleFunc("/", s.setHeaders(notFoundHandler))
+	v1 := s.traceHandler(routes, s.metricsHandler(routes, s.limitsHandler(routes)))
+
+	api := http.NewServeMux()
+	api.Handle("/v1/", mountVersion("/v1", v1))
+	api.Handle("/", s.legacyHandler("/v1", v1))
+
+	mux := debugRoutes()
+	s.handleProbes(mux)
+	mux.Handle("/", s.requestLogHandler(s.accessLogHandler(recoverHandler(api))))
+	return mux
+}
+
+// serveAdmin serves the admin server until Shutdown, failures are reported by HttpServerErr
+func (s *ToDoService) serveAdmin() {
+	go func() {
+		var err error
+		if s.adminServer.TLSConfig != nil {
+			err = s.adminServer.ListenAndServeTLS("", "")
+		} else {
+			err = s.adminServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			s.reportServerErr(err)
+		}
+	}()
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/metrics"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminServer(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	s := NewToDoService(testJWTKey, "127.0.0.1:0", m, WithMetrics(metrics.New()), WithAdminServer("127.0.0.1:0", nil))
+	token, err := s.createToken(&storages.User{Id: 1, Role: storages.RoleAdmin})
+	requireTest.NoError(err)
+	do := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/healthz", "/readyz", "/version", "/metrics", "/debug/vars", "/admin/users", "/v1/admin/users"} {
+		w := do(s.adminServer.Handler, path)
+		requireTest.Equal(http.StatusOK, w.Code, path)
+		requireTest.Empty(w.Header().Get("Access-Control-Allow-Origin"), "no CORS at the admin server")
+	}
+	requireTest.Equal(http.StatusNotFound, do(s.adminServer.Handler, "/v1/tasks").Code, "the API isn't served there")
+
+	// the API doesn't serve them, it still tells its build
+	for _, path := range []string{"/healthz", "/readyz", "/metrics", "/debug/vars", "/admin/users", "/v1/admin/users"} {
+		requireTest.Equal(http.StatusNotFound, do(s.server.Handler, path).Code, path)
+	}
+	requireTest.Equal(http.StatusOK, do(s.server.Handler, "/version").Code)
+	requireTest.Equal(http.StatusOK, do(s.server.Handler, "/v1/users/me/limit").Code)
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// multipartOverhead is room for multipart headers of an upload on top of the attachment size
+const multipartOverhead = 64 << 10
+
+var (
+	errAttachmentsDisabled = errors.New("attachments are not enabled")
+	errAttachmentTooLarge  = errors.New("attachment is too large")
+	errNoAttachment        = errors.New("no file part in body")
+)
+
+// countingReader counts bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// attachmentsHandler lists and uploads attachments of a task at /tasks/{id}/attachments
+func (s *ToDoService) attachmentsHandler(resp http.ResponseWriter, req *http.Request, taskId int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	attacher, ok := s.attacher(resp)
+	if !ok {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		attachments, err := attacher.GetAttachments(req.Context(), userID, taskId)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		if err := json.NewEncoder(resp).Encode(newDataResp(attachments)); err != nil {
+			log.Println(err)
+		}
+	case http.MethodPost:
+		s.uploadAttachmentHandler(resp, req, attacher, userID, taskId)
+	default:
+		writeError(resp, errMethodNotAllowed)
+	}
+}
+
+// uploadAttachmentHandler saves the "file" part of a multipart body, the blob is removed
+// again if it turns out too large or its metadata can't be saved
+func (s *ToDoService) uploadAttachmentHandler(resp http.ResponseWriter, req *http.Request, attacher storages.TaskAttacher, userID, taskId int) {
+	req.Body = http.MaxBytesReader(resp, req.Body, s.maxAttachmentSize+multipartOverhead)
+	reader, err := req.MultipartReader()
+	if err != nil {
+		writeErrResp(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			writeErrResp(resp, http.StatusBadRequest, errNoAttachment)
+			return
+		}
+		if err != nil {
+			writeErrResp(resp, http.StatusBadRequest, err)
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment := &storages.Attachment{
+			TaskId:      taskId,
+			UsrId:       userID,
+			Name:        part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			BlobKey:     newBlobKey(taskId),
+		}
+		if attachment.ContentType == "" {
+			attachment.ContentType = "application/octet-stream"
+		}
+
+		counter := &countingReader{r: io.LimitReader(part, s.maxAttachmentSize+1)}
+		if err := s.blobs.Put(req.Context(), attachment.BlobKey, counter); err != nil {
+			log.Println("saving attachment failed", err)
+			s.deleteBlob(req.Context(), attachment.BlobKey)
+			writeErrResp(resp, http.StatusInternalServerError, errInternal)
+			return
+		}
+		if counter.n > s.maxAttachmentSize {
+			s.deleteBlob(req.Context(), attachment.BlobKey)
+			writeErrResp(resp, http.StatusRequestEntityTooLarge, errAttachmentTooLarge)
+			return
+		}
+		attachment.Size = counter.n
+
+		if err := attacher.AddAttachment(req.Context(), attachment); err != nil {
+			s.deleteBlob(req.Context(), attachment.BlobKey)
+			writeError(resp, err)
+			return
+		}
+
+		resp.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(resp).Encode(newDataResp(attachment)); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+}
+
+// attachmentHandler downloads and deletes an attachment at /tasks/{id}/attachments/{attachmentId}
+func (s *ToDoService) attachmentHandler(resp http.ResponseWriter, req *http.Request, taskId, id int) {
+	if req.Method != http.MethodGet && req.Method != http.MethodDelete {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	attacher, ok := s.attacher(resp)
+	if !ok {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		attachment, err := attacher.DeleteAttachment(req.Context(), userID, taskId, id)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		s.deleteBlob(req.Context(), attachment.BlobKey)
+		resp.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	attachment, err := attacher.GetAttachment(req.Context(), userID, taskId, id)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	content, err := s.blobs.Get(req.Context(), attachment.BlobKey)
+	if err != nil {
+		if err == blobs.ErrNotFound {
+			writeErrResp(resp, http.StatusNotFound, storages.ErrNotFound)
+			return
+		}
+		log.Println("opening attachment failed", err)
+		writeErrResp(resp, http.StatusInternalServerError, errInternal)
+		return
+	}
+	defer content.Close()
+
+	resp.Header().Set("Content-Type", attachment.ContentType)
+	resp.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	resp.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	if _, err := io.Copy(resp, content); err != nil {
+		log.Println(err)
+	}
+}
+
+// attacher returns the storage of attachment metadata, it responds 501 if attachments are not available
+func (s *ToDoService) attacher(resp http.ResponseWriter) (storages.TaskAttacher, bool) {
+	if s.blobs == nil {
+		writeErrResp(resp, http.StatusNotImplemented, errAttachmentsDisabled)
+		return nil, false
+	}
+	attacher, ok := s.store.(storages.TaskAttacher)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return nil, false
+	}
+	return attacher, true
+}
+
+func (s *ToDoService) deleteBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		log.Println("deleting blob", key, "failed", err)
+	}
+}
+
+// newBlobKey returns a random key for a new attachment of the task
+func newBlobKey(taskId int) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("tasks/%d/%s", taskId, hex.EncodeToString(b))
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// blobsMock keeps blobs in a map
+type blobsMock struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (b *blobsMock) Put(_ context.Context, key string, r io.Reader) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blobs[key] = content
+	return nil
+}
+
+func (b *blobsMock) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	content, ok := b.blobs[key]
+	if !ok {
+		return nil, blobs.ErrNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (b *blobsMock) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.blobs, key)
+	return nil
+}
+
+func newUploadRequest(t *testing.T, ctx context.Context, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "notes.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/tasks/3/attachments", body).WithContext(ctx)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadDownloadAttachment(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	store := &blobsMock{blobs: make(map[string][]byte)}
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db, WithAttachments(store, 10))
+
+	var saved *storages.Attachment
+	req := newUploadRequest(t, ctx, "hello")
+	db.On("AddAttachment", req.Context(), mock.AnythingOfType("*storages.Attachment")).Return(nil).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*storages.Attachment)
+	})
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	require.Equal(t, "notes.txt", saved.Name)
+	require.Equal(t, int64(5), saved.Size)
+	require.Len(t, store.blobs, 1)
+
+	req = httptest.NewRequest("GET", "/tasks/3/attachments/1", nil).WithContext(ctx)
+	db.On("GetAttachment", req.Context(), 1, 3, 1).Return(saved, nil)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, `attachment; filename=notes.txt`, resp.Header.Get("Content-Disposition"))
+	content, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+
+	req = httptest.NewRequest("DELETE", "/tasks/3/attachments/1", nil).WithContext(ctx)
+	db.On("DeleteAttachment", req.Context(), 1, 3, 1).Return(saved, nil)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+	require.Empty(t, store.blobs)
+
+	db.AssertExpectations(t)
+}
+
+func TestUploadAttachmentTooLarge(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	store := &blobsMock{blobs: make(map[string][]byte)}
+
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock), WithAttachments(store, 4))
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, newUploadRequest(t, ctx, "hello"))
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Result().StatusCode)
+	require.Empty(t, store.blobs)
+}
+
+func TestAttachmentsDisabled(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock))
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, httptest.NewRequest("GET", "/tasks/3/attachments", nil).WithContext(ctx))
+
+	require.Equal(t, http.StatusNotImplemented, w.Result().StatusCode)
+}
+
+func TestDeleteTaskKeepsAttachments(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	store := &blobsMock{blobs: map[string][]byte{"tasks/3/a": []byte("hello")}}
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db, WithAttachments(store, 10))
+
+	req := httptest.NewRequest("DELETE", "/tasks/3", nil).WithContext(ctx)
+	db.On("DeleteTask", req.Context(), 1, 3).Return(nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+	require.Len(t, store.blobs, 1)
+	db.AssertNotCalled(t, "PurgeAttachments", req.Context(), 1, 3)
+	db.AssertExpectations(t)
+}
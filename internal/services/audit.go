@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// defaultAuditLimit and maxAuditLimit bound how many events GET /admin/audit returns
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// audit appends an event done by the actor about the user to the audit log, failures are only logged
+// so that the audit log never fails the request
+func (s *ToDoService) audit(req *http.Request, typ storages.AuditType, actorId, usrId int, detail string) {
+	s.auditClient(req.Context(), clientOf(req), typ, actorId, usrId, detail)
+}
+
+// auditClient is audit of a request coming from c
+func (s *ToDoService) auditClient(ctx context.Context, c client, typ storages.AuditType, actorId, usrId int, detail string) {
+	if s.auditLog == nil {
+		return
+	}
+	event := &storages.AuditEvent{
+		Type:      typ,
+		ActorId:   actorId,
+		UsrId:     usrId,
+		IP:        c.IP,
+		UserAgent: c.UserAgent,
+		Detail:    detail,
+	}
+	if err := s.auditLog.AppendAudit(ctx, event); err != nil {
+		log.Println("error appending audit event", typ, err)
+	}
+}
+
+// adminAuditHandler lists audit events latest first at GET /admin/audit, the usr_id, type and since
+// (RFC 3339) query params filter them and limit tells how many are returned
+func (s *ToDoService) adminAuditHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	if s.auditLog == nil {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	filter := storages.AuditFilter{Type: storages.AuditType(req.FormValue("type")), Limit: defaultAuditLimit}
+	if v := req.FormValue("usr_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			writeErrResp(resp, http.StatusBadRequest, errInvalidFilter)
+			return
+		}
+		filter.UsrId = id
+	}
+	if v := req.FormValue("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeErrResp(resp, http.StatusBadRequest, errInvalidFilter)
+			return
+		}
+		filter.Since = since
+	}
+	if v := req.FormValue("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxAuditLimit {
+			writeErrResp(resp, http.StatusBadRequest, errInvalidFilter)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	events, err := s.auditLog.ListAudit(req.Context(), filter)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(events)); err != nil {
+		log.Println(err)
+	}
+}
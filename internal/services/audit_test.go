@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	requireTest := require.New(t)
+	auditLog, err := memory.NewMemory()
+	requireTest.NoError(err)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db, WithAuditLog(auditLog))
+
+	db.On("ValidateUser", mock.Anything, testUser.Username, testUser.Password).Return(&storages.User{Id: 1}, nil)
+	db.On("ValidateUser", mock.Anything, testUser.Username, "wrong").Return((*storages.User)(nil), storages.ErrInvalidCredentials)
+	db.On("GetTwoFactor", mock.Anything, 1).Return((*storages.TwoFactor)(nil), storages.ErrNotFound)
+	req := newLoginRequest(testUser.Username, "wrong")
+	req.Header.Set("User-Agent", "curl/7.68.0")
+	s.createTokenHandler(httptest.NewRecorder(), req)
+	s.createTokenHandler(httptest.NewRecorder(), newLoginRequest(testUser.Username, testUser.Password))
+
+	events, err := auditLog.ListAudit(context.Background(), storages.AuditFilter{})
+	requireTest.NoError(err)
+	requireTest.Len(events, 2)
+	requireTest.Equal(storages.AuditLogin, events[0].Type)
+	requireTest.Equal(1, events[0].ActorId)
+	requireTest.Equal(storages.AuditLoginFailed, events[1].Type)
+	requireTest.Equal("username=userid", events[1].Detail)
+	requireTest.Equal("192.0.2.1", events[1].IP)
+	requireTest.Equal("curl/7.68.0", events[1].UserAgent)
+
+	w := httptest.NewRecorder()
+	s.adminHandler(s.adminAuditHandler)(w, newAdminRequest(t, s, "GET", "/admin/audit?type=login_failed", "", storages.RoleAdmin))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	body := &struct {
+		Data []*storages.AuditEvent `json:"data"`
+	}{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(body))
+	requireTest.Len(body.Data, 1)
+	requireTest.Equal(events[1].Id, body.Data[0].Id)
+
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminAuditHandler)(w, newAdminRequest(t, s, "GET", "/admin/audit?limit=0", "", storages.RoleAdmin))
+	requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminAuditHandler)(w, newAdminRequest(t, s, "GET", "/admin/audit", "", storages.RoleUser))
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+}
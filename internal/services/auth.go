@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+)
+
+// tokenPair is the body of responses of /auth/login and /auth/refresh, ExpiresIn is in seconds
+type tokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// refreshParams is the body of /auth/refresh and /auth/logout
+type refreshParams struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// authLoginHandler checks credentials like /login does and gives a refresh token along with the access token
+func (s *ToDoService) authLoginHandler(resp http.ResponseWriter, req *http.Request) {
+	refresher, ok := s.store.(storages.RefreshTokenStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	usr, ok := s.checkLogin(resp, req)
+	if !ok {
+		return
+	}
+
+	s.startSession(resp, req, refresher, usr)
+}
+
+// startSession answers with an access token and the refresh token of a new family of the user
+func (s *ToDoService) startSession(resp http.ResponseWriter, req *http.Request, refresher storages.RefreshTokenStore, usr *storages.User) {
+	secret, hash, err := tokens.NewRefreshToken()
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	refresh := s.newRefreshToken(req, hash)
+	refresh.UsrId = usr.Id
+	if err := refresher.CreateRefreshToken(req.Context(), refresh); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	s.writeTokenPair(resp, req, usr, secret)
+}
+
+// newRefreshToken returns a refresh token of hash which tells the device of req
+func (s *ToDoService) newRefreshToken(req *http.Request, hash string) *storages.RefreshToken {
+	return &storages.RefreshToken{
+		Hash:      hash,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+		UserAgent: userAgent(req),
+		IP:        clientIP(req),
+	}
+}
+
+// refreshHandler trades a refresh token for a new access token and a new refresh token,
+// the traded refresh token can't be used again
+func (s *ToDoService) refreshHandler(resp http.ResponseWriter, req *http.Request) {
+	refresher, params, ok := s.decodeRefresh(resp, req)
+	if !ok {
+		return
+	}
+
+	secret, hash, err := tokens.NewRefreshToken()
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	next := s.newRefreshToken(req, hash)
+	usr, err := refresher.RotateRefreshToken(req.Context(), tokens.HashRefreshToken(params.RefreshToken), next)
+	if err != nil {
+		writeRefreshErrResp(resp, err)
+		return
+	}
+
+	s.writeTokenPair(resp, req, usr, secret)
+}
+
+// logoutHandler revokes the refresh token and every token it has been rotated from or to,
+// access tokens already issued remain valid until they expire
+func (s *ToDoService) logoutHandler(resp http.ResponseWriter, req *http.Request) {
+	refresher, params, ok := s.decodeRefresh(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := refresher.RevokeRefreshTokens(req.Context(), tokens.HashRefreshToken(params.RefreshToken)); err != nil {
+		writeRefreshErrResp(resp, err)
+		return
+	}
+
+	s.audit(req, storages.AuditLogout, 0, 0, "")
+	if s.cookies != nil {
+		s.clearTokenCookies(resp, req)
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRefresh decodes the body of /auth/refresh and /auth/logout, the refresh cookie stands in for an empty
+// body in the cookie mode. The response is written if it fails
+func (s *ToDoService) decodeRefresh(resp http.ResponseWriter, req *http.Request) (storages.RefreshTokenStore, *refreshParams, bool) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return nil, nil, false
+	}
+
+	refresher, ok := s.store.(storages.RefreshTokenStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return nil, nil, false
+	}
+
+	params := &refreshParams{}
+	if err := json.NewDecoder(io.LimitReader(req.Body, maxJsonSize)).Decode(params); err != nil && err != io.EOF {
+		writeError(resp, errInvalidBody)
+		return nil, nil, false
+	}
+	if params.RefreshToken == "" {
+		token, err := s.cookieToken(req, refreshCookie)
+		if err != nil {
+			writeErrResp(resp, http.StatusForbidden, err)
+			return nil, nil, false
+		}
+		params.RefreshToken = token
+	}
+	if params.RefreshToken == "" {
+		writeError(resp, errInvalidBody)
+		return nil, nil, false
+	}
+	return refresher, params, true
+}
+
+func (s *ToDoService) writeTokenPair(resp http.ResponseWriter, req *http.Request, usr *storages.User, refresh string) {
+	access, err := s.createToken(usr)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if s.cookies != nil {
+		if err := s.setTokenCookies(resp, req, access, refresh); err != nil {
+			writeError(resp, err)
+			return
+		}
+	}
+
+	pair := tokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.tokens.TTL() / time.Second),
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(pair)); err != nil {
+		log.Println(err)
+	}
+}
+
+// writeRefreshErrResp answers 401 to unknown, expired, revoked and reused refresh tokens
+func writeRefreshErrResp(resp http.ResponseWriter, err error) {
+	if err == storages.ErrInvalidCredentials {
+		writeErrResp(resp, http.StatusUnauthorized, tokens.ErrInvalidToken)
+		return
+	}
+	writeError(resp, err)
+}
+
+// jwksHandler publishes the public keys which verify access tokens at GET /.well-known/jwks.json so that
+// other services can verify them and pick up rotated keys, the set is empty for HS256 tokens
+func (s *ToDoService) jwksHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	resp.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(resp).Encode(s.tokens.JWKS()); err != nil {
+		log.Println(err)
+	}
+}
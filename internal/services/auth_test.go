@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeTokenPair(t *testing.T, w *httptest.ResponseRecorder) tokenPair {
+	body := &struct {
+		Data tokenPair `json:"data"`
+	}{}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(body))
+	return body.Data
+}
+
+func TestAuthLogin(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := newLoginRequest(testUser.Username, testUser.Password)
+	req.URL.Path = "/auth/login"
+	db.On("ValidateUser", req.Context(), testUser.Username, testUser.Password).Return(&storages.User{Id: 1, MaxTodo: 5}, nil)
+	db.On("GetTwoFactor", req.Context(), 1).Return((*storages.TwoFactor)(nil), storages.ErrNotFound)
+	db.On("CreateRefreshToken", req.Context(), mock.MatchedBy(func(token *storages.RefreshToken) bool {
+		return token.UsrId == 1 && token.Hash != ""
+	})).Return(nil)
+	w := httptest.NewRecorder()
+	s.authLoginHandler(w, req)
+
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	pair := decodeTokenPair(t, w)
+	requireTest.Equal("Bearer", pair.TokenType)
+	requireTest.Equal(int(tokens.DefaultTTL.Seconds()), pair.ExpiresIn)
+	requireTest.NotEmpty(pair.RefreshToken)
+	claims, err := s.tokens.Verify(pair.AccessToken)
+	requireTest.NoError(err)
+	requireTest.Equal(5, claims.MaxTodo)
+	db.AssertExpectations(t)
+}
+
+func TestAuthRefresh(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{"refresh_token": "old"}`))
+	db.On("RotateRefreshToken", req.Context(), tokens.HashRefreshToken("old"), mock.Anything).Return(&storages.User{Id: 1}, nil)
+	w := httptest.NewRecorder()
+	s.refreshHandler(w, req)
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	pair := decodeTokenPair(t, w)
+	requireTest.NotEqual("old", pair.RefreshToken)
+
+	req = httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{"refresh_token": "reused"}`))
+	db.On("RotateRefreshToken", req.Context(), tokens.HashRefreshToken("reused"), mock.Anything).Return((*storages.User)(nil), storages.ErrInvalidCredentials)
+	w = httptest.NewRecorder()
+	s.refreshHandler(w, req)
+	requireTest.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{}`))
+	w = httptest.NewRecorder()
+	s.refreshHandler(w, req)
+	requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestAuthLogout(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/auth/logout", bytes.NewBufferString(`{"refresh_token": "token"}`))
+	db.On("RevokeRefreshTokens", req.Context(), tokens.HashRefreshToken("token")).Return(nil)
+	w := httptest.NewRecorder()
+	s.logoutHandler(w, req)
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("GET", "/auth/logout", nil)
+	w = httptest.NewRecorder()
+	s.logoutHandler(w, req)
+	requireTest.Equal(http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestJWKS(t *testing.T) {
+	requireTest := require.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	requireTest.NoError(err)
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock), WithTokenSigner(tokens.NewRS256(key, 0)))
+
+	w := httptest.NewRecorder()
+	s.jwksHandler(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	set := &tokens.JWKS{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(set))
+	requireTest.Len(set.Keys, 1)
+	requireTest.Equal("RS256", set.Keys[0].Alg)
+	requireTest.Equal("AQAB", set.Keys[0].E)
+
+	w = httptest.NewRecorder()
+	NewToDoService(testJWTKey, ":6000", new(storages.StoreMock)).jwksHandler(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	requireTest.JSONEq(`{"keys": []}`, w.Body.String())
+}
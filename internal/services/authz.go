@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+var (
+	errForbidden    = errors.New("not allowed to access the tasks of the user")
+	errInvalidOwner = errors.New("owner must be the id of a user")
+	errInvalidShare = errors.New("tasks can't be shared with their owner")
+	errNoPrincipal  = errors.New("not authenticated")
+)
+
+// principal is the authenticated user of a request, authHandler puts it in the context.
+// Scopes are the scopes of its token, nil unless the token is limited to them
+type principal struct {
+	UserID int
+	Role   storages.Role
+	Scopes []string
+}
+
+// allows reports whether the token of p may be used for scope
+func (p principal) allows(scope string) bool {
+	if len(p.Scopes) == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func withPrincipal(ctx context.Context, p principal) context.Context {
+	return context.WithValue(withRequestUser(ctx, p.UserID), principalKey{}, p)
+}
+
+func principalFromCtx(ctx context.Context) (principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(principal)
+	return p, ok
+}
+
+// access is what a request does to the tasks and projects of their owner, GET and HEAD requests read them
+type access int
+
+const (
+	accessRead access = iota
+	accessWrite
+)
+
+func accessOf(method string) access {
+	if method == http.MethodGet || method == http.MethodHead {
+		return accessRead
+	}
+	return accessWrite
+}
+
+// authorize returns the owner of the tasks and projects the request acts on, the authenticated user
+// unless the owner query param tells another one. The response is written if the policy denies it
+func (s *ToDoService) authorize(resp http.ResponseWriter, req *http.Request) (int, bool) {
+	owner := 0
+	if v := req.URL.Query().Get("owner"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			writeErrResp(resp, http.StatusBadRequest, errInvalidOwner)
+			return 0, false
+		}
+		owner = id
+	}
+
+	switch id, err := s.ownerOf(req.Context(), owner, accessOf(req.Method)); err {
+	case nil:
+		return id, true
+	case errNoPrincipal:
+		writeError(resp, errNoPrincipal)
+		return 0, false
+	case errForbidden:
+		writeErrResp(resp, http.StatusForbidden, err)
+		return 0, false
+	default:
+		writeError(resp, err)
+		return 0, false
+	}
+}
+
+// ownerOf returns the owner of the tasks and projects a call acts on, the authenticated user of ctx unless
+// owner tells another one, if the policy allows the access. It returns errNoPrincipal without a user
+func (s *ToDoService) ownerOf(ctx context.Context, owner int, a access) (int, error) {
+	p, ok := principalFromCtx(ctx)
+	if !ok {
+		return 0, errNoPrincipal
+	}
+	if owner < 0 {
+		return 0, errInvalidOwner
+	}
+	if owner == 0 {
+		owner = p.UserID
+	}
+	if err := s.allow(ctx, p, owner, a); err != nil {
+		return 0, err
+	}
+	return owner, nil
+}
+
+// allow is the policy of tasks and projects: their owner and admins may do anything with them, users
+// the owner shared them with may read them, and change them too if the share allows it
+func (s *ToDoService) allow(ctx context.Context, p principal, owner int, a access) error {
+	if p.UserID == owner || p.Role == storages.RoleAdmin {
+		return nil
+	}
+
+	shares, ok := s.store.(storages.ShareStore)
+	if !ok {
+		return errForbidden
+	}
+	share, err := shares.GetShare(ctx, owner, p.UserID)
+	if err == storages.ErrNotFound {
+		return errForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if a == accessWrite && !share.CanWrite {
+		return errForbidden
+	}
+	return nil
+}
+
+// sharesHandler lists the users the user shares their tasks and projects with at GET /users/me/shares
+func (s *ToDoService) sharesHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	shares, ok := s.store.(storages.ShareStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	list, err := shares.ListShares(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(list)); err != nil {
+		log.Println(err)
+	}
+}
+
+// shareHandler shares the tasks and projects of the user with another one at PUT /users/me/shares/{usrId},
+// with {"can_write": true} they may change them too. DELETE stops sharing them
+func (s *ToDoService) shareHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	usrId, action, ok := parseItemPath("/users/me/shares/", req.URL.Path)
+	if !ok || action != "" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodPut && req.Method != http.MethodDelete {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	shares, ok := s.store.(storages.ShareStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		if err := shares.DeleteShare(req.Context(), userID, usrId); err != nil {
+			writeError(resp, err)
+			return
+		}
+		resp.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	share := &storages.Share{}
+	if !decodeBody(resp, req, share, maxJsonSize) {
+		return
+	}
+	if usrId == userID {
+		writeErrResp(resp, http.StatusBadRequest, errInvalidShare)
+		return
+	}
+	share.OwnerId, share.UsrId = userID, usrId
+	if err := shares.SaveShare(req.Context(), share); err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(share)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+	db.On("GetShare", mock.Anything, 2, 1).Return(&storages.Share{OwnerId: 2, UsrId: 1}, nil)
+	db.On("GetShare", mock.Anything, 3, 1).Return(&storages.Share{OwnerId: 3, UsrId: 1, CanWrite: true}, nil)
+	db.On("GetShare", mock.Anything, 4, 1).Return((*storages.Share)(nil), storages.ErrNotFound)
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		p      principal
+		code   int
+		owner  int
+	}{
+		{"own tasks", "POST", "", principal{UserID: 1}, http.StatusOK, 1},
+		{"owner given", "POST", "?owner=1", principal{UserID: 1}, http.StatusOK, 1},
+		{"read shared", "GET", "?owner=2", principal{UserID: 1}, http.StatusOK, 2},
+		{"write read-only share", "POST", "?owner=2", principal{UserID: 1}, http.StatusForbidden, 0},
+		{"write shared", "DELETE", "?owner=3", principal{UserID: 1}, http.StatusOK, 3},
+		{"not shared", "GET", "?owner=4", principal{UserID: 1}, http.StatusForbidden, 0},
+		{"admin", "PUT", "?owner=4", principal{UserID: 5, Role: storages.RoleAdmin}, http.StatusOK, 4},
+		{"invalid owner", "GET", "?owner=me", principal{UserID: 1}, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/tasks"+tt.query, nil)
+			req = req.WithContext(withPrincipal(context.Background(), tt.p))
+			w := httptest.NewRecorder()
+			owner, ok := s.authorize(w, req)
+			require.Equal(t, tt.code, w.Result().StatusCode)
+			require.Equal(t, tt.code == http.StatusOK, ok)
+			require.Equal(t, tt.owner, owner)
+		})
+	}
+}
+
+func TestShares(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db.On("SaveShare", mock.Anything, &storages.Share{OwnerId: 1, UsrId: 2, CanWrite: true}).Return(nil)
+	req := httptest.NewRequest("PUT", "/users/me/shares/2", bytes.NewBufferString(`{"can_write": true}`)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	s.shareHandler(w, req)
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PUT", "/users/me/shares/1", bytes.NewBufferString(`{}`)).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.shareHandler(w, req)
+	requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+
+	db.On("DeleteShare", mock.Anything, 1, 3).Return(storages.ErrNotFound)
+	req = httptest.NewRequest("DELETE", "/users/me/shares/3", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.shareHandler(w, req)
+	requireTest.Equal(http.StatusNotFound, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// maxBatchTasks is how many tasks can be created by one request to /tasks:batch
+const maxBatchTasks = 100
+
+var errInvalidBatch = errors.New("batch must have 1 to 100 tasks")
+
+type taskBatch struct {
+	Tasks []*storages.Task `json:"tasks"`
+}
+
+// batchResult is the outcome of one task of a batch, Index is its position in the request
+type batchResult struct {
+	Index int            `json:"index"`
+	Task  *storages.Task `json:"task,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// batchTasksHandler creates all the tasks of the body at /tasks:batch or none of them,
+// the results tell the created task or why it failed for each task
+func (s *ToDoService) batchTasksHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		defer func() {
+			_ = req.Body.Close()
+		}()
+
+		if req.Method != http.MethodPost {
+			writeError(resp, errMethodNotAllowed)
+			return
+		}
+
+		inserter, ok := s.store.(storages.TaskBatchInserter)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+
+		userID, ok := s.authorize(resp, req)
+		if !ok {
+			return
+		}
+
+		body := &taskBatch{}
+		if !decodeBody(resp, req, body, maxBatchTasks*maxJsonSize) {
+			return
+		}
+		for _, task := range body.Tasks {
+			task.UsrId = userID
+		}
+
+		err := inserter.InsertTasks(req.Context(), body.Tasks)
+		s.countQuota(err)
+		if batchErr, ok := err.(storages.BatchError); ok {
+			writeBatchErrResp(resp, batchErr)
+			return
+		}
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+
+		results := make([]batchResult, 0, len(body.Tasks))
+		for i, task := range body.Tasks {
+			s.publishTask(events.Created, userID, task.Id, task)
+			s.auditTask(req.Context(), storages.TaskCreated, userID, task.Id, nil)
+			results = append(results, batchResult{Index: i, Task: task})
+		}
+		resp.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(resp).Encode(newDataResp(results)); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// batchErrCodes ranks statuses of failed tasks, the batch answers with the highest one
+var batchErrCodes = map[int]int{
+	http.StatusTooManyRequests:     0,
+	http.StatusNotFound:            1,
+	http.StatusBadRequest:          2,
+	http.StatusInternalServerError: 3,
+}
+
+// writeBatchErrResp answers with the failed tasks as details, the status and the code are the ones of the most
+// fixable error: invalid tasks first, then unknown projects, then exceeded limits
+func writeBatchErrResp(resp http.ResponseWriter, batchErr storages.BatchError) {
+	code, codeErr := http.StatusTooManyRequests, storages.ErrQuotaExceeded
+	results := make([]batchResult, 0, len(batchErr))
+	for i, err := range batchErr {
+		itemCode := http.StatusTooManyRequests
+		switch err {
+		case storages.ErrInvalidTask, storages.ErrInvalidProject:
+			itemCode = http.StatusBadRequest
+		case storages.ErrNotFound:
+			itemCode = http.StatusNotFound
+		case storages.ErrQuotaExceeded:
+		default:
+			log.Println(err)
+			err = errInternal
+			itemCode = http.StatusInternalServerError
+		}
+		if batchErrCodes[itemCode] > batchErrCodes[code] {
+			code, codeErr = itemCode, err
+		}
+		results = append(results, batchResult{Index: i, Error: err.Error()})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+
+	writeErrDetails(resp, code, errorCode(code, codeErr), errSomeTasksRejected, results)
+}
+
+// bulkResult is the body of responses to bulk operations
+type bulkResult struct {
+	Count int `json:"count"`
+}
+
+// bulkTasksHandler completes or deletes the tasks picked by the selector of the body
+// in one transaction at /tasks:complete and /tasks:delete
+func (s *ToDoService) bulkTasksHandler(deleting bool) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		defer func() {
+			_ = req.Body.Close()
+		}()
+
+		if req.Method != http.MethodPost {
+			writeError(resp, errMethodNotAllowed)
+			return
+		}
+
+		updater, ok := s.store.(storages.TaskBulkUpdater)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+
+		userID, ok := s.authorize(resp, req)
+		if !ok {
+			return
+		}
+
+		selector := storages.TaskSelector{}
+		if !decodeBody(resp, req, &selector, maxBatchTasks*maxJsonSize) {
+			return
+		}
+
+		var ids []int
+		var err error
+		if deleting {
+			ids, err = updater.DeleteTasks(req.Context(), userID, selector)
+		} else {
+			ids, err = updater.CompleteTasks(req.Context(), userID, selector)
+		}
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		typ, action := events.Updated, storages.TaskCompleted
+		if deleting {
+			typ, action = events.Deleted, storages.TaskDeleted
+		}
+		for _, id := range ids {
+			s.publishTask(typ, userID, id, nil)
+			s.auditTask(req.Context(), action, userID, id, nil)
+		}
+
+		if err := json.NewEncoder(resp).Encode(newDataResp(bulkResult{Count: len(ids)})); err != nil {
+			log.Println(err)
+		}
+	}
+}
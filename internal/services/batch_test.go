@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatchTasks(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/tasks:batch", bytes.NewBufferString(`{"tasks": [{"content": "a"}, {"content": "b"}]}`)).WithContext(ctx)
+	db.On("InsertTasks", req.Context(), []*storages.Task{{UsrId: 1, Content: "a"}, {UsrId: 1, Content: "b"}}).Return(nil).Once()
+	w := httptest.NewRecorder()
+	s.batchTasksHandler()(w, req)
+	resp := w.Result()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: []batchResult{
+		{Index: 0, Task: &storages.Task{UsrId: 1, Content: "a"}},
+		{Index: 1, Task: &storages.Task{UsrId: 1, Content: "b"}},
+	}}, resp)
+
+	req = httptest.NewRequest("POST", "/tasks:batch", bytes.NewBufferString(`{"tasks": [{"content": "a"}, {"content": "b"}, {"content": "c"}]}`)).WithContext(ctx)
+	db.On("InsertTasks", req.Context(), mock.Anything).
+		Return(storages.BatchError{2: storages.ErrQuotaExceeded, 0: storages.ErrInvalidTask}).Once()
+	w = httptest.NewRecorder()
+	s.batchTasksHandler()(w, req)
+	resp = w.Result()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assertErrResp(t, &ApiErrResp{Error: ApiError{
+		Code:    codeValidation,
+		Message: errSomeTasksRejected.Error(),
+		Details: []batchResult{
+			{Index: 0, Error: storages.ErrInvalidTask.Error()},
+			{Index: 2, Error: storages.ErrQuotaExceeded.Error()},
+		},
+	}}, resp)
+
+	req = httptest.NewRequest("POST", "/tasks:batch", bytes.NewBufferString(`{"tasks": [{"content": "a"}]}`)).WithContext(ctx)
+	db.On("InsertTasks", req.Context(), mock.Anything).Return(storages.BatchError{0: storages.ErrQuotaExceeded}).Once()
+	w = httptest.NewRecorder()
+	s.batchTasksHandler()(w, req)
+	require.Equal(t, http.StatusTooManyRequests, w.Result().StatusCode)
+
+	tooMany := `{"tasks": [` + strings.Repeat(`{"content": "a"},`, maxBatchTasks) + `{"content": "a"}]}`
+	for _, body := range []string{`{"tasks": []}`, tooMany, `{"tasks": 1}`} {
+		req = httptest.NewRequest("POST", "/tasks:batch", bytes.NewBufferString(body)).WithContext(ctx)
+		w = httptest.NewRecorder()
+		s.batchTasksHandler()(w, req)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/tasks:batch", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.batchTasksHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestBulkTasks(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/tasks:complete", bytes.NewBufferString(`{"ids": [1, 2]}`)).WithContext(ctx)
+	db.On("CompleteTasks", req.Context(), 1, storages.TaskSelector{Ids: []int{1, 2}}).Return([]int{1}, nil)
+	w := httptest.NewRecorder()
+	s.bulkTasksHandler(false)(w, req)
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: bulkResult{Count: 1}}, resp)
+
+	completed := true
+	req = httptest.NewRequest("POST", "/tasks:delete", bytes.NewBufferString(`{"completed": true}`)).WithContext(ctx)
+	db.On("DeleteTasks", req.Context(), 1, storages.TaskSelector{Completed: &completed}).Return([]int{1, 2}, nil)
+	w = httptest.NewRecorder()
+	s.bulkTasksHandler(true)(w, req)
+	resp = w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: bulkResult{Count: 2}}, resp)
+
+	req = httptest.NewRequest("POST", "/tasks:delete", bytes.NewBufferString(`{}`)).WithContext(ctx)
+	db.On("DeleteTasks", req.Context(), 1, storages.TaskSelector{}).Return([]int(nil), storages.ErrInvalidTask)
+	w = httptest.NewRecorder()
+	s.bulkTasksHandler(true)(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	req = httptest.NewRequest("DELETE", "/tasks:delete", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.bulkTasksHandler(true)(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
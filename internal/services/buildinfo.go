@@ -0,0 +1,21 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/version"
+)
+
+// versionHandler answers the build running at GET /version, like probes it's neither authenticated nor logged
+func (s *ToDoService) versionHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+	resp.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(resp).Encode(version.Get()); err != nil {
+		log.Println(err)
+	}
+}
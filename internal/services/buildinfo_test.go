@@ -0,0 +1,28 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/version"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersion(t *testing.T) {
+	requireTest := require.New(t)
+	s := NewToDoService(testJWTKey, ":6000", nil)
+
+	// the build is answered without a token
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+	requireTest.Equal(http.StatusOK, w.Code)
+	info := version.Info{}
+	requireTest.NoError(json.Unmarshal(w.Body.Bytes(), &info))
+	requireTest.Equal(version.Get(), info)
+
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/version", nil))
+	requireTest.Equal(http.StatusMethodNotAllowed, w.Code)
+}
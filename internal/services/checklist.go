@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// checklistOrder is the body of PUT /tasks/{id}/items, it holds ids of all items in the new order
+type checklistOrder struct {
+	Ids []int `json:"ids"`
+}
+
+// checklistHandler serves checklist items of a task at /tasks/{id}/items
+func (s *ToDoService) checklistHandler(resp http.ResponseWriter, req *http.Request, taskId int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	checklist, ok := s.store.(storages.TaskChecklist)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		items, err := checklist.GetChecklist(req.Context(), userID, taskId)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		if err := json.NewEncoder(resp).Encode(newDataResp(items)); err != nil {
+			log.Println(err)
+		}
+	case http.MethodPost:
+		item := &storages.ChecklistItem{}
+		if !decodeBody(resp, req, item, maxJsonSize) {
+			return
+		}
+		item.TaskId = taskId
+		if err := checklist.AddChecklistItem(req.Context(), userID, item); err != nil {
+			writeError(resp, err)
+			return
+		}
+		resp.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(resp).Encode(newDataResp(item)); err != nil {
+			log.Println(err)
+		}
+	case http.MethodPut:
+		body := &checklistOrder{}
+		if !decodeBody(resp, req, body, maxJsonSize) {
+			return
+		}
+		if err := checklist.ReorderChecklist(req.Context(), userID, taskId, body.Ids); err != nil {
+			writeError(resp, err)
+			return
+		}
+		resp.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(resp, errMethodNotAllowed)
+	}
+}
+
+// checklistItemHandler completes and uncompletes a checklist item at /tasks/{id}/items/{itemId}/complete
+func (s *ToDoService) checklistItemHandler(resp http.ResponseWriter, req *http.Request, taskId, itemId int, action string) {
+	if action != "complete" && action != "uncomplete" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodPatch {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	checklist, ok := s.store.(storages.TaskChecklist)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := checklist.SetChecklistItemDone(req.Context(), userID, taskId, itemId, action == "complete"); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
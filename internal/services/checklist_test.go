@@ -0,0 +1,44 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestChecklist(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/tasks/3/items", bytes.NewBufferString(`{"content": "step 1"}`)).WithContext(ctx)
+	db.On("AddChecklistItem", req.Context(), 1, &storages.ChecklistItem{TaskId: 3, Content: "step 1"}).Return(nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusCreated, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PUT", "/tasks/3/items", bytes.NewBufferString(`{"ids": [2, 1]}`)).WithContext(ctx)
+	db.On("ReorderChecklist", req.Context(), 1, 3, []int{2, 1}).Return(storages.ErrInvalidTask)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PATCH", "/tasks/3/items/2/complete", nil).WithContext(ctx)
+	db.On("SetChecklistItemDone", req.Context(), 1, 3, 2, true).Return(nil)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PATCH", "/tasks/3/items/2/uncomplete", nil).WithContext(ctx)
+	db.On("SetChecklistItemDone", req.Context(), 1, 3, 2, false).Return(storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
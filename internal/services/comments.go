@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// commentsHandler lists and appends comments of a task at /tasks/{id}/comments
+func (s *ToDoService) commentsHandler(resp http.ResponseWriter, req *http.Request, taskId int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	commenter, ok := s.store.(storages.TaskCommenter)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		comments, err := commenter.GetComments(req.Context(), userID, taskId)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		if err := json.NewEncoder(resp).Encode(newDataResp(comments)); err != nil {
+			log.Println(err)
+		}
+	case http.MethodPost:
+		comment := &storages.Comment{}
+		if !decodeBody(resp, req, comment, maxJsonSize) {
+			return
+		}
+		comment.TaskId = taskId
+		comment.UsrId = userID
+		if err := commenter.AddComment(req.Context(), comment); err != nil {
+			writeError(resp, err)
+			return
+		}
+		resp.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(resp).Encode(newDataResp(comment)); err != nil {
+			log.Println(err)
+		}
+	default:
+		writeError(resp, errMethodNotAllowed)
+	}
+}
+
+// deleteCommentHandler deletes a comment of the user at /tasks/{id}/comments/{commentId}
+func (s *ToDoService) deleteCommentHandler(resp http.ResponseWriter, req *http.Request, taskId, id int) {
+	commenter, ok := s.store.(storages.TaskCommenter)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := commenter.DeleteComment(req.Context(), userID, taskId, id); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
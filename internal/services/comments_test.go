@@ -0,0 +1,47 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/tasks/3/comments", bytes.NewBufferString(`{"content": "looks good"}`)).WithContext(ctx)
+	db.On("AddComment", req.Context(), &storages.Comment{TaskId: 3, UsrId: 1, Content: "looks good"}).Return(nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusCreated, w.Result().StatusCode)
+
+	comments := []*storages.Comment{{Id: 1, TaskId: 3, UsrId: 1, Content: "looks good"}}
+	req = httptest.NewRequest("GET", "/tasks/3/comments", nil).WithContext(ctx)
+	db.On("GetComments", req.Context(), 1, 3).Return(comments, nil)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: comments}, resp)
+
+	req = httptest.NewRequest("DELETE", "/tasks/3/comments/1", nil).WithContext(ctx)
+	db.On("DeleteComment", req.Context(), 1, 3, 1).Return(storages.ErrForbidden)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PUT", "/tasks/3/comments/1", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
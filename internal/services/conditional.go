@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+var errPreconditionFailed = errors.New("task has changed since the version of If-Match")
+
+// taskETag is the entity-tag of task which follows its version, rendered representations have tags of their own
+func taskETag(req *http.Request, task *storages.Task) string {
+	tag := strconv.Itoa(task.Version)
+	if req.URL.Query().Get("render") == "html" {
+		tag += "-html"
+	}
+	return `"` + tag + `"`
+}
+
+// lastModified is when task was last changed
+func lastModified(task *storages.Task) time.Time {
+	if task.UpdateAt != nil {
+		return *task.UpdateAt
+	}
+	return task.CreateAt
+}
+
+// setValidators sets the ETag and the Last-Modified headers of a response, the latter only if modified is known
+func setValidators(resp http.ResponseWriter, etag string, modified time.Time) {
+	resp.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		resp.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified reports whether the client has the representation of etag modified at modified already,
+// If-Modified-Since is only looked at when If-None-Match isn't given as RFC 7232 requires
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if list := req.Header.Get("If-None-Match"); list != "" {
+		for _, tag := range strings.Split(list, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.IsZero() && !modified.Truncate(time.Second).After(since)
+}
+
+// ifMatchVersion returns the version of the task tag given by If-Match, ok is false if there's no such header
+// or it matches any version
+func ifMatchVersion(req *http.Request) (version int, ok bool, err error) {
+	tag := strings.TrimSpace(req.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, false, nil
+	}
+	tag = strings.TrimSuffix(strings.Trim(tag, `"`), "-html")
+	version, err = strconv.Atoi(tag)
+	if err != nil {
+		return 0, false, errPreconditionFailed
+	}
+	return version, true, nil
+}
+
+// checkIfMatch answers 412 unless the task is at the version of If-Match, if any. Unlike updates, which compare
+// versions as they save, actions on a task are checked before they're applied
+func (s *ToDoService) checkIfMatch(resp http.ResponseWriter, req *http.Request, usrId, id int) bool {
+	version, ok, err := ifMatchVersion(req)
+	if err != nil {
+		writeError(resp, err)
+		return false
+	}
+	if !ok {
+		return true
+	}
+	getter, ok := s.store.(storages.TaskGetter)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return false
+	}
+	task, err := getter.GetTask(req.Context(), usrId, id)
+	if err != nil {
+		writeError(resp, err)
+		return false
+	}
+	if task.Version != version {
+		writeError(resp, errPreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// writeTask answers task with its validators, or 304 to GET requests of clients which have it already
+func writeTask(resp http.ResponseWriter, req *http.Request, task *storages.Task) {
+	etag, modified := taskETag(req, task), lastModified(task)
+	setValidators(resp, etag, modified)
+	if req.Method == http.MethodGet && notModified(req, etag, modified) {
+		resp.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(task)); err != nil {
+		log.Println(err)
+	}
+}
+
+// writeTasks answers a list of tasks, its ETag hashes the body and it was last modified when its latest task was.
+// Only If-None-Match is honored as tasks which leave the list don't make it newer. Lists are streamed as CSV or
+// NDJSON instead when Accept prefers them
+func writeTasks(resp http.ResponseWriter, req *http.Request, tasks []*storages.Task) {
+	resp.Header().Set("Vary", "Accept")
+	if format := listFormat(req); format != mimeJSON {
+		streamTasks(resp, format, eachOf(tasks))
+		return
+	}
+
+	body := &bytes.Buffer{}
+	if err := json.NewEncoder(body).Encode(newDataResp(tasks)); err != nil {
+		writeError(resp, err)
+		return
+	}
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	modified := time.Time{}
+	for _, task := range tasks {
+		if t := lastModified(task); t.After(modified) {
+			modified = t
+		}
+	}
+
+	setValidators(resp, etag, modified)
+	if notModified(req, etag, time.Time{}) {
+		resp.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if _, err := resp.Write(body.Bytes()); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalTasks(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	do := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		req = req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id}))
+		if path == "/tasks" || strings.HasPrefix(path, "/tasks?") {
+			s.tasksHandler()(w, req)
+		} else {
+			s.taskHandler()(w, req)
+		}
+		return w
+	}
+
+	w := do("POST", "/tasks", `{"content": "milk"}`, nil)
+	requireTest.Equal(http.StatusOK, w.Code)
+	task := &struct{ Data storages.Task }{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(task))
+	etag := w.Header().Get("ETag")
+	requireTest.Equal(`"`+strconv.Itoa(task.Data.Version)+`"`, etag)
+	requireTest.NotEmpty(w.Header().Get("Last-Modified"))
+	path := "/tasks/" + strconv.Itoa(task.Data.Id)
+
+	w = do("GET", path, "", http.Header{"If-None-Match": {etag}})
+	requireTest.Equal(http.StatusNotModified, w.Code)
+	requireTest.Empty(w.Body.String())
+	w = do("GET", path, "", http.Header{"If-Modified-Since": {time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}})
+	requireTest.Equal(http.StatusNotModified, w.Code)
+	w = do("GET", path+"?render=html", "", http.Header{"If-None-Match": {etag}})
+	requireTest.Equal(http.StatusOK, w.Code)
+
+	list := "/tasks?created_date=" + time.Now().UTC().Format("2006-01-02")
+	w = do("GET", list, "", nil)
+	requireTest.Equal(http.StatusOK, w.Code)
+	listETag := w.Header().Get("ETag")
+	requireTest.NotEmpty(listETag)
+	requireTest.Equal(http.StatusNotModified, do("GET", list, "", http.Header{"If-None-Match": {listETag}}).Code)
+
+	// a stale If-Match is rejected, the current one updates the task whatever the version of the body
+	stale := http.Header{"If-Match": {`"` + strconv.Itoa(task.Data.Version-1) + `"`}}
+	w = do("PUT", path, `{"content": "oat milk", "status": "todo"}`, stale)
+	requireTest.Equal(http.StatusPreconditionFailed, w.Code)
+	requireTest.Contains(w.Body.String(), codePreconditionFailed)
+	w = do("PUT", path, `{"content": "oat milk", "status": "todo"}`, http.Header{"If-Match": {etag}})
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.NotEqual(etag, w.Header().Get("ETag"))
+	requireTest.Equal(http.StatusPreconditionFailed, do("PATCH", path+"/complete", "", http.Header{"If-Match": {etag}}).Code)
+	requireTest.Equal(http.StatusNoContent, do("PATCH", path+"/complete", "", http.Header{"If-Match": {w.Header().Get("ETag")}}).Code)
+
+	requireTest.Equal(http.StatusOK, do("GET", list, "", http.Header{"If-None-Match": {listETag}}).Code)
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Cookies of the cookie mode: the access and refresh tokens are out of reach of scripts, scripts of the
+// site read csrfCookie and send it back in csrfHeader to prove requests are not forged by other sites
+const (
+	accessCookie  = "togo_access"
+	refreshCookie = "togo_refresh"
+	csrfCookie    = "togo_csrf"
+	csrfHeader    = "X-CSRF-Token"
+)
+
+var errInvalidCSRF = errors.New("CSRF token is missing or not valid")
+
+// CookieAuth configures the delivery of tokens as cookies to browsers. Secure cookies are only sent over HTTPS,
+// SameSite is http.SameSiteLaxMode unless set
+type CookieAuth struct {
+	Secure   bool
+	Domain   string
+	SameSite http.SameSite
+}
+
+// setTokenCookies gives the tokens of a login or a refresh along with a new CSRF token, the refresh
+// token is only sent back to /auth/ of the version of the API req came by
+func (s *ToDoService) setTokenCookies(resp http.ResponseWriter, req *http.Request, access, refresh string) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	accessAge, refreshAge := int(s.tokens.TTL()/time.Second), int(s.refreshTTL/time.Second)
+	http.SetCookie(resp, s.newCookie(accessCookie, access, "/", accessAge, true))
+	http.SetCookie(resp, s.newCookie(refreshCookie, refresh, versionPrefix(req.Context())+"/auth/", refreshAge, true))
+	http.SetCookie(resp, s.newCookie(csrfCookie, base64.RawURLEncoding.EncodeToString(b), "/", refreshAge, false))
+	return nil
+}
+
+// clearTokenCookies removes the cookies of a session which is logged out
+func (s *ToDoService) clearTokenCookies(resp http.ResponseWriter, req *http.Request) {
+	http.SetCookie(resp, s.newCookie(accessCookie, "", "/", -1, true))
+	http.SetCookie(resp, s.newCookie(refreshCookie, "", versionPrefix(req.Context())+"/auth/", -1, true))
+	http.SetCookie(resp, s.newCookie(csrfCookie, "", "/", -1, false))
+}
+
+func (s *ToDoService) newCookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	sameSite := s.cookies.SameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteLaxMode
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   s.cookies.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: sameSite,
+	}
+}
+
+// cookieToken returns the token of the cookie of name, empty unless the cookie mode is on. Requests which
+// change anything must carry the CSRF token in csrfHeader, errInvalidCSRF is returned otherwise
+func (s *ToDoService) cookieToken(req *http.Request, name string) (string, error) {
+	if s.cookies == nil {
+		return "", nil
+	}
+	cookie, err := req.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		return "", nil
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return cookie.Value, nil
+	}
+	csrf, err := req.Cookie(csrfCookie)
+	header := req.Header.Get(csrfHeader)
+	if err != nil || header == "" || subtle.ConstantTimeCompare([]byte(csrf.Value), []byte(header)) != 1 {
+		return "", errInvalidCSRF
+	}
+	return cookie.Value, nil
+}
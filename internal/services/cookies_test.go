@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCookieAuth(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db, WithCookieAuth(CookieAuth{Secure: true}))
+
+	w := httptest.NewRecorder()
+	s.writeTokenPair(w, httptest.NewRequest("POST", "/auth/login", nil), &storages.User{Id: 1, MaxTodo: 5}, "refresh")
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	requireTest.Len(cookies, 3)
+	requireTest.True(cookies[accessCookie].HttpOnly)
+	requireTest.True(cookies[accessCookie].Secure)
+	requireTest.Equal(http.SameSiteLaxMode, cookies[accessCookie].SameSite)
+	requireTest.Equal("/auth/", cookies[refreshCookie].Path)
+	requireTest.False(cookies[csrfCookie].HttpOnly)
+
+	ok := func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusNoContent)
+	}
+	newRequest := func(method string, csrf string) *http.Request {
+		req := httptest.NewRequest(method, "/tasks", nil)
+		req.AddCookie(cookies[accessCookie])
+		req.AddCookie(cookies[csrfCookie])
+		if csrf != "" {
+			req.Header.Set(csrfHeader, csrf)
+		}
+		return req
+	}
+	tests := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{"read by cookie", newRequest("GET", ""), http.StatusNoContent},
+		{"change without CSRF token", newRequest("POST", ""), http.StatusForbidden},
+		{"change with wrong CSRF token", newRequest("POST", "forged"), http.StatusForbidden},
+		{"change with CSRF token", newRequest("POST", cookies[csrfCookie].Value), http.StatusNoContent},
+		{"no token", httptest.NewRequest("POST", "/tasks", nil), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.authHandler(ok)(w, tt.req)
+			require.Equal(t, tt.code, w.Result().StatusCode)
+		})
+	}
+
+	// bearer clients are not asked for CSRF tokens
+	req := httptest.NewRequest("POST", "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+cookies[accessCookie].Value)
+	w = httptest.NewRecorder()
+	s.authHandler(ok)(w, req)
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/auth/logout", bytes.NewBuffer(nil))
+	req.AddCookie(cookies[refreshCookie])
+	w = httptest.NewRecorder()
+	s.logoutHandler(w, req)
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+
+	req.Header.Set(csrfHeader, cookies[csrfCookie].Value)
+	req.AddCookie(cookies[csrfCookie])
+	db.On("RevokeRefreshTokens", mock.Anything, tokens.HashRefreshToken("refresh")).Return(nil)
+	w = httptest.NewRecorder()
+	s.logoutHandler(w, req)
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+	for _, cookie := range w.Result().Cookies() {
+		requireTest.Equal(-1, cookie.MaxAge)
+	}
+	db.AssertExpectations(t)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultCORSMethods are the methods allowed to browsers unless CORS tells them
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// CORS tells which browser origins may call the API. Origins are exact ones such as "https://app.example.com",
+// patterns such as "https://*.example.com", or "*" for any origin. Credentials, i.e. cookies, are only
+// allowed to origins which are listed or match a pattern, never to any origin.
+// Empty AllowedMethods allow defaultCORSMethods and empty AllowedHeaders allow the headers browsers ask for
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache answers to preflight requests, they decide when it's 0
+	MaxAge time.Duration
+}
+
+// allowOrigin reports whether origin is allowed and whether it's allowed only by the "*" entry, anyOrigin
+// answers are given Access-Control-Allow-Origin: * and no credentials
+func (c *CORS) allowOrigin(origin string) (allowed, anyOrigin bool) {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		switch {
+		case allowed == origin:
+			return true, false
+		case allowed == "*":
+			anyOrigin = true
+		case strings.Contains(allowed, "://*."):
+			i := strings.Index(allowed, "*")
+			if strings.HasPrefix(origin, allowed[:i]) && strings.HasSuffix(origin, allowed[i+1:]) &&
+				len(origin) > len(allowed)-1 {
+				return true, false
+			}
+		}
+	}
+	return anyOrigin, anyOrigin
+}
+
+func (c *CORS) allowMethod(method string) bool {
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	for _, allowed := range methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCORS replaces the CORS policy of the requests served from now on, empty AllowedOrigins restore the default
+// which lets any origin call the API without credentials
+func (s *ToDoService) SetCORS(c CORS) {
+	if len(c.AllowedOrigins) == 0 {
+		c = CORS{AllowedOrigins: []string{"*"}}
+	}
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	s.cors = &c
+}
+
+// corsHandler serves requests by the CORS policy of the service when they come, see CORS.serve
+func (s *ToDoService) corsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		s.liveMu.RLock()
+		c := s.cors
+		s.liveMu.RUnlock()
+		c.serve(resp, req, next)
+	})
+}
+
+// serve answers preflight requests of allowed origins and adds CORS headers to their other requests,
+// requests of other origins are served without them so browsers don't let scripts read the answers
+func (c *CORS) serve(resp http.ResponseWriter, req *http.Request, next http.Handler) {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		next.ServeHTTP(resp, req)
+		return
+	}
+
+	header := resp.Header()
+	header.Add("Vary", "Origin")
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+	allowed, anyOrigin := c.allowOrigin(origin)
+	if !allowed {
+		if preflight {
+			resp.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(resp, req)
+		return
+	}
+
+	if anyOrigin {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+		if c.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+	}
+
+	if !preflight {
+		if len(c.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+		}
+		next.ServeHTTP(resp, req)
+		return
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	if method := req.Header.Get("Access-Control-Request-Method"); c.allowMethod(method) {
+		header.Set("Access-Control-Allow-Methods", method)
+		if len(c.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+		} else if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if c.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+		}
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
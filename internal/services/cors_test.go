@@ -0,0 +1,98 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	cors := CORS{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock), WithCORS(cors))
+	handler := s.corsHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method, origin, requestMethod string) *http.Response {
+		req := httptest.NewRequest(method, "/tasks", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if requestMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", requestMethod)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	resp := serve("OPTIONS", "https://app.example.com", "DELETE")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, "DELETE", resp.Header.Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Authorization, Content-Type", resp.Header.Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "3600", resp.Header.Get("Access-Control-Max-Age"))
+
+	resp = serve("OPTIONS", "https://app.example.com", "TRACE")
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+
+	resp = serve("GET", "https://eu.example.org", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "https://eu.example.org", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Retry-After", resp.Header.Get("Access-Control-Expose-Headers"))
+
+	for _, origin := range []string{"https://evil.com", "https://example.org", "http://eu.example.org"} {
+		resp = serve("OPTIONS", origin, "GET")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"), origin)
+	}
+
+	resp = serve("GET", "", "")
+	require.Empty(t, resp.Header.Get("Vary"))
+
+	// a reload applies to the requests served by the same handler from then on, any origin is never allowed
+	// credentials
+	cors.AllowedOrigins = []string{"*"}
+	s.SetCORS(cors)
+	resp = serve("GET", "https://evil.com", "")
+	require.Equal(t, "*", resp.Header.Get("Access-Control-Allow-Origin"))
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+
+	s.SetCORS(CORS{AllowedOrigins: []string{"https://new.example.com"}})
+	resp = serve("OPTIONS", "https://app.example.com", "DELETE")
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	resp = serve("OPTIONS", "https://new.example.com", "DELETE")
+	require.Equal(t, "https://new.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestSetCORS(t *testing.T) {
+	requireTest := require.New(t)
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock),
+		WithCORS(CORS{AllowedOrigins: []string{"https://app.example.com"}}))
+	allowedOrigin := func(origin string) string {
+		req := httptest.NewRequest("GET", "/v1/.well-known/jwks.json", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+		return w.Header().Get("Access-Control-Allow-Origin")
+	}
+
+	requireTest.Empty(allowedOrigin("https://new.example.com"))
+	s.SetCORS(CORS{AllowedOrigins: []string{"https://new.example.com"}})
+	requireTest.Equal("https://new.example.com", allowedOrigin("https://new.example.com"))
+	requireTest.Empty(allowedOrigin("https://app.example.com"))
+
+	// no origins is the default again
+	s.SetCORS(CORS{})
+	requireTest.Equal("*", allowedOrigin("https://app.example.com"))
+}
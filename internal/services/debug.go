@@ -0,0 +1,42 @@
+package services
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+)
+
+// WithDebugServer serves the profiles of net/http/pprof at /debug/pprof/ and the variables of expvar at
+// /debug/vars on addr, such as localhost:6060. They're served without a token, so addr must not be public
+func WithDebugServer(addr string) Option {
+	return func(s *ToDoService) {
+		s.debugServer = &http.Server{
+			Addr:              addr,
+			Handler:           debugRoutes(),
+			ReadHeaderTimeout: DefaultReadHeaderTimeout,
+			IdleTimeout:       DefaultIdleTimeout,
+		}
+	}
+}
+
+// debugRoutes returns the routes of the debug server, CPU profiles and traces take as long as their seconds
+// parameter so that the server has no write timeout
+func debugRoutes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// serveDebug serves the debug server until Shutdown, failures are reported by HttpServerErr
+func (s *ToDoService) serveDebug() {
+	go func() {
+		if err := s.debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.reportServerErr(err)
+		}
+	}()
+}
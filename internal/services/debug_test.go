@@ -0,0 +1,28 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugServer(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	s := NewToDoService(testJWTKey, ":6000", m, WithDebugServer("127.0.0.1:0"))
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/vars"} {
+		w := httptest.NewRecorder()
+		s.debugServer.Handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		requireTest.Equal(http.StatusOK, w.Code, path)
+	}
+
+	// the API doesn't serve them
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/vars", nil))
+	requireTest.NotEqual(http.StatusOK, w.Code)
+}
@@ -9,11 +9,19 @@ func newDataResp(data interface{}) *ApiDataResp {
 	return &ApiDataResp{Data: data}
 }
 
-// ApiDataResp represents api response with error
+// ApiErrResp represents api response with error
 type ApiErrResp struct {
-	Error interface{} `json:"error"`
+	Error ApiError `json:"error"`
 }
 
-func newErrResp(err interface{}) *ApiErrResp {
+// ApiError is the error of a response, Code is one of the codes of errors.go and Details tells more
+// about some errors
+type ApiError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+func newErrResp(err ApiError) *ApiErrResp {
 	return &ApiErrResp{Error: err}
 }
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/oidc"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+)
+
+// Codes of error responses, they are stable so that clients can tell errors apart without parsing messages
+const (
+	codeBadRequest         = "BAD_REQUEST"
+	codeValidation         = "VALIDATION_ERROR"
+	codeUnauthenticated    = "UNAUTHENTICATED"
+	codeInvalidCredentials = "INVALID_CREDENTIALS"
+	codeTwoFactorRequired  = "TWO_FACTOR_REQUIRED"
+	codeInvalidTwoFactor   = "INVALID_TWO_FACTOR"
+	codeInvalidCSRF        = "INVALID_CSRF"
+	codeForbidden          = "FORBIDDEN"
+	codeInsufficientScope  = "INSUFFICIENT_SCOPE"
+	codeNotFound           = "NOT_FOUND"
+	codeRouteNotFound      = "ROUTE_NOT_FOUND"
+	codeInvalidLink        = "INVALID_LINK"
+	codeMethodNotAllowed   = "METHOD_NOT_ALLOWED"
+	codeConflict           = "CONFLICT"
+	codeIdempotencyReused  = "IDEMPOTENCY_KEY_REUSED"
+	codeUsernameTaken      = "USERNAME_TAKEN"
+	codePreconditionFailed = "PRECONDITION_FAILED"
+	codePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	codeQuotaExceeded      = "QUOTA_EXCEEDED"
+	codeTooManyRequests    = "TOO_MANY_REQUESTS"
+	codeNotSupported       = "NOT_SUPPORTED"
+	codeTimeout            = "TIMEOUT"
+	codeInternal           = "INTERNAL"
+	codeBadGateway         = "BAD_GATEWAY"
+)
+
+var (
+	errInvalidBody       = errors.New("request body is not valid")
+	errInvalidCreated    = errors.New("created_date must be YYYY-MM-DD or today")
+	errUnknownRoute      = errors.New("no such route")
+	errMethodNotAllowed  = errors.New("method not allowed")
+	errSomeTasksRejected = errors.New("some tasks were rejected")
+)
+
+// apiErrorKind is how an error is answered
+type apiErrorKind struct {
+	status int
+	code   string
+}
+
+// apiErrors map known errors to their status and code, writeError answers them by it
+var apiErrors = map[error]apiErrorKind{
+	errInvalidBody:                 {http.StatusBadRequest, codeBadRequest},
+	errNoPrincipal:                 {http.StatusBadRequest, codeBadRequest},
+	errInvalidCreated:              {http.StatusBadRequest, codeValidation},
+	errInvalidFilter:               {http.StatusBadRequest, codeValidation},
+	errInvalidUsageRange:           {http.StatusBadRequest, codeValidation},
+	errInvalidStatsDays:            {http.StatusBadRequest, codeValidation},
+	errInvalidPlanName:             {http.StatusBadRequest, codeValidation},
+	errInvalidOwner:                {http.StatusBadRequest, codeValidation},
+	errInvalidShare:                {http.StatusBadRequest, codeValidation},
+	errNoAttachment:                {http.StatusBadRequest, codeValidation},
+	errInvalidDate:                 {http.StatusBadRequest, codeValidation},
+	errInvalidPage:                 {http.StatusBadRequest, codeValidation},
+	errInvalidIdempotencyKey:       {http.StatusBadRequest, codeBadRequest},
+	storages.ErrInvalidTask:        {http.StatusBadRequest, codeValidation},
+	storages.ErrInvalidProject:     {http.StatusBadRequest, codeValidation},
+	storages.ErrInvalidUser:        {http.StatusBadRequest, codeValidation},
+	storages.ErrInvalidTimezone:    {http.StatusBadRequest, codeValidation},
+	errInvalidResetToken:           {http.StatusBadRequest, codeInvalidLink},
+	oidc.ErrInvalidState:           {http.StatusBadRequest, codeBadRequest},
+	oidc.ErrInvalidIdentity:        {http.StatusBadGateway, codeBadGateway},
+	tokens.ErrInvalidToken:         {http.StatusUnauthorized, codeUnauthenticated},
+	storages.ErrInvalidCredentials: {http.StatusUnauthorized, codeInvalidCredentials},
+	errWrongPassword:               {http.StatusForbidden, codeInvalidCredentials},
+	errTwoFactorRequired:           {http.StatusUnauthorized, codeTwoFactorRequired},
+	errInvalidTwoFactor:            {http.StatusUnauthorized, codeInvalidTwoFactor},
+	errInvalidCSRF:                 {http.StatusForbidden, codeInvalidCSRF},
+	storages.ErrForbidden:          {http.StatusForbidden, codeForbidden},
+	errForbidden:                   {http.StatusForbidden, codeForbidden},
+	errAdminOnly:                   {http.StatusForbidden, codeForbidden},
+	errInsufficientScope:           {http.StatusForbidden, codeInsufficientScope},
+	storages.ErrNotFound:           {http.StatusNotFound, codeNotFound},
+	errUnknownProvider:             {http.StatusNotFound, codeNotFound},
+	errUnknownRoute:                {http.StatusNotFound, codeRouteNotFound},
+	errInvalidLink:                 {http.StatusNotFound, codeInvalidLink},
+	errMethodNotAllowed:            {http.StatusMethodNotAllowed, codeMethodNotAllowed},
+	storages.ErrConflict:           {http.StatusConflict, codeConflict},
+	errTwoFactorEnabled:            {http.StatusConflict, codeConflict},
+	errIdentityLinked:              {http.StatusConflict, codeConflict},
+	errUsernameTaken:               {http.StatusConflict, codeUsernameTaken},
+	errLinkRequired:                {http.StatusConflict, codeUsernameTaken},
+	errPreconditionFailed:          {http.StatusPreconditionFailed, codePreconditionFailed},
+	errIdempotencyKeyReused:        {http.StatusUnprocessableEntity, codeIdempotencyReused},
+	errAttachmentTooLarge:          {http.StatusRequestEntityTooLarge, codePayloadTooLarge},
+	errBodyTooLarge:                {http.StatusRequestEntityTooLarge, codePayloadTooLarge},
+	storages.ErrQuotaExceeded:      {http.StatusTooManyRequests, codeQuotaExceeded},
+	errTooManyLogins:               {http.StatusTooManyRequests, codeTooManyRequests},
+	errRateLimited:                 {http.StatusTooManyRequests, codeTooManyRequests},
+	errNotSupported:                {http.StatusNotImplemented, codeNotSupported},
+	errAttachmentsDisabled:         {http.StatusNotImplemented, codeNotSupported},
+	errLogLevelFixed:               {http.StatusNotImplemented, codeNotSupported},
+	errInternal:                    {http.StatusInternalServerError, codeInternal},
+	errHandlerTimeout:              {http.StatusServiceUnavailable, codeTimeout},
+}
+
+// statusCodes are the codes of errors which are not in apiErrors
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            codeBadRequest,
+	http.StatusUnauthorized:          codeUnauthenticated,
+	http.StatusForbidden:             codeForbidden,
+	http.StatusNotFound:              codeNotFound,
+	http.StatusMethodNotAllowed:      codeMethodNotAllowed,
+	http.StatusConflict:              codeConflict,
+	http.StatusPreconditionFailed:    codePreconditionFailed,
+	http.StatusRequestEntityTooLarge: codePayloadTooLarge,
+	http.StatusTooManyRequests:       codeTooManyRequests,
+	http.StatusNotImplemented:        codeNotSupported,
+	http.StatusBadGateway:            codeBadGateway,
+}
+
+// notFoundHandler answers paths which are not routed
+func notFoundHandler(resp http.ResponseWriter, req *http.Request) {
+	writeError(resp, errUnknownRoute)
+}
+
+// errorCode returns the code of err answered with status
+func errorCode(status int, err error) string {
+	if kind, ok := apiErrors[err]; ok {
+		return kind.code
+	}
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return codeInternal
+	}
+	return codeBadRequest
+}
+
+// writeError answers err with its status and code of apiErrors, every handler reports errors by it unless
+// the status depends on the request. Unknown errors are logged and hidden behind errInternal
+func writeError(resp http.ResponseWriter, err error) {
+	kind, ok := apiErrors[err]
+	if !ok {
+		failed(resp, err)
+		err, kind = errInternal, apiErrors[errInternal]
+	}
+	writeErrDetails(resp, kind.status, kind.code, err, nil)
+}
+
+// writeErrResp answers err with status, the code is the one of err or of status
+func writeErrResp(resp http.ResponseWriter, status int, err error) {
+	writeErrDetails(resp, status, errorCode(status, err), err, nil)
+}
+
+// writeErrDetails answers err with status and code along with details, such as the failures of the items
+// of a batch
+func writeErrDetails(resp http.ResponseWriter, status int, code string, err error, details interface{}) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	body := newErrResp(ApiError{Code: code, Message: err.Error(), Details: details})
+	if err := json.NewEncoder(resp).Encode(body); err != nil {
+		log.Println(err)
+	}
+}
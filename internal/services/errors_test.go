@@ -0,0 +1,41 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	requireTest := require.New(t)
+
+	for _, tc := range []struct {
+		err  error
+		code int
+		body string
+	}{
+		{storages.ErrQuotaExceeded, http.StatusTooManyRequests, `{"error": {"code": "QUOTA_EXCEEDED", "message": "user's daily-limit has been reached"}}`},
+		{storages.ErrInvalidCredentials, http.StatusUnauthorized, `{"error": {"code": "INVALID_CREDENTIALS", "message": "username or password is not correct"}}`},
+		{storages.ErrInvalidTask, http.StatusBadRequest, `{"error": {"code": "VALIDATION_ERROR", "message": "invalid task"}}`},
+		{errUnknownRoute, http.StatusNotFound, `{"error": {"code": "ROUTE_NOT_FOUND", "message": "no such route"}}`},
+		{errors.New("connection refused"), http.StatusInternalServerError, `{"error": {"code": "INTERNAL", "message": "internal error"}}`},
+	} {
+		w := httptest.NewRecorder()
+		writeError(w, tc.err)
+		requireTest.Equal(tc.code, w.Code, tc.err.Error())
+		requireTest.Equal("application/json", w.Header().Get("Content-Type"))
+		requireTest.JSONEq(tc.body, w.Body.String())
+	}
+
+	// errors answered with another status keep their code, unknown ones get the code of the status
+	w := httptest.NewRecorder()
+	writeErrResp(w, http.StatusBadRequest, storages.ErrInvalidCredentials)
+	requireTest.JSONEq(`{"error": {"code": "INVALID_CREDENTIALS", "message": "username or password is not correct"}}`, w.Body.String())
+	w = httptest.NewRecorder()
+	writeErrResp(w, http.StatusBadRequest, errors.New("password is too short"))
+	requireTest.JSONEq(`{"error": {"code": "BAD_REQUEST", "message": "password is too short"}}`, w.Body.String())
+}
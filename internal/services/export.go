@@ -0,0 +1,179 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	mimeJSON   = "application/json"
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+	// streamFlushRows is how many rows of a streamed list are written between flushes
+	streamFlushRows = 100
+)
+
+// csvTaskHeader names the columns of tasks exported as CSV
+var csvTaskHeader = []string{"id", "content", "status", "priority", "due_at", "completed_at", "tags", "project_id",
+	"create_at", "update_at", "version"}
+
+// listFormat is the media type of task lists Accept prefers, JSON unless CSV or NDJSON are preferred to it
+func listFormat(req *http.Request) string {
+	best, bestQ := mimeJSON, 0.0
+	for _, part := range strings.Split(req.Header.Get("Accept"), ",") {
+		params := strings.Split(part, ";")
+		mime := strings.ToLower(strings.TrimSpace(params[0]))
+		if mime != mimeJSON && mime != mimeCSV && mime != mimeNDJSON {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			if v := strings.TrimSpace(param); strings.HasPrefix(v, "q=") {
+				q, _ = strconv.ParseFloat(v[2:], 64)
+			}
+		}
+		if q > bestQ {
+			best, bestQ = mime, q
+		}
+	}
+	return best
+}
+
+// eachOf passes tasks on to fn one by one like storages.TaskStreamer does
+func eachOf(tasks []*storages.Task) func(fn func(*storages.Task) error) error {
+	return func(fn func(*storages.Task) error) error {
+		for _, task := range tasks {
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// streamTasks answers the tasks each passes on as rows of format, they're flushed in batches as they're written
+// so that long lists aren't buffered. An error before the first row is answered as usual, a later one can only
+// cut the response short. Streamed lists have no validators since their body isn't known up front
+func streamTasks(resp http.ResponseWriter, format string, each func(fn func(*storages.Task) error) error) {
+	var rows *taskRows
+	err := each(func(task *storages.Task) error {
+		if rows == nil {
+			if rows = startRows(resp, format); rows.err != nil {
+				return rows.err
+			}
+		}
+		return rows.write(task)
+	})
+	if err != nil {
+		if rows == nil {
+			writeError(resp, err)
+		} else {
+			log.Println(err)
+		}
+		return
+	}
+	if rows == nil {
+		rows = startRows(resp, format)
+	}
+	if err := rows.flush(); err != nil {
+		log.Println(err)
+	}
+}
+
+// taskRows writes tasks as CSV records or NDJSON lines, err is the first error written
+type taskRows struct {
+	resp http.ResponseWriter
+	csv  *csv.Writer
+	json *json.Encoder
+	n    int
+	err  error
+}
+
+// startRows answers 200 with the headers of format and the CSV header row
+func startRows(resp http.ResponseWriter, format string) *taskRows {
+	rows := &taskRows{resp: resp}
+	if format == mimeCSV {
+		resp.Header().Set("Content-Type", mimeCSV+"; charset=utf-8")
+		resp.Header().Set("Content-Disposition", `attachment; filename="tasks.csv"`)
+		rows.csv = csv.NewWriter(resp)
+	} else {
+		resp.Header().Set("Content-Type", format)
+		rows.json = json.NewEncoder(resp)
+	}
+	resp.WriteHeader(http.StatusOK)
+	if rows.csv != nil {
+		rows.err = rows.csv.Write(csvTaskHeader)
+	}
+	return rows
+}
+
+func (r *taskRows) write(task *storages.Task) error {
+	if r.csv != nil {
+		r.err = r.csv.Write(csvTaskRecord(task))
+	} else {
+		r.err = r.json.Encode(task)
+	}
+	if r.err != nil {
+		return r.err
+	}
+	if r.n++; r.n%streamFlushRows == 0 {
+		return r.flush()
+	}
+	return nil
+}
+
+func (r *taskRows) flush() error {
+	if r.csv != nil {
+		r.csv.Flush()
+		if r.err = r.csv.Error(); r.err != nil {
+			return r.err
+		}
+	}
+	if flusher, ok := r.resp.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// csvCell escapes text written by users, spreadsheets run cells which start with =, +, -, @, tab or CR as
+// formulas so those are prefixed with ' for them to show the text instead
+func csvCell(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// csvTaskRecord is the record of task under csvTaskHeader, times are RFC 3339 and missing values are empty
+func csvTaskRecord(task *storages.Task) []string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	projectId := ""
+	if task.ProjectId != nil {
+		projectId = strconv.Itoa(*task.ProjectId)
+	}
+	return []string{
+		strconv.Itoa(task.Id),
+		csvCell(task.Content),
+		string(task.Status),
+		strconv.Itoa(task.Priority),
+		formatTime(task.DueAt),
+		formatTime(task.CompletedAt),
+		csvCell(strings.Join(task.Tags, ",")),
+		projectId,
+		formatTime(&task.CreateAt),
+		formatTime(task.UpdateAt),
+		strconv.Itoa(task.Version),
+	}
+}
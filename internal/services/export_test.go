@@ -0,0 +1,107 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFormat(t *testing.T) {
+	for accept, format := range map[string]string{
+		"":                                   mimeJSON,
+		"*/*":                                mimeJSON,
+		"text/csv":                           mimeCSV,
+		"application/x-ndjson, */*;q=0.1":    mimeNDJSON,
+		"application/json, text/csv;q=0.5":   mimeJSON,
+		"application/json;q=0.5, text/csv":   mimeCSV,
+		"text/csv;q=0, application/x-ndjson": mimeNDJSON,
+		"text/xml":                           mimeJSON,
+	} {
+		req := httptest.NewRequest("GET", "/tasks", nil)
+		req.Header.Set("Accept", accept)
+		require.Equal(t, format, listFormat(req), accept)
+	}
+}
+
+func TestCSVCell(t *testing.T) {
+	requireTest := require.New(t)
+
+	for _, formula := range []string{`=HYPERLINK("https://evil.example","x")`, "+1", "-1+2", "@SUM(A1)", "\tx", "\rx"} {
+		requireTest.Equal("'"+formula, csvCell(formula))
+	}
+	for _, text := range []string{"", "milk", "a=b", "'quoted"} {
+		requireTest.Equal(text, csvCell(text))
+	}
+}
+
+func TestExportTasks(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	do := func(method, path, body, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w
+	}
+	requireTest.Equal(http.StatusOK, do("POST", "/tasks", `{"content": "milk, \"oat\"", "tags": ["shop", "home"]}`, "").Code)
+	requireTest.Equal(http.StatusOK, do("POST", "/tasks", `{"content": "eggs"}`, "").Code)
+	list := "/tasks?created_date=" + time.Now().UTC().Format("2006-01-02")
+
+	w := do("GET", list, "", "text/csv")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal("text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	requireTest.Empty(w.Header().Get("ETag"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	requireTest.NoError(err)
+	requireTest.Len(records, 3)
+	requireTest.Equal(csvTaskHeader, records[0])
+	requireTest.Equal([]string{`milk, "oat"`, "todo", "home,shop"}, []string{records[1][1], records[1][2], records[1][6]})
+	requireTest.Equal("eggs", records[2][1])
+
+	w = do("GET", list+"&render=html", "", "application/x-ndjson")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal(mimeNDJSON, w.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	requireTest.Len(lines, 2)
+	task := &storages.Task{}
+	requireTest.NoError(json.Unmarshal([]byte(lines[1]), task))
+	requireTest.Equal("eggs", task.ContentHTML)
+
+	// errors are answered as usual before any row is written
+	w = do("GET", list+"&sort_by=size", "", "text/csv")
+	requireTest.Equal(http.StatusBadRequest, w.Code)
+	requireTest.Equal("application/json", w.Header().Get("Content-Type"))
+
+	w = do("GET", "/tasks?created_date=2000-01-01", "", "text/csv")
+	records, err = csv.NewReader(w.Body).ReadAll()
+	requireTest.NoError(err)
+	requireTest.Equal([][]string{csvTaskHeader}, records)
+
+	w = do("GET", "/tasks?overdue=true", "", "application/x-ndjson")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Empty(w.Body.String())
+
+	// cells are no formulas to spreadsheets, even for tasks shared owners didn't write
+	requireTest.Equal(http.StatusOK, do("POST", "/tasks", `{"content": "=HYPERLINK(\"https://evil.example\")"}`, "").Code)
+	w = do("GET", list, "", "text/csv")
+	records, err = csv.NewReader(w.Body).ReadAll()
+	requireTest.NoError(err)
+	requireTest.Len(records, 4)
+	requireTest.Equal(`'=HYPERLINK("https://evil.example")`, records[3][1])
+}
@@ -0,0 +1,5 @@
+// Package graph is the generated executor of the GraphQL schema of schema.graphqls, the resolvers are
+// implemented by the services package
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate --config gqlgen.yml
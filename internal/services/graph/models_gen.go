@@ -0,0 +1,42 @@
+// Code generated by github.com/99designs/gqlgen, DO NOT EDIT.
+
+package graph
+
+import (
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+type NewTask struct {
+	Content   string     `json:"content"`
+	DueAt     *time.Time `json:"dueAt"`
+	Priority  *int       `json:"priority"`
+	Tags      []string   `json:"tags"`
+	ProjectID *int       `json:"projectId"`
+}
+
+// TaskChanges replace the fields of a task, version must be the one they are based on
+type TaskChanges struct {
+	Content  string     `json:"content"`
+	Status   *string    `json:"status"`
+	DueAt    *time.Time `json:"dueAt"`
+	Priority *int       `json:"priority"`
+	Version  int        `json:"version"`
+}
+
+type TaskFilter struct {
+	Completed *bool `json:"completed"`
+	// tags returns only tasks having all of the tags
+	Tags      []string `json:"tags"`
+	ProjectID *int     `json:"projectId"`
+	// sortBy is one of create_at, priority and position
+	SortBy *string `json:"sortBy"`
+}
+
+// TaskPage is a page of tasks, hasNextPage tells whether tasks follow the page
+type TaskPage struct {
+	Nodes       []*storages.Task `json:"nodes"`
+	TotalCount  int              `json:"totalCount"`
+	HasNextPage bool             `json:"hasNextPage"`
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/services/graph"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// maxGraphQLSize is the longest body of a GraphQL request
+	maxGraphQLSize = 16 << 10
+	// maxGraphQLComplexity bounds how many fields a query may select
+	maxGraphQLComplexity = 200
+	// defaultTaskPage and maxTaskPage are the default and the largest page of tasks
+	defaultTaskPage, maxTaskPage = 50, 100
+)
+
+var (
+	errInvalidDate = errors.New("createdDate must be YYYY-MM-DD")
+	errInvalidPage = errors.New("first must be between 1 and 100 and offset can't be negative")
+)
+
+// graphqlScope is the scope of GraphQL requests, mutations also need tasks:write
+func graphqlScope(*http.Request) string {
+	return tokens.ScopeTasksRead
+}
+
+// graphqlHandler serves the GraphQL API of graph/schema.graphqls at /graphql, queries may be sent by GET
+// and POST requests, mutations by POST requests only
+func (s *ToDoService) graphqlHandler() http.HandlerFunc {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graphResolver{s: s}}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(maxGraphQLComplexity))
+	srv.SetErrorPresenter(presentGraphQLErr)
+
+	return func(resp http.ResponseWriter, req *http.Request) {
+		req.Body = http.MaxBytesReader(resp, req.Body, maxGraphQLSize)
+		srv.ServeHTTP(resp, req)
+	}
+}
+
+// presentGraphQLErr hides unknown errors of resolvers behind errInternal, they are logged
+func presentGraphQLErr(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	cause := errors.Unwrap(gqlErr)
+	if cause == nil {
+		return gqlErr
+	}
+	switch cause {
+	case storages.ErrNotFound, storages.ErrForbidden, storages.ErrConflict, storages.ErrInvalidTask,
+		storages.ErrInvalidProject, storages.ErrQuotaExceeded, errForbidden, errInvalidOwner, errNoPrincipal,
+		errNotSupported, errInsufficientScope, errInvalidFilter, errInvalidDate, errInvalidPage:
+		return gqlErr
+	default:
+		log.Println(cause)
+		gqlErr.Message = errInternal.Error()
+		return gqlErr
+	}
+}
+
+// graphResolver resolves the GraphQL schema against the storage with the authorization policy of the HTTP API
+type graphResolver struct {
+	s *ToDoService
+}
+
+func (r *graphResolver) Mutation() graph.MutationResolver { return &graphMutation{r} }
+func (r *graphResolver) Query() graph.QueryResolver       { return &graphQuery{r} }
+func (r *graphResolver) Task() graph.TaskResolver         { return &graphTask{r} }
+func (r *graphResolver) User() graph.UserResolver         { return &graphUser{r} }
+
+// user returns the user of id
+func (r *graphResolver) user(ctx context.Context, id int) (*storages.User, error) {
+	users, ok := r.s.store.(storages.PasswordStore)
+	if !ok {
+		return nil, errNotSupported
+	}
+	return users.GetUser(ctx, id)
+}
+
+// owner returns the owner of the call, see ownerOf
+func (r *graphResolver) owner(ctx context.Context, owner *int, a access) (int, error) {
+	if a == accessWrite {
+		if p, _ := principalFromCtx(ctx); !p.allows(tokens.ScopeTasksWrite) {
+			return 0, errInsufficientScope
+		}
+	}
+	id := 0
+	if owner != nil {
+		if *owner <= 0 {
+			return 0, errInvalidOwner
+		}
+		id = *owner
+	}
+	return r.s.ownerOf(ctx, id, a)
+}
+
+type graphQuery struct{ *graphResolver }
+
+func (r *graphQuery) Me(ctx context.Context) (*storages.User, error) {
+	userID, ok := userIDFromCtx(ctx)
+	if !ok {
+		return nil, errNoPrincipal
+	}
+	return r.user(ctx, userID)
+}
+
+func (r *graphQuery) Tasks(ctx context.Context, createdDate string, owner *int, filter *graph.TaskFilter, first *int, offset *int) (*graph.TaskPage, error) {
+	usrId, err := r.owner(ctx, owner, accessRead)
+	if err != nil {
+		return nil, err
+	}
+	date, err := r.s.createdDate(ctx, usrId, createdDate)
+	if err == errInvalidCreated {
+		return nil, errInvalidDate
+	}
+	if err != nil {
+		return nil, err
+	}
+	limit, skip := defaultTaskPage, 0
+	if first != nil {
+		limit = *first
+	}
+	if offset != nil {
+		skip = *offset
+	}
+	if limit < 1 || limit > maxTaskPage || skip < 0 {
+		return nil, errInvalidPage
+	}
+
+	taskFilter := storages.TaskFilter{}
+	filtered := false
+	if filter != nil {
+		taskFilter.Completed, taskFilter.Tags, taskFilter.ProjectId = filter.Completed, filter.Tags, filter.ProjectID
+		if filter.SortBy != nil {
+			taskFilter.SortBy = storages.TaskSort(*filter.SortBy)
+			if !taskFilter.SortBy.Valid() {
+				return nil, errInvalidFilter
+			}
+		}
+		filtered = filter.Completed != nil || len(filter.Tags) > 0 || filter.ProjectID != nil || filter.SortBy != nil
+	}
+	tasks, err := r.s.filterTasks(ctx, usrId, date, taskFilter, filtered)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &graph.TaskPage{Nodes: []*storages.Task{}, TotalCount: len(tasks)}
+	if skip < len(tasks) {
+		end := skip + limit
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+		page.Nodes = tasks[skip:end]
+		page.HasNextPage = end < len(tasks)
+	}
+	return page, nil
+}
+
+type graphMutation struct{ *graphResolver }
+
+func (r *graphMutation) CreateTask(ctx context.Context, input graph.NewTask, owner *int) (*storages.Task, error) {
+	usrId, err := r.owner(ctx, owner, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &storages.Task{UsrId: usrId, Content: input.Content, DueAt: input.DueAt, Tags: input.Tags, ProjectId: input.ProjectID}
+	if input.Priority != nil {
+		task.Priority = *input.Priority
+	}
+	if err := r.s.store.InsertTask(ctx, task); err != nil {
+		r.s.countQuota(err)
+		return nil, err
+	}
+	r.s.publishTask(events.Created, usrId, task.Id, task)
+	r.s.auditTask(ctx, storages.TaskCreated, usrId, task.Id, nil)
+	return task, nil
+}
+
+func (r *graphMutation) UpdateTask(ctx context.Context, id int, input graph.TaskChanges, owner *int) (*storages.Task, error) {
+	updater, ok := r.s.store.(storages.TaskUpdater)
+	if !ok {
+		return nil, errNotSupported
+	}
+	usrId, err := r.owner(ctx, owner, accessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &storages.Task{Id: id, UsrId: usrId, Content: input.Content, DueAt: input.DueAt, Version: input.Version}
+	if input.Status != nil {
+		task.Status = storages.TaskStatus(*input.Status)
+	}
+	if input.Priority != nil {
+		task.Priority = *input.Priority
+	}
+	before := r.s.snapshotTask(ctx, usrId, id)
+	if err := updater.UpdateTask(ctx, task); err != nil {
+		return nil, err
+	}
+	r.s.publishTask(events.Updated, usrId, id, task)
+	r.s.auditTask(ctx, storages.TaskUpdated, usrId, id, before)
+	return task, nil
+}
+
+func (r *graphMutation) DeleteTask(ctx context.Context, id int, owner *int) (bool, error) {
+	archiver, ok := r.s.store.(storages.TaskArchiver)
+	if !ok {
+		return false, errNotSupported
+	}
+	usrId, err := r.owner(ctx, owner, accessWrite)
+	if err != nil {
+		return false, err
+	}
+
+	before := r.s.snapshotTask(ctx, usrId, id)
+	if err := archiver.DeleteTask(ctx, usrId, id); err != nil {
+		return false, err
+	}
+	r.s.publishTask(events.Deleted, usrId, id, nil)
+	r.s.auditTask(ctx, storages.TaskDeleted, usrId, id, before)
+	return true, nil
+}
+
+type graphTask struct{ *graphResolver }
+
+func (r *graphTask) Status(_ context.Context, task *storages.Task) (string, error) {
+	return string(task.Status), nil
+}
+
+func (r *graphTask) Owner(ctx context.Context, task *storages.Task) (*storages.User, error) {
+	return r.user(ctx, task.UsrId)
+}
+
+type graphUser struct{ *graphResolver }
+
+func (r *graphUser) Role(_ context.Context, usr *storages.User) (string, error) {
+	return string(usr.Role), nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQL(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+	graphql := s.graphqlHandler()
+
+	type result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	do := func(p principal, query string, vars map[string]interface{}) result {
+		body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+		requireTest.NoError(err)
+		req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		graphql(w, req.WithContext(withPrincipal(req.Context(), p)))
+		res := result{}
+		requireTest.NoError(json.NewDecoder(w.Body).Decode(&res))
+		return res
+	}
+	alice := principal{UserID: usr.Id}
+
+	create := `mutation($content: String!) { createTask(input: {content: $content, tags: ["home"]}) { id content tags owner { username } } }`
+	for _, content := range []string{"milk", "bread", "eggs"} {
+		res := do(alice, create, map[string]interface{}{"content": content})
+		requireTest.Empty(res.Errors)
+		requireTest.Contains(string(res.Data), `"owner":{"username":"alice"}`)
+	}
+
+	date := time.Now().UTC().Format("2006-01-02")
+	list := `query($date: String!, $first: Int, $offset: Int) {
+		tasks(createdDate: $date, first: $first, offset: $offset) { nodes { id content status version } totalCount hasNextPage }
+	}`
+	res := do(alice, list, map[string]interface{}{"date": date, "first": 2})
+	requireTest.Empty(res.Errors)
+	page := &struct {
+		Tasks struct {
+			Nodes []struct {
+				Id      int    `json:"id"`
+				Content string `json:"content"`
+				Status  string `json:"status"`
+				Version int    `json:"version"`
+			} `json:"nodes"`
+			TotalCount  int  `json:"totalCount"`
+			HasNextPage bool `json:"hasNextPage"`
+		} `json:"tasks"`
+	}{}
+	requireTest.NoError(json.Unmarshal(res.Data, page))
+	requireTest.Len(page.Tasks.Nodes, 2)
+	requireTest.Equal(3, page.Tasks.TotalCount)
+	requireTest.True(page.Tasks.HasNextPage)
+	requireTest.Equal("todo", page.Tasks.Nodes[0].Status)
+	res = do(alice, list, map[string]interface{}{"date": date, "first": 2, "offset": 2})
+	requireTest.NoError(json.Unmarshal(res.Data, page))
+	requireTest.Len(page.Tasks.Nodes, 1)
+	requireTest.False(page.Tasks.HasNextPage)
+	res = do(alice, list, map[string]interface{}{"date": date, "first": 1000})
+	requireTest.Equal(errInvalidPage.Error(), res.Errors[0].Message)
+
+	task := page.Tasks.Nodes[0]
+	update := `mutation($id: Int!, $version: Int!) { updateTask(id: $id, input: {content: "oat milk", status: "done", version: $version}) { content status version } }`
+	res = do(alice, update, map[string]interface{}{"id": task.Id, "version": task.Version})
+	requireTest.Empty(res.Errors)
+	requireTest.JSONEq(`{"updateTask": {"content": "oat milk", "status": "done", "version": `+strconv.Itoa(task.Version+1)+`}}`, string(res.Data))
+	res = do(alice, update, map[string]interface{}{"id": task.Id, "version": task.Version})
+	requireTest.Equal(storages.ErrConflict.Error(), res.Errors[0].Message)
+
+	readOnly := principal{UserID: usr.Id, Scopes: []string{tokens.ScopeTasksRead}}
+	deleteTask := `mutation($id: Int!) { deleteTask(id: $id) }`
+	res = do(readOnly, deleteTask, map[string]interface{}{"id": task.Id})
+	requireTest.Equal(errInsufficientScope.Error(), res.Errors[0].Message)
+	res = do(principal{UserID: usr.Id + 1}, `query($owner: Int) { tasks(createdDate: "`+date+`", owner: $owner) { totalCount } }`,
+		map[string]interface{}{"owner": usr.Id})
+	requireTest.Equal(errForbidden.Error(), res.Errors[0].Message)
+
+	res = do(alice, deleteTask, map[string]interface{}{"id": task.Id})
+	requireTest.Empty(res.Errors)
+	requireTest.JSONEq(`{"deleteTask": true}`, string(res.Data))
+	res = do(alice, `{ me { id username role } }`, nil)
+	requireTest.JSONEq(`{"me": {"id": `+strconv.Itoa(usr.Id)+`, "username": "alice", "role": "user"}}`, string(res.Data))
+}
+
+func TestGraphQLTimezone(t *testing.T) {
+	requireTest := require.New(t)
+	ctx := context.Background()
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+	requireTest.NoError(m.SetTimezone(ctx, usr.Id, "Etc/GMT-14"))
+	// it's March 2nd already 14 hours ahead of UTC
+	createAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	requireTest.NoError(m.Seed(ctx, &storages.Fixtures{Tasks: []*storages.Task{{UsrId: usr.Id, Content: "milk", CreateAt: createAt}}}))
+	graphql := NewToDoService(testJWTKey, ":6000", m).graphqlHandler()
+
+	count := func(date string) int {
+		body, err := json.Marshal(map[string]interface{}{
+			"query":     `query($date: String!) { tasks(createdDate: $date) { totalCount } }`,
+			"variables": map[string]interface{}{"date": date},
+		})
+		requireTest.NoError(err)
+		req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		graphql(w, req.WithContext(withPrincipal(ctx, principal{UserID: usr.Id})))
+		res := &struct {
+			Data struct {
+				Tasks struct {
+					TotalCount int `json:"totalCount"`
+				} `json:"tasks"`
+			} `json:"data"`
+		}{}
+		requireTest.NoError(json.NewDecoder(w.Body).Decode(res))
+		return res.Data.Tasks.TotalCount
+	}
+
+	// dates are days of the timezone of the user like those of GET /tasks
+	requireTest.Equal(1, count("2021-03-02"))
+	requireTest.Equal(0, count("2021-03-01"))
+}
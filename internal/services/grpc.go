@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/api/togopb"
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcScopes are the scopes scoped tokens need to call the methods of the gRPC API,
+// methods which are not listed don't require a token
+var grpcScopes = map[string]string{
+	"/togo.v1.Tasks/ListTasks":  tokens.ScopeTasksRead,
+	"/togo.v1.Tasks/CreateTask": tokens.ScopeTasksWrite,
+	"/togo.v1.Tasks/DeleteTask": tokens.ScopeTasksWrite,
+}
+
+// newGRPCServer returns the gRPC server of the Auth and Tasks services of s, over TLS unless tlsConfig is nil
+func (s *ToDoService) newGRPCServer(tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(logUnary, s.authUnary)}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(opts...)
+	togopb.RegisterAuthServer(server, &grpcAuth{s: s})
+	togopb.RegisterTasksServer(server, &grpcTasks{s: s})
+	return server
+}
+
+// shareGRPC serves the gRPC API by the HTTP server so that both are served at one port and by the same middlewares,
+// those wrapping s.server.Handler later on. The HTTP server terminates TLS of both APIs, without TLS gRPC clients
+// talk HTTP/2 in clear text (h2c)
+func (s *ToDoService) shareGRPC() {
+	s.grpcShared = true
+	s.grpcServer = s.newGRPCServer(nil)
+	s.server.Handler = grpcHandler(s.grpcServer, s.server.Handler)
+	// the TLS config of WithTLS takes precedence over the one of WithGRPC
+	if s.server.TLSConfig == nil {
+		s.server.TLSConfig = s.grpcTLS
+	}
+	if s.server.TLSConfig == nil {
+		s.server.Handler = h2c.NewHandler(s.server.Handler, &http2.Server{})
+	}
+}
+
+// grpcHandler hands gRPC requests to server and the others to next
+func grpcHandler(server *grpc.Server, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+			server.ServeHTTP(resp, req)
+			return
+		}
+		next.ServeHTTP(resp, req)
+	})
+}
+
+// listenAndServe serves the HTTP server over TLS once it has a config
+func (s *ToDoService) listenAndServe() error {
+	if s.server.TLSConfig != nil {
+		return s.server.ListenAndServeTLS("", "")
+	}
+	return s.server.ListenAndServe()
+}
+
+// serveGRPC serves the gRPC API at addr until Shutdown, failures are reported by HttpServerErr
+func (s *ToDoService) serveGRPC(addr string) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.reportServerErr(err)
+		return
+	}
+	go func() {
+		if err := s.grpcServer.Serve(lis); err != nil {
+			s.reportServerErr(err)
+		}
+	}()
+}
+
+// reportServerErr reports the first failure of the servers, later ones are only logged
+func (s *ToDoService) reportServerErr(err error) {
+	select {
+	case s.serverErr <- err:
+	default:
+		log.Println(err)
+	}
+}
+
+// logUnary logs the method, the status code and the duration of calls
+func logUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	log.Println(info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
+
+// authUnary requires a valid token in the authorization metadata of calls to the methods of grpcScopes and puts
+// its principal in the context, scoped tokens must have the scope of the method
+func (s *ToDoService) authUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	scope, ok := grpcScopes[info.FullMethod]
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	var authToken string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("authorization"); len(v) > 0 {
+			authToken = strings.TrimPrefix(v[0], "Bearer ")
+		}
+	}
+	if authToken == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing access token")
+	}
+	claims, err := s.tokens.Verify(authToken)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	p := principal{UserID: claims.UserId, Role: storages.Role(claims.Role), Scopes: claims.Scopes()}
+	if !p.allows(scope) {
+		return nil, status.Error(codes.PermissionDenied, errInsufficientScope.Error())
+	}
+	return handler(withPrincipal(ctx, p), req)
+}
+
+// grpcClientOf tells where a call comes from
+func grpcClientOf(ctx context.Context) client {
+	var c client
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			c.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("user-agent"); len(v) > 0 {
+			c.UserAgent = v[0]
+			if len(c.UserAgent) > maxUserAgentLen {
+				c.UserAgent = c.UserAgent[:maxUserAgentLen]
+			}
+		}
+	}
+	return c
+}
+
+// grpcErr returns the status of err, unknown errors are logged and hidden behind errInternal
+func grpcErr(err error) error {
+	switch err {
+	case storages.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case storages.ErrForbidden, errForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case storages.ErrConflict:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case storages.ErrInvalidTask, storages.ErrInvalidProject, errInvalidOwner:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case storages.ErrQuotaExceeded, errTooManyLogins:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case storages.ErrInvalidCredentials, errTwoFactorRequired, errInvalidTwoFactor, errNoPrincipal:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errNotSupported:
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		log.Println(err)
+		return status.Error(codes.Internal, errInternal.Error())
+	}
+}
+
+type grpcAuth struct {
+	togopb.UnimplementedAuthServer
+	s *ToDoService
+}
+
+func (a *grpcAuth) Login(ctx context.Context, req *togopb.LoginRequest) (*togopb.LoginResponse, error) {
+	params := &loginParams{Username: req.GetUsername(), Password: req.GetPassword(), OTP: req.GetOtp()}
+	usr, _, err := a.s.login(ctx, grpcClientOf(ctx), params)
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+
+	token, err := a.s.createToken(usr)
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+	return &togopb.LoginResponse{AccessToken: token, ExpiresIn: int64(a.s.tokens.TTL() / time.Second)}, nil
+}
+
+type grpcTasks struct {
+	togopb.UnimplementedTasksServer
+	s *ToDoService
+}
+
+func (t *grpcTasks) ListTasks(ctx context.Context, req *togopb.ListTasksRequest) (*togopb.ListTasksResponse, error) {
+	owner, err := t.s.ownerOf(ctx, int(req.GetOwner()), accessRead)
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+	createdDate, err := t.s.createdDate(ctx, owner, req.GetCreatedDate())
+	if err == errInvalidCreated {
+		return nil, status.Error(codes.InvalidArgument, errInvalidCreated.Error())
+	}
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+
+	tasks, err := t.s.store.GetTasks(ctx, owner, createdDate)
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+	resp := &togopb.ListTasksResponse{Tasks: make([]*togopb.Task, 0, len(tasks))}
+	for _, task := range tasks {
+		resp.Tasks = append(resp.Tasks, grpcTask(task))
+	}
+	return resp, nil
+}
+
+func (t *grpcTasks) CreateTask(ctx context.Context, req *togopb.CreateTaskRequest) (*togopb.Task, error) {
+	owner, err := t.s.ownerOf(ctx, int(req.GetOwner()), accessWrite)
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+
+	task := &storages.Task{
+		UsrId:    owner,
+		Content:  req.GetContent(),
+		Priority: int(req.GetPriority()),
+		Tags:     req.GetTags(),
+	}
+	if req.DueAt != nil {
+		if err := req.DueAt.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		due := req.DueAt.AsTime()
+		task.DueAt = &due
+	}
+	if id := int(req.GetProjectId()); id > 0 {
+		task.ProjectId = &id
+	}
+	if err := t.s.store.InsertTask(ctx, task); err != nil {
+		t.s.countQuota(err)
+		return nil, grpcErr(err)
+	}
+	t.s.publishTask(events.Created, owner, task.Id, task)
+	t.s.auditTask(ctx, storages.TaskCreated, owner, task.Id, nil)
+	return grpcTask(task), nil
+}
+
+func (t *grpcTasks) DeleteTask(ctx context.Context, req *togopb.DeleteTaskRequest) (*togopb.DeleteTaskResponse, error) {
+	archiver, ok := t.s.store.(storages.TaskArchiver)
+	if !ok {
+		return nil, grpcErr(errNotSupported)
+	}
+	owner, err := t.s.ownerOf(ctx, int(req.GetOwner()), accessWrite)
+	if err != nil {
+		return nil, grpcErr(err)
+	}
+
+	before := t.s.snapshotTask(ctx, owner, int(req.GetId()))
+	if err := archiver.DeleteTask(ctx, owner, int(req.GetId())); err != nil {
+		return nil, grpcErr(err)
+	}
+	t.s.publishTask(events.Deleted, owner, int(req.GetId()), nil)
+	t.s.auditTask(ctx, storages.TaskDeleted, owner, int(req.GetId()), before)
+	return &togopb.DeleteTaskResponse{}, nil
+}
+
+func grpcTask(task *storages.Task) *togopb.Task {
+	pb := &togopb.Task{
+		Id:       int64(task.Id),
+		UsrId:    int64(task.UsrId),
+		Content:  task.Content,
+		CreateAt: timestamppb.New(task.CreateAt),
+		Status:   string(task.Status),
+		Priority: int64(task.Priority),
+		Tags:     task.Tags,
+		Version:  int64(task.Version),
+	}
+	if task.DueAt != nil {
+		pb.DueAt = timestamppb.New(*task.DueAt)
+	}
+	if task.ProjectId != nil {
+		pb.ProjectId = int64(*task.ProjectId)
+	}
+	return pb
+}
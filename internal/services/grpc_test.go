@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/api/togopb"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPC(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	pwdHash, err := password.Hash("s3cretpass")
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", PwdHash: pwdHash, MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	lis := bufconn.Listen(1 << 16)
+	server := s.newGRPCServer(nil)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	requireTest.NoError(err)
+	defer func() {
+		_ = conn.Close()
+	}()
+	auth, tasks := togopb.NewAuthClient(conn), togopb.NewTasksClient(conn)
+	ctx := context.Background()
+
+	_, err = auth.Login(ctx, &togopb.LoginRequest{Username: "alice", Password: "wrong"})
+	requireTest.Equal(codes.Unauthenticated, status.Code(err))
+	login, err := auth.Login(ctx, &togopb.LoginRequest{Username: "alice", Password: "s3cretpass"})
+	requireTest.NoError(err)
+	requireTest.Equal(int64(s.tokens.TTL()/time.Second), login.ExpiresIn)
+
+	_, err = tasks.CreateTask(ctx, &togopb.CreateTaskRequest{Content: "milk"})
+	requireTest.Equal(codes.Unauthenticated, status.Code(err))
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+login.AccessToken)
+	task, err := tasks.CreateTask(authCtx, &togopb.CreateTaskRequest{Content: "milk", Tags: []string{"home"}})
+	requireTest.NoError(err)
+	requireTest.Equal(int64(usr.Id), task.UsrId)
+	_, err = tasks.CreateTask(authCtx, &togopb.CreateTaskRequest{Content: "bread"})
+	requireTest.Equal(codes.ResourceExhausted, status.Code(err))
+	_, err = tasks.CreateTask(authCtx, &togopb.CreateTaskRequest{Content: "bread", Owner: int64(usr.Id + 1)})
+	requireTest.Equal(codes.PermissionDenied, status.Code(err))
+
+	date := task.CreateAt.AsTime().Format("2006-01-02")
+	list, err := tasks.ListTasks(authCtx, &togopb.ListTasksRequest{CreatedDate: date})
+	requireTest.NoError(err)
+	requireTest.Len(list.Tasks, 1)
+	requireTest.Equal("milk", list.Tasks[0].Content)
+	list, err = tasks.ListTasks(authCtx, &togopb.ListTasksRequest{CreatedDate: "today"})
+	requireTest.NoError(err)
+	requireTest.Len(list.Tasks, 1)
+	_, err = tasks.ListTasks(authCtx, &togopb.ListTasksRequest{CreatedDate: "yesterday"})
+	requireTest.Equal(codes.InvalidArgument, status.Code(err))
+
+	readOnly, err := s.tokens.IssueScoped(usr.Id, usr.MaxTodo, "", []string{tokens.ScopeTasksRead}, time.Hour)
+	requireTest.NoError(err)
+	readCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+readOnly)
+	_, err = tasks.ListTasks(readCtx, &togopb.ListTasksRequest{CreatedDate: date})
+	requireTest.NoError(err)
+	_, err = tasks.DeleteTask(readCtx, &togopb.DeleteTaskRequest{Id: task.Id})
+	requireTest.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = tasks.DeleteTask(authCtx, &togopb.DeleteTaskRequest{Id: task.Id})
+	requireTest.NoError(err)
+	_, err = tasks.DeleteTask(authCtx, &togopb.DeleteTaskRequest{Id: task.Id + 100})
+	requireTest.Equal(codes.NotFound, status.Code(err))
+}
+
+func TestSharedGRPC(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	pwdHash, err := password.Hash("s3cretpass")
+	requireTest.NoError(err)
+	requireTest.NoError(m.CreateUser(context.Background(), &storages.User{Username: "alice", PwdHash: pwdHash, MaxTodo: 1}))
+	s := NewToDoService(testJWTKey, ":6000", m)
+	s.shareGRPC()
+	defer s.grpcServer.Stop()
+
+	server := httptest.NewServer(s.server.Handler)
+	defer server.Close()
+	conn, err := grpc.Dial(server.Listener.Addr().String(), grpc.WithInsecure())
+	requireTest.NoError(err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	login, err := togopb.NewAuthClient(conn).Login(context.Background(), &togopb.LoginRequest{Username: "alice", Password: "s3cretpass"})
+	requireTest.NoError(err)
+	requireTest.NotEmpty(login.AccessToken)
+
+	resp, err := http.Post(server.URL+"/login", "application/json", strings.NewReader(`{"username": "alice", "password": "s3cretpass"}`))
+	requireTest.NoError(err)
+	_ = resp.Body.Close()
+	requireTest.Equal(http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// readyTimeout bounds the checks of a readiness probe
+	readyTimeout = 2 * time.Second
+
+	healthOK   = "ok"
+	healthDown = "down"
+)
+
+// healthResp answers probes, Components are the statuses of the dependencies checked by readiness probes
+type healthResp struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components,omitempty"`
+}
+
+type componentHealth struct {
+	Status string `json:"status"`
+}
+
+// healthzHandler answers liveness probes, the app is alive as long as it answers.
+// Probes aren't logged as they come every few seconds
+func (s *ToDoService) healthzHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+	writeHealth(resp, http.StatusOK, &healthResp{Status: healthOK})
+}
+
+// readyzHandler answers readiness probes by checking the dependencies of the storage, 503 unless all of them
+// are up. Failures are logged rather than answered since probes aren't authenticated
+func (s *ToDoService) readyzHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	health := &healthResp{Status: healthOK}
+	if checker, ok := s.store.(storages.HealthChecker); ok {
+		ctx, cancel := context.WithTimeout(req.Context(), readyTimeout)
+		defer cancel()
+
+		health.Components = make(map[string]componentHealth)
+		for name, err := range checker.CheckHealth(ctx) {
+			if err != nil {
+				log.Println("readiness:", name, err)
+				health.Status = healthDown
+				health.Components[name] = componentHealth{Status: healthDown}
+				continue
+			}
+			health.Components[name] = componentHealth{Status: healthOK}
+		}
+	}
+	status := http.StatusOK
+	if health.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeHealth(resp, status, health)
+}
+
+func writeHealth(resp http.ResponseWriter, status int, health *healthResp) {
+	resp.Header().Set("Cache-Control", "no-store")
+	resp.WriteHeader(status)
+	if err := json.NewEncoder(resp).Encode(health); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// checkedMemory is a memory storage whose dependencies have the health of health
+type checkedMemory struct {
+	*memory.Memory
+	health map[string]error
+}
+
+func (m *checkedMemory) CheckHealth(context.Context) map[string]error {
+	return m.health
+}
+
+func TestHealth(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	store := &checkedMemory{Memory: m, health: map[string]error{"database": nil, "migrations": nil}}
+	routes := NewToDoService(testJWTKey, ":6000", store).versionedRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/healthz")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"status": "ok"}`, w.Body.String())
+	w = get("/readyz")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"status": "ok", "components": {"database": {"status": "ok"}, "migrations": {"status": "ok"}}}`, w.Body.String())
+
+	store.health["migrations"] = errors.New("migration 29 add_task_update_at is not applied")
+	w = get("/readyz")
+	requireTest.Equal(http.StatusServiceUnavailable, w.Code)
+	requireTest.JSONEq(`{"status": "down", "components": {"database": {"status": "ok"}, "migrations": {"status": "down"}}}`, w.Body.String())
+	requireTest.Equal(http.StatusOK, get("/healthz").Code)
+
+	// storages which can't check their dependencies are ready
+	w = httptest.NewRecorder()
+	NewToDoService(testJWTKey, ":6000", m).versionedRoutes().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"status": "ok"}`, w.Body.String())
+}
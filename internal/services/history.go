@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// snapshotTask returns the task of the user as it's stored before it's changed, so that the change can be kept
+// in its history. It's nil while storages don't keep histories or the task can't be read
+func (s *ToDoService) snapshotTask(ctx context.Context, usrId, id int) *storages.Task {
+	if _, ok := s.store.(storages.TaskAuditStore); !ok {
+		return nil
+	}
+	getter, ok := s.store.(storages.TaskGetter)
+	if !ok {
+		return nil
+	}
+	task, err := getter.GetTask(ctx, usrId, id)
+	if err != nil {
+		return nil
+	}
+	return task
+}
+
+// auditTask appends a change of the task of the user done by the authenticated user of ctx to the history of
+// the task, before is the task as snapshotTask saw it. The task as it became is read back unless it's deleted.
+// Failures are only logged like those of the audit log
+func (s *ToDoService) auditTask(ctx context.Context, action storages.TaskAction, usrId, id int, before *storages.Task) {
+	store, ok := s.store.(storages.TaskAuditStore)
+	if !ok {
+		return
+	}
+	actorId, _ := userIDFromCtx(ctx)
+	entry := &storages.TaskAuditEntry{TaskId: id, UsrId: usrId, ActorId: actorId, Action: action, Before: before}
+	if action != storages.TaskDeleted {
+		entry.After = s.snapshotTask(ctx, usrId, id)
+	}
+	if err := store.AppendTaskAudit(ctx, entry); err != nil {
+		log.Println("error appending task audit entry", action, err)
+	}
+}
+
+// taskHistoryHandler answers the changes of the task oldest first at GET /tasks/{id}/history, the history of
+// deleted and purged tasks is kept
+func (s *ToDoService) taskHistoryHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	store, ok := s.store.(storages.TaskAuditStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	entries, err := store.GetTaskHistory(req.Context(), userID, id)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(entries)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskHistory(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+	token, err := s.createToken(usr)
+	requireTest.NoError(err)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+		return w
+	}
+	w := serve("POST", "/v1/tasks", `{"content": "milk"}`)
+	requireTest.Equal(http.StatusOK, w.Code)
+	created := &struct {
+		Data *storages.Task `json:"data"`
+	}{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(created))
+	path := "/v1/tasks/" + strconv.Itoa(created.Data.Id)
+
+	requireTest.Equal(http.StatusOK, serve("PUT", path, `{"content": "oat milk", "version": 1}`).Code)
+	requireTest.Equal(http.StatusNoContent, serve("PATCH", path+"/complete", "").Code)
+	requireTest.Equal(http.StatusNoContent, serve("DELETE", path, "").Code)
+
+	// the history is kept once the task is deleted
+	w = serve("GET", path+"/history", "")
+	requireTest.Equal(http.StatusOK, w.Code)
+	history := &struct {
+		Data []*storages.TaskAuditEntry `json:"data"`
+	}{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(history))
+	requireTest.Len(history.Data, 4)
+
+	entry := history.Data[0]
+	requireTest.Equal(storages.TaskCreated, entry.Action)
+	requireTest.Equal(usr.Id, entry.ActorId)
+	requireTest.Nil(entry.Before)
+	requireTest.Equal("milk", entry.After.Content)
+
+	entry = history.Data[1]
+	requireTest.Equal(storages.TaskUpdated, entry.Action)
+	requireTest.Equal("milk", entry.Before.Content)
+	requireTest.Equal("oat milk", entry.After.Content)
+
+	entry = history.Data[2]
+	requireTest.Equal(storages.TaskCompleted, entry.Action)
+	requireTest.Equal(storages.TaskStatusDone, entry.After.Status)
+
+	entry = history.Data[3]
+	requireTest.Equal(storages.TaskDeleted, entry.Action)
+	requireTest.Equal("oat milk", entry.Before.Content)
+	requireTest.Nil(entry.After)
+
+	requireTest.Equal(http.StatusNotFound, serve("GET", "/v1/tasks/97/history", "").Code)
+	requireTest.Equal(http.StatusMethodNotAllowed, serve("POST", path+"/history", "").Code)
+}
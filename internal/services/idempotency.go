@@ -0,0 +1,143 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// idempotencyHeader carries the key clients give a request so that its retries aren't applied twice,
+	// idempotentReplayedHeader marks responses replayed to retries
+	idempotencyHeader        = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLen     = 255
+	// DefaultIdempotencyTTL is how long responses are replayed to retries by default
+	DefaultIdempotencyTTL = 24 * time.Hour
+)
+
+var (
+	errInvalidIdempotencyKey = errors.New("Idempotency-Key must have at most 255 characters")
+	errIdempotencyKeyReused  = errors.New("Idempotency-Key was used by another request")
+)
+
+// WithIdempotencyTTL changes how long responses to requests with an Idempotency-Key are replayed to their
+// retries, DefaultIdempotencyTTL by default
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(s *ToDoService) {
+		s.idempotencyTTL = ttl
+	}
+}
+
+// idempotentHandler answers retries of a request of the user made with the same Idempotency-Key by the response
+// to the first one for s.idempotencyTTL, a successful response of next is saved for them. Failures aren't saved
+// so that they may be retried. The key is rejected if it's reused by a request with another target or body.
+// Requests without the header and all requests when the storage doesn't keep responses are handled by next
+func (s *ToDoService) idempotentHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyHeader)
+		store, ok := s.store.(storages.IdempotencyStore)
+		if key == "" || !ok {
+			next(resp, req)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			writeError(resp, errInvalidIdempotencyKey)
+			return
+		}
+		userID, ok := userIDFromCtx(req.Context())
+		if !ok {
+			writeError(resp, errNoPrincipal)
+			return
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxJsonSize))
+		if err != nil {
+			writeError(resp, errInvalidBody)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		hash := requestHash(req, body)
+
+		now := time.Now()
+		saved, err := store.GetIdempotentResponse(req.Context(), userID, key, now)
+		switch err {
+		case nil:
+			if saved.RequestHash != hash {
+				writeError(resp, errIdempotencyKeyReused)
+				return
+			}
+			resp.Header().Set(idempotentReplayedHeader, "true")
+			resp.WriteHeader(saved.Status)
+			if _, err := resp.Write(saved.Body); err != nil {
+				log.Println(err)
+			}
+			return
+		case storages.ErrNotFound:
+		default:
+			writeError(resp, err)
+			return
+		}
+
+		capture := &responseCapture{ResponseWriter: resp, status: http.StatusOK}
+		next(capture, req)
+		if capture.status < 200 || capture.status >= 300 {
+			return
+		}
+		saved = &storages.IdempotentResponse{
+			UsrId:       userID,
+			Key:         key,
+			RequestHash: hash,
+			Status:      capture.status,
+			Body:        capture.body.Bytes(),
+			ExpiresAt:   now.Add(s.idempotencyTTL),
+		}
+		// a concurrent retry may have saved its response meanwhile, the first one saved is replayed
+		if err := store.SaveIdempotentResponse(req.Context(), saved, time.Now()); err != nil && err != storages.ErrConflict {
+			log.Println(err)
+		}
+	}
+}
+
+// requestHash identifies the target and the body of req
+func requestHash(req *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, req.Method+" "+req.URL.Path+"?"+req.URL.RawQuery+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseCapture writes a response through and keeps its status and body
+type responseCapture struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *responseCapture) fail(err error) {
+	if f, ok := c.ResponseWriter.(failer); ok {
+		f.fail(err)
+	}
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status, c.wroteHeader = status, true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentAddTask(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	add := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set(idempotencyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w
+	}
+	count := func() int {
+		tasks, err := m.GetTasks(context.Background(), usr.Id, time.Now())
+		requireTest.NoError(err)
+		return len(tasks)
+	}
+
+	first := add("k1", `{"content": "milk"}`)
+	requireTest.Equal(http.StatusOK, first.Code)
+	retry := add("k1", `{"content": "milk"}`)
+	requireTest.Equal(http.StatusOK, retry.Code)
+	requireTest.Equal("true", retry.Header().Get(idempotentReplayedHeader))
+	requireTest.Equal(first.Body.String(), retry.Body.String())
+	requireTest.Equal(1, count())
+
+	w := add("k1", `{"content": "bread"}`)
+	requireTest.Equal(http.StatusUnprocessableEntity, w.Code)
+	requireTest.Contains(w.Body.String(), codeIdempotencyReused)
+	w = add(strings.Repeat("k", maxIdempotencyKeyLen+1), `{"content": "bread"}`)
+	requireTest.Equal(http.StatusBadRequest, w.Code)
+
+	// failures are not saved so that they may be retried
+	requireTest.Equal(http.StatusUnprocessableEntity, add("k2", `{"content": ""}`).Code)
+	requireTest.Equal(http.StatusOK, add("k2", `{"content": "bread"}`).Code)
+	requireTest.Empty(add("", `{"content": "bread"}`).Header().Get(idempotentReplayedHeader))
+	requireTest.Equal(3, count())
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxBodySize bounds bodies of every request but attachment uploads which have a limit of their own
+	DefaultMaxBodySize = 1 << 20
+	// DefaultHandlerTimeout bounds how long handlers take to answer unless their route has a timeout of its own
+	DefaultHandlerTimeout = 30 * time.Second
+	// DefaultReadHeaderTimeout and DefaultIdleTimeout bound how long connections may take to send the headers of
+	// a request and may stay idle between requests
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+)
+
+var (
+	errBodyTooLarge   = errors.New("request body is too large")
+	errHandlerTimeout = errors.New("request took too long to handle")
+)
+
+// WithMaxBodySize changes the bound of request bodies, DefaultMaxBodySize by default.
+// Routes decoding JSON bodies have tighter bounds of their own
+func WithMaxBodySize(size int64) Option {
+	return func(s *ToDoService) {
+		s.maxBodySize = size
+	}
+}
+
+// WithHandlerTimeout changes how long handlers may take to answer, DefaultHandlerTimeout by default, 0 disables it
+func WithHandlerTimeout(timeout time.Duration) Option {
+	return func(s *ToDoService) {
+		s.handlerTimeout = timeout
+	}
+}
+
+// WithRouteTimeout overrides the handler timeout of the route registered at pattern by v1Routes,
+// such as "/tasks:batch", 0 disables it
+func WithRouteTimeout(pattern string, timeout time.Duration) Option {
+	return func(s *ToDoService) {
+		if s.routeTimeouts == nil {
+			s.routeTimeouts = make(map[string]time.Duration)
+		}
+		s.routeTimeouts[pattern] = timeout
+	}
+}
+
+// WithServerTimeouts changes the timeouts of the HTTP server, 0 disables one. Requests must send their headers
+// within readHeader and their bodies within read, responses must be written within write and connections are
+// closed after idle between requests. The write timeout also cuts streams short
+func WithServerTimeouts(readHeader, read, write, idle time.Duration) Option {
+	return func(s *ToDoService) {
+		s.server.ReadHeaderTimeout = readHeader
+		s.server.ReadTimeout = read
+		s.server.WriteTimeout = write
+		s.server.IdleTimeout = idle
+	}
+}
+
+// limitsHandler bounds the body of requests to the routes of mux by s.maxBodySize and their handling by the
+// timeout of their route. Attachments and streams aren't bounded by handler timeouts, which buffer responses,
+// they're left to the timeouts of the server
+func (s *ToDoService) limitsHandler(mux *http.ServeMux) http.Handler {
+	timeoutBody := &bytes.Buffer{}
+	_ = json.NewEncoder(timeoutBody).Encode(newErrResp(ApiError{Code: codeTimeout, Message: errHandlerTimeout.Error()}))
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if s.maxBodySize > 0 && !strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+			req.Body = http.MaxBytesReader(resp, req.Body, s.maxBodySize)
+		}
+
+		_, pattern := mux.Handler(req)
+		timeout, ok := s.routeTimeouts[pattern]
+		if !ok {
+			timeout = s.handlerTimeout
+		}
+		if timeout <= 0 || unbuffered(req) {
+			mux.ServeHTTP(resp, req)
+			return
+		}
+		// handlers set headers of their own once they're done
+		resp.Header().Set("Content-Type", "application/json")
+		http.TimeoutHandler(mux, timeout, timeoutBody.String()).ServeHTTP(resp, req)
+	})
+}
+
+// unbuffered reports whether the response to req is streamed or might be large
+func unbuffered(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/stream") ||
+		strings.Contains(req.URL.Path, "/attachments") ||
+		(req.Method == http.MethodGet && listFormat(req) != mimeJSON)
+}
+
+// tooLarge reports whether err comes from reading past a http.MaxBytesReader
+func tooLarge(err error) bool {
+	return err != nil && err.Error() == "http: request body too large"
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitsHandler(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	s := NewToDoService(testJWTKey, ":6000", m, WithMaxBodySize(8), WithHandlerTimeout(20*time.Millisecond),
+		WithRouteTimeout("/slow", time.Second), WithRouteTimeout("/tasks/", 0))
+
+	wait := func(resp http.ResponseWriter, req *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			resp.WriteHeader(http.StatusNoContent)
+		case <-req.Context().Done():
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", wait)
+	mux.HandleFunc("/slow", wait)
+	mux.HandleFunc("/tasks/", wait)
+	mux.HandleFunc("/echo", func(resp http.ResponseWriter, req *http.Request) {
+		if _, err := ioutil.ReadAll(req.Body); tooLarge(err) {
+			writeError(resp, errBodyTooLarge)
+		}
+	})
+	handler := s.limitsHandler(mux)
+	do := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("POST", path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	w := do("/", "")
+	requireTest.Equal(http.StatusServiceUnavailable, w.Code)
+	requireTest.Equal("application/json", w.Header().Get("Content-Type"))
+	requireTest.Contains(w.Body.String(), codeTimeout)
+	requireTest.Equal(http.StatusNoContent, do("/slow", "").Code)
+	requireTest.Equal(http.StatusNoContent, do("/tasks/1", "").Code)
+
+	requireTest.Equal(http.StatusOK, do("/echo", "12345678").Code)
+	w = do("/echo", "123456789")
+	requireTest.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	requireTest.Contains(w.Body.String(), codePayloadTooLarge)
+}
+
+func TestDecodeBodyTooLarge(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	req := httptest.NewRequest("POST", "/tasks", strings.NewReader(`{"content": "`+strings.Repeat("a", maxJsonSize)+`"}`))
+	w := httptest.NewRecorder()
+	s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+	requireTest.Equal(http.StatusRequestEntityTooLarge, w.Code)
+	requireTest.Contains(w.Body.String(), codePayloadTooLarge)
+}
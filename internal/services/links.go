@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+)
+
+var (
+	errInvalidLinkScope = errors.New("either created_date or project_id must be given")
+	errInvalidLink      = errors.New("share link is not valid or has expired")
+)
+
+// linkParams scope a share link to the tasks created on CreatedDate or to the tasks of the project of ProjectId,
+// ExpiresIn is in seconds
+type linkParams struct {
+	CreatedDate string `json:"created_date"`
+	ProjectId   int    `json:"project_id"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type shareLink struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// linksHandler mints a share link of the tasks of the user at POST /users/me/links, anyone holding it
+// may read them without login until it expires
+func (s *ToDoService) linksHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	params := &linkParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+
+	link := &tokens.ShareLink{UsrId: userID, Date: params.CreatedDate, ProjectId: params.ProjectId}
+	if link.Date == "" {
+		projects, ok := s.store.(storages.ProjectStore)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+		if _, err := projects.GetProject(req.Context(), userID, link.ProjectId); err != nil {
+			writeError(resp, err)
+			return
+		}
+	}
+
+	token, err := s.links.Sign(link, time.Duration(params.ExpiresIn)*time.Second)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusCreated)
+	data := shareLink{Token: token, URL: "/links/" + token, ExpiresAt: time.Unix(link.ExpiresAt, 0).UTC()}
+	if err := json.NewEncoder(resp).Encode(newDataResp(data)); err != nil {
+		log.Println(err)
+	}
+}
+
+// linkHandler lists the tasks a share link grants at GET /links/{token} without login. Links of a project
+// list its tasks created on created_date, the same filters as GET /tasks apply to both
+func (s *ToDoService) linkHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	link, err := s.links.Parse(strings.TrimPrefix(req.URL.Path, "/links/"))
+	if err != nil {
+		writeErrResp(resp, http.StatusNotFound, errInvalidLink)
+		return
+	}
+
+	date := link.Date
+	var projectId *int
+	if link.ProjectId != 0 {
+		projects, ok := s.store.(storages.ProjectStore)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+		if _, err := projects.GetProject(req.Context(), link.UsrId, link.ProjectId); err != nil {
+			writeError(resp, err)
+			return
+		}
+		date, projectId = req.FormValue("created_date"), &link.ProjectId
+	}
+	createdDate, err := s.createdDate(req.Context(), link.UsrId, date)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	tasks, err := s.findTasks(req, link.UsrId, createdDate, projectId)
+	switch err {
+	case nil:
+	case errNotSupported:
+		writeErrResp(resp, http.StatusNotImplemented, err)
+		return
+	case errInvalidFilter:
+		writeError(resp, errInvalidFilter)
+		return
+	default:
+		writeError(resp, err)
+		return
+	}
+
+	renderContent(req, tasks...)
+	writeTasks(resp, req, tasks)
+}
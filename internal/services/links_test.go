@@ -0,0 +1,118 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinks(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	project := &storages.Project{UsrId: usr.Id, Name: "home"}
+	requireTest.NoError(m.CreateProject(context.Background(), project))
+	now := time.Now().UTC()
+	requireTest.NoError(m.InsertTask(context.Background(), &storages.Task{UsrId: usr.Id, Content: "milk", CreateAt: now}))
+	requireTest.NoError(m.InsertTask(context.Background(),
+		&storages.Task{UsrId: usr.Id, Content: "paint", CreateAt: now, ProjectId: &project.Id}))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	ctx := withPrincipal(context.Background(), principal{UserID: usr.Id})
+	mint := func(body string) (int, shareLink) {
+		w := httptest.NewRecorder()
+		s.linksHandler(w, httptest.NewRequest("POST", "/users/me/links", bytes.NewBufferString(body)).WithContext(ctx))
+		link := &struct {
+			Data shareLink `json:"data"`
+		}{}
+		_ = json.NewDecoder(w.Body).Decode(link)
+		return w.Result().StatusCode, link.Data
+	}
+	list := func(url string) (int, []*storages.Task) {
+		w := httptest.NewRecorder()
+		s.linkHandler(w, httptest.NewRequest("GET", url, nil))
+		tasks := &struct {
+			Data []*storages.Task `json:"data"`
+		}{}
+		_ = json.NewDecoder(w.Body).Decode(tasks)
+		return w.Result().StatusCode, tasks.Data
+	}
+
+	code, _ := mint(`{}`)
+	requireTest.Equal(http.StatusUnprocessableEntity, code)
+	code, _ = mint(`{"project_id": 99}`)
+	requireTest.Equal(http.StatusNotFound, code)
+
+	date := now.Format("2006-01-02")
+	code, link := mint(`{"created_date": "` + date + `", "expires_in": 3600}`)
+	requireTest.Equal(http.StatusCreated, code)
+	requireTest.WithinDuration(now.Add(time.Hour), link.ExpiresAt, time.Minute)
+	code, tasks := list(link.URL)
+	requireTest.Equal(http.StatusOK, code)
+	requireTest.Len(tasks, 2)
+
+	code, link = mint(`{"project_id": ` + strconv.Itoa(project.Id) + `}`)
+	requireTest.Equal(http.StatusCreated, code)
+	code, _ = list(link.URL)
+	requireTest.Equal(http.StatusBadRequest, code)
+	code, tasks = list(link.URL + "?created_date=" + date)
+	requireTest.Equal(http.StatusOK, code)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("paint", tasks[0].Content)
+
+	code, _ = list(link.URL + "x?created_date=" + date)
+	requireTest.Equal(http.StatusNotFound, code)
+
+	w := httptest.NewRecorder()
+	s.linkHandler(w, httptest.NewRequest("DELETE", link.URL, nil))
+	requireTest.Equal(http.StatusMethodNotAllowed, w.Result().StatusCode)
+}
+
+func TestShareLinksTimezone(t *testing.T) {
+	requireTest := require.New(t)
+	ctx := context.Background()
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+	requireTest.NoError(m.SetTimezone(ctx, usr.Id, "Etc/GMT-14"))
+	// it's March 2nd already 14 hours ahead of UTC
+	createAt := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	requireTest.NoError(m.Seed(ctx, &storages.Fixtures{Tasks: []*storages.Task{{UsrId: usr.Id, Content: "milk", CreateAt: createAt}}}))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	list := func(date string) []*storages.Task {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/users/me/links", bytes.NewBufferString(`{"created_date": "`+date+`"}`))
+		s.linksHandler(w, req.WithContext(withPrincipal(ctx, principal{UserID: usr.Id})))
+		requireTest.Equal(http.StatusCreated, w.Code)
+		link := &struct {
+			Data shareLink `json:"data"`
+		}{}
+		requireTest.NoError(json.NewDecoder(w.Body).Decode(link))
+
+		w = httptest.NewRecorder()
+		s.linkHandler(w, httptest.NewRequest("GET", link.Data.URL, nil))
+		requireTest.Equal(http.StatusOK, w.Code)
+		tasks := &struct {
+			Data []*storages.Task `json:"data"`
+		}{}
+		requireTest.NoError(json.NewDecoder(w.Body).Decode(tasks))
+		return tasks.Data
+	}
+
+	// dates are days of the timezone of the owner like those of GET /tasks
+	requireTest.Len(list("2021-03-02"), 1)
+	requireTest.Empty(list("2021-03-01"))
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/logging"
+	"go.uber.org/zap"
+)
+
+// requestIDHeader carries the id of a request, those of clients are kept if they're valid
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen is how long request ids of clients may be
+const maxRequestIDLen = 128
+
+// WithLogger logs requests by logger, the global logger of zap is used by default
+func WithLogger(logger *zap.Logger) Option {
+	return func(s *ToDoService) {
+		s.logger = logger
+	}
+}
+
+// requestUser is the user a request was authenticated as, set once the request is
+type requestUser struct {
+	id int
+}
+
+type requestUserKey struct{}
+
+// requestLogHandler gives every request an id, the one of its X-Request-ID header or a new one which is sent
+// back by the same header, and a logger with it in the context of the request, at debug level for the routes
+// debugged by the log level. A line is logged once next
+// served the request with its method, path, status, latency and user, at error level along with the error if
+// it answered an internal error
+func (s *ToDoService) requestLogHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		id := req.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			var err error
+			if id, err = logging.NewRequestID(); err != nil {
+				s.log().Error("generating request id", zap.Error(err))
+			}
+		}
+		resp.Header().Set(requestIDHeader, id)
+
+		logger := s.log().With(zap.String("request_id", id))
+		if s.logLevel != nil && s.logLevel.DebugsPath(req.URL.Path) {
+			logger = logging.Debug(logger)
+		}
+		usr := &requestUser{}
+		ctx := context.WithValue(logging.NewContext(req.Context(), logger), requestUserKey{}, usr)
+		w := &statusWriter{ResponseWriter: resp, status: http.StatusOK}
+		next.ServeHTTP(w, req.WithContext(ctx))
+
+		fields := []zap.Field{
+			zap.String("method", req.Method),
+			zap.String("path", logPath(req.URL.Path)),
+			zap.Int("status", w.status),
+			zap.Duration("latency", time.Since(start)),
+		}
+		if usr.id != 0 {
+			fields = append(fields, zap.Int("user_id", usr.id))
+		}
+		if w.err != nil {
+			fields = append(fields, zap.Error(w.err))
+			if p, ok := w.err.(*panicError); ok {
+				fields = append(fields, zap.ByteString("stack", p.stack))
+			}
+			logger.Error("request", fields...)
+			return
+		}
+		logger.Info("request", fields...)
+	})
+}
+
+// log returns the logger of the service
+func (s *ToDoService) log() *zap.Logger {
+	if s.logger == nil {
+		return zap.L()
+	}
+	return s.logger
+}
+
+// withRequestUser tells the request log of ctx and the loggers of what serves it the user id ctx is
+// authenticated as
+func withRequestUser(ctx context.Context, id int) context.Context {
+	if usr, ok := ctx.Value(requestUserKey{}).(*requestUser); ok {
+		usr.id = id
+	}
+	return logging.NewContext(ctx, logging.FromContext(ctx).With(zap.Int("user_id", id)))
+}
+
+// logPath returns path as it's logged, the tokens of share links are left out as they grant access
+func logPath(path string) string {
+	if i := strings.Index(path, "/links/"); i >= 0 {
+		return path[:i+len("/links/")]
+	}
+	return path
+}
+
+// validRequestID reports whether id is a request id of printable ASCII short enough to be kept
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// statusWriter writes a response through and keeps its status, its size and the internal error it answers,
+// it flushes for the streams of /tasks/stream
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	size        int64
+	err         error
+}
+
+func (w *statusWriter) fail(err error) {
+	w.err = err
+	if f, ok := w.ResponseWriter.(failer); ok {
+		f.fail(err)
+	}
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
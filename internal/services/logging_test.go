@@ -0,0 +1,82 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLog(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	core, logs := observer.New(zap.InfoLevel)
+	s := NewToDoService(testJWTKey, ":6000", m, WithLogger(zap.New(core)))
+	token, err := s.createToken(&storages.User{Id: 1, MaxTodo: 5})
+	requireTest.NoError(err)
+
+	req := httptest.NewRequest("GET", "/v1/projects", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(requestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal("abc-123", w.Header().Get(requestIDHeader))
+
+	entries := logs.TakeAll()
+	requireTest.Len(entries, 1)
+	fields := entries[0].ContextMap()
+	requireTest.Equal("abc-123", fields["request_id"])
+	requireTest.Equal("GET", fields["method"])
+	requireTest.Equal("/v1/projects", fields["path"])
+	requireTest.Equal(int64(http.StatusOK), fields["status"])
+	requireTest.Equal(int64(1), fields["user_id"])
+	requireTest.Contains(fields, "latency")
+
+	// ids which aren't printable are replaced
+	req = httptest.NewRequest("GET", "/v1/tasks", nil)
+	req.Header.Set(requestIDHeader, "a b")
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	requireTest.Equal(http.StatusUnauthorized, w.Code)
+	requireTest.Len(w.Header().Get(requestIDHeader), 32)
+	fields = logs.TakeAll()[0].ContextMap()
+	requireTest.Equal(w.Header().Get(requestIDHeader), fields["request_id"])
+	requireTest.NotContains(fields, "user_id")
+}
+
+func TestRequestLogContext(t *testing.T) {
+	requireTest := require.New(t)
+	core, logs := observer.New(zap.InfoLevel)
+	s := NewToDoService(testJWTKey, ":6000", nil, WithLogger(zap.New(core)))
+
+	handler := s.requestLogHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		ctx := withPrincipal(req.Context(), principal{UserID: 7})
+		logging.FromContext(ctx).Info("storage")
+		resp.WriteHeader(http.StatusTeapot)
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(requestIDHeader, "id")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	// what serves the request logs with its id and user
+	entries := logs.TakeAll()
+	requireTest.Len(entries, 2)
+	requireTest.Equal("storage", entries[0].Message)
+	requireTest.Equal(map[string]interface{}{"request_id": "id", "user_id": int64(7)}, entries[0].ContextMap())
+	requireTest.Equal(int64(http.StatusTeapot), entries[1].ContextMap()["status"])
+}
+
+func TestLogPath(t *testing.T) {
+	require.Equal(t, "/v1/tasks", logPath("/v1/tasks"))
+	require.Equal(t, "/v1/links/", logPath("/v1/links/secret"))
+	require.Equal(t, "/links/", logPath("/links/secret"))
+}
@@ -1,76 +1,201 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/manabie-com/togo/internal/storages/postgres"
-	"io"
+	"errors"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/manabie-com/togo/internal/tokens"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 const maxJsonSize = 1024 //1kb
 
+var (
+	errTooManyLogins = errors.New("too many failed logins, retry later")
+	errAdminOnly     = errors.New("only admins are allowed")
+)
+
+// loginParams are the credentials of a login, OTP is the code of users who enabled two-factor authentication
 type loginParams struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	OTP      string `json:"otp"`
 }
 
 func (s *ToDoService) createTokenHandler(resp http.ResponseWriter, req *http.Request) {
-	log.Println(req.Method, req.URL.Path)
+	usr, ok := s.checkLogin(resp, req)
+	if !ok {
+		return
+	}
+
+	token, err := s.createToken(usr)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if err = json.NewEncoder(resp).Encode(newDataResp(token)); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// checkLogin returns the user whose credentials are posted in body, the response is written if it fails
+func (s *ToDoService) checkLogin(resp http.ResponseWriter, req *http.Request) (*storages.User, bool) {
 	defer func() {
 		_ = req.Body.Close()
 	}()
 
 	if req.Method != http.MethodPost {
-		resp.WriteHeader(http.StatusMethodNotAllowed)
-		return
+		writeError(resp, errMethodNotAllowed)
+		return nil, false
 	}
 
 	params := &loginParams{}
-	err := json.NewDecoder(io.LimitReader(req.Body, maxJsonSize)).Decode(params)
-	if err != nil {
-		resp.WriteHeader(http.StatusBadRequest)
-		return
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return nil, false
 	}
 
-	usr, err := s.pg.ValidateUser(req.Context(), params.Username, params.Password)
+	usr, wait, err := s.login(req.Context(), clientOf(req), params)
 	switch err {
 	case nil:
-		break
-	case postgres.ErrIncorrectUsernameOrPassword:
-		resp.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(resp).Encode(newErrResp(err.Error())); err != nil {
-			log.Println(err.Error())
-		}
-		return
+		return usr, true
+	case errTooManyLogins:
+		writeThrottledResp(resp, wait)
+		return nil, false
+	case storages.ErrInvalidCredentials:
+		writeErrResp(resp, http.StatusBadRequest, err)
+		return nil, false
 	default:
-		resp.WriteHeader(http.StatusInternalServerError)
-		return
+		writeError(resp, err)
+		return nil, false
 	}
+}
 
-	token, err := s.createToken(usr.Id)
-	if err != nil {
-		resp.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(resp).Encode(newErrResp(err.Error())); err != nil {
-			log.Println(err.Error())
+// login returns the user of the credentials of params logging in from c, logins are throttled and audited.
+// It returns errTooManyLogins along with the wait of throttled logins, storages.ErrInvalidCredentials,
+// errTwoFactorRequired or errInvalidTwoFactor when the credentials are not enough
+func (s *ToDoService) login(ctx context.Context, c client, params *loginParams) (*storages.User, time.Duration, error) {
+	var keys []string
+	if s.throttle != nil {
+		keys = loginKeys(c, params.Username)
+		wait, err := s.throttle.Wait(ctx, keys...)
+		if err != nil {
+			return nil, 0, err
+		}
+		if wait > 0 {
+			return nil, wait, errTooManyLogins
 		}
-		return
 	}
 
-	if err = json.NewEncoder(resp).Encode(newDataResp(token)); err != nil {
-		log.Println(err.Error())
+	usr, err := s.store.ValidateUser(ctx, params.Username, params.Password)
+	if err == nil {
+		err = s.verifyTwoFactor(ctx, usr.Id, params.OTP)
+	}
+	switch err {
+	case nil:
+		if s.throttle != nil {
+			s.throttle.Succeed(ctx, keys[0])
+		}
+		s.auditClient(ctx, c, storages.AuditLogin, usr.Id, usr.Id, "")
+		return usr, 0, nil
+	case storages.ErrInvalidCredentials:
+		if s.throttle != nil {
+			s.throttle.Fail(ctx, keys...)
+		}
+		s.metrics.LoginFailed()
+		s.auditClient(ctx, c, storages.AuditLoginFailed, 0, 0, "username="+params.Username)
+	case errInvalidTwoFactor:
+		if s.throttle != nil {
+			s.throttle.Fail(ctx, keys...)
+		}
+		s.metrics.LoginFailed()
+		s.auditClient(ctx, c, storages.AuditLoginFailed, 0, usr.Id, "two-factor")
+	}
+	return nil, 0, err
+}
+
+// writeThrottledResp answers 429 to logins and signups which have to wait
+func writeThrottledResp(resp http.ResponseWriter, wait time.Duration) {
+	resp.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+	writeErrResp(resp, http.StatusTooManyRequests, errTooManyLogins)
+}
+
+// loginKeys returns the keys counting failed logins of username and from the address of c, the username
+// is counted first. The address is left out when it can't be told
+func loginKeys(c client, username string) []string {
+	keys := []string{throttle.UserKey(username)}
+	if c.IP != "" {
+		keys = append(keys, throttle.AddrKey(c.IP))
+	}
+	return keys
+}
+
+// client tells where a request comes from
+type client struct {
+	IP        string
+	UserAgent string
+}
+
+func clientOf(req *http.Request) client {
+	return client{IP: clientIP(req), UserAgent: userAgent(req)}
+}
+
+// clientIP returns the address req comes from, empty if it can't be told
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return ""
 	}
+	return host
 }
 
+// authHandler requires a valid token which is not limited to scopes, scoped tokens can't manage the account
 func (s *ToDoService) authHandler(nextHandler http.HandlerFunc) http.HandlerFunc {
+	return s.scopeHandler(nil, nextHandler)
+}
+
+// scopeHandler requires a valid token, a scoped one must have the scope of the request told by scope
+func (s *ToDoService) scopeHandler(scope func(req *http.Request) string, nextHandler http.HandlerFunc) http.HandlerFunc {
 	return func(resp http.ResponseWriter, req *http.Request) {
 		req, err := s.validToken(req)
 		if err != nil {
-			resp.WriteHeader(http.StatusUnauthorized)
-			log.Println(err)
+			writeAuthErrResp(resp, err)
+			return
+		}
+		if p, _ := principalFromCtx(req.Context()); len(p.Scopes) > 0 && (scope == nil || !p.allows(scope(req))) {
+			writeErrResp(resp, http.StatusForbidden, errInsufficientScope)
 			return
 		}
 
 		nextHandler(resp, req)
 	}
 }
+
+// writeAuthErrResp answers 401 to requests without a valid token and 403 to forged ones
+func writeAuthErrResp(resp http.ResponseWriter, err error) {
+	log.Println(err)
+	if err == errInvalidCSRF {
+		writeErrResp(resp, http.StatusForbidden, err)
+		return
+	}
+	writeError(resp, tokens.ErrInvalidToken)
+}
+
+// adminHandler is authHandler which also requires the token of an admin, every admin-only endpoint is wrapped by it.
+// Scoped tokens must have the admin scope
+func (s *ToDoService) adminHandler(nextHandler http.HandlerFunc) http.HandlerFunc {
+	return s.scopeHandler(adminScope, func(resp http.ResponseWriter, req *http.Request) {
+		if !isAdmin(req.Context()) {
+			writeErrResp(resp, http.StatusForbidden, errAdminOnly)
+			return
+		}
+
+		nextHandler(resp, req)
+	})
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"github.com/manabie-com/togo/internal/storages"
-	"github.com/manabie-com/togo/internal/storages/postgres"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 var (
@@ -25,8 +28,9 @@ func newLoginRequest(username, password string) *http.Request {
 
 func mockCreateToken(t *testing.T, user *loginParams, err error) *http.Response {
 	req := newLoginRequest(user.Username, user.Password)
-	db := new(postgres.DatabaseMock)
+	db := new(storages.StoreMock)
 	db.On("ValidateUser", req.Context(), user.Username, user.Password).Return(&storages.User{}, err)
+	db.On("GetTwoFactor", req.Context(), mock.Anything).Return((*storages.TwoFactor)(nil), storages.ErrNotFound).Maybe()
 
 	s := NewToDoService(testJWTKey, ":6000", db)
 
@@ -54,13 +58,13 @@ func TestLoginCorrectUsernamePassword(t *testing.T) {
 }
 
 func TestLoginWrongUsernamePassword(t *testing.T) {
-	resp := mockCreateToken(t, testUser, postgres.ErrIncorrectUsernameOrPassword)
+	resp := mockCreateToken(t, testUser, storages.ErrInvalidCredentials)
 	defer resp.Body.Close()
 
 	requireTest := require.New(t)
 	requireTest.Equal(http.StatusBadRequest, resp.StatusCode)
 
-	expectedErrResp := &ApiErrResp{Error: postgres.ErrIncorrectUsernameOrPassword.Error()}
+	expectedErrResp := &ApiErrResp{Error: ApiError{Code: codeInvalidCredentials, Message: storages.ErrInvalidCredentials.Error()}}
 	assertErrResp(t, expectedErrResp, resp)
 }
 
@@ -84,11 +88,11 @@ func mockGetAuthToken(t *testing.T, user storages.User, expectedValidToken bool)
 	requireTest := require.New(t)
 	req := httptest.NewRequest("GET", "localhost:5050/login", nil)
 
-	db := new(postgres.DatabaseMock)
+	db := new(storages.StoreMock)
 	s := NewToDoService(testJWTKey, ":6000", db)
 
 	if expectedValidToken {
-		token, err := s.createToken(user.Id)
+		token, err := s.createToken(&user)
 		requireTest.NoError(err)
 		req.Header.Set("Authorization", token)
 	} else {
@@ -123,3 +127,44 @@ func assertErrResp(t *testing.T, expected *ApiErrResp, actualResp *http.Response
 	requireTest.NoError(err)
 	requireTest.Equal(expectedBytes, bytes.TrimSpace(actualRespBytes))
 }
+
+func TestAuthBearerToken(t *testing.T) {
+	requireTest := require.New(t)
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock))
+
+	token, err := s.createToken(&storages.User{Id: 7, MaxTodo: 5})
+	requireTest.NoError(err)
+
+	req := httptest.NewRequest("GET", "localhost:5050/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	var userID int
+	s.authHandler(func(writer http.ResponseWriter, request *http.Request) {
+		userID, _ = userIDFromCtx(request.Context())
+	})(recorder, req)
+
+	requireTest.Equal(http.StatusOK, recorder.Result().StatusCode)
+	requireTest.Equal(7, userID)
+}
+
+func TestLoginThrottle(t *testing.T) {
+	requireTest := require.New(t)
+	attempts, err := memory.NewMemory()
+	requireTest.NoError(err)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db, WithLoginThrottle(throttle.NewThrottler(attempts, 2, time.Minute, 0)))
+
+	db.On("ValidateUser", mock.Anything, testUser.Username, "wrong").Return((*storages.User)(nil), storages.ErrInvalidCredentials)
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		s.createTokenHandler(w, newLoginRequest(testUser.Username, "wrong"))
+		requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	}
+
+	// the right password can't be tried until the wait is over
+	w := httptest.NewRecorder()
+	s.createTokenHandler(w, newLoginRequest(testUser.Username, testUser.Password))
+	requireTest.Equal(http.StatusTooManyRequests, w.Result().StatusCode)
+	requireTest.Equal("60", w.Result().Header.Get("Retry-After"))
+	db.AssertNotCalled(t, "ValidateUser", mock.Anything, testUser.Username, testUser.Password)
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// defaultDebugFor is how long users and routes are debugged when for isn't given, maxDebugFor how long at most
+	defaultDebugFor = 15 * time.Minute
+	maxDebugFor     = 24 * time.Hour
+)
+
+var errLogLevelFixed = errors.New("the log level can't be changed")
+
+// WithLogLevel lets admins change level at /admin/log-level, it's the level of the logger of WithLogger
+func WithLogLevel(level *logging.Level) Option {
+	return func(s *ToDoService) {
+		s.logLevel = level
+	}
+}
+
+// logLevelResult is the level of the logs and the users and routes logged at debug level whatever it is
+type logLevelResult struct {
+	Level string                  `json:"level"`
+	Debug []logging.DebugOverride `json:"debug"`
+}
+
+// logLevelParams changes the level of the logs or debugs the requests of a user or of paths starting with a
+// route for a while, a for of 0s stops it
+type logLevelParams struct {
+	Level *string `json:"level"`
+	UsrId int     `json:"usr_id"`
+	Route string  `json:"route"`
+	For   string  `json:"for"`
+}
+
+// adminLogLevelHandler answers the level of the logs at GET /admin/log-level and changes it at PUT
+func (s *ToDoService) adminLogLevelHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if s.logLevel == nil {
+		writeErrResp(resp, http.StatusNotImplemented, errLogLevelFixed)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		params := &logLevelParams{}
+		if !decodeBody(resp, req, params, maxJsonSize) {
+			return
+		}
+		s.changeLogLevel(req, params)
+	default:
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	result := logLevelResult{Level: s.logLevel.Level().String(), Debug: s.logLevel.Overrides()}
+	if err := json.NewEncoder(resp).Encode(newDataResp(result)); err != nil {
+		log.Println(err)
+	}
+}
+
+// changeLogLevel applies params which were validated
+func (s *ToDoService) changeLogLevel(req *http.Request, params *logLevelParams) {
+	actorId, _ := userIDFromCtx(req.Context())
+	logger := logging.FromContext(req.Context())
+	if params.Level != nil {
+		_ = s.logLevel.UnmarshalText([]byte(*params.Level))
+		logger.Info("log level changed", zap.Int("actor_id", actorId), zap.String("level", *params.Level))
+	}
+	if params.UsrId == 0 && params.Route == "" {
+		return
+	}
+
+	debugFor := defaultDebugFor
+	if params.For != "" {
+		debugFor, _ = time.ParseDuration(params.For)
+	}
+	until := time.Now().Add(debugFor)
+	if params.UsrId != 0 {
+		s.logLevel.DebugUser(params.UsrId, until)
+	}
+	if params.Route != "" {
+		s.logLevel.DebugRoute(params.Route, until)
+	}
+	logger.Info("debug logging changed", zap.Int("actor_id", actorId), zap.Int("usr_id", params.UsrId),
+		zap.String("route", params.Route), zap.Duration("for", debugFor))
+}
+
+func (p *logLevelParams) validate(v *validator) {
+	if p.Level != nil {
+		var level zapcore.Level
+		v.check(level.UnmarshalText([]byte(*p.Level)) == nil && level <= zapcore.ErrorLevel, "level",
+			"must be debug, info, warn or error")
+	}
+	v.check(p.UsrId >= 0, "usr_id", "can't be negative")
+	v.check(p.Route == "" || p.Route[0] == '/', "route", "must be a path starting with /")
+	if p.For != "" {
+		d, err := time.ParseDuration(p.For)
+		v.check(err == nil && d >= 0 && d <= maxDebugFor, "for", "must be a duration such as 15m of at most 24h")
+	}
+	v.check(p.Level != nil || p.UsrId != 0 || p.Route != "", "", "level, usr_id or route is required")
+}
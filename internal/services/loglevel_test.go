@@ -0,0 +1,54 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAdminLogLevel(t *testing.T) {
+	requireTest := require.New(t)
+	_, level, err := logging.New("info", "json")
+	requireTest.NoError(err)
+	s := NewToDoService(testJWTKey, ":6000", nil, WithLogLevel(level))
+
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.adminHandler(s.adminLogLevelHandler)(w, newAdminRequest(t, s, method, "/admin/log-level", body, storages.RoleAdmin))
+		return w
+	}
+
+	w := serve("GET", "")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"level": "info", "debug": []}}`, w.Body.String())
+
+	w = serve("PUT", `{"level": "warn"}`)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal(zapcore.WarnLevel, level.Level())
+
+	// users and routes are debugged for a while
+	w = serve("PUT", `{"usr_id": 7, "for": "10m"}`)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Contains(w.Body.String(), `"level":"warn","debug":[{"usr_id":7,"until":`)
+	requireTest.True(level.DebugsUser(7))
+	requireTest.Equal(http.StatusOK, serve("PUT", `{"route": "/v1/tasks"}`).Code)
+	requireTest.True(level.DebugsPath("/v1/tasks/1"))
+	requireTest.Equal(http.StatusOK, serve("PUT", `{"usr_id": 7, "for": "0s"}`).Code)
+	requireTest.False(level.DebugsUser(7))
+
+	for _, body := range []string{`{"level": "loud"}`, `{"level": "fatal"}`, `{"usr_id": 7, "for": "48h"}`, `{"route": "tasks"}`, `{}`} {
+		requireTest.Equal(http.StatusUnprocessableEntity, serve("PUT", body).Code, body)
+	}
+	requireTest.Equal(http.StatusMethodNotAllowed, serve("DELETE", "").Code)
+
+	// only admins change it
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminLogLevelHandler)(w, newAdminRequest(t, s, "PUT", "/admin/log-level", `{"level": "debug"}`, storages.RoleUser))
+	requireTest.Equal(http.StatusForbidden, w.Code)
+	requireTest.Equal(zapcore.WarnLevel, level.Level())
+}
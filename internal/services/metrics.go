@@ -0,0 +1,51 @@
+package services
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/metrics"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// WithMetrics records requests, quota rejections and login failures in m and serves it at /metrics
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(s *ToDoService) {
+		s.metrics = m
+	}
+}
+
+// metricsHandler records the requests next serves by the patterns of routes they match, so that paths
+// with ids count towards the same route
+func (s *ToDoService) metricsHandler(routes *http.ServeMux, next http.Handler) http.Handler {
+	if s.metrics == nil {
+		return next
+	}
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		_, route := routes.Handler(req)
+		w := &statusWriter{ResponseWriter: resp, status: http.StatusOK}
+		next.ServeHTTP(w, req)
+		s.metrics.ObserveRequest(route, req.Method, w.status, time.Since(start))
+	})
+}
+
+// countQuota records the tasks err of an insert rejected by a daily-limit
+func (s *ToDoService) countQuota(err error) {
+	switch err := err.(type) {
+	case storages.BatchError:
+		n := 0
+		for _, taskErr := range err {
+			if taskErr == storages.ErrQuotaExceeded {
+				n++
+			}
+		}
+		if n > 0 {
+			s.metrics.QuotaRejected(n)
+		}
+	default:
+		if err == storages.ErrQuotaExceeded {
+			s.metrics.QuotaRejected(1)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/metrics"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRoutes(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m, WithMetrics(metrics.New()))
+	token, err := s.createToken(usr)
+	requireTest.NoError(err)
+
+	serve := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+		return w.Code
+	}
+	requireTest.Equal(http.StatusOK, serve("POST", "/v1/tasks", `{"content": "milk"}`))
+	requireTest.Equal(http.StatusTooManyRequests, serve("POST", "/v1/tasks", `{"content": "milk"}`))
+	requireTest.Equal(http.StatusNotFound, serve("GET", "/v1/tasks/97", ""))
+	requireTest.Equal(http.StatusNotFound, serve("GET", "/v1/tasks/98", ""))
+	requireTest.Equal(http.StatusBadRequest, serve("POST", "/v1/login", `{"username": "alice", "password": "wrong-password"}`))
+
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	requireTest.Equal(http.StatusOK, w.Code)
+	body := w.Body.String()
+	requireTest.Contains(body, `togo_http_requests_total{method="POST",route="/tasks",status="200"} 1`)
+	requireTest.Contains(body, `togo_http_requests_total{method="POST",route="/tasks",status="429"} 1`)
+	// paths with ids count towards their route
+	requireTest.Contains(body, `togo_http_requests_total{method="GET",route="/tasks/",status="404"} 2`)
+	requireTest.Contains(body, "togo_quota_rejections_total 1\n")
+	requireTest.Contains(body, "togo_login_failures_total 1\n")
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/manabie-com/togo/internal/oidc"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// stateCookie keeps the state of a login at a provider until its callback
+const stateCookie = "togo_oidc_state"
+
+var (
+	errUnknownProvider = errors.New("unknown identity provider")
+	errIdentityLinked  = errors.New("identity is linked to another user")
+	errLinkRequired    = errors.New("username is taken, log in and link the identity instead")
+)
+
+// linkResult is the body of responses of /auth/{provider}/link
+type linkResult struct {
+	URL string `json:"url"`
+}
+
+// providerHandler serves the logins at identity providers:
+// GET /auth/{provider}/login redirects to the provider,
+// POST /auth/{provider}/link gives the page of the provider where the logged in user links an identity and
+// GET /auth/{provider}/callback handles the redirect back from the provider and answers like /auth/login
+func (s *ToDoService) providerHandler(resp http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/auth/"), "/")
+	if len(parts) != 2 {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	name, action := parts[0], parts[1]
+	provider, ok := s.providers[name]
+	if !ok {
+		writeErrResp(resp, http.StatusNotFound, errUnknownProvider)
+		return
+	}
+
+	switch {
+	case action == "login" && req.Method == http.MethodGet:
+		url, ok := s.redirectToProvider(resp, req, name, provider, 0)
+		if ok {
+			http.Redirect(resp, req, url, http.StatusFound)
+		}
+	case action == "link" && req.Method == http.MethodPost:
+		req, err := s.validToken(req)
+		if err != nil {
+			writeAuthErrResp(resp, err)
+			return
+		}
+		userID, _ := userIDFromCtx(req.Context())
+		url, ok := s.redirectToProvider(resp, req, name, provider, userID)
+		if ok {
+			if err := json.NewEncoder(resp).Encode(newDataResp(linkResult{URL: url})); err != nil {
+				log.Println(err)
+			}
+		}
+	case action == "callback" && req.Method == http.MethodGet:
+		s.providerCallbackHandler(resp, req, name, provider)
+	case action == "login" || action == "link" || action == "callback":
+		writeError(resp, errMethodNotAllowed)
+	default:
+		writeError(resp, errUnknownRoute)
+	}
+}
+
+// redirectToProvider keeps a new state in the cookie and returns the page of the provider,
+// usrId is the user linking the identity or 0 for a login. The cookie isn't versioned like the callback
+// registered at the provider
+func (s *ToDoService) redirectToProvider(resp http.ResponseWriter, req *http.Request, name string, provider oidc.Provider, usrId int) (string, bool) {
+	st, cookie, err := s.states.New(name, usrId)
+	if err != nil {
+		writeError(resp, err)
+		return "", false
+	}
+	http.SetCookie(resp, &http.Cookie{
+		Name:     stateCookie,
+		Value:    cookie,
+		Path:     "/auth/" + name + "/",
+		MaxAge:   int(oidc.StateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   req.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return provider.AuthCodeURL(st.Value, st.Nonce()), true
+}
+
+// providerCallbackHandler trades the code for the identity of the user. A linking user gets the identity linked,
+// otherwise the user linked to it logs in and unknown identities are provisioned a new user named after them
+func (s *ToDoService) providerCallbackHandler(resp http.ResponseWriter, req *http.Request, name string, provider oidc.Provider) {
+	identities, ok := s.store.(storages.IdentityStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+	refresher, ok := s.store.(storages.RefreshTokenStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	cookie, err := req.Cookie(stateCookie)
+	if err != nil {
+		writeErrResp(resp, http.StatusBadRequest, oidc.ErrInvalidState)
+		return
+	}
+	st, err := s.states.Parse(name, cookie.Value, req.URL.Query().Get("state"))
+	if err != nil {
+		writeErrResp(resp, http.StatusBadRequest, err)
+		return
+	}
+	http.SetCookie(resp, &http.Cookie{Name: stateCookie, Path: "/auth/" + name + "/", MaxAge: -1})
+
+	code := req.URL.Query().Get("code")
+	if code == "" {
+		writeErrResp(resp, http.StatusBadRequest, oidc.ErrInvalidIdentity)
+		return
+	}
+	identity, err := provider.Exchange(req.Context(), code, st.Nonce())
+	if err == oidc.ErrInvalidIdentity {
+		writeErrResp(resp, http.StatusUnauthorized, err)
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		writeErrResp(resp, http.StatusBadGateway, oidc.ErrInvalidIdentity)
+		return
+	}
+
+	if st.UsrId != 0 {
+		switch err := identities.LinkIdentity(req.Context(), st.UsrId, name, identity.Subject); err {
+		case nil:
+		case storages.ErrConflict:
+			writeErrResp(resp, http.StatusConflict, errIdentityLinked)
+			return
+		default:
+			writeError(resp, err)
+			return
+		}
+	}
+
+	usr, err := identities.FindIdentityUser(req.Context(), name, identity.Subject)
+	if err == storages.ErrNotFound {
+		usr, err = provisionUser(req, identities, name, identity)
+	}
+	switch err {
+	case nil:
+		s.audit(req, storages.AuditLogin, usr.Id, usr.Id, "provider="+name)
+		s.startSession(resp, req, refresher, usr)
+	case storages.ErrConflict:
+		writeErrResp(resp, http.StatusConflict, errLinkRequired)
+	case storages.ErrInvalidUser:
+		writeErrResp(resp, http.StatusBadRequest, err)
+	default:
+		writeError(resp, err)
+	}
+}
+
+// provisionUser creates the user of an identity, named by its username or else by the local part of its email.
+// Provisioned users have no password, they log in at the provider until they reset one
+func provisionUser(req *http.Request, identities storages.IdentityStore, name string, identity *oidc.Identity) (*storages.User, error) {
+	username := identity.Username
+	if username == "" {
+		username = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	usr := &storages.User{Username: username, MaxTodo: storages.DefaultMaxTodo}
+	if err := usr.NormalizeUsername(); err != nil {
+		return nil, err
+	}
+	if err := identities.CreateIdentityUser(req.Context(), usr, name, identity.Subject); err != nil {
+		return nil, err
+	}
+	return usr, nil
+}
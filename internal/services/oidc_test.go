@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/oidc"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider vouches for identity when it's given back the nonce of the last login
+type fakeProvider struct {
+	identity *oidc.Identity
+	nonce    string
+}
+
+func (p *fakeProvider) AuthCodeURL(state, nonce string) string {
+	p.nonce = nonce
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state)
+}
+
+func (p *fakeProvider) Exchange(_ context.Context, code, nonce string) (*oidc.Identity, error) {
+	if code != "code" || nonce != p.nonce {
+		return nil, oidc.ErrInvalidIdentity
+	}
+	return p.identity, nil
+}
+
+// providerLogin goes through the redirect to the provider and back with the authorization header if any
+func providerLogin(t *testing.T, s *ToDoService, method, action, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/auth/fake/"+action, nil)
+	req.Header.Set("Authorization", authorization)
+	w := httptest.NewRecorder()
+	s.providerHandler(w, req)
+
+	location := w.Result().Header.Get("Location")
+	if action == "link" {
+		body := &struct {
+			Data linkResult `json:"data"`
+		}{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(body))
+		location = body.Data.URL
+	}
+	redirect, err := url.Parse(location)
+	require.NoError(t, err)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	req = httptest.NewRequest("GET", "/auth/fake/callback?code=code&state="+url.QueryEscape(redirect.Query().Get("state")), nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	s.providerHandler(w, req)
+	return w
+}
+
+func TestProviderLogin(t *testing.T) {
+	requireTest := require.New(t)
+	db, err := memory.NewMemory()
+	requireTest.NoError(err)
+	provider := &fakeProvider{identity: &oidc.Identity{Subject: "42", Username: "octocat"}}
+	s := NewToDoService(testJWTKey, ":6000", db, WithIdentityProviders(map[string]oidc.Provider{"fake": provider}, []byte("key")))
+
+	// the first login provisions a user which the next ones log in as
+	w := providerLogin(t, s, "GET", "login", "")
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	claims, err := s.tokens.Verify(decodeTokenPair(t, w).AccessToken)
+	requireTest.NoError(err)
+	w = providerLogin(t, s, "GET", "login", "")
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	again, err := s.tokens.Verify(decodeTokenPair(t, w).AccessToken)
+	requireTest.NoError(err)
+	requireTest.Equal(claims.UserId, again.UserId)
+
+	// an identity named like an existing user has to be linked by that user
+	provider.identity = &oidc.Identity{Subject: "7", Username: "firstUser"}
+	w = providerLogin(t, s, "GET", "login", "")
+	requireTest.Equal(http.StatusConflict, w.Result().StatusCode)
+
+	token, err := s.tokens.Issue(1, 5, "")
+	requireTest.NoError(err)
+	w = providerLogin(t, s, "POST", "link", "Bearer "+token)
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	w = providerLogin(t, s, "GET", "login", "")
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	claims, err = s.tokens.Verify(decodeTokenPair(t, w).AccessToken)
+	requireTest.NoError(err)
+	requireTest.Equal(1, claims.UserId)
+
+	// identities can't be moved to another user
+	token, err = s.tokens.Issue(2, 5, "")
+	requireTest.NoError(err)
+	w = providerLogin(t, s, "POST", "link", "Bearer "+token)
+	requireTest.Equal(http.StatusConflict, w.Result().StatusCode)
+}
+
+func TestProviderCallbackState(t *testing.T) {
+	requireTest := require.New(t)
+	db, err := memory.NewMemory()
+	requireTest.NoError(err)
+	provider := &fakeProvider{identity: &oidc.Identity{Subject: "42", Username: "octocat"}}
+	s := NewToDoService(testJWTKey, ":6000", db, WithIdentityProviders(map[string]oidc.Provider{"fake": provider}, []byte("key")))
+
+	// callbacks without the cookie of the login are rejected
+	req := httptest.NewRequest("GET", "/auth/fake/callback?code=code&state=forged", nil)
+	w := httptest.NewRecorder()
+	s.providerHandler(w, req)
+	requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+
+	req = httptest.NewRequest("GET", "/auth/unknown/login", nil)
+	w = httptest.NewRecorder()
+	s.providerHandler(w, req)
+	requireTest.Equal(http.StatusNotFound, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/auth/fake/link", nil)
+	w = httptest.NewRecorder()
+	s.providerHandler(w, req)
+	requireTest.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+}
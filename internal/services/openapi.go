@@ -0,0 +1,443 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+)
+
+// apiAuth tells who may call an operation
+type apiAuth int
+
+const (
+	// authPublic operations don't need a token
+	authPublic apiAuth = iota
+	// authUser operations need a token which is not limited to scopes
+	authUser
+	// authTasks operations need a token, scoped ones need tasks:read to read and tasks:write to change
+	authTasks
+	// authAdmin operations need the token of an admin, scoped ones need the admin scope
+	authAdmin
+)
+
+// apiOperation documents an operation of the HTTP API. Body and Data are values of the types of the
+// request body and of the data of the response, nil if there is none. Raw responses are not wrapped in data
+type apiOperation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Auth    apiAuth
+	Query   []string
+	Body    interface{}
+	Status  int
+	Data    interface{}
+	Raw     bool
+	// Upload operations take a multipart body, Download ones answer the content of a file
+	Upload, Download bool
+	// Stream operations answer server-sent events of Data until the client disconnects
+	Stream bool
+	// Idempotent operations replay their response to retries with the same Idempotency-Key
+	Idempotent bool
+}
+
+// taskQuery are the query params of listings of tasks
+var taskQuery = []string{"created_date", "completed", "sort_by", "tag", "owner", "render"}
+
+// graphqlRequest is the body of POST /graphql
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// apiOperations are the operations of the HTTP API served at /openapi.json, every route of NewToDoService
+// must be listed here
+var apiOperations = []apiOperation{
+	{Method: "POST", Path: "/login", Tag: "auth", Summary: "Log in, the data is an access token", Body: loginParams{}, Data: ""},
+	{Method: "POST", Path: "/signup", Tag: "auth", Summary: "Register a user", Body: signupParams{}, Status: http.StatusCreated, Data: signupResult{}},
+	{Method: "GET", Path: "/.well-known/jwks.json", Tag: "auth", Summary: "Public keys which verify RS256 access tokens", Data: tokens.JWKS{}, Raw: true},
+	{Method: "POST", Path: "/auth/login", Tag: "auth", Summary: "Log in with a refresh token", Body: loginParams{}, Data: tokenPair{}},
+	{Method: "POST", Path: "/auth/refresh", Tag: "auth", Summary: "Trade a refresh token for new tokens", Body: refreshParams{}, Data: tokenPair{}},
+	{Method: "POST", Path: "/auth/logout", Tag: "auth", Summary: "Revoke a refresh token", Body: refreshParams{}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/auth/{provider}/login", Tag: "auth", Summary: "Log in at an identity provider", Status: http.StatusFound},
+	{Method: "POST", Path: "/auth/{provider}/link", Tag: "auth", Summary: "Link an identity of a provider to the user", Auth: authUser, Data: linkResult{}},
+	{Method: "GET", Path: "/auth/{provider}/callback", Tag: "auth", Summary: "Redirect back from an identity provider", Query: []string{"code", "state"}, Data: tokenPair{}},
+	{Method: "POST", Path: "/password/reset", Tag: "auth", Summary: "Mail a password reset token", Body: resetParams{}, Status: http.StatusAccepted},
+	{Method: "POST", Path: "/password/reset/confirm", Tag: "auth", Summary: "Set a new password with a reset token", Body: confirmResetParams{}, Status: http.StatusNoContent},
+
+	{Method: "POST", Path: "/users/me/password", Tag: "users", Summary: "Change the password", Auth: authUser, Body: changePasswordParams{}, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/users/me/2fa", Tag: "users", Summary: "Start a two-factor enrollment", Auth: authUser, Status: http.StatusCreated, Data: twoFactorEnrollment{}},
+	{Method: "DELETE", Path: "/users/me/2fa", Tag: "users", Summary: "Disable two-factor authentication", Auth: authUser, Body: twoFactorParams{}, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/users/me/2fa/confirm", Tag: "users", Summary: "Confirm the two-factor enrollment", Auth: authUser, Body: twoFactorParams{}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/users/me/shares", Tag: "users", Summary: "List the users the tasks are shared with", Auth: authUser, Data: []storages.Share{}},
+	{Method: "PUT", Path: "/users/me/shares/{usrId}", Tag: "users", Summary: "Share the tasks with a user", Auth: authUser, Body: storages.Share{}, Data: storages.Share{}},
+	{Method: "DELETE", Path: "/users/me/shares/{usrId}", Tag: "users", Summary: "Stop sharing the tasks with a user", Auth: authUser, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/users/me/tokens", Tag: "users", Summary: "Issue a token limited to scopes", Auth: authUser, Body: scopedTokenParams{}, Status: http.StatusCreated, Data: scopedToken{}},
+	{Method: "POST", Path: "/users/me/links", Tag: "users", Summary: "Mint a read-only share link", Auth: authUser, Body: linkParams{}, Status: http.StatusCreated, Data: shareLink{}},
+	{Method: "GET", Path: "/users/me/sessions", Tag: "users", Summary: "List the sessions", Auth: authUser, Data: []storages.Session{}},
+	{Method: "DELETE", Path: "/users/me/sessions", Tag: "users", Summary: "Log out everywhere", Auth: authUser, Status: http.StatusNoContent},
+	{Method: "DELETE", Path: "/users/me/sessions/{id}", Tag: "users", Summary: "Log a session out", Auth: authUser, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/users/me/limit", Tag: "users", Summary: "Get the daily-limit, null when unlimited", Auth: authUser, Data: userLimit{}},
+	{Method: "GET", Path: "/users/me/quota", Tag: "users", Summary: "Get the quota of the day, limit and remaining are null when unlimited", Auth: authUser, Data: quotaResult{}},
+	{Method: "GET", Path: "/users/me/timezone", Tag: "users", Summary: "Get the timezone days are counted in", Auth: authUser, Data: timezoneParams{}},
+	{Method: "PUT", Path: "/users/me/timezone", Tag: "users", Summary: "Change the timezone days are counted in", Auth: authUser, Body: timezoneParams{}, Data: timezoneParams{}},
+	{Method: "GET", Path: "/users/me/usage", Tag: "users", Summary: "Count the tasks added a day, the last 30 days by default", Auth: authUser, Query: []string{"from", "to"}, Data: usageResult{}},
+	{Method: "GET", Path: "/users/me/stats", Tag: "users", Summary: "Count the tasks created and completed a day over the last days, 30 by default", Auth: authUser, Query: []string{"days"}, Data: statsResult{}},
+	{Method: "GET", Path: "/links/{token}", Tag: "users", Summary: "List the tasks of a share link", Query: taskQuery[:4], Data: []storages.Task{}},
+
+	{Method: "GET", Path: "/admin/audit", Tag: "admin", Summary: "List audit events", Auth: authAdmin, Query: []string{"usr_id", "type", "since", "limit"}, Data: []storages.AuditEvent{}},
+	{Method: "GET", Path: "/admin/users", Tag: "admin", Summary: "List the users", Auth: authAdmin, Data: []userResult{}},
+	{Method: "PATCH", Path: "/admin/users/{id}", Tag: "admin", Summary: "Change the role or the daily-limit of a user", Auth: authAdmin, Body: storages.UserPatch{}, Data: userResult{}},
+	{Method: "PATCH", Path: "/users/{id}/limit", Tag: "admin", Summary: "Change the daily-limit of a user, 0 or null lifts it", Auth: authAdmin, Body: userLimit{}, Data: userLimit{}},
+	{Method: "GET", Path: "/users/{id}/usage", Tag: "admin", Summary: "Count the tasks a user added a day", Auth: authAdmin, Query: []string{"from", "to"}, Data: usageResult{}},
+	{Method: "POST", Path: "/admin/users/{id}/quota/reset", Tag: "admin", Summary: "Stop the tasks a user added so far from counting towards their limit", Auth: authAdmin, Data: quotaResult{}},
+	{Method: "PUT", Path: "/admin/users/{id}/plan", Tag: "admin", Summary: "Put a user on a plan, null takes them off theirs", Auth: authAdmin, Body: userPlan{}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/admin/plans", Tag: "admin", Summary: "List the plans", Auth: authAdmin, Data: []planResult{}},
+	{Method: "PUT", Path: "/admin/plans/{name}", Tag: "admin", Summary: "Create or change a plan, a max_todo of 0 or null lifts its limit", Auth: authAdmin, Body: userLimit{}, Data: planResult{}},
+	{Method: "DELETE", Path: "/admin/plans/{name}", Tag: "admin", Summary: "Delete a plan, its users get their own limits back", Auth: authAdmin, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/admin/log-level", Tag: "admin", Summary: "Get the level of the logs and the users and routes logged at debug level", Auth: authAdmin, Data: logLevelResult{}},
+	{Method: "PUT", Path: "/admin/log-level", Tag: "admin", Summary: "Change the level of the logs or log a user or a route at debug level for a while", Auth: authAdmin, Body: logLevelParams{}, Data: logLevelResult{}},
+
+	{Method: "GET", Path: "/tasks", Tag: "tasks", Summary: "List tasks created on a date, or the overdue ones", Auth: authTasks, Query: append([]string{"overdue"}, taskQuery...), Data: []storages.Task{}},
+	{Method: "POST", Path: "/tasks", Tag: "tasks", Summary: "Create a task", Auth: authTasks, Query: []string{"owner", "render"}, Body: storages.Task{}, Data: storages.Task{}, Idempotent: true},
+	{Method: "POST", Path: "/tasks:batch", Tag: "tasks", Summary: "Create tasks all at once", Auth: authTasks, Body: taskBatch{}, Status: http.StatusCreated, Data: []batchResult{}},
+	{Method: "POST", Path: "/tasks:complete", Tag: "tasks", Summary: "Complete the selected tasks", Auth: authTasks, Body: storages.TaskSelector{}, Data: bulkResult{}},
+	{Method: "POST", Path: "/tasks:delete", Tag: "tasks", Summary: "Delete the selected tasks", Auth: authTasks, Body: storages.TaskSelector{}, Data: bulkResult{}},
+	{Method: "GET", Path: "/tasks/trash", Tag: "tasks", Summary: "List deleted tasks", Auth: authTasks, Data: []storages.Task{}},
+	{Method: "GET", Path: "/tasks/stream", Tag: "tasks", Summary: "Stream changes of tasks", Auth: authTasks, Query: []string{"owner"}, Data: events.TaskEvent{}, Stream: true},
+	{Method: "GET", Path: "/tasks/{id}", Tag: "tasks", Summary: "Get a task", Auth: authTasks, Query: []string{"owner", "render"}, Data: storages.Task{}},
+	{Method: "PUT", Path: "/tasks/{id}", Tag: "tasks", Summary: "Update a task", Auth: authTasks, Query: []string{"owner", "render"}, Body: storages.Task{}, Data: storages.Task{}},
+	{Method: "DELETE", Path: "/tasks/{id}", Tag: "tasks", Summary: "Move a task to the trash", Auth: authTasks, Query: []string{"owner"}, Status: http.StatusNoContent},
+	{Method: "PATCH", Path: "/tasks/{id}/complete", Tag: "tasks", Summary: "Mark a task done", Auth: authTasks, Query: []string{"owner"}, Status: http.StatusNoContent},
+	{Method: "PATCH", Path: "/tasks/{id}/uncomplete", Tag: "tasks", Summary: "Mark a task not done", Auth: authTasks, Query: []string{"owner"}, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/tasks/{id}/tags", Tag: "tasks", Summary: "Add tags to a task", Auth: authTasks, Body: taskTags{}, Status: http.StatusNoContent},
+	{Method: "DELETE", Path: "/tasks/{id}/tags", Tag: "tasks", Summary: "Remove tags of a task", Auth: authTasks, Body: taskTags{}, Status: http.StatusNoContent},
+	{Method: "PATCH", Path: "/tasks/{id}/position", Tag: "tasks", Summary: "Move a task in the manual order", Auth: authTasks, Body: taskPosition{}, Status: http.StatusNoContent},
+	{Method: "PATCH", Path: "/tasks/{id}/reminder", Tag: "tasks", Summary: "Set the reminder of a task", Auth: authTasks, Body: taskReminder{}, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/tasks/{id}/restore", Tag: "tasks", Summary: "Restore a task from the trash", Auth: authTasks, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/tasks/{id}/history", Tag: "tasks", Summary: "List the changes of a task", Auth: authTasks, Query: []string{"owner"}, Data: []storages.TaskAuditEntry{}},
+	{Method: "GET", Path: "/tasks/{id}/items", Tag: "tasks", Summary: "List the checklist of a task", Auth: authTasks, Data: []storages.ChecklistItem{}},
+	{Method: "POST", Path: "/tasks/{id}/items", Tag: "tasks", Summary: "Add a checklist item", Auth: authTasks, Body: storages.ChecklistItem{}, Status: http.StatusCreated, Data: storages.ChecklistItem{}},
+	{Method: "PUT", Path: "/tasks/{id}/items", Tag: "tasks", Summary: "Reorder the checklist", Auth: authTasks, Body: checklistOrder{}, Status: http.StatusNoContent},
+	{Method: "PATCH", Path: "/tasks/{id}/items/{itemId}/complete", Tag: "tasks", Summary: "Check a checklist item", Auth: authTasks, Status: http.StatusNoContent},
+	{Method: "PATCH", Path: "/tasks/{id}/items/{itemId}/uncomplete", Tag: "tasks", Summary: "Uncheck a checklist item", Auth: authTasks, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/tasks/{id}/comments", Tag: "tasks", Summary: "List the comments of a task", Auth: authTasks, Query: []string{"render"}, Data: []storages.Comment{}},
+	{Method: "POST", Path: "/tasks/{id}/comments", Tag: "tasks", Summary: "Comment a task", Auth: authTasks, Body: storages.Comment{}, Status: http.StatusCreated, Data: storages.Comment{}},
+	{Method: "DELETE", Path: "/tasks/{id}/comments/{commentId}", Tag: "tasks", Summary: "Delete a comment", Auth: authTasks, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/tasks/{id}/attachments", Tag: "tasks", Summary: "List the attachments of a task", Auth: authTasks, Data: []storages.Attachment{}},
+	{Method: "POST", Path: "/tasks/{id}/attachments", Tag: "tasks", Summary: "Upload the file part as an attachment", Auth: authTasks, Upload: true, Status: http.StatusCreated, Data: storages.Attachment{}},
+	{Method: "GET", Path: "/tasks/{id}/attachments/{attachmentId}", Tag: "tasks", Summary: "Download an attachment", Auth: authTasks, Download: true},
+	{Method: "DELETE", Path: "/tasks/{id}/attachments/{attachmentId}", Tag: "tasks", Summary: "Delete an attachment", Auth: authTasks, Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/projects", Tag: "projects", Summary: "List projects", Auth: authTasks, Data: []storages.Project{}},
+	{Method: "POST", Path: "/projects", Tag: "projects", Summary: "Create a project", Auth: authTasks, Body: storages.Project{}, Status: http.StatusCreated, Data: storages.Project{}},
+	{Method: "GET", Path: "/projects/{id}", Tag: "projects", Summary: "Get a project", Auth: authTasks, Data: storages.Project{}},
+	{Method: "PUT", Path: "/projects/{id}", Tag: "projects", Summary: "Update a project", Auth: authTasks, Body: storages.Project{}, Data: storages.Project{}},
+	{Method: "DELETE", Path: "/projects/{id}", Tag: "projects", Summary: "Delete a project", Auth: authTasks, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/projects/{id}/tasks", Tag: "projects", Summary: "List tasks of a project created on a date", Auth: authTasks, Query: taskQuery, Data: []storages.Task{}},
+
+	{Method: "GET", Path: "/templates", Tag: "templates", Summary: "List templates", Auth: authTasks, Data: []storages.Template{}},
+	{Method: "POST", Path: "/templates", Tag: "templates", Summary: "Create a template", Auth: authTasks, Body: newTemplate{}, Status: http.StatusCreated, Data: storages.Template{}},
+	{Method: "GET", Path: "/templates/{id}", Tag: "templates", Summary: "Get a template", Auth: authTasks, Data: storages.Template{}},
+	{Method: "DELETE", Path: "/templates/{id}", Tag: "templates", Summary: "Delete a template", Auth: authTasks, Status: http.StatusNoContent},
+	{Method: "POST", Path: "/templates/{id}/instantiate", Tag: "templates", Summary: "Create the tasks of a template", Auth: authTasks, Status: http.StatusCreated, Data: []storages.Task{}},
+
+	{Method: "GET", Path: "/graphql", Tag: "graphql", Summary: "Run a GraphQL query", Auth: authTasks, Query: []string{"query", "operationName", "variables"}, Data: map[string]interface{}{}},
+	{Method: "POST", Path: "/graphql", Tag: "graphql", Summary: "Run a GraphQL query or mutation", Auth: authTasks, Body: graphqlRequest{}, Data: map[string]interface{}{}},
+	{Method: "GET", Path: "/openapi.json", Tag: "docs", Summary: "This document", Data: map[string]interface{}{}, Raw: true},
+	{Method: "GET", Path: "/docs", Tag: "docs", Summary: "Swagger UI of this document"},
+}
+
+// authDescriptions are appended to the summaries of operations which need a token
+var authDescriptions = map[apiAuth]string{
+	authUser:  "Scoped tokens are rejected.",
+	authTasks: "Scoped tokens need tasks:read for GET requests and tasks:write otherwise.",
+	authAdmin: "Admins only, scoped tokens need the admin scope.",
+}
+
+var pathParam = regexp.MustCompile(`{([a-zA-Z]+)}`)
+
+// openAPIDoc returns the OpenAPI 3 document of ops, schemas of their bodies are generated from the Go types
+func openAPIDoc(ops []apiOperation) map[string]interface{} {
+	schemas := &schemaGen{schemas: map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{"error": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"code", "message"},
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "string", "example": codeValidation},
+					"message": map[string]interface{}{"type": "string"},
+					"details": map[string]interface{}{},
+				},
+			}},
+		},
+	}}
+	errResp := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+		}
+	}
+	security := []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}}
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range ops {
+		params := []map[string]interface{}{}
+		for _, match := range pathParam.FindAllStringSubmatch(op.Path, -1) {
+			typ := "integer"
+			if match[1] == "provider" || match[1] == "token" {
+				typ = "string"
+			}
+			params = append(params, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true, "schema": map[string]interface{}{"type": typ},
+			})
+		}
+		for _, name := range op.Query {
+			params = append(params, map[string]interface{}{"name": name, "in": "query", "schema": map[string]interface{}{"type": "string"}})
+		}
+		if op.Idempotent {
+			params = append(params, map[string]interface{}{
+				"name": idempotencyHeader, "in": "header", "schema": map[string]interface{}{"type": "string", "maxLength": maxIdempotencyKeyLen},
+				"description": "Retries with the same key are answered by the response to the first request",
+			})
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		switch {
+		case op.Download:
+			success["content"] = map[string]interface{}{
+				"application/octet-stream": map[string]interface{}{"schema": map[string]interface{}{"type": "string", "format": "binary"}},
+			}
+		case op.Stream:
+			success["content"] = map[string]interface{}{
+				"text/event-stream": map[string]interface{}{"schema": schemas.of(reflect.TypeOf(op.Data))},
+			}
+		case op.Data != nil && op.Raw:
+			success["content"] = jsonContent(schemas.of(reflect.TypeOf(op.Data)))
+		case op.Data != nil:
+			success["content"] = jsonContent(map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"data": schemas.of(reflect.TypeOf(op.Data))},
+			})
+		}
+		// lists of tasks are also streamed as CSV or NDJSON, see writeTasks
+		if _, ok := op.Data.([]storages.Task); ok && op.Method == http.MethodGet {
+			content := success["content"].(map[string]interface{})
+			content[mimeCSV] = map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+			content[mimeNDJSON] = map[string]interface{}{"schema": schemas.of(reflect.TypeOf(storages.Task{}))}
+		}
+		responses := map[string]interface{}{
+			strconv.Itoa(status): success,
+			"default":            errResp("Error"),
+		}
+
+		operation := map[string]interface{}{
+			"tags":        []string{op.Tag},
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"parameters":  params,
+			"responses":   responses,
+		}
+		if op.Auth != authPublic {
+			operation["security"] = security
+			operation["description"] = authDescriptions[op.Auth]
+			responses["401"] = errResp("Missing or invalid token")
+			responses["403"] = errResp("Forbidden")
+		} else {
+			operation["security"] = []map[string][]string{}
+		}
+		switch {
+		case op.Upload:
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{"multipart/form-data": map[string]interface{}{"schema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"file": map[string]interface{}{"type": "string", "format": "binary"}},
+				}}},
+			}
+		case op.Body != nil:
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(schemas.of(reflect.TypeOf(op.Body))),
+			}
+			responses["413"] = errResp("Body too large")
+			responses["422"] = errResp("Invalid fields, details lists them as {field, message}")
+		}
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = make(map[string]interface{})
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "togo",
+			"version":     "1.0.0",
+			"description": "Responses wrap their data in {\"data\": ...} and errors in {\"error\": {\"code\": ..., \"message\": ...}}.",
+		},
+		"servers": []map[string]string{{"url": currentVersion}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"cookieAuth": map[string]interface{}{"type": "apiKey", "in": "cookie", "name": accessCookie},
+			},
+		},
+	}
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// operationID names op after its method and the static parts of its path, e.g. getTasksIdComments
+func operationID(op apiOperation) string {
+	id := strings.ToLower(op.Method)
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return r == '/' || r == ':' || r == '.' || r == '-' }) {
+		part = strings.Trim(part, "{}")
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// schemaGen generates JSON schemas of Go types as encoding/json marshals them, named structs are
+// put in schemas and referred to
+type schemaGen struct {
+	schemas map[string]interface{}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGen) of(t reflect.Type) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.of(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.of(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.of(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = nil // placeholder against recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// object returns the schema of the fields of struct t, fields of embedded structs are promoted
+func (g *schemaGen) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var fields func(t reflect.Type)
+	fields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				fields(f.Type)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = g.of(f.Type)
+		}
+	}
+	fields(t)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// openAPIHandler serves the OpenAPI document of the HTTP API at GET /openapi.json
+func (s *ToDoService) openAPIHandler() http.HandlerFunc {
+	doc, err := json.Marshal(openAPIDoc(apiOperations))
+	if err != nil {
+		panic(err)
+	}
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(resp, errMethodNotAllowed)
+			return
+		}
+		if _, err := resp.Write(doc); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// swaggerUIVersion is the version of swagger-ui-dist the page at /docs loads from unpkg
+const swaggerUIVersion = "3.44.0"
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>togo API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function () {
+      SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+// docsHandler serves Swagger UI of /openapi.json at GET /docs
+func (s *ToDoService) docsHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+	resp.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := resp.Write([]byte(swaggerUIPage)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	admin := &storages.User{Username: "admin", Role: storages.RoleAdmin, MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), admin))
+	s := NewToDoService(testJWTKey, ":6000", m)
+	token, err := s.createToken(admin)
+	requireTest.NoError(err)
+	// streams end at once for requests which are already done
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, op := range apiOperations {
+		path := pathParam.ReplaceAllStringFunc(op.Path, func(param string) string {
+			if param == "{provider}" || param == "{token}" {
+				return "x"
+			}
+			return "1"
+		})
+		req := httptest.NewRequest(op.Method, currentVersion+path, strings.NewReader("{}"))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if op.Stream {
+			req = req.WithContext(done)
+		}
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+
+		// routes which are not served answer 405, or 404 with ROUTE_NOT_FOUND
+		code := w.Result().StatusCode
+		requireTest.NotEqual(http.StatusMethodNotAllowed, code, op.Method+" "+op.Path)
+		if code == http.StatusNotFound {
+			requireTest.NotContains(w.Body.String(), codeRouteNotFound, op.Method+" "+op.Path)
+		}
+	}
+}
+
+func TestOpenAPIDoc(t *testing.T) {
+	requireTest := require.New(t)
+	s := NewToDoService(testJWTKey, ":6000", nil)
+
+	w := httptest.NewRecorder()
+	s.openAPIHandler()(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	doc := &struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+		Schemas struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(doc))
+	requireTest.Equal("3.0.3", doc.OpenAPI)
+	requireTest.Contains(doc.Paths["/tasks/{id}"], "put")
+	requireTest.Equal("putTasksId", doc.Paths["/tasks/{id}"]["put"]["operationId"])
+
+	task := doc.Schemas.Schemas["Task"]
+	requireTest.Equal("date-time", task.Properties["create_at"]["format"])
+	requireTest.Equal("array", task.Properties["tags"]["type"])
+	requireTest.NotContains(doc.Schemas.Schemas["Attachment"].Properties, "BlobKey")
+	// fields of embedded structs are promoted
+	requireTest.Contains(doc.Schemas.Schemas["NewTemplate"].Properties, "name")
+	requireTest.Contains(doc.Schemas.Schemas["NewTemplate"].Properties, "task_ids")
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/mailer"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/manabie-com/togo/internal/tokens"
+	"go.uber.org/zap"
+)
+
+// resetMailTimeout bounds the delivery of a reset mail, it's sent once the request is answered
+const resetMailTimeout = 30 * time.Second
+
+var (
+	errWrongPassword     = errors.New("current password is not correct")
+	errInvalidResetToken = errors.New("reset token is not valid")
+)
+
+// changePasswordParams is the body of /users/me/password
+type changePasswordParams struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// resetParams is the body of /password/reset
+type resetParams struct {
+	Username string `json:"username"`
+}
+
+// confirmResetParams is the body of /password/reset/confirm
+type confirmResetParams struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// changePasswordHandler sets the new password of the user at /users/me/password once the current one
+// is checked, every refresh token of the user is revoked so other sessions have to log in again
+func (s *ToDoService) changePasswordHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.PasswordStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	params := &changePasswordParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+
+	usr, err := store.GetUser(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if !password.Compare(usr.PwdHash, params.CurrentPassword) {
+		writeErrResp(resp, http.StatusForbidden, errWrongPassword)
+		return
+	}
+
+	pwdHash, err := password.Hash(params.NewPassword)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := store.SetPassword(req.Context(), userID, pwdHash); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.audit(req, storages.AuditPasswordChanged, userID, userID, "")
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// resetPasswordHandler mails a password reset token to the user of the posted username at /password/reset.
+// It answers 202 whether the user exists or not and before the mail is sent so that usernames can't be probed.
+// Every reset counts for the username and the address like failed logins do so that nobody is flooded with mails
+func (s *ToDoService) resetPasswordHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.PasswordStore)
+	if !ok || s.mailer == nil {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	params := &resetParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+
+	if s.throttle != nil {
+		keys := []string{throttle.ResetKey(params.Username)}
+		if host := clientIP(req); host != "" {
+			keys = append(keys, throttle.AddrKey(host))
+		}
+		wait, err := s.throttle.Wait(req.Context(), keys...)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		if wait > 0 {
+			writeThrottledResp(resp, wait)
+			return
+		}
+		s.throttle.Fail(req.Context(), keys...)
+	}
+
+	secret, hash, err := tokens.NewResetToken()
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	reset := &storages.PasswordReset{Hash: hash, ExpiresAt: time.Now().Add(s.resetTTL)}
+	usr, err := store.CreatePasswordReset(req.Context(), params.Username, reset)
+	switch err {
+	case nil:
+		s.sendResetMail(req.Context(), &mailer.Message{
+			To:      usr.Username,
+			Subject: "Reset your password",
+			Body:    fmt.Sprintf("Your password reset token is valid until %s:\n\n%s\n", reset.ExpiresAt.UTC().Format(time.RFC1123), secret),
+		})
+	case storages.ErrNotFound:
+	default:
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// sendResetMail sends msg apart from the request of ctx, failures are only logged as the request is answered
+// already and the user may ask for another token. Shutdown waits for the mails being sent
+func (s *ToDoService) sendResetMail(ctx context.Context, msg *mailer.Message) {
+	logger := logging.FromContext(ctx)
+	s.mails.Add(1)
+	go func() {
+		defer s.mails.Done()
+		ctx, cancel := context.WithTimeout(logging.NewContext(context.Background(), logger), resetMailTimeout)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			logger.Warn("sending password reset mail", zap.Error(err))
+		}
+	}()
+}
+
+// confirmResetHandler sets the new password of the user of a reset token at /password/reset/confirm,
+// the token can't be used again and every refresh token of the user is revoked
+func (s *ToDoService) confirmResetHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.PasswordStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	params := &confirmResetParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+	pwdHash, err := password.Hash(params.NewPassword)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	usr, err := store.ResetPassword(req.Context(), tokens.HashResetToken(params.Token), pwdHash)
+	switch err {
+	case nil:
+		s.audit(req, storages.AuditPasswordReset, usr.Id, usr.Id, "")
+		resp.WriteHeader(http.StatusNoContent)
+	case storages.ErrInvalidCredentials:
+		writeErrResp(resp, http.StatusBadRequest, errInvalidResetToken)
+	default:
+		writeError(resp, err)
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/mailer"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangePassword(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	pwdHash, err := password.Hash("s3cretpass")
+	requireTest.NoError(err)
+	db.On("GetUser", ctx, 1).Return(&storages.User{Id: 1, PwdHash: pwdHash}, nil)
+	db.On("SetPassword", ctx, 1, mock.MatchedBy(func(pwdHash string) bool {
+		return password.Compare(pwdHash, "n3wpassword")
+	})).Return(nil).Once()
+
+	req := httptest.NewRequest("POST", "/users/me/password", bytes.NewBufferString(`{"current_password": "s3cretpass", "new_password": "n3wpassword"}`)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	s.changePasswordHandler(w, req)
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/users/me/password", bytes.NewBufferString(`{"current_password": "wrong", "new_password": "n3wpassword"}`)).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.changePasswordHandler(w, req)
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/users/me/password", bytes.NewBufferString(`{"current_password": "s3cretpass", "new_password": "weak"}`)).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.changePasswordHandler(w, req)
+	requireTest.Equal(http.StatusUnprocessableEntity, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	var sent []*mailer.Message
+	s := NewToDoService(testJWTKey, ":6000", db, WithMailer(mailer.Func(func(_ context.Context, msg *mailer.Message) error {
+		sent = append(sent, msg)
+		return nil
+	})))
+
+	req := httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(`{"username": "firstUser"}`))
+	var hash string
+	db.On("CreatePasswordReset", req.Context(), "firstUser", mock.Anything).Return(&storages.User{Id: 1, Username: "firstUser"}, nil).Run(func(args mock.Arguments) {
+		hash = args.Get(2).(*storages.PasswordReset).Hash
+	})
+	w := httptest.NewRecorder()
+	s.resetPasswordHandler(w, req)
+	requireTest.Equal(http.StatusAccepted, w.Result().StatusCode)
+	// the mail is sent once the request is answered
+	s.mails.Wait()
+	requireTest.Len(sent, 1)
+	requireTest.Equal("firstUser", sent[0].To)
+	lines := strings.Split(strings.TrimSpace(sent[0].Body), "\n")
+	secret := lines[len(lines)-1]
+	requireTest.Equal(hash, tokens.HashResetToken(secret))
+
+	// unknown users get the same answer without a mail
+	req = httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(`{"username": "nobody"}`))
+	db.On("CreatePasswordReset", req.Context(), "nobody", mock.Anything).Return((*storages.User)(nil), storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.resetPasswordHandler(w, req)
+	requireTest.Equal(http.StatusAccepted, w.Result().StatusCode)
+	s.mails.Wait()
+	requireTest.Len(sent, 1)
+
+	req = httptest.NewRequest("POST", "/password/reset/confirm", bytes.NewBufferString(`{"token": "`+secret+`", "new_password": "n3wpassword"}`))
+	db.On("ResetPassword", req.Context(), hash, mock.Anything).Return(&storages.User{Id: 1}, nil)
+	w = httptest.NewRecorder()
+	s.confirmResetHandler(w, req)
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/password/reset/confirm", bytes.NewBufferString(`{"token": "used", "new_password": "n3wpassword"}`))
+	db.On("ResetPassword", req.Context(), tokens.HashResetToken("used"), mock.Anything).Return((*storages.User)(nil), storages.ErrInvalidCredentials)
+	w = httptest.NewRecorder()
+	s.confirmResetHandler(w, req)
+	requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+	requireTest.Contains(w.Body.String(), errInvalidResetToken.Error())
+
+	db.AssertExpectations(t)
+}
+
+func TestResetPasswordThrottle(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	sent := make(chan *mailer.Message, 10)
+	s := NewToDoService(testJWTKey, ":6000", m,
+		WithLoginThrottle(throttle.NewThrottler(m, 2, time.Minute, 0)),
+		WithMailer(mailer.Func(func(_ context.Context, msg *mailer.Message) error {
+			sent <- msg
+			return nil
+		})))
+	reset := func(username, addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(`{"username": "`+username+`"}`))
+		req.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		s.resetPasswordHandler(w, req)
+		return w
+	}
+
+	// resets of unknown users count too, they're answered alike
+	requireTest.Equal(http.StatusAccepted, reset("nobody", "10.0.0.1:1234").Code)
+	requireTest.Equal(http.StatusAccepted, reset("firstUser", "10.0.0.1:1234").Code)
+	w := reset("firstUser", "10.0.0.1:1234")
+	requireTest.Equal(http.StatusTooManyRequests, w.Code, "the address asked too many")
+	requireTest.NotEmpty(w.Header().Get("Retry-After"))
+
+	requireTest.Equal(http.StatusAccepted, reset("firstUser", "10.0.0.2:1234").Code)
+	requireTest.Equal(http.StatusTooManyRequests, reset("firstUser", "10.0.0.3:1234").Code, "the user is sent too many")
+	s.mails.Wait()
+	requireTest.Len(sent, 2)
+
+	// logins of the user aren't slowed down by them
+	_, wait, err := s.login(context.Background(), client{IP: "10.0.0.4"}, &loginParams{Username: "firstUser", Password: "example"})
+	requireTest.NoError(err)
+	requireTest.Zero(wait)
+}
+
+func TestResetPasswordWithoutMailer(t *testing.T) {
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock))
+
+	req := httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(`{"username": "firstUser"}`))
+	w := httptest.NewRecorder()
+	s.resetPasswordHandler(w, req)
+	require.Equal(t, http.StatusNotImplemented, w.Result().StatusCode)
+}
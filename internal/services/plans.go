@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// userPlanSuffix ends the path of the plan of a user, /admin/users/{id}/plan
+const userPlanSuffix = "/plan"
+
+var (
+	planName = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+
+	errInvalidPlanName = errors.New("plan names must be 1 to 32 lowercase letters, digits or dashes")
+)
+
+// planResult is a plan, MaxTodo is null when its users may add any number of tasks a day
+type planResult struct {
+	Name    string `json:"name"`
+	MaxTodo *int   `json:"max_todo"`
+}
+
+func newPlanResult(plan *storages.Plan) planResult {
+	return planResult{Name: plan.Name, MaxTodo: newUserLimit(plan.MaxTodo).MaxTodo}
+}
+
+// userPlan puts a user on a plan, null takes them off theirs
+type userPlan struct {
+	Plan *string `json:"plan"`
+}
+
+// adminPlansHandler lists the plans at GET /admin/plans
+func (s *ToDoService) adminPlansHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.PlanStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	plans, err := store.ListPlans(req.Context())
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	results := make([]planResult, 0, len(plans))
+	for _, plan := range plans {
+		results = append(results, newPlanResult(plan))
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(results)); err != nil {
+		log.Println(err)
+	}
+}
+
+// adminPlanHandler creates or changes a plan at PUT /admin/plans/{name}, a max_todo of 0 or null lifts the
+// daily-limit, and deletes one at DELETE /admin/plans/{name}. Users of a deleted plan get their own limits back
+func (s *ToDoService) adminPlanHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	name := strings.TrimPrefix(req.URL.Path, "/admin/plans/")
+	if strings.Contains(name, "/") {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+
+	store, ok := s.store.(storages.PlanStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+	actorId, _ := userIDFromCtx(req.Context())
+
+	switch req.Method {
+	case http.MethodPut:
+		if !planName.MatchString(name) {
+			writeError(resp, errInvalidPlanName)
+			return
+		}
+		params := &userLimit{}
+		if !decodeBody(resp, req, params, maxJsonSize) {
+			return
+		}
+		plan := &storages.Plan{Name: name, MaxTodo: storages.UnlimitedTodo}
+		if params.MaxTodo != nil {
+			plan.MaxTodo = *params.MaxTodo
+		}
+		if err := store.PutPlan(req.Context(), plan); err != nil {
+			writeError(resp, err)
+			return
+		}
+		s.audit(req, storages.AuditQuotaChanged, actorId, 0, "plan="+name+" max_todo="+strconv.Itoa(plan.MaxTodo))
+		if err := json.NewEncoder(resp).Encode(newDataResp(newPlanResult(plan))); err != nil {
+			log.Println(err)
+		}
+	case http.MethodDelete:
+		if err := store.DeletePlan(req.Context(), name); err != nil {
+			writeError(resp, err)
+			return
+		}
+		s.audit(req, storages.AuditQuotaChanged, actorId, 0, "plan="+name+" deleted")
+		resp.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(resp, errMethodNotAllowed)
+	}
+}
+
+// adminUserPlanHandler puts a user on a plan at PUT /admin/users/{id}/plan, they get the daily-limit of the
+// plan rather than their own until taken off it by a null plan. Like other limits it applies to the next task
+func (s *ToDoService) adminUserPlanHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	id, action, ok := parseItemPath("/admin/users/", strings.TrimSuffix(req.URL.Path, userPlanSuffix))
+	if !ok || action != "" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodPut {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.PlanStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	params := &userPlan{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+	name := ""
+	if params.Plan != nil {
+		name = *params.Plan
+	}
+
+	if err := store.SetUserPlan(req.Context(), id, name); err != nil {
+		writeError(resp, err)
+		return
+	}
+	actorId, _ := userIDFromCtx(req.Context())
+	s.audit(req, storages.AuditQuotaChanged, actorId, id, "plan="+name)
+	resp.WriteHeader(http.StatusNoContent)
+}
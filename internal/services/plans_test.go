@@ -0,0 +1,62 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminPlans(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	serve := func(handler http.HandlerFunc, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.adminHandler(handler)(w, newAdminRequest(t, s, method, path, body, storages.RoleAdmin))
+		return w
+	}
+	addTask := func() int {
+		req := httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"content": "milk"}`))
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w.Code
+	}
+
+	w := serve(s.adminPlansHandler, "GET", "/admin/plans", "")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": [{"name": "free", "max_todo": 5}, {"name": "pro", "max_todo": 50}]}`, w.Body.String())
+
+	w = serve(s.adminPlanHandler, "PUT", "/admin/plans/team", `{"max_todo": 2}`)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"name": "team", "max_todo": 2}}`, w.Body.String())
+	requireTest.Equal(http.StatusBadRequest, serve(s.adminPlanHandler, "PUT", "/admin/plans/Team", `{"max_todo": 2}`).Code)
+	requireTest.Equal(http.StatusUnprocessableEntity, serve(s.adminPlanHandler, "PUT", "/admin/plans/team", `{"max_todo": -1}`).Code)
+
+	// users get the limit of their plan
+	requireTest.Equal(http.StatusNotFound, serve(s.adminUserHandler, "PUT", "/admin/users/2/plan", `{"plan": "gold"}`).Code)
+	requireTest.Equal(http.StatusNoContent, serve(s.adminUserHandler, "PUT", "/admin/users/2/plan", `{"plan": "team"}`).Code)
+	w = serve(s.adminUsersHandler, "GET", "/admin/users", "")
+	requireTest.Contains(w.Body.String(), `"username":"alice","max_todo":2,"role":"user","plan":"team"`)
+	requireTest.Equal(http.StatusOK, addTask())
+	requireTest.Equal(http.StatusOK, addTask())
+	requireTest.Equal(http.StatusTooManyRequests, addTask())
+
+	// and their own back once the plan is gone
+	requireTest.Equal(http.StatusNoContent, serve(s.adminPlanHandler, "DELETE", "/admin/plans/team", "").Code)
+	requireTest.Equal(http.StatusNotFound, serve(s.adminPlanHandler, "DELETE", "/admin/plans/team", "").Code)
+	requireTest.Equal(http.StatusNoContent, serve(s.adminUserHandler, "PUT", "/admin/users/2/plan", `{"plan": null}`).Code)
+	quota, err := m.GetQuota(context.Background(), usr.Id, time.Now())
+	requireTest.NoError(err)
+	requireTest.Equal(1, quota.MaxTodo)
+}
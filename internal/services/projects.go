@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// projectsHandler serves projects of the user at /projects
+func (s *ToDoService) projectsHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		projects, ok := s.store.(storages.ProjectStore)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodPost:
+			s.createProjectHandler(resp, req, projects)
+		case http.MethodGet:
+			s.listProjectsHandler(resp, req, projects)
+		default:
+			writeError(resp, errMethodNotAllowed)
+		}
+	}
+}
+
+// projectHandler serves a single project at /projects/{id} and its tasks at /projects/{id}/tasks
+func (s *ToDoService) projectHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		id, action, ok := parseItemPath("/projects/", req.URL.Path)
+		if !ok {
+			writeError(resp, errUnknownRoute)
+			return
+		}
+
+		projects, ok := s.store.(storages.ProjectStore)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+
+		switch {
+		case action == "" && req.Method == http.MethodGet:
+			s.getProjectHandler(resp, req, projects, id)
+		case action == "" && req.Method == http.MethodPut:
+			s.updateProjectHandler(resp, req, projects, id)
+		case action == "" && req.Method == http.MethodDelete:
+			s.deleteProjectHandler(resp, req, projects, id)
+		case action == "tasks" && req.Method == http.MethodGet:
+			s.listProjectTasksHandler(resp, req, id)
+		case action == "", action == "tasks":
+			writeError(resp, errMethodNotAllowed)
+		default:
+			writeError(resp, errUnknownRoute)
+		}
+	}
+}
+
+func (s *ToDoService) createProjectHandler(resp http.ResponseWriter, req *http.Request, projects storages.ProjectStore) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	project := &storages.Project{}
+	if !decodeBody(resp, req, project, maxJsonSize) {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	project.UsrId = userID
+
+	if err := projects.CreateProject(req.Context(), project); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(resp).Encode(newDataResp(project)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) listProjectsHandler(resp http.ResponseWriter, req *http.Request, projects storages.ProjectStore) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	list, err := projects.GetProjects(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(newDataResp(list)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) getProjectHandler(resp http.ResponseWriter, req *http.Request, projects storages.ProjectStore, id int) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	project, err := projects.GetProject(req.Context(), userID, id)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(newDataResp(project)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) updateProjectHandler(resp http.ResponseWriter, req *http.Request, projects storages.ProjectStore, id int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	project := &storages.Project{}
+	if !decodeBody(resp, req, project, maxJsonSize) {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	project.Id = id
+	project.UsrId = userID
+
+	if err := projects.UpdateProject(req.Context(), project); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(newDataResp(project)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) deleteProjectHandler(resp http.ResponseWriter, req *http.Request, projects storages.ProjectStore, id int) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := projects.DeleteProject(req.Context(), userID, id); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// listProjectTasksHandler lists tasks of the project created on created_date, the same filters as GET /tasks apply
+func (s *ToDoService) listProjectTasksHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	createdDate, err := s.createdDate(req.Context(), userID, req.FormValue("created_date"))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	tasks, err := s.findTasks(req, userID, createdDate, &id)
+	switch err {
+	case nil:
+	case errNotSupported:
+		writeErrResp(resp, http.StatusNotImplemented, err)
+		return
+	case errInvalidFilter:
+		writeError(resp, errInvalidFilter)
+		return
+	default:
+		writeError(resp, err)
+		return
+	}
+	writeTasks(resp, req, tasks)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCreateProject(t *testing.T) {
+	testCases := []struct {
+		err        error
+		statusCode int
+	}{
+		{nil, http.StatusCreated},
+		{storages.ErrInvalidProject, http.StatusBadRequest},
+		{storages.ErrConflict, http.StatusConflict},
+	}
+
+	for _, testCase := range testCases {
+		ctx := withPrincipal(context.Background(), principal{UserID: 1})
+		req := httptest.NewRequest("POST", "/projects", bytes.NewBufferString(`{"name": "work"}`)).WithContext(ctx)
+
+		db := new(storages.StoreMock)
+		db.On("CreateProject", req.Context(), &storages.Project{UsrId: 1, Name: "work"}).Return(testCase.err)
+
+		s := NewToDoService(testJWTKey, ":6000", db)
+		w := httptest.NewRecorder()
+
+		s.projectsHandler()(w, req)
+		db.AssertExpectations(t)
+
+		require.Equal(t, testCase.statusCode, w.Result().StatusCode)
+	}
+}
+
+func TestProjectHandler(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("GET", "/projects/2", nil).WithContext(ctx)
+	db.On("GetProject", req.Context(), 1, 2).Return((*storages.Project)(nil), storages.ErrNotFound)
+	w := httptest.NewRecorder()
+	s.projectHandler()(w, req)
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	req = httptest.NewRequest("DELETE", "/projects/2", nil).WithContext(ctx)
+	db.On("DeleteProject", req.Context(), 1, 2).Return(nil)
+	w = httptest.NewRecorder()
+	s.projectHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/projects/2/tasks", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.projectHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestListProjectTasks(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	req := httptest.NewRequest("GET", "/projects/2/tasks?created_date=2006-01-02", nil).WithContext(ctx)
+
+	createdAt, _ := time.Parse("2006-01-02", "2006-01-02")
+	projectId := 2
+
+	db := new(storages.StoreMock)
+	db.On("FindTasks", req.Context(), 1, createdAt, storages.TaskFilter{ProjectId: &projectId}).Return(testTasks, nil)
+
+	s := NewToDoService(testJWTKey, ":6000", db)
+	w := httptest.NewRecorder()
+
+	s.projectHandler()(w, req)
+	db.AssertExpectations(t)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: testTasks}, resp)
+}
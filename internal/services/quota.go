@@ -0,0 +1,213 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// userLimit is the daily-limit of a user, null when the user may add any number of tasks a day
+type userLimit struct {
+	MaxTodo *int `json:"max_todo"`
+}
+
+func newUserLimit(maxTodo int) userLimit {
+	if maxTodo == storages.UnlimitedTodo {
+		return userLimit{}
+	}
+	return userLimit{MaxTodo: &maxTodo}
+}
+
+func (l *userLimit) validate(v *validator) {
+	v.check(l.MaxTodo == nil || *l.MaxTodo >= 0, "max_todo", "can't be negative")
+}
+
+// userLimitHandler returns the daily-limit of the user at GET /users/me/limit
+func (s *ToDoService) userLimitHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.PasswordStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	usr, err := store.GetUser(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(newUserLimit(usr.MaxTodo))); err != nil {
+		log.Println(err)
+	}
+}
+
+// adminLimitHandler changes the daily-limit of a user at PATCH /users/{id}/limit, 0 or null lifts it.
+// Quotas are checked against the current limit so the change applies to the next task
+func (s *ToDoService) adminLimitHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	id, action, ok := parseItemPath("/users/", req.URL.Path)
+	if !ok || action != "limit" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodPatch {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	admin, ok := s.store.(storages.UserAdmin)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	params := &userLimit{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+	maxTodo := storages.UnlimitedTodo
+	if params.MaxTodo != nil {
+		maxTodo = *params.MaxTodo
+	}
+
+	usr, err := admin.UpdateUser(req.Context(), id, &storages.UserPatch{MaxTodo: &maxTodo})
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	actorId, _ := userIDFromCtx(req.Context())
+	s.audit(req, storages.AuditQuotaChanged, actorId, id, "max_todo="+strconv.Itoa(maxTodo))
+	if err := json.NewEncoder(resp).Encode(newDataResp(newUserLimit(usr.MaxTodo))); err != nil {
+		log.Println(err)
+	}
+}
+
+// quotaResetSuffix ends the path of the quota reset of a user, /admin/users/{id}/quota/reset
+const quotaResetSuffix = "/quota/reset"
+
+// adminQuotaResetHandler resets the quota of a user at POST /admin/users/{id}/quota/reset, the tasks they added so
+// far stop counting towards their limit. It's meant for tasks added by mistake, deleting them doesn't give the
+// quota back
+func (s *ToDoService) adminQuotaResetHandler(resp http.ResponseWriter, req *http.Request) {
+	id, action, ok := parseItemPath("/admin/users/", strings.TrimSuffix(req.URL.Path, quotaResetSuffix))
+	if !ok || action != "" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	resetter, ok := s.store.(storages.QuotaResetter)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	quota, err := resetter.ResetQuota(req.Context(), id, time.Now())
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	actorId, _ := userIDFromCtx(req.Context())
+	s.audit(req, storages.AuditQuotaChanged, actorId, id, "reset")
+	if err := json.NewEncoder(resp).Encode(newDataResp(newQuotaResult(quota))); err != nil {
+		log.Println(err)
+	}
+}
+
+// quotaResult is the quota of a user within the current window of the quota policy, the whole limit is
+// available again at reset_at. Limit and remaining are null for unlimited users, burst is how many tasks
+// they may still add over the limit when the storage allows bursts
+type quotaResult struct {
+	Limit     *int      `json:"limit"`
+	Used      int       `json:"used"`
+	Remaining *int      `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+	Burst     int       `json:"burst,omitempty"`
+}
+
+func newQuotaResult(quota *storages.Quota) quotaResult {
+	result := quotaResult{Limit: newUserLimit(quota.MaxTodo).MaxTodo, Used: quota.Used, ResetAt: quota.ResetAt, Burst: quota.Burst}
+	if result.Limit != nil {
+		remaining := quota.Remaining()
+		result.Remaining = &remaining
+	}
+	return result
+}
+
+// userQuotaHandler returns the current quota of the user at GET /users/me/quota
+func (s *ToDoService) userQuotaHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	quotas, ok := s.store.(storages.QuotaStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	quota, err := quotas.GetQuota(req.Context(), userID, time.Now())
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	setQuotaHeaders(resp.Header(), quota)
+	if err := json.NewEncoder(resp).Encode(newDataResp(newQuotaResult(quota))); err != nil {
+		log.Println(err)
+	}
+}
+
+// insertTask inserts task, storages which report quotas count them in the same transaction and
+// their quota is sent in the headers of resp, even if it has been exceeded
+func (s *ToDoService) insertTask(resp http.ResponseWriter, req *http.Request, task *storages.Task) error {
+	quotas, ok := s.store.(storages.QuotaStore)
+	if !ok {
+		err := s.store.InsertTask(req.Context(), task)
+		s.countQuota(err)
+		return err
+	}
+
+	quota, err := quotas.InsertTaskQuota(req.Context(), task)
+	if quota != nil {
+		setQuotaHeaders(resp.Header(), quota)
+	}
+	s.countQuota(err)
+	return err
+}
+
+// setQuotaHeaders sets X-Todo-Limit and X-Todo-Remaining, neither is set for unlimited users
+func setQuotaHeaders(header http.Header, quota *storages.Quota) {
+	if quota.MaxTodo == storages.UnlimitedTodo {
+		return
+	}
+	header.Set("X-Todo-Limit", strconv.Itoa(quota.MaxTodo))
+	header.Set("X-Todo-Remaining", strconv.Itoa(quota.Remaining()))
+}
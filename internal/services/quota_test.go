@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserLimit(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	getLimit := func() string {
+		req := httptest.NewRequest("GET", "/users/me/limit", nil)
+		w := httptest.NewRecorder()
+		s.userLimitHandler(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		requireTest.Equal(http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+	setLimit := func(body string, role storages.Role) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.adminHandler(s.adminLimitHandler)(w, newAdminRequest(t, s, "PATCH", "/users/2/limit", body, role))
+		return w
+	}
+	addTask := func() int {
+		req := httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"content": "milk"}`))
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w.Code
+	}
+	requireTest.JSONEq(`{"data": {"max_todo": 1}}`, getLimit())
+	requireTest.Equal(http.StatusOK, addTask())
+	requireTest.NotEqual(http.StatusOK, addTask())
+
+	requireTest.Equal(http.StatusForbidden, setLimit(`{"max_todo": 5}`, storages.RoleUser).Code)
+	requireTest.Equal(http.StatusUnprocessableEntity, setLimit(`{"max_todo": -1}`, storages.RoleAdmin).Code)
+
+	// the next task is checked against the new limit
+	w := setLimit(`{"max_todo": 2}`, storages.RoleAdmin)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"max_todo": 2}}`, w.Body.String())
+	requireTest.Equal(http.StatusOK, addTask())
+	requireTest.NotEqual(http.StatusOK, addTask())
+
+	w = setLimit(`{"max_todo": null}`, storages.RoleAdmin)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"max_todo": null}}`, w.Body.String())
+	requireTest.JSONEq(`{"data": {"max_todo": null}}`, getLimit())
+	for i := 0; i < 10; i++ {
+		requireTest.Equal(http.StatusOK, addTask())
+	}
+
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminLimitHandler)(w, newAdminRequest(t, s, "PATCH", "/users/9/limit", `{"max_todo": 0}`, storages.RoleAdmin))
+	requireTest.Equal(http.StatusNotFound, w.Code)
+}
+
+func TestUserQuota(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	addTask := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"content": "milk"}`))
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w
+	}
+
+	// concurrent inserts each see the count right after their own
+	var wg sync.WaitGroup
+	remaining := make(chan string, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := addTask()
+			requireTest.Equal("5", w.Header().Get("X-Todo-Limit"))
+			remaining <- w.Header().Get("X-Todo-Remaining")
+		}()
+	}
+	wg.Wait()
+	close(remaining)
+	seen := make([]string, 0, 5)
+	for r := range remaining {
+		seen = append(seen, r)
+	}
+	sort.Strings(seen)
+	requireTest.Equal([]string{"0", "1", "2", "3", "4"}, seen)
+
+	w := addTask()
+	requireTest.Equal(http.StatusTooManyRequests, w.Code)
+	requireTest.Equal("0", w.Header().Get("X-Todo-Remaining"))
+
+	req := httptest.NewRequest("GET", "/users/me/quota", nil)
+	w = httptest.NewRecorder()
+	s.userQuotaHandler(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+	requireTest.Equal(http.StatusOK, w.Code)
+	_, resetAt := storages.DayRange(time.Now())
+	requireTest.JSONEq(`{"data": {"limit": 5, "used": 5, "remaining": 0, "reset_at": "`+resetAt.Format(time.RFC3339)+`"}}`, w.Body.String())
+
+	unlimited := storages.UnlimitedTodo
+	_, err = m.UpdateUser(context.Background(), usr.Id, &storages.UserPatch{MaxTodo: &unlimited})
+	requireTest.NoError(err)
+	w = addTask()
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Empty(w.Header().Get("X-Todo-Limit"))
+}
+
+func TestAdminQuotaReset(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	addTask := func() int {
+		req := httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"content": "milk"}`))
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w.Code
+	}
+	reset := func(path string, role storages.Role) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.adminHandler(s.adminUserHandler)(w, newAdminRequest(t, s, "POST", path, "", role))
+		return w
+	}
+	requireTest.Equal(http.StatusOK, addTask())
+	requireTest.Equal(http.StatusTooManyRequests, addTask())
+
+	requireTest.Equal(http.StatusForbidden, reset("/admin/users/2/quota/reset", storages.RoleUser).Code)
+	requireTest.Equal(http.StatusNotFound, reset("/admin/users/9/quota/reset", storages.RoleAdmin).Code)
+	w := reset("/admin/users/2/quota/reset", storages.RoleAdmin)
+	requireTest.Equal(http.StatusOK, w.Code)
+	_, resetAt := storages.DayRange(time.Now())
+	requireTest.JSONEq(`{"data": {"limit": 1, "used": 0, "remaining": 1, "reset_at": "`+resetAt.Format(time.RFC3339)+`"}}`, w.Body.String())
+
+	// the tasks before the reset don't count anymore
+	requireTest.Equal(http.StatusOK, addTask())
+	requireTest.Equal(http.StatusTooManyRequests, addTask())
+
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminUserHandler)(w, newAdminRequest(t, s, "GET", "/admin/users/2/quota/reset", "", storages.RoleAdmin))
+	requireTest.Equal(http.StatusMethodNotAllowed, w.Code)
+}
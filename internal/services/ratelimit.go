@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/manabie-com/togo/internal/ratelimit"
+)
+
+var errRateLimited = errors.New("too many requests, retry later")
+
+// WithRateLimit limits the requests of every address by addrLimiter and those of every signed in user by
+// userLimiter, either may be nil to leave them unlimited
+func WithRateLimit(addrLimiter, userLimiter ratelimit.Limiter) Option {
+	return func(s *ToDoService) {
+		s.addrLimiter = addrLimiter
+		s.userLimiter = userLimiter
+	}
+}
+
+// SetRateLimit replaces the limiters of the requests served from now on, see WithRateLimit
+func (s *ToDoService) SetRateLimit(addrLimiter, userLimiter ratelimit.Limiter) {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+	s.addrLimiter, s.userLimiter = addrLimiter, userLimiter
+}
+
+func (s *ToDoService) limiters() (ratelimit.Limiter, ratelimit.Limiter) {
+	s.liveMu.RLock()
+	defer s.liveMu.RUnlock()
+	return s.addrLimiter, s.userLimiter
+}
+
+// rateLimitHandler takes a token of the user of a valid token or else of the address of the request before next
+// serves it. The bucket is told by the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers of
+// draft-ietf-httpapi-ratelimit-headers, requests which have to wait are answered 429 with Retry-After.
+// Requests are let through if the limiter fails
+func (s *ToDoService) rateLimitHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		limiter, key := s.rateLimitKey(req)
+		if limiter == nil || req.Method == http.MethodOptions {
+			next.ServeHTTP(resp, req)
+			return
+		}
+
+		result, err := limiter.Allow(req.Context(), key)
+		if err != nil {
+			log.Println("rate limit:", err)
+			next.ServeHTTP(resp, req)
+			return
+		}
+
+		header := resp.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		if !result.Allowed {
+			header.Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			writeError(resp, errRateLimited)
+			return
+		}
+		next.ServeHTTP(resp, req)
+	})
+}
+
+// rateLimitKey returns the limiter of req and the key of its bucket, nil if such requests are unlimited
+func (s *ToDoService) rateLimitKey(req *http.Request) (ratelimit.Limiter, string) {
+	addrLimiter, userLimiter := s.limiters()
+	if addrLimiter == nil && userLimiter == nil {
+		return nil, ""
+	}
+	if authed, err := s.validToken(req); err == nil {
+		if userLimiter == nil {
+			return nil, ""
+		}
+		id, _ := userIDFromCtx(authed.Context())
+		return userLimiter, ratelimit.UserKey(id)
+	}
+	if addrLimiter == nil {
+		return nil, ""
+	}
+	return addrLimiter, ratelimit.AddrKey(clientIP(req))
+}
+
+// seconds rounds d up to whole seconds
+func seconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
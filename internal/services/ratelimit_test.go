@@ -0,0 +1,57 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/ratelimit"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	requireTest := require.New(t)
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock), WithRateLimit(
+		ratelimit.NewMemoryLimiter(ratelimit.Rate{Limit: 1, Period: time.Minute}),
+		ratelimit.NewMemoryLimiter(ratelimit.Rate{Limit: 2, Period: time.Minute}),
+	))
+	do := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/.well-known/jwks.json", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal("1", w.Header().Get("RateLimit-Limit"))
+	requireTest.Equal("0", w.Header().Get("RateLimit-Remaining"))
+	requireTest.Equal("60", w.Header().Get("RateLimit-Reset"))
+	w = do("")
+	requireTest.Equal(http.StatusTooManyRequests, w.Code)
+	requireTest.Equal("60", w.Header().Get("Retry-After"))
+	requireTest.JSONEq(`{"error": {"code": "TOO_MANY_REQUESTS", "message": "too many requests, retry later"}}`, w.Body.String())
+
+	// signed in users have buckets of their own
+	token, err := s.createToken(&storages.User{Id: 1, MaxTodo: 5})
+	requireTest.NoError(err)
+	w = do(token)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal("2", w.Header().Get("RateLimit-Limit"))
+	requireTest.Equal("1", w.Header().Get("RateLimit-Remaining"))
+
+	// limits are replaced while the service serves, nil limiters lift them
+	s.SetRateLimit(ratelimit.NewMemoryLimiter(ratelimit.Rate{Limit: 3, Period: time.Minute}), nil)
+	w = do("")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal("3", w.Header().Get("RateLimit-Limit"))
+	s.SetRateLimit(nil, nil)
+	w = do("")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Empty(w.Header().Get("RateLimit-Limit"))
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// failer is a writer of responses which tells the request log of the internal error a response answers,
+// the request is then logged at error level along with it so that it's reported
+type failer interface {
+	fail(err error)
+}
+
+// panicError is a panic recovered while serving a request along with the stack of the goroutine which panicked
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// failed tells the request log of resp of err, err is logged by itself if resp has none
+func failed(resp http.ResponseWriter, err error) {
+	if f, ok := resp.(failer); ok {
+		f.fail(err)
+		return
+	}
+	log.Println(err)
+}
+
+// recoverHandler answers requests whose handler panicked 500 unless it had answered already, the panic and its
+// stack are logged with the request. http.ErrAbortHandler is let through as it's how handlers abort responses
+func recoverHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		w := &statusWriter{ResponseWriter: resp, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			failed(w, &panicError{value: p, stack: debug.Stack()})
+			if !w.wroteHeader {
+				writeError(w, errInternal)
+			}
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
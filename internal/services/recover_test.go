@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	requireTest := require.New(t)
+	core, logs := observer.New(zap.InfoLevel)
+	s := NewToDoService(testJWTKey, ":6000", nil, WithLogger(zap.New(core)))
+
+	handler := s.requestLogHandler(recoverHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		withRequestUser(req.Context(), 7)
+		panic("boom")
+	})))
+	req := httptest.NewRequest("GET", "/v1/tasks", nil)
+	req.Header.Set(requestIDHeader, "id")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	requireTest.Equal(http.StatusInternalServerError, w.Code)
+
+	// the request is logged at error level with the panic and its stack
+	entries := logs.TakeAll()
+	requireTest.Len(entries, 1)
+	requireTest.Equal(zapcore.ErrorLevel, entries[0].Level)
+	fields := entries[0].ContextMap()
+	requireTest.Equal("panic: boom", fields["error"])
+	requireTest.Equal("id", fields["request_id"])
+	requireTest.Equal(int64(7), fields["user_id"])
+	requireTest.Contains(fields["stack"], "TestRecoverPanics")
+
+	// so are internal errors of storages
+	handler = s.requestLogHandler(recoverHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		writeError(resp, errors.New("connection refused"))
+	})))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/v1/tasks", nil))
+	requireTest.Equal(http.StatusInternalServerError, w.Code)
+	entries = logs.TakeAll()
+	requireTest.Equal(zapcore.ErrorLevel, entries[0].Level)
+	requireTest.Equal("connection refused", entries[0].ContextMap()["error"])
+
+	// while errors answered to clients are not
+	handler = s.requestLogHandler(recoverHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		writeError(resp, errUnknownRoute)
+	})))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/tasks", nil))
+	requireTest.Equal(zapcore.InfoLevel, logs.TakeAll()[0].Level)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+)
+
+// maxScopedTokenTTL is the longest a scoped token may be valid, they can't be revoked
+const maxScopedTokenTTL = 30 * 24 * time.Hour
+
+var (
+	errInsufficientScope = errors.New("token is not allowed to be used for the request")
+	errInvalidScopes     = errors.New("scopes must be some of tasks:read, tasks:write and admin")
+)
+
+// tasksScope is the scope of requests to tasks, projects and templates: tasks:read to read them and
+// tasks:write to change them
+func tasksScope(req *http.Request) string {
+	if accessOf(req.Method) == accessRead {
+		return tokens.ScopeTasksRead
+	}
+	return tokens.ScopeTasksWrite
+}
+
+func adminScope(*http.Request) string {
+	return tokens.ScopeAdmin
+}
+
+// scopedTokenParams ask for a token limited to Scopes which expires after ExpiresIn seconds,
+// the TTL of access tokens by default
+type scopedTokenParams struct {
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int64    `json:"expires_in"`
+}
+
+type scopedToken struct {
+	AccessToken string   `json:"access_token"`
+	TokenType   string   `json:"token_type"`
+	ExpiresIn   int      `json:"expires_in"`
+	Scopes      []string `json:"scopes"`
+}
+
+// scopedTokenHandler issues a token of the user limited to scopes at POST /users/me/tokens, e.g. a read-only
+// one for an integration. Only admins get the admin scope
+func (s *ToDoService) scopedTokenHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	users, ok := s.store.(storages.PasswordStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	params := &scopedTokenParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+	ttl := time.Duration(params.ExpiresIn) * time.Second
+	if ttl == 0 {
+		ttl = s.tokens.TTL()
+	}
+	for _, scope := range params.Scopes {
+		if scope == tokens.ScopeAdmin && !isAdmin(req.Context()) {
+			writeErrResp(resp, http.StatusForbidden, errAdminOnly)
+			return
+		}
+	}
+
+	usr, err := users.GetUser(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	role := ""
+	if usr.Role == storages.RoleAdmin {
+		role = string(usr.Role)
+	}
+	token, err := s.tokens.IssueScoped(usr.Id, usr.MaxTodo, role, params.Scopes, ttl)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	data := scopedToken{AccessToken: token, TokenType: "Bearer", ExpiresIn: int(ttl / time.Second), Scopes: params.Scopes}
+	if err := json.NewEncoder(resp).Encode(newDataResp(data)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedTokens(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	token, err := s.createToken(usr)
+	requireTest.NoError(err)
+	newRequest := func(method, path, body, token string) *http.Request {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	mint := func(body string) (int, scopedToken) {
+		w := httptest.NewRecorder()
+		s.authHandler(s.scopedTokenHandler)(w, newRequest("POST", "/users/me/tokens", body, token))
+		scoped := &struct {
+			Data scopedToken `json:"data"`
+		}{}
+		_ = json.NewDecoder(w.Body).Decode(scoped)
+		return w.Result().StatusCode, scoped.Data
+	}
+
+	code, _ := mint(`{"scopes": []}`)
+	requireTest.Equal(http.StatusUnprocessableEntity, code)
+	code, _ = mint(`{"scopes": ["tasks:delete"]}`)
+	requireTest.Equal(http.StatusUnprocessableEntity, code)
+	code, _ = mint(`{"scopes": ["admin"]}`)
+	requireTest.Equal(http.StatusForbidden, code)
+
+	code, scoped := mint(`{"scopes": ["tasks:read"], "expires_in": 3600}`)
+	requireTest.Equal(http.StatusCreated, code)
+	requireTest.Equal(3600, scoped.ExpiresIn)
+
+	tasks := s.scopeHandler(tasksScope, s.tasksHandler())
+	w := httptest.NewRecorder()
+	tasks(w, newRequest("GET", "/tasks?created_date=2020-07-29", "", scoped.AccessToken))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	w = httptest.NewRecorder()
+	tasks(w, newRequest("POST", "/tasks", `{"content": "milk"}`, scoped.AccessToken))
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+	requireTest.JSONEq(`{"error": {"code": "INSUFFICIENT_SCOPE", "message": "token is not allowed to be used for the request"}}`, w.Body.String())
+
+	// scoped tokens can't manage the account, e.g. issue unscoped tokens
+	w = httptest.NewRecorder()
+	s.authHandler(s.scopedTokenHandler)(w, newRequest("POST", "/users/me/tokens", `{"scopes": ["tasks:write"]}`, scoped.AccessToken))
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+	w = httptest.NewRecorder()
+	s.adminHandler(s.adminUsersHandler)(w, newRequest("GET", "/admin/users", "", scoped.AccessToken))
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	tasks(w, newRequest("POST", "/tasks", `{"content": "milk"}`, token))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+}
@@ -2,100 +2,409 @@ package services
 
 import (
 	"context"
-	"github.com/dgrijalva/jwt-go"
-	"github.com/manabie-com/togo/internal/storages/postgres"
+	"crypto/tls"
+	"github.com/manabie-com/togo/internal/accesslog"
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/mailer"
+	"github.com/manabie-com/togo/internal/metrics"
+	"github.com/manabie-com/togo/internal/oidc"
+	"github.com/manabie-com/togo/internal/ratelimit"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/manabie-com/togo/internal/tokens"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
-const (
-	authSubKey string = "sub"
-	authExpKey        = "exp"
-)
-
 var (
-	errInternal         = errors.New("internal error")
-	authTokenIsNotValid = errors.New("auth token is not valid")
+	errInternal      = errors.New("internal error")
+	errNotSupported  = errors.New("not supported by the storage")
+	errInvalidFilter = errors.New("invalid filter")
 )
 
 // ToDoService implement HTTP server
 type ToDoService struct {
-	jwtKey string
-	pg     postgres.Database
+	// tokens issues tokens at /login and verifies them without the storage
+	tokens *tokens.Signer
+	store  storages.Store
+	// refreshTTL is how long refresh tokens issued at /auth/login and /auth/refresh are valid
+	refreshTTL time.Duration
+	// mailer delivers password reset tokens which are valid for resetTTL, resets are disabled while it's nil
+	mailer   mailer.Mailer
+	resetTTL time.Duration
+	// mails counts the reset mails being sent, they outlive their requests
+	mails sync.WaitGroup
+	// providers log users in at identity providers by name, states sign their logins
+	providers map[string]oidc.Provider
+	states    *oidc.StateCodec
+	// throttle slows down logins after failures and resets of passwords, neither is throttled while it's nil
+	throttle *throttle.Throttler
+
+	// liveMu guards the limiters and cors, they are changed by SetRateLimit and SetCORS while the service serves
+	liveMu      sync.RWMutex
+	addrLimiter ratelimit.Limiter
+	userLimiter ratelimit.Limiter
+	// cookies delivers tokens as cookies too and has requests authenticated by them checked for CSRF,
+	// clients only send tokens in the Authorization header while it's nil
+	cookies *CookieAuth
+	// auditLog records security events, they are not recorded while it's nil
+	auditLog storages.AuditStore
+	// cors tells which browser origins may call the API, any origin may without credentials by default
+	cors *CORS
+	// links signs share links which grant read-only access to tasks without login
+	links *tokens.LinkSigner
+	// metrics records requests and is served at /metrics, nothing is recorded while it's nil
+	metrics *metrics.Metrics
+	// logger logs requests, the global logger of zap is used while it's nil
+	logger *zap.Logger
+	// logLevel is the level of logger which admins change at /admin/log-level, it can't be changed while it's nil
+	logLevel *logging.Level
+	// accessLog writes a line for every request apart from logger, there's no access log while it's nil
+	accessLog *accesslog.Logger
+	// legacySunset is when the unversioned paths stop being served, it's not announced while it's zero
+	legacySunset time.Time
+	// idempotencyTTL is how long responses to requests with an Idempotency-Key are replayed to their retries
+	idempotencyTTL time.Duration
+	// stats keeps the stats of users answered at /users/me/stats for a while
+	stats statsCache
+	// events carries the changes of tasks to the streams of /tasks/stream
+	events *events.Bus
+
+	// blobs keeps contents of attachments, attachments are disabled while it's nil
+	blobs             blobs.Store
+	maxAttachmentSize int64
+
+	// grpcServer serves the gRPC API at grpcAddr, it's not served while grpcAddr is empty.
+	// grpcShared is set when grpcAddr is the address of server which serves both APIs then
+	grpcAddr   string
+	grpcTLS    *tls.Config
+	grpcServer *grpc.Server
+	grpcShared bool
+
+	// maxBodySize bounds request bodies, handlerTimeout bounds handlers of routes without routeTimeouts
+	maxBodySize    int64
+	handlerTimeout time.Duration
+	routeTimeouts  map[string]time.Duration
 
 	server    *http.Server
 	serverErr chan error
+	// debugServer serves profiles and runtime variables on an address of its own, it's not served while it's nil
+	debugServer *http.Server
+	// adminServer serves probes, metrics, profiles and the admin API on an address of its own, the API serves
+	// them but the profiles while it's nil
+	adminServer *http.Server
+}
+
+// Option configures optional features of ToDoService
+type Option func(s *ToDoService)
+
+// WithTokenSigner replaces the HS256 signer made of the key given to NewToDoService, e.g. by a RS256 one
+func WithTokenSigner(signer *tokens.Signer) Option {
+	return func(s *ToDoService) {
+		s.tokens = signer
+	}
+}
+
+// WithRefreshTTL changes how long refresh tokens are valid, tokens.DefaultRefreshTTL by default
+func WithRefreshTTL(ttl time.Duration) Option {
+	return func(s *ToDoService) {
+		s.refreshTTL = ttl
+	}
+}
+
+// WithMailer enables password resets whose tokens are delivered by m
+func WithMailer(m mailer.Mailer) Option {
+	return func(s *ToDoService) {
+		s.mailer = m
+	}
+}
+
+// WithResetTTL changes how long password reset tokens are valid, tokens.DefaultResetTTL by default
+func WithResetTTL(ttl time.Duration) Option {
+	return func(s *ToDoService) {
+		s.resetTTL = ttl
+	}
+}
+
+// WithIdentityProviders enables logins at providers by name, stateKey signs the states of logins
+// and must be shared by every instance of the service
+func WithIdentityProviders(providers map[string]oidc.Provider, stateKey []byte) Option {
+	return func(s *ToDoService) {
+		s.providers = providers
+		s.states = oidc.NewStateCodec(stateKey)
+	}
+}
+
+// WithLoginThrottle throttles /login, /auth/login, /signup and /password/reset by t
+func WithLoginThrottle(t *throttle.Throttler) Option {
+	return func(s *ToDoService) {
+		s.throttle = t
+	}
+}
+
+// WithCookieAuth delivers tokens issued at /auth/login and /auth/refresh as cookies which authenticate
+// requests without the Authorization header, requests changing anything must also send the CSRF token
+func WithCookieAuth(c CookieAuth) Option {
+	return func(s *ToDoService) {
+		s.cookies = &c
+	}
+}
+
+// WithCORS replaces the default CORS policy which allows any origin without credentials
+func WithCORS(c CORS) Option {
+	return func(s *ToDoService) {
+		s.cors = &c
+	}
+}
+
+// WithAuditLog records logins, password changes, token revocations, role and quota changes in log
+func WithAuditLog(log storages.AuditStore) Option {
+	return func(s *ToDoService) {
+		s.auditLog = log
+	}
+}
+
+// WithShareLinkKey replaces the key given to NewToDoService which signs share links
+func WithShareLinkKey(key []byte) Option {
+	return func(s *ToDoService) {
+		s.links = tokens.NewLinkSigner(key)
+	}
+}
+
+// WithAttachments enables task attachments whose contents are kept in store,
+// uploads larger than maxSize bytes are rejected
+func WithAttachments(store blobs.Store, maxSize int64) Option {
+	return func(s *ToDoService) {
+		s.blobs = store
+		s.maxAttachmentSize = maxSize
+	}
 }
 
-func NewToDoService(jwtKey string, addr string, pg postgres.Database) *ToDoService {
+// WithGRPC serves the gRPC API of api/togopb at addr along with the HTTP API, over TLS unless tlsConfig is nil.
+// When addr is the address of the HTTP API both are served at that port, the HTTP API over TLS too then
+func WithGRPC(addr string, tlsConfig *tls.Config) Option {
+	return func(s *ToDoService) {
+		s.grpcAddr = addr
+		s.grpcTLS = tlsConfig
+	}
+}
+
+func NewToDoService(jwtKey string, addr string, store storages.Store, opts ...Option) *ToDoService {
 	s := &ToDoService{
-		jwtKey: jwtKey,
-		pg:     pg,
+		tokens:         tokens.NewHS256([]byte(jwtKey), 0),
+		store:          store,
+		refreshTTL:     tokens.DefaultRefreshTTL,
+		resetTTL:       tokens.DefaultResetTTL,
+		idempotencyTTL: DefaultIdempotencyTTL,
+		maxBodySize:    DefaultMaxBodySize,
+		handlerTimeout: DefaultHandlerTimeout,
 		server: &http.Server{
-			Addr: addr,
+			Addr:              addr,
+			ReadHeaderTimeout: DefaultReadHeaderTimeout,
+			IdleTimeout:       DefaultIdleTimeout,
 		},
 		serverErr: make(chan error, 1),
+		cors:      &CORS{AllowedOrigins: []string{"*"}},
+		links:     tokens.NewLinkSigner([]byte(jwtKey)),
+		events:    events.NewBus(0),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/login", s.setHeaders(s.createTokenHandler))
-	mux.HandleFunc("/tasks", s.setHeaders(s.authHandler(s.tasksHandler())))
-	s.server.Handler = mux
+	s.server.Handler = s.corsHandler(s.versionedRoutes())
+	if s.adminServer != nil {
+		s.adminServer.Handler = s.adminRoutes()
+	}
+	s.server.RegisterOnShutdown(s.events.Close)
+	if s.grpcAddr != "" && s.grpcAddr == s.server.Addr {
+		s.shareGRPC()
+	}
 
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil {
-			s.serverErr <- err
+		if err := s.listenAndServe(); err != nil {
+			s.reportServerErr(err)
 		}
 	}()
+	if s.grpcAddr != "" && !s.grpcShared {
+		s.grpcServer = s.newGRPCServer(s.grpcTLS)
+		s.serveGRPC(s.grpcAddr)
+	}
+	if s.debugServer != nil {
+		s.serveDebug()
+	}
+	if s.adminServer != nil {
+		s.serveAdmin()
+	}
 
 	return s
 }
 
+// v1Routes returns the routes of version 1 of the HTTP API
+func (s *ToDoService) v1Routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", s.setHeaders(s.createTokenHandler))
+	mux.HandleFunc("/signup", s.setHeaders(s.signupHandler))
+	mux.HandleFunc("/.well-known/jwks.json", s.setHeaders(s.jwksHandler))
+	mux.HandleFunc("/auth/login", s.setHeaders(s.authLoginHandler))
+	mux.HandleFunc("/auth/refresh", s.setHeaders(s.refreshHandler))
+	mux.HandleFunc("/auth/logout", s.setHeaders(s.logoutHandler))
+	mux.HandleFunc("/auth/", s.setHeaders(s.providerHandler))
+	mux.HandleFunc("/users/me/password", s.setHeaders(s.authHandler(s.changePasswordHandler)))
+	mux.HandleFunc("/users/me/2fa", s.setHeaders(s.authHandler(s.twoFactorHandler)))
+	mux.HandleFunc("/users/me/2fa/confirm", s.setHeaders(s.authHandler(s.confirmTwoFactorHandler)))
+	mux.HandleFunc("/users/me/shares", s.setHeaders(s.authHandler(s.sharesHandler)))
+	mux.HandleFunc("/users/me/shares/", s.setHeaders(s.authHandler(s.shareHandler)))
+	mux.HandleFunc("/users/me/tokens", s.setHeaders(s.authHandler(s.scopedTokenHandler)))
+	mux.HandleFunc("/users/me/links", s.setHeaders(s.authHandler(s.linksHandler)))
+	mux.HandleFunc("/users/me/sessions", s.setHeaders(s.authHandler(s.sessionsHandler)))
+	mux.HandleFunc("/users/me/sessions/", s.setHeaders(s.authHandler(s.sessionHandler)))
+	mux.HandleFunc("/users/me/limit", s.setHeaders(s.authHandler(s.userLimitHandler)))
+	mux.HandleFunc("/users/me/quota", s.setHeaders(s.authHandler(s.userQuotaHandler)))
+	mux.HandleFunc("/users/me/timezone", s.setHeaders(s.authHandler(s.timezoneHandler)))
+	mux.HandleFunc("/users/me/usage", s.setHeaders(s.authHandler(s.userUsageHandler)))
+	mux.HandleFunc("/users/me/stats", s.setHeaders(s.authHandler(s.userStatsHandler)))
+	mux.HandleFunc("/password/reset", s.setHeaders(s.resetPasswordHandler))
+	mux.HandleFunc("/password/reset/confirm", s.setHeaders(s.confirmResetHandler))
+	// the admin API is served by the admin server once there's one
+	if s.adminServer == nil {
+		s.handleAdminRoutes(mux)
+	}
+	mux.HandleFunc("/tasks", s.setHeaders(s.scopeHandler(tasksScope, s.tasksHandler())))
+	mux.HandleFunc("/tasks:batch", s.setHeaders(s.scopeHandler(tasksScope, s.batchTasksHandler())))
+	mux.HandleFunc("/tasks:complete", s.setHeaders(s.scopeHandler(tasksScope, s.bulkTasksHandler(false))))
+	mux.HandleFunc("/tasks:delete", s.setHeaders(s.scopeHandler(tasksScope, s.bulkTasksHandler(true))))
+	mux.HandleFunc("/tasks/", s.setHeaders(s.scopeHandler(tasksScope, s.taskHandler())))
+	mux.HandleFunc("/projects", s.setHeaders(s.scopeHandler(tasksScope, s.projectsHandler())))
+	mux.HandleFunc("/projects/", s.setHeaders(s.scopeHandler(tasksScope, s.projectHandler())))
+	mux.HandleFunc("/links/", s.setHeaders(s.linkHandler))
+	mux.HandleFunc("/graphql", s.setHeaders(s.scopeHandler(graphqlScope, s.graphqlHandler())))
+	mux.HandleFunc("/openapi.json", s.setHeaders(s.openAPIHandler()))
+	mux.HandleFunc("/docs", s.docsHandler)
+	mux.HandleFunc("/templates", s.setHeaders(s.scopeHandler(tasksScope, s.templatesHandler())))
+	mux.HandleFunc("/templates/", s.setHeaders(s.scopeHandler(tasksScope, s.templateHandler())))
+	mux.HandleFunc("/", s.setHeaders(notFoundHandler))
+	return mux
+}
+
+// handleAdminRoutes adds the routes of the admin API of version 1 to mux
+func (s *ToDoService) handleAdminRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/users/", s.setHeaders(s.adminHandler(s.userActionHandler)))
+	mux.HandleFunc("/admin/audit", s.setHeaders(s.adminHandler(s.adminAuditHandler)))
+	mux.HandleFunc("/admin/users", s.setHeaders(s.adminHandler(s.adminUsersHandler)))
+	mux.HandleFunc("/admin/users/", s.setHeaders(s.adminHandler(s.adminUserHandler)))
+	mux.HandleFunc("/admin/plans", s.setHeaders(s.adminHandler(s.adminPlansHandler)))
+	mux.HandleFunc("/admin/plans/", s.setHeaders(s.adminHandler(s.adminPlanHandler)))
+	mux.HandleFunc("/admin/log-level", s.setHeaders(s.adminHandler(s.adminLogLevelHandler)))
+}
+
 func (s *ToDoService) HttpServerErr() <-chan error {
 	return s.serverErr
 }
 
+// Shutdown stops accepting requests and stops the servers once their requests are done or ctx is done,
+// connections left are closed then which cancels their requests and calls
 func (s *ToDoService) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	// reset mails asked for by the last requests are sent unless ctx is done first
+	defer s.waitMails(ctx)
+	if s.adminServer != nil {
+		// probes and metrics are answered until the API is done
+		defer func() {
+			if err := s.adminServer.Shutdown(ctx); err != nil {
+				_ = s.adminServer.Close()
+			}
+		}()
+	}
+	if s.debugServer != nil {
+		// profiles being taken are cut short
+		defer s.debugServer.Close()
+	}
+	if s.grpcShared {
+		// calls over h2c connections outlive the HTTP server which doesn't track them
+		err := s.shutdownHTTP(ctx)
+		s.grpcServer.Stop()
+		return err
+	}
+	if s.grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		defer func() {
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				s.grpcServer.Stop()
+			}
+		}()
+	}
+	return s.shutdownHTTP(ctx)
 }
 
-func (s *ToDoService) createToken(id int) (string, error) {
-	claims := jwt.MapClaims{
-		authSubKey: id,
-		authExpKey: time.Now().Add(time.Minute * 15).Unix(),
+func (s *ToDoService) waitMails(ctx context.Context) {
+	sent := make(chan struct{})
+	go func() {
+		s.mails.Wait()
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-ctx.Done():
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.jwtKey))
 }
 
-func (s *ToDoService) validToken(req *http.Request) (*http.Request, error) {
-	authToken := req.Header.Get("Authorization")
-
-	claims := make(jwt.MapClaims)
-	parsedToken, err := jwt.ParseWithClaims(authToken, claims, func(*jwt.Token) (interface{}, error) {
-		return []byte(s.jwtKey), nil
-	})
+func (s *ToDoService) shutdownHTTP(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
 	if err != nil {
-		return req, err
+		_ = s.server.Close()
 	}
+	return err
+}
 
-	if !parsedToken.Valid {
-		return req, authTokenIsNotValid
+func (s *ToDoService) createToken(usr *storages.User) (string, error) {
+	role := ""
+	if usr.Role == storages.RoleAdmin {
+		role = string(usr.Role)
 	}
+	return s.tokens.Issue(usr.Id, usr.MaxTodo, role)
+}
 
-	id, ok := claims[authSubKey].(float64)
-	if !ok {
-		return req, authTokenIsNotValid
+// validToken verifies the token of the Authorization header, with or without the Bearer scheme, or else
+// the one of the access cookie, and adds the user id and the role of its claims to the context of the request
+func (s *ToDoService) validToken(req *http.Request) (*http.Request, error) {
+	authToken := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if authToken == "" {
+		token, err := s.cookieToken(req, accessCookie)
+		if err != nil {
+			return req, err
+		}
+		authToken = token
 	}
 
-	req = req.WithContext(context.WithValue(req.Context(), authSubKey, int(id)))
-	return req, nil
+	claims, err := s.tokens.Verify(authToken)
+	if err != nil {
+		return req, err
+	}
+
+	p := principal{UserID: claims.UserId, Role: storages.Role(claims.Role), Scopes: claims.Scopes()}
+	return req.WithContext(withPrincipal(req.Context(), p)), nil
 }
 
 func userIDFromCtx(ctx context.Context) (int, bool) {
-	v := ctx.Value(authSubKey)
-	id, ok := v.(int)
-	return id, ok
+	p, ok := principalFromCtx(ctx)
+	return p.UserID, ok
+}
+
+// isAdmin reports whether the token of the request was given to an admin
+func isAdmin(ctx context.Context) bool {
+	p, _ := principalFromCtx(ctx)
+	return p.Role == storages.RoleAdmin
 }
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	// serve starts a service whose requests wait for release, it returns the status of a request in flight
+	serve := func() (*ToDoService, <-chan int) {
+		s := NewToDoService(testJWTKey, "127.0.0.1:0", m)
+		s.server.Handler = http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			started <- struct{}{}
+			select {
+			case <-release:
+				resp.WriteHeader(http.StatusNoContent)
+			case <-req.Context().Done():
+			}
+		})
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		requireTest.NoError(err)
+		go func() {
+			_ = s.server.Serve(lis)
+		}()
+
+		code := make(chan int, 1)
+		go func() {
+			resp, err := http.Get("http://" + lis.Addr().String())
+			if err != nil {
+				code <- 0
+				return
+			}
+			_ = resp.Body.Close()
+			code <- resp.StatusCode
+		}()
+		<-started
+		return s, code
+	}
+
+	// in-flight requests are drained
+	s, code := serve()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Shutdown(context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	requireTest.Equal(http.StatusNoContent, <-code)
+	requireTest.NoError(<-done)
+
+	// those left at the deadline are cancelled
+	release = make(chan struct{})
+	s, code = serve()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	requireTest.Equal(context.DeadlineExceeded, s.Shutdown(ctx))
+	requireTest.Equal(0, <-code)
+}
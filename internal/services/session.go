@@ -0,0 +1,94 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// maxUserAgentLen is how much of the User-Agent of a login is kept with its session
+const maxUserAgentLen = 256
+
+// userAgent returns the User-Agent of req, cut to maxUserAgentLen
+func userAgent(req *http.Request) string {
+	ua := req.UserAgent()
+	if len(ua) > maxUserAgentLen {
+		ua = ua[:maxUserAgentLen]
+	}
+	return ua
+}
+
+// sessionsHandler lists the sessions of the user at GET /users/me/sessions and logs the user out
+// everywhere at DELETE /users/me/sessions, access tokens already issued remain valid until they expire
+func (s *ToDoService) sessionsHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodDelete {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	sessioner, ok := s.store.(storages.SessionStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		if err := sessioner.RevokeSessions(req.Context(), userID); err != nil {
+			writeError(resp, err)
+			return
+		}
+		s.audit(req, storages.AuditSessionsRevoked, userID, userID, "")
+		resp.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	sessions, err := sessioner.ListSessions(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(sessions)); err != nil {
+		log.Println(err)
+	}
+}
+
+// sessionHandler revokes a session of the user at DELETE /users/me/sessions/{id}
+func (s *ToDoService) sessionHandler(resp http.ResponseWriter, req *http.Request) {
+	id, action, ok := parseItemPath("/users/me/sessions/", req.URL.Path)
+	if !ok || action != "" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodDelete {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	sessioner, ok := s.store.(storages.SessionStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	if err := sessioner.RevokeSession(req.Context(), userID, id); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.audit(req, storages.AuditSessionRevoked, userID, userID, "session="+strconv.Itoa(id))
+	resp.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	at := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	db.On("ListSessions", mock.Anything, 1).Return([]*storages.Session{
+		{Id: 3, CreateAt: at, LastUsedAt: at, ExpiresAt: at.Add(time.Hour), UserAgent: "curl/7.68.0", IP: "192.0.2.1"},
+	}, nil)
+	w := httptest.NewRecorder()
+	s.authHandler(s.sessionsHandler)(w, newAdminRequest(t, s, "GET", "/users/me/sessions", "", storages.RoleUser))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	requireTest.JSONEq(`{"data": [{
+		"id": 3, "create_at": "2020-09-01T10:00:00Z", "last_used_at": "2020-09-01T10:00:00Z",
+		"expires_at": "2020-09-01T11:00:00Z", "user_agent": "curl/7.68.0", "ip": "192.0.2.1"
+	}]}`, w.Body.String())
+
+	db.On("RevokeSession", mock.Anything, 1, 3).Return(nil)
+	db.On("RevokeSession", mock.Anything, 1, 4).Return(storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.authHandler(s.sessionHandler)(w, newAdminRequest(t, s, "DELETE", "/users/me/sessions/3", "", storages.RoleUser))
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+	w = httptest.NewRecorder()
+	s.authHandler(s.sessionHandler)(w, newAdminRequest(t, s, "DELETE", "/users/me/sessions/4", "", storages.RoleUser))
+	requireTest.Equal(http.StatusNotFound, w.Result().StatusCode)
+
+	db.On("RevokeSessions", mock.Anything, 1).Return(nil)
+	w = httptest.NewRecorder()
+	s.authHandler(s.sessionsHandler)(w, newAdminRequest(t, s, "DELETE", "/users/me/sessions", "", storages.RoleUser))
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
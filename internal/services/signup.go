@@ -0,0 +1,95 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/throttle"
+)
+
+var errUsernameTaken = errors.New("username is taken")
+
+// signupResult is the body of responses of /signup, passwords hashes are never returned
+type signupResult struct {
+	Id       int    `json:"id"`
+	Username string `json:"username"`
+	MaxTodo  int    `json:"max_todo"`
+}
+
+// signupParams are the credentials of a new user
+type signupParams struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// signupHandler registers the user of the posted credentials with the default daily-limit,
+// the password is hashed here so the storage never sees it. Taken usernames count as failed
+// logins of the address so that usernames can't be enumerated faster than passwords are guessed
+func (s *ToDoService) signupHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	creator, ok := s.store.(storages.UserCreator)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	params := &signupParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+
+	var keys []string
+	if host := clientIP(req); s.throttle != nil && host != "" {
+		keys = []string{throttle.AddrKey(host)}
+		wait, err := s.throttle.Wait(req.Context(), keys...)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		if wait > 0 {
+			writeThrottledResp(resp, wait)
+			return
+		}
+	}
+
+	usr := &storages.User{Username: params.Username, MaxTodo: storages.DefaultMaxTodo}
+	// the username is valid already, NormalizeUsername only trims it
+	_ = usr.NormalizeUsername()
+	pwdHash, err := password.Hash(params.Password)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	usr.PwdHash = pwdHash
+
+	switch err := creator.CreateUser(req.Context(), usr); err {
+	case nil:
+	case storages.ErrConflict:
+		if keys != nil {
+			s.throttle.Fail(req.Context(), keys...)
+		}
+		writeErrResp(resp, http.StatusConflict, errUsernameTaken)
+		return
+	default:
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	result := signupResult{Id: usr.Id, Username: usr.Username, MaxTodo: usr.MaxTodo}
+	if err := json.NewEncoder(resp).Encode(newDataResp(result)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignup(t *testing.T) {
+	requireTest := require.New(t)
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/signup", bytes.NewBufferString(`{"username": " alice ", "password": "s3cretpass"}`))
+	db.On("CreateUser", req.Context(), mock.MatchedBy(func(usr *storages.User) bool {
+		return usr.Username == "alice" && usr.MaxTodo == storages.DefaultMaxTodo && password.Compare(usr.PwdHash, "s3cretpass")
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*storages.User).Id = 2
+	})
+	w := httptest.NewRecorder()
+	s.signupHandler(w, req)
+	requireTest.Equal(http.StatusCreated, w.Result().StatusCode)
+	requireTest.JSONEq(`{"data": {"id": 2, "username": "alice", "max_todo": 5}}`, w.Body.String())
+
+	req = httptest.NewRequest("POST", "/signup", bytes.NewBufferString(`{"username": "bob", "password": "s3cretpass"}`))
+	db.On("CreateUser", req.Context(), mock.MatchedBy(func(usr *storages.User) bool {
+		return usr.Username == "bob"
+	})).Return(storages.ErrConflict)
+	w = httptest.NewRecorder()
+	s.signupHandler(w, req)
+	requireTest.Equal(http.StatusConflict, w.Result().StatusCode)
+
+	for _, body := range []string{
+		`{"username": "a", "password": "s3cretpass"}`,
+		`{"username": "alice smith", "password": "s3cretpass"}`,
+		`{"username": "alice", "password": "short1"}`,
+		`{"username": "alice", "password": "onlyletters"}`,
+	} {
+		req = httptest.NewRequest("POST", "/signup", bytes.NewBufferString(body))
+		w = httptest.NewRecorder()
+		s.signupHandler(w, req)
+		requireTest.Equal(http.StatusUnprocessableEntity, w.Result().StatusCode, body)
+	}
+
+	db.AssertExpectations(t)
+}
+
+func TestSignupThrottle(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	requireTest.NoError(m.CreateUser(context.Background(), &storages.User{Username: "alice", MaxTodo: 5}))
+	s := NewToDoService(testJWTKey, ":6000", m, WithLoginThrottle(throttle.NewThrottler(m, 2, time.Minute, 0)))
+
+	signup := func(username string) *httptest.ResponseRecorder {
+		body := `{"username": "` + username + `", "password": "s3cretpass"}`
+		w := httptest.NewRecorder()
+		s.signupHandler(w, httptest.NewRequest("POST", "/signup", bytes.NewBufferString(body)))
+		return w
+	}
+	for i := 0; i < 2; i++ {
+		requireTest.Equal(http.StatusConflict, signup("alice").Result().StatusCode)
+	}
+
+	// taken usernames slow down further signups and logins of the address
+	w := signup("bob")
+	requireTest.Equal(http.StatusTooManyRequests, w.Result().StatusCode)
+	requireTest.Equal("60", w.Result().Header.Get("Retry-After"))
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newLoginRequest("alice", "s3cretpass"))
+	requireTest.Equal(http.StatusTooManyRequests, w.Result().StatusCode)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// defaultStatsDays is how many days of stats are answered when days isn't given, maxStatsDays how many at most
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+
+	// statsTTL is how long the stats of a user are answered from the cache and by clients
+	statsTTL = 30 * time.Second
+)
+
+var errInvalidStatsDays = errors.New("days must be 1 to 366")
+
+// statsResult is how many tasks a user created and completed every day of the last days up to today, days without
+// any included. Deleted tasks are left out
+type statsResult struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Created   int        `json:"created"`
+	Completed int        `json:"completed"`
+	Days      []statsDay `json:"days"`
+}
+
+type statsDay struct {
+	Day       string `json:"day"`
+	Created   int    `json:"created"`
+	Completed int    `json:"completed"`
+}
+
+// statsCache keeps the stats answered to users for statsTTL, so that dashboards polling them don't query the
+// storage every time
+type statsCache struct {
+	mu        sync.Mutex
+	entries   map[statsKey]statsEntry
+	lastSweep time.Time
+}
+
+type statsKey struct {
+	usrId int
+	to    string
+	days  int
+}
+
+type statsEntry struct {
+	result  statsResult
+	expires time.Time
+}
+
+func (c *statsCache) get(key statsKey, now time.Time) (statsResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		return statsResult{}, false
+	}
+	return entry.result, true
+}
+
+// put keeps result, the entries which expired are forgotten once per statsTTL
+func (c *statsCache) put(key statsKey, result statsResult, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[statsKey]statsEntry)
+	}
+	if now.Sub(c.lastSweep) >= statsTTL {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = statsEntry{result: result, expires: now.Add(statsTTL)}
+}
+
+// userStatsHandler returns how many tasks the user created and completed a day at GET /users/me/stats?days=,
+// on the days of the timezone of the user
+func (s *ToDoService) userStatsHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+	store, ok := s.store.(storages.StatsStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	days := defaultStatsDays
+	if param := req.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > maxStatsDays {
+			writeError(resp, errInvalidStatsDays)
+			return
+		}
+	}
+	loc, err := s.userLocation(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	now := time.Now()
+	today := now.In(loc)
+	to := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, 1-days)
+
+	key := statsKey{usrId: userID, to: to.Format(usageLayout), days: days}
+	result, ok := s.stats.get(key, now)
+	if !ok {
+		stats, err := store.GetStats(req.Context(), userID, from, to, loc)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		result = newStatsResult(from, to, stats)
+		s.stats.put(key, result, now)
+	}
+
+	resp.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(statsTTL/time.Second)))
+	if err := json.NewEncoder(resp).Encode(newDataResp(result)); err != nil {
+		log.Println(err)
+	}
+}
+
+func newStatsResult(from, to time.Time, stats []*storages.DayStats) statsResult {
+	byDay := make(map[string]*storages.DayStats, len(stats))
+	for _, day := range stats {
+		byDay[day.Day.Format(usageLayout)] = day
+	}
+
+	result := statsResult{From: from.Format(usageLayout), To: to.Format(usageLayout), Days: make([]statsDay, 0)}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		d := statsDay{Day: day.Format(usageLayout)}
+		if stats, ok := byDay[d.Day]; ok {
+			d.Created, d.Completed = stats.Created, stats.Completed
+		}
+		result.Created += d.Created
+		result.Completed += d.Completed
+		result.Days = append(result.Days, d)
+	}
+	return result
+}
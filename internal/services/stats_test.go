@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	serve := func(handler http.HandlerFunc, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w
+	}
+	created := struct {
+		Data storages.Task `json:"data"`
+	}{}
+	for i := 0; i < 2; i++ {
+		w := serve(s.tasksHandler(), "POST", "/tasks", `{"content": "milk"}`)
+		requireTest.Equal(http.StatusOK, w.Code)
+		requireTest.NoError(json.Unmarshal(w.Body.Bytes(), &created))
+	}
+	requireTest.Equal(http.StatusNoContent, serve(s.taskHandler(), "PATCH", "/tasks/"+strconv.Itoa(created.Data.Id)+"/complete", "").Code)
+
+	// the last 30 days up to today, days without tasks included
+	w := serve(s.userStatsHandler, "GET", "/users/me/stats", "")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.Equal("private, max-age=30", w.Header().Get("Cache-Control"))
+	result := struct {
+		Data statsResult `json:"data"`
+	}{}
+	requireTest.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+	today := time.Now().UTC().Format(usageLayout)
+	requireTest.Equal(today, result.Data.To)
+	requireTest.Len(result.Data.Days, defaultStatsDays)
+	requireTest.Equal(statsDay{Day: today, Created: 2, Completed: 1}, result.Data.Days[defaultStatsDays-1])
+	requireTest.Equal(2, result.Data.Created)
+	requireTest.Equal(1, result.Data.Completed)
+
+	// stats are answered from the cache for a while
+	requireTest.Equal(http.StatusOK, serve(s.tasksHandler(), "POST", "/tasks", `{"content": "eggs"}`).Code)
+	requireTest.Equal(w.Body.String(), serve(s.userStatsHandler, "GET", "/users/me/stats", "").Body.String())
+	w = serve(s.userStatsHandler, "GET", "/users/me/stats?days=1", "")
+	requireTest.JSONEq(`{"data": {"from": "`+today+`", "to": "`+today+`", "created": 3, "completed": 1, "days": [
+		{"day": "`+today+`", "created": 3, "completed": 1}]}}`, w.Body.String())
+
+	for _, query := range []string{"?days=0", "?days=367", "?days=week"} {
+		requireTest.Equal(http.StatusBadRequest, serve(s.userStatsHandler, "GET", "/users/me/stats"+query, "").Code, query)
+	}
+}
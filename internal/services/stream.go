@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// streamHeartbeat is how often idle streams get a comment so that proxies keep them open
+const streamHeartbeat = 30 * time.Second
+
+// publishTask tells the streams of usrId about a change of its task, task is the task after the change
+// or nil when it isn't known
+func (s *ToDoService) publishTask(typ events.Type, usrId, taskId int, task *storages.Task) {
+	e := events.TaskEvent{Type: typ, UsrId: usrId, TaskId: taskId}
+	if task != nil {
+		copied := *task
+		e.Task = &copied
+	}
+	s.events.Publish(e)
+}
+
+// streamTasksHandler streams the changes of the tasks of the user as server-sent events named after their
+// type. The stream ends when the service shuts down or the user lags too far behind, clients then
+// fetch the tasks again and reconnect
+func (s *ToDoService) streamTasksHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	changes, cancel := s.events.Subscribe(userID)
+	defer cancel()
+
+	resp.Header().Set("Content-Type", "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(resp, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-changes:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if _, err := fmt.Fprintf(resp, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTasks(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+	token, err := s.createToken(usr)
+	requireTest.NoError(err)
+	srv := httptest.NewServer(s.server.Handler)
+	defer srv.Close()
+
+	do := func(method, path string, body io.Reader) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, body)
+		requireTest.NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		requireTest.NoError(err)
+		return resp
+	}
+	stream := do("GET", "/v1/tasks/stream", nil)
+	defer stream.Body.Close()
+	requireTest.Equal(http.StatusOK, stream.StatusCode)
+	requireTest.Equal("text/event-stream", stream.Header.Get("Content-Type"))
+
+	created := do("POST", "/v1/tasks", strings.NewReader(`{"content": "milk"}`))
+	requireTest.Equal(http.StatusOK, created.StatusCode)
+	_ = created.Body.Close()
+
+	lines := bufio.NewScanner(stream.Body)
+	requireTest.True(lines.Scan())
+	requireTest.Equal("event: created", lines.Text())
+	requireTest.True(lines.Scan())
+	e := events.TaskEvent{}
+	requireTest.NoError(json.Unmarshal([]byte(strings.TrimPrefix(lines.Text(), "data: ")), &e))
+	requireTest.Equal(usr.Id, e.UsrId)
+	requireTest.Equal("milk", e.Task.Content)
+
+	deleted := do("DELETE", "/v1/tasks/"+strconv.Itoa(e.TaskId), nil)
+	requireTest.Equal(http.StatusNoContent, deleted.StatusCode)
+	_ = deleted.Body.Close()
+	requireTest.True(lines.Scan())
+	requireTest.Empty(lines.Text())
+	requireTest.True(lines.Scan())
+	requireTest.Equal("event: deleted", lines.Text())
+	requireTest.True(lines.Scan())
+	requireTest.Contains(lines.Text(), `"task_id":`+strconv.Itoa(e.TaskId))
+	requireTest.True(lines.Scan())
+
+	// streams end once the service shuts down
+	s.events.Close()
+	requireTest.False(lines.Scan())
+	requireTest.NoError(lines.Err())
+}
@@ -1,22 +1,20 @@
 package services
 
 import (
-	"encoding/json"
-	"github.com/manabie-com/togo/internal/storages/postgres"
-	"io"
-	"log"
+	"context"
+	"html"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/markdown"
 	"github.com/manabie-com/togo/internal/storages"
 )
 
 func (s *ToDoService) setHeaders(next http.HandlerFunc) http.HandlerFunc {
 	return func(resp http.ResponseWriter, req *http.Request) {
-		resp.Header().Set("Access-Control-Allow-Origin", "*")
-		resp.Header().Set("Access-Control-Allow-Headers", "*")
-		resp.Header().Set("Access-Control-Allow-Methods", "*")
-
 		resp.Header().Set("Content-Type", "application/json")
 
 		if req.Method == http.MethodOptions {
@@ -30,39 +28,138 @@ func (s *ToDoService) setHeaders(next http.HandlerFunc) http.HandlerFunc {
 
 func (s *ToDoService) tasksHandler() http.HandlerFunc {
 	return func(resp http.ResponseWriter, req *http.Request) {
-		log.Println(req.Method, req.URL.Path)
 		switch req.Method {
 		case http.MethodPost:
-			s.addTaskHandler(resp, req)
+			s.idempotentHandler(s.addTaskHandler)(resp, req)
 		case http.MethodGet:
 			s.listTasksHandler(resp, req)
 		default:
-			resp.WriteHeader(http.StatusMethodNotAllowed)
+			writeError(resp, errMethodNotAllowed)
 		}
 	}
 }
 
 func (s *ToDoService) listTasksHandler(resp http.ResponseWriter, req *http.Request) {
-	id, _ := userIDFromCtx(req.Context())
+	id, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if overdue, _ := strconv.ParseBool(req.FormValue("overdue")); overdue {
+		s.listOverdueTasksHandler(resp, req, id)
+		return
+	}
+
+	createdDate, err := s.createdDate(req.Context(), id, req.FormValue("created_date"))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if format := listFormat(req); format != mimeJSON {
+		s.exportTasksHandler(resp, req, id, createdDate, format)
+		return
+	}
 
-	createdDate, err := time.Parse("2006-01-02", req.FormValue("created_date"))
+	tasks, err := s.findTasks(req, id, createdDate, nil)
 	if err != nil {
-		resp.WriteHeader(http.StatusBadRequest)
+		writeError(resp, err)
 		return
 	}
 
-	tasks, err := s.pg.GetTasks(req.Context(), id, createdDate)
+	renderContent(req, tasks...)
+	writeTasks(resp, req, tasks)
+}
+
+// exportTasksHandler lists tasks as rows of format, storages which can stream tasks pass them on as they're read
+func (s *ToDoService) exportTasksHandler(resp http.ResponseWriter, req *http.Request, usrId int, createdDate time.Time, format string) {
+	filter, filtered, err := taskFilter(req, nil)
 	if err != nil {
-		resp.WriteHeader(http.StatusInternalServerError)
-		if err = json.NewEncoder(resp).Encode(newErrResp(errInternal.Error())); err != nil {
-			log.Println(err)
+		writeError(resp, err)
+		return
+	}
+
+	resp.Header().Set("Vary", "Accept")
+	streamTasks(resp, format, func(fn func(*storages.Task) error) error {
+		render := func(task *storages.Task) error {
+			renderContent(req, task)
+			return fn(task)
+		}
+		if streamer, ok := s.store.(storages.TaskStreamer); ok {
+			return streamer.StreamTasks(req.Context(), usrId, createdDate, filter, render)
+		}
+		tasks, err := s.filterTasks(req.Context(), usrId, createdDate, filter, filtered)
+		if err != nil {
+			return err
 		}
+		return eachOf(tasks)(render)
+	})
+}
+
+// listOverdueTasksHandler lists tasks which are past due and not completed whenever they were created
+func (s *ToDoService) listOverdueTasksHandler(resp http.ResponseWriter, req *http.Request, usrId int) {
+	finder, ok := s.store.(storages.TaskFinder)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
 		return
 	}
 
-	if err = json.NewEncoder(resp).Encode(newDataResp(tasks)); err != nil {
-		log.Println(err)
+	tasks, err := finder.FindOverdueTasks(req.Context(), usrId, time.Now())
+	if err != nil {
+		writeError(resp, err)
+		return
 	}
+	renderContent(req, tasks...)
+	writeTasks(resp, req, tasks)
+}
+
+// findTasks returns tasks filtered by query params and of the project if it's given,
+// storages which can't filter are only asked for tasks when there is no filter
+func (s *ToDoService) findTasks(req *http.Request, usrId int, createdDate time.Time, projectId *int) ([]*storages.Task, error) {
+	filter, filtered, err := taskFilter(req, projectId)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterTasks(req.Context(), usrId, createdDate, filter, filtered)
+}
+
+// taskFilter parses the filter of query params, filtered is false if there's none
+func taskFilter(req *http.Request, projectId *int) (filter storages.TaskFilter, filtered bool, err error) {
+	filter = storages.TaskFilter{ProjectId: projectId}
+	filtered = projectId != nil
+	if v := req.FormValue("completed"); v != "" {
+		completed, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, false, errInvalidFilter
+		}
+		filter.Completed = &completed
+		filtered = true
+	}
+	if v := storages.TaskSort(req.FormValue("sort_by")); v != "" {
+		if !v.Valid() {
+			return filter, false, errInvalidFilter
+		}
+		filter.SortBy = v
+		filtered = true
+	}
+	if tags := req.Form["tag"]; len(tags) > 0 {
+		filter.Tags = tags
+		filtered = true
+	}
+	return filter, filtered, nil
+}
+
+// filterTasks returns tasks of filter, storages which can't filter are only asked for tasks unless filtered
+func (s *ToDoService) filterTasks(ctx context.Context, usrId int, createdDate time.Time, filter storages.TaskFilter, filtered bool) ([]*storages.Task, error) {
+	if !filtered {
+		return s.store.GetTasks(ctx, usrId, createdDate)
+	}
+
+	finder, ok := s.store.(storages.TaskFinder)
+	if !ok {
+		return nil, errNotSupported
+	}
+	return finder.FindTasks(ctx, usrId, createdDate, filter)
 }
 
 func (s *ToDoService) addTaskHandler(resp http.ResponseWriter, req *http.Request) {
@@ -71,35 +168,402 @@ func (s *ToDoService) addTaskHandler(resp http.ResponseWriter, req *http.Request
 	}()
 
 	task := &storages.Task{}
-	err := json.NewDecoder(io.LimitReader(req.Body, maxJsonSize)).Decode(task)
-	if err != nil {
-		resp.WriteHeader(http.StatusInternalServerError)
+	if !decodeBody(resp, req, task, maxJsonSize) {
 		return
 	}
 
-	userID, ok := userIDFromCtx(req.Context())
+	userID, ok := s.authorize(resp, req)
 	if !ok {
-		resp.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	task.UsrId = userID
 
-	switch err := s.pg.InsertTask(req.Context(), task); err {
-	case nil:
-		if err := json.NewEncoder(resp).Encode(newDataResp(task)); err != nil {
-			log.Println(err)
+	if err := s.insertTask(resp, req, task); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Created, task.UsrId, task.Id, task)
+	s.auditTask(req.Context(), storages.TaskCreated, task.UsrId, task.Id, nil)
+	renderContent(req, task)
+	writeTask(resp, req, task)
+}
+
+// taskHandler serves a single task at /tasks/{id} and its actions at /tasks/{id}/{action}
+func (s *ToDoService) taskHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/tasks/trash" {
+			s.trashHandler(resp, req)
+			return
+		}
+		if req.URL.Path == "/tasks/stream" {
+			s.streamTasksHandler(resp, req)
+			return
 		}
-	case postgres.ErrUserMaxTodoReached:
-		resp.WriteHeader(http.StatusTooManyRequests)
-		if err := json.NewEncoder(resp).Encode(newErrResp(err.Error())); err != nil {
-			log.Println(err)
+		id, action, ok := parseItemPath("/tasks/", req.URL.Path)
+		if !ok {
+			if taskId, collection, itemId, action, ok := parseTaskSubPath(req.URL.Path); ok {
+				s.taskSubHandler(resp, req, taskId, collection, itemId, action)
+				return
+			}
+			writeError(resp, errUnknownRoute)
+			return
+		}
+
+		switch {
+		case action == "" && req.Method == http.MethodGet:
+			s.getTaskHandler(resp, req, id)
+		case action == "" && req.Method == http.MethodPut:
+			s.updateTaskHandler(resp, req, id)
+		case action == "" && req.Method == http.MethodDelete:
+			s.deleteTaskHandler(resp, req, id)
+		case action == "complete" && req.Method == http.MethodPatch:
+			s.setTaskStatusHandler(resp, req, id, storages.TaskStatusDone)
+		case action == "uncomplete" && req.Method == http.MethodPatch:
+			s.setTaskStatusHandler(resp, req, id, storages.TaskStatusTodo)
+		case action == "tags" && (req.Method == http.MethodPost || req.Method == http.MethodDelete):
+			s.taskTagsHandler(resp, req, id)
+		case action == "position" && req.Method == http.MethodPatch:
+			s.moveTaskHandler(resp, req, id)
+		case action == "reminder" && req.Method == http.MethodPatch:
+			s.remindTaskHandler(resp, req, id)
+		case action == "restore" && req.Method == http.MethodPost:
+			s.restoreTaskHandler(resp, req, id)
+		case action == "history" && req.Method == http.MethodGet:
+			s.taskHistoryHandler(resp, req, id)
+		case action == "items":
+			s.checklistHandler(resp, req, id)
+		case action == "comments":
+			s.commentsHandler(resp, req, id)
+		case action == "attachments":
+			s.attachmentsHandler(resp, req, id)
+		case action == "", action == "complete", action == "uncomplete", action == "tags", action == "position", action == "reminder",
+			action == "restore", action == "history":
+			writeError(resp, errMethodNotAllowed)
+		default:
+			writeError(resp, errUnknownRoute)
 		}
+	}
+}
+
+// taskSubHandler serves single items of collections of a task like /tasks/{id}/comments/{commentId}
+func (s *ToDoService) taskSubHandler(resp http.ResponseWriter, req *http.Request, taskId int, collection string, itemId int, action string) {
+	switch {
+	case collection == "items" && action != "":
+		s.checklistItemHandler(resp, req, taskId, itemId, action)
+	case collection == "comments" && action == "" && req.Method == http.MethodDelete:
+		s.deleteCommentHandler(resp, req, taskId, itemId)
+	case collection == "comments" && action == "":
+		writeError(resp, errMethodNotAllowed)
+	case collection == "attachments" && action == "":
+		s.attachmentHandler(resp, req, taskId, itemId)
 	default:
-		resp.WriteHeader(http.StatusInternalServerError)
-		if err = json.NewEncoder(resp).Encode(newErrResp(errInternal.Error())); err != nil {
-			log.Println(err)
+		writeError(resp, errUnknownRoute)
+	}
+}
+
+// parseTaskSubPath splits /tasks/{id}/{collection}/{itemId}[/{action}]
+func parseTaskSubPath(path string) (int, string, int, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, "/tasks/"), "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return 0, "", 0, "", false
+	}
+
+	taskId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", 0, "", false
+	}
+	itemId, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, "", 0, "", false
+	}
+	if len(parts) == 4 {
+		return taskId, parts[1], itemId, parts[3], true
+	}
+	return taskId, parts[1], itemId, "", true
+}
+
+// parseItemPath splits {prefix}{id}[/{action}] like /tasks/{id}/complete
+func parseItemPath(prefix, path string) (int, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, prefix), "/")
+	if len(parts) > 2 {
+		return 0, "", false
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", false
+	}
+	if len(parts) == 2 {
+		return id, parts[1], true
+	}
+	return id, "", true
+}
+
+// updateTaskHandler saves the task given in body, its version must be the stored one
+func (s *ToDoService) updateTaskHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	updater, ok := s.store.(storages.TaskUpdater)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	task := &storages.Task{}
+	if !decodeBody(resp, req, task, maxJsonSize) {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	task.Id = id
+	task.UsrId = userID
+	version, ifMatch, err := ifMatchVersion(req)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if ifMatch {
+		task.Version = version
+	}
+
+	before := s.snapshotTask(req.Context(), userID, id)
+	err = updater.UpdateTask(req.Context(), task)
+	if err == storages.ErrConflict && ifMatch {
+		err = errPreconditionFailed
+	}
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Updated, userID, id, task)
+	s.auditTask(req.Context(), storages.TaskUpdated, userID, id, before)
+	renderContent(req, task)
+	writeTask(resp, req, task)
+}
+
+// getTaskHandler answers the task, clients may revalidate it by If-None-Match or If-Modified-Since
+func (s *ToDoService) getTaskHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	getter, ok := s.store.(storages.TaskGetter)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	task, err := getter.GetTask(req.Context(), userID, id)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	renderContent(req, task)
+	writeTask(resp, req, task)
+}
+
+// deleteTaskHandler moves the task to the trash, it must belong to the owner s.authorize returns: the
+// authenticated user, or with the owner query param a user who shared their tasks with write access or any user
+// for admins
+func (s *ToDoService) deleteTaskHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	archiver, ok := s.store.(storages.TaskArchiver)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	before := s.snapshotTask(req.Context(), userID, id)
+	if err := archiver.DeleteTask(req.Context(), userID, id); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Deleted, userID, id, nil)
+	s.auditTask(req.Context(), storages.TaskDeleted, userID, id, before)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// setTaskStatusHandler marks the task done or not done, completed_at follows the status
+func (s *ToDoService) setTaskStatusHandler(resp http.ResponseWriter, req *http.Request, id int, status storages.TaskStatus) {
+	updater, ok := s.store.(storages.TaskMetadataUpdater)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	if !s.checkIfMatch(resp, req, userID, id) {
+		return
+	}
+
+	before := s.snapshotTask(req.Context(), userID, id)
+	if err := updater.SetTaskStatus(req.Context(), userID, id, status); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Updated, userID, id, nil)
+	action := storages.TaskUpdated
+	if status == storages.TaskStatusDone {
+		action = storages.TaskCompleted
+	}
+	s.auditTask(req.Context(), action, userID, id, before)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// taskTags is the body of POST /tasks/{id}/tags
+type taskTags struct {
+	Tags []string `json:"tags"`
+}
+
+// taskTagsHandler tags the task with tags given in body on POST
+// and untags it from tags given by tag query params on DELETE
+func (s *ToDoService) taskTagsHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	tagger, ok := s.store.(storages.TaskTagger)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	var err error
+	before := s.snapshotTask(req.Context(), userID, id)
+	if req.Method == http.MethodPost {
+		body := &taskTags{}
+		if !decodeBody(resp, req, body, maxJsonSize) {
+			return
 		}
+		err = tagger.AddTaskTags(req.Context(), userID, id, body.Tags)
+	} else {
+		err = tagger.RemoveTaskTags(req.Context(), userID, id, req.URL.Query()["tag"])
+	}
+	if err != nil {
+		writeError(resp, err)
 		return
 	}
+	s.publishTask(events.Updated, userID, id, nil)
+	s.auditTask(req.Context(), storages.TaskUpdated, userID, id, before)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// taskPosition is the body of PATCH /tasks/{id}/position, it holds the new neighbors of the task
+type taskPosition struct {
+	AfterId  *int `json:"after_id"`
+	BeforeId *int `json:"before_id"`
+}
+
+// moveTaskHandler places the task between the neighbors given in body
+func (s *ToDoService) moveTaskHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	positioner, ok := s.store.(storages.TaskPositioner)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	body := &taskPosition{}
+	if !decodeBody(resp, req, body, maxJsonSize) {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	if !s.checkIfMatch(resp, req, userID, id) {
+		return
+	}
+
+	before := s.snapshotTask(req.Context(), userID, id)
+	if err := positioner.MoveTask(req.Context(), userID, id, body.AfterId, body.BeforeId); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Updated, userID, id, nil)
+	s.auditTask(req.Context(), storages.TaskUpdated, userID, id, before)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// taskReminder is the body of PATCH /tasks/{id}/reminder, null clears the reminder
+type taskReminder struct {
+	RemindAt *time.Time `json:"remind_at"`
+}
+
+// remindTaskHandler sets when the user is reminded of the task
+func (s *ToDoService) remindTaskHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	reminder, ok := s.store.(storages.TaskReminder)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	body := &taskReminder{}
+	if !decodeBody(resp, req, body, maxJsonSize) {
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+	if !s.checkIfMatch(resp, req, userID, id) {
+		return
+	}
+
+	before := s.snapshotTask(req.Context(), userID, id)
+	if err := reminder.SetTaskRemindAt(req.Context(), userID, id, body.RemindAt); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Updated, userID, id, nil)
+	s.auditTask(req.Context(), storages.TaskUpdated, userID, id, before)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// renderContent sets ContentHTML of tasks when the request asks for it by render=html,
+// markdown is rendered and plain contents are escaped so both are safe to embed
+func renderContent(req *http.Request, tasks ...*storages.Task) {
+	if req.URL.Query().Get("render") != "html" {
+		return
+	}
+	for _, task := range tasks {
+		if task.ContentFormat == storages.ContentFormatMarkdown {
+			task.ContentHTML = markdown.Render(task.Content)
+		} else {
+			task.ContentHTML = html.EscapeString(task.Content)
+		}
+	}
 }
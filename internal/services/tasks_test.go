@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/manabie-com/togo/internal/storages"
-	"github.com/manabie-com/togo/internal/storages/postgres"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
@@ -42,7 +42,7 @@ func TestListTaskErr(t *testing.T) {
 	requireTest := require.New(t)
 	requireTest.Equal(http.StatusInternalServerError, resp.StatusCode)
 
-	apiErrResp := &ApiErrResp{Error: errInternal.Error()}
+	apiErrResp := &ApiErrResp{Error: ApiError{Code: codeInternal, Message: errInternal.Error()}}
 	assertErrResp(t, apiErrResp, resp)
 }
 
@@ -66,19 +66,19 @@ func TestAddTaskInternalErr(t *testing.T) {
 	requireTest := require.New(t)
 	requireTest.Equal(http.StatusInternalServerError, resp.StatusCode)
 
-	apiErrResp := &ApiErrResp{Error: errInternal.Error()}
+	apiErrResp := &ApiErrResp{Error: ApiError{Code: codeInternal, Message: errInternal.Error()}}
 	assertErrResp(t, apiErrResp, resp)
 }
 
 func TestAddTaskQuotaExceed(t *testing.T) {
 	testTask := &storages.Task{Content: "test content", UsrId: 1}
-	resp := mockAddTasks(t, testTask, 1, postgres.ErrUserMaxTodoReached)
+	resp := mockAddTasks(t, testTask, 1, storages.ErrQuotaExceeded)
 	defer resp.Body.Close()
 
 	requireTest := require.New(t)
 	requireTest.Equal(http.StatusTooManyRequests, resp.StatusCode)
 
-	apiErrResp := &ApiErrResp{Error: postgres.ErrUserMaxTodoReached.Error()}
+	apiErrResp := &ApiErrResp{Error: ApiError{Code: codeQuotaExceeded, Message: storages.ErrQuotaExceeded.Error()}}
 	assertErrResp(t, apiErrResp, resp)
 }
 
@@ -91,7 +91,7 @@ func newListTasksRequest(userID int, createdDate string) *http.Request {
 }
 
 func mockListTasks(t *testing.T, usrId int, createDate string, taskData []*storages.Task, taskErr error) *http.Response {
-	ctx := context.WithValue(context.Background(), authSubKey, usrId)
+	ctx := withPrincipal(context.Background(), principal{UserID: usrId})
 	req := newListTasksRequest(usrId, createDate).WithContext(ctx)
 
 	createdAt, err := time.Parse("2006-01-02", createDate)
@@ -100,7 +100,7 @@ func mockListTasks(t *testing.T, usrId int, createDate string, taskData []*stora
 		return nil
 	}
 
-	db := new(postgres.DatabaseMock)
+	db := new(storages.StoreMock)
 	db.On("GetTasks", req.Context(), usrId, createdAt).Return(taskData, taskErr)
 
 	s := NewToDoService(testJWTKey, ":6000", db)
@@ -113,10 +113,10 @@ func mockListTasks(t *testing.T, usrId int, createDate string, taskData []*stora
 }
 
 func mockAddTasks(t *testing.T, taskData *storages.Task, usrId int, err error) *http.Response {
-	ctx := context.WithValue(context.Background(), authSubKey, usrId)
+	ctx := withPrincipal(context.Background(), principal{UserID: usrId})
 	req := newAddTaskRequest(t, taskData.Content).WithContext(ctx)
 
-	db := new(postgres.DatabaseMock)
+	db := new(storages.StoreMock)
 	db.On("InsertTask", req.Context(), taskData).Return(err)
 
 	s := NewToDoService(testJWTKey, ":6000", db)
@@ -141,3 +141,344 @@ func newAddTaskRequest(t *testing.T, content string) *http.Request {
 	req.Header.Add("Content-Type", "application/json")
 	return req
 }
+
+func TestUpdateTask(t *testing.T) {
+	testCases := []struct {
+		err        error
+		statusCode int
+	}{
+		{nil, http.StatusOK},
+		{storages.ErrConflict, http.StatusConflict},
+		{storages.ErrNotFound, http.StatusNotFound},
+		{storages.ErrInvalidTask, http.StatusBadRequest},
+		{errInternal, http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		testTask := &storages.Task{Id: 3, UsrId: 1, Content: "edited", Version: 2}
+		resp := mockUpdateTask(t, testTask, testCase.err)
+
+		requireTest := require.New(t)
+		requireTest.Equal(testCase.statusCode, resp.StatusCode)
+		if testCase.err == nil {
+			assertDataResp(t, &ApiDataResp{Data: testTask}, resp)
+		}
+		resp.Body.Close()
+	}
+}
+
+func mockUpdateTask(t *testing.T, taskData *storages.Task, err error) *http.Response {
+	payload, _ := json.Marshal(taskData)
+	ctx := withPrincipal(context.Background(), principal{UserID: taskData.UsrId})
+	req := httptest.NewRequest("PUT", "localhost:5050/tasks/3", bytes.NewBuffer(payload)).WithContext(ctx)
+
+	db := new(storages.StoreMock)
+	db.On("UpdateTask", req.Context(), taskData).Return(err)
+
+	s := NewToDoService(testJWTKey, ":6000", db)
+	w := httptest.NewRecorder()
+
+	s.updateTaskHandler(w, req, taskData.Id)
+	db.AssertExpectations(t)
+
+	return w.Result()
+}
+
+func TestDeleteTask(t *testing.T) {
+	testCases := []struct {
+		err        error
+		statusCode int
+	}{
+		{nil, http.StatusNoContent},
+		{storages.ErrNotFound, http.StatusNotFound},
+		{storages.ErrForbidden, http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		ctx := withPrincipal(context.Background(), principal{UserID: 1})
+		req := httptest.NewRequest("DELETE", "localhost:5050/tasks/3", nil).WithContext(ctx)
+
+		db := new(storages.StoreMock)
+		db.On("DeleteTask", req.Context(), 1, 3).Return(testCase.err)
+
+		s := NewToDoService(testJWTKey, ":6000", db)
+		w := httptest.NewRecorder()
+
+		s.deleteTaskHandler(w, req, 3)
+		db.AssertExpectations(t)
+
+		require.Equal(t, testCase.statusCode, w.Result().StatusCode)
+	}
+}
+
+func TestParseItemPath(t *testing.T) {
+	requireTest := require.New(t)
+
+	id, action, ok := parseItemPath("/tasks/", "/tasks/3")
+	requireTest.True(ok)
+	requireTest.Equal(3, id)
+	requireTest.Empty(action)
+
+	id, action, ok = parseItemPath("/tasks/", "/tasks/3/complete")
+	requireTest.True(ok)
+	requireTest.Equal(3, id)
+	requireTest.Equal("complete", action)
+
+	for _, path := range []string{"/tasks/", "/tasks/abc", "/tasks/3/complete/now"} {
+		_, _, ok = parseItemPath("/tasks/", path)
+		requireTest.False(ok, path)
+	}
+}
+
+func TestParseTaskSubPath(t *testing.T) {
+	requireTest := require.New(t)
+
+	taskId, collection, itemId, action, ok := parseTaskSubPath("/tasks/3/items/2/complete")
+	requireTest.True(ok)
+	requireTest.Equal(3, taskId)
+	requireTest.Equal("items", collection)
+	requireTest.Equal(2, itemId)
+	requireTest.Equal("complete", action)
+
+	taskId, collection, itemId, action, ok = parseTaskSubPath("/tasks/3/comments/7")
+	requireTest.True(ok)
+	requireTest.Equal(3, taskId)
+	requireTest.Equal("comments", collection)
+	requireTest.Equal(7, itemId)
+	requireTest.Empty(action)
+
+	for _, path := range []string{"/tasks/3/items", "/tasks/3/items/abc/complete", "/tasks/abc/items/2", "/tasks/3/items/2/complete/now"} {
+		_, _, _, _, ok = parseTaskSubPath(path)
+		requireTest.False(ok, path)
+	}
+}
+
+func TestCompleteUncompleteTask(t *testing.T) {
+	testCases := []struct {
+		path   string
+		status storages.TaskStatus
+	}{
+		{"/tasks/3/complete", storages.TaskStatusDone},
+		{"/tasks/3/uncomplete", storages.TaskStatusTodo},
+	}
+
+	for _, testCase := range testCases {
+		ctx := withPrincipal(context.Background(), principal{UserID: 1})
+		req := httptest.NewRequest("PATCH", testCase.path, nil).WithContext(ctx)
+
+		db := new(storages.StoreMock)
+		db.On("SetTaskStatus", req.Context(), 1, 3, testCase.status).Return(nil)
+
+		s := NewToDoService(testJWTKey, ":6000", db)
+		w := httptest.NewRecorder()
+
+		s.taskHandler()(w, req)
+		db.AssertExpectations(t)
+
+		require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+	}
+}
+
+func TestListTasksCompletedFilter(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	req := newListTasksRequest(1, "2006-01-02")
+	q := req.URL.Query()
+	q.Add("completed", "true")
+	req.URL.RawQuery = q.Encode()
+	req = req.WithContext(ctx)
+
+	createdAt, _ := time.Parse("2006-01-02", "2006-01-02")
+	completed := true
+
+	db := new(storages.StoreMock)
+	db.On("FindTasks", req.Context(), 1, createdAt, storages.TaskFilter{Completed: &completed}).Return(testTasks, nil)
+
+	s := NewToDoService(testJWTKey, ":6000", db)
+	w := httptest.NewRecorder()
+
+	s.listTasksHandler(w, req)
+	db.AssertExpectations(t)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: testTasks}, resp)
+}
+
+func TestListOverdueTasks(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	req := httptest.NewRequest("GET", "/tasks?overdue=true", nil).WithContext(ctx)
+
+	db := new(storages.StoreMock)
+	db.On("FindOverdueTasks", req.Context(), 1, mock.AnythingOfType("time.Time")).Return(testTasks, nil)
+
+	s := NewToDoService(testJWTKey, ":6000", db)
+	w := httptest.NewRecorder()
+
+	s.listTasksHandler(w, req)
+	db.AssertExpectations(t)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: testTasks}, resp)
+}
+
+func TestListTasksInvalidSortBy(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	req := httptest.NewRequest("GET", "/tasks?created_date=2006-01-02&sort_by=content", nil).WithContext(ctx)
+
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock))
+	w := httptest.NewRecorder()
+
+	s.listTasksHandler(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+func TestTaskTags(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("POST", "/tasks/3/tags", bytes.NewBufferString(`{"tags": ["work", "home"]}`)).WithContext(ctx)
+	db.On("AddTaskTags", req.Context(), 1, 3, []string{"work", "home"}).Return(nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("DELETE", "/tasks/3/tags?tag=work", nil).WithContext(ctx)
+	db.On("RemoveTaskTags", req.Context(), 1, 3, []string{"work"}).Return(storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestListTasksTagFilter(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	req := httptest.NewRequest("GET", "/tasks?created_date=2006-01-02&tag=work&tag=home", nil).WithContext(ctx)
+
+	createdAt, _ := time.Parse("2006-01-02", "2006-01-02")
+
+	db := new(storages.StoreMock)
+	db.On("FindTasks", req.Context(), 1, createdAt, storages.TaskFilter{Tags: []string{"work", "home"}}).Return(testTasks, nil)
+
+	s := NewToDoService(testJWTKey, ":6000", db)
+	w := httptest.NewRecorder()
+
+	s.listTasksHandler(w, req)
+	db.AssertExpectations(t)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+}
+
+func TestMoveTask(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	afterId, beforeId := 2, 5
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("PATCH", "/tasks/3/position", bytes.NewBufferString(`{"after_id": 2, "before_id": 5}`)).WithContext(ctx)
+	db.On("MoveTask", req.Context(), 1, 3, &afterId, &beforeId).Return(nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PATCH", "/tasks/3/position", bytes.NewBufferString(`{"before_id": 5}`)).WithContext(ctx)
+	db.On("MoveTask", req.Context(), 1, 3, (*int)(nil), &beforeId).Return(storages.ErrConflict)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusConflict, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestRemindTask(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	remindAt := time.Date(2020, 6, 29, 9, 0, 0, 0, time.UTC)
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("PATCH", "/tasks/3/reminder", bytes.NewBufferString(`{"remind_at": "2020-06-29T09:00:00Z"}`)).WithContext(ctx)
+	db.On("SetTaskRemindAt", req.Context(), 1, 3, &remindAt).Return(nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("PATCH", "/tasks/4/reminder", bytes.NewBufferString(`{"remind_at": null}`)).WithContext(ctx)
+	db.On("SetTaskRemindAt", req.Context(), 1, 4, (*time.Time)(nil)).Return(storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	req = httptest.NewRequest("GET", "/tasks/3/reminder", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestTrash(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	deletedAt := time.Date(2020, 6, 29, 9, 0, 0, 0, time.UTC)
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	req := httptest.NewRequest("GET", "/tasks/trash", nil).WithContext(ctx)
+	db.On("GetTrash", req.Context(), 1).Return([]*storages.Task{{Id: 3, Content: "a", DeletedAt: &deletedAt}}, nil)
+	w := httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+	require.Contains(t, w.Body.String(), `"id":3`)
+
+	req = httptest.NewRequest("DELETE", "/tasks/trash", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/tasks/3/restore", nil).WithContext(ctx)
+	db.On("RestoreTask", req.Context(), 1, 3).Return(nil)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/tasks/4/restore", nil).WithContext(ctx)
+	db.On("RestoreTask", req.Context(), 1, 4).Return(storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	req = httptest.NewRequest("GET", "/tasks/3/restore", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.taskHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	db.AssertExpectations(t)
+}
+
+func TestListTasksRenderHTML(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+	createdAt := time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC)
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	tasks := []*storages.Task{
+		{Id: 1, Content: "**milk** <b>", ContentFormat: storages.ContentFormatMarkdown},
+		{Id: 2, Content: "eggs <b>", ContentFormat: storages.ContentFormatPlain},
+	}
+	req := httptest.NewRequest("GET", "/tasks?created_date=2020-06-29&render=html", nil).WithContext(ctx)
+	db.On("GetTasks", req.Context(), 1, createdAt).Return(tasks, nil)
+	w := httptest.NewRecorder()
+	s.tasksHandler()(w, req)
+
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+	require.Equal(t, "<p><strong>milk</strong> &lt;b&gt;</p>\n", tasks[0].ContentHTML)
+	require.Equal(t, "eggs &lt;b&gt;", tasks[1].ContentHTML)
+	require.Contains(t, w.Body.String(), `"content_html"`)
+}
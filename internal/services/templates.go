@@ -0,0 +1,163 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// newTemplate is the body of POST /templates, the tasks of TaskIds are saved after Tasks
+type newTemplate struct {
+	storages.Template
+	TaskIds []int `json:"task_ids"`
+}
+
+// templatesHandler serves templates of the user at /templates
+func (s *ToDoService) templatesHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		templater, ok := s.store.(storages.TaskTemplater)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodPost:
+			s.createTemplateHandler(resp, req, templater)
+		case http.MethodGet:
+			s.listTemplatesHandler(resp, req, templater)
+		default:
+			writeError(resp, errMethodNotAllowed)
+		}
+	}
+}
+
+// templateHandler serves a single template at /templates/{id} and creates its tasks at /templates/{id}/instantiate
+func (s *ToDoService) templateHandler() http.HandlerFunc {
+	return func(resp http.ResponseWriter, req *http.Request) {
+		id, action, ok := parseItemPath("/templates/", req.URL.Path)
+		if !ok {
+			writeError(resp, errUnknownRoute)
+			return
+		}
+
+		templater, ok := s.store.(storages.TaskTemplater)
+		if !ok {
+			writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+			return
+		}
+
+		switch {
+		case action == "" && req.Method == http.MethodGet:
+			s.getTemplateHandler(resp, req, templater, id)
+		case action == "" && req.Method == http.MethodDelete:
+			s.deleteTemplateHandler(resp, req, templater, id)
+		case action == "instantiate" && req.Method == http.MethodPost:
+			s.instantiateTemplateHandler(resp, req, templater, id)
+		case action == "", action == "instantiate":
+			writeError(resp, errMethodNotAllowed)
+		default:
+			writeError(resp, errUnknownRoute)
+		}
+	}
+}
+
+func (s *ToDoService) createTemplateHandler(resp http.ResponseWriter, req *http.Request, templater storages.TaskTemplater) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	body := &newTemplate{}
+	if !decodeBody(resp, req, body, maxBatchTasks*maxJsonSize) {
+		return
+	}
+	template := &body.Template
+	template.UsrId = userID
+
+	if err := templater.CreateTemplate(req.Context(), template, body.TaskIds); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(resp).Encode(newDataResp(template)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) listTemplatesHandler(resp http.ResponseWriter, req *http.Request, templater storages.TaskTemplater) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	templates, err := templater.GetTemplates(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(newDataResp(templates)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) getTemplateHandler(resp http.ResponseWriter, req *http.Request, templater storages.TaskTemplater, id int) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	template, err := templater.GetTemplate(req.Context(), userID, id)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(newDataResp(template)); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *ToDoService) deleteTemplateHandler(resp http.ResponseWriter, req *http.Request, templater storages.TaskTemplater, id int) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := templater.DeleteTemplate(req.Context(), userID, id); err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// instantiateTemplateHandler creates the tasks of the template all at once, they count toward the daily-limit
+func (s *ToDoService) instantiateTemplateHandler(resp http.ResponseWriter, req *http.Request, templater storages.TaskTemplater, id int) {
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	tasks, err := templater.InstantiateTemplate(req.Context(), userID, id)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	for _, task := range tasks {
+		s.auditTask(req.Context(), storages.TaskCreated, userID, task.Id, nil)
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(resp).Encode(newDataResp(tasks)); err != nil {
+		log.Println(err)
+	}
+}
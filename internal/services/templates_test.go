@@ -0,0 +1,73 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTemplates(t *testing.T) {
+	ctx := withPrincipal(context.Background(), principal{UserID: 1})
+
+	db := new(storages.StoreMock)
+	s := NewToDoService(testJWTKey, ":6000", db)
+
+	body := `{"name": "weekly review", "tasks": [{"content": "inbox zero", "items": ["mail", "chat"]}], "task_ids": [4]}`
+	req := httptest.NewRequest("POST", "/templates", bytes.NewBufferString(body)).WithContext(ctx)
+	template := &storages.Template{UsrId: 1, Name: "weekly review", Tasks: []storages.TemplateTask{{Content: "inbox zero", Items: []string{"mail", "chat"}}}}
+	db.On("CreateTemplate", req.Context(), template, []int{4}).Return(nil)
+	w := httptest.NewRecorder()
+	s.templatesHandler()(w, req)
+	require.Equal(t, http.StatusCreated, w.Result().StatusCode)
+
+	tasks := []*storages.Task{{Id: 7, UsrId: 1, Content: "inbox zero", Checklist: &storages.Checklist{Total: 2}}}
+	req = httptest.NewRequest("POST", "/templates/2/instantiate", nil).WithContext(ctx)
+	db.On("InstantiateTemplate", req.Context(), 1, 2).Return(tasks, nil)
+	w = httptest.NewRecorder()
+	s.templateHandler()(w, req)
+	resp := w.Result()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assertDataResp(t, &ApiDataResp{Data: tasks}, resp)
+
+	req = httptest.NewRequest("POST", "/templates/3/instantiate", nil).WithContext(ctx)
+	db.On("InstantiateTemplate", req.Context(), 1, 3).Return([]*storages.Task(nil), storages.ErrQuotaExceeded)
+	w = httptest.NewRecorder()
+	s.templateHandler()(w, req)
+	require.Equal(t, http.StatusTooManyRequests, w.Result().StatusCode)
+
+	req = httptest.NewRequest("DELETE", "/templates/2", nil).WithContext(ctx)
+	db.On("DeleteTemplate", req.Context(), 1, 2).Return(storages.ErrNotFound)
+	w = httptest.NewRecorder()
+	s.templateHandler()(w, req)
+	require.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+
+	req = httptest.NewRequest("GET", "/templates/2/instantiate", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.templateHandler()(w, req)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Result().StatusCode)
+
+	// templates of others follow the policy of their tasks
+	db.On("GetShare", mock.Anything, 4, 1).Return(&storages.Share{OwnerId: 4, UsrId: 1}, nil)
+	db.On("GetTemplates", mock.Anything, 4).Return([]*storages.Template{}, nil)
+	req = httptest.NewRequest("GET", "/templates?owner=4", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.templatesHandler()(w, req)
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+	req = httptest.NewRequest("POST", "/templates/5/instantiate?owner=4", nil).WithContext(ctx)
+	w = httptest.NewRecorder()
+	s.templateHandler()(w, req)
+	require.Equal(t, http.StatusForbidden, w.Result().StatusCode, "the share is read-only")
+
+	req = httptest.NewRequest("GET", "/templates/2", nil)
+	w = httptest.NewRecorder()
+	s.templateHandler()(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Result().StatusCode, "no principal")
+
+	db.AssertExpectations(t)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// createdToday is the created_date of the tasks created on the current day of their user
+const createdToday = "today"
+
+// timezoneParams is the timezone days of a user are counted in
+type timezoneParams struct {
+	Timezone string `json:"timezone"`
+}
+
+func (p *timezoneParams) validate(v *validator) {
+	_, err := time.LoadLocation(p.Timezone)
+	v.check(p.Timezone != "" && err == nil, "timezone", "must be an IANA timezone such as Europe/Paris")
+}
+
+// timezoneHandler returns the timezone of the user at GET /users/me/timezone and changes it at PUT
+func (s *ToDoService) timezoneHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	store, ok := s.store.(storages.TimezoneStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	params := &timezoneParams{}
+	switch req.Method {
+	case http.MethodGet:
+		timezone, err := store.GetTimezone(req.Context(), userID)
+		if err != nil {
+			writeError(resp, err)
+			return
+		}
+		params.Timezone = timezone
+		if timezone == "" {
+			params.Timezone = "UTC"
+		}
+	case http.MethodPut:
+		if !decodeBody(resp, req, params, maxJsonSize) {
+			return
+		}
+		if err := store.SetTimezone(req.Context(), userID, params.Timezone); err != nil {
+			writeError(resp, err)
+			return
+		}
+	default:
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	if err := json.NewEncoder(resp).Encode(newDataResp(params)); err != nil {
+		log.Println(err)
+	}
+}
+
+// userLocation returns the timezone of the user, UTC unless the storage keeps timezones
+func (s *ToDoService) userLocation(ctx context.Context, usrId int) (*time.Location, error) {
+	store, ok := s.store.(storages.TimezoneStore)
+	if !ok {
+		return time.UTC, nil
+	}
+
+	timezone, err := store.GetTimezone(ctx, usrId)
+	if err != nil {
+		return nil, err
+	}
+	usr := &storages.User{Timezone: timezone}
+	return usr.Location(), nil
+}
+
+// createdDate parses the created_date given for the tasks of the user, YYYY-MM-DD or today, as the start of
+// the day in the timezone of the user. It returns errInvalidCreated for other dates
+func (s *ToDoService) createdDate(ctx context.Context, usrId int, date string) (time.Time, error) {
+	loc, err := s.userLocation(ctx, usrId)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if date == createdToday {
+		from, _ := storages.DayRangeIn(time.Now(), loc)
+		return from, nil
+	}
+	createdDate, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return time.Time{}, errInvalidCreated
+	}
+	return createdDate, nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimezone(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	do := func(handler http.HandlerFunc, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w
+	}
+
+	w := do(s.timezoneHandler, "GET", "/users/me/timezone", "")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"timezone": "UTC"}}`, w.Body.String())
+	requireTest.Equal(http.StatusUnprocessableEntity, do(s.timezoneHandler, "PUT", "/users/me/timezone", `{"timezone": "Mars/Olympus"}`).Code)
+	requireTest.Equal(http.StatusUnprocessableEntity, do(s.timezoneHandler, "PUT", "/users/me/timezone", `{"timezone": ""}`).Code)
+	w = do(s.timezoneHandler, "PUT", "/users/me/timezone", `{"timezone": "Etc/GMT-14"}`)
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"timezone": "Etc/GMT-14"}}`, w.Body.String())
+
+	requireTest.Equal(http.StatusOK, do(s.tasksHandler(), "POST", "/tasks", `{"content": "milk"}`).Code)
+	w = do(s.tasksHandler(), "GET", "/tasks?created_date=today", "")
+	requireTest.Equal(http.StatusOK, w.Code)
+	result := struct {
+		Data []*storages.Task `json:"data"`
+	}{}
+	requireTest.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+	requireTest.Len(result.Data, 1)
+
+	// today starts at midnight in the timezone of the user
+	loc, err := s.userLocation(context.Background(), usr.Id)
+	requireTest.NoError(err)
+	today, err := s.createdDate(context.Background(), usr.Id, createdToday)
+	requireTest.NoError(err)
+	requireTest.Equal(loc, today.Location())
+	requireTest.Zero(today.Hour())
+
+	requireTest.Equal(http.StatusBadRequest, do(s.tasksHandler(), "GET", "/tasks?created_date=yesterday", "").Code)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/manabie-com/togo/internal/services"
+
+// traceHandler serves every request next serves in a span named by the pattern of routes it matches, the span
+// continues the trace of the traceparent header of the request if it has one. Storages add theirs under it
+func (s *ToDoService) traceHandler(routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), req.Header)
+		_, route := routes.Handler(req)
+		ctx, span := otel.Tracer(tracerName).Start(ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("togo", route, req)...),
+		)
+		defer span.End()
+
+		w := &statusWriter{ResponseWriter: resp, status: http.StatusOK}
+		next.ServeHTTP(w, req.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(w.status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(w.status))
+	})
+}
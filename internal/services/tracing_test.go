@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceRequests(t *testing.T) {
+	requireTest := require.New(t)
+	recorder := &oteltest.StandardSpanRecorder{}
+	otel.SetTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+	token, err := s.createToken(usr)
+	requireTest.NoError(err)
+
+	req := httptest.NewRequest("GET", "/v1/tasks/97", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, req)
+	requireTest.Equal(http.StatusNotFound, w.Code)
+
+	spans := recorder.Completed()
+	requireTest.Len(spans, 1)
+	span := spans[0]
+	// spans are named by route and continue the trace of the caller
+	requireTest.Equal("GET /tasks/", span.Name())
+	requireTest.Equal(trace.SpanKindServer, span.SpanKind())
+	requireTest.Equal("0af7651916cd43dd8448eb211c80319c", span.SpanContext().TraceID.String())
+	requireTest.Equal("b7ad6b7169203331", span.ParentSpanID().String())
+	requireTest.Equal(int64(http.StatusNotFound), span.Attributes()[semconv.HTTPStatusCodeKey].AsInt64())
+	requireTest.Equal(codes.Error, span.StatusCode())
+}
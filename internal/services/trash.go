@@ -0,0 +1,58 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/manabie-com/togo/internal/events"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// trashHandler lists deleted tasks of the user at /tasks/trash, they are purged after the retention period
+func (s *ToDoService) trashHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	trasher, ok := s.store.(storages.TaskTrasher)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	tasks, err := trasher.GetTrash(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	renderContent(req, tasks...)
+	writeTasks(resp, req, tasks)
+}
+
+// restoreTaskHandler moves the task back from the trash
+func (s *ToDoService) restoreTaskHandler(resp http.ResponseWriter, req *http.Request, id int) {
+	trasher, ok := s.store.(storages.TaskTrasher)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := s.authorize(resp, req)
+	if !ok {
+		return
+	}
+
+	if err := trasher.RestoreTask(req.Context(), userID, id); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.publishTask(events.Created, userID, id, nil)
+	s.auditTask(req.Context(), storages.TaskRestored, userID, id, nil)
+
+	resp.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/totp"
+)
+
+// twoFactorIssuer names the service in authenticator apps
+const twoFactorIssuer = "togo"
+
+var (
+	errTwoFactorRequired = errors.New("two-factor code is required")
+	errInvalidTwoFactor  = errors.New("two-factor code is not valid")
+	errTwoFactorEnabled  = errors.New("two-factor authentication is enabled already")
+)
+
+type twoFactorParams struct {
+	Code string `json:"code"`
+}
+
+// twoFactorEnrollment is the answer to an enrollment, URL is the payload of the QR code authenticator apps scan.
+// The backup codes are only ever shown here
+type twoFactorEnrollment struct {
+	Secret      string   `json:"secret"`
+	URL         string   `json:"otpauth_url"`
+	BackupCodes []string `json:"backup_codes"`
+}
+
+// twoFactorHandler starts an enrollment of the user at POST /users/me/2fa, logins don't require codes until
+// it's confirmed. DELETE /users/me/2fa with a code disables two-factor authentication
+func (s *ToDoService) twoFactorHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost && req.Method != http.MethodDelete {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.TwoFactorStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	if req.Method == http.MethodDelete {
+		params := &twoFactorParams{}
+		if !decodeBody(resp, req, params, maxJsonSize) {
+			return
+		}
+		switch err := s.verifyTwoFactor(req.Context(), userID, params.Code); err {
+		case nil:
+		case errTwoFactorRequired, errInvalidTwoFactor:
+			writeErrResp(resp, http.StatusForbidden, errInvalidTwoFactor)
+			return
+		default:
+			writeError(resp, err)
+			return
+		}
+		if err := store.DisableTwoFactor(req.Context(), userID); err != nil {
+			writeError(resp, err)
+			return
+		}
+		s.audit(req, storages.AuditTwoFactorDisabled, userID, userID, "")
+		resp.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	usr, err := store.GetUser(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	codes, hashes, err := totp.NewBackupCodes(totp.BackupCodes)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	err = store.SaveTwoFactor(req.Context(), &storages.TwoFactor{UsrId: userID, Secret: secret, BackupCodes: hashes})
+	if err == storages.ErrConflict {
+		writeErrResp(resp, http.StatusConflict, errTwoFactorEnabled)
+		return
+	}
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	enrollment := twoFactorEnrollment{
+		Secret:      secret,
+		URL:         totp.URL(twoFactorIssuer, usr.Username, secret),
+		BackupCodes: codes,
+	}
+	resp.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(resp).Encode(newDataResp(enrollment)); err != nil {
+		log.Println(err)
+	}
+}
+
+// confirmTwoFactorHandler enables the pending enrollment of the user at POST /users/me/2fa/confirm
+// with a code of the authenticator app, every later login requires a code
+func (s *ToDoService) confirmTwoFactorHandler(resp http.ResponseWriter, req *http.Request) {
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	if req.Method != http.MethodPost {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	store, ok := s.store.(storages.TwoFactorStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+
+	params := &twoFactorParams{}
+	if !decodeBody(resp, req, params, maxJsonSize) {
+		return
+	}
+
+	tf, err := store.GetTwoFactor(req.Context(), userID)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if tf.Enabled {
+		writeErrResp(resp, http.StatusConflict, errTwoFactorEnabled)
+		return
+	}
+	counter, ok := totp.Validate(tf.Secret, params.Code, time.Now())
+	if !ok {
+		writeErrResp(resp, http.StatusBadRequest, errInvalidTwoFactor)
+		return
+	}
+	if err := store.EnableTwoFactor(req.Context(), userID, counter); err != nil {
+		writeError(resp, err)
+		return
+	}
+	s.audit(req, storages.AuditTwoFactorEnabled, userID, userID, "")
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// verifyTwoFactor checks code, a TOTP code or a backup code, if the user has enabled two-factor
+// authentication. Every code is accepted once
+func (s *ToDoService) verifyTwoFactor(ctx context.Context, usrId int, code string) error {
+	store, ok := s.store.(storages.TwoFactorStore)
+	if !ok {
+		return nil
+	}
+
+	tf, err := store.GetTwoFactor(ctx, usrId)
+	if err == storages.ErrNotFound || (err == nil && !tf.Enabled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if code == "" {
+		return errTwoFactorRequired
+	}
+
+	if counter, ok := totp.Validate(tf.Secret, code, time.Now()); ok {
+		err = store.UseTOTP(ctx, usrId, counter)
+	} else {
+		err = store.UseBackupCode(ctx, usrId, totp.HashBackupCode(code))
+	}
+	if err == storages.ErrInvalidCredentials {
+		return errInvalidTwoFactor
+	}
+	return err
+}
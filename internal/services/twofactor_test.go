@@ -0,0 +1,99 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/manabie-com/togo/internal/totp"
+	"github.com/stretchr/testify/require"
+)
+
+func newOTPLoginRequest(username, password, otp string) *http.Request {
+	body, _ := json.Marshal(&loginParams{Username: username, Password: password, OTP: otp})
+	return httptest.NewRequest("POST", "/login", bytes.NewBuffer(body))
+}
+
+func TestTwoFactor(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	pwdHash, err := password.Hash("s3cretpass")
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", PwdHash: pwdHash, MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	token, err := s.createToken(usr)
+	requireTest.NoError(err)
+	newRequest := func(method, path, body string) *http.Request {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	s.authHandler(s.twoFactorHandler)(w, newRequest("POST", "/users/me/2fa", ""))
+	requireTest.Equal(http.StatusCreated, w.Result().StatusCode)
+	enrollment := &struct {
+		Data twoFactorEnrollment `json:"data"`
+	}{}
+	requireTest.NoError(json.NewDecoder(w.Body).Decode(enrollment))
+	requireTest.Contains(enrollment.Data.URL, "otpauth://totp/togo:alice?")
+	requireTest.Len(enrollment.Data.BackupCodes, totp.BackupCodes)
+
+	// logins don't require codes until the enrollment is confirmed
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newOTPLoginRequest("alice", "s3cretpass", ""))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	s.authHandler(s.confirmTwoFactorHandler)(w, newRequest("POST", "/users/me/2fa/confirm", `{"code": "000000x"}`))
+	requireTest.Equal(http.StatusBadRequest, w.Result().StatusCode)
+
+	code, err := totp.Code(enrollment.Data.Secret, totp.Counter(time.Now()))
+	requireTest.NoError(err)
+	w = httptest.NewRecorder()
+	s.authHandler(s.confirmTwoFactorHandler)(w, newRequest("POST", "/users/me/2fa/confirm", `{"code": "`+code+`"}`))
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newOTPLoginRequest("alice", "s3cretpass", ""))
+	requireTest.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+	requireTest.JSONEq(`{"error": {"code": "TWO_FACTOR_REQUIRED", "message": "two-factor code is required"}}`, w.Body.String())
+
+	// the code which confirmed the enrollment can't be used again
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newOTPLoginRequest("alice", "s3cretpass", code))
+	requireTest.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+
+	backup := enrollment.Data.BackupCodes[0]
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newOTPLoginRequest("alice", "s3cretpass", backup))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newOTPLoginRequest("alice", "s3cretpass", backup))
+	requireTest.Equal(http.StatusUnauthorized, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	s.authHandler(s.twoFactorHandler)(w, newRequest("POST", "/users/me/2fa", ""))
+	requireTest.Equal(http.StatusConflict, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	s.authHandler(s.twoFactorHandler)(w, newRequest("DELETE", "/users/me/2fa", `{"code": "wrong"}`))
+	requireTest.Equal(http.StatusForbidden, w.Result().StatusCode)
+	w = httptest.NewRecorder()
+	s.authHandler(s.twoFactorHandler)(w, newRequest("DELETE", "/users/me/2fa", `{"code": "`+enrollment.Data.BackupCodes[1]+`"}`))
+	requireTest.Equal(http.StatusNoContent, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	s.createTokenHandler(w, newOTPLoginRequest("alice", "s3cretpass", ""))
+	requireTest.Equal(http.StatusOK, w.Result().StatusCode)
+}
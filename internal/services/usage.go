@@ -0,0 +1,139 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// defaultUsageDays is how many days of usage are reported when from isn't given, maxUsageDays how many at most
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+
+	usageLayout = "2006-01-02"
+)
+
+var errInvalidUsageRange = errors.New("from and to must be YYYY-MM-DD, from not after to and at most 366 days apart")
+
+// usageResult is how many tasks a user added every day from from to to, days without tasks included
+type usageResult struct {
+	From  string     `json:"from"`
+	To    string     `json:"to"`
+	Total int        `json:"total"`
+	Days  []usageDay `json:"days"`
+}
+
+type usageDay struct {
+	Day   string `json:"day"`
+	Tasks int    `json:"tasks"`
+}
+
+// userUsageHandler returns how many tasks the user added a day at GET /users/me/usage?from=&to=
+func (s *ToDoService) userUsageHandler(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromCtx(req.Context())
+	if !ok {
+		writeError(resp, errNoPrincipal)
+		return
+	}
+	s.writeUsage(resp, req, userID)
+}
+
+// adminUsageHandler returns how many tasks a user added a day at GET /users/{id}/usage?from=&to=
+func (s *ToDoService) adminUsageHandler(resp http.ResponseWriter, req *http.Request) {
+	id, action, ok := parseItemPath("/users/", req.URL.Path)
+	if !ok || action != "usage" {
+		writeError(resp, errUnknownRoute)
+		return
+	}
+	if req.Method != http.MethodGet {
+		writeError(resp, errMethodNotAllowed)
+		return
+	}
+	s.writeUsage(resp, req, id)
+}
+
+// userActionHandler serves the admin routes of a user at /users/{id}/limit and /users/{id}/usage
+func (s *ToDoService) userActionHandler(resp http.ResponseWriter, req *http.Request) {
+	if _, action, _ := parseItemPath("/users/", req.URL.Path); action == "usage" {
+		s.adminUsageHandler(resp, req)
+		return
+	}
+	s.adminLimitHandler(resp, req)
+}
+
+// writeUsage answers the usage of the user within the days of the from and to parameters, dates of the
+// timezone of the user. The last defaultUsageDays up to today are answered by default
+func (s *ToDoService) writeUsage(resp http.ResponseWriter, req *http.Request, usrId int) {
+	store, ok := s.store.(storages.UsageStore)
+	if !ok {
+		writeErrResp(resp, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+
+	loc, err := s.userLocation(req.Context(), usrId)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	from, to, err := usageRange(req.URL.Query().Get("from"), req.URL.Query().Get("to"), time.Now().In(loc))
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+
+	usage, err := store.GetUsage(req.Context(), usrId, from, to)
+	if err != nil {
+		writeError(resp, err)
+		return
+	}
+	if err := json.NewEncoder(resp).Encode(newDataResp(newUsageResult(from, to, usage))); err != nil {
+		log.Println(err)
+	}
+}
+
+// usageRange parses the dates from and to, to is the date of now and from defaultUsageDays before it by default
+func usageRange(fromParam, toParam string, now time.Time) (time.Time, time.Time, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if toParam != "" {
+		var err error
+		if to, err = time.Parse(usageLayout, toParam); err != nil {
+			return time.Time{}, time.Time{}, errInvalidUsageRange
+		}
+	}
+	from := to.AddDate(0, 0, 1-defaultUsageDays)
+	if fromParam != "" {
+		var err error
+		if from, err = time.Parse(usageLayout, fromParam); err != nil {
+			return time.Time{}, time.Time{}, errInvalidUsageRange
+		}
+	}
+	if from.After(to) || to.Sub(from) >= maxUsageDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errInvalidUsageRange
+	}
+	return from, to, nil
+}
+
+func newUsageResult(from, to time.Time, usage []*storages.Usage) usageResult {
+	tasks := make(map[string]int, len(usage))
+	for _, day := range usage {
+		tasks[day.Day.Format(usageLayout)] = day.Tasks
+	}
+
+	result := usageResult{From: from.Format(usageLayout), To: to.Format(usageLayout), Days: make([]usageDay, 0)}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		d := usageDay{Day: day.Format(usageLayout), Tasks: tasks[day.Format(usageLayout)]}
+		result.Total += d.Tasks
+		result.Days = append(result.Days, d)
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	requireTest := require.New(t)
+	m, err := memory.NewMemory()
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(context.Background(), usr))
+	s := NewToDoService(testJWTKey, ":6000", m)
+
+	getUsage := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/users/me/usage"+query, nil)
+		w := httptest.NewRecorder()
+		s.userUsageHandler(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		return w
+	}
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/tasks", bytes.NewBufferString(`{"content": "milk"}`))
+		w := httptest.NewRecorder()
+		s.tasksHandler()(w, req.WithContext(withPrincipal(req.Context(), principal{UserID: usr.Id})))
+		requireTest.Equal(http.StatusOK, w.Code)
+	}
+
+	// the last 30 days up to today, days without tasks included
+	w := getUsage("")
+	requireTest.Equal(http.StatusOK, w.Code)
+	result := struct {
+		Data usageResult `json:"data"`
+	}{}
+	requireTest.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+	today := time.Now().UTC().Format(usageLayout)
+	requireTest.Equal(today, result.Data.To)
+	requireTest.Len(result.Data.Days, defaultUsageDays)
+	requireTest.Equal(usageDay{Day: today, Tasks: 2}, result.Data.Days[defaultUsageDays-1])
+	requireTest.Equal(2, result.Data.Total)
+
+	w = getUsage("?from=2020-07-01&to=2020-07-02")
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"from": "2020-07-01", "to": "2020-07-02", "total": 0, "days": [
+		{"day": "2020-07-01", "tasks": 0}, {"day": "2020-07-02", "tasks": 0}]}}`, w.Body.String())
+
+	for _, query := range []string{"?from=yesterday", "?from=2020-07-02&to=2020-07-01", "?from=2019-01-01&to=2020-07-01"} {
+		requireTest.Equal(http.StatusBadRequest, getUsage(query).Code, query)
+	}
+
+	// admins see the usage of users at /users/{id}/usage
+	w = httptest.NewRecorder()
+	s.adminHandler(s.userActionHandler)(w, newAdminRequest(t, s, "GET", "/users/2/usage?from="+today, "", storages.RoleAdmin))
+	requireTest.Equal(http.StatusOK, w.Code)
+	requireTest.JSONEq(`{"data": {"from": "`+today+`", "to": "`+today+`", "total": 2, "days": [{"day": "`+today+`", "tasks": 2}]}}`, w.Body.String())
+	w = httptest.NewRecorder()
+	s.adminHandler(s.userActionHandler)(w, newAdminRequest(t, s, "GET", "/users/2/usage", "", storages.RoleUser))
+	requireTest.Equal(http.StatusForbidden, w.Code)
+}
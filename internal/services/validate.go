@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/tokens"
+)
+
+// maxContentLen is the longest content of a task or checklist item in characters
+const maxContentLen = 500
+
+var errInvalidFields = errors.New("some fields of the request are not valid")
+
+// fieldError tells why a field of a request body was rejected, Field is its JSON path such as tasks[1].content
+// and is empty when the body as a whole is wrong
+type fieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// validator collects the fields of a body which break its rules
+type validator struct {
+	prefix string
+	errs   []fieldError
+}
+
+// check rejects field with message unless ok
+func (v *validator) check(ok bool, field, message string) {
+	if !ok {
+		v.errs = append(v.errs, fieldError{Field: v.prefix + field, Message: message})
+	}
+}
+
+// nested checks the fields of an element of the body by fn, their paths are under field
+func (v *validator) nested(field string, fn func(v *validator)) {
+	prefix := v.prefix
+	v.prefix += field + "."
+	fn(v)
+	v.prefix = prefix
+}
+
+// validBody is a request body with rules of its own
+type validBody interface {
+	validate(v *validator)
+}
+
+// decodeBody reads the JSON body of req, up to limit bytes, into body and checks its rules. Malformed JSON is
+// answered with 400, fields of the wrong type or which break the rules with 422 and the fields as details
+func decodeBody(resp http.ResponseWriter, req *http.Request, body interface{}, limit int64) bool {
+	err := json.NewDecoder(http.MaxBytesReader(resp, req.Body, limit)).Decode(body)
+	v := &validator{}
+	switch err := err.(type) {
+	case nil:
+		validate(v, body)
+	case *json.UnmarshalTypeError:
+		v.check(false, err.Field, "must be "+jsonKind(err.Type))
+	case *time.ParseError:
+		v.check(false, "", "times must be RFC 3339 such as 2006-01-02T15:04:05Z")
+	default:
+		if tooLarge(err) {
+			writeError(resp, errBodyTooLarge)
+		} else {
+			writeError(resp, errInvalidBody)
+		}
+		return false
+	}
+	if len(v.errs) > 0 {
+		writeErrDetails(resp, http.StatusUnprocessableEntity, codeValidation, errInvalidFields, v.errs)
+		return false
+	}
+	return true
+}
+
+// validate checks the rules of body, storage entities have theirs here as they're shared by other APIs
+func validate(v *validator, body interface{}) {
+	switch body := body.(type) {
+	case validBody:
+		body.validate(v)
+	case *storages.Task:
+		validateTask(v, body)
+	case *storages.ChecklistItem:
+		v.check(strings.TrimSpace(body.Content) != "", "content", "is required")
+		v.check(utf8.RuneCountInString(body.Content) <= maxContentLen, "content", "must be at most "+strconv.Itoa(maxContentLen)+" characters")
+	case *storages.Comment:
+		v.check(strings.TrimSpace(body.Content) != "", "content", "is required")
+		v.check(len(body.Content) <= storages.MaxCommentLen, "content", "must be at most "+strconv.Itoa(storages.MaxCommentLen)+" bytes")
+		v.check(body.ContentFormat.Valid(), "content_format", "must be plain or markdown")
+	case *storages.Project:
+		validateName(v, body.Name)
+		v.check(body.MaxTodo == nil || *body.MaxTodo >= 0, "max_todo", "can't be negative")
+	case *storages.UserPatch:
+		v.check(body.Role == nil || body.Role.Valid(), "role", "must be user or admin")
+		v.check(body.MaxTodo == nil || *body.MaxTodo >= 0, "max_todo", "can't be negative")
+	}
+}
+
+// validateTask checks the fields of a task given by a client
+func validateTask(v *validator, task *storages.Task) {
+	v.check(strings.TrimSpace(task.Content) != "", "content", "is required")
+	v.check(utf8.RuneCountInString(task.Content) <= maxContentLen, "content", "must be at most "+strconv.Itoa(maxContentLen)+" characters")
+	v.check(task.ContentFormat.Valid(), "content_format", "must be plain or markdown")
+	v.check(task.Status == "" || task.Status.Valid(), "status", "must be todo, doing or done")
+	v.check(task.Recurrence.Valid(), "recurrence", "must be daily, weekly or monthly")
+	validateTags(v, task.Tags)
+}
+
+// validateTags checks tags of a task
+func validateTags(v *validator, tags []string) {
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		v.check(tag != "" && len(tag) <= storages.MaxTagLen, "tags["+strconv.Itoa(i)+"]",
+			"must be 1 to "+strconv.Itoa(storages.MaxTagLen)+" bytes")
+	}
+}
+
+// validateName checks the name of a project or a template
+func validateName(v *validator, name string) {
+	name = strings.TrimSpace(name)
+	v.check(name != "" && len(name) <= storages.MaxProjectNameLen, "name",
+		"must be 1 to "+strconv.Itoa(storages.MaxProjectNameLen)+" bytes")
+}
+
+// validatePassword checks field holds a password of the policy of password.Validate
+func validatePassword(v *validator, field, pwd string) {
+	v.check(password.Validate(pwd) == nil, field, password.ErrWeak.Error())
+}
+
+// jsonKind names the JSON type a field of Go type t is decoded from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func (b *taskBatch) validate(v *validator) {
+	v.check(len(b.Tasks) > 0 && len(b.Tasks) <= maxBatchTasks, "tasks", errInvalidBatch.Error())
+	for i, task := range b.Tasks {
+		field := "tasks[" + strconv.Itoa(i) + "]"
+		if task == nil {
+			v.check(false, field, "is required")
+			continue
+		}
+		v.nested(field, func(v *validator) {
+			validateTask(v, task)
+		})
+	}
+}
+
+func (b *taskTags) validate(v *validator) {
+	v.check(len(b.Tags) > 0, "tags", "is required")
+	validateTags(v, b.Tags)
+}
+
+func (b *taskPosition) validate(v *validator) {
+	v.check(b.AfterId != nil || b.BeforeId != nil, "", "after_id or before_id is required")
+}
+
+func (b *newTemplate) validate(v *validator) {
+	validateName(v, b.Name)
+	count := len(b.Tasks) + len(b.TaskIds)
+	v.check(count > 0 && count <= storages.MaxTemplateTasks, "tasks",
+		"template must have 1 to "+strconv.Itoa(storages.MaxTemplateTasks)+" tasks")
+	for i, task := range b.Tasks {
+		v.nested("tasks["+strconv.Itoa(i)+"]", func(v *validator) {
+			v.check(task.ContentFormat.Valid(), "content_format", "must be plain or markdown")
+			validateTags(v, task.Tags)
+			for j, item := range task.Items {
+				v.check(strings.TrimSpace(item) != "", "items["+strconv.Itoa(j)+"]", "is required")
+			}
+		})
+	}
+}
+
+func (p *signupParams) validate(v *validator) {
+	usr := &storages.User{Username: p.Username}
+	v.check(usr.NormalizeUsername() == nil, "username", "must be "+strconv.Itoa(storages.MinUsernameLen)+" to "+
+		strconv.Itoa(storages.MaxUsernameLen)+" letters, digits, dots, dashes or underscores")
+	validatePassword(v, "password", p.Password)
+}
+
+func (p *changePasswordParams) validate(v *validator) {
+	v.check(p.CurrentPassword != "", "current_password", "is required")
+	validatePassword(v, "new_password", p.NewPassword)
+}
+
+func (p *resetParams) validate(v *validator) {
+	v.check(p.Username != "", "username", "is required")
+}
+
+func (p *confirmResetParams) validate(v *validator) {
+	v.check(p.Token != "", "token", "is required")
+	validatePassword(v, "new_password", p.NewPassword)
+}
+
+func (p *scopedTokenParams) validate(v *validator) {
+	v.check(len(p.Scopes) > 0, "scopes", "is required")
+	for i, scope := range p.Scopes {
+		switch scope {
+		case tokens.ScopeTasksRead, tokens.ScopeTasksWrite, tokens.ScopeAdmin:
+		default:
+			v.check(false, "scopes["+strconv.Itoa(i)+"]", errInvalidScopes.Error())
+		}
+	}
+	v.check(p.ExpiresIn >= 0 && time.Duration(p.ExpiresIn)*time.Second <= maxScopedTokenTTL, "expires_in",
+		"must be 0 to "+strconv.Itoa(int(maxScopedTokenTTL/time.Second))+" seconds")
+}
+
+func (p *linkParams) validate(v *validator) {
+	v.check((p.CreatedDate == "") != (p.ProjectId == 0), "", errInvalidLinkScope.Error())
+	if p.CreatedDate != "" {
+		_, err := time.Parse("2006-01-02", p.CreatedDate)
+		v.check(err == nil, "created_date", "must be YYYY-MM-DD")
+	}
+	v.check(p.ProjectId >= 0, "project_id", "can't be negative")
+	v.check(p.ExpiresIn >= 0, "expires_in", "can't be negative")
+}
+
+func (p *twoFactorParams) validate(v *validator) {
+	v.check(p.Code != "", "code", "is required")
+}
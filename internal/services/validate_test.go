@@ -0,0 +1,63 @@
+package services
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBody(t *testing.T) {
+	requireTest := require.New(t)
+
+	for _, tc := range []struct {
+		body    interface{}
+		payload string
+		code    int
+		resp    string
+	}{
+		{&storages.Task{}, `{"content": "milk"`, http.StatusBadRequest,
+			`{"error": {"code": "BAD_REQUEST", "message": "request body is not valid"}}`},
+		{&storages.Task{}, `{"content": "milk", "priority": "high"}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"field": "priority", "message": "must be an integer"}]}}`},
+		{&storages.Task{}, `{"content": "milk", "due_at": "tomorrow"}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"message": "times must be RFC 3339 such as 2006-01-02T15:04:05Z"}]}}`},
+		{&storages.Task{}, `{"content": " ", "content_format": "html", "tags": ["home", ""]}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"field": "content", "message": "is required"},
+				{"field": "content_format", "message": "must be plain or markdown"},
+				{"field": "tags[1]", "message": "must be 1 to 32 bytes"}]}}`},
+		{&storages.Task{}, `{"content": "` + strings.Repeat("é", maxContentLen+1) + `"}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"field": "content", "message": "must be at most 500 characters"}]}}`},
+		{&taskBatch{}, `{"tasks": [{"content": "milk"}, null, {"content": "eggs", "recurrence": "hourly"}]}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"field": "tasks[1]", "message": "is required"},
+				{"field": "tasks[2].recurrence", "message": "must be daily, weekly or monthly"}]}}`},
+		{&signupParams{}, `{"username": "al", "password": "short1"}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"field": "username", "message": "must be 3 to 36 letters, digits, dots, dashes or underscores"},
+				{"field": "password", "message": "password must have 8 to 72 bytes with both letters and digits"}]}}`},
+		{&linkParams{}, `{"created_date": "2021-13-01"}`, http.StatusUnprocessableEntity,
+			`{"error": {"code": "VALIDATION_ERROR", "message": "some fields of the request are not valid", "details": [
+				{"field": "created_date", "message": "must be YYYY-MM-DD"}]}}`},
+	} {
+		req := httptest.NewRequest("POST", "/", bytes.NewBufferString(tc.payload))
+		w := httptest.NewRecorder()
+		requireTest.False(decodeBody(w, req, tc.body, maxBatchTasks*maxJsonSize), tc.payload)
+		requireTest.Equal(tc.code, w.Code, tc.payload)
+		requireTest.JSONEq(tc.resp, w.Body.String())
+	}
+
+	task := &storages.Task{}
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"content": "milk", "tags": ["home"]}`))
+	w := httptest.NewRecorder()
+	requireTest.True(decodeBody(w, req, task, maxJsonSize))
+	requireTest.Equal(&storages.Task{Content: "milk", Tags: []string{"home"}}, task)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// currentVersion is the prefix of the latest version of the HTTP API. Every version is a mux of its own mounted
+// at /{version}/, a later version reuses handlers of the previous one for the routes it doesn't change
+const currentVersion = "/v1"
+
+// WithLegacySunset announces when the unversioned legacy paths stop being served by their Sunset header,
+// they are only marked deprecated while it's not set
+func WithLegacySunset(t time.Time) Option {
+	return func(s *ToDoService) {
+		s.legacySunset = t
+	}
+}
+
+// versionedRoutes serves the versions of the HTTP API under their prefixes, the paths without a version are
+// deprecated aliases of version 1. Well-known paths stay at the root
+func (s *ToDoService) versionedRoutes() http.Handler {
+	routes := s.v1Routes()
+	v1 := s.traceHandler(routes, s.metricsHandler(routes, s.rateLimitHandler(s.limitsHandler(routes))))
+
+	api := http.NewServeMux()
+	api.Handle("/v1/", mountVersion("/v1", v1))
+	api.Handle("/.well-known/", v1)
+	api.Handle("/", s.legacyHandler("/v1", v1))
+
+	// probes, the build and metrics are not logged, probes and metrics are served by the admin server once
+	// there's one
+	mux := http.NewServeMux()
+	if s.adminServer == nil {
+		s.handleProbes(mux)
+	} else {
+		mux.HandleFunc("/version", s.setHeaders(s.versionHandler))
+	}
+	mux.Handle("/", s.requestLogHandler(s.accessLogHandler(recoverHandler(api))))
+	return mux
+}
+
+// handleProbes adds the probes, the build and metrics to mux
+func (s *ToDoService) handleProbes(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", s.setHeaders(s.healthzHandler))
+	mux.HandleFunc("/readyz", s.setHeaders(s.readyzHandler))
+	mux.HandleFunc("/version", s.setHeaders(s.versionHandler))
+	if s.metrics != nil {
+		mux.Handle("/metrics", s.metrics.Handler())
+	}
+}
+
+type versionKey struct{}
+
+// mountVersion serves the routes of a version under prefix, handlers tell it by versionPrefix
+func mountVersion(prefix string, routes http.Handler) http.Handler {
+	next := http.StripPrefix(prefix, routes)
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(resp, req.WithContext(context.WithValue(req.Context(), versionKey{}, prefix)))
+	})
+}
+
+// versionPrefix returns the prefix of the version of the API a request came by, empty for legacy paths
+func versionPrefix(ctx context.Context) string {
+	prefix, _ := ctx.Value(versionKey{}).(string)
+	return prefix
+}
+
+// legacyHandler serves unversioned paths by next and points clients to the same path under prefix by
+// the Deprecation, Sunset and Link headers of draft-ietf-httpapi-deprecation-header and RFC 8594
+func (s *ToDoService) legacyHandler(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Header().Set("Deprecation", "true")
+		if !s.legacySunset.IsZero() {
+			resp.Header().Set("Sunset", s.legacySunset.UTC().Format(http.TimeFormat))
+		}
+		resp.Header().Set("Link", "<"+prefix+req.URL.Path+`>; rel="successor-version"`)
+		next.ServeHTTP(resp, req)
+	})
+}
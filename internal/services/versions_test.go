@@ -0,0 +1,44 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedRoutes(t *testing.T) {
+	requireTest := require.New(t)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewToDoService(testJWTKey, ":6000", new(storages.StoreMock), WithLegacySunset(sunset),
+		WithCookieAuth(CookieAuth{}))
+
+	w := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/v1/tasks", nil))
+	requireTest.Equal(http.StatusUnauthorized, w.Code)
+	requireTest.Empty(w.Header().Get("Deprecation"))
+
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/tasks", nil))
+	requireTest.Equal(http.StatusUnauthorized, w.Code)
+	requireTest.Equal("true", w.Header().Get("Deprecation"))
+	requireTest.Equal("Fri, 01 Jan 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	requireTest.Equal(`</v1/tasks>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	requireTest.Empty(w.Header().Get("Deprecation"))
+
+	w = httptest.NewRecorder()
+	mountVersion("/v1", http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requireTest.NoError(s.setTokenCookies(resp, req, "access", "refresh"))
+	})).ServeHTTP(w, httptest.NewRequest("POST", "/v1/auth/login", nil))
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == refreshCookie {
+			requireTest.Equal("/v1/auth/", cookie.Path)
+		}
+	}
+}
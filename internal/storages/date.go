@@ -0,0 +1,16 @@
+package storages
+
+import "time"
+
+// DayRange returns [start, end) of the UTC day containing t,
+// it's used by storages which can not compare dates natively
+func DayRange(t time.Time) (time.Time, time.Time) {
+	return DayRangeIn(t, time.UTC)
+}
+
+// DayRangeIn returns [start, end) of the day containing t in loc, days across DST changes aren't 24 hours long
+func DayRangeIn(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	from := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return from, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,211 @@
+package dualwrite
+
+import (
+	"context"
+	"fmt"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/storages"
+	"go.uber.org/zap"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DualWrite is a composite storage used to migrate data stores online:
+// reads are served by primary, writes go to primary then secondary.
+// Secondary failures are logged and never surface to callers.
+// Only Store and HealthChecker are implemented, Wrap forwards the optional interfaces to primary
+type DualWrite struct {
+	primary   storages.StoreCloser
+	secondary storages.StoreCloser
+
+	consistencyCheck bool
+	mismatches       uint64
+}
+
+// NewDualWrite create new DualWrite instance, when consistencyCheck is on
+// every read is also served by secondary and compared against primary
+func NewDualWrite(primary, secondary storages.StoreCloser, consistencyCheck bool) *DualWrite {
+	return &DualWrite{
+		primary:          primary,
+		secondary:        secondary,
+		consistencyCheck: consistencyCheck,
+	}
+}
+
+// Wrap wraps primary and secondary into DualWrite along with the optional interfaces of primary it forwards,
+// see forwarded. Primary is wrapped into DualWrite alone unless it implements all of them
+func Wrap(primary, secondary storages.StoreCloser, consistencyCheck bool) storages.StoreCloser {
+	d := NewDualWrite(primary, secondary, consistencyCheck)
+	all, ok := primary.(forwarded)
+	if !ok {
+		return d
+	}
+	f := &forwarding{DualWrite: d, forwarded: all}
+	if pooled, ok := primary.(pooled); ok {
+		return &pooledForwarding{forwarding: f, pooled: pooled}
+	}
+	return f
+}
+
+// optional are the optional interfaces of storages, Dropped tells which of them Wrap hides
+var optional = []reflect.Type{
+	reflect.TypeOf((*storages.TaskFinder)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskStreamer)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskGetter)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskArchiver)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskMetadataUpdater)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskTagger)(nil)).Elem(),
+	reflect.TypeOf((*storages.ProjectStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskChecklist)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskCommenter)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskAttacher)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskTrasher)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskRecurrer)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskPositioner)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskBatchInserter)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskBulkUpdater)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskTemplater)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskReminder)(nil)).Elem(),
+	reflect.TypeOf((*storages.UserCreator)(nil)).Elem(),
+	reflect.TypeOf((*storages.RefreshTokenStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.PasswordStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.LoginAttemptStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.PoolStater)(nil)).Elem(),
+	reflect.TypeOf((*storages.StatementTimer)(nil)).Elem(),
+	reflect.TypeOf((*storages.IdempotencyStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.IdentityStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.UserAdmin)(nil)).Elem(),
+	reflect.TypeOf((*storages.QuotaStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.QuotaResetter)(nil)).Elem(),
+	reflect.TypeOf((*storages.PlanStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.UsageStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.StatsStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.TimezoneStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.SessionStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.TwoFactorStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.ShareStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.AuditStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskAuditStore)(nil)).Elem(),
+	reflect.TypeOf((*storages.TaskUpdater)(nil)).Elem(),
+}
+
+// Dropped returns the names of the optional interfaces which primary implements and Wrap hides, the endpoints
+// relying on them answer as if the storage had no support for them
+func Dropped(primary storages.StoreCloser) []string {
+	wrapped := reflect.TypeOf(Wrap(primary, nil, false))
+	names := make([]string, 0)
+	for _, t := range optional {
+		if reflect.TypeOf(primary).Implements(t) && !wrapped.Implements(t) {
+			names = append(names, t.Name())
+		}
+	}
+	return names
+}
+
+// Mismatches returns number of inconsistencies found between primary and secondary
+func (d *DualWrite) Mismatches() uint64 {
+	return atomic.LoadUint64(&d.mismatches)
+}
+
+func (d *DualWrite) ValidateUser(ctx context.Context, username, password string) (*storages.User, error) {
+	usr, err := d.primary.ValidateUser(ctx, username, password)
+	if !d.consistencyCheck {
+		return usr, err
+	}
+
+	secondaryUsr, secondaryErr := d.secondary.ValidateUser(ctx, username, password)
+	switch {
+	case err != secondaryErr:
+		d.mismatch(ctx, "ValidateUser", "primary err: %v, secondary err: %v", err, secondaryErr)
+	case err == nil && (usr.Username != secondaryUsr.Username || usr.MaxTodo != secondaryUsr.MaxTodo):
+		d.mismatch(ctx, "ValidateUser", "primary user: %+v, secondary user: %+v", usr, secondaryUsr)
+	}
+
+	return usr, err
+}
+
+func (d *DualWrite) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	tasks, err := d.primary.GetTasks(ctx, usrId, createAt)
+	if !d.consistencyCheck || err != nil {
+		return tasks, err
+	}
+
+	secondaryTasks, secondaryErr := d.secondary.GetTasks(ctx, usrId, createAt)
+	switch {
+	case secondaryErr != nil:
+		d.mismatch(ctx, "GetTasks", "secondary err: %v", secondaryErr)
+	case !sameContents(tasks, secondaryTasks):
+		d.mismatch(ctx, "GetTasks", "user %d has %d tasks in primary, %d tasks in secondary", usrId, len(tasks), len(secondaryTasks))
+	}
+
+	return tasks, nil
+}
+
+// InsertTask inserts task to primary, then a copy of it to secondary
+// since ids and creation times are assigned by each storage
+func (d *DualWrite) InsertTask(ctx context.Context, task *storages.Task) error {
+	if err := d.primary.InsertTask(ctx, task); err != nil {
+		return err
+	}
+
+	d.mirror(ctx, task)
+	return nil
+}
+
+// mirror inserts a copy of task inserted to primary to secondary
+func (d *DualWrite) mirror(ctx context.Context, task *storages.Task) {
+	copied := &storages.Task{UsrId: task.UsrId, Content: task.Content}
+	if err := d.secondary.InsertTask(ctx, copied); err != nil {
+		logging.FromContext(ctx).Warn("dualwrite InsertTask to secondary", zap.Error(err))
+		if err == storages.ErrQuotaExceeded {
+			d.mismatch(ctx, "InsertTask", "user %d daily-limit reached in secondary only", task.UsrId)
+		}
+	}
+}
+
+func (d *DualWrite) Close() error {
+	secondaryErr := d.secondary.Close()
+	if err := d.primary.Close(); err != nil {
+		return err
+	}
+	return secondaryErr
+}
+
+// CheckHealth checks the primary storage, the secondary one doesn't make callers fail
+func (d *DualWrite) CheckHealth(ctx context.Context) map[string]error {
+	if checker, ok := d.primary.(storages.HealthChecker); ok {
+		return checker.CheckHealth(ctx)
+	}
+	return nil
+}
+
+func (d *DualWrite) mismatch(ctx context.Context, op, format string, args ...interface{}) {
+	atomic.AddUint64(&d.mismatches, 1)
+	logging.FromContext(ctx).Warn("dualwrite mismatch", zap.String("op", op), zap.String("detail", fmt.Sprintf(format, args...)))
+}
+
+// sameContents compares tasks by content, ids and creation times are storage specific
+func sameContents(a, b []*storages.Task) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	contents := func(tasks []*storages.Task) []string {
+		res := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			res = append(res, task.Content)
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	ca, cb := contents(a), contents(b)
+	for i := range ca {
+		if ca[i] != cb[i] {
+			return false
+		}
+	}
+	return true
+}
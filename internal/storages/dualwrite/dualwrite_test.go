@@ -0,0 +1,152 @@
+package dualwrite
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type failingStore struct {
+	*memory.Memory
+}
+
+func (f *failingStore) InsertTask(context.Context, *storages.Task) error {
+	return errors.New("secondary is down")
+}
+
+func newMemory(t *testing.T) *memory.Memory {
+	m, err := memory.NewMemory()
+	require.NoError(t, err)
+	return m
+}
+
+func TestDualWriteInsertTask(t *testing.T) {
+	primary, secondary := newMemory(t), newMemory(t)
+	d := NewDualWrite(primary, secondary, true)
+	defer d.Close()
+
+	requireTest := require.New(t)
+	requireTest.NoError(d.InsertTask(context.Background(), &storages.Task{UsrId: 1, Content: "content"}))
+
+	for _, store := range []storages.Store{primary, secondary} {
+		tasks, err := store.GetTasks(context.Background(), 1, time.Now())
+		requireTest.NoError(err)
+		requireTest.Len(tasks, 1)
+	}
+
+	_, err := d.GetTasks(context.Background(), 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Zero(d.Mismatches())
+}
+
+func TestDualWriteSecondaryFailure(t *testing.T) {
+	primary := newMemory(t)
+	d := NewDualWrite(primary, &failingStore{Memory: newMemory(t)}, true)
+	defer d.Close()
+
+	requireTest := require.New(t)
+	requireTest.NoError(d.InsertTask(context.Background(), &storages.Task{UsrId: 1, Content: "content"}))
+
+	tasks, err := d.GetTasks(context.Background(), 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal(uint64(1), d.Mismatches())
+}
+
+// pooledStore is a storage implementing every optional interface Wrap forwards, memory has no pool
+type pooledStore struct {
+	*memory.Memory
+	stats storages.PoolStats
+}
+
+func (p *pooledStore) StreamTasks(ctx context.Context, usrId int, createAt time.Time, _ storages.TaskFilter, fn func(*storages.Task) error) error {
+	tasks, err := p.GetTasks(ctx, usrId, createAt)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pooledStore) PoolStats() storages.PoolStats {
+	return p.stats
+}
+
+func (p *pooledStore) TimeStatements(func(statement string, elapsed time.Duration)) {}
+
+func TestWrapForwards(t *testing.T) {
+	primary, secondary := &pooledStore{Memory: newMemory(t), stats: storages.PoolStats{Healthy: true, MaxConns: 4}}, newMemory(t)
+	d := Wrap(primary, secondary, false)
+	defer d.Close()
+	ctx := context.Background()
+
+	requireTest := require.New(t)
+	stater, ok := d.(storages.PoolStater)
+	requireTest.True(ok)
+	requireTest.Equal(primary.stats, stater.PoolStats())
+	_, ok = d.(storages.TaskStreamer)
+	requireTest.True(ok)
+
+	// writes which aren't mirrored reach primary alone
+	creator, ok := d.(storages.UserCreator)
+	requireTest.True(ok)
+	usr := &storages.User{Username: "secondUser", MaxTodo: 5, Role: storages.RoleUser}
+	requireTest.NoError(creator.CreateUser(ctx, usr))
+	_, err := primary.GetUser(ctx, usr.Id)
+	requireTest.NoError(err)
+	_, err = secondary.GetUser(ctx, usr.Id)
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	// tasks of batches are mirrored
+	inserter, ok := d.(storages.TaskBatchInserter)
+	requireTest.True(ok)
+	requireTest.NoError(inserter.InsertTasks(ctx, []*storages.Task{{UsrId: 1, Content: "one"}, {UsrId: 1, Content: "two"}}))
+	for _, store := range []storages.Store{primary, secondary} {
+		tasks, err := store.GetTasks(ctx, 1, time.Now())
+		requireTest.NoError(err)
+		requireTest.Len(tasks, 2)
+	}
+	requireTest.Empty(Dropped(primary))
+}
+
+func TestWrapWithoutPool(t *testing.T) {
+	requireTest := require.New(t)
+
+	d := Wrap(newMemory(t), newMemory(t), false)
+	defer d.Close()
+	_, ok := d.(storages.TaskUpdater)
+	requireTest.True(ok)
+	_, ok = d.(storages.RefreshTokenStore)
+	requireTest.True(ok)
+	_, ok = d.(storages.TaskStreamer)
+	requireTest.False(ok, "memory streams no tasks so exports fall back to listing them")
+	_, ok = d.(storages.HealthChecker)
+	requireTest.True(ok, "DualWrite checks the health of primary")
+	requireTest.Empty(Dropped(newMemory(t)))
+}
+
+func TestWrapPartial(t *testing.T) {
+	requireTest := require.New(t)
+
+	// only primaries implementing all of forwarded get them forwarded
+	primary := struct {
+		storages.StoreCloser
+		storages.LoginAttemptStore
+	}{newMemory(t), newMemory(t)}
+	d := Wrap(primary, newMemory(t), false)
+	defer d.Close()
+	_, ok := d.(storages.LoginAttemptStore)
+	requireTest.False(ok)
+	requireTest.Equal([]string{"LoginAttemptStore"}, Dropped(primary))
+
+	// a storage with no more than Store loses nothing
+	requireTest.Empty(Dropped(struct{ storages.StoreCloser }{newMemory(t)}))
+}
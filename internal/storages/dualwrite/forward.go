@@ -0,0 +1,78 @@
+package dualwrite
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+// forwarded are the optional interfaces which Wrap passes on to primary. Only the tasks of InsertTasks are
+// mirrored like those of InsertTask, other writes reach primary alone: mirroring them would need to map the ids
+// of primary to those of secondary as each storage assigns its own
+type forwarded interface {
+	storages.TaskFinder
+	storages.TaskGetter
+	storages.TaskArchiver
+	storages.TaskMetadataUpdater
+	storages.TaskTagger
+	storages.ProjectStore
+	storages.TaskChecklist
+	storages.TaskCommenter
+	storages.TaskAttacher
+	storages.TaskTrasher
+	storages.TaskRecurrer
+	storages.TaskPositioner
+	storages.TaskBatchInserter
+	storages.TaskBulkUpdater
+	storages.TaskTemplater
+	storages.TaskReminder
+	storages.UserCreator
+	storages.RefreshTokenStore
+	storages.PasswordStore
+	storages.LoginAttemptStore
+	storages.IdempotencyStore
+	storages.IdentityStore
+	storages.UserAdmin
+	storages.QuotaStore
+	storages.QuotaResetter
+	storages.PlanStore
+	storages.UsageStore
+	storages.StatsStore
+	storages.TimezoneStore
+	storages.SessionStore
+	storages.TwoFactorStore
+	storages.ShareStore
+	storages.AuditStore
+	storages.TaskAuditStore
+	storages.TaskUpdater
+}
+
+// pooled are the optional interfaces of storages on a connection pool which Wrap also passes on to primary
+type pooled interface {
+	storages.TaskStreamer
+	storages.PoolStater
+	storages.StatementTimer
+}
+
+// forwarding is DualWrite of a primary storage which implements forwarded
+type forwarding struct {
+	*DualWrite
+	forwarded
+}
+
+// InsertTasks inserts tasks to primary, then copies of them to secondary one by one
+func (f *forwarding) InsertTasks(ctx context.Context, tasks []*storages.Task) error {
+	if err := f.forwarded.InsertTasks(ctx, tasks); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		f.mirror(ctx, task)
+	}
+	return nil
+}
+
+// pooledForwarding is DualWrite of a primary storage which implements forwarded and pooled
+type pooledForwarding struct {
+	*forwarding
+	pooled
+}
@@ -0,0 +1,40 @@
+package dynamo
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"net/url"
+)
+
+func init() {
+	storages.Register("dynamo", func(ctx context.Context, dsn string) (storages.StoreCloser, error) {
+		config, err := configFromURL(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return NewDynamo(ctx, config)
+	})
+}
+
+// configFromURL creates config from url in format
+// dynamodb://region?endpoint=http://localhost:8000&table_prefix=togo_
+func configFromURL(rawURL string) (*Config, error) {
+	if rawURL == "" {
+		rawURL = "dynamodb://ap-southeast-1?table_prefix=togo_"
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parse()")
+	}
+	if u.Scheme != "dynamodb" {
+		return nil, errors.Errorf("invalid dynamodb url scheme %q", u.Scheme)
+	}
+
+	return &Config{
+		Region:      u.Host,
+		Endpoint:    u.Query().Get("endpoint"),
+		TablePrefix: u.Query().Get("table_prefix"),
+	}, nil
+}
@@ -0,0 +1,17 @@
+package dynamo
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConfigFromURL(t *testing.T) {
+	requireTest := require.New(t)
+
+	config, err := configFromURL("dynamodb://us-east-1?endpoint=http://localhost:8000&table_prefix=test_")
+	requireTest.NoError(err)
+	requireTest.Equal(&Config{Region: "us-east-1", Endpoint: "http://localhost:8000", TablePrefix: "test_"}, config)
+
+	_, err = configFromURL("redis://us-east-1")
+	requireTest.Error(err)
+}
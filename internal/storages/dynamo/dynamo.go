@@ -0,0 +1,356 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	dateLayout    = "2006-01-02"
+	usernameIndex = "username-index"
+)
+
+type Config struct {
+	Region string
+	// Endpoint overrides AWS endpoint, e.g. http://localhost:8000 for DynamoDB Local
+	Endpoint string
+	// TablePrefix is prepended to every table name
+	TablePrefix string
+}
+
+// usrItem reflects items of usr table, partition key is id
+type usrItem struct {
+	Id       int    `dynamodbav:"id"`
+	Username string `dynamodbav:"username"`
+	PwdHash  string `dynamodbav:"pwd_hash"`
+	MaxTodo  int    `dynamodbav:"max_todo"`
+}
+
+// taskItem reflects items of task table, partition key is usr_id and
+// sort key is create_at in unix nanoseconds
+type taskItem struct {
+	UsrId    int    `dynamodbav:"usr_id"`
+	CreateAt int64  `dynamodbav:"create_at"`
+	Id       int    `dynamodbav:"id"`
+	Content  string `dynamodbav:"content"`
+}
+
+// Dynamo represents a database instance for working with DynamoDB
+type Dynamo struct {
+	db           *dynamodb.DynamoDB
+	usrTable     string
+	taskTable    string
+	counterTable string
+}
+
+// NewDynamo create new Dynamo instance, missing tables are created on demand
+func NewDynamo(ctx context.Context, config *Config) (*Dynamo, error) {
+	awsConfig := aws.NewConfig().WithRegion(config.Region)
+	if config.Endpoint != "" {
+		awsConfig = awsConfig.WithEndpoint(config.Endpoint)
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewSession()")
+	}
+
+	d := &Dynamo{
+		db:           dynamodb.New(sess),
+		usrTable:     config.TablePrefix + "usr",
+		taskTable:    config.TablePrefix + "task",
+		counterTable: config.TablePrefix + "counter",
+	}
+
+	if err := d.init(ctx); err != nil {
+		return nil, errors.Wrap(err, "init()")
+	}
+
+	return d, nil
+}
+
+// init creates tables, demo data is only inserted by Seed
+func (d *Dynamo) init(ctx context.Context) error {
+	tables := []*dynamodb.CreateTableInput{
+		{
+			TableName: aws.String(d.usrTable),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeN)},
+				{AttributeName: aws.String("username"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+			},
+			GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{
+				{
+					IndexName: aws.String(usernameIndex),
+					KeySchema: []*dynamodb.KeySchemaElement{
+						{AttributeName: aws.String("username"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+					},
+					Projection: &dynamodb.Projection{ProjectionType: aws.String(dynamodb.ProjectionTypeAll)},
+				},
+			},
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		},
+		{
+			TableName: aws.String(d.taskTable),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("usr_id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeN)},
+				{AttributeName: aws.String("create_at"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeN)},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("usr_id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+				{AttributeName: aws.String("create_at"), KeyType: aws.String(dynamodb.KeyTypeRange)},
+			},
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		},
+		{
+			// counter table keeps task id sequence and per user per day task counts
+			TableName: aws.String(d.counterTable),
+			AttributeDefinitions: []*dynamodb.AttributeDefinition{
+				{AttributeName: aws.String("id"), AttributeType: aws.String(dynamodb.ScalarAttributeTypeS)},
+			},
+			KeySchema: []*dynamodb.KeySchemaElement{
+				{AttributeName: aws.String("id"), KeyType: aws.String(dynamodb.KeyTypeHash)},
+			},
+			BillingMode: aws.String(dynamodb.BillingModePayPerRequest),
+		},
+	}
+
+	for _, table := range tables {
+		_, err := d.db.CreateTableWithContext(ctx, table)
+		if err != nil {
+			if e, ok := err.(awserr.Error); ok && e.Code() == dynamodb.ErrCodeResourceInUseException {
+				continue
+			}
+			return errors.Wrap(err, "CreateTableWithContext()")
+		}
+
+		err = d.db.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{TableName: table.TableName})
+		if err != nil {
+			return errors.Wrap(err, "WaitUntilTableExistsWithContext()")
+		}
+	}
+
+	return nil
+}
+
+// ValidateUser returns user if match username AND password
+func (d *Dynamo) ValidateUser(ctx context.Context, username, pwd string) (*storages.User, error) {
+	out, err := d.db.QueryWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.usrTable),
+		IndexName:              aws.String(usernameIndex),
+		KeyConditionExpression: aws.String("username = :username"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":username": {S: aws.String(username)},
+		},
+		Limit: aws.Int64(1),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "QueryWithContext()")
+	}
+	if len(out.Items) == 0 {
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
+	}
+
+	usr := &usrItem{}
+	if err := dynamodbattribute.UnmarshalMap(out.Items[0], usr); err != nil {
+		return nil, errors.Wrap(err, "UnmarshalMap()")
+	}
+
+	if !password.Compare(usr.PwdHash, pwd) {
+		return nil, storages.ErrInvalidCredentials
+	}
+
+	validated := &storages.User{Id: usr.Id, Username: usr.Username, PwdHash: usr.PwdHash, MaxTodo: usr.MaxTodo}
+	if password.NeedsRehash(validated.PwdHash) {
+		d.rehash(ctx, validated, pwd)
+	}
+	return validated, nil
+}
+
+// rehash replaces the legacy hash of usr, a failure only postpones it to the next login
+func (d *Dynamo) rehash(ctx context.Context, usr *storages.User, pwd string) {
+	pwdHash, err := password.Hash(pwd)
+	if err == nil {
+		_, err = d.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName:           aws.String(d.usrTable),
+			Key:                 map[string]*dynamodb.AttributeValue{"id": {N: aws.String(strconv.Itoa(usr.Id))}},
+			UpdateExpression:    aws.String("SET pwd_hash = :new"),
+			ConditionExpression: aws.String("pwd_hash = :old"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":new": {S: aws.String(pwdHash)},
+				":old": {S: aws.String(usr.PwdHash)},
+			},
+		})
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("rehashing password", zap.Int("user_id", usr.Id), zap.Error(err))
+		return
+	}
+	usr.PwdHash = pwdHash
+}
+
+// GetTasks returns tasks of the user which were created on the date of createAt
+func (d *Dynamo) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	from, to := storages.DayRange(createAt)
+
+	var unmarshalErr error
+	tasks := make([]*storages.Task, 0)
+	err := d.db.QueryPagesWithContext(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(d.taskTable),
+		KeyConditionExpression: aws.String("usr_id = :usr_id AND create_at BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":usr_id": {N: aws.String(strconv.Itoa(usrId))},
+			":from":   {N: aws.String(strconv.FormatInt(from.UnixNano(), 10))},
+			":to":     {N: aws.String(strconv.FormatInt(to.UnixNano()-1, 10))},
+		},
+	}, func(out *dynamodb.QueryOutput, _ bool) bool {
+		for _, item := range out.Items {
+			task := &taskItem{}
+			if unmarshalErr = dynamodbattribute.UnmarshalMap(item, task); unmarshalErr != nil {
+				return false
+			}
+			tasks = append(tasks, &storages.Task{
+				Id:       task.Id,
+				UsrId:    task.UsrId,
+				Content:  task.Content,
+				CreateAt: time.Unix(0, task.CreateAt).UTC(),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "QueryPagesWithContext()")
+	}
+	if unmarshalErr != nil {
+		return nil, errors.Wrap(unmarshalErr, "UnmarshalMap()")
+	}
+
+	return tasks, nil
+}
+
+// InsertTask inserts task if the user's daily-limit has not been reached yet.
+// The daily counter update is conditioned on the limit and written in the same
+// transaction as the task, so the quota is enforced atomically
+func (d *Dynamo) InsertTask(ctx context.Context, task *storages.Task) error {
+	out, err := d.db.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(d.usrTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {N: aws.String(strconv.Itoa(task.UsrId))},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "GetItemWithContext()")
+	}
+	if out.Item == nil {
+		return storages.ErrNotFound
+	}
+
+	usr := &usrItem{}
+	if err := dynamodbattribute.UnmarshalMap(out.Item, usr); err != nil {
+		return errors.Wrap(err, "UnmarshalMap()")
+	}
+
+	id, err := d.nextSeq(ctx, "task")
+	if err != nil {
+		return err
+	}
+
+	task.Id = id
+	task.CreateAt = time.Now().UTC()
+	item, err := dynamodbattribute.MarshalMap(&taskItem{
+		UsrId:    task.UsrId,
+		CreateAt: task.CreateAt.UnixNano(),
+		Id:       task.Id,
+		Content:  task.Content,
+	})
+	if err != nil {
+		return errors.Wrap(err, "MarshalMap()")
+	}
+
+	quotaId := fmt.Sprintf("quota#%d#%s", task.UsrId, task.CreateAt.Format(dateLayout))
+	_, err = d.db.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					TableName: aws.String(d.counterTable),
+					Key: map[string]*dynamodb.AttributeValue{
+						"id": {S: aws.String(quotaId)},
+					},
+					UpdateExpression:    aws.String("SET cnt = if_not_exists(cnt, :zero) + :one"),
+					ConditionExpression: aws.String("attribute_not_exists(cnt) OR cnt < :max"),
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":zero": {N: aws.String("0")},
+						":one":  {N: aws.String("1")},
+						":max":  {N: aws.String(strconv.Itoa(storages.TodoLimit(usr.MaxTodo)))},
+					},
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName:           aws.String(d.taskTable),
+					Item:                item,
+					ConditionExpression: aws.String("attribute_not_exists(usr_id)"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		if e, ok := err.(*dynamodb.TransactionCanceledException); ok &&
+			len(e.CancellationReasons) > 0 &&
+			aws.StringValue(e.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return storages.ErrQuotaExceeded
+		}
+		return errors.Wrap(err, "TransactWriteItemsWithContext()")
+	}
+
+	return nil
+}
+
+// nextSeq returns the next value of the named sequence
+func (d *Dynamo) nextSeq(ctx context.Context, name string) (int, error) {
+	out, err := d.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(d.counterTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String("seq#" + name)},
+		},
+		UpdateExpression: aws.String("ADD cnt :one"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {N: aws.String("1")},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "UpdateItemWithContext()")
+	}
+
+	seq, err := strconv.Atoi(aws.StringValue(out.Attributes["cnt"].N))
+	if err != nil {
+		return 0, errors.Wrap(err, "Atoi()")
+	}
+	return seq, nil
+}
+
+// Close does nothing, DynamoDB client is stateless
+func (d *Dynamo) Close() error {
+	return nil
+}
+
+func isConditionalCheckFailed(err error) bool {
+	e, ok := err.(awserr.Error)
+	return ok && e.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
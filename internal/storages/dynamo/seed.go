@@ -0,0 +1,107 @@
+package dynamo
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// Seed puts fixtures, items with existing ids or usernames are left as is. Zero ids are taken from the
+// sequences which are moved past explicit ids so generated ones don't collide with them.
+// DynamoDB has no roles, tags or statuses so those of fixtures are left out
+func (d *Dynamo) Seed(ctx context.Context, fixtures *storages.Fixtures) error {
+	for _, usr := range fixtures.Users {
+		out, err := d.db.QueryWithContext(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(d.usrTable),
+			IndexName:              aws.String(usernameIndex),
+			KeyConditionExpression: aws.String("username = :username"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":username": {S: aws.String(usr.Username)},
+			},
+			Limit: aws.Int64(1),
+		})
+		if err != nil {
+			return errors.Wrap(err, "QueryWithContext()")
+		}
+		if len(out.Items) > 0 {
+			continue
+		}
+
+		pwdHash, err := password.Hash(usr.Password)
+		if err != nil {
+			return err
+		}
+		id, err := d.seedId(ctx, "usr", usr.Id)
+		if err != nil {
+			return err
+		}
+		item, err := dynamodbattribute.MarshalMap(&usrItem{Id: id, Username: usr.Username, PwdHash: pwdHash, MaxTodo: usr.MaxTodo})
+		if err != nil {
+			return errors.Wrap(err, "MarshalMap()")
+		}
+		if err := d.putNew(ctx, d.usrTable, item, "attribute_not_exists(id)"); err != nil {
+			return err
+		}
+	}
+
+	for _, task := range fixtures.Tasks {
+		id, err := d.seedId(ctx, "task", task.Id)
+		if err != nil {
+			return err
+		}
+		item, err := dynamodbattribute.MarshalMap(&taskItem{
+			UsrId:    task.UsrId,
+			CreateAt: task.CreateAt.UnixNano(),
+			Id:       id,
+			Content:  task.Content,
+		})
+		if err != nil {
+			return errors.Wrap(err, "MarshalMap()")
+		}
+		if err := d.putNew(ctx, d.taskTable, item, "attribute_not_exists(usr_id)"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedId returns id after moving the named sequence past it, the next value of the sequence when id is zero
+func (d *Dynamo) seedId(ctx context.Context, name string, id int) (int, error) {
+	if id <= 0 {
+		return d.nextSeq(ctx, name)
+	}
+	_, err := d.db.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(d.counterTable),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String("seq#" + name)},
+		},
+		UpdateExpression:    aws.String("SET cnt = :id"),
+		ConditionExpression: aws.String("attribute_not_exists(cnt) OR cnt < :id"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":id": {N: aws.String(strconv.Itoa(id))},
+		},
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		return 0, errors.Wrap(err, "UpdateItemWithContext()")
+	}
+	return id, nil
+}
+
+// putNew puts item into table unless condition fails, i.e. the item exists
+func (d *Dynamo) putNew(ctx context.Context, table string, item map[string]*dynamodb.AttributeValue, condition string) error {
+	_, err := d.db.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(table),
+		Item:                item,
+		ConditionExpression: aws.String(condition),
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		return errors.Wrap(err, "PutItemWithContext()")
+	}
+	return nil
+}
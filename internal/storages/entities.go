@@ -1,13 +1,145 @@
 package storages
 
-import "time"
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 // User reflects tasks in DB
 type User struct {
 	Id       int
 	Username string
 	PwdHash  string
-	MaxTodo  int
+	// MaxTodo is the daily-limit of the user, the one of Plan while they're on a plan
+	MaxTodo int
+	// Plan is the name of the plan of the user, empty for none. Storages without plans leave it empty
+	Plan string
+	// Role is RoleUser unless the user administrates the service, storages without roles leave it empty
+	Role Role
+	// Timezone is the IANA name of the timezone days of the user are counted in, UTC when empty
+	Timezone string
+}
+
+// Location returns the timezone of the user, UTC for unknown ones
+func (u *User) Location() *time.Location {
+	if loc, err := time.LoadLocation(u.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// Role tells what a user may do
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
+// UserPatch changes the fields of a user which are given
+type UserPatch struct {
+	Role    *Role `json:"role,omitempty"`
+	MaxTodo *int  `json:"max_todo,omitempty"`
+}
+
+// Validate returns ErrInvalidUser for unknown roles and negative daily-limits
+func (p *UserPatch) Validate() error {
+	if p.Role != nil && !p.Role.Valid() {
+		return ErrInvalidUser
+	}
+	if p.MaxTodo != nil && *p.MaxTodo < 0 {
+		return ErrInvalidUser
+	}
+	return nil
+}
+
+const (
+	// MinUsernameLen and MaxUsernameLen bound usernames in bytes
+	MinUsernameLen = 3
+	MaxUsernameLen = 36
+	// DefaultMaxTodo is the daily-limit of new users
+	DefaultMaxTodo = 5
+	// UnlimitedTodo is the daily-limit of users who may add any number of tasks a day
+	UnlimitedTodo = 0
+)
+
+// Quota is the daily-limit of a user and how many tasks they added within the window of the QuotaPolicy,
+// the whole limit is available again at ResetAt. Burst is how many more tasks the Burst of the storage lets
+// them add over the limit
+type Quota struct {
+	MaxTodo int
+	Used    int
+	ResetAt time.Time
+	Burst   int
+}
+
+// Plan is a tier of users who share its daily-limit rather than having their own
+type Plan struct {
+	Name    string
+	MaxTodo int
+}
+
+// DefaultPlans are the plans storages are created with, users aren't on any until admins put them on one
+func DefaultPlans() []*Plan {
+	return []*Plan{
+		{Name: "free", MaxTodo: DefaultMaxTodo},
+		{Name: "pro", MaxTodo: 50},
+	}
+}
+
+// Usage is how many tasks a user added on Day, the date in the timezone of the user at midnight UTC
+type Usage struct {
+	Day   time.Time
+	Tasks int
+}
+
+// DayStats is how many tasks a user created and completed on a day
+type DayStats struct {
+	Day       time.Time
+	Created   int
+	Completed int
+}
+
+// Remaining is how many more tasks the user may add on the day, math.MaxInt32 for unlimited users
+func (q *Quota) Remaining() int {
+	if remaining := TodoLimit(q.MaxTodo) - q.Used; remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// TodoLimit is how many tasks a day the daily-limit maxTodo allows, UnlimitedTodo allows math.MaxInt32 ones
+func TodoLimit(maxTodo int) int {
+	if maxTodo == UnlimitedTodo {
+		return math.MaxInt32
+	}
+	return maxTodo
+}
+
+// NormalizeUsername trims Username, it returns ErrInvalidUser unless it has MinUsernameLen
+// to MaxUsernameLen ASCII letters, digits, dots, dashes and underscores
+func (u *User) NormalizeUsername() error {
+	u.Username = strings.TrimSpace(u.Username)
+	if len(u.Username) < MinUsernameLen || len(u.Username) > MaxUsernameLen {
+		return ErrInvalidUser
+	}
+	for _, r := range u.Username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+		default:
+			return ErrInvalidUser
+		}
+	}
+	return nil
 }
 
 // Task reflects tasks in DB
@@ -16,6 +148,607 @@ type Task struct {
 	UsrId    int       `json:"usr_id"`
 	Content  string    `json:"content"`
 	CreateAt time.Time `json:"create_at"`
+	// ContentFormat tells how Content is displayed, ContentHTML is only rendered on request and not stored
+	ContentFormat ContentFormat `json:"content_format,omitempty"`
+	ContentHTML   string        `json:"content_html,omitempty"`
+	// DeletedAt and ArchivedAt are set once the task is soft deleted or archived
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+
+	Status TaskStatus `json:"status"`
+	DueAt  *time.Time `json:"due_at,omitempty"`
+	// Priority orders tasks, higher is more important
+	Priority int `json:"priority"`
+	// CompletedAt is set while Status is TaskStatusDone
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Version is increased by every change, updates must give the version they are based on.
+	// UpdateAt is when the task was last changed, it's nil until then and left out by storages which don't keep it
+	Version  int        `json:"version"`
+	UpdateAt *time.Time `json:"update_at,omitempty"`
+
+	// Tags are sorted and normalized by NormalizeTags
+	Tags []string `json:"tags,omitempty"`
+
+	// ProjectId is the project of the task, it's given on creation only
+	ProjectId *int `json:"project_id,omitempty"`
+
+	// Checklist rolls up checklist items of the task, it's nil when the task has none
+	Checklist *Checklist `json:"checklist,omitempty"`
+
+	// Recurrence is given on creation only. RecurAt is when the next occurrence is created
+	// unless the task is done earlier, it's nil once the next occurrence has been created
+	Recurrence TaskRecurrence `json:"recurrence,omitempty"`
+	RecurAt    *time.Time     `json:"recur_at,omitempty"`
+
+	// Position is the manual order of tasks of the user, new tasks get DefaultPosition
+	Position float64 `json:"position"`
+
+	// RemindAt is when the user is notified about the task, RemindedAt is set once it's delivered
+	// and cleared whenever RemindAt changes
+	RemindAt   *time.Time `json:"remind_at,omitempty"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty"`
+}
+
+// ContentFormat is the markup of task contents
+type ContentFormat string
+
+const (
+	ContentFormatPlain    ContentFormat = "plain"
+	ContentFormatMarkdown ContentFormat = "markdown"
+)
+
+// Valid reports whether f is empty or a known format
+func (f ContentFormat) Valid() bool {
+	switch f {
+	case "", ContentFormatPlain, ContentFormatMarkdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeContent defaults the format to plain, strips invalid UTF-8 and control characters
+// but newlines and tabs from the content and drops any given ContentHTML,
+// it returns ErrInvalidTask for unknown formats
+func (t *Task) NormalizeContent() error {
+	if !t.ContentFormat.Valid() {
+		return ErrInvalidTask
+	}
+	if t.ContentFormat == "" {
+		t.ContentFormat = ContentFormatPlain
+	}
+	t.ContentHTML = ""
+	t.Content = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (unicode.IsControl(r) && r != '\n' && r != '\t') {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(t.Content, ""))
+	return nil
+}
+
+// PositionGap separates positions of tasks which are renumbered or moved to an end of a list
+const PositionGap = 1024.0
+
+// DefaultPosition places a task created at createAt after older tasks
+func DefaultPosition(createAt time.Time) float64 {
+	return float64(createAt.UnixNano() / int64(time.Millisecond))
+}
+
+// PositionBetween returns a position after lo and before hi, nil bounds are open ends.
+// It returns ErrConflict if lo is after hi and ok is false when there is no position
+// left between them, e.g. for tasks created at the same time, so positions have to be renumbered
+func PositionBetween(lo, hi *float64) (position float64, ok bool, err error) {
+	switch {
+	case lo == nil && hi == nil:
+		return 0, false, ErrInvalidTask
+	case hi == nil:
+		return *lo + PositionGap, true, nil
+	case lo == nil:
+		return *hi - PositionGap, true, nil
+	case *lo > *hi:
+		return 0, false, ErrConflict
+	}
+
+	position = *lo + (*hi-*lo)/2
+	return position, position > *lo && position < *hi, nil
+}
+
+// TaskRecurrence repeats a task, empty means the task does not recur
+type TaskRecurrence string
+
+const (
+	RecurrenceDaily   TaskRecurrence = "daily"
+	RecurrenceWeekly  TaskRecurrence = "weekly"
+	RecurrenceMonthly TaskRecurrence = "monthly"
+)
+
+// Valid reports whether r is empty or a known recurrence
+func (r TaskRecurrence) Valid() bool {
+	switch r {
+	case "", RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next returns the occurrence following t, months are added like time.AddDate does
+func (r TaskRecurrence) Next(t time.Time) time.Time {
+	switch r {
+	case RecurrenceDaily:
+		return t.AddDate(0, 0, 1)
+	case RecurrenceWeekly:
+		return t.AddDate(0, 0, 7)
+	case RecurrenceMonthly:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t
+	}
+}
+
+// InitRecurrence sets RecurAt of a new task from its CreateAt,
+// it returns ErrInvalidTask for unknown recurrences
+func (t *Task) InitRecurrence() error {
+	if !t.Recurrence.Valid() {
+		return ErrInvalidTask
+	}
+	t.RecurAt = nil
+	if t.Recurrence != "" {
+		recurAt := t.Recurrence.Next(t.CreateAt)
+		t.RecurAt = &recurAt
+	}
+	return nil
+}
+
+// RecurDue reports whether the next occurrence of the task should be created by now
+func (t *Task) RecurDue(now time.Time) bool {
+	return t.RecurAt != nil && t.DeletedAt == nil && (!t.RecurAt.After(now) || t.Status == TaskStatusDone)
+}
+
+// NextOccurrence returns a new task which follows the recurring task, occurrences which
+// would have been created before the day of now are skipped and the due date keeps its
+// distance to the creation
+func (t *Task) NextOccurrence(now time.Time) *Task {
+	createAt := t.Recurrence.Next(t.CreateAt)
+	from, _ := DayRange(now)
+	for createAt.Before(from) {
+		createAt = t.Recurrence.Next(createAt)
+	}
+
+	next := &Task{
+		UsrId:         t.UsrId,
+		Content:       t.Content,
+		ContentFormat: t.ContentFormat,
+		CreateAt:      createAt,
+		Status:        TaskStatusTodo,
+		Priority:      t.Priority,
+		Version:       1,
+		Tags:          append([]string(nil), t.Tags...),
+		Recurrence:    t.Recurrence,
+		Position:      DefaultPosition(createAt),
+	}
+	if t.DueAt != nil {
+		dueAt := createAt.Add(t.DueAt.Sub(t.CreateAt))
+		next.DueAt = &dueAt
+	}
+	if t.RemindAt != nil {
+		remindAt := createAt.Add(t.RemindAt.Sub(t.CreateAt))
+		next.RemindAt = &remindAt
+	}
+	if t.ProjectId != nil {
+		projectId := *t.ProjectId
+		next.ProjectId = &projectId
+	}
+	recurAt := t.Recurrence.Next(createAt)
+	next.RecurAt = &recurAt
+	return next
+}
+
+// ChecklistItem is a subtask of a task, items are ordered by Position
+type ChecklistItem struct {
+	Id       int       `json:"id"`
+	TaskId   int       `json:"task_id"`
+	Content  string    `json:"content"`
+	Position int       `json:"position"`
+	Done     bool      `json:"done"`
+	CreateAt time.Time `json:"create_at"`
+}
+
+// Normalize trims Content, it returns ErrInvalidTask for empty contents
+func (i *ChecklistItem) Normalize() error {
+	i.Content = strings.TrimSpace(i.Content)
+	if i.Content == "" {
+		return ErrInvalidTask
+	}
+	return nil
+}
+
+// SameIds reports whether ids holds each of current exactly once in any order
+func SameIds(current, ids []int) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	for _, id := range current {
+		if !seen[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Comment is a note on a task written by UsrId
+type Comment struct {
+	Id       int       `json:"id"`
+	TaskId   int       `json:"task_id"`
+	UsrId    int       `json:"usr_id"`
+	Content  string    `json:"content"`
+	CreateAt time.Time `json:"create_at"`
+	// ContentFormat tells how Content is displayed, ContentHTML is only rendered on request and not stored
+	ContentFormat ContentFormat `json:"content_format,omitempty"`
+	ContentHTML   string        `json:"content_html,omitempty"`
+}
+
+// MaxCommentLen is the longest comment in bytes
+const MaxCommentLen = 4096
+
+// Normalize trims Content, it returns ErrInvalidTask for empty or too long comments
+func (c *Comment) Normalize() error {
+	c.Content = strings.TrimSpace(c.Content)
+	if c.Content == "" || len(c.Content) > MaxCommentLen {
+		return ErrInvalidTask
+	}
+	return nil
+}
+
+// Attachment describes a file attached to a task, its content is kept in a blob store under BlobKey
+type Attachment struct {
+	Id          int       `json:"id"`
+	TaskId      int       `json:"task_id"`
+	UsrId       int       `json:"usr_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	BlobKey     string    `json:"-"`
+	CreateAt    time.Time `json:"create_at"`
+}
+
+// MaxAttachmentNameLen is the longest attachment name in bytes
+const MaxAttachmentNameLen = 255
+
+// Normalize trims Name, it returns ErrInvalidTask for empty or too long names
+func (a *Attachment) Normalize() error {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" || len(a.Name) > MaxAttachmentNameLen || a.BlobKey == "" {
+		return ErrInvalidTask
+	}
+	return nil
+}
+
+// Checklist counts checklist items of a task
+type Checklist struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
+}
+
+// NewChecklist rolls up items, it returns nil for no items
+func NewChecklist(items []*ChecklistItem) *Checklist {
+	if len(items) == 0 {
+		return nil
+	}
+	checklist := &Checklist{Total: len(items)}
+	for _, item := range items {
+		if item.Done {
+			checklist.Done++
+		}
+	}
+	return checklist
+}
+
+// Completed reports whether all items are done
+func (c *Checklist) Completed() bool {
+	return c.Done == c.Total
+}
+
+// Project groups tasks of a user
+type Project struct {
+	Id    int    `json:"id"`
+	UsrId int    `json:"usr_id"`
+	Name  string `json:"name"`
+	// MaxTodo limits tasks created in the project per day on top of the user's daily-limit, nil means no limit
+	MaxTodo  *int      `json:"max_todo,omitempty"`
+	CreateAt time.Time `json:"create_at"`
+}
+
+// Template is a saved set of tasks which can be instantiated again and again, e.g. a recurring checklist
+type Template struct {
+	Id       int            `json:"id"`
+	UsrId    int            `json:"usr_id"`
+	Name     string         `json:"name"`
+	Tasks    []TemplateTask `json:"tasks"`
+	CreateAt time.Time      `json:"create_at"`
+}
+
+// TemplateTask is a task of a template, Items are the contents of its checklist items
+type TemplateTask struct {
+	Content       string        `json:"content"`
+	ContentFormat ContentFormat `json:"content_format,omitempty"`
+	Priority      int           `json:"priority"`
+	Tags          []string      `json:"tags,omitempty"`
+	Items         []string      `json:"items,omitempty"`
+}
+
+// MaxTemplateTasks is how many tasks a template can hold
+const MaxTemplateTasks = 100
+
+// Normalize trims Name and checklist items and normalizes formats and tags, it returns ErrInvalidTask
+// for empty or too long names, no or too many tasks, unknown content formats and empty checklist items
+func (t *Template) Normalize() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" || len(t.Name) > MaxProjectNameLen {
+		return ErrInvalidTask
+	}
+	if len(t.Tasks) == 0 || len(t.Tasks) > MaxTemplateTasks {
+		return ErrInvalidTask
+	}
+	for i := range t.Tasks {
+		task := &t.Tasks[i]
+		if !task.ContentFormat.Valid() {
+			return ErrInvalidTask
+		}
+		if task.ContentFormat == "" {
+			task.ContentFormat = ContentFormatPlain
+		}
+		tags, err := NormalizeTags(task.Tags)
+		if err != nil {
+			return err
+		}
+		task.Tags = tags
+		for j, item := range task.Items {
+			if task.Items[j] = strings.TrimSpace(item); task.Items[j] == "" {
+				return ErrInvalidTask
+			}
+		}
+	}
+	return nil
+}
+
+// NewTemplateTask returns the template task saving task along with the contents of its checklist items
+func NewTemplateTask(task *Task, items []*ChecklistItem) TemplateTask {
+	templateTask := TemplateTask{
+		Content:       task.Content,
+		ContentFormat: task.ContentFormat,
+		Priority:      task.Priority,
+		Tags:          append([]string(nil), task.Tags...),
+	}
+	for _, item := range items {
+		templateTask.Items = append(templateTask.Items, item.Content)
+	}
+	return templateTask
+}
+
+// NewTask returns a new task of the user following the template task
+func (t TemplateTask) NewTask(usrId int) *Task {
+	return &Task{
+		UsrId:         usrId,
+		Content:       t.Content,
+		ContentFormat: t.ContentFormat,
+		Priority:      t.Priority,
+		Tags:          append([]string(nil), t.Tags...),
+	}
+}
+
+// MaxProjectNameLen is the longest project name in bytes
+const MaxProjectNameLen = 64
+
+// Normalize trims Name, it returns ErrInvalidProject for empty or too long names and negative MaxTodo
+func (p *Project) Normalize() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len(p.Name) > MaxProjectNameLen {
+		return ErrInvalidProject
+	}
+	if p.MaxTodo != nil && *p.MaxTodo < 0 {
+		return ErrInvalidProject
+	}
+	return nil
+}
+
+// MaxTagLen is the longest tag in bytes
+const MaxTagLen = 32
+
+// NormalizeTags trims, lowercases, sorts and dedupes tags,
+// it returns ErrInvalidTask for empty or too long tags
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > MaxTagLen {
+			return nil, ErrInvalidTask
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// TaskStatus is the progress of a task
+type TaskStatus string
+
+const (
+	TaskStatusTodo  TaskStatus = "todo"
+	TaskStatusDoing TaskStatus = "doing"
+	TaskStatusDone  TaskStatus = "done"
+)
+
+// Valid reports whether s is a known status
+func (s TaskStatus) Valid() bool {
+	switch s {
+	case TaskStatusTodo, TaskStatusDoing, TaskStatusDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetStatus sets Status and keeps CompletedAt consistent with it,
+// it returns ErrInvalidTask for unknown statuses
+func (t *Task) SetStatus(status TaskStatus, now time.Time) error {
+	if status == "" {
+		status = TaskStatusTodo
+	}
+	if !status.Valid() {
+		return ErrInvalidTask
+	}
+
+	switch {
+	case status != TaskStatusDone:
+		t.CompletedAt = nil
+	case t.CompletedAt == nil:
+		t.CompletedAt = &now
+	}
+	t.Status = status
+	return nil
+}
+
+// RefreshToken is a long-lived token traded for new access tokens, only the hash of its secret is stored.
+// Family is the hash of the first token of a chain of rotations
+type RefreshToken struct {
+	Id        int
+	UsrId     int
+	Hash      string
+	Family    string
+	CreateAt  time.Time
+	ExpiresAt time.Time
+	// UserAgent and IP tell the device which got the token
+	UserAgent string
+	IP        string
+}
+
+// Session is a login of a user on a device, i.e. a family of refresh tokens. Id is the id of the first
+// token of the family, LastUsedAt, ExpiresAt, UserAgent and IP are the ones of the latest token
+type Session struct {
+	Id         int       `json:"id"`
+	CreateAt   time.Time `json:"create_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+}
+
+// PasswordReset is a single-use token which lets a user set a new password without the current one,
+// only the hash of its secret is stored
+type PasswordReset struct {
+	Id        int
+	UsrId     int
+	Hash      string
+	CreateAt  time.Time
+	ExpiresAt time.Time
+}
+
+// IdempotentResponse is the response to a request of a user made with an idempotency key, retries of the request
+// with the same key are answered by it until ExpiresAt. RequestHash tells the request apart from other requests
+// reusing its key
+type IdempotentResponse struct {
+	UsrId       int
+	Key         string
+	RequestHash string
+	Status      int
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// TwoFactor is the TOTP enrollment of a user, logins of the user require a code once it's Enabled.
+// LastCounter is the period of the last accepted code so that no code is accepted twice. BackupCodes are
+// the hashes of the single-use codes, they are saved along with the enrollment and never returned
+type TwoFactor struct {
+	UsrId       int
+	Secret      string
+	Enabled     bool
+	LastCounter int64
+	BackupCodes []string
+}
+
+// Share lets the user UsrId read the tasks and projects of the owner, and also change them when CanWrite
+type Share struct {
+	OwnerId  int       `json:"-"`
+	UsrId    int       `json:"usr_id"`
+	CanWrite bool      `json:"can_write"`
+	CreateAt time.Time `json:"create_at"`
+}
+
+// AuditType tells what happened in an AuditEvent
+type AuditType string
+
+const (
+	AuditLogin             AuditType = "login"
+	AuditLoginFailed       AuditType = "login_failed"
+	AuditLogout            AuditType = "logout"
+	AuditPasswordChanged   AuditType = "password_changed"
+	AuditPasswordReset     AuditType = "password_reset"
+	AuditSessionRevoked    AuditType = "session_revoked"
+	AuditSessionsRevoked   AuditType = "sessions_revoked"
+	AuditTwoFactorEnabled  AuditType = "two_factor_enabled"
+	AuditTwoFactorDisabled AuditType = "two_factor_disabled"
+	AuditRoleChanged       AuditType = "role_changed"
+	AuditQuotaChanged      AuditType = "quota_changed"
+)
+
+// AuditEvent is an entry of the security audit log, entries are never changed nor deleted. ActorId is the user
+// who acted, 0 when it's not known such as for failed logins, and UsrId the user the event is about
+type AuditEvent struct {
+	Id        int       `json:"id"`
+	Type      AuditType `json:"type"`
+	ActorId   int       `json:"actor_id,omitempty"`
+	UsrId     int       `json:"usr_id,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Detail    string    `json:"detail,omitempty"`
+	CreateAt  time.Time `json:"create_at"`
+}
+
+// AuditFilter picks audit events, zero fields pick all of them. UsrId picks events the user did or is about
+type AuditFilter struct {
+	UsrId int
+	Type  AuditType
+	Since time.Time
+	Limit int
+}
+
+// TaskAction tells how a task was changed in a TaskAuditEntry
+type TaskAction string
+
+const (
+	TaskCreated   TaskAction = "created"
+	TaskUpdated   TaskAction = "updated"
+	TaskCompleted TaskAction = "completed"
+	TaskDeleted   TaskAction = "deleted"
+	TaskRestored  TaskAction = "restored"
+)
+
+// TaskAuditEntry is a change of a task of the user UsrId done by ActorId, who is someone else for tasks shared
+// with them. Before and After are the task as it was and became, Before is nil for created and restored tasks and
+// for tasks changed along with others at once, After is nil for deleted tasks. Entries are never changed nor deleted
+type TaskAuditEntry struct {
+	Id       int        `json:"id"`
+	TaskId   int        `json:"task_id"`
+	UsrId    int        `json:"usr_id"`
+	ActorId  int        `json:"actor_id"`
+	Action   TaskAction `json:"action"`
+	Before   *Task      `json:"before"`
+	After    *Task      `json:"after"`
+	CreateAt time.Time  `json:"create_at"`
 }
 
 // LEGACY CODE----------------------------
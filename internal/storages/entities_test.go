@@ -0,0 +1,140 @@
+package storages
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskSetStatus(t *testing.T) {
+	requireTest := require.New(t)
+
+	now := time.Now()
+	task := &Task{}
+	requireTest.NoError(task.SetStatus("", now))
+	requireTest.Equal(TaskStatusTodo, task.Status)
+	requireTest.Nil(task.CompletedAt)
+
+	requireTest.NoError(task.SetStatus(TaskStatusDone, now))
+	requireTest.Equal(now, *task.CompletedAt)
+
+	// completing again keeps the first completion time
+	requireTest.NoError(task.SetStatus(TaskStatusDone, now.Add(time.Hour)))
+	requireTest.Equal(now, *task.CompletedAt)
+
+	requireTest.NoError(task.SetStatus(TaskStatusDoing, now))
+	requireTest.Nil(task.CompletedAt)
+
+	requireTest.Equal(ErrInvalidTask, task.SetStatus("later", now))
+	requireTest.Equal(TaskStatusDoing, task.Status)
+}
+
+func TestNormalizeTags(t *testing.T) {
+	requireTest := require.New(t)
+
+	tags, err := NormalizeTags([]string{" Work", "home", "work "})
+	requireTest.NoError(err)
+	requireTest.Equal([]string{"home", "work"}, tags)
+
+	_, err = NormalizeTags([]string{" "})
+	requireTest.Equal(ErrInvalidTask, err)
+
+	_, err = NormalizeTags([]string{strings.Repeat("a", MaxTagLen+1)})
+	requireTest.Equal(ErrInvalidTask, err)
+}
+
+func TestSameIds(t *testing.T) {
+	requireTest := require.New(t)
+
+	requireTest.True(SameIds([]int{1, 2, 3}, []int{3, 1, 2}))
+	requireTest.True(SameIds(nil, []int{}))
+	requireTest.False(SameIds([]int{1, 2}, []int{1}))
+	requireTest.False(SameIds([]int{1, 2}, []int{1, 1}))
+	requireTest.False(SameIds([]int{1, 2}, []int{1, 3}))
+}
+
+func TestTaskNextOccurrence(t *testing.T) {
+	requireTest := require.New(t)
+
+	createAt := time.Date(2020, 6, 29, 9, 0, 0, 0, time.UTC)
+	dueAt, remindAt := createAt.Add(2*time.Hour), createAt.Add(time.Hour)
+	task := &Task{UsrId: 1, Content: "standup", CreateAt: createAt, DueAt: &dueAt, RemindAt: &remindAt, RemindedAt: &remindAt,
+		Recurrence: RecurrenceDaily, Tags: []string{"work"}}
+	requireTest.NoError(task.InitRecurrence())
+	requireTest.Equal(createAt.AddDate(0, 0, 1), *task.RecurAt)
+
+	requireTest.False(task.RecurDue(createAt))
+	requireTest.True(task.RecurDue(*task.RecurAt))
+	task.Status = TaskStatusDone
+	requireTest.True(task.RecurDue(createAt))
+
+	// missed days are skipped
+	now := time.Date(2020, 7, 3, 12, 0, 0, 0, time.UTC)
+	next := task.NextOccurrence(now)
+	requireTest.Equal(time.Date(2020, 7, 3, 9, 0, 0, 0, time.UTC), next.CreateAt)
+	requireTest.Equal(time.Date(2020, 7, 3, 11, 0, 0, 0, time.UTC), *next.DueAt)
+	requireTest.Equal(time.Date(2020, 7, 3, 10, 0, 0, 0, time.UTC), *next.RemindAt)
+	requireTest.Nil(next.RemindedAt)
+	requireTest.Equal(time.Date(2020, 7, 4, 9, 0, 0, 0, time.UTC), *next.RecurAt)
+	requireTest.Equal(TaskStatusTodo, next.Status)
+	requireTest.Equal([]string{"work"}, next.Tags)
+
+	requireTest.Equal(ErrInvalidTask, (&Task{Recurrence: "yearly"}).InitRecurrence())
+	requireTest.Equal(createAt.AddDate(0, 1, 0), RecurrenceMonthly.Next(createAt))
+}
+
+func TestPositionBetween(t *testing.T) {
+	requireTest := require.New(t)
+	lo, hi := 1.0, 2.0
+
+	position, ok, err := PositionBetween(&lo, &hi)
+	requireTest.NoError(err)
+	requireTest.True(ok)
+	requireTest.Equal(1.5, position)
+
+	position, _, err = PositionBetween(&lo, nil)
+	requireTest.NoError(err)
+	requireTest.Equal(lo+PositionGap, position)
+
+	position, _, err = PositionBetween(nil, &hi)
+	requireTest.NoError(err)
+	requireTest.Equal(hi-PositionGap, position)
+
+	_, _, err = PositionBetween(&hi, &lo)
+	requireTest.Equal(ErrConflict, err)
+	_, _, err = PositionBetween(nil, nil)
+	requireTest.Equal(ErrInvalidTask, err)
+
+	// no float left between adjacent values
+	next := math.Nextafter(lo, hi)
+	_, ok, err = PositionBetween(&lo, &next)
+	requireTest.NoError(err)
+	requireTest.False(ok)
+}
+
+func TestTaskNormalizeContent(t *testing.T) {
+	task := &Task{Content: "milk\x00\x1b[31m\n\teggs\xff", ContentHTML: "<script>"}
+	require.NoError(t, task.NormalizeContent())
+	require.Equal(t, "milk[31m\n\teggs", task.Content)
+	require.Equal(t, ContentFormatPlain, task.ContentFormat)
+	require.Empty(t, task.ContentHTML)
+
+	task = &Task{Content: "# milk", ContentFormat: ContentFormatMarkdown}
+	require.NoError(t, task.NormalizeContent())
+	require.Equal(t, ContentFormatMarkdown, task.ContentFormat)
+
+	task = &Task{Content: "milk", ContentFormat: "html"}
+	require.Equal(t, ErrInvalidTask, task.NormalizeContent())
+}
+
+func TestUserNormalizeUsername(t *testing.T) {
+	usr := &User{Username: " first.User_1 "}
+	require.NoError(t, usr.NormalizeUsername())
+	require.Equal(t, "first.User_1", usr.Username)
+
+	for _, username := range []string{"ab", strings.Repeat("a", MaxUsernameLen+1), "first user", "usér"} {
+		require.Equal(t, ErrInvalidUser, (&User{Username: username}).NormalizeUsername(), username)
+	}
+}
@@ -0,0 +1,69 @@
+package storages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Config selects a storage backend, DSN format depends on the driver
+type Config struct {
+	Driver string
+	DSN    string
+}
+
+// StoreCloser is a Store which holds resources to be released
+type StoreCloser interface {
+	Store
+	io.Closer
+}
+
+// OpenFunc opens a storage from the given dsn, an empty dsn means driver's default
+type OpenFunc func(ctx context.Context, dsn string) (StoreCloser, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]OpenFunc)
+)
+
+// Register makes a storage driver available by the provided name,
+// it's meant to be called from init() of the driver package
+func Register(name string, open OpenFunc) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if open == nil {
+		panic("storages: Register open func is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("storages: Register called twice for driver " + name)
+	}
+	drivers[name] = open
+}
+
+// Drivers returns a sorted list of the names of the registered drivers
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens the storage of the configured driver
+func Open(ctx context.Context, config *Config) (StoreCloser, error) {
+	driversMu.RLock()
+	open, ok := drivers[config.Driver]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("storages: unknown driver %q (forgotten import?)", config.Driver)
+	}
+	return open(ctx, config.DSN)
+}
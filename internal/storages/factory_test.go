@@ -0,0 +1,40 @@
+package storages
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type closableStoreMock struct {
+	StoreMock
+	dsn string
+}
+
+func (m *closableStoreMock) Close() error {
+	return nil
+}
+
+func TestOpenRegisteredDriver(t *testing.T) {
+	Register("test", func(ctx context.Context, dsn string) (StoreCloser, error) {
+		return &closableStoreMock{dsn: dsn}, nil
+	})
+
+	requireTest := require.New(t)
+	requireTest.Contains(Drivers(), "test")
+
+	store, err := Open(context.Background(), &Config{Driver: "test", DSN: "test dsn"})
+	requireTest.NoError(err)
+	requireTest.Equal("test dsn", store.(*closableStoreMock).dsn)
+
+	requireTest.Panics(func() {
+		Register("test", func(ctx context.Context, dsn string) (StoreCloser, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestOpenUnknownDriver(t *testing.T) {
+	_, err := Open(context.Background(), &Config{Driver: "unknown"})
+	require.Error(t, err)
+}
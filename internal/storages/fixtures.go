@@ -0,0 +1,69 @@
+package storages
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"time"
+)
+
+// FixtureUser is a user to be seeded, Password is plain text and hashed by storages
+type FixtureUser struct {
+	Id       int    `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	MaxTodo  int    `json:"max_todo"`
+	// Role is RoleUser by default
+	Role Role `json:"role,omitempty"`
+}
+
+// Fixtures is data to be seeded into a storage, zero ids are generated by the storage
+type Fixtures struct {
+	Users []FixtureUser `json:"users"`
+	Tasks []*Task       `json:"tasks"`
+}
+
+// Seeder is implemented by storages which can be seeded with fixtures,
+// seeding is idempotent: rows which already exist are left as is
+type Seeder interface {
+	Seed(ctx context.Context, fixtures *Fixtures) error
+}
+
+// DefaultFixtures returns the demo data, it must never be seeded into production
+func DefaultFixtures() *Fixtures {
+	return &Fixtures{
+		Users: []FixtureUser{
+			{Id: 1, Username: "firstUser", Password: "example", MaxTodo: 5},
+		},
+		Tasks: []*Task{
+			{Id: 1, UsrId: 1, Content: "test 1", CreateAt: time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+// LoadFixtures decodes JSON fixtures from r
+func LoadFixtures(r io.Reader) (*Fixtures, error) {
+	fixtures := &Fixtures{}
+	if err := json.NewDecoder(r).Decode(fixtures); err != nil {
+		return nil, errors.Wrap(err, "Decode()")
+	}
+	return fixtures, nil
+}
+
+// LoadFixturesFile decodes JSON fixtures from the file at path,
+// "default" gives DefaultFixtures
+func LoadFixturesFile(path string) (*Fixtures, error) {
+	if path == "default" {
+		return DefaultFixtures(), nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Open()")
+	}
+	defer f.Close()
+
+	return LoadFixtures(f)
+}
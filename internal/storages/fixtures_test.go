@@ -0,0 +1,34 @@
+package storages
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	requireTest := require.New(t)
+
+	fixtures, err := LoadFixtures(strings.NewReader(`{
+		"users": [{"id": 1, "username": "firstUser", "password": "example", "max_todo": 5}],
+		"tasks": [{"id": 1, "usr_id": 1, "content": "test 1", "create_at": "2020-06-29T00:00:00Z"}]
+	}`))
+	requireTest.NoError(err)
+	requireTest.Equal(DefaultFixtures(), fixtures)
+
+	_, err = LoadFixtures(strings.NewReader(`{"users": 1}`))
+	requireTest.Error(err)
+}
+
+func TestLoadFixturesFileDefault(t *testing.T) {
+	requireTest := require.New(t)
+
+	fixtures, err := LoadFixturesFile("default")
+	requireTest.NoError(err)
+	requireTest.Len(fixtures.Users, 1)
+	requireTest.Equal(time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC), fixtures.Tasks[0].CreateAt)
+
+	_, err = LoadFixturesFile("does-not-exist.json")
+	requireTest.Error(err)
+}
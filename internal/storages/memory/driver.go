@@ -0,0 +1,12 @@
+package memory
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+func init() {
+	storages.Register("memory", func(context.Context, string) (storages.StoreCloser, error) {
+		return NewMemory()
+	})
+}
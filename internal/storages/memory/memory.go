@@ -0,0 +1,2371 @@
+package memory
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory represents a concurrency-safe in-memory storage,
+// it's meant for demos and tests, data is lost on restart
+type Memory struct {
+	mu         sync.RWMutex
+	users      map[string]*storages.User // by username
+	tasks      []*storages.Task
+	projects   map[int]*storages.Project         // by id
+	checklists map[int][]*storages.ChecklistItem // by task id, ordered by position
+	comments   map[int][]*storages.Comment       // by task id, oldest first
+	attachs    map[int][]*storages.Attachment    // by task id, oldest first
+	templates  map[int]*storages.Template
+	leases     map[int]time.Time         // reminder leases by task id
+	refreshes  map[string]*refreshToken  // by hash
+	resets     map[string]*passwordReset // by hash
+	failures   map[string]*loginFailures // by key
+	idempotent map[idempotencyKey]*storages.IdempotentResponse
+	identities map[identity]int           // user ids by provider and subject
+	twoFactors map[int]*twoFactor         // by user id
+	shares     map[share]*storages.Share  // by owner and user id
+	audit      []*storages.AuditEvent     // oldest first
+	taskAudit  []*storages.TaskAuditEntry // oldest first
+	usage      map[usageKey]int           // tasks added by user and day
+	policy     storages.QuotaPolicy
+	burst      storages.Burst
+	bursts     map[int]storages.BurstState // by user id
+	quotaReset map[int]time.Time           // when quotas were reset by user id
+	plans      map[string]*storages.Plan   // by name
+	nextUsrId  int
+	nextTaskId int
+	nextProjId int
+	nextItemId int
+	nextCmtId  int
+	nextAttId  int
+	nextTplId  int
+	nextRefId  int
+	nextRstId  int
+}
+
+// NewMemory create new Memory instance which is seeded with storages.DefaultFixtures
+func NewMemory() (*Memory, error) {
+	m := &Memory{
+		users:      make(map[string]*storages.User),
+		tasks:      make([]*storages.Task, 0),
+		projects:   make(map[int]*storages.Project),
+		checklists: make(map[int][]*storages.ChecklistItem),
+		comments:   make(map[int][]*storages.Comment),
+		attachs:    make(map[int][]*storages.Attachment),
+		templates:  make(map[int]*storages.Template),
+		leases:     make(map[int]time.Time),
+		refreshes:  make(map[string]*refreshToken),
+		resets:     make(map[string]*passwordReset),
+		failures:   make(map[string]*loginFailures),
+		idempotent: make(map[idempotencyKey]*storages.IdempotentResponse),
+		identities: make(map[identity]int),
+		twoFactors: make(map[int]*twoFactor),
+		shares:     make(map[share]*storages.Share),
+		usage:      make(map[usageKey]int),
+		bursts:     make(map[int]storages.BurstState),
+		quotaReset: make(map[int]time.Time),
+		plans:      make(map[string]*storages.Plan),
+		policy:     storages.DailyQuota{},
+		nextUsrId:  1,
+		nextTaskId: 1,
+		nextProjId: 1,
+		nextItemId: 1,
+		nextCmtId:  1,
+		nextAttId:  1,
+		nextTplId:  1,
+		nextRefId:  1,
+		nextRstId:  1,
+	}
+
+	for _, plan := range storages.DefaultPlans() {
+		m.plans[plan.Name] = plan
+	}
+	if err := m.Seed(context.Background(), storages.DefaultFixtures()); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// AddUser adds a new user with hashed password
+func (m *Memory) AddUser(username, pwd string, maxTodo int) (*storages.User, error) {
+	pwdHash, err := password.Hash(pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usr := &storages.User{
+		Id:       m.nextUsrId,
+		Username: username,
+		PwdHash:  pwdHash,
+		MaxTodo:  maxTodo,
+		Role:     storages.RoleUser,
+	}
+	m.users[username] = usr
+	m.nextUsrId++
+
+	copied := *usr
+	return &copied, nil
+}
+
+// CreateUser inserts usr whose password is already hashed
+func (m *Memory) CreateUser(_ context.Context, usr *storages.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.createUser(usr)
+}
+
+// createUser inserts a copy of usr, m.mu must be held
+func (m *Memory) createUser(usr *storages.User) error {
+	if _, ok := m.users[usr.Username]; ok {
+		return storages.ErrConflict
+	}
+	if usr.Role == "" {
+		usr.Role = storages.RoleUser
+	}
+	usr.Id = m.nextUsrId
+	m.nextUsrId++
+
+	copied := *usr
+	m.users[usr.Username] = &copied
+	return nil
+}
+
+// Seed inserts fixtures, users with existing ids or usernames and tasks with existing ids are skipped
+func (m *Memory) Seed(_ context.Context, fixtures *storages.Fixtures) error {
+	users := make([]*storages.User, 0, len(fixtures.Users))
+	for _, fixture := range fixtures.Users {
+		pwdHash, err := password.Hash(fixture.Password)
+		if err != nil {
+			return err
+		}
+		usr := &storages.User{
+			Id:       fixture.Id,
+			Username: fixture.Username,
+			PwdHash:  pwdHash,
+			MaxTodo:  fixture.MaxTodo,
+			Role:     fixture.Role,
+		}
+		if usr.Role == "" {
+			usr.Role = storages.RoleUser
+		}
+		users = append(users, usr)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, usr := range users {
+		if _, ok := m.users[usr.Username]; ok || m.findUser(usr.Id) != nil {
+			continue
+		}
+		if usr.Id == 0 {
+			usr.Id = m.nextUsrId
+		}
+		if usr.Id >= m.nextUsrId {
+			m.nextUsrId = usr.Id + 1
+		}
+		m.users[usr.Username] = usr
+	}
+
+	for _, task := range fixtures.Tasks {
+		if task.Id != 0 && m.hasTask(task.Id) {
+			continue
+		}
+		task = copyTask(task)
+		if err := task.SetStatus(task.Status, task.CreateAt); err != nil {
+			return err
+		}
+		tags, err := storages.NormalizeTags(task.Tags)
+		if err != nil {
+			return err
+		}
+		task.Tags = tags
+		task.Checklist = nil
+		if task.Position == 0 {
+			task.Position = storages.DefaultPosition(task.CreateAt)
+		}
+		if err := task.InitRecurrence(); err != nil {
+			return err
+		}
+		if task.Id == 0 {
+			task.Id = m.nextTaskId
+		}
+		if task.Version == 0 {
+			task.Version = 1
+		}
+		if task.Id >= m.nextTaskId {
+			m.nextTaskId = task.Id + 1
+		}
+		m.tasks = append(m.tasks, task)
+	}
+
+	return nil
+}
+
+// findUser returns the stored user by id, m.mu must be held
+func (m *Memory) findUser(id int) *storages.User {
+	for _, usr := range m.users {
+		if usr.Id == id {
+			return usr
+		}
+	}
+	return nil
+}
+
+// copyUser returns a copy of usr with the daily-limit of their plan, m.mu must be held
+func (m *Memory) copyUser(usr *storages.User) *storages.User {
+	copied := *usr
+	copied.MaxTodo = m.maxTodo(usr)
+	return &copied
+}
+
+// maxTodo returns the daily-limit of the plan of usr, or else their own, m.mu must be held
+func (m *Memory) maxTodo(usr *storages.User) int {
+	if plan, ok := m.plans[usr.Plan]; ok {
+		return plan.MaxTodo
+	}
+	return usr.MaxTodo
+}
+
+// hasTask reports whether a task with the id is stored, m.mu must be held
+func (m *Memory) hasTask(id int) bool {
+	for _, task := range m.tasks {
+		if task.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateUser returns user if match username AND password
+func (m *Memory) ValidateUser(_ context.Context, username, pwd string) (*storages.User, error) {
+	m.mu.RLock()
+	usr, ok := m.users[username]
+	var copied storages.User
+	if ok {
+		copied = *m.copyUser(usr)
+	}
+	m.mu.RUnlock()
+
+	if !ok {
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
+	}
+	if !password.Compare(copied.PwdHash, pwd) {
+		return nil, storages.ErrInvalidCredentials
+	}
+	if password.NeedsRehash(copied.PwdHash) {
+		if pwdHash, err := password.Hash(pwd); err == nil {
+			m.mu.Lock()
+			if usr.PwdHash == copied.PwdHash {
+				usr.PwdHash = pwdHash
+			}
+			m.mu.Unlock()
+			copied.PwdHash = pwdHash
+		}
+	}
+	return &copied, nil
+}
+
+// GetTasks returns tasks of the user which were created on the date of createAt, deleted tasks are left out
+func (m *Memory) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	return m.FindTasks(ctx, usrId, createAt, storages.TaskFilter{})
+}
+
+// GetTask returns the task of the user
+func (m *Memory) GetTask(_ context.Context, usrId, id int) (*storages.Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return nil, storages.ErrNotFound
+	}
+	return copyTask(task), nil
+}
+
+// FindTasks returns tasks of the user which were created on the date of createAt matching the filter
+func (m *Memory) FindTasks(_ context.Context, usrId int, createAt time.Time, filter storages.TaskFilter) ([]*storages.Task, error) {
+	if !filter.SortBy.Valid() {
+		return nil, storages.ErrInvalidTask
+	}
+	tags, err := storages.NormalizeTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	from, to := storages.DayRangeIn(createAt, createAt.Location())
+	tasks := make([]*storages.Task, 0)
+	for _, task := range m.tasks {
+		if task.UsrId != usrId || !inRange(task.CreateAt, from, to) {
+			continue
+		}
+		if task.DeletedAt != nil && !filter.IncludeDeleted {
+			continue
+		}
+		if filter.Completed != nil && *filter.Completed != (task.Status == storages.TaskStatusDone) {
+			continue
+		}
+		if !hasTags(task, tags) {
+			continue
+		}
+		if filter.ProjectId != nil && (task.ProjectId == nil || *task.ProjectId != *filter.ProjectId) {
+			continue
+		}
+		tasks = append(tasks, copyTask(task))
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].CreateAt.Before(tasks[j].CreateAt)
+	})
+	switch filter.SortBy {
+	case storages.TaskSortPriority:
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].Priority > tasks[j].Priority
+		})
+	case storages.TaskSortPosition:
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].Position < tasks[j].Position
+		})
+	}
+	return tasks, nil
+}
+
+// InsertTask inserts task if the user's daily-limit has not been reached yet,
+// counting and inserting happen under the same lock
+func (m *Memory) InsertTask(_ context.Context, task *storages.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := prepareInsert(task, time.Now().UTC()); err != nil {
+		return err
+	}
+	return m.insertTask(task)
+}
+
+// InsertTaskQuota inserts task like InsertTask does and returns the quota of the user after it
+func (m *Memory) InsertTaskQuota(_ context.Context, task *storages.Task) (*storages.Quota, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := prepareInsert(task, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	err := m.insertTask(task)
+	if err != nil && err != storages.ErrQuotaExceeded {
+		return nil, err
+	}
+	return m.quota(m.findUser(task.UsrId), task.CreateAt), err
+}
+
+// SetQuotaPolicy changes which tasks count towards the limits of users and of projects
+func (m *Memory) SetQuotaPolicy(policy storages.QuotaPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.policy = policy
+}
+
+// GetQuota returns the daily-limit of the user and how many tasks they added within the window of at
+func (m *Memory) GetQuota(_ context.Context, usrId int, at time.Time) (*storages.Quota, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usr := m.findUser(usrId)
+	if usr == nil {
+		return nil, storages.ErrNotFound
+	}
+	return m.quota(usr, at), nil
+}
+
+// quota counts the tasks usr added within the window of at in their timezone, m.mu must be held
+func (m *Memory) quota(usr *storages.User, at time.Time) *storages.Quota {
+	from, to := m.policy.Window(at, usr.Location())
+	from = storages.CountFrom(from, m.quotaReset[usr.Id])
+	quota := &storages.Quota{MaxTodo: m.maxTodo(usr), ResetAt: to, Burst: m.burst.Available(m.bursts[usr.Id], at)}
+	for _, t := range m.tasks {
+		if t.UsrId == usr.Id && inRange(t.CreateAt, from, to) {
+			quota.Used++
+		}
+	}
+	return quota
+}
+
+// ResetQuota stops the tasks the user added before at from counting towards their limit and clears their burst
+func (m *Memory) ResetQuota(_ context.Context, usrId int, at time.Time) (*storages.Quota, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usr := m.findUser(usrId)
+	if usr == nil {
+		return nil, storages.ErrNotFound
+	}
+	m.quotaReset[usrId] = at
+	delete(m.bursts, usrId)
+	return m.quota(usr, at), nil
+}
+
+// SetBurst lets users add tasks over their limits by burst
+func (m *Memory) SetBurst(burst storages.Burst) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.burst = burst
+}
+
+// InsertTasks inserts all tasks or none, the quota of each task counts the tasks before it in the batch
+func (m *Memory) InsertTasks(_ context.Context, tasks []*storages.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	createAt := time.Now().UTC()
+	batchErr := storages.BatchError{}
+	for i, task := range tasks {
+		if err := prepareInsert(task, createAt); err != nil {
+			batchErr[i] = err
+		}
+	}
+	if len(batchErr) > 0 {
+		return batchErr
+	}
+
+	return m.insertTasks(tasks)
+}
+
+// insertTasks inserts prepared tasks or none of them, m.mu must be held
+func (m *Memory) insertTasks(tasks []*storages.Task) error {
+	n, nextTaskId := len(m.tasks), m.nextTaskId
+	bursts := make(map[int]storages.BurstState, len(m.bursts))
+	for id, state := range m.bursts {
+		bursts[id] = state
+	}
+	batchErr := storages.BatchError{}
+	for i, task := range tasks {
+		if err := m.insertTask(task); err != nil {
+			batchErr[i] = err
+		}
+	}
+	if len(batchErr) > 0 {
+		for i, task := range tasks {
+			if _, ok := batchErr[i]; !ok {
+				m.usage[newUsageKey(m.findUser(task.UsrId), task)]--
+			}
+		}
+		m.tasks, m.nextTaskId, m.bursts = m.tasks[:n], nextTaskId, bursts
+		return batchErr
+	}
+	return nil
+}
+
+func prepareInsert(task *storages.Task, createAt time.Time) error {
+	task.CreateAt = createAt
+	if err := task.SetStatus(task.Status, task.CreateAt); err != nil {
+		return err
+	}
+	if err := task.NormalizeContent(); err != nil {
+		return err
+	}
+	tags, err := storages.NormalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+	task.Checklist = nil
+	task.Position = storages.DefaultPosition(task.CreateAt)
+	task.RemindedAt = nil
+	return task.InitRecurrence()
+}
+
+// insertTask checks the daily-limits and appends task, m.mu must be held
+func (m *Memory) insertTask(task *storages.Task) error {
+	usr := m.findUser(task.UsrId)
+	if usr == nil {
+		return storages.ErrNotFound
+	}
+
+	var project *storages.Project
+	if task.ProjectId != nil {
+		if project = m.projects[*task.ProjectId]; project == nil || project.UsrId != task.UsrId {
+			return storages.ErrNotFound
+		}
+	}
+
+	from, to := m.policy.Window(task.CreateAt, usr.Location())
+	countFrom := storages.CountFrom(from, m.quotaReset[usr.Id])
+	count, projectCount := 0, 0
+	for _, t := range m.tasks {
+		if !inRange(t.CreateAt, from, to) {
+			continue
+		}
+		if t.UsrId == task.UsrId && !t.CreateAt.Before(countFrom) {
+			count++
+		}
+		if project != nil && t.ProjectId != nil && *t.ProjectId == project.Id {
+			projectCount++
+		}
+	}
+	// tasks over the limit of the user use burst credits
+	over := count >= storages.TodoLimit(m.maxTodo(usr))
+	if over && m.burst.Available(m.bursts[usr.Id], task.CreateAt) == 0 {
+		return storages.ErrQuotaExceeded
+	}
+	if project != nil && project.MaxTodo != nil && projectCount >= *project.MaxTodo {
+		return storages.ErrQuotaExceeded
+	}
+	if over {
+		m.bursts[usr.Id] = m.burst.Use(m.bursts[usr.Id], 1, task.CreateAt)
+	}
+
+	task.Id = m.nextTaskId
+	task.Version = 1
+	m.nextTaskId++
+
+	m.tasks = append(m.tasks, copyTask(task))
+	m.usage[newUsageKey(usr, task)]++
+
+	return nil
+}
+
+// FindOverdueTasks returns not done tasks of the user which were due before now, the earliest due first
+func (m *Memory) FindOverdueTasks(_ context.Context, usrId int, now time.Time) ([]*storages.Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tasks := make([]*storages.Task, 0)
+	for _, task := range m.tasks {
+		if task.UsrId != usrId || task.DeletedAt != nil || task.Status == storages.TaskStatusDone {
+			continue
+		}
+		if task.DueAt != nil && task.DueAt.Before(now) {
+			tasks = append(tasks, copyTask(task))
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].DueAt.Before(*tasks[j].DueAt)
+	})
+	return tasks, nil
+}
+
+// DeleteTask soft deletes the task of the user
+func (m *Memory) DeleteTask(_ context.Context, usrId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		for _, t := range m.tasks {
+			if t.Id == id && t.DeletedAt == nil {
+				return storages.ErrForbidden
+			}
+		}
+		return storages.ErrNotFound
+	}
+	now := time.Now().UTC()
+	task.DeletedAt = &now
+	touch(task)
+	return nil
+}
+
+// CompleteTasks marks the selected tasks of the user done under one lock
+func (m *Memory) CompleteTasks(_ context.Context, usrId int, selector storages.TaskSelector) ([]int, error) {
+	return m.bulkUpdate(usrId, selector, func(task *storages.Task, now time.Time) bool {
+		if task.Status == storages.TaskStatusDone {
+			return false
+		}
+		return task.SetStatus(storages.TaskStatusDone, now) == nil
+	})
+}
+
+// DeleteTasks soft deletes the selected tasks of the user under one lock
+func (m *Memory) DeleteTasks(_ context.Context, usrId int, selector storages.TaskSelector) ([]int, error) {
+	return m.bulkUpdate(usrId, selector, func(task *storages.Task, now time.Time) bool {
+		task.DeletedAt = &now
+		return true
+	})
+}
+
+// bulkUpdate runs fn on the selected tasks and returns the ids of those it changed
+func (m *Memory) bulkUpdate(usrId int, selector storages.TaskSelector, fn func(task *storages.Task, now time.Time) bool) ([]int, error) {
+	if err := selector.Normalize(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+	ids := make([]int, 0)
+	for _, task := range m.tasks {
+		if task.UsrId != usrId || task.DeletedAt != nil || !selects(selector, task) {
+			continue
+		}
+		if fn(task, now) {
+			touch(task)
+			ids = append(ids, task.Id)
+		}
+	}
+	return ids, nil
+}
+
+// ArchiveTask archives the task of the user, archiving an archived task keeps its ArchivedAt
+func (m *Memory) ArchiveTask(_ context.Context, usrId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	if task.ArchivedAt == nil {
+		now := time.Now().UTC()
+		task.ArchivedAt = &now
+	}
+	touch(task)
+	return nil
+}
+
+// SetTaskStatus sets status of the task of the user, CompletedAt follows the status
+func (m *Memory) SetTaskStatus(_ context.Context, usrId, id int, status storages.TaskStatus) error {
+	if !status.Valid() {
+		return storages.ErrInvalidTask
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	if err := task.SetStatus(status, time.Now().UTC()); err != nil {
+		return err
+	}
+	touch(task)
+	return nil
+}
+
+// SetTaskDueAt sets due date of the task of the user, nil clears it
+func (m *Memory) SetTaskDueAt(_ context.Context, usrId, id int, dueAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	task.DueAt = copyTime(dueAt)
+	touch(task)
+	return nil
+}
+
+// SetTaskPriority sets priority of the task of the user
+func (m *Memory) SetTaskPriority(_ context.Context, usrId, id int, priority int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	task.Priority = priority
+	touch(task)
+	return nil
+}
+
+// AddTaskTags tags the task of the user
+func (m *Memory) AddTaskTags(_ context.Context, usrId, id int, tags []string) error {
+	tags, err := storages.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	if task.Tags, err = storages.NormalizeTags(append(task.Tags, tags...)); err != nil {
+		return err
+	}
+	touch(task)
+	return nil
+}
+
+// RemoveTaskTags untags the task of the user
+func (m *Memory) RemoveTaskTags(_ context.Context, usrId, id int, tags []string) error {
+	tags, err := storages.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	kept := make([]string, 0, len(task.Tags))
+	for _, tag := range task.Tags {
+		if !contains(tags, tag) {
+			kept = append(kept, tag)
+		}
+	}
+	task.Tags = kept
+	touch(task)
+	return nil
+}
+
+// UpdateTask saves task if its Version is still the stored one
+func (m *Memory) UpdateTask(_ context.Context, task *storages.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.findTask(task.UsrId, task.Id)
+	if stored == nil {
+		return storages.ErrNotFound
+	}
+	if stored.Version != task.Version {
+		return storages.ErrConflict
+	}
+
+	updated := copyTask(stored)
+	updated.Content = task.Content
+	updated.ContentFormat = task.ContentFormat
+	if err := updated.NormalizeContent(); err != nil {
+		return err
+	}
+	updated.DueAt = copyTime(task.DueAt)
+	updated.Priority = task.Priority
+	if err := updated.SetStatus(task.Status, time.Now().UTC()); err != nil {
+		return err
+	}
+	touch(updated)
+
+	*stored = *updated
+	*task = *copyTask(updated)
+	return nil
+}
+
+// findTask returns the stored task of the user which is not deleted, m.mu must be held
+func (m *Memory) findTask(usrId, id int) *storages.Task {
+	for _, task := range m.tasks {
+		if task.Id == id && task.UsrId == usrId && task.DeletedAt == nil {
+			return task
+		}
+	}
+	return nil
+}
+
+// CreateProject adds project, names are unique per user
+func (m *Memory) CreateProject(_ context.Context, project *storages.Project) error {
+	if err := project.Normalize(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findUser(project.UsrId) == nil {
+		return storages.ErrNotFound
+	}
+	if m.hasProjectName(project.UsrId, project.Name, 0) {
+		return storages.ErrConflict
+	}
+
+	project.Id = m.nextProjId
+	project.CreateAt = time.Now().UTC()
+	m.nextProjId++
+	m.projects[project.Id] = copyProject(project)
+	return nil
+}
+
+// GetProjects returns projects of the user by name
+func (m *Memory) GetProjects(_ context.Context, usrId int) ([]*storages.Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	projects := make([]*storages.Project, 0)
+	for _, project := range m.projects {
+		if project.UsrId == usrId {
+			projects = append(projects, copyProject(project))
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+	return projects, nil
+}
+
+// GetProject returns the project of the user
+func (m *Memory) GetProject(_ context.Context, usrId, id int) (*storages.Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	project := m.projects[id]
+	if project == nil || project.UsrId != usrId {
+		return nil, storages.ErrNotFound
+	}
+	return copyProject(project), nil
+}
+
+// UpdateProject saves name and daily-limit of the project
+func (m *Memory) UpdateProject(_ context.Context, project *storages.Project) error {
+	if err := project.Normalize(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.projects[project.Id]
+	if stored == nil || stored.UsrId != project.UsrId {
+		return storages.ErrNotFound
+	}
+	if m.hasProjectName(project.UsrId, project.Name, project.Id) {
+		return storages.ErrConflict
+	}
+
+	project.CreateAt = stored.CreateAt
+	m.projects[project.Id] = copyProject(project)
+	return nil
+}
+
+// DeleteProject deletes the project of the user, its tasks are kept without a project
+func (m *Memory) DeleteProject(_ context.Context, usrId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	project := m.projects[id]
+	if project == nil || project.UsrId != usrId {
+		return storages.ErrNotFound
+	}
+
+	delete(m.projects, id)
+	for _, task := range m.tasks {
+		if task.ProjectId != nil && *task.ProjectId == id {
+			task.ProjectId = nil
+		}
+	}
+	return nil
+}
+
+// hasProjectName reports whether the user has another project than exceptId with the name, m.mu must be held
+func (m *Memory) hasProjectName(usrId int, name string, exceptId int) bool {
+	for _, project := range m.projects {
+		if project.UsrId == usrId && project.Name == name && project.Id != exceptId {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChecklist returns checklist items of the task of the user by position
+func (m *Memory) GetChecklist(_ context.Context, usrId, taskId int) ([]*storages.ChecklistItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findTask(usrId, taskId) == nil {
+		return nil, storages.ErrNotFound
+	}
+	items := make([]*storages.ChecklistItem, 0, len(m.checklists[taskId]))
+	for _, item := range m.checklists[taskId] {
+		copied := *item
+		items = append(items, &copied)
+	}
+	return items, nil
+}
+
+// AddChecklistItem appends item to the checklist of its task
+func (m *Memory) AddChecklistItem(_ context.Context, usrId int, item *storages.ChecklistItem) error {
+	if err := item.Normalize(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, item.TaskId)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+
+	items := m.checklists[task.Id]
+	item.Id = m.nextItemId
+	item.Position = 1
+	if len(items) > 0 {
+		item.Position = items[len(items)-1].Position + 1
+	}
+	item.Done = false
+	item.CreateAt = time.Now().UTC()
+	m.nextItemId++
+
+	copied := *item
+	m.checklists[task.Id] = append(items, &copied)
+	m.changeChecklist(task)
+	return nil
+}
+
+// SetChecklistItemDone marks the item of the task done or not
+func (m *Memory) SetChecklistItemDone(_ context.Context, usrId, taskId, id int, done bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, taskId)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	for _, item := range m.checklists[taskId] {
+		if item.Id == id {
+			item.Done = done
+			m.changeChecklist(task)
+			return nil
+		}
+	}
+	return storages.ErrNotFound
+}
+
+// ReorderChecklist gives items of the task positions in the order of ids
+func (m *Memory) ReorderChecklist(_ context.Context, usrId, taskId int, ids []int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, taskId)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+
+	items := m.checklists[taskId]
+	current := make([]int, 0, len(items))
+	for _, item := range items {
+		current = append(current, item.Id)
+	}
+	if !storages.SameIds(current, ids) {
+		return storages.ErrInvalidTask
+	}
+
+	positions := make(map[int]int, len(ids))
+	for i, id := range ids {
+		positions[id] = i + 1
+	}
+	for _, item := range items {
+		item.Position = positions[item.Id]
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Position < items[j].Position
+	})
+	m.changeChecklist(task)
+	return nil
+}
+
+// changeChecklist rolls up the checklist of task and increases its version, m.mu must be held
+func (m *Memory) changeChecklist(task *storages.Task) {
+	task.Checklist = storages.NewChecklist(m.checklists[task.Id])
+	touch(task)
+}
+
+// AddComment appends comment to the thread of its task
+func (m *Memory) AddComment(_ context.Context, comment *storages.Comment) error {
+	if err := comment.Normalize(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findTask(comment.UsrId, comment.TaskId) == nil {
+		return storages.ErrNotFound
+	}
+	comment.Id = m.nextCmtId
+	comment.CreateAt = time.Now().UTC()
+	m.nextCmtId++
+
+	copied := *comment
+	m.comments[comment.TaskId] = append(m.comments[comment.TaskId], &copied)
+	return nil
+}
+
+// GetComments returns comments on the task of the user, oldest first
+func (m *Memory) GetComments(_ context.Context, usrId, taskId int) ([]*storages.Comment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findTask(usrId, taskId) == nil {
+		return nil, storages.ErrNotFound
+	}
+	comments := make([]*storages.Comment, 0, len(m.comments[taskId]))
+	for _, comment := range m.comments[taskId] {
+		copied := *comment
+		comments = append(comments, &copied)
+	}
+	return comments, nil
+}
+
+// DeleteComment deletes the comment of the user on the task
+func (m *Memory) DeleteComment(_ context.Context, usrId, taskId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findTask(usrId, taskId) == nil {
+		return storages.ErrNotFound
+	}
+	comments := m.comments[taskId]
+	for i, comment := range comments {
+		if comment.Id != id {
+			continue
+		}
+		if comment.UsrId != usrId {
+			return storages.ErrForbidden
+		}
+		m.comments[taskId] = append(comments[:i:i], comments[i+1:]...)
+		return nil
+	}
+	return storages.ErrNotFound
+}
+
+// AddAttachment inserts attachment of its task
+func (m *Memory) AddAttachment(_ context.Context, attachment *storages.Attachment) error {
+	if err := attachment.Normalize(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findTask(attachment.UsrId, attachment.TaskId) == nil {
+		return storages.ErrNotFound
+	}
+	attachment.Id = m.nextAttId
+	attachment.CreateAt = time.Now().UTC()
+	m.nextAttId++
+
+	copied := *attachment
+	m.attachs[attachment.TaskId] = append(m.attachs[attachment.TaskId], &copied)
+	return nil
+}
+
+// GetAttachments returns attachments of the task of the user, oldest first
+func (m *Memory) GetAttachments(_ context.Context, usrId, taskId int) ([]*storages.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findTask(usrId, taskId) == nil {
+		return nil, storages.ErrNotFound
+	}
+	return copyAttachments(m.attachs[taskId]), nil
+}
+
+// GetAttachment returns the attachment of the task of the user
+func (m *Memory) GetAttachment(_ context.Context, usrId, taskId, id int) (*storages.Attachment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findTask(usrId, taskId) == nil {
+		return nil, storages.ErrNotFound
+	}
+	for _, attachment := range m.attachs[taskId] {
+		if attachment.Id == id {
+			copied := *attachment
+			return &copied, nil
+		}
+	}
+	return nil, storages.ErrNotFound
+}
+
+// DeleteAttachment deletes and returns the attachment of the task of the user
+func (m *Memory) DeleteAttachment(_ context.Context, usrId, taskId, id int) (*storages.Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findTask(usrId, taskId) == nil {
+		return nil, storages.ErrNotFound
+	}
+	attachments := m.attachs[taskId]
+	for i, attachment := range attachments {
+		if attachment.Id == id {
+			m.attachs[taskId] = append(attachments[:i:i], attachments[i+1:]...)
+			return attachment, nil
+		}
+	}
+	return nil, storages.ErrNotFound
+}
+
+// PurgeAttachments deletes and returns attachments of the deleted task of the user
+func (m *Memory) PurgeAttachments(_ context.Context, usrId, taskId int) ([]*storages.Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, task := range m.tasks {
+		if task.Id == taskId && task.UsrId == usrId && task.DeletedAt != nil {
+			attachments := copyAttachments(m.attachs[taskId])
+			delete(m.attachs, taskId)
+			return attachments, nil
+		}
+	}
+	return []*storages.Attachment{}, nil
+}
+
+// MoveTask places the task between its new neighbors, tasks of the user are renumbered
+// when there is no position left between the neighbors
+func (m *Memory) MoveTask(_ context.Context, usrId, id int, afterId, beforeId *int) error {
+	if (afterId != nil && *afterId == id) || (beforeId != nil && *beforeId == id) {
+		return storages.ErrInvalidTask
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	lo, hi, err := m.neighborPositions(usrId, afterId, beforeId)
+	if err != nil {
+		return err
+	}
+	position, ok, err := storages.PositionBetween(lo, hi)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		m.renumberPositions(usrId)
+		lo, hi, _ = m.neighborPositions(usrId, afterId, beforeId)
+		if position, _, err = storages.PositionBetween(lo, hi); err != nil {
+			return err
+		}
+	}
+
+	task.Position = position
+	touch(task)
+	return nil
+}
+
+// neighborPositions returns positions of the given neighbors, m.mu must be held
+func (m *Memory) neighborPositions(usrId int, afterId, beforeId *int) (*float64, *float64, error) {
+	var positions [2]*float64
+	for i, neighborId := range []*int{afterId, beforeId} {
+		if neighborId == nil {
+			continue
+		}
+		neighbor := m.findTask(usrId, *neighborId)
+		if neighbor == nil {
+			return nil, nil, storages.ErrNotFound
+		}
+		position := neighbor.Position
+		positions[i] = &position
+	}
+	return positions[0], positions[1], nil
+}
+
+// renumberPositions spreads positions of tasks of the user PositionGap apart keeping their order, m.mu must be held
+func (m *Memory) renumberPositions(usrId int) {
+	tasks := make([]*storages.Task, 0)
+	for _, task := range m.tasks {
+		if task.UsrId == usrId {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Position != tasks[j].Position {
+			return tasks[i].Position < tasks[j].Position
+		}
+		return tasks[i].Id < tasks[j].Id
+	})
+	for i, task := range tasks {
+		task.Position = float64(i+1) * storages.PositionGap
+	}
+}
+
+// MaterializeRecurrences creates next occurrences of due recurring tasks
+func (m *Memory) MaterializeRecurrences(_ context.Context, now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, task := range m.tasks {
+		if !task.RecurDue(now) {
+			continue
+		}
+		next := task.NextOccurrence(now)
+		next.Id = m.nextTaskId
+		m.nextTaskId++
+		if next.Tags == nil {
+			next.Tags = []string{}
+		}
+		m.tasks = append(m.tasks, next)
+
+		task.RecurAt = nil
+		touch(task)
+		count++
+	}
+	return count, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+// copyTask returns a deep copy of task so callers can't change stored tasks
+// touch increases the version of a stored task which is changed
+func touch(task *storages.Task) {
+	now := time.Now().UTC()
+	task.Version++
+	task.UpdateAt = &now
+}
+
+func copyTask(task *storages.Task) *storages.Task {
+	copied := *task
+	copied.DeletedAt = copyTime(task.DeletedAt)
+	copied.ArchivedAt = copyTime(task.ArchivedAt)
+	copied.DueAt = copyTime(task.DueAt)
+	copied.CompletedAt = copyTime(task.CompletedAt)
+	copied.UpdateAt = copyTime(task.UpdateAt)
+	copied.RemindAt = copyTime(task.RemindAt)
+	copied.RemindedAt = copyTime(task.RemindedAt)
+	if task.Tags != nil {
+		copied.Tags = append([]string(nil), task.Tags...)
+	}
+	if task.ProjectId != nil {
+		projectId := *task.ProjectId
+		copied.ProjectId = &projectId
+	}
+	if task.Checklist != nil {
+		checklist := *task.Checklist
+		copied.Checklist = &checklist
+	}
+	copied.RecurAt = copyTime(task.RecurAt)
+	return &copied
+}
+
+// copyProject returns a deep copy of project so callers can't change stored projects
+func copyProject(project *storages.Project) *storages.Project {
+	copied := *project
+	if project.MaxTodo != nil {
+		maxTodo := *project.MaxTodo
+		copied.MaxTodo = &maxTodo
+	}
+	return &copied
+}
+
+func copyAttachments(attachments []*storages.Attachment) []*storages.Attachment {
+	copied := make([]*storages.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		a := *attachment
+		copied = append(copied, &a)
+	}
+	return copied
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+// hasTags reports whether task has all of the tags
+// selects reports whether task matches every field of selector
+func selects(selector storages.TaskSelector, task *storages.Task) bool {
+	if len(selector.Ids) > 0 && !containsId(selector.Ids, task.Id) {
+		return false
+	}
+	if selector.Completed != nil && *selector.Completed != (task.Status == storages.TaskStatusDone) {
+		return false
+	}
+	if selector.CreatedBefore != nil && !task.CreateAt.Before(*selector.CreatedBefore) {
+		return false
+	}
+	if selector.CompletedBefore != nil && (task.CompletedAt == nil || !task.CompletedAt.Before(*selector.CompletedBefore)) {
+		return false
+	}
+	if selector.ProjectId != nil && (task.ProjectId == nil || *task.ProjectId != *selector.ProjectId) {
+		return false
+	}
+	return hasTags(task, selector.Tags)
+}
+
+func containsId(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTags(task *storages.Task, tags []string) bool {
+	for _, tag := range tags {
+		if !contains(task.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTemplate saves template along with the tasks of taskIds
+func (m *Memory) CreateTemplate(_ context.Context, template *storages.Template, taskIds []int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks := append([]storages.TemplateTask(nil), template.Tasks...)
+	for _, id := range taskIds {
+		task := m.findTask(template.UsrId, id)
+		if task == nil {
+			return storages.ErrNotFound
+		}
+		tasks = append(tasks, storages.NewTemplateTask(task, m.checklists[id]))
+	}
+	template.Tasks = tasks
+	if err := template.Normalize(); err != nil {
+		return err
+	}
+
+	template.Id = m.nextTplId
+	template.CreateAt = time.Now().UTC()
+	m.nextTplId++
+	m.templates[template.Id] = copyTemplate(template)
+	return nil
+}
+
+// GetTemplates returns templates of the user, the oldest first
+func (m *Memory) GetTemplates(_ context.Context, usrId int) ([]*storages.Template, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	templates := make([]*storages.Template, 0)
+	for _, template := range m.templates {
+		if template.UsrId == usrId {
+			templates = append(templates, copyTemplate(template))
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Id < templates[j].Id
+	})
+	return templates, nil
+}
+
+// GetTemplate returns the template of the user
+func (m *Memory) GetTemplate(_ context.Context, usrId, id int) (*storages.Template, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	template := m.templates[id]
+	if template == nil || template.UsrId != usrId {
+		return nil, storages.ErrNotFound
+	}
+	return copyTemplate(template), nil
+}
+
+// DeleteTemplate deletes the template of the user, tasks instantiated from it are kept
+func (m *Memory) DeleteTemplate(_ context.Context, usrId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	template := m.templates[id]
+	if template == nil || template.UsrId != usrId {
+		return storages.ErrNotFound
+	}
+	delete(m.templates, id)
+	return nil
+}
+
+// InstantiateTemplate inserts the tasks of the template with their checklists under one lock
+func (m *Memory) InstantiateTemplate(_ context.Context, usrId, id int) ([]*storages.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	template := m.templates[id]
+	if template == nil || template.UsrId != usrId {
+		return nil, storages.ErrNotFound
+	}
+
+	createAt := time.Now().UTC()
+	tasks := make([]*storages.Task, 0, len(template.Tasks))
+	for _, templateTask := range template.Tasks {
+		task := templateTask.NewTask(usrId)
+		if err := prepareInsert(task, createAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := m.insertTasks(tasks); err != nil {
+		return nil, storages.ErrQuotaExceeded
+	}
+
+	for i, task := range tasks {
+		items := make([]*storages.ChecklistItem, 0, len(template.Tasks[i].Items))
+		for j, content := range template.Tasks[i].Items {
+			items = append(items, &storages.ChecklistItem{
+				Id:       m.nextItemId,
+				TaskId:   task.Id,
+				Content:  content,
+				Position: j + 1,
+				CreateAt: createAt,
+			})
+			m.nextItemId++
+		}
+		if len(items) > 0 {
+			m.checklists[task.Id] = items
+			task.Checklist = storages.NewChecklist(items)
+			m.findTask(usrId, task.Id).Checklist = storages.NewChecklist(items)
+		}
+	}
+	return tasks, nil
+}
+
+// copyTemplate returns a deep copy of template so callers can't change stored templates
+func copyTemplate(template *storages.Template) *storages.Template {
+	copied := *template
+	copied.Tasks = make([]storages.TemplateTask, 0, len(template.Tasks))
+	for _, task := range template.Tasks {
+		task.Tags = append([]string(nil), task.Tags...)
+		task.Items = append([]string(nil), task.Items...)
+		copied.Tasks = append(copied.Tasks, task)
+	}
+	return &copied
+}
+
+// SetTaskRemindAt sets the reminder of the task of the user, a changed reminder is delivered again
+func (m *Memory) SetTaskRemindAt(_ context.Context, usrId, id int, remindAt *time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task := m.findTask(usrId, id)
+	if task == nil {
+		return storages.ErrNotFound
+	}
+	task.RemindAt = copyTime(remindAt)
+	task.RemindedAt = nil
+	delete(m.leases, id)
+	touch(task)
+	return nil
+}
+
+// ClaimReminders leases up to limit due reminders until now+lease, the earliest first
+func (m *Memory) ClaimReminders(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*storages.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := make([]*storages.Task, 0)
+	for _, task := range m.tasks {
+		if task.RemindAt == nil || task.RemindAt.After(now) || task.RemindedAt != nil || task.DeletedAt != nil {
+			continue
+		}
+		if leased, ok := m.leases[task.Id]; ok && leased.After(now) {
+			continue
+		}
+		due = append(due, task)
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].RemindAt.Before(*due[j].RemindAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	tasks := make([]*storages.Task, 0, len(due))
+	for _, task := range due {
+		m.leases[task.Id] = now.Add(lease)
+		tasks = append(tasks, copyTask(task))
+	}
+	return tasks, nil
+}
+
+// AckReminder marks the reminder of the task delivered, a reminder changed in the meantime is kept
+func (m *Memory) AckReminder(_ context.Context, id int, remindAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, task := range m.tasks {
+		if task.Id != id || task.RemindAt == nil || !task.RemindAt.Equal(remindAt) || task.RemindedAt != nil {
+			continue
+		}
+		now := time.Now().UTC()
+		task.RemindedAt = &now
+		delete(m.leases, id)
+		touch(task)
+	}
+	return nil
+}
+
+// GetTrash returns deleted tasks of the user, the latest deleted first
+func (m *Memory) GetTrash(_ context.Context, usrId int) ([]*storages.Task, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tasks := make([]*storages.Task, 0)
+	for _, task := range m.tasks {
+		if task.UsrId == usrId && task.DeletedAt != nil {
+			tasks = append(tasks, copyTask(task))
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+	})
+	return tasks, nil
+}
+
+// RestoreTask undeletes the deleted task of the user
+func (m *Memory) RestoreTask(_ context.Context, usrId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, task := range m.tasks {
+		if task.Id == id && task.UsrId == usrId && task.DeletedAt != nil {
+			task.DeletedAt = nil
+			touch(task)
+			return nil
+		}
+	}
+	return storages.ErrNotFound
+}
+
+// PurgeTrash deletes up to limit tasks deleted before before along with everything kept for them
+func (m *Memory) PurgeTrash(_ context.Context, before time.Time, limit int) (int, []*storages.Attachment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	attachments := make([]*storages.Attachment, 0)
+	tasks := m.tasks[:0]
+	for _, task := range m.tasks {
+		if n >= limit || task.DeletedAt == nil || !task.DeletedAt.Before(before) {
+			tasks = append(tasks, task)
+			continue
+		}
+		attachments = append(attachments, copyAttachments(m.attachs[task.Id])...)
+		delete(m.checklists, task.Id)
+		delete(m.comments, task.Id)
+		delete(m.attachs, task.Id)
+		delete(m.leases, task.Id)
+		n++
+	}
+	m.tasks = tasks
+	return n, attachments, nil
+}
+
+// refreshToken is a stored refresh token along with whether it has been rotated or revoked
+type refreshToken struct {
+	storages.RefreshToken
+	rotated bool
+	revoked bool
+}
+
+// CreateRefreshToken saves the first token of a family
+func (m *Memory) CreateRefreshToken(_ context.Context, token *storages.RefreshToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.refreshes[token.Hash]; ok {
+		return storages.ErrConflict
+	}
+	token.Id = m.nextRefId
+	token.CreateAt = time.Now().UTC()
+	token.Family = token.Hash
+	m.nextRefId++
+	m.refreshes[token.Hash] = &refreshToken{RefreshToken: *token}
+	return nil
+}
+
+// RotateRefreshToken trades the token of hash for next under one lock
+func (m *Memory) RotateRefreshToken(_ context.Context, hash string, next *storages.RefreshToken) (*storages.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.refreshes[hash]
+	switch {
+	case !ok || token.revoked:
+		return nil, storages.ErrInvalidCredentials
+	case token.rotated:
+		m.revokeFamily(token.Family)
+		return nil, storages.ErrInvalidCredentials
+	}
+	now := time.Now().UTC()
+	if !token.ExpiresAt.After(now) {
+		return nil, storages.ErrInvalidCredentials
+	}
+	if _, ok := m.refreshes[next.Hash]; ok {
+		return nil, storages.ErrConflict
+	}
+	usr := m.findUser(token.UsrId)
+	if usr == nil {
+		return nil, storages.ErrInvalidCredentials
+	}
+
+	token.rotated = true
+	next.Id = m.nextRefId
+	next.UsrId = token.UsrId
+	next.Family = token.Family
+	next.CreateAt = now
+	m.nextRefId++
+	m.refreshes[next.Hash] = &refreshToken{RefreshToken: *next}
+
+	copied := *usr
+	return &copied, nil
+}
+
+// RevokeRefreshTokens revokes the family of the token of hash
+func (m *Memory) RevokeRefreshTokens(_ context.Context, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.refreshes[hash]
+	if !ok {
+		return storages.ErrInvalidCredentials
+	}
+	m.revokeFamily(token.Family)
+	return nil
+}
+
+// ListSessions returns the sessions of the user, the token of a session which has not been rotated is its latest one
+func (m *Memory) ListSessions(_ context.Context, usrId int) ([]*storages.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	sessions := make([]*storages.Session, 0)
+	for _, token := range m.refreshes {
+		if token.UsrId != usrId || token.rotated || token.revoked || !token.ExpiresAt.After(now) {
+			continue
+		}
+		first := m.refreshes[token.Family]
+		sessions = append(sessions, &storages.Session{
+			Id:         first.Id,
+			CreateAt:   first.CreateAt,
+			LastUsedAt: token.CreateAt,
+			ExpiresAt:  token.ExpiresAt,
+			UserAgent:  token.UserAgent,
+			IP:         token.IP,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastUsedAt.Equal(sessions[j].LastUsedAt) {
+			return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+		}
+		return sessions[i].Id > sessions[j].Id
+	})
+	return sessions, nil
+}
+
+// RevokeSession revokes the family whose first token is id
+func (m *Memory) RevokeSession(_ context.Context, usrId, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, token := range m.refreshes {
+		if token.Id == id && token.UsrId == usrId && token.Hash == token.Family {
+			m.revokeFamily(token.Family)
+			return nil
+		}
+	}
+	return storages.ErrNotFound
+}
+
+// RevokeSessions revokes every refresh token of the user
+func (m *Memory) RevokeSessions(_ context.Context, usrId int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, token := range m.refreshes {
+		if token.UsrId == usrId {
+			token.revoked = true
+		}
+	}
+	return nil
+}
+
+// revokeFamily revokes every token of the family, m.mu must be held
+func (m *Memory) revokeFamily(family string) {
+	for _, token := range m.refreshes {
+		if token.Family == family {
+			token.revoked = true
+		}
+	}
+}
+
+// passwordReset is a stored password reset token along with whether it has been used
+type passwordReset struct {
+	storages.PasswordReset
+	used bool
+}
+
+// GetUser returns a copy of the user of id
+func (m *Memory) GetUser(_ context.Context, id int) (*storages.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usr := m.findUser(id)
+	if usr == nil {
+		return nil, storages.ErrNotFound
+	}
+	return m.copyUser(usr), nil
+}
+
+// SetPassword replaces the password hash of the user and revokes their sessions
+func (m *Memory) SetPassword(_ context.Context, usrId int, pwdHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.setPassword(usrId, pwdHash)
+}
+
+// CreatePasswordReset saves reset for the user of username
+func (m *Memory) CreatePasswordReset(_ context.Context, username string, reset *storages.PasswordReset) (*storages.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usr, ok := m.users[username]
+	if !ok {
+		return nil, storages.ErrNotFound
+	}
+	if _, ok := m.resets[reset.Hash]; ok {
+		return nil, storages.ErrConflict
+	}
+	reset.Id = m.nextRstId
+	reset.UsrId = usr.Id
+	reset.CreateAt = time.Now().UTC()
+	m.nextRstId++
+	m.resets[reset.Hash] = &passwordReset{PasswordReset: *reset}
+
+	copied := *usr
+	return &copied, nil
+}
+
+// ResetPassword uses the reset token of hash under one lock
+func (m *Memory) ResetPassword(_ context.Context, hash, pwdHash string) (*storages.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reset, ok := m.resets[hash]
+	if !ok || reset.used || !reset.ExpiresAt.After(time.Now()) {
+		return nil, storages.ErrInvalidCredentials
+	}
+	if err := m.setPassword(reset.UsrId, pwdHash); err != nil {
+		return nil, err
+	}
+
+	copied := *m.findUser(reset.UsrId)
+	return &copied, nil
+}
+
+// setPassword replaces the password hash, revokes refresh tokens and uses up resets of the user, m.mu must be held
+func (m *Memory) setPassword(usrId int, pwdHash string) error {
+	usr := m.findUser(usrId)
+	if usr == nil {
+		return storages.ErrNotFound
+	}
+	usr.PwdHash = pwdHash
+	for _, token := range m.refreshes {
+		if token.UsrId == usrId {
+			token.revoked = true
+		}
+	}
+	for _, reset := range m.resets {
+		if reset.UsrId == usrId {
+			reset.used = true
+		}
+	}
+	return nil
+}
+
+// loginFailures counts failed logins of a key
+type loginFailures struct {
+	count  int
+	lastAt time.Time
+}
+
+// GetLoginFailures returns the failures of key
+func (m *Memory) GetLoginFailures(_ context.Context, key string) (int, time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.failures[key]
+	if !ok {
+		return 0, time.Time{}, nil
+	}
+	return f.count, f.lastAt, nil
+}
+
+// AddLoginFailure counts a failure of key at now
+func (m *Memory) AddLoginFailure(_ context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.failures[key]
+	if !ok || !f.lastAt.After(now.Add(-window)) {
+		f = &loginFailures{}
+		m.failures[key] = f
+	}
+	f.count++
+	f.lastAt = now
+	return f.count, nil
+}
+
+// ClearLoginFailures forgets the failures of key
+func (m *Memory) ClearLoginFailures(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.failures, key)
+	return nil
+}
+
+// idempotencyKey is an idempotency key of a user
+// usageKey is a day of a user, YYYY-MM-DD in their timezone
+type usageKey struct {
+	usrId int
+	day   string
+}
+
+func newUsageKey(usr *storages.User, task *storages.Task) usageKey {
+	return usageKey{usrId: usr.Id, day: task.CreateAt.In(usr.Location()).Format(usageLayout)}
+}
+
+const usageLayout = "2006-01-02"
+
+// GetUsage returns the days from from to to on which the user added tasks, counted by insertTask
+func (m *Memory) GetUsage(_ context.Context, usrId int, from, to time.Time) ([]*storages.Usage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// dates in the layout sort like strings
+	first, last := from.Format(usageLayout), to.Format(usageLayout)
+	usage := make([]*storages.Usage, 0)
+	for key, tasks := range m.usage {
+		if key.usrId != usrId || key.day < first || key.day > last || tasks == 0 {
+			continue
+		}
+		day, err := time.Parse(usageLayout, key.day)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, &storages.Usage{Day: day, Tasks: tasks})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Day.Before(usage[j].Day)
+	})
+	return usage, nil
+}
+
+type idempotencyKey struct {
+	usrId int
+	key   string
+}
+
+// GetIdempotentResponse returns the response saved under key for the user
+func (m *Memory) GetIdempotentResponse(_ context.Context, usrId int, key string, now time.Time) (*storages.IdempotentResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	resp, ok := m.idempotent[idempotencyKey{usrId, key}]
+	if !ok || !resp.ExpiresAt.After(now) {
+		return nil, storages.ErrNotFound
+	}
+	return copyIdempotentResponse(resp), nil
+}
+
+// SaveIdempotentResponse saves resp unless its key is taken, expired responses are deleted first
+func (m *Memory) SaveIdempotentResponse(_ context.Context, resp *storages.IdempotentResponse, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, saved := range m.idempotent {
+		if !saved.ExpiresAt.After(now) {
+			delete(m.idempotent, key)
+		}
+	}
+	key := idempotencyKey{resp.UsrId, resp.Key}
+	if _, ok := m.idempotent[key]; ok {
+		return storages.ErrConflict
+	}
+	m.idempotent[key] = copyIdempotentResponse(resp)
+	return nil
+}
+
+func copyIdempotentResponse(resp *storages.IdempotentResponse) *storages.IdempotentResponse {
+	copied := *resp
+	copied.Body = append([]byte(nil), resp.Body...)
+	return &copied
+}
+
+// identity is an account at an external identity provider
+type identity struct {
+	provider string
+	subject  string
+}
+
+// FindIdentityUser returns a copy of the user linked to subject of provider
+func (m *Memory) FindIdentityUser(_ context.Context, provider, subject string) (*storages.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, ok := m.identities[identity{provider, subject}]
+	if !ok {
+		return nil, storages.ErrNotFound
+	}
+	copied := *m.findUser(id)
+	return &copied, nil
+}
+
+// LinkIdentity links subject of provider to the user
+func (m *Memory) LinkIdentity(_ context.Context, usrId int, provider, subject string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findUser(usrId) == nil {
+		return storages.ErrNotFound
+	}
+	return m.linkIdentity(usrId, identity{provider, subject})
+}
+
+// CreateIdentityUser inserts usr and links subject of provider to it under one lock
+func (m *Memory) CreateIdentityUser(_ context.Context, usr *storages.User, provider, subject string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := identity{provider, subject}
+	if _, ok := m.identities[key]; ok {
+		return storages.ErrConflict
+	}
+	if err := m.createUser(usr); err != nil {
+		return err
+	}
+	return m.linkIdentity(usr.Id, key)
+}
+
+// linkIdentity links key to the user, m.mu must be held
+func (m *Memory) linkIdentity(usrId int, key identity) error {
+	if linked, ok := m.identities[key]; ok && linked != usrId {
+		return storages.ErrConflict
+	}
+	m.identities[key] = usrId
+	return nil
+}
+
+// ListUsers returns copies of all users by id without their password hashes
+func (m *Memory) ListUsers(_ context.Context) ([]*storages.User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]*storages.User, 0, len(m.users))
+	for _, usr := range m.users {
+		copied := m.copyUser(usr)
+		copied.PwdHash = ""
+		users = append(users, copied)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id < users[j].Id
+	})
+	return users, nil
+}
+
+// UpdateUser applies patch to the user of id
+func (m *Memory) UpdateUser(_ context.Context, id int, patch *storages.UserPatch) (*storages.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usr := m.findUser(id)
+	if usr == nil {
+		return nil, storages.ErrNotFound
+	}
+	if patch.Role != nil {
+		usr.Role = *patch.Role
+	}
+	if patch.MaxTodo != nil {
+		usr.MaxTodo = *patch.MaxTodo
+	}
+	copied := m.copyUser(usr)
+	copied.PwdHash = ""
+	return copied, nil
+}
+
+// ListPlans returns copies of the plans by name
+func (m *Memory) ListPlans(_ context.Context) ([]*storages.Plan, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	plans := make([]*storages.Plan, 0, len(m.plans))
+	for _, plan := range m.plans {
+		copied := *plan
+		plans = append(plans, &copied)
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].Name < plans[j].Name
+	})
+	return plans, nil
+}
+
+// PutPlan creates the plan or changes its daily-limit
+func (m *Memory) PutPlan(_ context.Context, plan *storages.Plan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	copied := *plan
+	m.plans[plan.Name] = &copied
+	return nil
+}
+
+// DeletePlan deletes the plan of name, its users get their own daily-limits back
+func (m *Memory) DeletePlan(_ context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.plans[name]; !ok {
+		return storages.ErrNotFound
+	}
+	delete(m.plans, name)
+	for _, usr := range m.users {
+		if usr.Plan == name {
+			usr.Plan = ""
+		}
+	}
+	return nil
+}
+
+// SetUserPlan puts the user on the plan of name, "" takes them off their plan
+func (m *Memory) SetUserPlan(_ context.Context, usrId int, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usr := m.findUser(usrId)
+	if usr == nil {
+		return storages.ErrNotFound
+	}
+	if _, ok := m.plans[name]; name != "" && !ok {
+		return storages.ErrNotFound
+	}
+	usr.Plan = name
+	return nil
+}
+
+// GetTimezone returns the timezone of the user, "" for UTC
+func (m *Memory) GetTimezone(_ context.Context, usrId int) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usr := m.findUser(usrId)
+	if usr == nil {
+		return "", storages.ErrNotFound
+	}
+	return usr.Timezone, nil
+}
+
+// SetTimezone changes the timezone of the user to one known by the time package
+func (m *Memory) SetTimezone(_ context.Context, usrId int, timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return storages.ErrInvalidTimezone
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usr := m.findUser(usrId)
+	if usr == nil {
+		return storages.ErrNotFound
+	}
+	usr.Timezone = timezone
+	return nil
+}
+
+// twoFactor is a stored enrollment along with the hashes of its unused backup codes
+type twoFactor struct {
+	storages.TwoFactor
+	codes map[string]bool
+}
+
+// GetTwoFactor returns the enrollment of the user without its backup codes
+func (m *Memory) GetTwoFactor(_ context.Context, usrId int) (*storages.TwoFactor, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tf, ok := m.twoFactors[usrId]
+	if !ok {
+		return nil, storages.ErrNotFound
+	}
+	copied := tf.TwoFactor
+	return &copied, nil
+}
+
+// SaveTwoFactor replaces a pending enrollment of the user
+func (m *Memory) SaveTwoFactor(_ context.Context, tf *storages.TwoFactor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findUser(tf.UsrId) == nil {
+		return storages.ErrNotFound
+	}
+	if saved, ok := m.twoFactors[tf.UsrId]; ok && saved.Enabled {
+		return storages.ErrConflict
+	}
+	saved := &twoFactor{TwoFactor: storages.TwoFactor{UsrId: tf.UsrId, Secret: tf.Secret}, codes: make(map[string]bool)}
+	for _, hash := range tf.BackupCodes {
+		saved.codes[hash] = true
+	}
+	m.twoFactors[tf.UsrId] = saved
+	return nil
+}
+
+// EnableTwoFactor enables the pending enrollment of the user
+func (m *Memory) EnableTwoFactor(_ context.Context, usrId int, counter int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tf, ok := m.twoFactors[usrId]
+	if !ok || tf.Enabled {
+		return storages.ErrNotFound
+	}
+	tf.Enabled, tf.LastCounter = true, counter
+	return nil
+}
+
+// UseTOTP records counter as the last period of the user unless a code of it or a later one was accepted
+func (m *Memory) UseTOTP(_ context.Context, usrId int, counter int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tf, ok := m.twoFactors[usrId]
+	if !ok || !tf.Enabled || tf.LastCounter >= counter {
+		return storages.ErrInvalidCredentials
+	}
+	tf.LastCounter = counter
+	return nil
+}
+
+// UseBackupCode deletes the backup code of hash of the user
+func (m *Memory) UseBackupCode(_ context.Context, usrId int, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tf, ok := m.twoFactors[usrId]
+	if !ok || !tf.codes[hash] {
+		return storages.ErrInvalidCredentials
+	}
+	delete(tf.codes, hash)
+	return nil
+}
+
+// DisableTwoFactor deletes the enrollment of the user
+func (m *Memory) DisableTwoFactor(_ context.Context, usrId int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.twoFactors, usrId)
+	return nil
+}
+
+// share keys shares by the owner and the user they are shared with
+type share struct {
+	ownerId, usrId int
+}
+
+// GetShare returns the share of the owner with the user
+func (m *Memory) GetShare(_ context.Context, ownerId, usrId int) (*storages.Share, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	saved, ok := m.shares[share{ownerId, usrId}]
+	if !ok {
+		return nil, storages.ErrNotFound
+	}
+	copied := *saved
+	return &copied, nil
+}
+
+// ListShares returns the shares of the owner in the order they were created
+func (m *Memory) ListShares(_ context.Context, ownerId int) ([]*storages.Share, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	shares := make([]*storages.Share, 0)
+	for key, saved := range m.shares {
+		if key.ownerId == ownerId {
+			copied := *saved
+			shares = append(shares, &copied)
+		}
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if !shares[i].CreateAt.Equal(shares[j].CreateAt) {
+			return shares[i].CreateAt.Before(shares[j].CreateAt)
+		}
+		return shares[i].UsrId < shares[j].UsrId
+	})
+	return shares, nil
+}
+
+// SaveShare creates the share or replaces its access
+func (m *Memory) SaveShare(_ context.Context, s *storages.Share) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findUser(s.OwnerId) == nil || m.findUser(s.UsrId) == nil || s.OwnerId == s.UsrId {
+		return storages.ErrNotFound
+	}
+	key := share{s.OwnerId, s.UsrId}
+	if saved, ok := m.shares[key]; ok {
+		s.CreateAt = saved.CreateAt
+	} else {
+		s.CreateAt = time.Now()
+	}
+	copied := *s
+	m.shares[key] = &copied
+	return nil
+}
+
+// DeleteShare deletes the share of the owner with the user
+func (m *Memory) DeleteShare(_ context.Context, ownerId, usrId int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := share{ownerId, usrId}
+	if _, ok := m.shares[key]; !ok {
+		return storages.ErrNotFound
+	}
+	delete(m.shares, key)
+	return nil
+}
+
+// AppendAudit appends the event to the log
+func (m *Memory) AppendAudit(_ context.Context, event *storages.AuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	event.Id = len(m.audit) + 1
+	event.CreateAt = time.Now()
+	copied := *event
+	m.audit = append(m.audit, &copied)
+	return nil
+}
+
+// AppendTaskAudit appends the entry to the history of its task
+func (m *Memory) AppendTaskAudit(_ context.Context, entry *storages.TaskAuditEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry.Id = len(m.taskAudit) + 1
+	entry.CreateAt = time.Now()
+	m.taskAudit = append(m.taskAudit, copyTaskAuditEntry(entry))
+	return nil
+}
+
+// GetTaskHistory returns the entries of the task of the user oldest first
+func (m *Memory) GetTaskHistory(_ context.Context, usrId, taskId int) ([]*storages.TaskAuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := make([]*storages.TaskAuditEntry, 0)
+	for _, entry := range m.taskAudit {
+		if entry.TaskId == taskId && entry.UsrId == usrId {
+			entries = append(entries, copyTaskAuditEntry(entry))
+		}
+	}
+	if len(entries) == 0 {
+		return nil, storages.ErrNotFound
+	}
+	return entries, nil
+}
+
+func copyTaskAuditEntry(entry *storages.TaskAuditEntry) *storages.TaskAuditEntry {
+	copied := *entry
+	if entry.Before != nil {
+		copied.Before = copyTask(entry.Before)
+	}
+	if entry.After != nil {
+		copied.After = copyTask(entry.After)
+	}
+	return &copied
+}
+
+// ListAudit returns the events picked by the filter latest first
+func (m *Memory) ListAudit(_ context.Context, filter storages.AuditFilter) ([]*storages.AuditEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := make([]*storages.AuditEvent, 0)
+	for i := len(m.audit) - 1; i >= 0 && (filter.Limit <= 0 || len(events) < filter.Limit); i-- {
+		event := m.audit[i]
+		if filter.UsrId != 0 && event.ActorId != filter.UsrId && event.UsrId != filter.UsrId {
+			continue
+		}
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if !filter.Since.IsZero() && event.CreateAt.Before(filter.Since) {
+			continue
+		}
+		copied := *event
+		events = append(events, &copied)
+	}
+	return events, nil
+}
+
+// GetStats counts the tasks the user created and completed by day in loc
+func (m *Memory) GetStats(_ context.Context, usrId int, from, to time.Time, loc *time.Location) ([]*storages.DayStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// dates in the layout sort like strings
+	first, last := from.Format(usageLayout), to.Format(usageLayout)
+	days := make(map[string]*storages.DayStats)
+	count := func(at time.Time) *storages.DayStats {
+		key := at.In(loc).Format(usageLayout)
+		if key < first || key > last {
+			return nil
+		}
+		if days[key] == nil {
+			day, _ := time.Parse(usageLayout, key)
+			days[key] = &storages.DayStats{Day: day}
+		}
+		return days[key]
+	}
+	for _, task := range m.tasks {
+		if task.UsrId != usrId || task.DeletedAt != nil {
+			continue
+		}
+		if day := count(task.CreateAt); day != nil {
+			day.Created++
+		}
+		if task.CompletedAt != nil {
+			if day := count(*task.CompletedAt); day != nil {
+				day.Completed++
+			}
+		}
+	}
+
+	stats := make([]*storages.DayStats, 0, len(days))
+	for _, day := range days {
+		stats = append(stats, day)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Day.Before(stats[j].Day)
+	})
+	return stats, nil
+}
@@ -0,0 +1,1183 @@
+package memory
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryValidateUser(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	usr, err := m.ValidateUser(context.Background(), "firstUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(1, usr.Id)
+
+	_, err = m.ValidateUser(context.Background(), "firstUser", "wrong")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+}
+
+func TestMemoryGetTasks(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	tasks, err := m.GetTasks(context.Background(), 1, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+
+	tasks, err = m.GetTasks(context.Background(), 2, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+}
+
+func TestMemoryInsertTaskConcurrentDailyLimit(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- m.InsertTask(context.Background(), &storages.Task{UsrId: 1, Content: "content"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	inserted := 0
+	for err := range errs {
+		if err == nil {
+			inserted++
+		} else {
+			requireTest.Equal(storages.ErrQuotaExceeded, err)
+		}
+	}
+	requireTest.Equal(5, inserted)
+
+	tasks, err := m.GetTasks(context.Background(), 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 5)
+}
+
+func TestMemoryInsertTaskUnknownUser(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	err = m.InsertTask(context.Background(), &storages.Task{UsrId: 42, Content: "content"})
+	requireTest.Equal(storages.ErrNotFound, err)
+}
+
+func TestMemoryDeleteArchiveTask(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	task := &storages.Task{UsrId: 1, Content: "content"}
+	requireTest.NoError(m.InsertTask(ctx, task))
+
+	requireTest.NoError(m.ArchiveTask(ctx, 1, task.Id))
+	tasks, err := m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.NotNil(tasks[0].ArchivedAt)
+
+	requireTest.Equal(storages.ErrForbidden, m.DeleteTask(ctx, 2, task.Id))
+	requireTest.NoError(m.DeleteTask(ctx, 1, task.Id))
+	requireTest.Equal(storages.ErrNotFound, m.DeleteTask(ctx, 1, task.Id))
+	requireTest.Equal(storages.ErrNotFound, m.ArchiveTask(ctx, 1, task.Id))
+
+	tasks, err = m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+
+	tasks, err = m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{IncludeDeleted: true})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.NotNil(tasks[0].DeletedAt)
+}
+
+func TestMemorySeed(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	fixtures := &storages.Fixtures{
+		Users: []storages.FixtureUser{
+			{Id: 1, Username: "firstUser", Password: "changed", MaxTodo: 1},
+			{Username: "secondUser", Password: "example", MaxTodo: 1},
+		},
+		Tasks: []*storages.Task{
+			{UsrId: 2, Content: "content", CreateAt: time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	requireTest.NoError(m.Seed(ctx, fixtures))
+	requireTest.NoError(m.Seed(ctx, &storages.Fixtures{Users: fixtures.Users}))
+
+	// existing users are left as is
+	_, err = m.ValidateUser(ctx, "firstUser", "example")
+	requireTest.NoError(err)
+
+	usr, err := m.ValidateUser(ctx, "secondUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(2, usr.Id)
+
+	tasks, err := m.GetTasks(ctx, 2, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal(2, tasks[0].Id)
+}
+
+func TestMemoryTaskMetadata(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	task := &storages.Task{UsrId: 1, Content: "content"}
+	requireTest.NoError(m.InsertTask(ctx, task))
+	requireTest.Equal(storages.TaskStatusTodo, task.Status)
+
+	dueAt := time.Now().Add(time.Hour).UTC()
+	requireTest.NoError(m.SetTaskDueAt(ctx, 1, task.Id, &dueAt))
+	requireTest.NoError(m.SetTaskPriority(ctx, 1, task.Id, 3))
+	requireTest.NoError(m.SetTaskStatus(ctx, 1, task.Id, storages.TaskStatusDone))
+	requireTest.Equal(storages.ErrInvalidTask, m.SetTaskStatus(ctx, 1, task.Id, "unknown"))
+	requireTest.Equal(storages.ErrNotFound, m.SetTaskPriority(ctx, 1, 42, 3))
+
+	tasks, err := m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal(storages.TaskStatusDone, tasks[0].Status)
+	requireTest.NotNil(tasks[0].CompletedAt)
+	requireTest.Equal(dueAt, *tasks[0].DueAt)
+	requireTest.Equal(3, tasks[0].Priority)
+
+	requireTest.NoError(m.SetTaskStatus(ctx, 1, task.Id, storages.TaskStatusDoing))
+	tasks, err = m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Nil(tasks[0].CompletedAt)
+
+	requireTest.Equal(storages.ErrInvalidTask, m.InsertTask(ctx, &storages.Task{UsrId: 1, Status: "unknown"}))
+}
+
+func TestMemoryUpdateTask(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	task := &storages.Task{UsrId: 1, Content: "content"}
+	requireTest.NoError(m.InsertTask(ctx, task))
+	requireTest.Equal(1, task.Version)
+
+	first := *task
+	first.Content = "first edit"
+	first.Status = storages.TaskStatusDone
+	requireTest.NoError(m.UpdateTask(ctx, &first))
+	requireTest.Equal(2, first.Version)
+	requireTest.NotNil(first.CompletedAt)
+	requireTest.Equal(task.CreateAt, first.CreateAt)
+
+	// based on the stale version
+	second := *task
+	second.Content = "second edit"
+	requireTest.Equal(storages.ErrConflict, m.UpdateTask(ctx, &second))
+
+	missing := &storages.Task{Id: 42, UsrId: 1, Version: 1}
+	requireTest.Equal(storages.ErrNotFound, m.UpdateTask(ctx, missing))
+
+	tasks, err := m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Equal("first edit", tasks[0].Content)
+}
+
+func TestMemoryFindTasksCompleted(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	done := &storages.Task{UsrId: 1, Content: "done", Status: storages.TaskStatusDone}
+	requireTest.NoError(m.InsertTask(ctx, done))
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "todo"}))
+
+	completed := true
+	tasks, err := m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{Completed: &completed})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal(done.Id, tasks[0].Id)
+
+	completed = false
+	tasks, err = m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{Completed: &completed})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("todo", tasks[0].Content)
+}
+
+func TestMemoryFindOverdueTasks(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	now := time.Now()
+	later, earlier, future := now.Add(-time.Hour), now.Add(-2*time.Hour), now.Add(time.Hour)
+	for _, task := range []*storages.Task{
+		{UsrId: 1, Content: "later", DueAt: &later},
+		{UsrId: 1, Content: "done", DueAt: &earlier, Status: storages.TaskStatusDone},
+		{UsrId: 1, Content: "earlier", DueAt: &earlier},
+		{UsrId: 1, Content: "future", DueAt: &future},
+		{UsrId: 1, Content: "no due date"},
+	} {
+		requireTest.NoError(m.InsertTask(ctx, task))
+	}
+
+	tasks, err := m.FindOverdueTasks(ctx, 1, now)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 2)
+	requireTest.Equal("earlier", tasks[0].Content)
+	requireTest.Equal("later", tasks[1].Content)
+}
+
+func TestMemoryFindTasksSortByPriority(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	for i, priority := range []int{1, 3, 1, 2} {
+		requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: strconv.Itoa(i), Priority: priority}))
+	}
+
+	tasks, err := m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{SortBy: storages.TaskSortPriority})
+	requireTest.NoError(err)
+	contents := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		contents = append(contents, task.Content)
+	}
+	requireTest.Equal([]string{"1", "3", "0", "2"}, contents)
+
+	_, err = m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{SortBy: "content"})
+	requireTest.Equal(storages.ErrInvalidTask, err)
+}
+
+func TestMemoryTaskTags(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	work := &storages.Task{UsrId: 1, Content: "work", Tags: []string{"Work"}}
+	requireTest.NoError(m.InsertTask(ctx, work))
+	requireTest.Equal([]string{"work"}, work.Tags)
+
+	both := &storages.Task{UsrId: 1, Content: "both"}
+	requireTest.NoError(m.InsertTask(ctx, both))
+	requireTest.NoError(m.AddTaskTags(ctx, 1, both.Id, []string{"work", "home", "home"}))
+	requireTest.Equal(storages.ErrNotFound, m.AddTaskTags(ctx, 2, both.Id, []string{"work"}))
+	requireTest.Equal(storages.ErrInvalidTask, m.AddTaskTags(ctx, 1, both.Id, []string{""}))
+
+	tasks, err := m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{Tags: []string{"work"}})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 2)
+
+	tasks, err = m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{Tags: []string{"work", "home"}})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal([]string{"home", "work"}, tasks[0].Tags)
+
+	requireTest.NoError(m.RemoveTaskTags(ctx, 1, both.Id, []string{"home", "missing"}))
+	tasks, err = m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{Tags: []string{"home"}})
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+}
+
+func TestMemoryProjects(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	maxTodo := 1
+	project := &storages.Project{UsrId: 1, Name: " work ", MaxTodo: &maxTodo}
+	requireTest.NoError(m.CreateProject(ctx, project))
+	requireTest.Equal("work", project.Name)
+	requireTest.Equal(storages.ErrConflict, m.CreateProject(ctx, &storages.Project{UsrId: 1, Name: "work"}))
+	requireTest.Equal(storages.ErrInvalidProject, m.CreateProject(ctx, &storages.Project{UsrId: 1, Name: " "}))
+
+	// the project's daily-limit is on top of the user's one
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "first", ProjectId: &project.Id}))
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "second", ProjectId: &project.Id}))
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "no project"}))
+
+	other := &storages.Project{UsrId: 2, Name: "other"}
+	_, err = m.AddUser("secondUser", "example", 5)
+	requireTest.NoError(err)
+	requireTest.NoError(m.CreateProject(ctx, other))
+	requireTest.Equal(storages.ErrNotFound, m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "other", ProjectId: &other.Id}))
+	_, err = m.GetProject(ctx, 1, other.Id)
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	tasks, err := m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{ProjectId: &project.Id})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("first", tasks[0].Content)
+
+	project.Name = "office"
+	project.MaxTodo = nil
+	requireTest.NoError(m.UpdateProject(ctx, project))
+	projects, err := m.GetProjects(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Len(projects, 1)
+	requireTest.Equal("office", projects[0].Name)
+	requireTest.Nil(projects[0].MaxTodo)
+
+	requireTest.NoError(m.DeleteProject(ctx, 1, project.Id))
+	requireTest.Equal(storages.ErrNotFound, m.DeleteProject(ctx, 1, project.Id))
+	tasks, err = m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	for _, task := range tasks {
+		requireTest.Nil(task.ProjectId)
+	}
+}
+
+func TestMemoryChecklist(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	first := &storages.ChecklistItem{TaskId: 1, Content: " step 1 "}
+	second := &storages.ChecklistItem{TaskId: 1, Content: "step 2"}
+	requireTest.NoError(m.AddChecklistItem(ctx, 1, first))
+	requireTest.NoError(m.AddChecklistItem(ctx, 1, second))
+	requireTest.Equal("step 1", first.Content)
+	requireTest.Equal(2, second.Position)
+	requireTest.Equal(storages.ErrInvalidTask, m.AddChecklistItem(ctx, 1, &storages.ChecklistItem{TaskId: 1}))
+	requireTest.Equal(storages.ErrNotFound, m.AddChecklistItem(ctx, 2, &storages.ChecklistItem{TaskId: 1, Content: "other"}))
+
+	requireTest.NoError(m.SetChecklistItemDone(ctx, 1, 1, second.Id, true))
+	requireTest.Equal(storages.ErrNotFound, m.SetChecklistItemDone(ctx, 1, 1, 100, true))
+
+	requireTest.Equal(storages.ErrInvalidTask, m.ReorderChecklist(ctx, 1, 1, []int{second.Id}))
+	requireTest.NoError(m.ReorderChecklist(ctx, 1, 1, []int{second.Id, first.Id}))
+
+	items, err := m.GetChecklist(ctx, 1, 1)
+	requireTest.NoError(err)
+	requireTest.Len(items, 2)
+	requireTest.Equal(second.Id, items[0].Id)
+	requireTest.True(items[0].Done)
+
+	tasks, err := m.GetTasks(ctx, 1, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Equal(&storages.Checklist{Total: 2, Done: 1}, tasks[0].Checklist)
+	requireTest.False(tasks[0].Checklist.Completed())
+	// two items added, one completed and one reorder
+	requireTest.Equal(5, tasks[0].Version)
+}
+
+func TestMemoryMaterializeRecurrences(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	task := &storages.Task{UsrId: 1, Content: "standup", Recurrence: storages.RecurrenceWeekly}
+	requireTest.NoError(m.InsertTask(ctx, task))
+	requireTest.NotNil(task.RecurAt)
+
+	n, err := m.MaterializeRecurrences(ctx, task.CreateAt)
+	requireTest.NoError(err)
+	requireTest.Equal(0, n)
+
+	// completing creates the next occurrence once
+	requireTest.NoError(m.SetTaskStatus(ctx, 1, task.Id, storages.TaskStatusDone))
+	for i := 0; i < 2; i++ {
+		n, err = m.MaterializeRecurrences(ctx, task.CreateAt)
+		requireTest.NoError(err)
+		requireTest.Equal(1-i, n)
+	}
+
+	tasks, err := m.GetTasks(ctx, 1, task.CreateAt.AddDate(0, 0, 7))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("standup", tasks[0].Content)
+	requireTest.Equal(storages.TaskStatusTodo, tasks[0].Status)
+	requireTest.Equal(storages.RecurrenceWeekly, tasks[0].Recurrence)
+
+	requireTest.Equal(storages.ErrInvalidTask, m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "x", Recurrence: "hourly"}))
+}
+
+func TestMemoryComments(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	comment := &storages.Comment{TaskId: 1, UsrId: 1, Content: " first "}
+	requireTest.NoError(m.AddComment(ctx, comment))
+	requireTest.Equal("first", comment.Content)
+	requireTest.NoError(m.AddComment(ctx, &storages.Comment{TaskId: 1, UsrId: 1, Content: "second"}))
+	requireTest.Equal(storages.ErrInvalidTask, m.AddComment(ctx, &storages.Comment{TaskId: 1, UsrId: 1}))
+	requireTest.Equal(storages.ErrNotFound, m.AddComment(ctx, &storages.Comment{TaskId: 1, UsrId: 2, Content: "other"}))
+
+	requireTest.NoError(m.DeleteComment(ctx, 1, 1, comment.Id))
+	requireTest.Equal(storages.ErrNotFound, m.DeleteComment(ctx, 1, 1, comment.Id))
+
+	comments, err := m.GetComments(ctx, 1, 1)
+	requireTest.NoError(err)
+	requireTest.Len(comments, 1)
+	requireTest.Equal("second", comments[0].Content)
+
+	_, err = m.GetComments(ctx, 2, 1)
+	requireTest.Equal(storages.ErrNotFound, err)
+}
+
+func TestMemoryAttachments(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	attachment := &storages.Attachment{TaskId: 1, UsrId: 1, Name: "notes.txt", ContentType: "text/plain", Size: 5, BlobKey: "tasks/1/a"}
+	requireTest.NoError(m.AddAttachment(ctx, attachment))
+	requireTest.NoError(m.AddAttachment(ctx, &storages.Attachment{TaskId: 1, UsrId: 1, Name: "b.txt", BlobKey: "tasks/1/b"}))
+	requireTest.Equal(storages.ErrInvalidTask, m.AddAttachment(ctx, &storages.Attachment{TaskId: 1, UsrId: 1, BlobKey: "tasks/1/c"}))
+	requireTest.Equal(storages.ErrNotFound, m.AddAttachment(ctx, &storages.Attachment{TaskId: 1, UsrId: 2, Name: "c", BlobKey: "tasks/1/c"}))
+
+	got, err := m.GetAttachment(ctx, 1, 1, attachment.Id)
+	requireTest.NoError(err)
+	requireTest.Equal(attachment, got)
+
+	deleted, err := m.DeleteAttachment(ctx, 1, 1, attachment.Id)
+	requireTest.NoError(err)
+	requireTest.Equal("tasks/1/a", deleted.BlobKey)
+	_, err = m.GetAttachment(ctx, 1, 1, attachment.Id)
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	// attachments are only purged once the task is deleted
+	purged, err := m.PurgeAttachments(ctx, 1, 1)
+	requireTest.NoError(err)
+	requireTest.Empty(purged)
+	requireTest.NoError(m.DeleteTask(ctx, 1, 1))
+	purged, err = m.PurgeAttachments(ctx, 1, 1)
+	requireTest.NoError(err)
+	requireTest.Len(purged, 1)
+	requireTest.Equal("tasks/1/b", purged[0].BlobKey)
+}
+
+func TestMemoryMoveTask(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	ids := make([]int, 0, 3)
+	for i := 0; i < 3; i++ {
+		task := &storages.Task{UsrId: 1, Content: strconv.Itoa(i)}
+		requireTest.NoError(m.InsertTask(ctx, task))
+		ids = append(ids, task.Id)
+	}
+
+	// move the last one between the first two until positions have to be renumbered
+	for i := 0; i < 60; i++ {
+		requireTest.NoError(m.MoveTask(ctx, 1, ids[2], &ids[0], &ids[1]))
+		requireTest.NoError(m.MoveTask(ctx, 1, ids[1], &ids[2], nil))
+		ids[1], ids[2] = ids[2], ids[1]
+	}
+	requireTest.Equal(storages.ErrConflict, m.MoveTask(ctx, 1, ids[0], &ids[2], &ids[1]))
+	requireTest.Equal(storages.ErrNotFound, m.MoveTask(ctx, 1, ids[0], nil, &[]int{100}[0]))
+	requireTest.Equal(storages.ErrInvalidTask, m.MoveTask(ctx, 1, ids[0], &ids[0], nil))
+
+	tasks, err := m.FindTasks(ctx, 1, time.Now(), storages.TaskFilter{SortBy: storages.TaskSortPosition})
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 3)
+	for i, task := range tasks {
+		requireTest.Equal(ids[i], task.Id)
+	}
+}
+
+func TestMemoryInsertTasks(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	tasks := []*storages.Task{{UsrId: 1, Content: "a"}, {UsrId: 1, Content: "b"}}
+	requireTest.NoError(m.InsertTasks(ctx, tasks))
+	requireTest.NotZero(tasks[0].Id)
+	requireTest.Equal(tasks[0].Id+1, tasks[1].Id)
+
+	projectId := 42
+	err = m.InsertTasks(ctx, []*storages.Task{
+		{UsrId: 1, Content: "c"},
+		{UsrId: 1, Content: "d", Status: "unknown"},
+		{UsrId: 1, Content: "e", ProjectId: &projectId},
+	})
+	requireTest.Equal(storages.BatchError{1: storages.ErrInvalidTask}, err)
+
+	err = m.InsertTasks(ctx, []*storages.Task{{UsrId: 1, Content: "c"}, {UsrId: 1, Content: "e", ProjectId: &projectId}})
+	requireTest.Equal(storages.BatchError{1: storages.ErrNotFound}, err)
+
+	// the default user may add 5 tasks a day
+	err = m.InsertTasks(ctx, []*storages.Task{{UsrId: 1, Content: "c"}, {UsrId: 1, Content: "d"}, {UsrId: 1, Content: "e"}, {UsrId: 1, Content: "f"}})
+	requireTest.Equal(storages.BatchError{3: storages.ErrQuotaExceeded}, err)
+
+	requireTest.NoError(m.InsertTasks(ctx, []*storages.Task{{UsrId: 1, Content: "c"}, {UsrId: 1, Content: "d"}, {UsrId: 1, Content: "e"}}))
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "f"}))
+}
+
+func TestMemoryBulkUpdate(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	tasks := []*storages.Task{{UsrId: 1, Content: "a", Tags: []string{"work"}}, {UsrId: 1, Content: "b"}, {UsrId: 1, Content: "c"}}
+	requireTest.NoError(m.InsertTasks(ctx, tasks))
+
+	_, err = m.CompleteTasks(ctx, 1, storages.TaskSelector{})
+	requireTest.Equal(storages.ErrInvalidTask, err)
+
+	ids, err := m.CompleteTasks(ctx, 1, storages.TaskSelector{Ids: []int{tasks[0].Id, tasks[1].Id}})
+	requireTest.NoError(err)
+	requireTest.Equal([]int{tasks[0].Id, tasks[1].Id}, ids)
+
+	// done tasks are not completed again
+	ids, err = m.CompleteTasks(ctx, 1, storages.TaskSelector{Tags: []string{"Work"}})
+	requireTest.NoError(err)
+	requireTest.Empty(ids)
+
+	ids, err = m.DeleteTasks(ctx, 2, storages.TaskSelector{Ids: []int{tasks[2].Id}})
+	requireTest.NoError(err)
+	requireTest.Empty(ids)
+
+	completed, future := true, time.Now().Add(time.Hour)
+	ids, err = m.DeleteTasks(ctx, 1, storages.TaskSelector{Completed: &completed, CompletedBefore: &future})
+	requireTest.NoError(err)
+	requireTest.Equal([]int{tasks[0].Id, tasks[1].Id}, ids)
+
+	left, err := m.GetTasks(ctx, 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(left, 1)
+	requireTest.Equal(tasks[2].Id, left[0].Id)
+	requireTest.Equal(storages.TaskStatusTodo, left[0].Status)
+}
+
+func TestMemoryTemplates(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	task := &storages.Task{UsrId: 1, Content: "pack", Tags: []string{"trip"}}
+	requireTest.NoError(m.InsertTask(ctx, task))
+	requireTest.NoError(m.AddChecklistItem(ctx, 1, &storages.ChecklistItem{TaskId: task.Id, Content: "passport"}))
+
+	requireTest.Equal(storages.ErrNotFound, m.CreateTemplate(ctx, &storages.Template{UsrId: 1, Name: "trip"}, []int{42}))
+	requireTest.Equal(storages.ErrInvalidTask, m.CreateTemplate(ctx, &storages.Template{UsrId: 1, Name: "trip"}, nil))
+
+	template := &storages.Template{UsrId: 1, Name: " trip ", Tasks: []storages.TemplateTask{{Content: "book hotel"}}}
+	requireTest.NoError(m.CreateTemplate(ctx, template, []int{task.Id}))
+	requireTest.Equal("trip", template.Name)
+	requireTest.Equal([]storages.TemplateTask{
+		{Content: "book hotel", ContentFormat: storages.ContentFormatPlain, Tags: []string{}},
+		{Content: "pack", ContentFormat: storages.ContentFormatPlain, Tags: []string{"trip"}, Items: []string{"passport"}},
+	}, template.Tasks)
+
+	_, err = m.InstantiateTemplate(ctx, 2, template.Id)
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	tasks, err := m.InstantiateTemplate(ctx, 1, template.Id)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 2)
+	requireTest.Equal(&storages.Checklist{Total: 1}, tasks[1].Checklist)
+	items, err := m.GetChecklist(ctx, 1, tasks[1].Id)
+	requireTest.NoError(err)
+	requireTest.Equal("passport", items[0].Content)
+
+	// 3 of 5 tasks of the day are taken, both tasks of the template don't fit in twice
+	_, err = m.InstantiateTemplate(ctx, 1, template.Id)
+	requireTest.NoError(err)
+	_, err = m.InstantiateTemplate(ctx, 1, template.Id)
+	requireTest.Equal(storages.ErrQuotaExceeded, err)
+
+	requireTest.NoError(m.DeleteTemplate(ctx, 1, template.Id))
+	templates, err := m.GetTemplates(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Empty(templates)
+}
+
+func TestMemoryReminders(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	remindAt := now.Add(-time.Minute)
+	task := &storages.Task{UsrId: 1, Content: "call mom", RemindAt: &remindAt, RemindedAt: &remindAt}
+	requireTest.NoError(m.InsertTask(ctx, task))
+	requireTest.Nil(task.RemindedAt)
+
+	tasks, err := m.ClaimReminders(ctx, now, time.Minute, 10)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+
+	// leased reminders are skipped until the lease expires
+	tasks, err = m.ClaimReminders(ctx, now, time.Minute, 10)
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+	tasks, err = m.ClaimReminders(ctx, now.Add(time.Minute), time.Minute, 10)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+
+	requireTest.NoError(m.AckReminder(ctx, task.Id, remindAt))
+	tasks, err = m.ClaimReminders(ctx, now.Add(time.Hour), time.Minute, 10)
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+
+	// a new reminder is delivered again, an outdated ack doesn't mark it delivered
+	requireTest.NoError(m.SetTaskRemindAt(ctx, 1, task.Id, &now))
+	requireTest.NoError(m.AckReminder(ctx, task.Id, remindAt))
+	tasks, err = m.ClaimReminders(ctx, now, time.Minute, 10)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Nil(tasks[0].RemindedAt)
+
+	requireTest.Equal(storages.ErrNotFound, m.SetTaskRemindAt(ctx, 2, task.Id, nil))
+}
+
+func TestMemoryTrash(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	tasks := []*storages.Task{{UsrId: 1, Content: "a"}, {UsrId: 1, Content: "b"}, {UsrId: 1, Content: "c"}}
+	requireTest.NoError(m.InsertTasks(ctx, tasks))
+	requireTest.NoError(m.AddAttachment(ctx, &storages.Attachment{TaskId: tasks[0].Id, UsrId: 1, Name: "a.txt", BlobKey: "tasks/a"}))
+	requireTest.NoError(m.DeleteTask(ctx, 1, tasks[0].Id))
+	requireTest.NoError(m.DeleteTask(ctx, 1, tasks[1].Id))
+
+	trash, err := m.GetTrash(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Len(trash, 2)
+	requireTest.Equal(tasks[1].Id, trash[0].Id)
+
+	trash, err = m.GetTrash(ctx, 2)
+	requireTest.NoError(err)
+	requireTest.Empty(trash)
+
+	requireTest.Equal(storages.ErrNotFound, m.RestoreTask(ctx, 2, tasks[1].Id))
+	requireTest.Equal(storages.ErrNotFound, m.RestoreTask(ctx, 1, tasks[2].Id))
+	requireTest.NoError(m.RestoreTask(ctx, 1, tasks[1].Id))
+	_, err = m.GetChecklist(ctx, 1, tasks[1].Id)
+	requireTest.NoError(err)
+
+	// tasks deleted after before are kept
+	n, _, err := m.PurgeTrash(ctx, time.Now().Add(-time.Hour), 10)
+	requireTest.NoError(err)
+	requireTest.Zero(n)
+
+	n, attachments, err := m.PurgeTrash(ctx, time.Now().Add(time.Hour), 10)
+	requireTest.NoError(err)
+	requireTest.Equal(1, n)
+	requireTest.Len(attachments, 1)
+	requireTest.Equal("tasks/a", attachments[0].BlobKey)
+
+	trash, err = m.GetTrash(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Empty(trash)
+	requireTest.Equal(storages.ErrNotFound, m.RestoreTask(ctx, 1, tasks[0].Id))
+}
+
+func TestMemoryRefreshTokens(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+	first := &storages.RefreshToken{UsrId: 1, Hash: "a", ExpiresAt: expiresAt}
+	requireTest.NoError(m.CreateRefreshToken(ctx, first))
+	requireTest.Equal("a", first.Family)
+
+	second := &storages.RefreshToken{Hash: "b", ExpiresAt: expiresAt}
+	usr, err := m.RotateRefreshToken(ctx, "a", second)
+	requireTest.NoError(err)
+	requireTest.Equal(1, usr.Id)
+	requireTest.Equal("a", second.Family)
+
+	// reusing a rotated token revokes the newer ones too
+	_, err = m.RotateRefreshToken(ctx, "a", &storages.RefreshToken{Hash: "c", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+	_, err = m.RotateRefreshToken(ctx, "b", &storages.RefreshToken{Hash: "c", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	requireTest.NoError(m.CreateRefreshToken(ctx, &storages.RefreshToken{UsrId: 1, Hash: "d", ExpiresAt: expiresAt}))
+	requireTest.NoError(m.RevokeRefreshTokens(ctx, "d"))
+	_, err = m.RotateRefreshToken(ctx, "d", &storages.RefreshToken{Hash: "e", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+	requireTest.Equal(storages.ErrInvalidCredentials, m.RevokeRefreshTokens(ctx, "unknown"))
+
+	requireTest.NoError(m.CreateRefreshToken(ctx, &storages.RefreshToken{UsrId: 1, Hash: "f", ExpiresAt: time.Now().Add(-time.Second)}))
+	_, err = m.RotateRefreshToken(ctx, "f", &storages.RefreshToken{Hash: "g", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+}
+
+func TestMemorySessions(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+	first := &storages.RefreshToken{UsrId: 1, Hash: "a", ExpiresAt: expiresAt, UserAgent: "old"}
+	requireTest.NoError(m.CreateRefreshToken(ctx, first))
+	_, err = m.RotateRefreshToken(ctx, "a", &storages.RefreshToken{Hash: "b", ExpiresAt: expiresAt, UserAgent: "new"})
+	requireTest.NoError(err)
+	other := &storages.RefreshToken{UsrId: 1, Hash: "c", ExpiresAt: expiresAt}
+	requireTest.NoError(m.CreateRefreshToken(ctx, other))
+	requireTest.NoError(m.CreateRefreshToken(ctx, &storages.RefreshToken{UsrId: 2, Hash: "d", ExpiresAt: expiresAt}))
+
+	sessions, err := m.ListSessions(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Len(sessions, 2)
+	ids := map[int]string{}
+	for _, session := range sessions {
+		ids[session.Id] = session.UserAgent
+	}
+	requireTest.Equal(map[int]string{first.Id: "new", other.Id: ""}, ids)
+
+	requireTest.Equal(storages.ErrNotFound, m.RevokeSession(ctx, 2, first.Id))
+	requireTest.NoError(m.RevokeSession(ctx, 1, first.Id))
+	_, err = m.RotateRefreshToken(ctx, "b", &storages.RefreshToken{Hash: "e", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	requireTest.NoError(m.RevokeSessions(ctx, 1))
+	sessions, err = m.ListSessions(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Empty(sessions)
+	sessions, err = m.ListSessions(ctx, 2)
+	requireTest.NoError(err)
+	requireTest.Len(sessions, 1)
+}
+
+func TestMemoryShares(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	usr := &storages.User{Username: "alice", MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+
+	requireTest.NoError(m.SaveShare(ctx, &storages.Share{OwnerId: 1, UsrId: usr.Id}))
+	requireTest.NoError(m.SaveShare(ctx, &storages.Share{OwnerId: 1, UsrId: usr.Id, CanWrite: true}))
+	share, err := m.GetShare(ctx, 1, usr.Id)
+	requireTest.NoError(err)
+	requireTest.True(share.CanWrite)
+	_, err = m.GetShare(ctx, usr.Id, 1)
+	requireTest.Equal(storages.ErrNotFound, err)
+	requireTest.Equal(storages.ErrNotFound, m.SaveShare(ctx, &storages.Share{OwnerId: 1, UsrId: 99}))
+
+	shares, err := m.ListShares(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Len(shares, 1)
+
+	requireTest.NoError(m.DeleteShare(ctx, 1, usr.Id))
+	requireTest.Equal(storages.ErrNotFound, m.DeleteShare(ctx, 1, usr.Id))
+}
+
+func TestMemoryIdempotentResponses(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	now := time.Now()
+	resp := &storages.IdempotentResponse{UsrId: 1, Key: "k1", RequestHash: "h", Status: 200, Body: []byte(`{}`), ExpiresAt: now.Add(time.Hour)}
+	requireTest.NoError(m.SaveIdempotentResponse(ctx, resp, now))
+	requireTest.Equal(storages.ErrConflict, m.SaveIdempotentResponse(ctx, resp, now))
+	saved, err := m.GetIdempotentResponse(ctx, 1, "k1", now)
+	requireTest.NoError(err)
+	requireTest.Equal(resp, saved)
+	_, err = m.GetIdempotentResponse(ctx, 2, "k1", now)
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	// expired responses are gone and their keys may be used again
+	later := now.Add(2 * time.Hour)
+	_, err = m.GetIdempotentResponse(ctx, 1, "k1", later)
+	requireTest.Equal(storages.ErrNotFound, err)
+	resp.ExpiresAt = later.Add(time.Hour)
+	requireTest.NoError(m.SaveIdempotentResponse(ctx, resp, later))
+}
+
+func TestMemoryCreateUser(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	pwdHash, err := password.Hash("s3cretpass")
+	requireTest.NoError(err)
+	usr := &storages.User{Username: "alice", PwdHash: pwdHash, MaxTodo: 5}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+	requireTest.NotZero(usr.Id)
+
+	validated, err := m.ValidateUser(ctx, "alice", "s3cretpass")
+	requireTest.NoError(err)
+	requireTest.Equal(usr.Id, validated.Id)
+
+	requireTest.Equal(storages.ErrConflict, m.CreateUser(ctx, &storages.User{Username: "alice", PwdHash: pwdHash}))
+	requireTest.Equal(storages.ErrConflict, m.CreateUser(ctx, &storages.User{Username: "firstUser", PwdHash: pwdHash}))
+}
+
+func TestMemoryRehashLegacyPassword(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	legacy, err := bcrypt.GenerateFromPassword([]byte("s3cretpass"), bcrypt.MinCost)
+	requireTest.NoError(err)
+	requireTest.NoError(m.CreateUser(ctx, &storages.User{Username: "alice", PwdHash: string(legacy), MaxTodo: 5}))
+
+	usr, err := m.ValidateUser(ctx, "alice", "s3cretpass")
+	requireTest.NoError(err)
+	requireTest.False(password.NeedsRehash(usr.PwdHash))
+	requireTest.Equal(usr.PwdHash, m.users["alice"].PwdHash)
+
+	_, err = m.ValidateUser(ctx, "alice", "s3cretpass")
+	requireTest.NoError(err)
+}
+
+func TestMemoryPasswordReset(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+	requireTest.NoError(m.CreateRefreshToken(ctx, &storages.RefreshToken{UsrId: 1, Hash: "refresh", ExpiresAt: expiresAt}))
+
+	usr, err := m.CreatePasswordReset(ctx, "firstUser", &storages.PasswordReset{Hash: "a", ExpiresAt: expiresAt})
+	requireTest.NoError(err)
+	requireTest.Equal(1, usr.Id)
+	_, err = m.CreatePasswordReset(ctx, "firstUser", &storages.PasswordReset{Hash: "b", ExpiresAt: expiresAt})
+	requireTest.NoError(err)
+	_, err = m.CreatePasswordReset(ctx, "nobody", &storages.PasswordReset{Hash: "c", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	pwdHash, err := password.Hash("n3wpassword")
+	requireTest.NoError(err)
+	_, err = m.ResetPassword(ctx, "a", pwdHash)
+	requireTest.NoError(err)
+	_, err = m.ValidateUser(ctx, "firstUser", "n3wpassword")
+	requireTest.NoError(err)
+
+	// the used token, other pending resets and sessions are all invalidated
+	_, err = m.ResetPassword(ctx, "a", pwdHash)
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+	_, err = m.ResetPassword(ctx, "b", pwdHash)
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+	_, err = m.RotateRefreshToken(ctx, "refresh", &storages.RefreshToken{Hash: "next", ExpiresAt: expiresAt})
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	_, err = m.CreatePasswordReset(ctx, "firstUser", &storages.PasswordReset{Hash: "d", ExpiresAt: time.Now().Add(-time.Second)})
+	requireTest.NoError(err)
+	_, err = m.ResetPassword(ctx, "d", pwdHash)
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	requireTest.Equal(storages.ErrNotFound, m.SetPassword(ctx, 99, pwdHash))
+	user, err := m.GetUser(ctx, 1)
+	requireTest.NoError(err)
+	requireTest.Equal(pwdHash, user.PwdHash)
+}
+
+func TestMemoryIdentities(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	_, err = m.FindIdentityUser(ctx, "github", "42")
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	usr := &storages.User{Username: "octocat", MaxTodo: 5}
+	requireTest.NoError(m.CreateIdentityUser(ctx, usr, "github", "42"))
+	found, err := m.FindIdentityUser(ctx, "github", "42")
+	requireTest.NoError(err)
+	requireTest.Equal(usr.Id, found.Id)
+	requireTest.Equal(storages.ErrConflict, m.CreateIdentityUser(ctx, &storages.User{Username: "other"}, "github", "42"))
+
+	requireTest.NoError(m.LinkIdentity(ctx, 1, "google", "7"))
+	requireTest.NoError(m.LinkIdentity(ctx, 1, "google", "7"))
+	requireTest.Equal(storages.ErrConflict, m.LinkIdentity(ctx, 2, "google", "7"))
+	requireTest.Equal(storages.ErrNotFound, m.LinkIdentity(ctx, 99, "google", "8"))
+	found, err = m.FindIdentityUser(ctx, "google", "7")
+	requireTest.NoError(err)
+	requireTest.Equal(1, found.Id)
+}
+
+func TestMemoryUpdateUser(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+
+	ctx := context.Background()
+	role, maxTodo := storages.RoleAdmin, 10
+	usr, err := m.UpdateUser(ctx, 1, &storages.UserPatch{Role: &role, MaxTodo: &maxTodo})
+	requireTest.NoError(err)
+	requireTest.Equal(&storages.User{Id: 1, Username: "firstUser", MaxTodo: 10, Role: storages.RoleAdmin}, usr)
+
+	users, err := m.ListUsers(ctx)
+	requireTest.NoError(err)
+	requireTest.Equal([]*storages.User{usr}, users)
+
+	validated, err := m.ValidateUser(ctx, "firstUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(storages.RoleAdmin, validated.Role)
+
+	_, err = m.UpdateUser(ctx, 99, &storages.UserPatch{Role: &role})
+	requireTest.Equal(storages.ErrNotFound, err)
+}
+
+func TestMemoryTimezone(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+
+	requireTest.Equal(storages.ErrInvalidTimezone, m.SetTimezone(ctx, usr.Id, "Mars/Olympus"))
+	requireTest.Equal(storages.ErrNotFound, m.SetTimezone(ctx, 99, "UTC"))
+	requireTest.NoError(m.SetTimezone(ctx, usr.Id, "Etc/GMT-14"))
+	timezone, err := m.GetTimezone(ctx, usr.Id)
+	requireTest.NoError(err)
+	requireTest.Equal("Etc/GMT-14", timezone)
+
+	// a task of the previous day of the user doesn't count for today whatever the UTC day is
+	loc, err := time.LoadLocation(timezone)
+	requireTest.NoError(err)
+	from, _ := storages.DayRangeIn(time.Now(), loc)
+	m.tasks = append(m.tasks, &storages.Task{Id: 99, UsrId: usr.Id, Content: "late", CreateAt: from.Add(-time.Second)})
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "early"}))
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "early"}))
+
+	tasks, err := m.GetTasks(ctx, usr.Id, from)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("early", tasks[0].Content)
+	quota, err := m.GetQuota(ctx, usr.Id, time.Now())
+	requireTest.NoError(err)
+	requireTest.Equal(1, quota.Used)
+}
+
+func TestMemoryRollingQuota(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+	usr := &storages.User{Username: "alice", MaxTodo: 2}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+	m.SetQuotaPolicy(storages.RollingQuota{Period: 24 * time.Hour})
+
+	now := time.Now()
+	m.tasks = append(m.tasks,
+		&storages.Task{Id: 98, UsrId: usr.Id, Content: "aged out", CreateAt: now.Add(-25 * time.Hour)},
+		&storages.Task{Id: 99, UsrId: usr.Id, Content: "counted", CreateAt: now.Add(-23 * time.Hour)},
+	)
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "content"}))
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "content"}))
+
+	quota, err := m.GetQuota(ctx, usr.Id, now)
+	requireTest.NoError(err)
+	requireTest.Equal(2, quota.Used)
+	requireTest.Equal(now.Add(24*time.Hour), quota.ResetAt)
+}
+
+func TestMemoryUsage(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+	usr := &storages.User{Username: "alice", MaxTodo: 2}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "content"}))
+	// batches over the limit count none of their tasks
+	err = m.InsertTasks(ctx, []*storages.Task{{UsrId: usr.Id, Content: "first"}, {UsrId: usr.Id, Content: "second"}})
+	requireTest.Equal(storages.BatchError{1: storages.ErrQuotaExceeded}, err)
+
+	now := time.Now()
+	usage, err := m.GetUsage(ctx, usr.Id, now.AddDate(0, 0, -1), now)
+	requireTest.NoError(err)
+	requireTest.Len(usage, 1)
+	requireTest.Equal(1, usage[0].Tasks)
+	requireTest.Equal(now.UTC().Format("2006-01-02"), usage[0].Day.Format("2006-01-02"))
+
+	// deleted tasks still count
+	requireTest.NoError(m.DeleteTask(ctx, usr.Id, m.tasks[len(m.tasks)-1].Id))
+	usage, err = m.GetUsage(ctx, usr.Id, now, now)
+	requireTest.NoError(err)
+	requireTest.Equal(1, usage[0].Tasks)
+
+	usage, err = m.GetUsage(ctx, usr.Id, now.AddDate(0, 0, -2), now.AddDate(0, 0, -1))
+	requireTest.NoError(err)
+	requireTest.Empty(usage)
+}
+
+func TestMemoryBurst(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+	m.SetBurst(storages.Burst{Extra: 2, Recovery: time.Hour})
+
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "content"}))
+	quota, err := m.GetQuota(ctx, usr.Id, time.Now())
+	requireTest.NoError(err)
+	requireTest.Equal(2, quota.Burst)
+
+	// batches over the burst use none of it
+	err = m.InsertTasks(ctx, []*storages.Task{{UsrId: usr.Id, Content: "a"}, {UsrId: usr.Id, Content: "b"}, {UsrId: usr.Id, Content: "c"}})
+	requireTest.Equal(storages.BatchError{2: storages.ErrQuotaExceeded}, err)
+	quota, err = m.InsertTaskQuota(ctx, &storages.Task{UsrId: usr.Id, Content: "content"})
+	requireTest.NoError(err)
+	requireTest.Equal(1, quota.Burst)
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "content"}))
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "content"}))
+
+	// credits come back over time
+	quota, err = m.GetQuota(ctx, usr.Id, time.Now().Add(time.Hour))
+	requireTest.NoError(err)
+	requireTest.Equal(1, quota.Burst)
+}
+
+func TestMemoryPlans(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+	usr := &storages.User{Username: "alice", MaxTodo: 1}
+	requireTest.NoError(m.CreateUser(ctx, usr))
+
+	plans, err := m.ListPlans(ctx)
+	requireTest.NoError(err)
+	requireTest.Equal(storages.DefaultPlans(), plans)
+	requireTest.Equal(storages.ErrNotFound, m.SetUserPlan(ctx, usr.Id, "gold"))
+	requireTest.Equal(storages.ErrNotFound, m.SetUserPlan(ctx, 99, "pro"))
+
+	// users on a plan have its limit rather than their own
+	requireTest.NoError(m.PutPlan(ctx, &storages.Plan{Name: "pro", MaxTodo: 2}))
+	requireTest.NoError(m.SetUserPlan(ctx, usr.Id, "pro"))
+	found, err := m.GetUser(ctx, usr.Id)
+	requireTest.NoError(err)
+	requireTest.Equal("pro", found.Plan)
+	requireTest.Equal(2, found.MaxTodo)
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "a"}))
+	requireTest.NoError(m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "b"}))
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: usr.Id, Content: "c"}))
+
+	// deleting the plan gives them their own limit back
+	requireTest.NoError(m.DeletePlan(ctx, "pro"))
+	requireTest.Equal(storages.ErrNotFound, m.DeletePlan(ctx, "pro"))
+	quota, err := m.GetQuota(ctx, usr.Id, time.Now())
+	requireTest.NoError(err)
+	requireTest.Equal(1, quota.MaxTodo)
+	found, err = m.GetUser(ctx, usr.Id)
+	requireTest.NoError(err)
+	requireTest.Equal("", found.Plan)
+}
+
+func TestMemoryTaskHistory(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+
+	_, err = m.GetTaskHistory(ctx, 1, 1)
+	requireTest.Equal(storages.ErrNotFound, err)
+
+	created := &storages.TaskAuditEntry{TaskId: 1, UsrId: 1, ActorId: 1, Action: storages.TaskCreated, After: &storages.Task{Id: 1, Content: "a"}}
+	requireTest.NoError(m.AppendTaskAudit(ctx, created))
+	requireTest.Equal(1, created.Id)
+	created.After.Content = "changed"
+	updated := &storages.TaskAuditEntry{
+		TaskId: 1, UsrId: 1, ActorId: 2, Action: storages.TaskUpdated,
+		Before: &storages.Task{Id: 1, Content: "a"}, After: &storages.Task{Id: 1, Content: "b"},
+	}
+	requireTest.NoError(m.AppendTaskAudit(ctx, updated))
+	requireTest.NoError(m.AppendTaskAudit(ctx, &storages.TaskAuditEntry{TaskId: 2, UsrId: 1, Action: storages.TaskCreated}))
+
+	// entries are kept oldest first and copied in and out
+	entries, err := m.GetTaskHistory(ctx, 1, 1)
+	requireTest.NoError(err)
+	requireTest.Len(entries, 2)
+	requireTest.Equal(storages.TaskCreated, entries[0].Action)
+	requireTest.Nil(entries[0].Before)
+	requireTest.Equal("a", entries[0].After.Content)
+	requireTest.Equal(2, entries[1].ActorId)
+	requireTest.Equal("b", entries[1].After.Content)
+	entries[1].After.Content = "changed"
+	entries, err = m.GetTaskHistory(ctx, 1, 1)
+	requireTest.NoError(err)
+	requireTest.Equal("b", entries[1].After.Content)
+
+	// the history of a task is only found for its owner
+	_, err = m.GetTaskHistory(ctx, 2, 1)
+	requireTest.Equal(storages.ErrNotFound, err)
+}
+
+func TestMemoryStats(t *testing.T) {
+	m, err := NewMemory()
+	requireTest := require.New(t)
+	requireTest.NoError(err)
+	ctx := context.Background()
+	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	requireTest.NoError(err)
+
+	// 20:00 UTC is the next day in Ho Chi Minh City
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2021, 3, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	m.tasks = []*storages.Task{
+		{Id: 1, UsrId: 1, CreateAt: *at(1, 8), CompletedAt: at(1, 20)},
+		{Id: 2, UsrId: 1, CreateAt: *at(1, 20)},
+		{Id: 3, UsrId: 1, CreateAt: *at(2, 8), DeletedAt: at(2, 9)},
+		{Id: 4, UsrId: 2, CreateAt: *at(2, 8)},
+		{Id: 5, UsrId: 1, CreateAt: *at(5, 8)},
+	}
+
+	stats, err := m.GetStats(ctx, 1, *at(1, 0), *at(3, 0), loc)
+	requireTest.NoError(err)
+	requireTest.Equal([]*storages.DayStats{
+		{Day: *at(1, 0), Created: 1},
+		{Day: *at(2, 0), Created: 1, Completed: 1},
+	}, stats)
+
+	stats, err = m.GetStats(ctx, 1, *at(3, 0), *at(4, 0), loc)
+	requireTest.NoError(err)
+	requireTest.Empty(stats)
+}
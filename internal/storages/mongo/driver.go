@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+func init() {
+	storages.Register("mongo", func(ctx context.Context, dsn string) (storages.StoreCloser, error) {
+		config, err := configFromURI(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return NewMongo(ctx, config)
+	})
+}
+
+// configFromURI creates config from mongodb uri, the database is taken from uri path
+func configFromURI(uri string) (*Config, error) {
+	if uri == "" {
+		uri = "mongodb://localhost:27017/togo"
+	}
+
+	cs, err := connstring.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parse()")
+	}
+
+	config := &Config{URI: uri, Db: cs.Database}
+	if config.Db == "" {
+		config.Db = "togo"
+	}
+	return config, nil
+}
@@ -0,0 +1,256 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	usrCollection     = "usr"
+	taskCollection    = "task"
+	quotaCollection   = "quota"
+	counterCollection = "counter"
+
+	dateLayout = "2006-01-02"
+)
+
+type Config struct {
+	URI string
+	Db  string
+}
+
+// usrDoc reflects documents of usr collection
+type usrDoc struct {
+	Id       int    `bson:"_id"`
+	Username string `bson:"username"`
+	PwdHash  string `bson:"pwd_hash"`
+	MaxTodo  int    `bson:"max_todo"`
+}
+
+// taskDoc reflects documents of task collection, CreateDate is the UTC
+// date of CreateAt which is used for daily queries
+type taskDoc struct {
+	Id         int       `bson:"_id"`
+	UsrId      int       `bson:"usr_id"`
+	Content    string    `bson:"content"`
+	CreateAt   time.Time `bson:"create_at"`
+	CreateDate string    `bson:"create_date"`
+}
+
+// Mongo represents a database instance for working with MongoDB
+type Mongo struct {
+	client *mongo.Client
+	db     *mongo.Database
+}
+
+// NewMongo create new Mongo instance
+func NewMongo(ctx context.Context, config *Config) (*Mongo, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(config.URI))
+	if err != nil {
+		return nil, errors.Wrap(err, "Connect()")
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, errors.Wrap(err, "Ping()")
+	}
+
+	m := &Mongo{
+		client: client,
+		db:     client.Database(config.Db),
+	}
+
+	if err := m.init(ctx); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, errors.Wrap(err, "init()")
+	}
+
+	return m, nil
+}
+
+// init creates indexes, demo data is only inserted by Seed
+func (m *Mongo) init(ctx context.Context) error {
+	_, err := m.db.Collection(usrCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "CreateOne()")
+	}
+
+	_, err = m.db.Collection(taskCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "usr_id", Value: 1}, {Key: "create_date", Value: 1}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "CreateOne()")
+	}
+
+	return nil
+}
+
+// ValidateUser returns user if match username AND password
+func (m *Mongo) ValidateUser(ctx context.Context, username, pwd string) (*storages.User, error) {
+	doc := &usrDoc{}
+	err := m.db.Collection(usrCollection).FindOne(ctx, bson.M{"username": username}).Decode(doc)
+
+	switch err {
+	case nil:
+		if !password.Compare(doc.PwdHash, pwd) {
+			return nil, storages.ErrInvalidCredentials
+		}
+		usr := &storages.User{Id: doc.Id, Username: doc.Username, PwdHash: doc.PwdHash, MaxTodo: doc.MaxTodo}
+		if password.NeedsRehash(usr.PwdHash) {
+			m.rehash(ctx, usr, pwd)
+		}
+		return usr, nil
+	case mongo.ErrNoDocuments:
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
+	default:
+		return nil, errors.Wrap(err, "FindOne()")
+	}
+}
+
+// rehash replaces the legacy hash of usr, a failure only postpones it to the next login
+func (m *Mongo) rehash(ctx context.Context, usr *storages.User, pwd string) {
+	pwdHash, err := password.Hash(pwd)
+	if err == nil {
+		filter := bson.M{"_id": usr.Id, "pwd_hash": usr.PwdHash}
+		_, err = m.db.Collection(usrCollection).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"pwd_hash": pwdHash}})
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("rehashing password", zap.Int("user_id", usr.Id), zap.Error(err))
+		return
+	}
+	usr.PwdHash = pwdHash
+}
+
+// GetTasks returns tasks of the user which were created on the date of createAt
+func (m *Mongo) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	filter := bson.M{"usr_id": usrId, "create_date": createAt.UTC().Format(dateLayout)}
+	cur, err := m.db.Collection(taskCollection).Find(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "Find()")
+	}
+	defer cur.Close(ctx)
+
+	tasks := make([]*storages.Task, 0)
+	for cur.Next(ctx) {
+		doc := &taskDoc{}
+		if err := cur.Decode(doc); err != nil {
+			return nil, errors.Wrap(err, "Decode()")
+		}
+		tasks = append(tasks, &storages.Task{
+			Id:       doc.Id,
+			UsrId:    doc.UsrId,
+			Content:  doc.Content,
+			CreateAt: doc.CreateAt,
+		})
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, errors.Wrap(err, "Err()")
+	}
+
+	return tasks, nil
+}
+
+// InsertTask inserts task if the user's daily-limit has not been reached yet.
+// The limit is enforced atomically by a per user per day counter document:
+// the conditional upsert either increases the counter or fails with a duplicate key
+func (m *Mongo) InsertTask(ctx context.Context, task *storages.Task) error {
+	task.CreateAt = time.Now().UTC()
+	createDate := task.CreateAt.Format(dateLayout)
+
+	usr := &usrDoc{}
+	err := m.db.Collection(usrCollection).FindOne(ctx, bson.M{"_id": task.UsrId}).Decode(usr)
+	switch err {
+	case nil:
+	case mongo.ErrNoDocuments:
+		return storages.ErrNotFound
+	default:
+		return errors.Wrap(err, "FindOne()")
+	}
+
+	quotaId := fmt.Sprintf("%d:%s", task.UsrId, createDate)
+	_, err = m.db.Collection(quotaCollection).UpdateOne(ctx,
+		bson.M{"_id": quotaId, "count": bson.M{"$lt": storages.TodoLimit(usr.MaxTodo)}},
+		bson.M{"$inc": bson.M{"count": 1}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if isDuplicateKey(err) {
+			return storages.ErrQuotaExceeded
+		}
+		return errors.Wrap(err, "UpdateOne()")
+	}
+
+	id, err := m.nextSeq(ctx, taskCollection)
+	if err == nil {
+		task.Id = id
+		_, err = m.db.Collection(taskCollection).InsertOne(ctx, taskDoc{
+			Id:         task.Id,
+			UsrId:      task.UsrId,
+			Content:    task.Content,
+			CreateAt:   task.CreateAt,
+			CreateDate: createDate,
+		})
+	}
+	if err != nil {
+		// Give the quota back
+		_, _ = m.db.Collection(quotaCollection).UpdateOne(ctx, bson.M{"_id": quotaId}, bson.M{"$inc": bson.M{"count": -1}})
+		return errors.Wrap(err, "InsertOne()")
+	}
+
+	return nil
+}
+
+// nextSeq returns the next value of the named sequence
+func (m *Mongo) nextSeq(ctx context.Context, name string) (int, error) {
+	doc := &struct {
+		Seq int `bson:"seq"`
+	}{}
+	err := m.db.Collection(counterCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": name},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(doc)
+	if err != nil {
+		return 0, errors.Wrap(err, "FindOneAndUpdate()")
+	}
+	return doc.Seq, nil
+}
+
+func (m *Mongo) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return m.client.Disconnect(ctx)
+}
+
+func isDuplicateKey(err error) bool {
+	if e, ok := err.(mongo.WriteException); ok {
+		for _, we := range e.WriteErrors {
+			if we.Code == 11000 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CheckHealth pings the deployment
+func (m *Mongo) CheckHealth(ctx context.Context) map[string]error {
+	if err := m.client.Ping(ctx, nil); err != nil {
+		return map[string]error{"database": errors.Wrap(err, "Ping()")}
+	}
+	return map[string]error{"database": nil}
+}
@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDSNEnv names the URI of the MongoDB deployment tests which need one run against, they're skipped without
+// it, e.g. mongodb://localhost:27017. Every test uses a database of its own which is dropped once it ends
+const testDSNEnv = "TOGO_TEST_MONGO_DSN"
+
+func newTestMongo(t *testing.T) *Mongo {
+	uri := os.Getenv(testDSNEnv)
+	if uri == "" {
+		t.Skip(testDSNEnv + " is not set")
+	}
+	ctx := context.Background()
+	m, err := NewMongo(ctx, &Config{URI: uri, Db: fmt.Sprintf("togo_test_%d", time.Now().UnixNano())})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = m.db.Drop(ctx)
+		_ = m.Close()
+	})
+	require.NoError(t, m.Seed(ctx, storages.DefaultFixtures()))
+	return m
+}
+
+func TestMongoValidateUser(t *testing.T) {
+	m := newTestMongo(t)
+	requireTest := require.New(t)
+
+	usr, err := m.ValidateUser(context.Background(), "firstUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(1, usr.Id)
+	requireTest.Equal(5, usr.MaxTodo)
+
+	_, err = m.ValidateUser(context.Background(), "firstUser", "wrong")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	_, err = m.ValidateUser(context.Background(), "nobody", "example")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+}
+
+func TestMongoGetTasks(t *testing.T) {
+	m := newTestMongo(t)
+	requireTest := require.New(t)
+
+	tasks, err := m.GetTasks(context.Background(), 1, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("test 1", tasks[0].Content)
+
+	tasks, err = m.GetTasks(context.Background(), 1, time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+}
+
+func TestMongoInsertTaskDailyLimit(t *testing.T) {
+	m := newTestMongo(t)
+	requireTest := require.New(t)
+	ctx := context.Background()
+
+	var task *storages.Task
+	for i := 0; i < 5; i++ {
+		task = &storages.Task{UsrId: 1, Content: "content"}
+		requireTest.NoError(m.InsertTask(ctx, task))
+		requireTest.True(task.Id > 1, "ids follow the seeded task")
+	}
+	requireTest.Equal(storages.ErrQuotaExceeded, m.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "content"}))
+	requireTest.Equal(storages.ErrNotFound, m.InsertTask(ctx, &storages.Task{UsrId: 99, Content: "content"}))
+
+	// the tasks of the day are counted by the usrId:date document
+	quota := &struct {
+		Count int `bson:"count"`
+	}{}
+	quotaId := fmt.Sprintf("%d:%s", 1, task.CreateAt.Format(dateLayout))
+	requireTest.NoError(m.db.Collection(quotaCollection).FindOne(ctx, bson.M{"_id": quotaId}).Decode(quota))
+	requireTest.Equal(5, quota.Count)
+
+	tasks, err := m.GetTasks(ctx, 1, task.CreateAt)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 5)
+}
@@ -0,0 +1,70 @@
+package mongo
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Seed upserts fixtures, documents with existing ids or usernames are left as is. Zero ids are taken from
+// the sequences which are moved past explicit ids so generated ones don't collide with them
+func (m *Mongo) Seed(ctx context.Context, fixtures *storages.Fixtures) error {
+	upsert := options.Update().SetUpsert(true)
+
+	for _, usr := range fixtures.Users {
+		pwdHash, err := password.Hash(usr.Password)
+		if err != nil {
+			return err
+		}
+
+		id, err := m.seedId(ctx, usrCollection, usr.Id)
+		if err != nil {
+			return err
+		}
+		_, err = m.db.Collection(usrCollection).UpdateOne(ctx,
+			bson.M{"$or": bson.A{bson.M{"_id": id}, bson.M{"username": usr.Username}}},
+			bson.M{"$setOnInsert": usrDoc{Id: id, Username: usr.Username, PwdHash: pwdHash, MaxTodo: usr.MaxTodo}},
+			upsert,
+		)
+		if err != nil && !isDuplicateKey(err) {
+			return errors.Wrap(err, "UpdateOne()")
+		}
+	}
+
+	for _, task := range fixtures.Tasks {
+		id, err := m.seedId(ctx, taskCollection, task.Id)
+		if err != nil {
+			return err
+		}
+		createAt := task.CreateAt.UTC()
+		_, err = m.db.Collection(taskCollection).UpdateOne(ctx,
+			bson.M{"_id": id},
+			bson.M{"$setOnInsert": taskDoc{Id: id, UsrId: task.UsrId, Content: task.Content, CreateAt: createAt, CreateDate: createAt.Format(dateLayout)}},
+			upsert,
+		)
+		if err != nil {
+			return errors.Wrap(err, "UpdateOne()")
+		}
+	}
+
+	return nil
+}
+
+// seedId returns id after moving the named sequence past it, the next value of the sequence when id is zero
+func (m *Mongo) seedId(ctx context.Context, name string, id int) (int, error) {
+	if id <= 0 {
+		return m.nextSeq(ctx, name)
+	}
+	_, err := m.db.Collection(counterCollection).UpdateOne(ctx,
+		bson.M{"_id": name},
+		bson.M{"$max": bson.M{"seq": id}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "UpdateOne()")
+	}
+	return id, nil
+}
@@ -0,0 +1,27 @@
+package mysql
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"net"
+	"time"
+)
+
+type Config struct {
+	Host string
+	Port string
+	Usr  string
+	Pwd  string
+	Db   string
+}
+
+func (c *Config) toDSN() string {
+	config := mysql.NewConfig()
+	config.User = c.Usr
+	config.Passwd = c.Pwd
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(c.Host, c.Port)
+	config.DBName = c.Db
+	config.ParseTime = true
+	config.Loc = time.UTC
+	return config.FormatDSN()
+}
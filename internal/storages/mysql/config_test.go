@@ -0,0 +1,21 @@
+package mysql
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestToDSN(t *testing.T) {
+	test := assert.New(t)
+
+	expected := "test:123456@tcp(localhost:3306)/test_db?parseTime=true"
+
+	config := &Config{
+		Host: "localhost",
+		Port: "3306",
+		Usr:  "test",
+		Pwd:  "123456",
+		Db:   "test_db",
+	}
+	test.Equal(expected, config.toDSN())
+}
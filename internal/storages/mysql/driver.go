@@ -0,0 +1,15 @@
+package mysql
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+func init() {
+	storages.Register("mysql", func(ctx context.Context, dsn string) (storages.StoreCloser, error) {
+		if dsn == "" {
+			dsn = (&Config{Host: "localhost", Port: "3306", Usr: "togo", Pwd: "togo", Db: "togo"}).toDSN()
+		}
+		return open(ctx, dsn)
+	})
+}
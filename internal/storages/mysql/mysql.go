@@ -0,0 +1,244 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// MySQL represents a database instance for working with MySQL/MariaDB
+type MySQL struct {
+	db *sql.DB
+	// policyMu guards policy as it changes on reload
+	policyMu sync.RWMutex
+	policy   storages.QuotaPolicy
+}
+
+// NewMySQL create new MySQL instance
+func NewMySQL(ctx context.Context, config *Config) (*MySQL, error) {
+	return open(ctx, config.toDSN())
+}
+
+// open creates new MySQL instance from the given dsn
+func open(ctx context.Context, dsn string) (*MySQL, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "Open()")
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "PingContext()")
+	}
+
+	m := &MySQL{
+		db:     db,
+		policy: storages.DailyQuota{},
+	}
+
+	if err := m.init(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "init()")
+	}
+
+	return m, nil
+}
+
+// init initializes tables, indexes, ..., demo data is only inserted by Seed.
+// Statements are executed one by one since multi statements are disabled by default
+func (m *MySQL) init(ctx context.Context) error {
+	stmts := []string{
+		`
+		CREATE TABLE IF NOT EXISTS usr (
+			id 			INT AUTO_INCREMENT PRIMARY KEY ,
+			username	VARCHAR(36) NOT NULL UNIQUE ,
+			pwd_hash 	TEXT NOT NULL ,
+			max_todo 	INT NOT NULL DEFAULT 5 CHECK ( max_todo >= 0 )
+		)`,
+		`
+		CREATE TABLE IF NOT EXISTS task (
+			id 			INT AUTO_INCREMENT PRIMARY KEY ,
+			usr_id 		INT NOT NULL ,
+			content 	TEXT NOT NULL ,
+			create_at	DATETIME(6) NOT NULL ,
+			FOREIGN KEY (usr_id) REFERENCES usr(id) ,
+			INDEX task_usr_id_create_at_idx (usr_id, create_at)
+		)`,
+	}
+
+	for _, stmt := range stmts {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return errors.Wrap(err, "ExecContext()")
+		}
+	}
+	return nil
+}
+
+// ValidateUser returns user if match username AND password
+func (m *MySQL) ValidateUser(ctx context.Context, username, pwd string) (*storages.User, error) {
+	stmt := `SELECT id, username, pwd_hash, max_todo FROM usr WHERE username = ?`
+	row := m.db.QueryRowContext(ctx, stmt, username)
+
+	usr := &storages.User{}
+	err := row.Scan(&usr.Id, &usr.Username, &usr.PwdHash, &usr.MaxTodo)
+
+	switch err {
+	case nil:
+		if !password.Compare(usr.PwdHash, pwd) {
+			return nil, storages.ErrInvalidCredentials
+		}
+		if password.NeedsRehash(usr.PwdHash) {
+			m.rehash(ctx, usr, pwd)
+		}
+		return usr, nil
+	case sql.ErrNoRows:
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
+	default:
+		return nil, errors.Wrap(err, "Scan()")
+	}
+}
+
+// rehash replaces the legacy hash of usr, a failure only postpones it to the next login
+func (m *MySQL) rehash(ctx context.Context, usr *storages.User, pwd string) {
+	pwdHash, err := password.Hash(pwd)
+	if err == nil {
+		_, err = m.db.ExecContext(ctx, `UPDATE usr SET pwd_hash = ? WHERE id = ? AND pwd_hash = ?`, pwdHash, usr.Id, usr.PwdHash)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("rehashing password", zap.Int("user_id", usr.Id), zap.Error(err))
+		return
+	}
+	usr.PwdHash = pwdHash
+}
+
+// GetTasks returns tasks of the user which were created on the date of createAt
+func (m *MySQL) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	stmt :=
+		`
+		SELECT id, usr_id, content, create_at
+		FROM task
+		WHERE usr_id = ? AND create_at >= ? AND create_at < ?
+		`
+	from, to := storages.DayRange(createAt)
+	rows, err := m.db.QueryContext(ctx, stmt, usrId, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "QueryContext()")
+	}
+	defer rows.Close()
+
+	tasks := make([]*storages.Task, 0)
+	for rows.Next() {
+		task := &storages.Task{}
+		err := rows.Scan(
+			&task.Id,
+			&task.UsrId,
+			&task.Content,
+			&task.CreateAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "Err()")
+	}
+
+	return tasks, nil
+}
+
+// SetQuotaPolicy changes which tasks count towards the limits of users, days are UTC ones
+func (m *MySQL) SetQuotaPolicy(policy storages.QuotaPolicy) {
+	m.policyMu.Lock()
+	defer m.policyMu.Unlock()
+	m.policy = policy
+}
+
+func (m *MySQL) currentPolicy() storages.QuotaPolicy {
+	m.policyMu.RLock()
+	defer m.policyMu.RUnlock()
+	return m.policy
+}
+
+// InsertTask inserts task if the user's daily-limit has not been reached yet. The row of the user is locked
+// first, so concurrent inserts of the user wait for each other instead of all counting the same tasks under
+// READ COMMITTED or deadlocking on gap locks under REPEATABLE READ
+func (m *MySQL) InsertTask(ctx context.Context, task *storages.Task) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "BeginTx()")
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var usrId int
+	switch err := tx.QueryRowContext(ctx, `SELECT id FROM usr WHERE id = ? FOR UPDATE`, task.UsrId).Scan(&usrId); err {
+	case nil:
+	case sql.ErrNoRows:
+		return storages.ErrNotFound
+	default:
+		return errors.Wrap(err, "QueryRowContext()")
+	}
+
+	task.CreateAt = time.Now().UTC()
+	stmt :=
+		`
+		INSERT INTO task (usr_id, content, create_at)
+		SELECT ?, ?, ? FROM DUAL
+		WHERE
+			(
+				SELECT count(*) FROM task
+				WHERE usr_id = ? AND create_at >= ? AND create_at < ?
+			) < (SELECT COALESCE(NULLIF(max_todo, 0), 2147483647) FROM usr WHERE id = ?)
+		`
+	from, to := m.currentPolicy().Window(task.CreateAt, time.UTC)
+	res, err := tx.ExecContext(ctx, stmt,
+		task.UsrId, task.Content, task.CreateAt,
+		task.UsrId, from, to,
+		task.UsrId,
+	)
+	if err != nil {
+		return errors.Wrap(err, "ExecContext()")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "RowsAffected()")
+	}
+	if affected < 1 {
+		return storages.ErrQuotaExceeded
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "LastInsertId()")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "Commit()")
+	}
+	task.Id = int(id)
+
+	return nil
+}
+
+func (m *MySQL) Close() error {
+	return m.db.Close()
+}
+
+// CheckHealth pings the database
+func (m *MySQL) CheckHealth(ctx context.Context) map[string]error {
+	if err := m.db.PingContext(ctx); err != nil {
+		return map[string]error{"database": errors.Wrap(err, "PingContext()")}
+	}
+	return map[string]error{"database": nil}
+}
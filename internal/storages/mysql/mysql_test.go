@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDSNEnv names the DSN of the MySQL server tests which need one run against, they're skipped without it,
+// e.g. togo:togo@tcp(localhost:3306)/togo?parseTime=true
+const testDSNEnv = "TOGO_TEST_MYSQL_DSN"
+
+func newTestMySQL(t *testing.T) *MySQL {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skip(testDSNEnv + " is not set")
+	}
+	m, err := open(context.Background(), dsn)
+	require.NoError(t, err)
+	return m
+}
+
+// newTestUser inserts a user with no task yet, it's deleted along with its tasks when the test ends
+func newTestUser(t *testing.T, m *MySQL, maxTodo int) int {
+	ctx := context.Background()
+	res, err := m.db.ExecContext(ctx, `INSERT INTO usr (username, pwd_hash, max_todo) VALUES (?, '', ?)`,
+		fmt.Sprintf("test-%d", time.Now().UnixNano()), maxTodo)
+	require.NoError(t, err)
+	id, err := res.LastInsertId()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = m.db.ExecContext(ctx, `DELETE FROM task WHERE usr_id = ?`, id)
+		_, _ = m.db.ExecContext(ctx, `DELETE FROM usr WHERE id = ?`, id)
+	})
+	return int(id)
+}
+
+func TestMySQLInsertTaskConcurrent(t *testing.T) {
+	m := newTestMySQL(t)
+	defer m.Close()
+
+	requireTest := require.New(t)
+	usrId := newTestUser(t, m, 5)
+
+	// parallel inserts never exceed the limit as the row of the user is locked, those over it fail with
+	// ErrQuotaExceeded
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- m.InsertTask(context.Background(), &storages.Task{UsrId: usrId, Content: "content"})
+		}()
+	}
+	inserted := 0
+	for i := 0; i < cap(errs); i++ {
+		switch err := <-errs; err {
+		case nil:
+			inserted++
+		default:
+			requireTest.Equal(storages.ErrQuotaExceeded, err)
+		}
+	}
+	requireTest.Equal(5, inserted)
+
+	tasks, err := m.GetTasks(context.Background(), usrId, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 5)
+}
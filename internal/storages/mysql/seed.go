@@ -0,0 +1,54 @@
+package mysql
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// Seed inserts fixtures in one transaction, rows with existing ids or usernames are skipped.
+// MySQL has no roles, tags or statuses so those of fixtures are left out
+func (m *MySQL) Seed(ctx context.Context, fixtures *storages.Fixtures) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "BeginTx()")
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, usr := range fixtures.Users {
+		pwdHash, err := password.Hash(usr.Password)
+		if err != nil {
+			return err
+		}
+
+		stmt := `INSERT IGNORE INTO usr (username, pwd_hash, max_todo) VALUES (?, ?, ?)`
+		args := []interface{}{usr.Username, pwdHash, usr.MaxTodo}
+		if usr.Id > 0 {
+			stmt = `INSERT IGNORE INTO usr (id, username, pwd_hash, max_todo) VALUES (?, ?, ?, ?)`
+			args = append([]interface{}{usr.Id}, args...)
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return errors.Wrap(err, "ExecContext()")
+		}
+	}
+
+	for _, task := range fixtures.Tasks {
+		stmt := `INSERT IGNORE INTO task (usr_id, content, create_at) VALUES (?, ?, ?)`
+		args := []interface{}{task.UsrId, task.Content, task.CreateAt.UTC()}
+		if task.Id > 0 {
+			stmt = `INSERT IGNORE INTO task (id, usr_id, content, create_at) VALUES (?, ?, ?, ?)`
+			args = append([]interface{}{task.Id}, args...)
+		}
+		if _, err := tx.ExecContext(ctx, stmt, args...); err != nil {
+			return errors.Wrap(err, "ExecContext()")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "Commit()")
+	}
+	return nil
+}
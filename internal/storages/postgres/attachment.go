@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// attachmentColumns are the columns scanned by scanAttachment
+const attachmentColumns = `id, task_id, usr_id, name, content_type, size, blob_key, create_at`
+
+func scanAttachment(row pgx.Row, attachment *storages.Attachment) error {
+	return row.Scan(
+		&attachment.Id,
+		&attachment.TaskId,
+		&attachment.UsrId,
+		&attachment.Name,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.BlobKey,
+		&attachment.CreateAt,
+	)
+}
+
+// AddAttachment inserts attachment of its task
+func (pg *Postgres) AddAttachment(ctx context.Context, attachment *storages.Attachment) error {
+	if err := attachment.Normalize(); err != nil {
+		return err
+	}
+	attachment.CreateAt = time.Now().UTC()
+
+	stmt :=
+		`
+		INSERT INTO task_attachment (task_id, usr_id, name, content_type, size, blob_key, create_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7
+		WHERE EXISTS (SELECT 1 FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL)
+		RETURNING id
+		`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, attachment.TaskId, attachment.UsrId, attachment.Name,
+			attachment.ContentType, attachment.Size, attachment.BlobKey, attachment.CreateAt)
+		switch err := row.Scan(&attachment.Id); err {
+		case nil:
+			return nil
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+	})
+}
+
+// GetAttachments returns attachments of the task of the user, oldest first
+func (pg *Postgres) GetAttachments(ctx context.Context, usrId, taskId int) ([]*storages.Attachment, error) {
+	stmt := `SELECT ` + attachmentColumns + ` FROM task_attachment WHERE task_id = $1 ORDER BY create_at, id`
+
+	var attachments []*storages.Attachment
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		var exists bool
+		row := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL)`, taskId, usrId)
+		if err := row.Scan(&exists); err != nil {
+			return errors.Wrap(err, "Scan()")
+		}
+		if !exists {
+			return storages.ErrNotFound
+		}
+
+		rows, err := pool.Query(ctx, stmt, taskId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		attachments = make([]*storages.Attachment, 0)
+		for rows.Next() {
+			attachment := &storages.Attachment{}
+			if err := scanAttachment(rows, attachment); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			attachments = append(attachments, attachment)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return attachments, nil
+}
+
+// GetAttachment returns the attachment of the task of the user
+func (pg *Postgres) GetAttachment(ctx context.Context, usrId, taskId, id int) (*storages.Attachment, error) {
+	stmt := `
+		SELECT ` + attachmentColumns + ` FROM task_attachment
+		WHERE id = $1 AND task_id = $2 AND task_id IN (SELECT id FROM task WHERE id = $2 AND usr_id = $3 AND deleted_at IS NULL)`
+
+	attachment := &storages.Attachment{}
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		return scanAttachment(pool.QueryRow(ctx, stmt, id, taskId, usrId), attachment)
+	})
+
+	switch err {
+	case nil:
+		return attachment, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// DeleteAttachment deletes and returns the attachment of the task of the user
+func (pg *Postgres) DeleteAttachment(ctx context.Context, usrId, taskId, id int) (*storages.Attachment, error) {
+	stmt := `
+		DELETE FROM task_attachment
+		WHERE id = $1 AND task_id = $2 AND task_id IN (SELECT id FROM task WHERE id = $2 AND usr_id = $3 AND deleted_at IS NULL)
+		RETURNING ` + attachmentColumns
+
+	attachment := &storages.Attachment{}
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		return scanAttachment(pg.pool().QueryRow(ctx, stmt, id, taskId, usrId), attachment)
+	})
+
+	switch err {
+	case nil:
+		return attachment, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// PurgeAttachments deletes and returns attachments of the deleted task of the user
+func (pg *Postgres) PurgeAttachments(ctx context.Context, usrId, taskId int) ([]*storages.Attachment, error) {
+	stmt := `
+		DELETE FROM task_attachment
+		WHERE task_id = $1 AND task_id IN (SELECT id FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NOT NULL)
+		RETURNING ` + attachmentColumns
+
+	var attachments []*storages.Attachment
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, stmt, taskId, usrId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		attachments = make([]*storages.Attachment, 0)
+		for rows.Next() {
+			attachment := &storages.Attachment{}
+			if err := scanAttachment(rows, attachment); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			attachments = append(attachments, attachment)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return attachments, nil
+}
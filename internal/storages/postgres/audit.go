@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// AppendAudit inserts the event, the table rejects updates and deletes on Postgres
+func (pg *Postgres) AppendAudit(ctx context.Context, event *storages.AuditEvent) error {
+	stmt := `
+		INSERT INTO audit_event (type, actor_id, usr_id, ip, user_agent, detail)
+		VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, $5, $6)
+		RETURNING id, create_at`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, event.Type, event.ActorId, event.UsrId, event.IP, event.UserAgent, event.Detail)
+		if err := row.Scan(&event.Id, &event.CreateAt); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// ListAudit returns the events picked by the filter latest first
+func (pg *Postgres) ListAudit(ctx context.Context, filter storages.AuditFilter) ([]*storages.AuditEvent, error) {
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if filter.UsrId != 0 {
+		p := arg(filter.UsrId)
+		conds = append(conds, "(actor_id = "+p+" OR usr_id = "+p+")")
+	}
+	if filter.Type != "" {
+		conds = append(conds, "type = "+arg(filter.Type))
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "create_at >= "+arg(filter.Since))
+	}
+	stmt := `SELECT id, type, COALESCE(actor_id, 0), COALESCE(usr_id, 0), ip, user_agent, detail, create_at FROM audit_event`
+	if len(conds) > 0 {
+		stmt += " WHERE " + strings.Join(conds, " AND ")
+	}
+	stmt += " ORDER BY create_at DESC, id DESC"
+	if filter.Limit > 0 {
+		stmt += " LIMIT " + arg(filter.Limit)
+	}
+
+	var events []*storages.AuditEvent
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, stmt, args...)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		events = make([]*storages.AuditEvent, 0)
+		for rows.Next() {
+			event := &storages.AuditEvent{}
+			err := rows.Scan(&event.Id, &event.Type, &event.ActorId, &event.UsrId, &event.IP, &event.UserAgent, &event.Detail, &event.CreateAt)
+			if err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			events = append(events, event)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// selectorClause narrows statements to the tasks of $1 picked by a storages.TaskSelector,
+// see selectorArgs
+const selectorClause = `
+		      usr_id = $1
+		      AND deleted_at IS NULL
+		      AND (array_length($2::int[], 1) IS NULL OR id = ANY($2))
+		      AND ($3::bool IS NULL OR (status = 'done') = $3)
+		      AND ($4::timestamptz IS NULL OR create_at < $4)
+		      AND ($5::timestamptz IS NULL OR completed_at < $5)
+		      AND ($6::int IS NULL OR project_id = $6)
+		      AND (
+		          array_length($7::text[], 1) IS NULL 
+		          OR (SELECT count(*) FROM task_tag WHERE task_tag.task_id = task.id AND tag = ANY($7)) = array_length($7::text[], 1)
+		      )`
+
+const completeTasksStmt = `
+		UPDATE task SET 
+			status = 'done', 
+			completed_at = now(),
+			version = version + 1
+		WHERE ` + selectorClause + `
+		      AND status <> 'done'
+		RETURNING id`
+
+const deleteTasksStmt = `
+		UPDATE task SET deleted_at = now(), version = version + 1
+		WHERE ` + selectorClause + `
+		RETURNING id`
+
+func selectorArgs(usrId int, selector storages.TaskSelector) []interface{} {
+	return []interface{}{
+		usrId, selector.Ids, selector.Completed, selector.CreatedBefore, selector.CompletedBefore,
+		selector.ProjectId, selector.Tags,
+	}
+}
+
+// CompleteTasks marks the selected tasks of the user done in one statement
+func (pg *Postgres) CompleteTasks(ctx context.Context, usrId int, selector storages.TaskSelector) ([]int, error) {
+	return pg.bulkUpdate(ctx, completeTasksStmt, usrId, selector)
+}
+
+// DeleteTasks soft deletes the selected tasks of the user in one statement
+func (pg *Postgres) DeleteTasks(ctx context.Context, usrId int, selector storages.TaskSelector) ([]int, error) {
+	return pg.bulkUpdate(ctx, deleteTasksStmt, usrId, selector)
+}
+
+// bulkUpdate runs stmt which updates the selected tasks and returns their ids,
+// a single statement is atomic so no explicit transaction is needed
+func (pg *Postgres) bulkUpdate(ctx context.Context, stmt string, usrId int, selector storages.TaskSelector) ([]int, error) {
+	if err := selector.Normalize(); err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, stmt, selectorArgs(usrId, selector)...)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		ids = make([]int, 0)
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	return ids, err
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// GetChecklist returns checklist items of the task of the user by position
+func (pg *Postgres) GetChecklist(ctx context.Context, usrId, taskId int) ([]*storages.ChecklistItem, error) {
+	stmt := `SELECT id, task_id, content, position, done, create_at FROM checklist_item WHERE task_id = $1 ORDER BY position, id`
+
+	var items []*storages.ChecklistItem
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		var exists bool
+		row := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL)`, taskId, usrId)
+		if err := row.Scan(&exists); err != nil {
+			return errors.Wrap(err, "Scan()")
+		}
+		if !exists {
+			return storages.ErrNotFound
+		}
+
+		rows, err := pool.Query(ctx, stmt, taskId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		items = make([]*storages.ChecklistItem, 0)
+		for rows.Next() {
+			item := &storages.ChecklistItem{}
+			if err := rows.Scan(&item.Id, &item.TaskId, &item.Content, &item.Position, &item.Done, &item.CreateAt); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			items = append(items, item)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return items, nil
+}
+
+// AddChecklistItem appends item to the checklist of its task
+func (pg *Postgres) AddChecklistItem(ctx context.Context, usrId int, item *storages.ChecklistItem) error {
+	if err := item.Normalize(); err != nil {
+		return err
+	}
+	item.Done = false
+	item.CreateAt = time.Now().UTC()
+
+	stmt :=
+		`
+		INSERT INTO checklist_item (task_id, content, position, create_at)
+		SELECT $1, $2, COALESCE(max(position), 0) + 1, $3 FROM checklist_item WHERE task_id = $1
+		RETURNING id, position
+		`
+	return pg.changeTask(ctx, usrId, item.TaskId, func(ctx context.Context, tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, stmt, item.TaskId, item.Content, item.CreateAt)
+		if err := row.Scan(&item.Id, &item.Position); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// SetChecklistItemDone marks the item of the task done or not
+func (pg *Postgres) SetChecklistItemDone(ctx context.Context, usrId, taskId, id int, done bool) error {
+	stmt := `UPDATE checklist_item SET done = $3 WHERE task_id = $1 AND id = $2`
+	return pg.changeTask(ctx, usrId, taskId, func(ctx context.Context, tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, stmt, taskId, id, done)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// ReorderChecklist gives items of the task positions in the order of ids
+func (pg *Postgres) ReorderChecklist(ctx context.Context, usrId, taskId int, ids []int) error {
+	stmt :=
+		`
+		UPDATE checklist_item SET position = o.position
+		FROM unnest($2::int8[]) WITH ORDINALITY AS o(id, position)
+		WHERE checklist_item.task_id = $1 AND checklist_item.id = o.id
+		`
+	return pg.changeTask(ctx, usrId, taskId, func(ctx context.Context, tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `SELECT id FROM checklist_item WHERE task_id = $1`, taskId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		current := make([]int, 0, len(ids))
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return errors.Wrap(err, "Scan()")
+			}
+			current = append(current, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Rows()"))
+		}
+		if !storages.SameIds(current, ids) {
+			return storages.ErrInvalidTask
+		}
+
+		if _, err := tx.Exec(ctx, stmt, taskId, ids); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,10 @@
+package postgres
+
+// Dialect is the flavour of Postgres compatible server
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	// DialectCockroach emits CockroachDB safe DDL since CockroachDB doesn't support identity columns
+	DialectCockroach Dialect = "cockroach"
+)
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// AddComment appends comment to the thread of its task
+func (pg *Postgres) AddComment(ctx context.Context, comment *storages.Comment) error {
+	if err := comment.Normalize(); err != nil {
+		return err
+	}
+	comment.CreateAt = time.Now().UTC()
+
+	stmt :=
+		`
+		INSERT INTO task_comment (task_id, usr_id, content, create_at)
+		SELECT $1, $2, $3, $4
+		WHERE EXISTS (SELECT 1 FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL)
+		RETURNING id
+		`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, comment.TaskId, comment.UsrId, comment.Content, comment.CreateAt)
+		switch err := row.Scan(&comment.Id); err {
+		case nil:
+			return nil
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+	})
+}
+
+// GetComments returns comments on the task of the user, oldest first
+func (pg *Postgres) GetComments(ctx context.Context, usrId, taskId int) ([]*storages.Comment, error) {
+	stmt := `SELECT id, task_id, usr_id, content, create_at FROM task_comment WHERE task_id = $1 ORDER BY create_at, id`
+
+	var comments []*storages.Comment
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		var exists bool
+		row := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL)`, taskId, usrId)
+		if err := row.Scan(&exists); err != nil {
+			return errors.Wrap(err, "Scan()")
+		}
+		if !exists {
+			return storages.ErrNotFound
+		}
+
+		rows, err := pool.Query(ctx, stmt, taskId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		comments = make([]*storages.Comment, 0)
+		for rows.Next() {
+			comment := &storages.Comment{}
+			if err := rows.Scan(&comment.Id, &comment.TaskId, &comment.UsrId, &comment.Content, &comment.CreateAt); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			comments = append(comments, comment)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return comments, nil
+}
+
+// DeleteComment deletes the comment of the user on the task
+func (pg *Postgres) DeleteComment(ctx context.Context, usrId, taskId, id int) error {
+	stmt := `DELETE FROM task_comment WHERE id = $1 AND task_id = $2 AND usr_id = $3`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, stmt, id, taskId, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() > 0 {
+			return nil
+		}
+
+		// tell comments of others from missing ones
+		var exists bool
+		stmt := `
+			SELECT EXISTS (
+				SELECT 1 FROM task_comment JOIN task ON task.id = task_comment.task_id
+				WHERE task_comment.id = $1 AND task_comment.task_id = $2 AND task.usr_id = $3 AND task.deleted_at IS NULL
+			)`
+		if err := pg.pool().QueryRow(ctx, stmt, id, taskId, usrId).Scan(&exists); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		if exists {
+			return storages.ErrForbidden
+		}
+		return storages.ErrNotFound
+	})
+}
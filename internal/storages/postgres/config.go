@@ -1,6 +1,12 @@
 package postgres
 
-import "fmt"
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	Host string
@@ -8,8 +14,120 @@ type Config struct {
 	Usr  string
 	Pwd  string
 	Db   string
+	// Dialect is the flavour of the server, DialectPostgres by default
+	Dialect Dialect
+
+	// Pool tuning, zero values keep pgxpool defaults
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementCacheMode is "prepare" (default) or "describe", StatementCacheCapacity
+	// is the number of cached statements per connection
+	StatementCacheMode     string
+	StatementCacheCapacity int
+	// StatementTimeout aborts statements running longer than it, zero means no timeout
+	StatementTimeout time.Duration
+	// OpTimeout bounds each attempt of a storage operation, zero means no timeout
+	OpTimeout time.Duration
+	// MaxRetries is how many times an operation failed with a transient error is retried,
+	// zero keeps the default, negative disables retrying
+	MaxRetries int
+	// SlowQuery is how long a statement takes at least to be logged as slow, zero means none is
+	SlowQuery time.Duration
+	// RecreateAfter is how many health checks in a row the pool fails before it's re-created,
+	// zero keeps the default, negative disables re-creating it
+	RecreateAfter int
+	// ConnectWait is how long connecting on start is retried while the server isn't ready, zero keeps the
+	// default, negative fails at the first attempt
+	ConnectWait time.Duration
+
+	// TLS settings, see libpq sslmode for SSLMode values (disable, require, verify-ca, verify-full, ...)
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	// Replicas are connection strings of read replicas
+	Replicas []string
+}
+
+// ConnString returns the connection string of the config, credentials are escaped
+func (c *Config) ConnString() string {
+	u := &url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(c.Usr, c.Pwd),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     "/" + c.Db,
+		RawQuery: c.params().Encode(),
+	}
+	return u.String()
 }
 
-func (c *Config) toConnStr() string {
-	return fmt.Sprintf("postgresql://%s:%s@%s:%s/%s", c.Usr, c.Pwd, c.Host, c.Port, c.Db)
+// params returns connection string params which pgxpool understands
+func (c *Config) params() url.Values {
+	params := url.Values{}
+	if c.SSLMode != "" {
+		params.Set("sslmode", c.SSLMode)
+	}
+	if c.SSLRootCert != "" {
+		params.Set("sslrootcert", c.SSLRootCert)
+	}
+	if c.SSLCert != "" {
+		params.Set("sslcert", c.SSLCert)
+	}
+	if c.SSLKey != "" {
+		params.Set("sslkey", c.SSLKey)
+	}
+	if c.MaxConns > 0 {
+		params.Set("pool_max_conns", strconv.Itoa(int(c.MaxConns)))
+	}
+	if c.MinConns > 0 {
+		params.Set("pool_min_conns", strconv.Itoa(int(c.MinConns)))
+	}
+	if c.MaxConnLifetime > 0 {
+		params.Set("pool_max_conn_lifetime", c.MaxConnLifetime.String())
+	}
+	if c.MaxConnIdleTime > 0 {
+		params.Set("pool_max_conn_idle_time", c.MaxConnIdleTime.String())
+	}
+	if c.HealthCheckPeriod > 0 {
+		params.Set("pool_health_check_period", c.HealthCheckPeriod.String())
+	}
+	if replicas := splitReplicas(strings.Join(c.Replicas, ",")); len(replicas) > 0 {
+		params.Set(replicasParam, strings.Join(replicas, ","))
+	}
+	if c.StatementCacheMode != "" {
+		params.Set("statement_cache_mode", c.StatementCacheMode)
+	}
+	if c.StatementCacheCapacity > 0 {
+		params.Set("statement_cache_capacity", strconv.Itoa(c.StatementCacheCapacity))
+	}
+	if c.StatementTimeout > 0 {
+		// unknown params are sent to server as run-time parameters
+		params.Set("statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10))
+	}
+	if c.OpTimeout > 0 {
+		params.Set(opTimeoutParam, c.OpTimeout.String())
+	}
+	if c.MaxRetries > 0 {
+		params.Set(maxRetriesParam, strconv.Itoa(c.MaxRetries))
+	} else if c.MaxRetries < 0 {
+		params.Set(maxRetriesParam, "0")
+	}
+	if c.SlowQuery > 0 {
+		params.Set(slowQueryParam, c.SlowQuery.String())
+	}
+	if c.RecreateAfter > 0 {
+		params.Set(recreateAfterParam, strconv.Itoa(c.RecreateAfter))
+	} else if c.RecreateAfter < 0 {
+		params.Set(recreateAfterParam, "0")
+	}
+	if c.ConnectWait > 0 {
+		params.Set(connectWaitParam, c.ConnectWait.String())
+	} else if c.ConnectWait < 0 {
+		params.Set(connectWaitParam, "0s")
+	}
+	return params
 }
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"log"
+	"time"
+)
+
+const (
+	// connectWaitParam is the connection string param holding how long connecting on start is retried while
+	// the server isn't ready, zero fails at the first attempt
+	connectWaitParam = "x-connect-wait"
+
+	defaultConnectWait = 30 * time.Second
+
+	// the backoff between attempts to connect doubles from connectInitialBackoff up to connectMaxBackoff
+	connectInitialBackoff = 250 * time.Millisecond
+	connectMaxBackoff     = 5 * time.Second
+
+	// errors of servers which are starting up or full, clients are expected to try again
+	cannotConnectNow   = "57P03"
+	tooManyConnections = "53300"
+)
+
+// connectPool connects the pool of config. Attempts which fail while the server isn't ready, such as when the
+// app starts along with it in docker-compose or kubernetes, are retried for wait with a jittered backoff
+func connectPool(ctx context.Context, config *pgxpool.Config, wait time.Duration) (*pgxpool.Pool, error) {
+	deadline := time.Now().Add(wait)
+	backoff := connectInitialBackoff
+	for attempt := 1; ; attempt++ {
+		pool, err := pgxpool.ConnectConfig(ctx, config)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("postgres connected after %d attempts", attempt)
+			}
+			return pool, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 || ctx.Err() != nil || !canRetryConnect(err) {
+			return nil, errors.Wrap(err, "Connect()")
+		}
+		sleep := jitter(backoff)
+		if sleep > remaining {
+			sleep = remaining
+		}
+		log.Printf("postgres not ready, retrying in %s: %v", sleep.Round(time.Millisecond), err)
+
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrap(err, "Connect()")
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > connectMaxBackoff {
+			backoff = connectMaxBackoff
+		}
+	}
+}
+
+// canRetryConnect reports whether connecting may succeed later after err. Errors the server answered are
+// final, wrong passwords or databases among them, unless it's starting up or has no connection left
+func canRetryConnect(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == cannotConnectNow || pgErr.Code == tooManyConnections
+	}
+	return true
+}
+
+// parseConnectWaitParam takes out x-connect-wait param into s
+func parseConnectWaitParam(params map[string]string, s *settings) error {
+	v, ok := params[connectWaitParam]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return errors.Wrap(err, connectWaitParam)
+	}
+	s.connectWait = d
+	delete(params, connectWaitParam)
+	return nil
+}
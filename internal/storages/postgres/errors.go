@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"github.com/jackc/pgconn"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	// invalidParameterValue is raised for unknown timezones among others
+	invalidParameterValue = "22023"
+)
+
+// mapErr maps well-known Postgres errors onto storages errors, others are returned as is
+func mapErr(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolation:
+		return storages.ErrConflict
+	case foreignKeyViolation:
+		return storages.ErrNotFound
+	default:
+		return err
+	}
+}
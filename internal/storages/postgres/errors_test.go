@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"github.com/jackc/pgconn"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMapErr(t *testing.T) {
+	requireTest := require.New(t)
+
+	requireTest.Equal(storages.ErrConflict, mapErr(errors.Wrap(&pgconn.PgError{Code: uniqueViolation}, "Exec()")))
+	requireTest.Equal(storages.ErrNotFound, mapErr(&pgconn.PgError{Code: foreignKeyViolation}))
+
+	other := &pgconn.PgError{Code: "42601"}
+	requireTest.Equal(other, mapErr(other))
+
+	plain := errors.New("plain")
+	requireTest.Equal(plain, mapErr(plain))
+}
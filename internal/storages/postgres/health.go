@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/manabie-com/togo/internal/migrations"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// CheckHealth pings the primary and checks that every migration of the schema is applied, the pool is down
+// while its last health check failed, until it recovers or is re-created
+func (pg *Postgres) CheckHealth(ctx context.Context) map[string]error {
+	health := map[string]error{"database": nil, "pool": nil, "migrations": nil}
+	if err := ping(ctx, pg.pool()); err != nil {
+		health["database"] = errors.Wrap(err, "ping()")
+	}
+	if failures, _, err := pg.health.state(); err != nil {
+		health["pool"] = errors.Wrapf(err, "%d health checks failed in a row", failures)
+	}
+
+	migrator, err := migrations.NewMigrator(pg.pool(), schemaMigrations(pg.dialect))
+	if err != nil {
+		health["migrations"] = err
+		return health
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		health["migrations"] = err
+		return health
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			health["migrations"] = errors.Errorf("migration %d %s is not applied", status.Version, status.Name)
+			break
+		}
+	}
+	return health
+}
+
+// PoolStats returns the connections and the health of the pool of the primary
+func (pg *Postgres) PoolStats() storages.PoolStats {
+	stat := pg.pool().Stat()
+	failures, recreations, err := pg.health.state()
+	return storages.PoolStats{
+		Healthy:             err == nil,
+		ConsecutiveFailures: failures,
+		Recreations:         recreations,
+
+		AcquiredConns:     int(stat.AcquiredConns()),
+		IdleConns:         int(stat.IdleConns()),
+		TotalConns:        int(stat.TotalConns()),
+		MaxConns:          int(stat.MaxConns()),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+		AcquireDuration:   stat.AcquireDuration(),
+	}
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// GetIdempotentResponse returns the response saved under key for the user
+func (pg *Postgres) GetIdempotentResponse(ctx context.Context, usrId int, key string, now time.Time) (*storages.IdempotentResponse, error) {
+	stmt := `SELECT request_hash, status, body, expires_at FROM idempotency_key WHERE usr_id = $1 AND key = $2 AND expires_at > $3`
+	resp := &storages.IdempotentResponse{UsrId: usrId, Key: key}
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, usrId, key, now).Scan(&resp.RequestHash, &resp.Status, &resp.Body, &resp.ExpiresAt)
+	})
+	switch err {
+	case nil:
+		return resp, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// SaveIdempotentResponse deletes the expired responses of the user and saves resp unless its key is taken
+func (pg *Postgres) SaveIdempotentResponse(ctx context.Context, resp *storages.IdempotentResponse, now time.Time) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if _, err := pg.pool().Exec(ctx, `DELETE FROM idempotency_key WHERE usr_id = $1 AND expires_at <= $2`, resp.UsrId, now); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		stmt := `
+			INSERT INTO idempotency_key (usr_id, key, request_hash, status, body, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (usr_id, key) DO NOTHING`
+		tag, err := pg.pool().Exec(ctx, stmt, resp.UsrId, resp.Key, resp.RequestHash, resp.Status, resp.Body, resp.ExpiresAt)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrConflict
+		}
+		return nil
+	})
+}
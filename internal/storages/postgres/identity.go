@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// linkIdentityStmt links an identity unless it's linked already, RETURNING tells the user it's linked to
+const linkIdentityStmt = `
+	INSERT INTO usr_identity (provider, subject, usr_id) VALUES ($1, $2, $3)
+	ON CONFLICT (provider, subject) DO UPDATE SET provider = EXCLUDED.provider
+	RETURNING usr_id`
+
+// FindIdentityUser returns the user linked to subject of provider
+func (pg *Postgres) FindIdentityUser(ctx context.Context, provider, subject string) (*storages.User, error) {
+	stmt := `
+		SELECT usr.id, usr.username, ` + usrMaxTodo + `, usr.role
+		FROM usr_identity AS i JOIN usr ON usr.id = i.usr_id
+		WHERE i.provider = $1 AND i.subject = $2`
+	usr := &storages.User{}
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, provider, subject).Scan(&usr.Id, &usr.Username, &usr.MaxTodo, &usr.Role)
+	})
+	switch err {
+	case nil:
+		return usr, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// LinkIdentity links subject of provider to the user, an unknown user violates the foreign key
+func (pg *Postgres) LinkIdentity(ctx context.Context, usrId int, provider, subject string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		return scanLinked(pg.pool().QueryRow(ctx, linkIdentityStmt, provider, subject, usrId), usrId)
+	})
+}
+
+// CreateIdentityUser inserts usr and links subject of provider to it in one transaction
+func (pg *Postgres) CreateIdentityUser(ctx context.Context, usr *storages.User, provider, subject string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		if err := tx.QueryRow(ctx, insertUserStmt, insertUserArgs(usr)...).Scan(&usr.Id); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		if err := scanLinked(tx.QueryRow(ctx, linkIdentityStmt, provider, subject, usr.Id), usr.Id); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+}
+
+// scanLinked scans the result of linkIdentityStmt, an identity linked to another user is a conflict
+func scanLinked(row pgx.Row, usrId int) error {
+	var linked int
+	if err := row.Scan(&linked); err != nil {
+		return mapErr(errors.Wrap(err, "Scan()"))
+	}
+	if linked != usrId {
+		return storages.ErrConflict
+	}
+	return nil
+}
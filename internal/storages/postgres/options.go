@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"crypto/tls"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgconn/stmtcache"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+// settings are built from the connection string then adjusted by options
+type settings struct {
+	pool      *pgxpool.Config
+	migrate   bool
+	replicas  []string
+	opTimeout time.Duration
+	retry     RetryPolicy
+	slowQuery time.Duration
+	// recreateAfter is how many health checks in a row the pool fails before it's re-created, zero disables it
+	recreateAfter int
+	// connectWait is how long connecting on start is retried while the server isn't ready
+	connectWait time.Duration
+}
+
+// Option configures how Postgres connects
+type Option func(*settings)
+
+// WithMaxConns sets the maximum size of the pool
+func WithMaxConns(n int32) Option {
+	return func(s *settings) {
+		s.pool.MaxConns = n
+	}
+}
+
+// WithMinConns sets the minimum size of the pool
+func WithMinConns(n int32) Option {
+	return func(s *settings) {
+		s.pool.MinConns = n
+	}
+}
+
+// WithConnectTimeout sets the timeout of establishing a connection
+func WithConnectTimeout(d time.Duration) Option {
+	return func(s *settings) {
+		s.pool.ConnConfig.ConnectTimeout = d
+	}
+}
+
+// WithTLSConfig sets the TLS config of connections, nil disables TLS
+func WithTLSConfig(config *tls.Config) Option {
+	return func(s *settings) {
+		s.pool.ConnConfig.TLSConfig = config
+		s.pool.ConnConfig.Fallbacks = nil
+	}
+}
+
+// WithReplicas routes reads to the given replicas, reads fail over to primary
+// while replicas are unhealthy
+func WithReplicas(connStrs ...string) Option {
+	return func(s *settings) {
+		s.replicas = append(s.replicas, connStrs...)
+	}
+}
+
+// WithStatementCache sets how statements are cached on each connection:
+// mode "prepare" prepares every distinct SQL once per connection, mode "describe"
+// only caches statement descriptions (safe behind PgBouncer), zero capacity disables caching
+func WithStatementCache(mode string, capacity int) Option {
+	return func(s *settings) {
+		if capacity <= 0 {
+			s.pool.ConnConfig.BuildStatementCache = nil
+			return
+		}
+
+		cacheMode := stmtcache.ModePrepare
+		if mode == "describe" {
+			cacheMode = stmtcache.ModeDescribe
+		}
+		s.pool.ConnConfig.BuildStatementCache = func(conn *pgconn.PgConn) stmtcache.Cache {
+			return stmtcache.New(conn, cacheMode, capacity)
+		}
+	}
+}
+
+// WithMigrations turns on or off applying pending migrations on start
+func WithMigrations(on bool) Option {
+	return func(s *settings) {
+		s.migrate = on
+	}
+}
+
+// WithOpTimeout bounds each attempt of a storage operation, zero means no timeout
+func WithOpTimeout(d time.Duration) Option {
+	return func(s *settings) {
+		s.opTimeout = d
+	}
+}
+
+// WithRetryPolicy sets how operations failed with transient errors
+// (serialization failures, deadlocks, connection resets) are retried
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *settings) {
+		s.retry = policy
+	}
+}
+
+// WithSlowQueryThreshold logs statements which take d or longer, zero turns it off
+func WithSlowQueryThreshold(d time.Duration) Option {
+	return func(s *settings) {
+		s.slowQuery = d
+	}
+}
+
+// WithPoolRecreation re-creates the pool of the primary once it failed n health checks in a row, zero turns it off
+func WithPoolRecreation(n int) Option {
+	return func(s *settings) {
+		s.recreateAfter = n
+	}
+}
+
+// WithConnectWait retries connecting on start for d while the server isn't ready, zero fails at the first attempt
+func WithConnectWait(d time.Duration) Option {
+	return func(s *settings) {
+		s.connectWait = d
+	}
+}
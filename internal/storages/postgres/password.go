@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// GetUser returns the user of id along with its password hash
+func (pg *Postgres) GetUser(ctx context.Context, id int) (*storages.User, error) {
+	stmt := `SELECT id, username, pwd_hash, ` + usrMaxTodo + `, role FROM usr WHERE id = $1`
+	usr := &storages.User{}
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, id).Scan(&usr.Id, &usr.Username, &usr.PwdHash, &usr.MaxTodo, &usr.Role)
+	})
+	switch err {
+	case nil:
+		return usr, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// SetPassword replaces the password hash of the user and revokes their sessions in one transaction
+func (pg *Postgres) SetPassword(ctx context.Context, usrId int, pwdHash string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		if err := setPassword(ctx, tx, usrId, pwdHash); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+}
+
+// CreatePasswordReset saves reset for the user of username
+func (pg *Postgres) CreatePasswordReset(ctx context.Context, username string, reset *storages.PasswordReset) (*storages.User, error) {
+	reset.CreateAt = time.Now().UTC()
+
+	usr := &storages.User{}
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, `SELECT id, username, `+usrMaxTodo+` FROM usr WHERE username = $1`, username)
+		switch err := row.Scan(&usr.Id, &usr.Username, &usr.MaxTodo); err {
+		case nil:
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		reset.UsrId = usr.Id
+		stmt := `INSERT INTO password_reset (usr_id, hash, create_at, expires_at) VALUES ($1, $2, $3, $4) RETURNING id`
+		if err := pg.pool().QueryRow(ctx, stmt, reset.UsrId, reset.Hash, reset.CreateAt, reset.ExpiresAt).Scan(&reset.Id); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usr, nil
+}
+
+// ResetPassword uses the reset token of hash, the token row is locked so it's used once
+// even if it's presented twice at the same time
+func (pg *Postgres) ResetPassword(ctx context.Context, hash, pwdHash string) (*storages.User, error) {
+	var usr *storages.User
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		var usrId int
+		var expiresAt time.Time
+		var usedAt *time.Time
+		stmt := `SELECT usr_id, expires_at, used_at FROM password_reset WHERE hash = $1 FOR UPDATE`
+		err = tx.QueryRow(ctx, stmt, hash).Scan(&usrId, &expiresAt, &usedAt)
+		switch {
+		case err == pgx.ErrNoRows:
+			return storages.ErrInvalidCredentials
+		case err != nil:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		case usedAt != nil || !expiresAt.After(time.Now()):
+			return storages.ErrInvalidCredentials
+		}
+
+		if err := setPassword(ctx, tx, usrId, pwdHash); err != nil {
+			return err
+		}
+		usr = &storages.User{}
+		row := tx.QueryRow(ctx, `SELECT id, username, `+usrMaxTodo+` FROM usr WHERE id = $1`, usrId)
+		if err := row.Scan(&usr.Id, &usr.Username, &usr.MaxTodo); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usr, nil
+}
+
+// setPassword replaces the password hash in tx, it revokes refresh tokens of the user so that
+// whoever knew the old password is logged out and uses up pending resets
+func setPassword(ctx context.Context, tx pgx.Tx, usrId int, pwdHash string) error {
+	tag, err := tx.Exec(ctx, `UPDATE usr SET pwd_hash = $2 WHERE id = $1`, usrId, pwdHash)
+	if err != nil {
+		return mapErr(errors.Wrap(err, "Exec()"))
+	}
+	if tag.RowsAffected() == 0 {
+		return storages.ErrNotFound
+	}
+	if _, err := tx.Exec(ctx, `UPDATE refresh_token SET revoked_at = now() WHERE usr_id = $1 AND revoked_at IS NULL`, usrId); err != nil {
+		return mapErr(errors.Wrap(err, "Exec()"))
+	}
+	if _, err := tx.Exec(ctx, `UPDATE password_reset SET used_at = now() WHERE usr_id = $1 AND used_at IS NULL`, usrId); err != nil {
+		return mapErr(errors.Wrap(err, "Exec()"))
+	}
+	return nil
+}
@@ -4,210 +4,1005 @@ import (
 	"context"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/migrations"
+	"github.com/manabie-com/togo/internal/password"
 	"github.com/manabie-com/togo/internal/storages"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var (
-	ErrIncorrectUsernameOrPassword = errors.New("username or password is not correct")
-	ErrUserMaxTodoReached          = errors.New("user's daily-limit has been reached")
-)
-
-type Database interface {
-	ValidateUser(ctx context.Context, username, password string) (*storages.User, error)
-	GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error)
-	InsertTask(ctx context.Context, task *storages.Task) error
-}
-
 // Postgres represents a database instance for working with Postgres
 type Postgres struct {
-	pool *pgxpool.Pool
+	// primary holds the *pgxpool.Pool of the primary, it's re-created from poolConfig once it failed
+	// recreateAfter health checks in a row
+	primary       atomic.Value
+	poolConfig    *pgxpool.Config
+	recreateAfter int
+	health        poolHealth
+	dialect       Dialect
+
+	// opTimeout bounds each attempt of an operation, retry tells how failed operations are retried
+	opTimeout time.Duration
+	retry     RetryPolicy
+
+	// quotaPolicy tells which tasks count towards the limits of users and of projects,
+	// burst how many tasks users may add over their limits. quotaMu guards them as they change on reload
+	quotaMu     sync.RWMutex
+	quotaPolicy storages.QuotaPolicy
+	burst       storages.Burst
+
+	// reads are routed to replicas, writes to the primary. stopHealthCheck stops the health checks of both,
+	// checks is done once they stopped
+	replicas        []*replica
+	nextReplicaIdx  uint32
+	stopHealthCheck chan struct{}
+	checks          sync.WaitGroup
+
+	// statements is told of the statements of the primary and of the replicas
+	statements *statementLogger
 }
 
-// NewPostgres create new Postgres instance
-func NewPostgres(ctx context.Context) (*Postgres, error) {
-	var connStr string
-	switch v := ctx.Value("config").(type) {
-	case *Config:
-		connStr = v.toConnStr()
-	default:
+// NewPostgres create new Postgres instance from config, options take precedence
+// over the config
+func NewPostgres(ctx context.Context, config *Config, opts ...Option) (*Postgres, error) {
+	if config == nil {
 		return nil, errors.New("no config")
 	}
+	return connect(ctx, config.ConnString(), config.Dialect, opts...)
+}
+
+// connect creates new Postgres instance from the given connection string,
+// pending migrations are applied unless the connection string has x-migrate=false
+// or WithMigrations(false) is given
+func connect(ctx context.Context, connStr string, dialect Dialect, opts ...Option) (*Postgres, error) {
+	config, migrate, err := parseConfig(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &settings{
+		pool:     config,
+		migrate:  migrate,
+		replicas: splitReplicas(config.ConnConfig.RuntimeParams[replicasParam]),
+		retry:    defaultRetryPolicy,
+
+		recreateAfter: defaultRecreateAfter,
+		connectWait:   defaultConnectWait,
+	}
+	delete(config.ConnConfig.RuntimeParams, replicasParam)
+	if err := parseRetryParams(config.ConnConfig.RuntimeParams, s); err != nil {
+		return nil, err
+	}
+	if err := parseRecreateParam(config.ConnConfig.RuntimeParams, s); err != nil {
+		return nil, err
+	}
+	if err := parseSlowQueryParam(config.ConnConfig.RuntimeParams, s); err != nil {
+		return nil, err
+	}
+	if err := parseConnectWaitParam(config.ConnConfig.RuntimeParams, s); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// statements are traced as spans of the requests they run for, timed and logged if they're slow
+	statements := &statementLogger{slowQuery: s.slowQuery}
+	s.pool.ConnConfig.Logger = statements
+	s.pool.ConnConfig.LogLevel = pgx.LogLevelInfo
 
-	pool, err := pgxpool.Connect(ctx, connStr)
+	pool, err := connectPool(ctx, s.pool, s.connectWait)
 	if err != nil {
-		return nil, errors.Wrap(err, "Connect()")
+		return nil, err
 	}
 
 	pg := &Postgres{
-		pool: pool,
+		poolConfig:      s.pool,
+		recreateAfter:   s.recreateAfter,
+		dialect:         dialect,
+		opTimeout:       s.opTimeout,
+		retry:           s.retry,
+		quotaPolicy:     storages.DailyQuota{},
+		stopHealthCheck: make(chan struct{}),
+		statements:      statements,
 	}
+	pg.primary.Store(pool)
 
-	if err := pg.init(ctx); err != nil {
+	if err := pg.init(ctx, s.migrate); err != nil {
+		pool.Close()
 		return nil, errors.Wrap(err, "init()")
 	}
 
+	if len(s.replicas) > 0 {
+		if pg.replicas, err = connectReplicas(ctx, s.replicas, statements); err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pg.checks.Add(1)
+		go func() {
+			defer pg.checks.Done()
+			pg.checkReplicas(pg.stopHealthCheck)
+		}()
+	}
+
+	pg.checks.Add(1)
+	go func() {
+		defer pg.checks.Done()
+		pg.checkPool(pg.stopHealthCheck)
+	}()
 	return pg, nil
 }
 
-// init initializes pgcrypto extension, tables, indexes, ... and
-// inserts default data
-func (pg *Postgres) init(ctx context.Context) error {
-	stmt :=
-		`
-		SET TIMEZONE = 'Asia/Ho_Chi_Minh';
-
-		CREATE EXTENSION IF NOT EXISTS pgcrypto;
-
-		CREATE TABLE IF NOT EXISTS usr (
-		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
-		    username	varchar(36) NOT NULL UNIQUE ,
-		    pwd_hash 	text NOT NULL ,
-		    max_todo 	int NOT NULL DEFAULT 5 CHECK ( max_todo >= 0 )
-		);
-		CREATE TABLE IF NOT EXISTS task (
-		  	id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
-		  	usr_id 		int NOT NULL REFERENCES usr(id),
-		  	content 	text NOT NULL ,
-		  	create_at	timestamptz NOT NULL
-		);
-
-		CREATE INDEX IF NOT EXISTS usr_username_pwd_hash_idx ON usr(username, pwd_hash);
-		CREATE INDEX IF NOT EXISTS task_usr_id_idx ON task(usr_id);
-		CREATE INDEX IF NOT EXISTS task_usr_id_create_at_idx ON task(usr_id);
-
-		INSERT INTO usr (
-			id,
-			username, 
-			pwd_hash, 
-			max_todo
-		) OVERRIDING SYSTEM VALUE VALUES (
-		    1,                              
-			'firstUser',
-		    crypt('example', gen_salt('bf')) ,
-			5
-		) ON CONFLICT DO NOTHING ;
-
-		INSERT INTO task (
-			id,
-			usr_id, 
-			content, 
-			create_at) 
-		OVERRIDING SYSTEM VALUE VALUES  (
-			1,
-			1,
-			'test 1',
-			'2020-06-29'::timestamptz
-		) ON CONFLICT DO NOTHING ;
-		`
+// parseConfig parses pool config from the connection string and takes out x-migrate param
+func parseConfig(connStr string) (*pgxpool.Config, bool, error) {
+	config, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "ParseConfig()")
+	}
 
-	_, err := pg.pool.Exec(ctx, stmt)
+	// pgx keeps unknown params as runtime params which would be sent to server
+	migrate := config.ConnConfig.RuntimeParams[migrateParam] != "false"
+	delete(config.ConnConfig.RuntimeParams, migrateParam)
+
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, "SET TIME ZONE 'Asia/Ho_Chi_Minh'")
+		return err
+	}
+
+	return config, migrate, nil
+}
+
+// init applies migrations, data is seeded separately by Seed
+func (pg *Postgres) init(ctx context.Context, migrate bool) error {
+	if !migrate {
+		return nil
+	}
+
+	migrator, err := migrations.NewMigrator(pg.pool(), schemaMigrations(pg.dialect))
 	if err != nil {
-		return errors.Wrap(err, "Exec()")
+		return err
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		return errors.Wrap(err, "Up()")
 	}
 	return nil
 }
 
-// ValidateUser
-func (pg *Postgres) ValidateUser(ctx context.Context, username, password string) (*storages.User, error) {
+// OpenMigrator connects to the database only for running migrations
+// in standalone mode, the returned func closes the connection
+func OpenMigrator(ctx context.Context, connStr string, dialect Dialect) (*migrations.Migrator, func(), error) {
+	if connStr == "" {
+		connStr = defaultConnString
+		if dialect == DialectCockroach {
+			connStr = defaultCockroachConnString
+		}
+	}
+
+	config, _, err := parseConfig(connStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &settings{connectWait: defaultConnectWait}
+	if err := parseConnectWaitParam(config.ConnConfig.RuntimeParams, s); err != nil {
+		return nil, nil, err
+	}
+
+	pool, err := connectPool(ctx, config, s.connectWait)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	migrator, err := migrations.NewMigrator(pool, schemaMigrations(dialect))
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+
+	return migrator, pool.Close, nil
+}
+
+// ValidateUser returns user if match username AND password, passwords are compared in Go
+// so they never reach the database and legacy hashes are upgraded on success
+func (pg *Postgres) ValidateUser(ctx context.Context, username, pwd string) (*storages.User, error) {
 	stmt :=
 		`
 		SELECT 
 			id,
 			username,
 			pwd_hash,
-			max_todo
+			` + usrMaxTodo + `,
+			role
 		FROM 
 			usr
 		WHERE 
 			username = $1
-			AND pwd_hash = crypt($2, pwd_hash)
 		`
-	row := pg.pool.QueryRow(ctx, stmt, username, password)
-
 	usr := &storages.User{}
-	err := row.Scan(&usr.Id, &usr.Username, &usr.PwdHash, &usr.MaxTodo)
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		return pool.QueryRow(ctx, stmt, username).Scan(&usr.Id, &usr.Username, &usr.PwdHash, &usr.MaxTodo, &usr.Role)
+	})
 
 	switch err {
 	case nil:
+		if !password.Compare(usr.PwdHash, pwd) {
+			return nil, storages.ErrInvalidCredentials
+		}
+		if password.NeedsRehash(usr.PwdHash) {
+			pg.rehash(ctx, usr, pwd)
+		}
 		return usr, nil
 	case pgx.ErrNoRows:
-		return nil, ErrIncorrectUsernameOrPassword
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
 	default:
-		return nil, errors.Wrap(err, "Scan()")
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// rehash replaces the legacy hash of usr, a failure only postpones it to the next login
+func (pg *Postgres) rehash(ctx context.Context, usr *storages.User, pwd string) {
+	pwdHash, err := password.Hash(pwd)
+	if err == nil {
+		err = pg.do(ctx, false, func(ctx context.Context) error {
+			_, err := pg.pool().Exec(ctx, `UPDATE usr SET pwd_hash = $2 WHERE id = $1 AND pwd_hash = $3`, usr.Id, pwdHash, usr.PwdHash)
+			return mapErr(err)
+		})
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("rehashing password", zap.Int("user_id", usr.Id), zap.Error(err))
+		return
 	}
+	usr.PwdHash = pwdHash
 }
 
+// GetTasks returns tasks of the user which were created on the day of createAt in its location, deleted tasks are left out
 func (pg *Postgres) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	return pg.FindTasks(ctx, usrId, createAt, storages.TaskFilter{})
+}
+
+// taskColumns are the columns scanned by scanTask
+const taskColumns = `id, usr_id, content, create_at, deleted_at, archived_at, status, due_at, priority, completed_at, version, update_at,
+	ARRAY(SELECT tag FROM task_tag WHERE task_tag.task_id = task.id ORDER BY tag), project_id,
+	(SELECT count(*) FROM checklist_item WHERE checklist_item.task_id = task.id),
+	(SELECT count(*) FROM checklist_item WHERE checklist_item.task_id = task.id AND done),
+	recurrence, recur_at, position, remind_at, reminded_at, content_format`
+
+func scanTask(row pgx.Row, task *storages.Task) error {
+	var status, contentFormat string
+	var checklist storages.Checklist
+	var recurrence *string
+	err := row.Scan(
+		&task.Id,
+		&task.UsrId,
+		&task.Content,
+		&task.CreateAt,
+		&task.DeletedAt,
+		&task.ArchivedAt,
+		&status,
+		&task.DueAt,
+		&task.Priority,
+		&task.CompletedAt,
+		&task.Version,
+		&task.UpdateAt,
+		&task.Tags,
+		&task.ProjectId,
+		&checklist.Total,
+		&checklist.Done,
+		&recurrence,
+		&task.RecurAt,
+		&task.Position,
+		&task.RemindAt,
+		&task.RemindedAt,
+		&contentFormat,
+	)
+	if err != nil {
+		return err
+	}
+	task.Status = storages.TaskStatus(status)
+	task.ContentFormat = storages.ContentFormat(contentFormat)
+	task.Checklist = nil
+	if checklist.Total > 0 {
+		task.Checklist = &checklist
+	}
+	task.Recurrence = ""
+	if recurrence != nil {
+		task.Recurrence = storages.TaskRecurrence(*recurrence)
+	}
+	return nil
+}
+
+// GetTask returns the task of the user
+func (pg *Postgres) GetTask(ctx context.Context, usrId, id int) (*storages.Task, error) {
+	stmt := `SELECT ` + taskColumns + ` FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	task := &storages.Task{}
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return scanTask(pg.pool().QueryRow(ctx, stmt, id, usrId), task)
+	})
+	switch err {
+	case nil:
+		return task, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// FindTasks returns tasks of the user which were created on the date of createAt matching the filter
+func (pg *Postgres) FindTasks(ctx context.Context, usrId int, createAt time.Time, filter storages.TaskFilter) ([]*storages.Task, error) {
+	stmt, args, err := findTasksQuery(usrId, createAt, filter)
+	if err != nil {
+		return nil, err
+	}
+	return pg.queryTasks(ctx, stmt, args...)
+}
+
+// StreamTasks calls fn with tasks of FindTasks as they're scanned. A retried read skips the rows fn was
+// given already, the order of rows is total so they're the same ones unless tasks changed meanwhile
+func (pg *Postgres) StreamTasks(ctx context.Context, usrId int, createAt time.Time, filter storages.TaskFilter, fn func(*storages.Task) error) error {
+	stmt, args, err := findTasksQuery(usrId, createAt, filter)
+	if err != nil {
+		return err
+	}
+
+	var sent int
+	var fnErr error
+	err = pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, stmt, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for skip := sent; rows.Next(); skip-- {
+			if skip > 0 {
+				continue
+			}
+			task := &storages.Task{}
+			if err := scanTask(rows, task); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			// errors of fn aren't of the database, they must not be retried
+			if fnErr = fn(task); fnErr != nil {
+				return nil
+			}
+			sent++
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return mapErr(err)
+	}
+	return fnErr
+}
+
+// findTasksQuery returns the statement of FindTasks and its args
+func findTasksQuery(usrId int, createAt time.Time, filter storages.TaskFilter) (string, []interface{}, error) {
+	if !filter.SortBy.Valid() {
+		return "", nil, storages.ErrInvalidTask
+	}
+	tags, err := storages.NormalizeTags(filter.Tags)
+	if err != nil {
+		return "", nil, err
+	}
+	orderBy := "create_at, id"
+	switch filter.SortBy {
+	case storages.TaskSortPriority:
+		orderBy = "priority DESC, create_at, id"
+	case storages.TaskSortPosition:
+		orderBy = "position, id"
+	}
+
 	stmt :=
 		`
 		SELECT 
-			id, usr_id, content, create_at
+			` + taskColumns + `
 		FROM 
 		     task
 		WHERE 
 		      usr_id = $1
-		      AND create_at::date = $2::date
+		      AND create_at >= $2 AND create_at < $7
+		      AND ($3 OR deleted_at IS NULL)
+		      AND ($4::bool IS NULL OR (status = 'done') = $4)
+		      AND (
+		          array_length($5::text[], 1) IS NULL 
+		          OR (SELECT count(*) FROM task_tag WHERE task_tag.task_id = task.id AND tag = ANY($5)) = array_length($5::text[], 1)
+		      )
+		      AND ($6::int IS NULL OR project_id = $6)
+		ORDER BY ` + orderBy
+	from, to := storages.DayRangeIn(createAt, createAt.Location())
+	return stmt, []interface{}{usrId, from, filter.IncludeDeleted, filter.Completed, tags, filter.ProjectId, to}, nil
+}
+
+// FindOverdueTasks returns not done tasks of the user which were due before now,
+// the query is served by the partial task_usr_id_due_at_overdue_idx index
+func (pg *Postgres) FindOverdueTasks(ctx context.Context, usrId int, now time.Time) ([]*storages.Task, error) {
+	stmt :=
 		`
+		SELECT 
+			` + taskColumns + `
+		FROM 
+		     task
+		WHERE 
+		      usr_id = $1
+		      AND due_at < $2
+		      AND deleted_at IS NULL
+		      AND status <> 'done'
+		      AND due_at IS NOT NULL
+		ORDER BY due_at, id
+		`
+	return pg.queryTasks(ctx, stmt, usrId, now)
+}
 
-	rows, err := pg.pool.Query(ctx, stmt, usrId, createAt)
-	switch err {
-	case nil:
+// queryTasks runs a read-only stmt which selects taskColumns
+func (pg *Postgres) queryTasks(ctx context.Context, stmt string, args ...interface{}) ([]*storages.Task, error) {
+	var tasks []*storages.Task
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, stmt, args...)
+		if err != nil {
+			return err
+		}
 		defer rows.Close()
-	case pgx.ErrNoRows:
-		return nil, nil
-	default:
-		return nil, err
-	}
 
-	tasks := make([]*storages.Task, 0)
-	for rows.Next() {
-		task := &storages.Task{}
-		err := rows.Scan(
-			&task.Id,
-			&task.UsrId,
-			&task.Content,
-			&task.CreateAt,
-		)
-		if err != nil {
-			return nil, errors.Wrap(err, "Scan()")
+		tasks = make([]*storages.Task, 0)
+		for rows.Next() {
+			task := &storages.Task{}
+			if err := scanTask(rows, task); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			tasks = append(tasks, task)
 		}
-		tasks = append(tasks, task)
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
 	}
 
 	return tasks, nil
 }
 
-func (pg *Postgres) InsertTask(ctx context.Context, task *storages.Task) error {
-	task.CreateAt = time.Now()
+// DeleteTask soft deletes the task of the user
+func (pg *Postgres) DeleteTask(ctx context.Context, usrId, id int) error {
+	stmt := `UPDATE task SET deleted_at = now(), version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	err := pg.updateTask(ctx, stmt, id, usrId)
+	if err != storages.ErrNotFound {
+		return err
+	}
+
+	// tell a task of another user from a missing one, replicas might lag behind
+	var exists bool
+	stmt = `SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND deleted_at IS NULL)`
+	err = pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, id).Scan(&exists)
+	})
+	switch {
+	case err != nil:
+		return mapErr(errors.Wrap(err, "Scan()"))
+	case exists:
+		return storages.ErrForbidden
+	default:
+		return storages.ErrNotFound
+	}
+}
+
+// ArchiveTask archives the task of the user, archiving an archived task keeps its archived_at
+func (pg *Postgres) ArchiveTask(ctx context.Context, usrId, id int) error {
+	stmt := `UPDATE task SET archived_at = COALESCE(archived_at, now()), version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	return pg.updateTask(ctx, stmt, id, usrId)
+}
+
+// SetTaskStatus sets status of the task of the user, completed_at follows the status
+func (pg *Postgres) SetTaskStatus(ctx context.Context, usrId, id int, status storages.TaskStatus) error {
+	if !status.Valid() {
+		return storages.ErrInvalidTask
+	}
+	stmt := `
+		UPDATE task SET 
+			status = $3, 
+			completed_at = CASE WHEN $3 = 'done' THEN COALESCE(completed_at, now()) END,
+			version = version + 1
+		WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	return pg.updateTask(ctx, stmt, id, usrId, string(status))
+}
+
+// SetTaskDueAt sets due date of the task of the user, nil clears it
+func (pg *Postgres) SetTaskDueAt(ctx context.Context, usrId, id int, dueAt *time.Time) error {
+	stmt := `UPDATE task SET due_at = $3, version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	return pg.updateTask(ctx, stmt, id, usrId, dueAt)
+}
+
+// SetTaskPriority sets priority of the task of the user
+func (pg *Postgres) SetTaskPriority(ctx context.Context, usrId, id int, priority int) error {
+	stmt := `UPDATE task SET priority = $3, version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	return pg.updateTask(ctx, stmt, id, usrId, priority)
+}
+
+// UpdateTask saves task if its version is still the stored one
+func (pg *Postgres) UpdateTask(ctx context.Context, task *storages.Task) error {
+	if err := task.SetStatus(task.Status, time.Now()); err != nil {
+		return err
+	}
+	if err := task.NormalizeContent(); err != nil {
+		return err
+	}
+
 	stmt :=
 		`
-		INSERT INTO 
-		    task (usr_id, content, create_at)
-		SELECT 
-		   $1, $2, $3::timestamptz
+		UPDATE task SET
+			content = $4,
+			status = $5,
+			due_at = $6,
+			priority = $7,
+			content_format = $8,
+			completed_at = CASE WHEN $5 = 'done' THEN COALESCE(completed_at, now()) END,
+			version = version + 1
 		WHERE 
-			(
-				SELECT count(*) FROM task
-				WHERE 
-					usr_id = $1
-					AND create_at::date = $3::date
-			) < (SELECT max_todo FROM usr WHERE id = $1)
+			id = $1 
+			AND usr_id = $2 
+			AND version = $3 
+			AND deleted_at IS NULL
+		RETURNING ` + taskColumns
+
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, task.Id, task.UsrId, task.Version, task.Content, string(task.Status), task.DueAt, task.Priority, string(task.ContentFormat))
+		switch err := scanTask(row, task); err {
+		case nil:
+			return nil
+		case pgx.ErrNoRows:
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		// tell a stale version from a missing task
+		var exists bool
+		stmt := `SELECT EXISTS (SELECT 1 FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL)`
+		if err := pg.pool().QueryRow(ctx, stmt, task.Id, task.UsrId).Scan(&exists); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		if exists {
+			return storages.ErrConflict
+		}
+		return storages.ErrNotFound
+	})
+}
+
+// AddTaskTags tags the task of the user
+func (pg *Postgres) AddTaskTags(ctx context.Context, usrId, id int, tags []string) error {
+	stmt := `INSERT INTO task_tag (task_id, tag) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`
+	return pg.changeTags(ctx, usrId, id, tags, stmt)
+}
+
+// RemoveTaskTags untags the task of the user
+func (pg *Postgres) RemoveTaskTags(ctx context.Context, usrId, id int, tags []string) error {
+	stmt := `DELETE FROM task_tag WHERE task_id = $1 AND tag = ANY($2)`
+	return pg.changeTags(ctx, usrId, id, tags, stmt)
+}
+
+// changeTags runs stmt on tags of the task
+func (pg *Postgres) changeTags(ctx context.Context, usrId, id int, tags []string, stmt string) error {
+	tags, err := storages.NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+
+	return pg.changeTask(ctx, usrId, id, func(ctx context.Context, tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, stmt, id, tags); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
+
+// changeTask runs fn in a transaction which first increases the version of the task,
+// that also locks the task and checks that the user owns it
+func (pg *Postgres) changeTask(ctx context.Context, usrId, id int, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		tag, err := tx.Exec(ctx, `UPDATE task SET version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`, id, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+}
+
+// updateTask runs stmt which updates a single task, it returns storages.ErrNotFound if no row was updated
+func (pg *Postgres) updateTask(ctx context.Context, stmt string, args ...interface{}) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, stmt, args...)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
+
+const (
+	// lockUsrStmt locks rows of the given users in a consistent order to avoid deadlocks
+	lockUsrStmt = `SELECT id FROM usr WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+	// lockUsrQuotaStmt locks rows of the given users like lockUsrStmt and returns their quota states
+	lockUsrQuotaStmt = `SELECT id, timezone, burst_used, burst_at, quota_reset_at FROM usr WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+
+	// insertTaskWithQuotaStmt inserts task and its tags only if the limits of the user and of the project
+	// allow it, it returns no rows otherwise. Tasks created within [$15, $16) count towards the limits, from $19
+	// for the user whose quota was reset, and the user may add $18 tasks over theirs. The task is counted in
+	// task_usage on the day $17 of the user
+	insertTaskWithQuotaStmt = `
+		WITH inserted AS (
+			INSERT INTO 
+			    task (usr_id, content, create_at, status, due_at, priority, completed_at, project_id, recurrence, recur_at, position, remind_at, content_format)
+			SELECT 
+			   $1, $2, $3::timestamptz, $4, $5, $6, $7, $9, $10, $11, $12, $13, $14
+			WHERE 
+				(
+					SELECT count(*) FROM task
+					WHERE 
+						usr_id = $1
+						AND create_at >= $19 AND create_at < $16
+				) < (SELECT COALESCE(NULLIF(` + usrMaxTodo + `, 0), 2147483647)::int8 + $18 FROM usr WHERE id = $1)
+				AND (
+					$9::int IS NULL
+					OR (
+						SELECT count(*) FROM task
+						WHERE 
+							project_id = $9
+							AND create_at >= $15 AND create_at < $16
+					) < (SELECT COALESCE(max_todo, 2147483647) FROM project WHERE id = $9)
+				)
+			RETURNING id
+		), tagged AS (
+			INSERT INTO task_tag (task_id, tag)
+			SELECT id, unnest($8::text[]) FROM inserted
+		), counted AS (
+			INSERT INTO task_usage (usr_id, day, tasks)
+			SELECT $1, $17::date, 1 FROM inserted
+			ON CONFLICT (usr_id, day) DO UPDATE SET tasks = task_usage.tasks + 1
+		)
+		SELECT id FROM inserted
 		`
+)
 
-	cmd, err := pg.pool.Exec(ctx, stmt, task.UsrId, task.Content, task.CreateAt)
+// quotaArgs appends the window of the quota policy for task created by the locked user, the day of the user
+// it's created on, the burst available to the user and when their tasks start counting to args of
+// insertTaskWithQuotaStmt
+func (pg *Postgres) quotaArgs(args []interface{}, task *storages.Task, locked *lockedUsr) []interface{} {
+	from, to := pg.quotaWindow(task.CreateAt, locked.timezone)
+	usr := &storages.User{Timezone: locked.timezone}
+	return append(args, from, to, task.CreateAt.In(usr.Location()).Format(dateLayout),
+		pg.currentBurst().Available(locked.burst, task.CreateAt), storages.CountFrom(from, locked.resetAt))
+}
+
+// quotaWindow returns the window of the quota policy for a task added at by a user in timezone,
+// timezones unknown to Go count as UTC
+func (pg *Postgres) quotaWindow(at time.Time, timezone string) (time.Time, time.Time) {
+	usr := &storages.User{Timezone: timezone}
+	return pg.currentQuotaPolicy().Window(at, usr.Location())
+}
+
+func insertTaskArgs(task *storages.Task) []interface{} {
+	return []interface{}{task.UsrId, task.Content, task.CreateAt, string(task.Status), task.DueAt, task.Priority, task.CompletedAt, task.Tags, task.ProjectId,
+		recurrenceArg(task.Recurrence), task.RecurAt, task.Position, task.RemindAt, string(task.ContentFormat)}
+}
+
+// recurrenceArg gives NULL for tasks which don't recur
+func recurrenceArg(recurrence storages.TaskRecurrence) interface{} {
+	if recurrence == "" {
+		return nil
+	}
+	return string(recurrence)
+}
+
+// prepareInsert fills fields of a new task which are not given by users
+func prepareInsert(task *storages.Task, createAt time.Time) error {
+	task.CreateAt = createAt
+	task.Position = storages.DefaultPosition(createAt)
+	task.Version = 1
+	if err := task.SetStatus(task.Status, createAt); err != nil {
+		return err
+	}
+	if err := task.NormalizeContent(); err != nil {
+		return err
+	}
+	if err := task.InitRecurrence(); err != nil {
+		return err
+	}
+
+	tags, err := storages.NormalizeTags(task.Tags)
 	if err != nil {
-		return errors.Wrap(err, "Exec()")
+		return err
 	}
+	task.Tags = tags
+	task.Checklist = nil
+	task.RemindedAt = nil
+	return nil
+}
 
-	if cmd.RowsAffected() < 1 {
-		return ErrUserMaxTodoReached
+// InsertTask inserts task if the user's daily-limit has not been reached yet
+func (pg *Postgres) InsertTask(ctx context.Context, task *storages.Task) error {
+	return pg.AddTaskWithQuota(ctx, task)
+}
+
+// AddTaskWithQuota inserts task and checks the user's daily-limit in one transaction.
+// The user row is locked first so concurrent inserts of the same user are serialized
+// and the count always sees tasks committed by the others, it returns
+// storages.ErrQuotaExceeded when the limit has been reached
+func (pg *Postgres) AddTaskWithQuota(ctx context.Context, task *storages.Task) error {
+	_, err := pg.InsertTaskQuota(ctx, task)
+	return err
+}
+
+// InsertTaskQuota inserts task like AddTaskWithQuota does and returns the quota of the user counted
+// while the user row is still locked
+func (pg *Postgres) InsertTaskQuota(ctx context.Context, task *storages.Task) (*storages.Quota, error) {
+	if err := prepareInsert(task, time.Now()); err != nil {
+		return nil, err
+	}
+	var quota *storages.Quota
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		var err error
+		quota, err = pg.addTaskWithQuota(ctx, task)
+		return err
+	})
+	return quota, err
+}
+
+func (pg *Postgres) addTaskWithQuota(ctx context.Context, task *storages.Task) (*storages.Quota, error) {
+	tx, err := pg.pool().Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Begin()")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// projects are locked before users like InsertTasks does to avoid deadlocks
+	if err := checkProjects(ctx, tx, []*storages.Task{task}); err != nil {
+		return nil, err
 	}
 
+	locked, err := lockUsrQuotas(ctx, tx, []int{task.UsrId})
+	if err != nil {
+		return nil, err
+	}
+	if locked[task.UsrId] == nil {
+		return nil, storages.ErrNotFound
+	}
+
+	err = tx.QueryRow(ctx, insertTaskWithQuotaStmt, pg.quotaArgs(insertTaskArgs(task), task, locked[task.UsrId])...).Scan(&task.Id)
+	switch err {
+	case nil:
+	case pgx.ErrNoRows:
+		quota, err := pg.getQuota(ctx, tx, task.UsrId, task.CreateAt)
+		if err != nil {
+			return nil, err
+		}
+		return quota, storages.ErrQuotaExceeded
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+
+	quota, err := pg.getQuota(ctx, tx, task.UsrId, task.CreateAt)
+	if err != nil {
+		return nil, err
+	}
+	if quota.Used > storages.TodoLimit(quota.MaxTodo) {
+		state, err := pg.useBurst(ctx, tx, task.UsrId, locked[task.UsrId].burst, 1, task.CreateAt)
+		if err != nil {
+			return nil, err
+		}
+		quota.Burst = pg.currentBurst().Available(state, task.CreateAt)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, mapErr(errors.Wrap(err, "Commit()"))
+	}
+	return quota, nil
+}
+
+// InsertTasks inserts tasks in one transaction, the inserts are sent as a batch once
+// the users are locked so round trips don't grow with the number of tasks.
+// Either all tasks are inserted or none, a storages.BatchError tells which tasks
+// are invalid, have an unknown project or would exceed a daily-limit
+func (pg *Postgres) InsertTasks(ctx context.Context, tasks []*storages.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	createAt := time.Now()
+	batchErr := storages.BatchError{}
+	for i, task := range tasks {
+		if err := prepareInsert(task, createAt); err != nil {
+			batchErr[i] = err
+		}
+	}
+	if len(batchErr) > 0 {
+		return batchErr
+	}
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		return pg.insertTasks(ctx, tasks)
+	})
+}
+
+func (pg *Postgres) insertTasks(ctx context.Context, tasks []*storages.Task) error {
+	tx, err := pg.pool().Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Begin()")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := pg.insertTasksTx(ctx, tx, tasks); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return mapErr(errors.Wrap(err, "Commit()"))
+	}
+	return nil
+}
+
+// insertTasksTx inserts prepared tasks within tx checking projects and daily-limits,
+// it returns a storages.BatchError for the tasks which can't be inserted
+func (pg *Postgres) insertTasksTx(ctx context.Context, tx pgx.Tx, tasks []*storages.Task) error {
+	usrIds := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		usrIds = append(usrIds, task.UsrId)
+	}
+
+	owners, err := lockProjects(ctx, tx, tasks)
+	if err != nil {
+		return err
+	}
+	batchErr := storages.BatchError{}
+	for i, task := range tasks {
+		if !ownsProject(owners, task) {
+			batchErr[i] = storages.ErrNotFound
+		}
+	}
+	if len(batchErr) > 0 {
+		return batchErr
+	}
+
+	locked, err := lockUsrQuotas(ctx, tx, usrIds)
+	if err != nil {
+		return err
+	}
+	batch := &pgx.Batch{}
+	for _, task := range tasks {
+		usr := locked[task.UsrId]
+		if usr == nil {
+			// tasks of unknown users find no limit and aren't inserted
+			usr = &lockedUsr{}
+		}
+		batch.Queue(insertTaskWithQuotaStmt, pg.quotaArgs(insertTaskArgs(task), task, usr)...)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	// every insert is read so all the tasks over a limit are reported
+	for i, task := range tasks {
+		err := results.QueryRow().Scan(&task.Id)
+		switch err {
+		case nil:
+		case pgx.ErrNoRows:
+			batchErr[i] = storages.ErrQuotaExceeded
+		default:
+			_ = results.Close()
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+	}
+	if err := results.Close(); err != nil {
+		return mapErr(errors.Wrap(err, "Close()"))
+	}
+	if len(batchErr) > 0 {
+		return batchErr
+	}
+	return pg.useBursts(ctx, tx, tasks, locked)
+}
+
+// useBursts uses the burst credits of the users of inserted tasks for those of their tasks over their limits
+func (pg *Postgres) useBursts(ctx context.Context, tx pgx.Tx, tasks []*storages.Task, locked map[int]*lockedUsr) error {
+	if pg.currentBurst().Extra <= 0 {
+		return nil
+	}
+	inserted := make(map[int]int, len(locked))
+	for _, task := range tasks {
+		inserted[task.UsrId]++
+	}
+	for usrId, n := range inserted {
+		quota, err := pg.getQuota(ctx, tx, usrId, tasks[0].CreateAt)
+		if err != nil {
+			return err
+		}
+		// tasks of the batch are over the limit once the tasks before them reached it
+		over := quota.Used - storages.TodoLimit(quota.MaxTodo)
+		if over > n {
+			over = n
+		}
+		if over > 0 {
+			if _, err := pg.useBurst(ctx, tx, usrId, locked[usrId].burst, over, tasks[0].CreateAt); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
-func (pg *Postgres) Close() {
-	pg.pool.Close()
+// useBurst saves the burst state of the locked user once n more credits are used at
+func (pg *Postgres) useBurst(ctx context.Context, tx pgx.Tx, usrId int, state storages.BurstState, n int, at time.Time) (storages.BurstState, error) {
+	state = pg.currentBurst().Use(state, n, at)
+	_, err := tx.Exec(ctx, `UPDATE usr SET burst_used = $2, burst_at = $3 WHERE id = $1`, usrId, state.Used, state.At)
+	if err != nil {
+		return state, mapErr(errors.Wrap(err, "Exec()"))
+	}
+	return state, nil
+}
+
+// lockedUsr is the quota state of a user read by lockUsrQuotas, resetAt is the zero time unless their quota was reset
+type lockedUsr struct {
+	timezone string
+	burst    storages.BurstState
+	resetAt  time.Time
+}
+
+// lockUsrQuotas locks the rows of the users and returns their quota states by id
+func lockUsrQuotas(ctx context.Context, tx pgx.Tx, usrIds []int) (map[int]*lockedUsr, error) {
+	rows, err := tx.Query(ctx, lockUsrQuotaStmt, usrIds)
+	if err != nil {
+		return nil, mapErr(errors.Wrap(err, "Query()"))
+	}
+	defer rows.Close()
+
+	locked := make(map[int]*lockedUsr, len(usrIds))
+	for rows.Next() {
+		var id int
+		var burstAt, resetAt *time.Time
+		usr := &lockedUsr{}
+		if err := rows.Scan(&id, &usr.timezone, &usr.burst.Used, &burstAt, &resetAt); err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		if burstAt != nil {
+			usr.burst.At = *burstAt
+		}
+		if resetAt != nil {
+			usr.resetAt = *resetAt
+		}
+		locked[id] = usr
+	}
+	return locked, mapErr(errors.Wrap(rows.Err(), "Err()"))
+}
+
+func (pg *Postgres) Close() error {
+	// the pool is not re-created anymore once the checks stopped
+	if pg.stopHealthCheck != nil {
+		close(pg.stopHealthCheck)
+	}
+	pg.checks.Wait()
+	closeReplicas(pg.replicas)
+	pg.pool().Close()
+	return nil
 }
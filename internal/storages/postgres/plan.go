@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// usrMaxTodo is the daily-limit of the plan of the user of the usr row, or else their own
+const usrMaxTodo = `COALESCE((SELECT plan.max_todo FROM plan WHERE plan.name = usr.plan), usr.max_todo)`
+
+// ListPlans returns the plans by name
+func (pg *Postgres) ListPlans(ctx context.Context) ([]*storages.Plan, error) {
+	var plans []*storages.Plan
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, `SELECT name, max_todo FROM plan ORDER BY name`)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		plans = make([]*storages.Plan, 0)
+		for rows.Next() {
+			plan := &storages.Plan{}
+			if err := rows.Scan(&plan.Name, &plan.MaxTodo); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			plans = append(plans, plan)
+		}
+		return mapErr(errors.Wrap(rows.Err(), "Err()"))
+	})
+	return plans, err
+}
+
+// PutPlan creates the plan or changes its daily-limit
+func (pg *Postgres) PutPlan(ctx context.Context, plan *storages.Plan) error {
+	stmt := `INSERT INTO plan (name, max_todo) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET max_todo = EXCLUDED.max_todo`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if _, err := pg.pool().Exec(ctx, stmt, plan.Name, plan.MaxTodo); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
+
+// DeletePlan deletes the plan of name, the foreign key of usr takes its users off it
+func (pg *Postgres) DeletePlan(ctx context.Context, name string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, `DELETE FROM plan WHERE name = $1`, name)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// SetUserPlan puts the user on the plan of name, "" takes them off their plan. Unknown plans violate the
+// foreign key of usr which is mapped onto ErrNotFound
+func (pg *Postgres) SetUserPlan(ctx context.Context, usrId int, name string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, `UPDATE usr SET plan = NULLIF($2, '') WHERE id = $1`, usrId, name)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
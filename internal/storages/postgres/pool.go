@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"log"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// recreateAfterParam is the connection string param holding how many health checks in a row the pool of
+	// the primary fails before it's re-created, zero disables re-creating it
+	recreateAfterParam = "x-recreate-after"
+
+	defaultRecreateAfter = 3
+
+	poolHealthCheckPeriod = 5 * time.Second
+	// recreateMaxBackoff bounds the wait between failed re-creations of the pool, it doubles from the
+	// health check period after each
+	recreateMaxBackoff = 2 * time.Minute
+	recreateTimeout    = 10 * time.Second
+)
+
+// poolHealth is what the health checks of the pool of the primary found so far
+type poolHealth struct {
+	mu sync.Mutex
+	// err is the error of the last check, failures how many checks in a row failed
+	err         error
+	failures    int
+	recreations int64
+}
+
+// observe records the result of a check and returns how many checks in a row failed
+func (h *poolHealth) observe(err error) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err == nil {
+		if h.failures > 0 {
+			log.Printf("postgres pool healthy again after %d failed checks", h.failures)
+		}
+		h.err, h.failures = nil, 0
+		return 0
+	}
+	if h.failures == 0 {
+		log.Printf("postgres pool unhealthy: %v", err)
+	}
+	h.err = err
+	h.failures++
+	return h.failures
+}
+
+// recreated records that the pool was replaced by a new one which answered
+func (h *poolHealth) recreated() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.err, h.failures = nil, 0
+	h.recreations++
+}
+
+func (h *poolHealth) state() (failures int, recreations int64, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failures, h.recreations, h.err
+}
+
+// pool returns the pool of the primary, it's replaced when it's re-created
+func (pg *Postgres) pool() *pgxpool.Pool {
+	return pg.primary.Load().(*pgxpool.Pool)
+}
+
+// checkPool pings the primary periodically until done is closed. Once the pool failed recreateAfter checks
+// in a row it's re-created from its config, each failed attempt waits for a jittered backoff before the next
+func (pg *Postgres) checkPool(done <-chan struct{}) {
+	wait, backoff := poolHealthCheckPeriod, poolHealthCheckPeriod
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		wait = poolHealthCheckPeriod
+		failures := pg.health.observe(ping(context.Background(), pg.pool()))
+		if failures == 0 {
+			backoff = poolHealthCheckPeriod
+			continue
+		}
+		if pg.recreateAfter <= 0 || failures < pg.recreateAfter {
+			continue
+		}
+
+		if err := pg.recreatePool(context.Background()); err != nil {
+			log.Printf("postgres pool re-creation failed, retrying in about %s: %v", backoff, err)
+			wait = jitter(backoff)
+			if backoff *= 2; backoff > recreateMaxBackoff {
+				backoff = recreateMaxBackoff
+			}
+			continue
+		}
+		log.Printf("postgres pool re-created after %d failed checks", failures)
+		pg.health.recreated()
+		backoff = poolHealthCheckPeriod
+	}
+}
+
+// recreatePool connects a new pool from the config of the primary and replaces the current one if it answers.
+// The old pool is closed in the background as closing waits for the connections in use to be released
+func (pg *Postgres) recreatePool(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, recreateTimeout)
+	defer cancel()
+
+	pool, err := pgxpool.ConnectConfig(ctx, pg.poolConfig.Copy())
+	if err != nil {
+		return errors.Wrap(err, "Connect()")
+	}
+	if err := ping(ctx, pool); err != nil {
+		pool.Close()
+		return errors.Wrap(err, "ping()")
+	}
+
+	old := pg.pool()
+	pg.primary.Store(pool)
+	go old.Close()
+	return nil
+}
+
+// jitter returns a random duration between half of d and one and a half of it, so that instances which lost
+// the database together don't reconnect together
+func jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(rand.Int63n(int64(d)+1))
+}
+
+// parseRecreateParam takes out x-recreate-after param into s
+func parseRecreateParam(params map[string]string, s *settings) error {
+	v, ok := params[recreateAfterParam]
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return errors.Wrap(err, recreateAfterParam)
+	}
+	s.recreateAfter = n
+	delete(params, recreateAfterParam)
+	return nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// renumberPositionsStmt spreads positions of all tasks of the user PositionGap apart keeping their order
+const renumberPositionsStmt = `
+	UPDATE task SET position = r.n * $2
+	FROM (SELECT id, row_number() OVER (ORDER BY position, id) AS n FROM task WHERE usr_id = $1) AS r
+	WHERE task.id = r.id
+	`
+
+// MoveTask places the task between its new neighbors, moves of a user are serialized by locking the user
+func (pg *Postgres) MoveTask(ctx context.Context, usrId, id int, afterId, beforeId *int) error {
+	if (afterId != nil && *afterId == id) || (beforeId != nil && *beforeId == id) {
+		return storages.ErrInvalidTask
+	}
+
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		var locked int
+		switch err := tx.QueryRow(ctx, lockUsrStmt, []int{usrId}).Scan(&locked); err {
+		case nil:
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		lo, hi, err := neighborPositions(ctx, tx, usrId, id, afterId, beforeId)
+		if err != nil {
+			return err
+		}
+		position, ok, err := storages.PositionBetween(lo, hi)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if _, err := tx.Exec(ctx, renumberPositionsStmt, usrId, storages.PositionGap); err != nil {
+				return mapErr(errors.Wrap(err, "Exec()"))
+			}
+			if lo, hi, err = neighborPositions(ctx, tx, usrId, id, afterId, beforeId); err != nil {
+				return err
+			}
+			if position, _, err = storages.PositionBetween(lo, hi); err != nil {
+				return err
+			}
+		}
+
+		stmt := `UPDATE task SET position = $3, version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+		tag, err := tx.Exec(ctx, stmt, id, usrId, position)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+}
+
+// neighborPositions returns positions of the given neighbors of the task, nil neighbors give nil
+func neighborPositions(ctx context.Context, tx pgx.Tx, usrId, id int, afterId, beforeId *int) (*float64, *float64, error) {
+	stmt := `SELECT position FROM task WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+
+	var positions [2]*float64
+	for i, neighborId := range []*int{afterId, beforeId} {
+		if neighborId == nil {
+			continue
+		}
+		var position float64
+		switch err := tx.QueryRow(ctx, stmt, *neighborId, usrId).Scan(&position); err {
+		case nil:
+			positions[i] = &position
+		case pgx.ErrNoRows:
+			return nil, nil, storages.ErrNotFound
+		default:
+			return nil, nil, mapErr(errors.Wrap(err, "Scan()"))
+		}
+	}
+	return positions[0], positions[1], nil
+}
@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// projectColumns are the columns scanned by scanProject
+const projectColumns = `id, usr_id, name, max_todo, create_at`
+
+func scanProject(row pgx.Row, project *storages.Project) error {
+	return row.Scan(
+		&project.Id,
+		&project.UsrId,
+		&project.Name,
+		&project.MaxTodo,
+		&project.CreateAt,
+	)
+}
+
+// CreateProject inserts project, names are unique per user
+func (pg *Postgres) CreateProject(ctx context.Context, project *storages.Project) error {
+	if err := project.Normalize(); err != nil {
+		return err
+	}
+
+	stmt := `INSERT INTO project (usr_id, name, max_todo) VALUES ($1, $2, $3) RETURNING ` + projectColumns
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, project.UsrId, project.Name, project.MaxTodo)
+		if err := scanProject(row, project); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// GetProjects returns projects of the user by name
+func (pg *Postgres) GetProjects(ctx context.Context, usrId int) ([]*storages.Project, error) {
+	stmt := `SELECT ` + projectColumns + ` FROM project WHERE usr_id = $1 ORDER BY name`
+
+	var projects []*storages.Project
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, stmt, usrId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		projects = make([]*storages.Project, 0)
+		for rows.Next() {
+			project := &storages.Project{}
+			if err := scanProject(rows, project); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			projects = append(projects, project)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return projects, nil
+}
+
+// GetProject returns the project of the user
+func (pg *Postgres) GetProject(ctx context.Context, usrId, id int) (*storages.Project, error) {
+	stmt := `SELECT ` + projectColumns + ` FROM project WHERE id = $1 AND usr_id = $2`
+
+	project := &storages.Project{}
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		return scanProject(pool.QueryRow(ctx, stmt, id, usrId), project)
+	})
+
+	switch err {
+	case nil:
+		return project, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// UpdateProject saves name and daily-limit of the project
+func (pg *Postgres) UpdateProject(ctx context.Context, project *storages.Project) error {
+	if err := project.Normalize(); err != nil {
+		return err
+	}
+
+	stmt := `UPDATE project SET name = $3, max_todo = $4 WHERE id = $1 AND usr_id = $2 RETURNING ` + projectColumns
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, project.Id, project.UsrId, project.Name, project.MaxTodo)
+		switch err := scanProject(row, project); err {
+		case nil:
+			return nil
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+	})
+}
+
+// DeleteProject deletes the project of the user, its tasks are kept without a project
+func (pg *Postgres) DeleteProject(ctx context.Context, usrId, id int) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, `DELETE FROM project WHERE id = $1 AND usr_id = $2`, id, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// lockProjectsStmt locks projects of the given users, projects of other users are left out
+const lockProjectsStmt = `SELECT id, usr_id FROM project WHERE id = ANY($1) ORDER BY id FOR UPDATE`
+
+// checkProjects locks projects of tasks and makes sure they belong to the users of the tasks
+func checkProjects(ctx context.Context, tx pgx.Tx, tasks []*storages.Task) error {
+	owners, err := lockProjects(ctx, tx, tasks)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if !ownsProject(owners, task) {
+			return storages.ErrNotFound
+		}
+	}
+	return nil
+}
+
+// lockProjects locks projects of tasks and returns their owners by project id
+func lockProjects(ctx context.Context, tx pgx.Tx, tasks []*storages.Task) (map[int]int, error) {
+	ids := make([]int, 0)
+	for _, task := range tasks {
+		if task.ProjectId != nil {
+			ids = append(ids, *task.ProjectId)
+		}
+	}
+	owners := make(map[int]int)
+	if len(ids) == 0 {
+		return owners, nil
+	}
+
+	rows, err := tx.Query(ctx, lockProjectsStmt, ids)
+	if err != nil {
+		return nil, mapErr(errors.Wrap(err, "Query()"))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, usrId int
+		if err := rows.Scan(&id, &usrId); err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		owners[id] = usrId
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapErr(errors.Wrap(err, "Err()"))
+	}
+	return owners, nil
+}
+
+// ownsProject reports whether task has no project or its project belongs to the task's user
+func ownsProject(owners map[int]int, task *storages.Task) bool {
+	if task.ProjectId == nil {
+		return true
+	}
+	usrId, ok := owners[*task.ProjectId]
+	return ok && usrId == task.UsrId
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// dateLayout is the layout of the days of task_usage
+const dateLayout = "2006-01-02"
+
+// rowQuerier is either the pool or a transaction
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// SetQuotaPolicy changes which tasks count towards the limits of users and of projects
+func (pg *Postgres) SetQuotaPolicy(policy storages.QuotaPolicy) {
+	pg.quotaMu.Lock()
+	defer pg.quotaMu.Unlock()
+	pg.quotaPolicy = policy
+}
+
+// SetBurst lets users add tasks over their limits by burst
+func (pg *Postgres) SetBurst(burst storages.Burst) {
+	pg.quotaMu.Lock()
+	defer pg.quotaMu.Unlock()
+	pg.burst = burst
+}
+
+func (pg *Postgres) currentQuotaPolicy() storages.QuotaPolicy {
+	pg.quotaMu.RLock()
+	defer pg.quotaMu.RUnlock()
+	return pg.quotaPolicy
+}
+
+func (pg *Postgres) currentBurst() storages.Burst {
+	pg.quotaMu.RLock()
+	defer pg.quotaMu.RUnlock()
+	return pg.burst
+}
+
+// ResetQuota stops the tasks the user added before at from counting towards their limit and clears their burst
+func (pg *Postgres) ResetQuota(ctx context.Context, usrId int, at time.Time) (*storages.Quota, error) {
+	var quota *storages.Quota
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		stmt := `UPDATE usr SET quota_reset_at = $2, burst_used = 0, burst_at = NULL WHERE id = $1`
+		tag, err := pg.pool().Exec(ctx, stmt, usrId, at)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		quota, err = pg.getQuota(ctx, pg.pool(), usrId, at)
+		return err
+	})
+	return quota, err
+}
+
+// GetQuota returns the daily-limit of the user and how many tasks they added within the window of at,
+// it reads the primary so the count includes the latest inserts
+func (pg *Postgres) GetQuota(ctx context.Context, usrId int, at time.Time) (*storages.Quota, error) {
+	var quota *storages.Quota
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		quota, err = pg.getQuota(ctx, pg.pool(), usrId, at)
+		return err
+	})
+	return quota, err
+}
+
+func (pg *Postgres) getQuota(ctx context.Context, q rowQuerier, usrId int, at time.Time) (*storages.Quota, error) {
+	quota := &storages.Quota{}
+	var timezone string
+	var burst storages.BurstState
+	var burstAt, resetAt *time.Time
+	stmt := `SELECT ` + usrMaxTodo + `, timezone, burst_used, burst_at, quota_reset_at FROM usr WHERE id = $1`
+	switch err := q.QueryRow(ctx, stmt, usrId).Scan(&quota.MaxTodo, &timezone, &burst.Used, &burstAt, &resetAt); err {
+	case nil:
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+
+	if burstAt != nil {
+		burst.At = *burstAt
+	}
+
+	from, to := pg.quotaWindow(at, timezone)
+	if resetAt != nil {
+		from = storages.CountFrom(from, *resetAt)
+	}
+	stmt = `SELECT count(*) FROM task WHERE usr_id = $1 AND create_at >= $2 AND create_at < $3`
+	if err := q.QueryRow(ctx, stmt, usrId, from, to).Scan(&quota.Used); err != nil {
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+	quota.ResetAt = to
+	quota.Burst = pg.currentBurst().Available(burst, at)
+	return quota, nil
+}
+
+// GetUsage returns the days from from to to on which the user added tasks as counted in task_usage, it may
+// read a replica which lags behind the latest inserts
+func (pg *Postgres) GetUsage(ctx context.Context, usrId int, from, to time.Time) ([]*storages.Usage, error) {
+	var usage []*storages.Usage
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		stmt := `SELECT day, tasks FROM task_usage WHERE usr_id = $1 AND day >= $2::date AND day <= $3::date ORDER BY day`
+		rows, err := pool.Query(ctx, stmt, usrId, from.Format(dateLayout), to.Format(dateLayout))
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		usage = make([]*storages.Usage, 0)
+		for rows.Next() {
+			day := &storages.Usage{}
+			if err := rows.Scan(&day.Day, &day.Tasks); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			usage = append(usage, day)
+		}
+		return mapErr(errors.Wrap(rows.Err(), "Err()"))
+	})
+	return usage, err
+}
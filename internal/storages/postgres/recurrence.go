@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// recurrenceBatchSize is how many recurring tasks are materialized per transaction
+const recurrenceBatchSize = 100
+
+const (
+	// dueRecurrencesStmt locks recurring tasks which are done or whose recur_at has arrived,
+	// waiting instances skip tasks once recur_at has been cleared by the first one
+	dueRecurrencesStmt = `
+		SELECT ` + taskColumns + `
+		FROM 
+		     task
+		WHERE 
+		      recur_at IS NOT NULL
+		      AND deleted_at IS NULL
+		      AND (recur_at <= $1 OR status = 'done')
+		ORDER BY id
+		LIMIT $2
+		FOR UPDATE
+		`
+	insertOccurrenceStmt = `
+		WITH inserted AS (
+			INSERT INTO 
+			    task (usr_id, content, create_at, status, due_at, priority, completed_at, project_id, recurrence, recur_at, position, remind_at, content_format)
+			VALUES 
+			   ($1, $2, $3, $4, $5, $6, $7, $9, $10, $11, $12, $13, $14)
+			RETURNING id
+		), tagged AS (
+			INSERT INTO task_tag (task_id, tag)
+			SELECT id, unnest($8::text[]) FROM inserted
+		)
+		SELECT id FROM inserted
+		`
+)
+
+// MaterializeRecurrences creates next occurrences of due recurring tasks in batches
+func (pg *Postgres) MaterializeRecurrences(ctx context.Context, now time.Time) (int, error) {
+	total := 0
+	for {
+		var n int
+		err := pg.do(ctx, false, func(ctx context.Context) error {
+			var err error
+			n, err = pg.materializeRecurrences(ctx, now)
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < recurrenceBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func (pg *Postgres) materializeRecurrences(ctx context.Context, now time.Time) (int, error) {
+	tx, err := pg.pool().Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "Begin()")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, dueRecurrencesStmt, now, recurrenceBatchSize)
+	if err != nil {
+		return 0, mapErr(errors.Wrap(err, "Query()"))
+	}
+	tasks := make([]*storages.Task, 0)
+	for rows.Next() {
+		task := &storages.Task{}
+		if err := scanTask(rows, task); err != nil {
+			rows.Close()
+			return 0, errors.Wrap(err, "Scan()")
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, mapErr(errors.Wrap(err, "Rows()"))
+	}
+
+	for _, task := range tasks {
+		next := task.NextOccurrence(now)
+		if err := tx.QueryRow(ctx, insertOccurrenceStmt, insertTaskArgs(next)...).Scan(&next.Id); err != nil {
+			return 0, mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		stmt := `UPDATE task SET recur_at = NULL, version = version + 1 WHERE id = $1`
+		if _, err := tx.Exec(ctx, stmt, task.Id); err != nil {
+			return 0, mapErr(errors.Wrap(err, "Exec()"))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, mapErr(errors.Wrap(err, "Commit()"))
+	}
+	return len(tasks), nil
+}
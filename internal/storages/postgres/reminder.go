@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	// claimRemindersStmt leases due reminders which are not leased by another dispatcher,
+	// waiting dispatchers skip the tasks once the first one has leased them
+	claimRemindersStmt = `
+		UPDATE task SET reminder_lease = $2
+		WHERE id IN (
+			SELECT id
+			FROM
+			     task
+			WHERE
+			      remind_at <= $1
+			      AND reminded_at IS NULL
+			      AND deleted_at IS NULL
+			      AND (reminder_lease IS NULL OR reminder_lease <= $1)
+			ORDER BY remind_at, id
+			LIMIT $3
+			FOR UPDATE
+		)
+		RETURNING ` + taskColumns
+	ackReminderStmt = `
+		UPDATE task SET reminded_at = now(), reminder_lease = NULL, version = version + 1
+		WHERE id = $1 AND remind_at = $2 AND reminded_at IS NULL`
+)
+
+// SetTaskRemindAt sets the reminder of the task of the user, a changed reminder is delivered again
+func (pg *Postgres) SetTaskRemindAt(ctx context.Context, usrId, id int, remindAt *time.Time) error {
+	stmt := `
+		UPDATE task SET
+			remind_at = $3,
+			reminded_at = NULL,
+			reminder_lease = NULL,
+			version = version + 1
+		WHERE id = $1 AND usr_id = $2 AND deleted_at IS NULL`
+	return pg.updateTask(ctx, stmt, id, usrId, remindAt)
+}
+
+// ClaimReminders leases up to limit due reminders until now+lease, the earliest first
+func (pg *Postgres) ClaimReminders(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*storages.Task, error) {
+	var tasks []*storages.Task
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, claimRemindersStmt, now, now.Add(lease), limit)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		tasks = make([]*storages.Task, 0)
+		for rows.Next() {
+			task := &storages.Task{}
+			if err := scanTask(rows, task); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			tasks = append(tasks, task)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// AckReminder marks the reminder of the task delivered, a reminder changed in the meantime is kept
+func (pg *Postgres) AckReminder(ctx context.Context, id int, remindAt time.Time) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if _, err := pg.pool().Exec(ctx, ackReminderStmt, id, remindAt); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
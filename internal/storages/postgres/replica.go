@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/pkg/errors"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// replicasParam is the connection string param holding comma separated replica connection strings
+	replicasParam = "x-replicas"
+
+	replicaHealthCheckPeriod  = 5 * time.Second
+	replicaHealthCheckTimeout = 2 * time.Second
+)
+
+// replica is a read-only pool which is skipped while it's unhealthy
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *replica) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	if old := atomic.SwapInt32(&r.healthy, v); old != v {
+		log.Printf("postgres replica %s healthy: %v", r.pool.Config().ConnConfig.Host, healthy)
+	}
+}
+
+// connectReplicas creates lazy pools for replicas which tell statements of their statements, a replica which
+// is down at start is not an error, it's just unhealthy
+func connectReplicas(ctx context.Context, connStrs []string, statements pgx.Logger) ([]*replica, error) {
+	replicas := make([]*replica, 0, len(connStrs))
+	for _, connStr := range connStrs {
+		config, _, err := parseConfig(connStr)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, errors.Wrap(err, "replica")
+		}
+		config.LazyConnect = true
+		config.ConnConfig.Logger = statements
+		config.ConnConfig.LogLevel = pgx.LogLevelInfo
+
+		pool, err := pgxpool.ConnectConfig(ctx, config)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, errors.Wrap(err, "replica Connect()")
+		}
+
+		r := &replica{pool: pool}
+		r.setHealthy(ping(ctx, pool) == nil)
+		replicas = append(replicas, r)
+	}
+	return replicas, nil
+}
+
+func closeReplicas(replicas []*replica) {
+	for _, r := range replicas {
+		r.pool.Close()
+	}
+}
+
+// splitReplicas parses replicas param which is comma separated
+func splitReplicas(param string) []string {
+	replicas := make([]string, 0)
+	for _, connStr := range strings.Split(param, ",") {
+		if connStr = strings.TrimSpace(connStr); connStr != "" {
+			replicas = append(replicas, connStr)
+		}
+	}
+	return replicas
+}
+
+func ping(ctx context.Context, pool *pgxpool.Pool) error {
+	ctx, cancel := context.WithTimeout(ctx, replicaHealthCheckTimeout)
+	defer cancel()
+	_, err := pool.Exec(ctx, "SELECT 1")
+	return err
+}
+
+// checkReplicas pings replicas periodically until done is closed
+func (pg *Postgres) checkReplicas(done <-chan struct{}) {
+	ticker := time.NewTicker(replicaHealthCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			for _, r := range pg.replicas {
+				r.setHealthy(ping(context.Background(), r.pool) == nil)
+			}
+		}
+	}
+}
+
+// nextReplica returns the next healthy replica by round robin, or nil if all are down
+func (pg *Postgres) nextReplica() *replica {
+	n := len(pg.replicas)
+	if n == 0 {
+		return nil
+	}
+
+	start := int(atomic.AddUint32(&pg.nextReplicaIdx, 1))
+	for i := 0; i < n; i++ {
+		if r := pg.replicas[(start+i)%n]; r.isHealthy() {
+			return r
+		}
+	}
+	return nil
+}
+
+// read runs read-only fn on a healthy replica, it fails over to primary when
+// there is no healthy replica or the replica could not be reached.
+// The whole read is retried by the retry policy
+func (pg *Postgres) read(ctx context.Context, fn func(ctx context.Context, pool *pgxpool.Pool) error) error {
+	return pg.do(ctx, true, func(ctx context.Context) error {
+		r := pg.nextReplica()
+		if r == nil {
+			return fn(ctx, pg.pool())
+		}
+
+		err := fn(ctx, r.pool)
+		if err == nil || !isConnErr(ctx, err) {
+			return err
+		}
+
+		r.setHealthy(false)
+		return fn(ctx, pg.pool())
+	})
+}
+
+// isConnErr reports whether err is caused by connection rather than the query
+func isConnErr(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	return !errors.As(err, &pgErr)
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSplitReplicas(t *testing.T) {
+	requireTest := require.New(t)
+
+	requireTest.Empty(splitReplicas(""))
+	requireTest.Empty(splitReplicas(","))
+	requireTest.Equal(
+		[]string{"postgresql://r1:5432/togo", "postgresql://r2:5432/togo"},
+		splitReplicas("postgresql://r1:5432/togo, postgresql://r2:5432/togo"),
+	)
+}
+
+func TestReplicasParam(t *testing.T) {
+	requireTest := require.New(t)
+
+	config := &Config{
+		Host:     "localhost",
+		Port:     "5432",
+		Usr:      "test",
+		Pwd:      "123456",
+		Db:       "test_db",
+		Replicas: []string{"postgresql://test:123456@r1:5432/test_db?sslmode=disable", "postgresql://test:123456@r2:5432/test_db"},
+	}
+
+	poolConfig, _, err := parseConfig(config.ConnString())
+	requireTest.NoError(err)
+	requireTest.Equal(config.Replicas, splitReplicas(poolConfig.ConnConfig.RuntimeParams[replicasParam]))
+}
+
+func TestNextReplicaSkipsUnhealthy(t *testing.T) {
+	requireTest := require.New(t)
+
+	pg := &Postgres{}
+	requireTest.Nil(pg.nextReplica())
+
+	healthy := &replica{healthy: 1}
+	pg.replicas = []*replica{{}, healthy, {}}
+	for i := 0; i < 5; i++ {
+		requireTest.Equal(healthy, pg.nextReplica())
+	}
+
+	healthy.healthy = 0
+	requireTest.Nil(pg.nextReplica())
+}
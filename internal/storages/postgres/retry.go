@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	// opTimeoutParam is the connection string param holding the timeout of each attempt of an operation
+	opTimeoutParam = "x-op-timeout"
+	// maxRetriesParam is the connection string param holding how many times a failed operation is retried
+	maxRetriesParam = "x-max-retries"
+
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// RetryPolicy tells how operations failed with transient errors are retried,
+// the backoff doubles after each retry up to MaxBackoff
+type RetryPolicy struct {
+	// MaxRetries is how many times an operation is retried, zero disables retrying
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var defaultRetryPolicy = RetryPolicy{
+	MaxRetries:     2,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+}
+
+// do runs fn until it succeeds, fails with a non transient error or runs out of retries.
+// Each attempt gets its own deadline when an op timeout is set, readOnly operations
+// are retried on any connection error while writes only when nothing has been sent
+// to server or the transaction was aborted by server
+func (pg *Postgres) do(ctx context.Context, readOnly bool, fn func(ctx context.Context) error) error {
+	backoff := pg.retry.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := pg.attempt(ctx, fn)
+		if err == nil || attempt >= pg.retry.MaxRetries || !isTransient(ctx, err, readOnly) {
+			return err
+		}
+
+		logging.FromContext(ctx).Debug("retrying postgres operation", zap.Int("attempt", attempt+1), zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		if backoff *= 2; backoff > pg.retry.MaxBackoff {
+			backoff = pg.retry.MaxBackoff
+		}
+	}
+}
+
+func (pg *Postgres) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if pg.opTimeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, pg.opTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// isTransient reports whether err of an attempt might not happen again,
+// nothing is transient once the caller's ctx is done
+func isTransient(ctx context.Context, err error, readOnly bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+	return readOnly && isConnErr(ctx, err)
+}
+
+// parseRetryParams takes out op timeout and max retries params into s
+func parseRetryParams(params map[string]string, s *settings) error {
+	if v, ok := params[opTimeoutParam]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return errors.Wrap(err, opTimeoutParam)
+		}
+		s.opTimeout = d
+		delete(params, opTimeoutParam)
+	}
+	if v, ok := params[maxRetriesParam]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.Wrap(err, maxRetriesParam)
+		}
+		s.retry.MaxRetries = n
+		delete(params, maxRetriesParam)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDoRetriesTransientErrors(t *testing.T) {
+	requireTest := require.New(t)
+
+	pg := &Postgres{retry: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}}
+
+	calls := 0
+	err := pg.do(context.Background(), false, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return &pgconn.PgError{Code: serializationFailure}
+		}
+		return nil
+	})
+	requireTest.NoError(err)
+	requireTest.Equal(3, calls)
+
+	calls = 0
+	err = pg.do(context.Background(), false, func(ctx context.Context) error {
+		calls++
+		return &pgconn.PgError{Code: deadlockDetected}
+	})
+	requireTest.Error(err)
+	requireTest.Equal(3, calls)
+}
+
+func TestDoDoesNotRetryOtherErrors(t *testing.T) {
+	requireTest := require.New(t)
+
+	pg := &Postgres{retry: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}}
+
+	for _, readOnly := range []bool{true, false} {
+		calls := 0
+		err := pg.do(context.Background(), readOnly, func(ctx context.Context) error {
+			calls++
+			return pgx.ErrNoRows
+		})
+		requireTest.Equal(pgx.ErrNoRows, err)
+		requireTest.Equal(1, calls)
+	}
+
+	// a write may have reached server
+	calls := 0
+	err := pg.do(context.Background(), false, func(ctx context.Context) error {
+		calls++
+		return errors.New("connection reset by peer")
+	})
+	requireTest.Error(err)
+	requireTest.Equal(1, calls)
+
+	// a read is safe to run again
+	calls = 0
+	err = pg.do(context.Background(), true, func(ctx context.Context) error {
+		calls++
+		return errors.New("connection reset by peer")
+	})
+	requireTest.Error(err)
+	requireTest.Equal(3, calls)
+}
+
+func TestDoOpTimeout(t *testing.T) {
+	requireTest := require.New(t)
+
+	pg := &Postgres{opTimeout: time.Millisecond}
+	err := pg.do(context.Background(), true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	requireTest.Equal(context.DeadlineExceeded, err)
+}
+
+func TestRetryParams(t *testing.T) {
+	requireTest := require.New(t)
+
+	config := &Config{
+		Host:       "localhost",
+		Port:       "5432",
+		Usr:        "test",
+		Pwd:        "123456",
+		Db:         "test_db",
+		OpTimeout:  3 * time.Second,
+		MaxRetries: -1,
+	}
+
+	poolConfig, _, err := parseConfig(config.ConnString())
+	requireTest.NoError(err)
+
+	s := &settings{pool: poolConfig, retry: defaultRetryPolicy}
+	requireTest.NoError(parseRetryParams(poolConfig.ConnConfig.RuntimeParams, s))
+	requireTest.Equal(3*time.Second, s.opTimeout)
+	requireTest.Equal(0, s.retry.MaxRetries)
+	requireTest.NotContains(poolConfig.ConnConfig.RuntimeParams, opTimeoutParam)
+	requireTest.NotContains(poolConfig.ConnConfig.RuntimeParams, maxRetriesParam)
+}
@@ -0,0 +1,1171 @@
+package postgres
+
+import "github.com/manabie-com/togo/internal/migrations"
+
+// migrateParam is the connection string param to turn off migrations on start
+const migrateParam = "x-migrate"
+
+// schemaMigrations returns the schema migrations of the dialect,
+// new migrations must be appended with increasing versions
+func schemaMigrations(dialect Dialect) []migrations.Migration {
+	if dialect == DialectCockroach {
+		return cockroachMigrations
+	}
+	return postgresMigrations
+}
+
+var postgresMigrations = []migrations.Migration{
+	{
+		Version: 1,
+		Name:    "create_usr_task",
+		Up: `
+		CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+		CREATE TABLE IF NOT EXISTS usr (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    username	varchar(36) NOT NULL UNIQUE ,
+		    pwd_hash 	text NOT NULL ,
+		    max_todo 	int NOT NULL DEFAULT 5 CHECK ( max_todo >= 0 )
+		);
+		CREATE TABLE IF NOT EXISTS task (
+		  	id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		  	usr_id 		int NOT NULL REFERENCES usr(id),
+		  	content 	text NOT NULL ,
+		  	create_at	timestamptz NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS usr_username_pwd_hash_idx ON usr(username, pwd_hash);
+		CREATE INDEX IF NOT EXISTS task_usr_id_idx ON task(usr_id);
+		CREATE INDEX IF NOT EXISTS task_usr_id_create_at_idx ON task(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task;
+		DROP TABLE IF EXISTS usr;
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "add_task_deleted_at_archived_at",
+		Up: `
+		ALTER TABLE task
+			ADD COLUMN IF NOT EXISTS deleted_at timestamptz ,
+			ADD COLUMN IF NOT EXISTS archived_at timestamptz ;
+		`,
+		Down: `
+		ALTER TABLE task
+			DROP COLUMN IF EXISTS archived_at ,
+			DROP COLUMN IF EXISTS deleted_at ;
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "add_task_metadata",
+		Up: `
+		ALTER TABLE task
+			ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'todo' CHECK ( status IN ('todo', 'doing', 'done') ) ,
+			ADD COLUMN IF NOT EXISTS due_at timestamptz ,
+			ADD COLUMN IF NOT EXISTS priority int NOT NULL DEFAULT 0 ,
+			ADD COLUMN IF NOT EXISTS completed_at timestamptz ;
+		`,
+		Down: `
+		ALTER TABLE task
+			DROP COLUMN IF EXISTS completed_at ,
+			DROP COLUMN IF EXISTS priority ,
+			DROP COLUMN IF EXISTS due_at ,
+			DROP COLUMN IF EXISTS status ;
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "add_task_version",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1 ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS version ;
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "add_task_overdue_idx",
+		Up: `
+		CREATE INDEX IF NOT EXISTS task_usr_id_due_at_overdue_idx ON task(usr_id, due_at)
+			WHERE deleted_at IS NULL AND status <> 'done' AND due_at IS NOT NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_usr_id_due_at_overdue_idx ;
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "add_task_priority_idx",
+		Up: `
+		CREATE INDEX IF NOT EXISTS task_usr_id_priority_create_at_idx ON task(usr_id, priority DESC, create_at) ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_usr_id_priority_create_at_idx ;
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "create_task_tag",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_tag (
+		    task_id 	int NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    tag 		varchar(32) NOT NULL ,
+		    PRIMARY KEY (task_id, tag)
+		);
+
+		CREATE INDEX IF NOT EXISTS task_tag_tag_idx ON task_tag(tag);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_tag;
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "create_project",
+		Up: `
+		CREATE TABLE IF NOT EXISTS project (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    name 		varchar(64) NOT NULL ,
+		    max_todo 	int CHECK ( max_todo >= 0 ) ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    UNIQUE (usr_id, name)
+		);
+
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS project_id int REFERENCES project(id) ON DELETE SET NULL ;
+		CREATE INDEX IF NOT EXISTS task_project_id_create_at_idx ON task(project_id, create_at) ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS project_id ;
+		DROP TABLE IF EXISTS project;
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "create_checklist_item",
+		Up: `
+		CREATE TABLE IF NOT EXISTS checklist_item (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    task_id 	int NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    content 	text NOT NULL ,
+		    position 	int NOT NULL ,
+		    done 		boolean NOT NULL DEFAULT false ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS checklist_item_task_id_position_idx ON checklist_item(task_id, position);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS checklist_item;
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "add_task_recurrence",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS recurrence text CHECK ( recurrence IN ('daily', 'weekly', 'monthly') ) ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS recur_at timestamptz ;
+		CREATE INDEX IF NOT EXISTS task_recur_at_idx ON task(recur_at) WHERE recur_at IS NOT NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_recur_at_idx ;
+		ALTER TABLE task DROP COLUMN IF EXISTS recur_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS recurrence ;
+		`,
+	},
+	{
+		Version: 11,
+		Name:    "create_task_comment",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_comment (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    task_id 	int NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    content 	text NOT NULL ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_comment_task_id_create_at_idx ON task_comment(task_id, create_at);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_comment;
+		`,
+	},
+	{
+		Version: 12,
+		Name:    "create_task_attachment",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_attachment (
+		    id 				int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    task_id 		int NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    usr_id 			int NOT NULL REFERENCES usr(id) ,
+		    name 			varchar(255) NOT NULL ,
+		    content_type 	text NOT NULL ,
+		    size 			bigint NOT NULL ,
+		    blob_key 		text NOT NULL UNIQUE ,
+		    create_at 		timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_attachment_task_id_idx ON task_attachment(task_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_attachment;
+		`,
+	},
+	{
+		Version: 13,
+		Name:    "add_task_position",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS position double precision ;
+		CREATE INDEX IF NOT EXISTS task_usr_id_position_idx ON task(usr_id, position) ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_usr_id_position_idx ;
+		ALTER TABLE task DROP COLUMN IF EXISTS position ;
+		`,
+	},
+	{
+		// backfilled apart from adding the column to match CockroachDB migrations
+		Version: 14,
+		Name:    "backfill_task_position",
+		Up: `
+		UPDATE task SET position = floor(extract(epoch FROM create_at) * 1000) WHERE position IS NULL ;
+		`,
+		// positions are dropped along with the column by add_task_position
+		Down: `
+		SELECT 1 ;
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "create_task_template",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_template (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    name 		varchar(64) NOT NULL ,
+		    tasks 		jsonb NOT NULL ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_template_usr_id_idx ON task_template(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_template;
+		`,
+	},
+	{
+		Version: 16,
+		Name:    "add_task_reminder",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS remind_at timestamptz ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS reminded_at timestamptz ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS reminder_lease timestamptz ;
+		CREATE INDEX IF NOT EXISTS task_remind_at_idx ON task(remind_at) WHERE remind_at IS NOT NULL AND reminded_at IS NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_remind_at_idx ;
+		ALTER TABLE task DROP COLUMN IF EXISTS reminder_lease ;
+		ALTER TABLE task DROP COLUMN IF EXISTS reminded_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS remind_at ;
+		`,
+	},
+	{
+		Version: 17,
+		Name:    "add_task_deleted_at_index",
+		Up: `
+		CREATE INDEX IF NOT EXISTS task_deleted_at_idx ON task(deleted_at) WHERE deleted_at IS NOT NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_deleted_at_idx ;
+		`,
+	},
+	{
+		Version: 18,
+		Name:    "add_task_content_format",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS content_format text NOT NULL DEFAULT 'plain' CHECK ( content_format IN ('plain', 'markdown') ) ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS content_format ;
+		`,
+	},
+	{
+		Version: 19,
+		Name:    "create_refresh_token",
+		Up: `
+		CREATE TABLE IF NOT EXISTS refresh_token (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    hash 		text NOT NULL UNIQUE ,
+		    family 		text NOT NULL ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    expires_at 	timestamptz NOT NULL ,
+		    rotated_at 	timestamptz ,
+		    revoked_at 	timestamptz
+		);
+
+		CREATE INDEX IF NOT EXISTS refresh_token_family_idx ON refresh_token(family);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS refresh_token;
+		`,
+	},
+	{
+		Version: 20,
+		Name:    "create_password_reset",
+		Up: `
+		CREATE TABLE IF NOT EXISTS password_reset (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    hash 		text NOT NULL UNIQUE ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    expires_at 	timestamptz NOT NULL ,
+		    used_at 	timestamptz
+		);
+
+		CREATE INDEX IF NOT EXISTS password_reset_usr_id_idx ON password_reset(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS password_reset;
+		`,
+	},
+	{
+		Version: 21,
+		Name:    "create_login_failure",
+		Up: `
+		CREATE TABLE IF NOT EXISTS login_failure (
+		    login_key 	text PRIMARY KEY ,
+		    failures 	int NOT NULL ,
+		    last_at 	timestamptz NOT NULL
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS login_failure;
+		`,
+	},
+	{
+		Version: 22,
+		Name:    "create_usr_identity",
+		Up: `
+		CREATE TABLE IF NOT EXISTS usr_identity (
+		    provider 	text NOT NULL ,
+		    subject 	text NOT NULL ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    PRIMARY KEY (provider, subject)
+		);
+
+		CREATE INDEX IF NOT EXISTS usr_identity_usr_id_idx ON usr_identity(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS usr_identity;
+		`,
+	},
+	{
+		Version: 23,
+		Name:    "add_usr_role",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user' CHECK ( role IN ('user', 'admin') );
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS role;
+		`,
+	},
+	{
+		Version: 24,
+		Name:    "add_refresh_token_device",
+		Up: `
+		ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS user_agent text NOT NULL DEFAULT '';
+		ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS ip text NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS refresh_token_usr_id_idx ON refresh_token(usr_id);
+		`,
+		Down: `
+		DROP INDEX IF EXISTS refresh_token_usr_id_idx;
+		ALTER TABLE refresh_token DROP COLUMN IF EXISTS ip;
+		ALTER TABLE refresh_token DROP COLUMN IF EXISTS user_agent;
+		`,
+	},
+	{
+		Version: 25,
+		Name:    "create_two_factor",
+		Up: `
+		CREATE TABLE IF NOT EXISTS two_factor (
+		    usr_id 			int PRIMARY KEY REFERENCES usr(id) ,
+		    secret 			text NOT NULL ,
+		    enabled 		bool NOT NULL DEFAULT false ,
+		    last_counter 	bigint NOT NULL DEFAULT 0 ,
+		    create_at 		timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE TABLE IF NOT EXISTS two_factor_backup_code (
+		    usr_id 	int NOT NULL REFERENCES two_factor(usr_id) ON DELETE CASCADE ,
+		    hash 	text NOT NULL ,
+		    PRIMARY KEY (usr_id, hash)
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS two_factor_backup_code;
+		DROP TABLE IF EXISTS two_factor;
+		`,
+	},
+	{
+		Version: 26,
+		Name:    "create_usr_share",
+		Up: `
+		CREATE TABLE IF NOT EXISTS usr_share (
+		    owner_id 	int NOT NULL REFERENCES usr(id) ,
+		    usr_id 		int NOT NULL REFERENCES usr(id) ,
+		    can_write 	bool NOT NULL DEFAULT false ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    PRIMARY KEY (owner_id, usr_id) ,
+		    CHECK ( owner_id <> usr_id )
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS usr_share;
+		`,
+	},
+	{
+		Version: 27,
+		Name:    "create_audit_event",
+		Up: `
+		CREATE TABLE IF NOT EXISTS audit_event (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    type 		text NOT NULL ,
+		    actor_id 	int ,
+		    usr_id 		int ,
+		    ip 			text NOT NULL DEFAULT '' ,
+		    user_agent 	text NOT NULL DEFAULT '' ,
+		    detail 		text NOT NULL DEFAULT '' ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS audit_event_actor_id_idx ON audit_event(actor_id);
+		CREATE INDEX IF NOT EXISTS audit_event_usr_id_idx ON audit_event(usr_id);
+
+		CREATE OR REPLACE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+		BEGIN
+		    RAISE EXCEPTION 'audit_event is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS audit_event_append_only ON audit_event;
+		CREATE TRIGGER audit_event_append_only BEFORE UPDATE OR DELETE ON audit_event
+		    FOR EACH ROW EXECUTE PROCEDURE audit_event_append_only();
+		`,
+		Down: `
+		DROP TABLE IF EXISTS audit_event;
+		DROP FUNCTION IF EXISTS audit_event_append_only();
+		`,
+	},
+	{
+		Version: 28,
+		Name:    "create_idempotency_key",
+		Up: `
+		CREATE TABLE IF NOT EXISTS idempotency_key (
+		    usr_id 			int NOT NULL REFERENCES usr(id) ,
+		    key 			text NOT NULL ,
+		    request_hash 	text NOT NULL ,
+		    status 			int NOT NULL ,
+		    body 			bytea NOT NULL ,
+		    expires_at 		timestamptz NOT NULL ,
+		    PRIMARY KEY (usr_id, key)
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS idempotency_key;
+		`,
+	},
+	{
+		Version: 29,
+		Name:    "add_task_update_at",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS update_at timestamptz;
+
+		CREATE OR REPLACE FUNCTION task_touch() RETURNS trigger AS $$
+		BEGIN
+		    NEW.update_at = now();
+		    RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS task_touch ON task;
+		CREATE TRIGGER task_touch BEFORE UPDATE ON task
+		    FOR EACH ROW WHEN (NEW.version <> OLD.version) EXECUTE PROCEDURE task_touch();
+		`,
+		Down: `
+		DROP TRIGGER IF EXISTS task_touch ON task;
+		DROP FUNCTION IF EXISTS task_touch();
+		ALTER TABLE task DROP COLUMN IF EXISTS update_at;
+		`,
+	},
+	{
+		Version: 30,
+		Name:    "add_usr_timezone",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS timezone text NOT NULL DEFAULT 'UTC';
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS timezone;
+		`,
+	},
+	{
+		Version: 31,
+		Name:    "create_task_usage",
+		// tasks added before are counted on the days of the current timezones of their users
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_usage (
+		    usr_id 	int NOT NULL REFERENCES usr(id) ,
+		    day 	date NOT NULL ,
+		    tasks 	int NOT NULL ,
+		    PRIMARY KEY (usr_id, day)
+		);
+
+		INSERT INTO task_usage (usr_id, day, tasks)
+		SELECT t.usr_id, (t.create_at AT TIME ZONE u.timezone)::date, count(*)
+		FROM task t JOIN usr u ON u.id = t.usr_id
+		GROUP BY 1, 2
+		ON CONFLICT (usr_id, day) DO NOTHING;
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_usage;
+		`,
+	},
+	{
+		Version: 32,
+		Name:    "add_usr_burst",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS burst_used float8 NOT NULL DEFAULT 0;
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS burst_at timestamptz;
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS burst_at;
+		ALTER TABLE usr DROP COLUMN IF EXISTS burst_used;
+		`,
+	},
+	{
+		Version: 33,
+		Name:    "add_usr_quota_reset_at",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS quota_reset_at timestamptz;
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS quota_reset_at;
+		`,
+	},
+	{
+		Version: 34,
+		Name:    "create_plan",
+		// users are on no plan and keep their own daily-limits until admins put them on one
+		Up: `
+		CREATE TABLE IF NOT EXISTS plan (
+		    name 		text PRIMARY KEY ,
+		    max_todo 	int NOT NULL CHECK ( max_todo >= 0 ) ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+		INSERT INTO plan (name, max_todo) VALUES ('free', 5), ('pro', 50) ON CONFLICT (name) DO NOTHING;
+
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS plan text REFERENCES plan(name) ON DELETE SET NULL;
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS plan;
+		DROP TABLE IF EXISTS plan;
+		`,
+	},
+	{
+		Version: 35,
+		Name:    "create_task_audit",
+		// history outlives tasks purged from the trash, so that task_id references nothing
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_audit (
+		    id 			int GENERATED ALWAYS AS IDENTITY PRIMARY KEY ,
+		    task_id 	int NOT NULL ,
+		    usr_id 		int NOT NULL ,
+		    actor_id 	int NOT NULL ,
+		    action 		text NOT NULL ,
+		    before 		jsonb ,
+		    after 		jsonb ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_audit_task_id_idx ON task_audit(task_id, id);
+
+		CREATE OR REPLACE FUNCTION task_audit_append_only() RETURNS trigger AS $$
+		BEGIN
+		    RAISE EXCEPTION 'task_audit is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS task_audit_append_only ON task_audit;
+		CREATE TRIGGER task_audit_append_only BEFORE UPDATE OR DELETE ON task_audit
+		    FOR EACH ROW EXECUTE PROCEDURE task_audit_append_only();
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_audit;
+		DROP FUNCTION IF EXISTS task_audit_append_only();
+		`,
+	},
+}
+
+var cockroachMigrations = []migrations.Migration{
+	{
+		Version: 1,
+		Name:    "create_usr_task",
+		Up: `
+		CREATE TABLE IF NOT EXISTS usr (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    username	varchar(36) NOT NULL UNIQUE ,
+		    pwd_hash 	text NOT NULL ,
+		    max_todo 	int NOT NULL DEFAULT 5 CHECK ( max_todo >= 0 )
+		);
+		CREATE TABLE IF NOT EXISTS task (
+		  	id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		  	usr_id 		INT8 NOT NULL REFERENCES usr(id),
+		  	content 	text NOT NULL ,
+		  	create_at	timestamptz NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS task_usr_id_create_at_idx ON task(usr_id, create_at);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task;
+		DROP TABLE IF EXISTS usr;
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "add_task_deleted_at_archived_at",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS deleted_at timestamptz ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS archived_at timestamptz ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS archived_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS deleted_at ;
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "add_task_metadata",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'todo' CHECK ( status IN ('todo', 'doing', 'done') ) ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS due_at timestamptz ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS priority int NOT NULL DEFAULT 0 ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS completed_at timestamptz ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS completed_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS priority ;
+		ALTER TABLE task DROP COLUMN IF EXISTS due_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS status ;
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "add_task_version",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS version int NOT NULL DEFAULT 1 ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS version ;
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "add_task_overdue_idx",
+		Up: `
+		CREATE INDEX IF NOT EXISTS task_usr_id_due_at_overdue_idx ON task(usr_id, due_at)
+			WHERE deleted_at IS NULL AND status <> 'done' AND due_at IS NOT NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_usr_id_due_at_overdue_idx ;
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "add_task_priority_idx",
+		Up: `
+		CREATE INDEX IF NOT EXISTS task_usr_id_priority_create_at_idx ON task(usr_id, priority DESC, create_at) ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_usr_id_priority_create_at_idx ;
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "create_task_tag",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_tag (
+		    task_id 	INT8 NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    tag 		varchar(32) NOT NULL ,
+		    PRIMARY KEY (task_id, tag)
+		);
+
+		CREATE INDEX IF NOT EXISTS task_tag_tag_idx ON task_tag(tag);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_tag;
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "create_project",
+		Up: `
+		CREATE TABLE IF NOT EXISTS project (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    name 		varchar(64) NOT NULL ,
+		    max_todo 	int CHECK ( max_todo >= 0 ) ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    UNIQUE (usr_id, name)
+		);
+
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS project_id INT8 REFERENCES project(id) ON DELETE SET NULL ;
+		CREATE INDEX IF NOT EXISTS task_project_id_create_at_idx ON task(project_id, create_at) ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS project_id ;
+		DROP TABLE IF EXISTS project;
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "create_checklist_item",
+		Up: `
+		CREATE TABLE IF NOT EXISTS checklist_item (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    task_id 	INT8 NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    content 	text NOT NULL ,
+		    position 	int NOT NULL ,
+		    done 		boolean NOT NULL DEFAULT false ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS checklist_item_task_id_position_idx ON checklist_item(task_id, position);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS checklist_item;
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "add_task_recurrence",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS recurrence text CHECK ( recurrence IN ('daily', 'weekly', 'monthly') ) ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS recur_at timestamptz ;
+		CREATE INDEX IF NOT EXISTS task_recur_at_idx ON task(recur_at) WHERE recur_at IS NOT NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_recur_at_idx ;
+		ALTER TABLE task DROP COLUMN IF EXISTS recur_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS recurrence ;
+		`,
+	},
+	{
+		Version: 11,
+		Name:    "create_task_comment",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_comment (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    task_id 	INT8 NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    content 	text NOT NULL ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_comment_task_id_create_at_idx ON task_comment(task_id, create_at);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_comment;
+		`,
+	},
+	{
+		Version: 12,
+		Name:    "create_task_attachment",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_attachment (
+		    id 				INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    task_id 		INT8 NOT NULL REFERENCES task(id) ON DELETE CASCADE ,
+		    usr_id 			INT8 NOT NULL REFERENCES usr(id) ,
+		    name 			varchar(255) NOT NULL ,
+		    content_type 	text NOT NULL ,
+		    size 			bigint NOT NULL ,
+		    blob_key 		text NOT NULL UNIQUE ,
+		    create_at 		timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_attachment_task_id_idx ON task_attachment(task_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_attachment;
+		`,
+	},
+	{
+		Version: 13,
+		Name:    "add_task_position",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS position double precision ;
+		CREATE INDEX IF NOT EXISTS task_usr_id_position_idx ON task(usr_id, position) ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_usr_id_position_idx ;
+		ALTER TABLE task DROP COLUMN IF EXISTS position ;
+		`,
+	},
+	{
+		// backfilled apart from adding the column since CockroachDB can't write a column added in the same transaction
+		Version: 14,
+		Name:    "backfill_task_position",
+		Up: `
+		UPDATE task SET position = floor(extract(epoch FROM create_at) * 1000) WHERE position IS NULL ;
+		`,
+		// positions are dropped along with the column by add_task_position
+		Down: `
+		SELECT 1 ;
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "create_task_template",
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_template (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    name 		varchar(64) NOT NULL ,
+		    tasks 		jsonb NOT NULL ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_template_usr_id_idx ON task_template(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_template;
+		`,
+	},
+	{
+		Version: 16,
+		Name:    "add_task_reminder",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS remind_at timestamptz ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS reminded_at timestamptz ;
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS reminder_lease timestamptz ;
+		CREATE INDEX IF NOT EXISTS task_remind_at_idx ON task(remind_at) WHERE remind_at IS NOT NULL AND reminded_at IS NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_remind_at_idx ;
+		ALTER TABLE task DROP COLUMN IF EXISTS reminder_lease ;
+		ALTER TABLE task DROP COLUMN IF EXISTS reminded_at ;
+		ALTER TABLE task DROP COLUMN IF EXISTS remind_at ;
+		`,
+	},
+	{
+		Version: 17,
+		Name:    "add_task_deleted_at_index",
+		Up: `
+		CREATE INDEX IF NOT EXISTS task_deleted_at_idx ON task(deleted_at) WHERE deleted_at IS NOT NULL ;
+		`,
+		Down: `
+		DROP INDEX IF EXISTS task_deleted_at_idx ;
+		`,
+	},
+	{
+		Version: 18,
+		Name:    "add_task_content_format",
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS content_format text NOT NULL DEFAULT 'plain' CHECK ( content_format IN ('plain', 'markdown') ) ;
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS content_format ;
+		`,
+	},
+	{
+		Version: 19,
+		Name:    "create_refresh_token",
+		Up: `
+		CREATE TABLE IF NOT EXISTS refresh_token (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    hash 		text NOT NULL UNIQUE ,
+		    family 		text NOT NULL ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    expires_at 	timestamptz NOT NULL ,
+		    rotated_at 	timestamptz ,
+		    revoked_at 	timestamptz
+		);
+
+		CREATE INDEX IF NOT EXISTS refresh_token_family_idx ON refresh_token(family);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS refresh_token;
+		`,
+	},
+	{
+		Version: 20,
+		Name:    "create_password_reset",
+		Up: `
+		CREATE TABLE IF NOT EXISTS password_reset (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    hash 		text NOT NULL UNIQUE ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    expires_at 	timestamptz NOT NULL ,
+		    used_at 	timestamptz
+		);
+
+		CREATE INDEX IF NOT EXISTS password_reset_usr_id_idx ON password_reset(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS password_reset;
+		`,
+	},
+	{
+		Version: 21,
+		Name:    "create_login_failure",
+		Up: `
+		CREATE TABLE IF NOT EXISTS login_failure (
+		    login_key 	text PRIMARY KEY ,
+		    failures 	int NOT NULL ,
+		    last_at 	timestamptz NOT NULL
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS login_failure;
+		`,
+	},
+	{
+		Version: 22,
+		Name:    "create_usr_identity",
+		Up: `
+		CREATE TABLE IF NOT EXISTS usr_identity (
+		    provider 	text NOT NULL ,
+		    subject 	text NOT NULL ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    PRIMARY KEY (provider, subject)
+		);
+
+		CREATE INDEX IF NOT EXISTS usr_identity_usr_id_idx ON usr_identity(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS usr_identity;
+		`,
+	},
+	{
+		Version: 23,
+		Name:    "add_usr_role",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user' CHECK ( role IN ('user', 'admin') );
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS role;
+		`,
+	},
+	{
+		Version: 24,
+		Name:    "add_refresh_token_device",
+		Up: `
+		ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS user_agent text NOT NULL DEFAULT '';
+		ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS ip text NOT NULL DEFAULT '';
+		CREATE INDEX IF NOT EXISTS refresh_token_usr_id_idx ON refresh_token(usr_id);
+		`,
+		Down: `
+		DROP INDEX IF EXISTS refresh_token_usr_id_idx;
+		ALTER TABLE refresh_token DROP COLUMN IF EXISTS ip;
+		ALTER TABLE refresh_token DROP COLUMN IF EXISTS user_agent;
+		`,
+	},
+	{
+		Version: 25,
+		Name:    "create_two_factor",
+		Up: `
+		CREATE TABLE IF NOT EXISTS two_factor (
+		    usr_id 			INT8 PRIMARY KEY REFERENCES usr(id) ,
+		    secret 			text NOT NULL ,
+		    enabled 		bool NOT NULL DEFAULT false ,
+		    last_counter 	bigint NOT NULL DEFAULT 0 ,
+		    create_at 		timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE TABLE IF NOT EXISTS two_factor_backup_code (
+		    usr_id 	INT8 NOT NULL REFERENCES two_factor(usr_id) ON DELETE CASCADE ,
+		    hash 	text NOT NULL ,
+		    PRIMARY KEY (usr_id, hash)
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS two_factor_backup_code;
+		DROP TABLE IF EXISTS two_factor;
+		`,
+	},
+	{
+		Version: 26,
+		Name:    "create_usr_share",
+		Up: `
+		CREATE TABLE IF NOT EXISTS usr_share (
+		    owner_id 	INT8 NOT NULL REFERENCES usr(id) ,
+		    usr_id 		INT8 NOT NULL REFERENCES usr(id) ,
+		    can_write 	bool NOT NULL DEFAULT false ,
+		    create_at 	timestamptz NOT NULL DEFAULT now() ,
+		    PRIMARY KEY (owner_id, usr_id) ,
+		    CHECK ( owner_id <> usr_id )
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS usr_share;
+		`,
+	},
+	{
+		Version: 27,
+		Name:    "create_audit_event",
+		// CockroachDB has no triggers to reject updates and deletes, audit_event is append-only as the store
+		// never updates or deletes its rows, see the README for revoking them from the user of togo
+		Up: `
+		CREATE TABLE IF NOT EXISTS audit_event (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    type 		text NOT NULL ,
+		    actor_id 	INT8 ,
+		    usr_id 		INT8 ,
+		    ip 			text NOT NULL DEFAULT '' ,
+		    user_agent 	text NOT NULL DEFAULT '' ,
+		    detail 		text NOT NULL DEFAULT '' ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS audit_event_actor_id_idx ON audit_event(actor_id);
+		CREATE INDEX IF NOT EXISTS audit_event_usr_id_idx ON audit_event(usr_id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS audit_event;
+		`,
+	},
+	{
+		Version: 28,
+		Name:    "create_idempotency_key",
+		Up: `
+		CREATE TABLE IF NOT EXISTS idempotency_key (
+		    usr_id 			INT8 NOT NULL REFERENCES usr(id) ,
+		    key 			text NOT NULL ,
+		    request_hash 	text NOT NULL ,
+		    status 			int NOT NULL ,
+		    body 			bytea NOT NULL ,
+		    expires_at 		timestamptz NOT NULL ,
+		    PRIMARY KEY (usr_id, key)
+		);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS idempotency_key;
+		`,
+	},
+	{
+		Version: 29,
+		Name:    "add_task_update_at",
+		// CockroachDB has no triggers, update_at is set by every update of a task rather than by those
+		// which increase its version
+		Up: `
+		ALTER TABLE task ADD COLUMN IF NOT EXISTS update_at timestamptz ON UPDATE now();
+		`,
+		Down: `
+		ALTER TABLE task DROP COLUMN IF EXISTS update_at;
+		`,
+	},
+	{
+		Version: 30,
+		Name:    "add_usr_timezone",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS timezone text NOT NULL DEFAULT 'UTC';
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS timezone;
+		`,
+	},
+	{
+		Version: 31,
+		Name:    "create_task_usage",
+		// tasks added before are counted on the days of the current timezones of their users
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_usage (
+		    usr_id 	INT8 NOT NULL REFERENCES usr(id) ,
+		    day 	date NOT NULL ,
+		    tasks 	int NOT NULL ,
+		    PRIMARY KEY (usr_id, day)
+		);
+
+		INSERT INTO task_usage (usr_id, day, tasks)
+		SELECT t.usr_id, (t.create_at AT TIME ZONE u.timezone)::date, count(*)
+		FROM task t JOIN usr u ON u.id = t.usr_id
+		GROUP BY 1, 2
+		ON CONFLICT (usr_id, day) DO NOTHING;
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_usage;
+		`,
+	},
+	{
+		Version: 32,
+		Name:    "add_usr_burst",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS burst_used float8 NOT NULL DEFAULT 0;
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS burst_at timestamptz;
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS burst_at;
+		ALTER TABLE usr DROP COLUMN IF EXISTS burst_used;
+		`,
+	},
+	{
+		Version: 33,
+		Name:    "add_usr_quota_reset_at",
+		Up: `
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS quota_reset_at timestamptz;
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS quota_reset_at;
+		`,
+	},
+	{
+		Version: 34,
+		Name:    "create_plan",
+		// users are on no plan and keep their own daily-limits until admins put them on one
+		Up: `
+		CREATE TABLE IF NOT EXISTS plan (
+		    name 		text PRIMARY KEY ,
+		    max_todo 	int NOT NULL CHECK ( max_todo >= 0 ) ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+		INSERT INTO plan (name, max_todo) VALUES ('free', 5), ('pro', 50) ON CONFLICT (name) DO NOTHING;
+
+		ALTER TABLE usr ADD COLUMN IF NOT EXISTS plan text REFERENCES plan(name) ON DELETE SET NULL;
+		`,
+		Down: `
+		ALTER TABLE usr DROP COLUMN IF EXISTS plan;
+		DROP TABLE IF EXISTS plan;
+		`,
+	},
+	{
+		Version: 35,
+		Name:    "create_task_audit",
+		// history outlives tasks purged from the trash, so that task_id references nothing. Like audit_event it's
+		// append-only as the store never updates or deletes its rows
+		Up: `
+		CREATE TABLE IF NOT EXISTS task_audit (
+		    id 			INT8 PRIMARY KEY DEFAULT unique_rowid() ,
+		    task_id 	INT8 NOT NULL ,
+		    usr_id 		INT8 NOT NULL ,
+		    actor_id 	INT8 NOT NULL ,
+		    action 		text NOT NULL ,
+		    before 		jsonb ,
+		    after 		jsonb ,
+		    create_at 	timestamptz NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX IF NOT EXISTS task_audit_task_id_idx ON task_audit(task_id, id);
+		`,
+		Down: `
+		DROP TABLE IF EXISTS task_audit;
+		`,
+	},
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// Seed inserts fixtures in one transaction, rows with existing ids or usernames are skipped.
+// Passwords are hashed in Go with argon2id
+func (pg *Postgres) Seed(ctx context.Context, fixtures *storages.Fixtures) error {
+	tx, err := pg.pool().Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Begin()")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	for _, usr := range fixtures.Users {
+		pwdHash, err := password.Hash(usr.Password)
+		if err != nil {
+			return err
+		}
+
+		role := usr.Role
+		if role == "" {
+			role = storages.RoleUser
+		}
+		stmt := `INSERT INTO usr (username, pwd_hash, max_todo, role) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+		args := []interface{}{usr.Username, pwdHash, usr.MaxTodo, role}
+		if usr.Id > 0 {
+			stmt = `INSERT INTO usr (id, username, pwd_hash, max_todo, role) ` + pg.overridingSystemValue() + ` VALUES ($5, $1, $2, $3, $4) ON CONFLICT DO NOTHING`
+			args = append(args, usr.Id)
+		}
+		if _, err := tx.Exec(ctx, stmt, args...); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+	}
+
+	for _, task := range fixtures.Tasks {
+		task := *task
+		if err := task.SetStatus(task.Status, task.CreateAt); err != nil {
+			return err
+		}
+		tags, err := storages.NormalizeTags(task.Tags)
+		if err != nil {
+			return err
+		}
+
+		if task.Position == 0 {
+			task.Position = storages.DefaultPosition(task.CreateAt)
+		}
+
+		stmt := `INSERT INTO task (usr_id, content, create_at, deleted_at, archived_at, status, due_at, priority, completed_at, position) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING RETURNING id`
+		args := []interface{}{task.UsrId, task.Content, task.CreateAt, task.DeletedAt, task.ArchivedAt, string(task.Status), task.DueAt, task.Priority, task.CompletedAt, task.Position}
+		if task.Id > 0 {
+			stmt = `INSERT INTO task (id, usr_id, content, create_at, deleted_at, archived_at, status, due_at, priority, completed_at, position) ` + pg.overridingSystemValue() + ` VALUES ($11, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10) ON CONFLICT DO NOTHING RETURNING id`
+			args = append(args, task.Id)
+		}
+		var id int
+		switch err := tx.QueryRow(ctx, stmt, args...).Scan(&id); err {
+		case nil:
+		case pgx.ErrNoRows:
+			// the task already exists
+			continue
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		stmt = `INSERT INTO task_tag (task_id, tag) SELECT $1, unnest($2::text[]) ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(ctx, stmt, id, tags); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+	}
+
+	if err := pg.syncIdentities(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, "Commit()")
+	}
+	return nil
+}
+
+// overridingSystemValue allows inserting explicit ids into identity columns
+func (pg *Postgres) overridingSystemValue() string {
+	if pg.dialect == DialectCockroach {
+		return ""
+	}
+	return "OVERRIDING SYSTEM VALUE"
+}
+
+// syncIdentities moves identity sequences past explicitly inserted ids so generated
+// ids don't collide with them, CockroachDB's unique_rowid() needs no syncing
+func (pg *Postgres) syncIdentities(ctx context.Context, tx pgx.Tx) error {
+	if pg.dialect == DialectCockroach {
+		return nil
+	}
+
+	for _, table := range []string{"usr", "task"} {
+		stmt := `SELECT setval(pg_get_serial_sequence('` + table + `', 'id'), GREATEST((SELECT max(id) FROM ` + table + `), 1))`
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return errors.Wrap(err, "Exec()")
+		}
+	}
+	return nil
+}
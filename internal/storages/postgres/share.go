@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// GetShare returns the share of the owner with the user
+func (pg *Postgres) GetShare(ctx context.Context, ownerId, usrId int) (*storages.Share, error) {
+	stmt := `SELECT can_write, create_at FROM usr_share WHERE owner_id = $1 AND usr_id = $2`
+	share := &storages.Share{OwnerId: ownerId, UsrId: usrId}
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, ownerId, usrId).Scan(&share.CanWrite, &share.CreateAt)
+	})
+	switch err {
+	case nil:
+		return share, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// ListShares returns the shares of the owner in the order they were created
+func (pg *Postgres) ListShares(ctx context.Context, ownerId int) ([]*storages.Share, error) {
+	stmt := `SELECT usr_id, can_write, create_at FROM usr_share WHERE owner_id = $1 ORDER BY create_at, usr_id`
+	var shares []*storages.Share
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, stmt, ownerId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		shares = make([]*storages.Share, 0)
+		for rows.Next() {
+			share := &storages.Share{OwnerId: ownerId}
+			if err := rows.Scan(&share.UsrId, &share.CanWrite, &share.CreateAt); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			shares = append(shares, share)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// SaveShare creates the share or replaces its access, an unknown user violates the foreign key
+func (pg *Postgres) SaveShare(ctx context.Context, share *storages.Share) error {
+	stmt := `
+		INSERT INTO usr_share (owner_id, usr_id, can_write) VALUES ($1, $2, $3)
+		ON CONFLICT (owner_id, usr_id) DO UPDATE SET can_write = EXCLUDED.can_write
+		RETURNING create_at`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, share.OwnerId, share.UsrId, share.CanWrite)
+		if err := row.Scan(&share.CreateAt); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// DeleteShare deletes the share of the owner with the user
+func (pg *Postgres) DeleteShare(ctx context.Context, ownerId, usrId int) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, `DELETE FROM usr_share WHERE owner_id = $1 AND usr_id = $2`, ownerId, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sync/atomic"
+	"time"
+)
+
+// slowQueryParam is the connection string param holding how long a statement takes at least to be logged as slow
+const slowQueryParam = "x-slow-query"
+
+// statementLogger is told by pgx of every statement the connections of its pools run. It traces them, tells
+// observe of how long they took and logs those which took slowQuery or longer along with a summary of their
+// arguments
+type statementLogger struct {
+	slowQuery time.Duration
+	// observe holds a func(statement string, elapsed time.Duration)
+	observe atomic.Value
+}
+
+// Log records the statement data tells of
+func (l *statementLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	sql, ok := data["sql"].(string)
+	if !ok {
+		return
+	}
+	elapsed, timed := data["time"].(time.Duration)
+	err, _ := data["err"].(error)
+	op, name := statementName(sql)
+	traceStatement(ctx, op, name, sql, elapsed, err)
+	if !timed {
+		return
+	}
+
+	if observe, ok := l.observe.Load().(func(string, time.Duration)); ok {
+		observe(name, elapsed)
+	}
+	if l.slowQuery > 0 && elapsed >= l.slowQuery {
+		args, _ := data["args"].([]interface{})
+		logging.FromContext(ctx).Warn("slow postgres statement",
+			zap.String("statement", name),
+			zap.String("sql", sql),
+			zap.Strings("args", summarizeArgs(args)),
+			zap.Duration("elapsed", elapsed),
+		)
+	}
+}
+
+// summarizeArgs describes bound parameters without telling what users wrote: numbers, booleans and NULL are
+// kept, text such as contents and hashes is told by its length and other values by their type
+func summarizeArgs(args []interface{}) []string {
+	summary := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			summary = append(summary, "NULL")
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			summary = append(summary, fmt.Sprint(v))
+		case string:
+			summary = append(summary, fmt.Sprintf("text(%d)", len(v)))
+		case time.Time:
+			summary = append(summary, "timestamp")
+		default:
+			summary = append(summary, fmt.Sprintf("%T", v))
+		}
+	}
+	return summary
+}
+
+// TimeStatements tells observe how long every statement took, statements are named like their spans
+func (pg *Postgres) TimeStatements(observe func(statement string, elapsed time.Duration)) {
+	pg.statements.observe.Store(observe)
+}
+
+// parseSlowQueryParam takes out x-slow-query param
+func parseSlowQueryParam(params map[string]string, s *settings) error {
+	v, ok := params[slowQueryParam]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return errors.Wrap(err, slowQueryParam)
+	}
+	s.slowQuery = d
+	delete(params, slowQueryParam)
+	return nil
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// GetStats counts the tasks the user created and completed by day in loc, creations and completions are
+// bounded by the instants the days start and end at so that the index of the tasks of users is used
+func (pg *Postgres) GetStats(ctx context.Context, usrId int, from, to time.Time, loc *time.Location) ([]*storages.DayStats, error) {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc)
+	stmt := `
+		SELECT day, sum(created)::INT8, sum(completed)::INT8 FROM (
+			SELECT (create_at AT TIME ZONE $2)::date AS day, 1 AS created, 0 AS completed FROM task
+			WHERE usr_id = $1 AND deleted_at IS NULL AND create_at >= $3 AND create_at < $4
+			UNION ALL
+			SELECT (completed_at AT TIME ZONE $2)::date, 0, 1 FROM task
+			WHERE usr_id = $1 AND deleted_at IS NULL AND completed_at >= $3 AND completed_at < $4
+		) AS t
+		GROUP BY day ORDER BY day`
+
+	var stats []*storages.DayStats
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, stmt, usrId, loc.String(), start, end)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		stats = make([]*storages.DayStats, 0)
+		for rows.Next() {
+			day := &storages.DayStats{}
+			if err := rows.Scan(&day.Day, &day.Created, &day.Completed); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			stats = append(stats, day)
+		}
+		return mapErr(errors.Wrap(rows.Err(), "Err()"))
+	})
+	return stats, err
+}
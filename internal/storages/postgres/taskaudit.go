@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// AppendTaskAudit inserts the entry, the table rejects updates and deletes on Postgres
+func (pg *Postgres) AppendTaskAudit(ctx context.Context, entry *storages.TaskAuditEntry) error {
+	before, err := marshalSnapshot(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalSnapshot(entry.After)
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+		INSERT INTO task_audit (task_id, usr_id, actor_id, action, before, after)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb)
+		RETURNING id, create_at`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, stmt, entry.TaskId, entry.UsrId, entry.ActorId, entry.Action, before, after)
+		if err := row.Scan(&entry.Id, &entry.CreateAt); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// GetTaskHistory returns the entries of the task of the user oldest first
+func (pg *Postgres) GetTaskHistory(ctx context.Context, usrId, taskId int) ([]*storages.TaskAuditEntry, error) {
+	stmt := `
+		SELECT id, task_id, usr_id, actor_id, action, before::text, after::text, create_at FROM task_audit
+		WHERE task_id = $1 AND usr_id = $2
+		ORDER BY create_at, id`
+
+	var entries []*storages.TaskAuditEntry
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, stmt, taskId, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		entries = make([]*storages.TaskAuditEntry, 0)
+		for rows.Next() {
+			entry := &storages.TaskAuditEntry{}
+			var before, after *string
+			err := rows.Scan(&entry.Id, &entry.TaskId, &entry.UsrId, &entry.ActorId, &entry.Action, &before, &after, &entry.CreateAt)
+			if err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			if entry.Before, err = unmarshalSnapshot(before); err != nil {
+				return err
+			}
+			if entry.After, err = unmarshalSnapshot(after); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, storages.ErrNotFound
+	}
+	return entries, nil
+}
+
+// marshalSnapshot returns the JSON of task, nil for no task
+func marshalSnapshot(task *storages.Task) (*string, error) {
+	if task == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(task)
+	if err != nil {
+		return nil, errors.Wrap(err, "Marshal()")
+	}
+	snapshot := string(b)
+	return &snapshot, nil
+}
+
+// unmarshalSnapshot parses the JSON of a task saved by marshalSnapshot
+func unmarshalSnapshot(snapshot *string) (*storages.Task, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	task := &storages.Task{}
+	if err := json.Unmarshal([]byte(*snapshot), task); err != nil {
+		return nil, errors.Wrap(err, "Unmarshal()")
+	}
+	return task, nil
+}
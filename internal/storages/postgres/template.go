@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const templateColumns = `id, usr_id, name, tasks, create_at`
+
+func scanTemplate(row pgx.Row, template *storages.Template) error {
+	var tasks []byte
+	if err := row.Scan(&template.Id, &template.UsrId, &template.Name, &tasks, &template.CreateAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(tasks, &template.Tasks)
+}
+
+// CreateTemplate saves template, the tasks of taskIds are read in the same transaction
+func (pg *Postgres) CreateTemplate(ctx context.Context, template *storages.Template, taskIds []int) error {
+	template.CreateAt = time.Now().UTC()
+	explicit := template.Tasks
+
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		saved, err := templateTasks(ctx, tx, template.UsrId, taskIds)
+		if err != nil {
+			return err
+		}
+		template.Tasks = append(append([]storages.TemplateTask(nil), explicit...), saved...)
+		if err := template.Normalize(); err != nil {
+			return err
+		}
+		tasks, err := json.Marshal(template.Tasks)
+		if err != nil {
+			return errors.Wrap(err, "Marshal()")
+		}
+
+		stmt := `INSERT INTO task_template (usr_id, name, tasks, create_at) VALUES ($1, $2, $3, $4) RETURNING id`
+		row := tx.QueryRow(ctx, stmt, template.UsrId, template.Name, string(tasks), template.CreateAt)
+		if err := row.Scan(&template.Id); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+}
+
+// templateTasks returns the tasks of ids of the user with their checklists in the order of ids
+func templateTasks(ctx context.Context, tx pgx.Tx, usrId int, ids []int) ([]storages.TemplateTask, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	stmt := `SELECT ` + taskColumns + ` FROM task WHERE id = ANY($1) AND usr_id = $2 AND deleted_at IS NULL`
+	rows, err := tx.Query(ctx, stmt, ids, usrId)
+	if err != nil {
+		return nil, mapErr(errors.Wrap(err, "Query()"))
+	}
+	defer rows.Close()
+
+	tasks := make(map[int]*storages.Task)
+	for rows.Next() {
+		task := &storages.Task{}
+		if err := scanTask(rows, task); err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		tasks[task.Id] = task
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapErr(errors.Wrap(err, "Err()"))
+	}
+
+	stmt = `SELECT task_id, content FROM checklist_item WHERE task_id = ANY($1) ORDER BY position, id`
+	rows, err = tx.Query(ctx, stmt, ids)
+	if err != nil {
+		return nil, mapErr(errors.Wrap(err, "Query()"))
+	}
+	defer rows.Close()
+
+	items := make(map[int][]*storages.ChecklistItem)
+	for rows.Next() {
+		item := &storages.ChecklistItem{}
+		if err := rows.Scan(&item.TaskId, &item.Content); err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		items[item.TaskId] = append(items[item.TaskId], item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapErr(errors.Wrap(err, "Err()"))
+	}
+
+	templateTasks := make([]storages.TemplateTask, 0, len(ids))
+	for _, id := range ids {
+		task, ok := tasks[id]
+		if !ok {
+			return nil, storages.ErrNotFound
+		}
+		templateTasks = append(templateTasks, storages.NewTemplateTask(task, items[id]))
+	}
+	return templateTasks, nil
+}
+
+// GetTemplates returns templates of the user, the oldest first
+func (pg *Postgres) GetTemplates(ctx context.Context, usrId int) ([]*storages.Template, error) {
+	stmt := `SELECT ` + templateColumns + ` FROM task_template WHERE usr_id = $1 ORDER BY create_at, id`
+
+	var templates []*storages.Template
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		rows, err := pool.Query(ctx, stmt, usrId)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		templates = make([]*storages.Template, 0)
+		for rows.Next() {
+			template := &storages.Template{}
+			if err := scanTemplate(rows, template); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			templates = append(templates, template)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, mapErr(err)
+	}
+
+	return templates, nil
+}
+
+// GetTemplate returns the template of the user
+func (pg *Postgres) GetTemplate(ctx context.Context, usrId, id int) (*storages.Template, error) {
+	stmt := `SELECT ` + templateColumns + ` FROM task_template WHERE id = $1 AND usr_id = $2`
+
+	template := &storages.Template{}
+	err := pg.read(ctx, func(ctx context.Context, pool *pgxpool.Pool) error {
+		return scanTemplate(pool.QueryRow(ctx, stmt, id, usrId), template)
+	})
+	switch err {
+	case nil:
+		return template, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// DeleteTemplate deletes the template of the user, tasks instantiated from it are kept
+func (pg *Postgres) DeleteTemplate(ctx context.Context, usrId, id int) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, `DELETE FROM task_template WHERE id = $1 AND usr_id = $2`, id, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
+
+const insertTemplateItemsStmt = `
+		INSERT INTO checklist_item (task_id, content, position, create_at)
+		SELECT $1, item.content, item.position, $3 FROM unnest($2::text[]) WITH ORDINALITY AS item(content, position)
+		`
+
+// InstantiateTemplate inserts the tasks of the template with their checklists in one transaction
+func (pg *Postgres) InstantiateTemplate(ctx context.Context, usrId, id int) ([]*storages.Task, error) {
+	var tasks []*storages.Task
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		template := &storages.Template{}
+		row := tx.QueryRow(ctx, `SELECT `+templateColumns+` FROM task_template WHERE id = $1 AND usr_id = $2`, id, usrId)
+		switch err := scanTemplate(row, template); err {
+		case nil:
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		createAt := time.Now()
+		tasks = make([]*storages.Task, 0, len(template.Tasks))
+		for _, templateTask := range template.Tasks {
+			task := templateTask.NewTask(usrId)
+			if err := prepareInsert(task, createAt); err != nil {
+				return err
+			}
+			tasks = append(tasks, task)
+		}
+		if err := pg.insertTasksTx(ctx, tx, tasks); err != nil {
+			if _, ok := err.(storages.BatchError); ok {
+				return storages.ErrQuotaExceeded
+			}
+			return err
+		}
+
+		batch := &pgx.Batch{}
+		for i, task := range tasks {
+			items := template.Tasks[i].Items
+			if len(items) == 0 {
+				continue
+			}
+			batch.Queue(insertTemplateItemsStmt, task.Id, items, task.CreateAt)
+			task.Checklist = &storages.Checklist{Total: len(items)}
+		}
+		if batch.Len() > 0 {
+			if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+				return mapErr(errors.Wrap(err, "Close()"))
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
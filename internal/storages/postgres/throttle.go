@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// addLoginFailureStmt counts a failure in one statement so concurrent failures are all counted,
+// the count starts over if the last failure is older than $3
+const addLoginFailureStmt = `
+	INSERT INTO login_failure (login_key, failures, last_at) VALUES ($1, 1, $2)
+	ON CONFLICT (login_key) DO UPDATE SET
+		failures = CASE WHEN login_failure.last_at > $3 THEN login_failure.failures + 1 ELSE 1 END,
+		last_at = $2
+	RETURNING failures`
+
+// GetLoginFailures returns the failures of key
+func (pg *Postgres) GetLoginFailures(ctx context.Context, key string) (int, time.Time, error) {
+	var failures int
+	var lastAt time.Time
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, `SELECT failures, last_at FROM login_failure WHERE login_key = $1`, key).Scan(&failures, &lastAt)
+	})
+	switch err {
+	case nil:
+		return failures, lastAt, nil
+	case pgx.ErrNoRows:
+		return 0, time.Time{}, nil
+	default:
+		return 0, time.Time{}, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// AddLoginFailure counts a failure of key at now
+func (pg *Postgres) AddLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	var failures int
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		if err := pg.pool().QueryRow(ctx, addLoginFailureStmt, key, now, now.Add(-window)).Scan(&failures); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+// ClearLoginFailures forgets the failures of key
+func (pg *Postgres) ClearLoginFailures(ctx context.Context, key string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if _, err := pg.pool().Exec(ctx, `DELETE FROM login_failure WHERE login_key = $1`, key); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// GetTimezone returns the timezone of the user, "UTC" unless they changed it
+func (pg *Postgres) GetTimezone(ctx context.Context, usrId int) (string, error) {
+	var timezone string
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, `SELECT timezone FROM usr WHERE id = $1`, usrId).Scan(&timezone)
+	})
+	switch err {
+	case nil:
+		return timezone, nil
+	case pgx.ErrNoRows:
+		return "", storages.ErrNotFound
+	default:
+		return "", mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// SetTimezone changes the timezone of the user, the name is checked by the database
+// as it counts the days of the user for their daily-limit
+func (pg *Postgres) SetTimezone(ctx context.Context, usrId int, timezone string) error {
+	stmt := `UPDATE usr SET timezone = $2 WHERE id = $1 AND now() AT TIME ZONE $2 IS NOT NULL`
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, stmt, usrId, timezone)
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == invalidParameterValue:
+			return storages.ErrInvalidTimezone
+		case err != nil:
+			return mapErr(errors.Wrap(err, "Exec()"))
+		case tag.RowsAffected() == 0:
+			return storages.ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	insertRefreshTokenStmt = `
+		INSERT INTO refresh_token (usr_id, hash, family, create_at, expires_at, user_agent, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	revokeFamilyStmt = `UPDATE refresh_token SET revoked_at = now() WHERE family = $1 AND revoked_at IS NULL`
+)
+
+func insertRefreshTokenArgs(token *storages.RefreshToken) []interface{} {
+	return []interface{}{token.UsrId, token.Hash, token.Family, token.CreateAt, token.ExpiresAt, token.UserAgent, token.IP}
+}
+
+// CreateRefreshToken saves the first token of a family
+func (pg *Postgres) CreateRefreshToken(ctx context.Context, token *storages.RefreshToken) error {
+	token.CreateAt = time.Now().UTC()
+	token.Family = token.Hash
+
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		row := pg.pool().QueryRow(ctx, insertRefreshTokenStmt, insertRefreshTokenArgs(token)...)
+		if err := row.Scan(&token.Id); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// RotateRefreshToken trades the token of hash for next, the token row is locked so a token
+// is traded once even if it's presented twice at the same time
+func (pg *Postgres) RotateRefreshToken(ctx context.Context, hash string, next *storages.RefreshToken) (*storages.User, error) {
+	var usr *storages.User
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		var id int
+		var expiresAt time.Time
+		var rotatedAt, revokedAt *time.Time
+		stmt := `SELECT id, usr_id, family, expires_at, rotated_at, revoked_at FROM refresh_token WHERE hash = $1 FOR UPDATE`
+		err = tx.QueryRow(ctx, stmt, hash).Scan(&id, &next.UsrId, &next.Family, &expiresAt, &rotatedAt, &revokedAt)
+		switch {
+		case err == pgx.ErrNoRows:
+			return storages.ErrInvalidCredentials
+		case err != nil:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		case revokedAt != nil:
+			return storages.ErrInvalidCredentials
+		case rotatedAt != nil:
+			// a rotated token is presented again, whoever holds the family can't be trusted
+			if _, err := tx.Exec(ctx, revokeFamilyStmt, next.Family); err != nil {
+				return mapErr(errors.Wrap(err, "Exec()"))
+			}
+			if err := tx.Commit(ctx); err != nil {
+				return mapErr(errors.Wrap(err, "Commit()"))
+			}
+			return storages.ErrInvalidCredentials
+		}
+
+		next.CreateAt = time.Now().UTC()
+		if !expiresAt.After(next.CreateAt) {
+			return storages.ErrInvalidCredentials
+		}
+		if _, err := tx.Exec(ctx, `UPDATE refresh_token SET rotated_at = $2 WHERE id = $1`, id, next.CreateAt); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		row := tx.QueryRow(ctx, insertRefreshTokenStmt, insertRefreshTokenArgs(next)...)
+		if err := row.Scan(&next.Id); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		usr = &storages.User{}
+		row = tx.QueryRow(ctx, `SELECT id, username, `+usrMaxTodo+`, role FROM usr WHERE id = $1`, next.UsrId)
+		if err := row.Scan(&usr.Id, &usr.Username, &usr.MaxTodo, &usr.Role); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usr, nil
+}
+
+// RevokeRefreshTokens revokes the family of the token of hash
+func (pg *Postgres) RevokeRefreshTokens(ctx context.Context, hash string) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		stmt := `UPDATE refresh_token SET revoked_at = now()
+			WHERE family = (SELECT family FROM refresh_token WHERE hash = $1) AND revoked_at IS NULL`
+		if _, err := pg.pool().Exec(ctx, stmt, hash); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+
+		var exists bool
+		if err := pg.pool().QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM refresh_token WHERE hash = $1)`, hash).Scan(&exists); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		if !exists {
+			return storages.ErrInvalidCredentials
+		}
+		return nil
+	})
+}
+
+// ListSessions returns the sessions of the user, the token of a session which has not been rotated is its latest one
+func (pg *Postgres) ListSessions(ctx context.Context, usrId int) ([]*storages.Session, error) {
+	stmt := `
+		SELECT f.id, f.create_at, t.create_at, t.expires_at, t.user_agent, t.ip
+		FROM refresh_token AS t JOIN refresh_token AS f ON f.hash = t.family
+		WHERE t.usr_id = $1 AND t.rotated_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > now()
+		ORDER BY t.create_at DESC, f.id DESC`
+	var sessions []*storages.Session
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, stmt, usrId)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		sessions = make([]*storages.Session, 0)
+		for rows.Next() {
+			session := &storages.Session{}
+			if err := rows.Scan(&session.Id, &session.CreateAt, &session.LastUsedAt, &session.ExpiresAt, &session.UserAgent, &session.IP); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			sessions = append(sessions, session)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes the family whose first token is id
+func (pg *Postgres) RevokeSession(ctx context.Context, usrId, id int) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		var family string
+		err := pg.pool().QueryRow(ctx, `SELECT family FROM refresh_token WHERE id = $1 AND usr_id = $2 AND hash = family`, id, usrId).Scan(&family)
+		switch err {
+		case nil:
+		case pgx.ErrNoRows:
+			return storages.ErrNotFound
+		default:
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+
+		if _, err := pg.pool().Exec(ctx, revokeFamilyStmt, family); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
+
+// RevokeSessions revokes every refresh token of the user
+func (pg *Postgres) RevokeSessions(ctx context.Context, usrId int) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if _, err := pg.pool().Exec(ctx, `UPDATE refresh_token SET revoked_at = now() WHERE usr_id = $1 AND revoked_at IS NULL`, usrId); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
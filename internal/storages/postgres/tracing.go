@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+	"time"
+)
+
+const tracerName = "github.com/manabie-com/togo/internal/storages/postgres"
+
+// traceStatement records a statement which took elapsed as a span of the span of ctx, while its trace is recorded.
+// pgx tells of statements once they're done, so that spans are started back by how long statements took
+func traceStatement(ctx context.Context, op, name, sql string, elapsed time.Duration, err error) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return
+	}
+
+	end := time.Now()
+	_, span := otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithTimestamp(end.Add(-elapsed)),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgres, semconv.DBOperationKey.String(op), semconv.DBStatementKey.String(sql)),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// statementName returns the operation of sql and a name of its span made of the operation and the table it's on,
+// such as SELECT usr. Tables of subqueries are skipped
+func statementName(sql string) (string, string) {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "", "postgres"
+	}
+	op := strings.ToUpper(fields[0])
+
+	var keyword string
+	switch op {
+	case "SELECT", "DELETE":
+		keyword = "FROM"
+	case "INSERT":
+		keyword = "INTO"
+	case "UPDATE":
+		keyword = "UPDATE"
+	default:
+		return op, op
+	}
+
+	depth := 0
+	for i, field := range fields {
+		if depth == 0 && strings.EqualFold(field, keyword) && i+1 < len(fields) {
+			table := fields[i+1]
+			if end := strings.IndexAny(table, "(),;"); end >= 0 {
+				table = table[:end]
+			}
+			if table != "" {
+				return op, op + " " + table
+			}
+		}
+		depth += strings.Count(field, "(") - strings.Count(field, ")")
+	}
+	return op, op
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestStatementName(t *testing.T) {
+	requireTest := require.New(t)
+
+	for sql, name := range map[string]string{
+		"SELECT id FROM usr WHERE id = $1":                    "SELECT usr",
+		"select id,\n\tusername, " + usrMaxTodo + " FROM usr": "SELECT usr",
+		"SELECT count(*) FROM (SELECT id FROM task) t":        "SELECT",
+		"INSERT INTO task(id, content) VALUES ($1, $2)":       "INSERT task",
+		"UPDATE usr SET plan = NULLIF($2, '') WHERE id = $1":  "UPDATE usr",
+		"DELETE FROM task WHERE id = $1":                      "DELETE task",
+		"commit":                                              "COMMIT",
+		"":                                                    "postgres",
+	} {
+		_, got := statementName(sql)
+		requireTest.Equal(name, got, sql)
+	}
+}
+
+func TestTraceStatement(t *testing.T) {
+	requireTest := require.New(t)
+
+	recorder := &oteltest.StandardSpanRecorder{}
+	provider := oteltest.NewTracerProvider(oteltest.WithSpanRecorder(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+
+	// statements out of a trace aren't recorded
+	traceStatement(context.Background(), "SELECT", "SELECT", "SELECT 1", time.Millisecond, nil)
+	requireTest.Empty(recorder.Completed())
+
+	traceStatement(ctx, "SELECT", "SELECT usr", "SELECT id FROM usr", time.Second, nil)
+	traceStatement(ctx, "DELETE", "DELETE task", "DELETE FROM task", 0, errors.New("boom"))
+	parent.End()
+
+	spans := recorder.Completed()
+	requireTest.Len(spans, 3)
+	requireTest.Equal("SELECT usr", spans[0].Name())
+	requireTest.Equal(parent.SpanContext().SpanID, spans[0].ParentSpanID())
+	end, _ := spans[0].EndTime()
+	requireTest.Equal(time.Second, end.Sub(spans[0].StartTime()))
+	requireTest.Equal("SELECT id FROM usr", spans[0].Attributes()[semconv.DBStatementKey].AsString())
+	requireTest.Equal("DELETE task", spans[1].Name())
+	requireTest.Equal(codes.Error, spans[1].StatusCode())
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// expiredTrashStmt locks tasks deleted before $1, waiting instances skip tasks purged by the first one
+const expiredTrashStmt = `SELECT id FROM task WHERE deleted_at < $1 ORDER BY deleted_at, id LIMIT $2 FOR UPDATE`
+
+// GetTrash returns deleted tasks of the user, the latest deleted first
+func (pg *Postgres) GetTrash(ctx context.Context, usrId int) ([]*storages.Task, error) {
+	stmt :=
+		`
+		SELECT 
+			` + taskColumns + `
+		FROM 
+		     task
+		WHERE 
+		      usr_id = $1
+		      AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		`
+	return pg.queryTasks(ctx, stmt, usrId)
+}
+
+// RestoreTask undeletes the deleted task of the user
+func (pg *Postgres) RestoreTask(ctx context.Context, usrId, id int) error {
+	stmt := `UPDATE task SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND usr_id = $2 AND deleted_at IS NOT NULL`
+	return pg.updateTask(ctx, stmt, id, usrId)
+}
+
+// PurgeTrash deletes up to limit tasks deleted before before, rows referencing them are deleted by cascade
+func (pg *Postgres) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, []*storages.Attachment, error) {
+	var n int
+	var attachments []*storages.Attachment
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		var err error
+		n, attachments, err = pg.purgeTrash(ctx, before, limit)
+		return err
+	})
+	return n, attachments, err
+}
+
+func (pg *Postgres) purgeTrash(ctx context.Context, before time.Time, limit int) (int, []*storages.Attachment, error) {
+	tx, err := pg.pool().Begin(ctx)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "Begin()")
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	rows, err := tx.Query(ctx, expiredTrashStmt, before, limit)
+	if err != nil {
+		return 0, nil, mapErr(errors.Wrap(err, "Query()"))
+	}
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, nil, errors.Wrap(err, "Scan()")
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, mapErr(errors.Wrap(err, "Err()"))
+	}
+	if len(ids) == 0 {
+		return 0, []*storages.Attachment{}, nil
+	}
+
+	rows, err = tx.Query(ctx, `SELECT `+attachmentColumns+` FROM task_attachment WHERE task_id = ANY($1)`, ids)
+	if err != nil {
+		return 0, nil, mapErr(errors.Wrap(err, "Query()"))
+	}
+	attachments := make([]*storages.Attachment, 0)
+	for rows.Next() {
+		attachment := &storages.Attachment{}
+		if err := scanAttachment(rows, attachment); err != nil {
+			rows.Close()
+			return 0, nil, errors.Wrap(err, "Scan()")
+		}
+		attachments = append(attachments, attachment)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, mapErr(errors.Wrap(err, "Err()"))
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM task WHERE id = ANY($1)`, ids); err != nil {
+		return 0, nil, mapErr(errors.Wrap(err, "Exec()"))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, mapErr(errors.Wrap(err, "Commit()"))
+	}
+	return len(ids), attachments, nil
+}
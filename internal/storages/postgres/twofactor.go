@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// GetTwoFactor returns the enrollment of the user without its backup codes
+func (pg *Postgres) GetTwoFactor(ctx context.Context, usrId int) (*storages.TwoFactor, error) {
+	stmt := `SELECT secret, enabled, last_counter FROM two_factor WHERE usr_id = $1`
+	tf := &storages.TwoFactor{UsrId: usrId}
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, usrId).Scan(&tf.Secret, &tf.Enabled, &tf.LastCounter)
+	})
+	switch err {
+	case nil:
+		return tf, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
+
+// SaveTwoFactor replaces a pending enrollment of the user and its backup codes in one transaction
+func (pg *Postgres) SaveTwoFactor(ctx context.Context, tf *storages.TwoFactor) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tx, err := pg.pool().Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "Begin()")
+		}
+		defer func() {
+			_ = tx.Rollback(ctx)
+		}()
+
+		if _, err := tx.Exec(ctx, `DELETE FROM two_factor WHERE usr_id = $1 AND NOT enabled`, tf.UsrId); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO two_factor (usr_id, secret) VALUES ($1, $2) ON CONFLICT (usr_id) DO NOTHING`, tf.UsrId, tf.Secret)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return storages.ErrConflict
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO two_factor_backup_code (usr_id, hash) SELECT $1, unnest($2::text[])`, tf.UsrId, tf.BackupCodes); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return mapErr(errors.Wrap(err, "Commit()"))
+		}
+		return nil
+	})
+}
+
+// EnableTwoFactor enables the pending enrollment of the user
+func (pg *Postgres) EnableTwoFactor(ctx context.Context, usrId int, counter int64) error {
+	stmt := `UPDATE two_factor SET enabled = true, last_counter = $2 WHERE usr_id = $1 AND NOT enabled`
+	return pg.execTwoFactor(ctx, storages.ErrNotFound, stmt, usrId, counter)
+}
+
+// UseTOTP records counter as the last period of the user unless a code of it or a later one was accepted
+func (pg *Postgres) UseTOTP(ctx context.Context, usrId int, counter int64) error {
+	stmt := `UPDATE two_factor SET last_counter = $2 WHERE usr_id = $1 AND enabled AND last_counter < $2`
+	return pg.execTwoFactor(ctx, storages.ErrInvalidCredentials, stmt, usrId, counter)
+}
+
+// UseBackupCode deletes the backup code of hash of the user
+func (pg *Postgres) UseBackupCode(ctx context.Context, usrId int, hash string) error {
+	stmt := `DELETE FROM two_factor_backup_code WHERE usr_id = $1 AND hash = $2`
+	return pg.execTwoFactor(ctx, storages.ErrInvalidCredentials, stmt, usrId, hash)
+}
+
+// DisableTwoFactor deletes the enrollment of the user, its backup codes cascade
+func (pg *Postgres) DisableTwoFactor(ctx context.Context, usrId int) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if _, err := pg.pool().Exec(ctx, `DELETE FROM two_factor WHERE usr_id = $1`, usrId); err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		return nil
+	})
+}
+
+// execTwoFactor executes stmt which must change a row, noRows is returned otherwise
+func (pg *Postgres) execTwoFactor(ctx context.Context, noRows error, stmt string, args ...interface{}) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		tag, err := pg.pool().Exec(ctx, stmt, args...)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Exec()"))
+		}
+		if tag.RowsAffected() == 0 {
+			return noRows
+		}
+		return nil
+	})
+}
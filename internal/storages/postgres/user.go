@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+const insertUserStmt = `INSERT INTO usr (username, pwd_hash, max_todo, role) VALUES ($1, $2, $3, $4) RETURNING id`
+
+// insertUserArgs returns the arguments of insertUserStmt, users get RoleUser unless told otherwise
+func insertUserArgs(usr *storages.User) []interface{} {
+	if usr.Role == "" {
+		usr.Role = storages.RoleUser
+	}
+	return []interface{}{usr.Username, usr.PwdHash, usr.MaxTodo, usr.Role}
+}
+
+// CreateUser inserts usr whose password is already hashed, a taken username violates its unique constraint
+func (pg *Postgres) CreateUser(ctx context.Context, usr *storages.User) error {
+	return pg.do(ctx, false, func(ctx context.Context) error {
+		if err := pg.pool().QueryRow(ctx, insertUserStmt, insertUserArgs(usr)...).Scan(&usr.Id); err != nil {
+			return mapErr(errors.Wrap(err, "Scan()"))
+		}
+		return nil
+	})
+}
+
+// ListUsers returns all users by id without their password hashes
+func (pg *Postgres) ListUsers(ctx context.Context) ([]*storages.User, error) {
+	var users []*storages.User
+	err := pg.do(ctx, true, func(ctx context.Context) error {
+		rows, err := pg.pool().Query(ctx, `SELECT id, username, `+usrMaxTodo+`, role, COALESCE(plan, '') FROM usr ORDER BY id`)
+		if err != nil {
+			return mapErr(errors.Wrap(err, "Query()"))
+		}
+		defer rows.Close()
+
+		users = make([]*storages.User, 0)
+		for rows.Next() {
+			usr := &storages.User{}
+			if err := rows.Scan(&usr.Id, &usr.Username, &usr.MaxTodo, &usr.Role, &usr.Plan); err != nil {
+				return errors.Wrap(err, "Scan()")
+			}
+			users = append(users, usr)
+		}
+		if err := rows.Err(); err != nil {
+			return mapErr(errors.Wrap(err, "Err()"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUser sets the fields of patch in one statement
+func (pg *Postgres) UpdateUser(ctx context.Context, id int, patch *storages.UserPatch) (*storages.User, error) {
+	sets := make([]string, 0, 2)
+	args := []interface{}{id}
+	if patch.Role != nil {
+		args = append(args, *patch.Role)
+		sets = append(sets, "role = $"+strconv.Itoa(len(args)))
+	}
+	if patch.MaxTodo != nil {
+		args = append(args, *patch.MaxTodo)
+		sets = append(sets, "max_todo = $"+strconv.Itoa(len(args)))
+	}
+	columns := `id, username, ` + usrMaxTodo + `, role, COALESCE(plan, '')`
+	stmt := `SELECT ` + columns + ` FROM usr WHERE id = $1`
+	if len(sets) > 0 {
+		stmt = `UPDATE usr SET ` + strings.Join(sets, ", ") + ` WHERE id = $1 RETURNING ` + columns
+	}
+
+	usr := &storages.User{}
+	err := pg.do(ctx, false, func(ctx context.Context) error {
+		return pg.pool().QueryRow(ctx, stmt, args...).Scan(&usr.Id, &usr.Username, &usr.MaxTodo, &usr.Role, &usr.Plan)
+	})
+	switch err {
+	case nil:
+		return usr, nil
+	case pgx.ErrNoRows:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, mapErr(errors.Wrap(err, "Scan()"))
+	}
+}
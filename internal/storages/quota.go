@@ -0,0 +1,149 @@
+package storages
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuotaPolicy decides which tasks of a user count towards their limit when they add a task at some time
+type QuotaPolicy interface {
+	// Window returns [from, to) of the tasks counted for a task added at, in the timezone loc of the user.
+	// The whole quota is available again at to
+	Window(at time.Time, loc *time.Location) (time.Time, time.Time)
+}
+
+// QuotaPolicySetter is implemented by storages which can count tasks by policies other than DailyQuota,
+// SetQuotaPolicy may be called while the storage is used, the tasks added from then on are counted by policy
+type QuotaPolicySetter interface {
+	SetQuotaPolicy(policy QuotaPolicy)
+}
+
+// DailyQuota counts the tasks of the calendar day, it's the policy of storages by default
+type DailyQuota struct{}
+
+func (DailyQuota) Window(at time.Time, loc *time.Location) (time.Time, time.Time) {
+	return DayRangeIn(at, loc)
+}
+
+// WeeklyQuota counts the tasks of the calendar week, weeks start on Mondays
+type WeeklyQuota struct{}
+
+func (WeeklyQuota) Window(at time.Time, loc *time.Location) (time.Time, time.Time) {
+	from, _ := DayRangeIn(at, loc)
+	from = from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+	return from, from.AddDate(0, 0, 7)
+}
+
+// RollingQuota counts the tasks added within the last Period whatever the calendar says
+type RollingQuota struct {
+	Period time.Duration
+}
+
+func (q RollingQuota) Window(at time.Time, _ *time.Location) (time.Time, time.Time) {
+	// no task is added after at yet, those counted have all aged out by at+Period
+	return at.Add(-q.Period), at.Add(q.Period)
+}
+
+// ParseQuotaPolicy returns the policy of name: daily, weekly or rolling, which counts the tasks of the last 24h
+// unless a period is given such as rolling:12h
+func ParseQuotaPolicy(name string) (QuotaPolicy, error) {
+	kind, arg := name, ""
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		kind, arg = name[:i], name[i+1:]
+	}
+
+	switch {
+	case kind == "daily" && arg == "":
+		return DailyQuota{}, nil
+	case kind == "weekly" && arg == "":
+		return WeeklyQuota{}, nil
+	case kind == "rolling" && arg == "":
+		return RollingQuota{Period: 24 * time.Hour}, nil
+	case kind == "rolling":
+		period, err := time.ParseDuration(arg)
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("storages: invalid period of quota policy %q", name)
+		}
+		return RollingQuota{Period: period}, nil
+	default:
+		return nil, fmt.Errorf("storages: unknown quota policy %q", name)
+	}
+}
+
+// CountFrom returns from which time tasks count towards the limit of a user within a window starting at from,
+// it's later once their quota was reset at resetAt. resetAt is the zero time if it never was
+func CountFrom(from, resetAt time.Time) time.Time {
+	if resetAt.After(from) {
+		return resetAt
+	}
+	return from
+}
+
+// Burst lets users add up to Extra tasks over their limit once in a while. Every task over the limit uses a
+// credit, used credits come back one per Recovery. Storages disallow any task over the limit by default
+type Burst struct {
+	Extra    int
+	Recovery time.Duration
+}
+
+// BurstSetter is implemented by storages which allow bursts over the limits of users, SetBurst may be called
+// while the storage is used
+type BurstSetter interface {
+	SetBurst(burst Burst)
+}
+
+// BurstState is how many burst credits a user used as of At, before they came back
+type BurstState struct {
+	Used float64
+	At   time.Time
+}
+
+// used returns the credits of state still used at
+func (b Burst) used(state BurstState, at time.Time) float64 {
+	used := state.Used
+	if elapsed := at.Sub(state.At); elapsed > 0 && b.Recovery > 0 {
+		used -= float64(elapsed) / float64(b.Recovery)
+	}
+	return math.Max(used, 0)
+}
+
+// Available returns how many more tasks over the limit a user of state may add at
+func (b Burst) Available(state BurstState, at time.Time) int {
+	if b.Extra <= 0 {
+		return 0
+	}
+	available := b.Extra - int(math.Ceil(b.used(state, at)))
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// Use returns state once n more credits are used at
+func (b Burst) Use(state BurstState, n int, at time.Time) BurstState {
+	return BurstState{Used: b.used(state, at) + float64(n), At: at}
+}
+
+// ParseBurst parses a burst such as 2/24h, 2 tasks over the limit of which one comes back every 24 hours.
+// An empty burst disallows tasks over the limit
+func ParseBurst(s string) (Burst, error) {
+	if s == "" {
+		return Burst{}, nil
+	}
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return Burst{}, fmt.Errorf("storages: burst %q is not extra/recovery", s)
+	}
+	extra, err := strconv.Atoi(s[:i])
+	if err != nil || extra < 0 {
+		return Burst{}, fmt.Errorf("storages: invalid extra tasks of burst %q", s)
+	}
+	recovery, err := time.ParseDuration(s[i+1:])
+	if err != nil || recovery <= 0 {
+		return Burst{}, fmt.Errorf("storages: invalid recovery of burst %q", s)
+	}
+	return Burst{Extra: extra, Recovery: recovery}, nil
+}
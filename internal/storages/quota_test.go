@@ -0,0 +1,82 @@
+package storages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaPolicies(t *testing.T) {
+	requireTest := require.New(t)
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	// Sunday 2020-06-28 23:30 at UTC+7
+	at := time.Date(2020, 6, 28, 16, 30, 0, 0, time.UTC)
+
+	from, to := DailyQuota{}.Window(at, loc)
+	requireTest.Equal(time.Date(2020, 6, 28, 0, 0, 0, 0, loc), from)
+	requireTest.Equal(time.Date(2020, 6, 29, 0, 0, 0, 0, loc), to)
+
+	from, to = WeeklyQuota{}.Window(at, loc)
+	requireTest.Equal(time.Date(2020, 6, 22, 0, 0, 0, 0, loc), from)
+	requireTest.Equal(time.Date(2020, 6, 29, 0, 0, 0, 0, loc), to)
+	from, _ = WeeklyQuota{}.Window(at, time.UTC)
+	requireTest.Equal(time.Date(2020, 6, 22, 0, 0, 0, 0, time.UTC), from)
+	from, _ = WeeklyQuota{}.Window(time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC), time.UTC)
+	requireTest.Equal(time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC), from)
+
+	from, to = RollingQuota{Period: 24 * time.Hour}.Window(at, loc)
+	requireTest.Equal(at.Add(-24*time.Hour), from)
+	requireTest.Equal(at.Add(24*time.Hour), to)
+}
+
+func TestParseQuotaPolicy(t *testing.T) {
+	for name, policy := range map[string]QuotaPolicy{
+		"daily":       DailyQuota{},
+		"weekly":      WeeklyQuota{},
+		"rolling":     RollingQuota{Period: 24 * time.Hour},
+		"rolling:12h": RollingQuota{Period: 12 * time.Hour},
+	} {
+		parsed, err := ParseQuotaPolicy(name)
+		require.NoError(t, err, name)
+		require.Equal(t, policy, parsed, name)
+	}
+	for _, name := range []string{"", "monthly", "daily:1h", "rolling:soon", "rolling:-1h"} {
+		_, err := ParseQuotaPolicy(name)
+		require.Error(t, err, name)
+	}
+}
+
+func TestBurst(t *testing.T) {
+	requireTest := require.New(t)
+	burst := Burst{Extra: 2, Recovery: time.Hour}
+	at := time.Date(2020, 6, 28, 16, 30, 0, 0, time.UTC)
+
+	state := BurstState{}
+	requireTest.Equal(2, burst.Available(state, at))
+	state = burst.Use(state, 2, at)
+	requireTest.Zero(burst.Available(state, at))
+
+	// credits come back one per recovery, partly recovered ones are still used
+	requireTest.Zero(burst.Available(state, at.Add(59*time.Minute)))
+	requireTest.Equal(1, burst.Available(state, at.Add(time.Hour)))
+	requireTest.Equal(2, burst.Available(state, at.Add(5*time.Hour)))
+	state = burst.Use(state, 1, at.Add(90*time.Minute))
+	requireTest.Equal(BurstState{Used: 1.5, At: at.Add(90 * time.Minute)}, state)
+
+	requireTest.Zero(Burst{}.Available(BurstState{}, at))
+}
+
+func TestParseBurst(t *testing.T) {
+	burst, err := ParseBurst("2/24h")
+	require.NoError(t, err)
+	require.Equal(t, Burst{Extra: 2, Recovery: 24 * time.Hour}, burst)
+	burst, err = ParseBurst("")
+	require.NoError(t, err)
+	require.Equal(t, Burst{}, burst)
+
+	for _, s := range []string{"2", "-1/1h", "x/1h", "2/0s", "2/x"} {
+		_, err := ParseBurst(s)
+		require.Error(t, err, s)
+	}
+}
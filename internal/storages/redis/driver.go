@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	storages.Register("redis", func(ctx context.Context, dsn string) (storages.StoreCloser, error) {
+		config, err := configFromURL(dsn)
+		if err != nil {
+			return nil, err
+		}
+		return NewRedis(ctx, config)
+	})
+}
+
+// configFromURL creates config from url in format redis://:password@host:port/db?task_ttl=72h
+func configFromURL(rawURL string) (*Config, error) {
+	if rawURL == "" {
+		rawURL = "redis://localhost:6379/0"
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parse()")
+	}
+	if u.Scheme != "redis" {
+		return nil, errors.Errorf("invalid redis url scheme %q", u.Scheme)
+	}
+
+	config := &Config{Addr: u.Host}
+	if pwd, ok := u.User.Password(); ok {
+		config.Pwd = pwd
+	}
+
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if config.Db, err = strconv.Atoi(db); err != nil {
+			return nil, errors.Wrap(err, "invalid redis db")
+		}
+	}
+
+	if ttl := u.Query().Get("task_ttl"); ttl != "" {
+		if config.TaskTTL, err = time.ParseDuration(ttl); err != nil {
+			return nil, errors.Wrap(err, "invalid task_ttl")
+		}
+	}
+
+	return config, nil
+}
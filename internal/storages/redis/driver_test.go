@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestConfigFromURL(t *testing.T) {
+	requireTest := require.New(t)
+
+	config, err := configFromURL("redis://:secret@localhost:6380/2?task_ttl=72h")
+	requireTest.NoError(err)
+	requireTest.Equal(&Config{Addr: "localhost:6380", Pwd: "secret", Db: 2, TaskTTL: 72 * time.Hour}, config)
+
+	config, err = configFromURL("")
+	requireTest.NoError(err)
+	requireTest.Equal(&Config{Addr: "localhost:6379"}, config)
+
+	_, err = configFromURL("http://localhost:6379")
+	requireTest.Error(err)
+
+	_, err = configFromURL("redis://localhost:6379/x")
+	requireTest.Error(err)
+}
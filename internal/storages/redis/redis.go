@@ -0,0 +1,390 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type Config struct {
+	Addr string
+	Pwd  string
+	Db   int
+	// TaskTTL is how long a day's tasks are kept, zero keeps them forever
+	TaskTTL time.Duration
+}
+
+// insertTaskScript adds a task to the user's daily sorted set if the
+// daily-limit has not been reached yet, a limit of 0 is unlimited. It returns -1 if user does not exist,
+// 0 if limit is reached and 1 on success. The day's tasks are counted by a counter of their own which expires
+// at the end of the day, so a ttl shorter than a day doesn't reset the limit. A day without a counter yet,
+// e.g. one seeded or written before it existed, starts at the size of its sorted set.
+// The ttl of the sorted set is only set when it has none, its tasks expire ttl after the first one.
+// KEYS[1] user hash, KEYS[2] tasks sorted set, KEYS[3] quota counter
+// ARGV[1] score, ARGV[2] member, ARGV[3] ttl in seconds, ARGV[4] unix time of the end of the day in seconds
+var insertTaskScript = redis.NewScript(3, `
+	local max = redis.call('HGET', KEYS[1], 'max_todo')
+	if not max then
+		return -1
+	end
+	local used = redis.call('GET', KEYS[3])
+	if used then
+		used = tonumber(used)
+	else
+		used = redis.call('ZCARD', KEYS[2])
+	end
+	if tonumber(max) > 0 and used >= tonumber(max) then
+		return 0
+	end
+	redis.call('SET', KEYS[3], used + 1)
+	redis.call('EXPIREAT', KEYS[3], ARGV[4])
+	redis.call('ZADD', KEYS[2], ARGV[1], ARGV[2])
+	if tonumber(ARGV[3]) > 0 and redis.call('TTL', KEYS[2]) == -1 then
+		redis.call('EXPIRE', KEYS[2], ARGV[3])
+	end
+	return 1
+`)
+
+// addLoginFailureScript counts a failure of a key, the counter expires once the key
+// hasn't failed for the window so old failures are forgotten.
+// KEYS[1] failures hash, ARGV[1] unix time of the failure in nanoseconds, ARGV[2] window in milliseconds
+var addLoginFailureScript = redis.NewScript(1, `
+	local failures = redis.call('HINCRBY', KEYS[1], 'failures', 1)
+	redis.call('HSET', KEYS[1], 'last_at', ARGV[1])
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return failures
+`)
+
+// Redis represents a database instance for working with Redis,
+// tasks are stored in sorted sets per user per day scored by creation time
+type Redis struct {
+	pool    *redis.Pool
+	taskTTL time.Duration
+}
+
+// NewRedis create new Redis instance
+func NewRedis(ctx context.Context, config *Config) (*Redis, error) {
+	pool := &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 5 * time.Minute,
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialContext(ctx, "tcp", config.Addr,
+				redis.DialPassword(config.Pwd),
+				redis.DialDatabase(config.Db),
+			)
+		},
+	}
+
+	r := &Redis{
+		pool:    pool,
+		taskTTL: config.TaskTTL,
+	}
+
+	if err := r.init(ctx); err != nil {
+		_ = pool.Close()
+		return nil, errors.Wrap(err, "init()")
+	}
+
+	return r, nil
+}
+
+// init checks the server can be reached, demo data is only inserted by Seed
+func (r *Redis) init(ctx context.Context) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	if _, err := conn.Do("PING"); err != nil {
+		return errors.Wrap(err, "PING")
+	}
+	return nil
+}
+
+// ValidateUser returns user if match username AND password
+func (r *Redis) ValidateUser(ctx context.Context, username, pwd string) (*storages.User, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	id, err := redis.Int(conn.Do("GET", usernameKey(username)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
+	default:
+		return nil, errors.Wrap(err, "GET")
+	}
+
+	values, err := redis.Values(conn.Do("HGETALL", usrKey(id)))
+	if err != nil {
+		return nil, errors.Wrap(err, "HGETALL")
+	}
+
+	usr := &struct {
+		Id       int    `redis:"id"`
+		Username string `redis:"username"`
+		PwdHash  string `redis:"pwd_hash"`
+		MaxTodo  int    `redis:"max_todo"`
+	}{}
+	if err := redis.ScanStruct(values, usr); err != nil {
+		return nil, errors.Wrap(err, "ScanStruct()")
+	}
+
+	if !password.Compare(usr.PwdHash, pwd) {
+		return nil, storages.ErrInvalidCredentials
+	}
+
+	validated := &storages.User{Id: usr.Id, Username: usr.Username, PwdHash: usr.PwdHash, MaxTodo: usr.MaxTodo}
+	if password.NeedsRehash(validated.PwdHash) {
+		rehash(ctx, conn, validated, pwd)
+	}
+	return validated, nil
+}
+
+// rehash replaces the legacy hash of usr, a failure only postpones it to the next login
+func rehash(ctx context.Context, conn redis.Conn, usr *storages.User, pwd string) {
+	pwdHash, err := password.Hash(pwd)
+	if err == nil {
+		_, err = conn.Do("HSET", usrKey(usr.Id), "pwd_hash", pwdHash)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("rehashing password", zap.Int("user_id", usr.Id), zap.Error(err))
+		return
+	}
+	usr.PwdHash = pwdHash
+}
+
+// GetTasks returns tasks of the user which were created on the date of createAt
+func (r *Redis) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	members, err := redis.ByteSlices(conn.Do("ZRANGE", tasksKey(usrId, createAt), 0, -1))
+	if err != nil {
+		return nil, errors.Wrap(err, "ZRANGE")
+	}
+
+	tasks := make([]*storages.Task, 0, len(members))
+	for _, member := range members {
+		task := &storages.Task{}
+		if err := json.Unmarshal(member, task); err != nil {
+			return nil, errors.Wrap(err, "Unmarshal()")
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// InsertTask inserts task if the user's daily-limit has not been reached yet
+func (r *Redis) InsertTask(ctx context.Context, task *storages.Task) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	id, err := redis.Int(conn.Do("INCR", taskSeqKey))
+	if err != nil {
+		return errors.Wrap(err, "INCR")
+	}
+
+	task.Id = id
+	task.CreateAt = time.Now().UTC()
+	member, err := json.Marshal(task)
+	if err != nil {
+		return errors.Wrap(err, "Marshal()")
+	}
+
+	res, err := redis.Int(insertTaskScript.Do(conn,
+		usrKey(task.UsrId), tasksKey(task.UsrId, task.CreateAt), quotaKey(task.UsrId, task.CreateAt),
+		task.CreateAt.UnixNano(), member, int(r.taskTTL.Seconds()), endOfDay(task.CreateAt).Unix(),
+	))
+	if err != nil {
+		return errors.Wrap(err, "insertTaskScript")
+	}
+	switch res {
+	case -1:
+		return storages.ErrNotFound
+	case 0:
+		return storages.ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// GetLoginFailures returns the failures of key
+func (r *Redis) GetLoginFailures(ctx context.Context, key string) (int, time.Time, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do("HMGET", loginFailuresKey(key), "failures", "last_at"))
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "HMGET")
+	}
+	var failures int
+	var lastAt int64
+	if _, err := redis.Scan(values, &failures, &lastAt); err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "Scan()")
+	}
+	if failures == 0 {
+		return 0, time.Time{}, nil
+	}
+	return failures, time.Unix(0, lastAt).UTC(), nil
+}
+
+// AddLoginFailure counts a failure of key at now
+func (r *Redis) AddLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	failures, err := redis.Int(addLoginFailureScript.Do(conn, loginFailuresKey(key), now.UnixNano(), window.Milliseconds()))
+	if err != nil {
+		return 0, errors.Wrap(err, "addLoginFailureScript")
+	}
+	return failures, nil
+}
+
+// ClearLoginFailures forgets the failures of key
+func (r *Redis) ClearLoginFailures(ctx context.Context, key string) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	if _, err := conn.Do("DEL", loginFailuresKey(key)); err != nil {
+		return errors.Wrap(err, "DEL")
+	}
+	return nil
+}
+
+// GetIdempotentResponse returns the response saved under key for the user
+func (r *Redis) GetIdempotentResponse(ctx context.Context, usrId int, key string, now time.Time) (*storages.IdempotentResponse, error) {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	value, err := redis.Bytes(conn.Do("GET", idempotencyKey(usrId, key)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return nil, storages.ErrNotFound
+	default:
+		return nil, errors.Wrap(err, "GET")
+	}
+	resp := &storages.IdempotentResponse{}
+	if err := json.Unmarshal(value, resp); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal()")
+	}
+	if !resp.ExpiresAt.After(now) {
+		return nil, storages.ErrNotFound
+	}
+	return resp, nil
+}
+
+// SaveIdempotentResponse saves resp unless its key is taken, the key expires along with resp
+func (r *Redis) SaveIdempotentResponse(ctx context.Context, resp *storages.IdempotentResponse, now time.Time) error {
+	ttl := resp.ExpiresAt.Sub(now).Milliseconds()
+	if ttl <= 0 {
+		return nil
+	}
+	value, err := json.Marshal(resp)
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal()")
+	}
+
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	_, err = redis.String(conn.Do("SET", idempotencyKey(resp.UsrId, resp.Key), value, "PX", ttl, "NX"))
+	switch err {
+	case nil:
+		return nil
+	case redis.ErrNil:
+		return storages.ErrConflict
+	default:
+		return errors.Wrap(err, "SET")
+	}
+}
+
+func (r *Redis) Close() error {
+	return r.pool.Close()
+}
+
+const (
+	usrSeqKey  = "usr:seq"
+	taskSeqKey = "task:seq"
+)
+
+func usrKey(id int) string {
+	return fmt.Sprintf("usr:%d", id)
+}
+
+func usernameKey(username string) string {
+	return "usr:username:" + username
+}
+
+func loginFailuresKey(key string) string {
+	return "login:failures:" + key
+}
+
+func idempotencyKey(usrId int, key string) string {
+	return fmt.Sprintf("idempotency:%d:%s", usrId, key)
+}
+
+func tasksKey(usrId int, createAt time.Time) string {
+	return fmt.Sprintf("task:%d:%s", usrId, createAt.UTC().Format(dateLayout))
+}
+
+func quotaKey(usrId int, createAt time.Time) string {
+	return fmt.Sprintf("quota:%d:%s", usrId, createAt.UTC().Format(dateLayout))
+}
+
+// endOfDay returns the start of the UTC day after t, the day of tasksKey and quotaKey
+func endOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
+
+// CheckHealth pings the server
+func (r *Redis) CheckHealth(ctx context.Context) map[string]error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return map[string]error{"database": errors.Wrap(err, "GetContext()")}
+	}
+	defer conn.Close()
+
+	if _, err := conn.Do("PING"); err != nil {
+		return map[string]error{"database": errors.Wrap(err, "PING")}
+	}
+	return map[string]error{"database": nil}
+}
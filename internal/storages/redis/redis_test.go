@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestRedis(t *testing.T, taskTTL time.Duration) (*Redis, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	r, err := NewRedis(context.Background(), &Config{Addr: mr.Addr(), TaskTTL: taskTTL})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = r.Close()
+	})
+	require.NoError(t, r.Seed(context.Background(), storages.DefaultFixtures()))
+	return r, mr
+}
+
+func TestRedisValidateUser(t *testing.T) {
+	r, _ := newTestRedis(t, 0)
+	requireTest := require.New(t)
+
+	usr, err := r.ValidateUser(context.Background(), "firstUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(1, usr.Id)
+	requireTest.Equal(5, usr.MaxTodo)
+
+	_, err = r.ValidateUser(context.Background(), "firstUser", "wrong")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	_, err = r.ValidateUser(context.Background(), "nobody", "example")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+}
+
+func TestRedisGetTasks(t *testing.T) {
+	r, _ := newTestRedis(t, 0)
+	requireTest := require.New(t)
+
+	tasks, err := r.GetTasks(context.Background(), 1, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("test 1", tasks[0].Content)
+
+	tasks, err = r.GetTasks(context.Background(), 1, time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+}
+
+func TestRedisInsertTaskDailyLimit(t *testing.T) {
+	r, mr := newTestRedis(t, 0)
+	requireTest := require.New(t)
+	ctx := context.Background()
+
+	var task *storages.Task
+	for i := 0; i < 5; i++ {
+		task = &storages.Task{UsrId: 1, Content: "content"}
+		requireTest.NoError(r.InsertTask(ctx, task))
+		requireTest.True(task.Id > 1, "ids follow the seeded task")
+	}
+	requireTest.Equal(storages.ErrQuotaExceeded, r.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "content"}))
+	requireTest.Equal(storages.ErrNotFound, r.InsertTask(ctx, &storages.Task{UsrId: 99, Content: "content"}))
+
+	// the counter of the day expires when the day ends, tasks are kept forever without a ttl
+	counter, err := mr.Get(quotaKey(1, task.CreateAt))
+	requireTest.NoError(err)
+	requireTest.Equal("5", counter)
+	requireTest.InDelta(time.Until(endOfDay(task.CreateAt)).Seconds(), mr.TTL(quotaKey(1, task.CreateAt)).Seconds(), 5)
+	requireTest.Zero(mr.TTL(tasksKey(1, task.CreateAt)))
+
+	tasks, err := r.GetTasks(ctx, 1, task.CreateAt)
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 5)
+}
+
+func TestRedisInsertTaskTTL(t *testing.T) {
+	r, mr := newTestRedis(t, time.Minute)
+	requireTest := require.New(t)
+	ctx := context.Background()
+
+	task := &storages.Task{UsrId: 1, Content: "content"}
+	requireTest.NoError(r.InsertTask(ctx, task))
+	requireTest.Equal(time.Minute, mr.TTL(tasksKey(1, task.CreateAt)))
+
+	// later tasks don't push the expiry back
+	mr.FastForward(30 * time.Second)
+	for i := 0; i < 4; i++ {
+		requireTest.NoError(r.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "content"}))
+	}
+	requireTest.Equal(30*time.Second, mr.TTL(tasksKey(1, task.CreateAt)))
+
+	// the tasks expire but the limit of the day still holds
+	mr.FastForward(31 * time.Second)
+	tasks, err := r.GetTasks(ctx, 1, task.CreateAt)
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+	requireTest.Equal(storages.ErrQuotaExceeded, r.InsertTask(ctx, &storages.Task{UsrId: 1, Content: "content"}))
+}
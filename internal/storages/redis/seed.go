@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gomodule/redigo/redis"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/pkg/errors"
+)
+
+// seedUserScript adds a user unless its username or id is taken and moves the user sequence past its id.
+// KEYS[1] username key, KEYS[2] user hash, KEYS[3] user sequence
+// ARGV[1] id, ARGV[2] username, ARGV[3] pwd_hash, ARGV[4] max_todo
+var seedUserScript = redis.NewScript(3, `
+	if redis.call('EXISTS', KEYS[1]) == 1 or redis.call('EXISTS', KEYS[2]) == 1 then
+		return 0
+	end
+	redis.call('SET', KEYS[1], ARGV[1])
+	redis.call('HSET', KEYS[2], 'id', ARGV[1], 'username', ARGV[2], 'pwd_hash', ARGV[3], 'max_todo', ARGV[4])
+	if tonumber(redis.call('GET', KEYS[3]) or 0) < tonumber(ARGV[1]) then
+		redis.call('SET', KEYS[3], ARGV[1])
+	end
+	return 1
+`)
+
+// seedTaskScript adds a task to the user's daily sorted set and moves the task sequence past its id.
+// KEYS[1] tasks sorted set, KEYS[2] task sequence
+// ARGV[1] score, ARGV[2] member, ARGV[3] id
+var seedTaskScript = redis.NewScript(2, `
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+	if tonumber(redis.call('GET', KEYS[2]) or 0) < tonumber(ARGV[3]) then
+		redis.call('SET', KEYS[2], ARGV[3])
+	end
+	return 1
+`)
+
+// Seed inserts fixtures, users with existing ids or usernames are skipped and tasks with ids are members of
+// sorted sets so seeding one twice adds it once. Redis has no roles, tags or statuses so those are left out
+func (r *Redis) Seed(ctx context.Context, fixtures *storages.Fixtures) error {
+	conn, err := r.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "GetContext()")
+	}
+	defer conn.Close()
+
+	for _, usr := range fixtures.Users {
+		pwdHash, err := password.Hash(usr.Password)
+		if err != nil {
+			return err
+		}
+
+		id := usr.Id
+		if id <= 0 {
+			if id, err = redis.Int(conn.Do("INCR", usrSeqKey)); err != nil {
+				return errors.Wrap(err, "INCR")
+			}
+		}
+		_, err = seedUserScript.Do(conn, usernameKey(usr.Username), usrKey(id), usrSeqKey, id, usr.Username, pwdHash, usr.MaxTodo)
+		if err != nil {
+			return errors.Wrap(err, "seedUserScript")
+		}
+	}
+
+	for _, fixture := range fixtures.Tasks {
+		task := &storages.Task{Id: fixture.Id, UsrId: fixture.UsrId, Content: fixture.Content, CreateAt: fixture.CreateAt.UTC()}
+		if task.Id <= 0 {
+			if task.Id, err = redis.Int(conn.Do("INCR", taskSeqKey)); err != nil {
+				return errors.Wrap(err, "INCR")
+			}
+		}
+		member, err := json.Marshal(task)
+		if err != nil {
+			return errors.Wrap(err, "Marshal()")
+		}
+		_, err = seedTaskScript.Do(conn, tasksKey(task.UsrId, task.CreateAt), taskSeqKey, task.CreateAt.UnixNano(), member, task.Id)
+		if err != nil {
+			return errors.Wrap(err, "seedTaskScript")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package sqllite
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+func init() {
+	storages.Register("sqlite", func(ctx context.Context, dsn string) (storages.StoreCloser, error) {
+		if dsn == "" {
+			dsn = "./togo.db"
+		}
+		return NewSqlite(ctx, dsn)
+	})
+}
@@ -0,0 +1,210 @@
+package sqllite
+
+import (
+	"context"
+	"database/sql"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/password"
+	"github.com/manabie-com/togo/internal/storages"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Sqlite represents a database instance for working with SQLite,
+// it has the same behaviours as Postgres
+type Sqlite struct {
+	db *sql.DB
+	// policyMu guards policy as it changes on reload
+	policyMu sync.RWMutex
+	policy   storages.QuotaPolicy
+}
+
+// NewSqlite create new Sqlite instance from the given database file path,
+// use ":memory:" for an in-memory database
+func NewSqlite(ctx context.Context, path string) (*Sqlite, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Open()")
+	}
+	// SQLite only allows one writer, and every connection of an in-memory
+	// database is a different database. The single connection also runs the
+	// count and the insert of InsertTask without any other insert in between
+	db.SetMaxOpenConns(1)
+
+	s := &Sqlite{
+		db:     db,
+		policy: storages.DailyQuota{},
+	}
+
+	if err := s.init(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "init()")
+	}
+
+	return s, nil
+}
+
+// init initializes tables, indexes, ..., demo data is only inserted by Seed
+func (s *Sqlite) init(ctx context.Context) error {
+	stmt :=
+		`
+		CREATE TABLE IF NOT EXISTS usr (
+			id 			INTEGER PRIMARY KEY AUTOINCREMENT ,
+			username	TEXT NOT NULL UNIQUE ,
+			pwd_hash 	TEXT NOT NULL ,
+			max_todo 	INTEGER NOT NULL DEFAULT 5 CHECK ( max_todo >= 0 )
+		);
+		CREATE TABLE IF NOT EXISTS task (
+			id 			INTEGER PRIMARY KEY AUTOINCREMENT ,
+			usr_id 		INTEGER NOT NULL REFERENCES usr(id),
+			content 	TEXT NOT NULL ,
+			create_at	TIMESTAMP NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS task_usr_id_create_at_idx ON task(usr_id, create_at);
+		`
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return errors.Wrap(err, "ExecContext()")
+	}
+
+	return nil
+}
+
+// ValidateUser returns user if match username AND password
+func (s *Sqlite) ValidateUser(ctx context.Context, username, pwd string) (*storages.User, error) {
+	stmt := `SELECT id, username, pwd_hash, max_todo FROM usr WHERE username = ?`
+	row := s.db.QueryRowContext(ctx, stmt, username)
+
+	usr := &storages.User{}
+	err := row.Scan(&usr.Id, &usr.Username, &usr.PwdHash, &usr.MaxTodo)
+
+	switch err {
+	case nil:
+		if !password.Compare(usr.PwdHash, pwd) {
+			return nil, storages.ErrInvalidCredentials
+		}
+		if password.NeedsRehash(usr.PwdHash) {
+			s.rehash(ctx, usr, pwd)
+		}
+		return usr, nil
+	case sql.ErrNoRows:
+		password.CompareMissing(pwd)
+		return nil, storages.ErrInvalidCredentials
+	default:
+		return nil, errors.Wrap(err, "Scan()")
+	}
+}
+
+// rehash replaces the legacy hash of usr, a failure only postpones it to the next login
+func (s *Sqlite) rehash(ctx context.Context, usr *storages.User, pwd string) {
+	pwdHash, err := password.Hash(pwd)
+	if err == nil {
+		_, err = s.db.ExecContext(ctx, `UPDATE usr SET pwd_hash = ? WHERE id = ? AND pwd_hash = ?`, pwdHash, usr.Id, usr.PwdHash)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("rehashing password", zap.Int("user_id", usr.Id), zap.Error(err))
+		return
+	}
+	usr.PwdHash = pwdHash
+}
+
+// GetTasks returns tasks of the user which were created on the date of createAt
+func (s *Sqlite) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*storages.Task, error) {
+	stmt :=
+		`
+		SELECT id, usr_id, content, create_at
+		FROM task
+		WHERE usr_id = ? AND create_at >= ? AND create_at < ?
+		`
+	from, to := storages.DayRange(createAt)
+	rows, err := s.db.QueryContext(ctx, stmt, usrId, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "QueryContext()")
+	}
+	defer rows.Close()
+
+	tasks := make([]*storages.Task, 0)
+	for rows.Next() {
+		task := &storages.Task{}
+		err := rows.Scan(
+			&task.Id,
+			&task.UsrId,
+			&task.Content,
+			&task.CreateAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "Scan()")
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "Err()")
+	}
+
+	return tasks, nil
+}
+
+// SetQuotaPolicy changes which tasks count towards the limits of users, days are UTC ones
+func (s *Sqlite) SetQuotaPolicy(policy storages.QuotaPolicy) {
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	s.policy = policy
+}
+
+func (s *Sqlite) currentPolicy() storages.QuotaPolicy {
+	s.policyMu.RLock()
+	defer s.policyMu.RUnlock()
+	return s.policy
+}
+
+// InsertTask inserts task if the user's daily-limit has not been reached yet
+func (s *Sqlite) InsertTask(ctx context.Context, task *storages.Task) error {
+	task.CreateAt = time.Now().UTC()
+	stmt :=
+		`
+		INSERT INTO task (usr_id, content, create_at)
+		SELECT ?1, ?2, ?3
+		WHERE
+			(
+				SELECT count(*) FROM task
+				WHERE usr_id = ?1 AND create_at >= ?4 AND create_at < ?5
+			) < (SELECT COALESCE(NULLIF(max_todo, 0), 2147483647) FROM usr WHERE id = ?1)
+		`
+	from, to := s.currentPolicy().Window(task.CreateAt, time.UTC)
+	res, err := s.db.ExecContext(ctx, stmt, task.UsrId, task.Content, task.CreateAt, from, to)
+	if err != nil {
+		return errors.Wrap(err, "ExecContext()")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "RowsAffected()")
+	}
+	if affected < 1 {
+		return storages.ErrQuotaExceeded
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return errors.Wrap(err, "LastInsertId()")
+	}
+	task.Id = int(id)
+
+	return nil
+}
+
+func (s *Sqlite) Close() error {
+	return s.db.Close()
+}
+
+// CheckHealth pings the database
+func (s *Sqlite) CheckHealth(ctx context.Context) map[string]error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return map[string]error{"database": errors.Wrap(err, "PingContext()")}
+	}
+	return map[string]error{"database": nil}
+}
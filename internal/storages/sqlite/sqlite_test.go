@@ -0,0 +1,136 @@
+package sqllite
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestSqlite(t *testing.T) *Sqlite {
+	s, err := NewSqlite(context.Background(), ":memory:")
+	require.NoError(t, err)
+	require.NoError(t, s.Seed(context.Background(), storages.DefaultFixtures()))
+	return s
+}
+
+func TestSqliteSeed(t *testing.T) {
+	s, err := NewSqlite(context.Background(), ":memory:")
+	require.NoError(t, err)
+	defer s.Close()
+
+	requireTest := require.New(t)
+	ctx := context.Background()
+
+	_, err = s.ValidateUser(ctx, "firstUser", "example")
+	requireTest.Equal(storages.ErrInvalidCredentials, err, "nothing is inserted on start")
+
+	fixtures := storages.DefaultFixtures()
+	fixtures.Users = append(fixtures.Users, storages.FixtureUser{Username: "secondUser", Password: "example", MaxTodo: 1})
+	requireTest.NoError(s.Seed(ctx, fixtures))
+	requireTest.NoError(s.Seed(ctx, fixtures), "seeding twice skips existing rows")
+
+	usr, err := s.ValidateUser(ctx, "secondUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(2, usr.Id)
+	tasks, err := s.GetTasks(ctx, 1, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+}
+
+func TestSqliteValidateUser(t *testing.T) {
+	s := newTestSqlite(t)
+	defer s.Close()
+
+	requireTest := require.New(t)
+
+	usr, err := s.ValidateUser(context.Background(), "firstUser", "example")
+	requireTest.NoError(err)
+	requireTest.Equal(1, usr.Id)
+	requireTest.Equal(5, usr.MaxTodo)
+
+	_, err = s.ValidateUser(context.Background(), "firstUser", "wrong")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+
+	_, err = s.ValidateUser(context.Background(), "nobody", "example")
+	requireTest.Equal(storages.ErrInvalidCredentials, err)
+}
+
+func TestSqliteGetTasks(t *testing.T) {
+	s := newTestSqlite(t)
+	defer s.Close()
+
+	requireTest := require.New(t)
+
+	tasks, err := s.GetTasks(context.Background(), 1, time.Date(2020, 6, 29, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 1)
+	requireTest.Equal("test 1", tasks[0].Content)
+
+	tasks, err = s.GetTasks(context.Background(), 1, time.Date(2020, 6, 30, 0, 0, 0, 0, time.UTC))
+	requireTest.NoError(err)
+	requireTest.Empty(tasks)
+}
+
+func TestSqliteInsertTaskDailyLimit(t *testing.T) {
+	s := newTestSqlite(t)
+	defer s.Close()
+
+	requireTest := require.New(t)
+
+	for i := 0; i < 5; i++ {
+		task := &storages.Task{UsrId: 1, Content: "content"}
+		requireTest.NoError(s.InsertTask(context.Background(), task))
+		requireTest.NotZero(task.Id)
+	}
+
+	err := s.InsertTask(context.Background(), &storages.Task{UsrId: 1, Content: "content"})
+	requireTest.Equal(storages.ErrQuotaExceeded, err)
+
+	tasks, err := s.GetTasks(context.Background(), 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 5)
+}
+
+func TestSqliteInsertTaskUnlimited(t *testing.T) {
+	s := newTestSqlite(t)
+	defer s.Close()
+
+	requireTest := require.New(t)
+
+	_, err := s.db.Exec(`UPDATE usr SET max_todo = ? WHERE id = 1`, storages.UnlimitedTodo)
+	requireTest.NoError(err)
+	for i := 0; i < 10; i++ {
+		requireTest.NoError(s.InsertTask(context.Background(), &storages.Task{UsrId: 1, Content: "content"}))
+	}
+}
+
+func TestSqliteInsertTaskConcurrent(t *testing.T) {
+	s := newTestSqlite(t)
+	defer s.Close()
+
+	requireTest := require.New(t)
+
+	// parallel inserts never exceed the limit, those over it fail with ErrQuotaExceeded
+	errs := make(chan error, 20)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- s.InsertTask(context.Background(), &storages.Task{UsrId: 1, Content: "content"})
+		}()
+	}
+	inserted := 0
+	for i := 0; i < cap(errs); i++ {
+		switch err := <-errs; err {
+		case nil:
+			inserted++
+		default:
+			requireTest.Equal(storages.ErrQuotaExceeded, err)
+		}
+	}
+	requireTest.Equal(5, inserted)
+
+	tasks, err := s.GetTasks(context.Background(), 1, time.Now())
+	requireTest.NoError(err)
+	requireTest.Len(tasks, 5)
+}
@@ -0,0 +1,475 @@
+package storages
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// Storages return these errors as is so callers can compare them directly
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrInvalidCredentials = errors.New("username or password is not correct")
+	ErrQuotaExceeded      = errors.New("user's daily-limit has been reached")
+	ErrConflict           = errors.New("conflict with existing data")
+	ErrInvalidTask        = errors.New("invalid task")
+	ErrForbidden          = errors.New("forbidden")
+	ErrInvalidProject     = errors.New("invalid project")
+	ErrInvalidUser        = errors.New("invalid username")
+	ErrInvalidTimezone    = errors.New("unknown timezone")
+)
+
+// Store is implemented by every storage backend, business logic only
+// depends on it so backends and mocks can be swapped freely.
+// GetTasks returns the tasks created on the day of createAt in the location of createAt
+type Store interface {
+	ValidateUser(ctx context.Context, username, password string) (*User, error)
+	GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*Task, error)
+	InsertTask(ctx context.Context, task *Task) error
+}
+
+// TaskFilter narrows tasks returned by TaskFinder.FindTasks
+type TaskFilter struct {
+	// IncludeDeleted also returns soft deleted tasks, it's meant for admin views
+	IncludeDeleted bool
+	// Completed returns only done tasks if true, only not done tasks if false
+	Completed *bool
+	// SortBy orders tasks, TaskSortCreateAt by default
+	SortBy TaskSort
+	// Tags returns only tasks having all of the tags
+	Tags []string
+	// ProjectId returns only tasks of the project
+	ProjectId *int
+}
+
+// TaskSort is the order of tasks returned by TaskFinder.FindTasks
+type TaskSort string
+
+const (
+	// TaskSortCreateAt orders the oldest first
+	TaskSortCreateAt TaskSort = "create_at"
+	// TaskSortPriority orders the highest priority first, then the oldest
+	TaskSortPriority TaskSort = "priority"
+	// TaskSortPosition follows the manual order, see TaskPositioner
+	TaskSortPosition TaskSort = "position"
+)
+
+// Valid reports whether s is a known order, empty means the default one
+func (s TaskSort) Valid() bool {
+	switch s {
+	case "", TaskSortCreateAt, TaskSortPriority, TaskSortPosition:
+		return true
+	default:
+		return false
+	}
+}
+
+// TaskFinder is implemented by storages which can filter tasks.
+// FindOverdueTasks returns not deleted tasks of the user which are not done
+// and were due before now, the earliest due first
+type TaskFinder interface {
+	FindTasks(ctx context.Context, usrId int, createAt time.Time, filter TaskFilter) ([]*Task, error)
+	FindOverdueTasks(ctx context.Context, usrId int, now time.Time) ([]*Task, error)
+}
+
+// TaskStreamer is implemented by storages which can pass tasks on as they're read rather than load them all.
+// StreamTasks calls fn with the tasks FindTasks would return in the same order, it stops at the first error
+// of fn and returns it
+type TaskStreamer interface {
+	StreamTasks(ctx context.Context, usrId int, createAt time.Time, filter TaskFilter, fn func(*Task) error) error
+}
+
+// TaskGetter is implemented by storages which can return a single task. GetTask returns the task of the user
+// which is not deleted or ErrNotFound
+type TaskGetter interface {
+	GetTask(ctx context.Context, usrId, id int) (*Task, error)
+}
+
+// TaskArchiver is implemented by storages which support soft delete and archival of tasks,
+// their GetTasks leaves deleted tasks out. Deleted tasks still count toward the daily-limit.
+// DeleteTask and ArchiveTask return ErrNotFound if the user has no such task,
+// DeleteTask returns ErrForbidden instead if the task belongs to another user
+type TaskArchiver interface {
+	DeleteTask(ctx context.Context, usrId, id int) error
+	ArchiveTask(ctx context.Context, usrId, id int) error
+}
+
+// TaskMetadataUpdater is implemented by storages which keep status, due date and priority of tasks.
+// Setting TaskStatusDone sets CompletedAt, other statuses clear it, ErrInvalidTask is returned
+// for unknown statuses and ErrNotFound if the user has no such task
+type TaskMetadataUpdater interface {
+	SetTaskStatus(ctx context.Context, usrId, id int, status TaskStatus) error
+	SetTaskDueAt(ctx context.Context, usrId, id int, dueAt *time.Time) error
+	SetTaskPriority(ctx context.Context, usrId, id int, priority int) error
+}
+
+// TaskTagger is implemented by storages which can tag tasks, tags given to InsertTask are saved too.
+// Tags are normalized by NormalizeTags, adding a tag twice or removing a missing one does nothing.
+// Both return ErrNotFound if the user has no such task
+type TaskTagger interface {
+	AddTaskTags(ctx context.Context, usrId, id int, tags []string) error
+	RemoveTaskTags(ctx context.Context, usrId, id int, tags []string) error
+}
+
+// ProjectStore is implemented by storages which group tasks into projects.
+// Project names are unique per user, ErrConflict is returned for duplicates. InsertTask returns
+// ErrNotFound for projects of other users and ErrQuotaExceeded when the project's daily-limit
+// has been reached. Deleting a project keeps its tasks without a project
+type ProjectStore interface {
+	CreateProject(ctx context.Context, project *Project) error
+	GetProjects(ctx context.Context, usrId int) ([]*Project, error)
+	GetProject(ctx context.Context, usrId, id int) (*Project, error)
+	UpdateProject(ctx context.Context, project *Project) error
+	DeleteProject(ctx context.Context, usrId, id int) error
+}
+
+// TaskChecklist is implemented by storages which keep ordered checklist items of tasks.
+// Items are appended at the end, ReorderChecklist takes ids of all items of the task in
+// the new order or returns ErrInvalidTask. Every change increases the version of the task
+// and ErrNotFound is returned if the user has no such task or item
+type TaskChecklist interface {
+	GetChecklist(ctx context.Context, usrId, taskId int) ([]*ChecklistItem, error)
+	AddChecklistItem(ctx context.Context, usrId int, item *ChecklistItem) error
+	SetChecklistItemDone(ctx context.Context, usrId, taskId, id int, done bool) error
+	ReorderChecklist(ctx context.Context, usrId, taskId int, ids []int) error
+}
+
+// TaskCommenter is implemented by storages which keep comment threads of tasks.
+// Comments are listed oldest first, users can only delete comments they wrote and get
+// ErrForbidden for others. ErrNotFound is returned if the user has no such task or comment
+type TaskCommenter interface {
+	AddComment(ctx context.Context, comment *Comment) error
+	GetComments(ctx context.Context, usrId, taskId int) ([]*Comment, error)
+	DeleteComment(ctx context.Context, usrId, taskId, id int) error
+}
+
+// TaskAttacher is implemented by storages which keep metadata of files attached to tasks,
+// contents are kept in a blob store by callers. Attachments are listed oldest first and
+// ErrNotFound is returned if the user has no such task or attachment. PurgeAttachments removes
+// and returns attachments of the task once it has been deleted so their blobs can be removed
+type TaskAttacher interface {
+	AddAttachment(ctx context.Context, attachment *Attachment) error
+	GetAttachments(ctx context.Context, usrId, taskId int) ([]*Attachment, error)
+	GetAttachment(ctx context.Context, usrId, taskId, id int) (*Attachment, error)
+	DeleteAttachment(ctx context.Context, usrId, taskId, id int) (*Attachment, error)
+	PurgeAttachments(ctx context.Context, usrId, taskId int) ([]*Attachment, error)
+}
+
+// TaskTrasher is implemented by storages which keep deleted tasks in a trash until they are purged.
+// GetTrash returns deleted tasks of the user, the latest deleted first. RestoreTask undeletes the task and
+// returns ErrNotFound if the user has no such deleted task. PurgeTrash permanently deletes up to limit tasks
+// deleted before the given time along with their checklists, comments and attachments, it returns how many
+// tasks were purged and their attachments so callers can remove the blobs
+type TaskTrasher interface {
+	GetTrash(ctx context.Context, usrId int) ([]*Task, error)
+	RestoreTask(ctx context.Context, usrId, id int) error
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int, []*Attachment, error)
+}
+
+// TaskRecurrer is implemented by storages which keep recurring tasks.
+// MaterializeRecurrences creates the next occurrence of every recurring task which is done or
+// whose RecurAt has arrived by now, see Task.NextOccurrence. Each task recurs once and the new
+// occurrence carries the recurrence on, occurrences don't count against the daily-limit.
+// It returns the number of created occurrences
+type TaskRecurrer interface {
+	MaterializeRecurrences(ctx context.Context, now time.Time) (int, error)
+}
+
+// TaskPositioner is implemented by storages which keep the manual order of tasks. Positions are
+// global per user so any list of tasks, e.g. of a day or of a project, sorted by TaskSortPosition
+// follows the manual order. MoveTask places the task between its new neighbors afterId and beforeId
+// in the list seen by the client, nil is an end of the list and at least one of them must be given.
+// It returns ErrNotFound if the user has no such tasks and ErrConflict if afterId is not before
+// beforeId anymore. Moving increases the version of the task
+type TaskPositioner interface {
+	MoveTask(ctx context.Context, usrId, id int, afterId, beforeId *int) error
+}
+
+// TaskBatchInserter is implemented by storages which insert many tasks at once. Either all tasks
+// are inserted in one transaction or none, daily-limits are checked against the whole batch.
+// It returns a BatchError telling which tasks failed, e.g. with ErrInvalidTask, ErrNotFound for
+// an unknown project or ErrQuotaExceeded for the tasks over the limit
+type TaskBatchInserter interface {
+	InsertTasks(ctx context.Context, tasks []*Task) error
+}
+
+// BatchError holds the errors of the failed tasks of a batch by their index in the batch
+type BatchError map[int]error
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("%d tasks of the batch failed", len(e))
+}
+
+// TaskSelector picks the not deleted tasks of a user changed by TaskBulkUpdater,
+// every given field narrows the selection
+type TaskSelector struct {
+	Ids []int `json:"ids"`
+	// Completed picks only done tasks if true, only not done tasks if false
+	Completed       *bool      `json:"completed"`
+	CreatedBefore   *time.Time `json:"created_before"`
+	CompletedBefore *time.Time `json:"completed_before"`
+	ProjectId       *int       `json:"project_id"`
+	// Tags picks only tasks having all of the tags
+	Tags []string `json:"tags"`
+}
+
+// Normalize normalizes Tags, a selector without any field gives ErrInvalidTask
+// so that a mistake never changes all tasks of a user
+func (s *TaskSelector) Normalize() error {
+	if len(s.Ids) == 0 && s.Completed == nil && s.CreatedBefore == nil && s.CompletedBefore == nil &&
+		s.ProjectId == nil && len(s.Tags) == 0 {
+		return ErrInvalidTask
+	}
+	tags, err := NormalizeTags(s.Tags)
+	if err != nil {
+		return err
+	}
+	s.Tags = tags
+	return nil
+}
+
+// TaskBulkUpdater is implemented by storages which change many tasks of a user in one transaction.
+// Both return the ids of the changed tasks, tasks already done are left out by CompleteTasks.
+// ErrInvalidTask is returned for an invalid selector, see TaskSelector.Normalize
+type TaskBulkUpdater interface {
+	CompleteTasks(ctx context.Context, usrId int, selector TaskSelector) ([]int, error)
+	DeleteTasks(ctx context.Context, usrId int, selector TaskSelector) ([]int, error)
+}
+
+// TaskTemplater is implemented by storages which keep templates of tasks. CreateTemplate appends
+// the user's tasks of taskIds along with their checklists to the tasks of template, it returns ErrNotFound
+// if the user has no such tasks. InstantiateTemplate inserts the tasks of the template with their
+// checklists in one transaction and returns them, ErrQuotaExceeded is returned if they don't fit in
+// the daily-limit. The others return ErrNotFound if the user has no such template
+type TaskTemplater interface {
+	CreateTemplate(ctx context.Context, template *Template, taskIds []int) error
+	GetTemplates(ctx context.Context, usrId int) ([]*Template, error)
+	GetTemplate(ctx context.Context, usrId, id int) (*Template, error)
+	DeleteTemplate(ctx context.Context, usrId, id int) error
+	InstantiateTemplate(ctx context.Context, usrId, id int) ([]*Task, error)
+}
+
+// TaskReminder is implemented by storages which keep reminders of tasks, RemindAt given to InsertTask
+// is saved too. SetTaskRemindAt sets the reminder of the task, nil clears it, and returns ErrNotFound if
+// the user has no such task. ClaimReminders returns up to limit not deleted tasks whose reminder is due
+// at now and not delivered yet, they are leased until now+lease so that other dispatchers skip them and
+// a reminder which is never acknowledged is claimed again. AckReminder marks the reminder of the task
+// delivered unless it has changed since it was claimed, remindAt is the claimed RemindAt
+type TaskReminder interface {
+	SetTaskRemindAt(ctx context.Context, usrId, id int, remindAt *time.Time) error
+	ClaimReminders(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Task, error)
+	AckReminder(ctx context.Context, id int, remindAt time.Time) error
+}
+
+// UserCreator is implemented by storages which can register users. CreateUser inserts usr whose
+// PwdHash is already hashed and sets its Id, it returns ErrConflict if the username is taken
+type UserCreator interface {
+	CreateUser(ctx context.Context, usr *User) error
+}
+
+// RefreshTokenStore is implemented by storages which keep refresh tokens. CreateRefreshToken saves
+// the first token of a family. RotateRefreshToken trades the token of hash for next in one transaction
+// and returns its user, next joins the family of the traded token. It returns ErrInvalidCredentials
+// for unknown, expired and revoked tokens, a token which has already been rotated revokes its whole
+// family since it has been stolen. RevokeRefreshTokens revokes the family of the token of hash,
+// it returns ErrInvalidCredentials for unknown tokens
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
+	RotateRefreshToken(ctx context.Context, hash string, next *RefreshToken) (*User, error)
+	RevokeRefreshTokens(ctx context.Context, hash string) error
+}
+
+// PasswordStore is implemented by storages which can change passwords of users, hashes are made by callers.
+// GetUser returns the user along with its PwdHash or ErrNotFound. SetPassword replaces the hash of the user
+// and revokes all of their refresh tokens and pending resets, it returns ErrNotFound for unknown users.
+// CreatePasswordReset saves reset for the user of username and returns the user, ErrNotFound if there is none.
+// ResetPassword sets pwdHash for the user of the reset token of hash like SetPassword does in one transaction
+// and returns the user, it returns ErrInvalidCredentials for unknown, expired and used tokens
+type PasswordStore interface {
+	GetUser(ctx context.Context, id int) (*User, error)
+	SetPassword(ctx context.Context, usrId int, pwdHash string) error
+	CreatePasswordReset(ctx context.Context, username string, reset *PasswordReset) (*User, error)
+	ResetPassword(ctx context.Context, hash, pwdHash string) (*User, error)
+}
+
+// LoginAttemptStore is implemented by storages which count failed logins by key, e.g. by username or address.
+// GetLoginFailures returns how many failures are counted under key and when the last one happened, zero values
+// if there are none. AddLoginFailure counts a failure at now and returns the failures of key, previous failures
+// are forgotten first if the last one happened longer than window ago. ClearLoginFailures forgets failures of key
+type LoginAttemptStore interface {
+	GetLoginFailures(ctx context.Context, key string) (int, time.Time, error)
+	AddLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error)
+	ClearLoginFailures(ctx context.Context, key string) error
+}
+
+// HealthChecker is implemented by storages which can check their dependencies. CheckHealth returns the error of
+// each component by its name such as "database" or "migrations", nil for healthy ones
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) map[string]error
+}
+
+// PoolStats are the connections of the pool of a storage and how long acquiring them took so far, along with
+// what its health checks found: whether the last one succeeded, how many failed in a row and how many times the
+// pool was re-created
+type PoolStats struct {
+	Healthy             bool
+	ConsecutiveFailures int
+	Recreations         int64
+
+	AcquiredConns     int
+	IdleConns         int
+	TotalConns        int
+	MaxConns          int
+	AcquireCount      int64
+	EmptyAcquireCount int64
+	AcquireDuration   time.Duration
+}
+
+// PoolStater is implemented by storages which keep a pool of connections
+type PoolStater interface {
+	PoolStats() PoolStats
+}
+
+// StatementTimer is implemented by storages which time their statements, observe is told the name of every
+// statement run, such as SELECT usr, and how long it took
+type StatementTimer interface {
+	TimeStatements(observe func(statement string, elapsed time.Duration))
+}
+
+// IdempotencyStore is implemented by storages which keep responses of requests by their idempotency keys, keys are
+// scoped to users. GetIdempotentResponse returns the response saved under key for the user which has not expired
+// at now or ErrNotFound. SaveIdempotentResponse saves resp unless a response which has not expired at now is saved
+// under its key already, it returns ErrConflict then. Expired responses may be deleted by either
+type IdempotencyStore interface {
+	GetIdempotentResponse(ctx context.Context, usrId int, key string, now time.Time) (*IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, resp *IdempotentResponse, now time.Time) error
+}
+
+// IdentityStore is implemented by storages which link users to their accounts at external identity providers,
+// a subject is the id of the account at the provider. FindIdentityUser returns the user linked to subject of
+// provider or ErrNotFound. LinkIdentity links subject of provider to the user, linking it again to the same user
+// does nothing. It returns ErrConflict if subject is linked to another user and ErrNotFound for unknown users.
+// CreateIdentityUser inserts usr like UserCreator.CreateUser does and links subject to it in one transaction
+type IdentityStore interface {
+	FindIdentityUser(ctx context.Context, provider, subject string) (*User, error)
+	LinkIdentity(ctx context.Context, usrId int, provider, subject string) error
+	CreateIdentityUser(ctx context.Context, usr *User, provider, subject string) error
+}
+
+// UserAdmin is implemented by storages which let admins manage users. ListUsers returns all users by id
+// without their password hashes. UpdateUser applies patch to the user and returns it, ErrNotFound is returned
+// for unknown users
+type UserAdmin interface {
+	ListUsers(ctx context.Context) ([]*User, error)
+	UpdateUser(ctx context.Context, id int, patch *UserPatch) (*User, error)
+}
+
+// QuotaStore is implemented by storages which report the daily quotas of users. GetQuota counts the tasks the
+// user added on the day of at, ErrNotFound is returned for unknown users. InsertTaskQuota inserts task like
+// InsertTask does and returns the quota counted in the same transaction, along with ErrQuotaExceeded when the
+// limit has been reached
+type QuotaStore interface {
+	GetQuota(ctx context.Context, usrId int, at time.Time) (*Quota, error)
+	InsertTaskQuota(ctx context.Context, task *Task) (*Quota, error)
+}
+
+// QuotaResetter is implemented by storages which let admins reset the quotas of users. ResetQuota stops the tasks
+// the user added before at from counting towards their limit and gives their burst credits back, it returns the
+// quota at at afterwards or ErrNotFound for unknown users. Limits of projects and usage still count those tasks
+type QuotaResetter interface {
+	ResetQuota(ctx context.Context, usrId int, at time.Time) (*Quota, error)
+}
+
+// PlanStore is implemented by storages which keep plans. Users on a plan have its daily-limit rather than their
+// own, which they get back once off the plan. ListPlans returns the plans by name, PutPlan creates or changes a
+// plan and DeletePlan deletes one, taking its users off it. SetUserPlan puts the user on the plan of name, or
+// on none for "". ErrNotFound is returned for unknown users and plans
+type PlanStore interface {
+	ListPlans(ctx context.Context) ([]*Plan, error)
+	PutPlan(ctx context.Context, plan *Plan) error
+	DeletePlan(ctx context.Context, name string) error
+	SetUserPlan(ctx context.Context, usrId int, name string) error
+}
+
+// UsageStore is implemented by storages which keep how many tasks each user added a day, on the days of their
+// timezones. Tasks count on the day they were added through the quota even once deleted. GetUsage returns the
+// days from the date of from to the date of to, both included, on which the user added tasks, the earliest first
+type UsageStore interface {
+	GetUsage(ctx context.Context, usrId int, from, to time.Time) ([]*Usage, error)
+}
+
+// StatsStore is implemented by storages which count the tasks users created and completed by day by a single
+// query. GetStats returns the days in loc from the date of from to the date of to, both included, on which the
+// user created or completed tasks which are not deleted, the earliest first
+type StatsStore interface {
+	GetStats(ctx context.Context, usrId int, from, to time.Time, loc *time.Location) ([]*DayStats, error)
+}
+
+// TimezoneStore is implemented by storages which count the days of users, for their daily-limits, in the
+// timezones of the users. GetTimezone returns the IANA name of the timezone of the user, "" or "UTC" for UTC,
+// and SetTimezone changes it, ErrInvalidTimezone is returned for names the storage doesn't know.
+// Both return ErrNotFound for unknown users
+type TimezoneStore interface {
+	GetTimezone(ctx context.Context, usrId int) (string, error)
+	SetTimezone(ctx context.Context, usrId int, timezone string) error
+}
+
+// SessionStore is implemented by storages which keep refresh tokens and let users manage their sessions.
+// ListSessions returns the sessions of the user which are neither revoked nor expired, the latest used first.
+// RevokeSession revokes the refresh tokens of the session of the user and returns ErrNotFound if the user has
+// no such session. RevokeSessions revokes every session of the user
+type SessionStore interface {
+	ListSessions(ctx context.Context, usrId int) ([]*Session, error)
+	RevokeSession(ctx context.Context, usrId, id int) error
+	RevokeSessions(ctx context.Context, usrId int) error
+}
+
+// TwoFactorStore is implemented by storages which keep TOTP enrollments. GetTwoFactor returns ErrNotFound
+// if the user has not enrolled. SaveTwoFactor replaces the enrollment of the user by tf which is not enabled,
+// it returns ErrConflict if the enrollment of the user is enabled. EnableTwoFactor enables the enrollment
+// of the user with the period of the code which confirmed it, ErrNotFound if there is no pending one.
+// UseTOTP accepts the period counter only if it's later than the last one and UseBackupCode deletes
+// the backup code of hash, both return ErrInvalidCredentials otherwise. DisableTwoFactor deletes the enrollment
+type TwoFactorStore interface {
+	GetUser(ctx context.Context, id int) (*User, error)
+	GetTwoFactor(ctx context.Context, usrId int) (*TwoFactor, error)
+	SaveTwoFactor(ctx context.Context, tf *TwoFactor) error
+	EnableTwoFactor(ctx context.Context, usrId int, counter int64) error
+	UseTOTP(ctx context.Context, usrId int, counter int64) error
+	UseBackupCode(ctx context.Context, usrId int, hash string) error
+	DisableTwoFactor(ctx context.Context, usrId int) error
+}
+
+// ShareStore is implemented by storages which let users share their tasks and projects with other users.
+// GetShare returns the share of the owner with the user or ErrNotFound. SaveShare creates or replaces a share,
+// it returns ErrNotFound for unknown users. DeleteShare returns ErrNotFound if there is no such share
+type ShareStore interface {
+	GetShare(ctx context.Context, ownerId, usrId int) (*Share, error)
+	ListShares(ctx context.Context, ownerId int) ([]*Share, error)
+	SaveShare(ctx context.Context, share *Share) error
+	DeleteShare(ctx context.Context, ownerId, usrId int) error
+}
+
+// AuditStore is implemented by storages which keep the security audit log. AppendAudit sets the Id and
+// the CreateAt of the event, ListAudit returns the events picked by the filter latest first, up to its limit
+type AuditStore interface {
+	AppendAudit(ctx context.Context, event *AuditEvent) error
+	ListAudit(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error)
+}
+
+// TaskAuditStore is implemented by storages which keep the history of changes of tasks, it's kept once tasks are
+// purged. AppendTaskAudit sets the Id and the CreateAt of the entry, GetTaskHistory returns the entries of the task
+// of the user oldest first or ErrNotFound if there are none
+type TaskAuditStore interface {
+	AppendTaskAudit(ctx context.Context, entry *TaskAuditEntry) error
+	GetTaskHistory(ctx context.Context, usrId, taskId int) ([]*TaskAuditEntry, error)
+}
+
+// TaskUpdater is implemented by storages which can edit tasks with optimistic concurrency.
+// UpdateTask saves content, status, due date and priority of task if its Version is still
+// the stored one and fills task with the saved values, it returns ErrConflict if the task
+// has been changed in the meantime and ErrNotFound if the user has no such task
+type TaskUpdater interface {
+	UpdateTask(ctx context.Context, task *Task) error
+}
@@ -0,0 +1,411 @@
+package storages
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"time"
+)
+
+type StoreMock struct {
+	mock.Mock
+}
+
+func (m *StoreMock) ValidateUser(ctx context.Context, username, password string) (*User, error) {
+	args := m.Called(ctx, username, password)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) GetTasks(ctx context.Context, usrId int, createAt time.Time) ([]*Task, error) {
+	args := m.Called(ctx, usrId, createAt)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *StoreMock) GetTask(ctx context.Context, usrId, id int) (*Task, error) {
+	args := m.Called(ctx, usrId, id)
+	return args.Get(0).(*Task), args.Error(1)
+}
+
+func (m *StoreMock) InsertTask(ctx context.Context, task *Task) error {
+	args := m.Called(ctx, task)
+	return args.Error(0)
+}
+
+func (m *StoreMock) FindTasks(ctx context.Context, usrId int, createAt time.Time, filter TaskFilter) ([]*Task, error) {
+	args := m.Called(ctx, usrId, createAt, filter)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *StoreMock) FindOverdueTasks(ctx context.Context, usrId int, now time.Time) ([]*Task, error) {
+	args := m.Called(ctx, usrId, now)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *StoreMock) DeleteTask(ctx context.Context, usrId, id int) error {
+	args := m.Called(ctx, usrId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) ArchiveTask(ctx context.Context, usrId, id int) error {
+	args := m.Called(ctx, usrId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) SetTaskStatus(ctx context.Context, usrId, id int, status TaskStatus) error {
+	args := m.Called(ctx, usrId, id, status)
+	return args.Error(0)
+}
+
+func (m *StoreMock) SetTaskDueAt(ctx context.Context, usrId, id int, dueAt *time.Time) error {
+	args := m.Called(ctx, usrId, id, dueAt)
+	return args.Error(0)
+}
+
+func (m *StoreMock) AddTaskTags(ctx context.Context, usrId, id int, tags []string) error {
+	args := m.Called(ctx, usrId, id, tags)
+	return args.Error(0)
+}
+
+func (m *StoreMock) RemoveTaskTags(ctx context.Context, usrId, id int, tags []string) error {
+	args := m.Called(ctx, usrId, id, tags)
+	return args.Error(0)
+}
+
+func (m *StoreMock) UpdateTask(ctx context.Context, task *Task) error {
+	args := m.Called(ctx, task)
+	return args.Error(0)
+}
+
+func (m *StoreMock) SetTaskPriority(ctx context.Context, usrId, id int, priority int) error {
+	args := m.Called(ctx, usrId, id, priority)
+	return args.Error(0)
+}
+
+func (m *StoreMock) CreateProject(ctx context.Context, project *Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetProjects(ctx context.Context, usrId int) ([]*Project, error) {
+	args := m.Called(ctx, usrId)
+	return args.Get(0).([]*Project), args.Error(1)
+}
+
+func (m *StoreMock) GetProject(ctx context.Context, usrId, id int) (*Project, error) {
+	args := m.Called(ctx, usrId, id)
+	return args.Get(0).(*Project), args.Error(1)
+}
+
+func (m *StoreMock) UpdateProject(ctx context.Context, project *Project) error {
+	args := m.Called(ctx, project)
+	return args.Error(0)
+}
+
+func (m *StoreMock) DeleteProject(ctx context.Context, usrId, id int) error {
+	args := m.Called(ctx, usrId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetChecklist(ctx context.Context, usrId, taskId int) ([]*ChecklistItem, error) {
+	args := m.Called(ctx, usrId, taskId)
+	return args.Get(0).([]*ChecklistItem), args.Error(1)
+}
+
+func (m *StoreMock) AddChecklistItem(ctx context.Context, usrId int, item *ChecklistItem) error {
+	args := m.Called(ctx, usrId, item)
+	return args.Error(0)
+}
+
+func (m *StoreMock) SetChecklistItemDone(ctx context.Context, usrId, taskId, id int, done bool) error {
+	args := m.Called(ctx, usrId, taskId, id, done)
+	return args.Error(0)
+}
+
+func (m *StoreMock) ReorderChecklist(ctx context.Context, usrId, taskId int, ids []int) error {
+	args := m.Called(ctx, usrId, taskId, ids)
+	return args.Error(0)
+}
+
+func (m *StoreMock) MaterializeRecurrences(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *StoreMock) AddComment(ctx context.Context, comment *Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetComments(ctx context.Context, usrId, taskId int) ([]*Comment, error) {
+	args := m.Called(ctx, usrId, taskId)
+	return args.Get(0).([]*Comment), args.Error(1)
+}
+
+func (m *StoreMock) DeleteComment(ctx context.Context, usrId, taskId, id int) error {
+	args := m.Called(ctx, usrId, taskId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) AddAttachment(ctx context.Context, attachment *Attachment) error {
+	args := m.Called(ctx, attachment)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetAttachments(ctx context.Context, usrId, taskId int) ([]*Attachment, error) {
+	args := m.Called(ctx, usrId, taskId)
+	return args.Get(0).([]*Attachment), args.Error(1)
+}
+
+func (m *StoreMock) GetAttachment(ctx context.Context, usrId, taskId, id int) (*Attachment, error) {
+	args := m.Called(ctx, usrId, taskId, id)
+	return args.Get(0).(*Attachment), args.Error(1)
+}
+
+func (m *StoreMock) DeleteAttachment(ctx context.Context, usrId, taskId, id int) (*Attachment, error) {
+	args := m.Called(ctx, usrId, taskId, id)
+	return args.Get(0).(*Attachment), args.Error(1)
+}
+
+func (m *StoreMock) PurgeAttachments(ctx context.Context, usrId, taskId int) ([]*Attachment, error) {
+	args := m.Called(ctx, usrId, taskId)
+	return args.Get(0).([]*Attachment), args.Error(1)
+}
+
+func (m *StoreMock) MoveTask(ctx context.Context, usrId, id int, afterId, beforeId *int) error {
+	args := m.Called(ctx, usrId, id, afterId, beforeId)
+	return args.Error(0)
+}
+
+func (m *StoreMock) InsertTasks(ctx context.Context, tasks []*Task) error {
+	args := m.Called(ctx, tasks)
+	return args.Error(0)
+}
+
+func (m *StoreMock) CompleteTasks(ctx context.Context, usrId int, selector TaskSelector) ([]int, error) {
+	args := m.Called(ctx, usrId, selector)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *StoreMock) DeleteTasks(ctx context.Context, usrId int, selector TaskSelector) ([]int, error) {
+	args := m.Called(ctx, usrId, selector)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *StoreMock) CreateTemplate(ctx context.Context, template *Template, taskIds []int) error {
+	args := m.Called(ctx, template, taskIds)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetTemplates(ctx context.Context, usrId int) ([]*Template, error) {
+	args := m.Called(ctx, usrId)
+	return args.Get(0).([]*Template), args.Error(1)
+}
+
+func (m *StoreMock) GetTemplate(ctx context.Context, usrId, id int) (*Template, error) {
+	args := m.Called(ctx, usrId, id)
+	return args.Get(0).(*Template), args.Error(1)
+}
+
+func (m *StoreMock) DeleteTemplate(ctx context.Context, usrId, id int) error {
+	args := m.Called(ctx, usrId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) InstantiateTemplate(ctx context.Context, usrId, id int) ([]*Task, error) {
+	args := m.Called(ctx, usrId, id)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *StoreMock) SetTaskRemindAt(ctx context.Context, usrId, id int, remindAt *time.Time) error {
+	args := m.Called(ctx, usrId, id, remindAt)
+	return args.Error(0)
+}
+
+func (m *StoreMock) ClaimReminders(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Task, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *StoreMock) AckReminder(ctx context.Context, id int, remindAt time.Time) error {
+	args := m.Called(ctx, id, remindAt)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetTrash(ctx context.Context, usrId int) ([]*Task, error) {
+	args := m.Called(ctx, usrId)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *StoreMock) RestoreTask(ctx context.Context, usrId, id int) error {
+	args := m.Called(ctx, usrId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) PurgeTrash(ctx context.Context, before time.Time, limit int) (int, []*Attachment, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Int(0), args.Get(1).([]*Attachment), args.Error(2)
+}
+
+func (m *StoreMock) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *StoreMock) RotateRefreshToken(ctx context.Context, hash string, next *RefreshToken) (*User, error) {
+	args := m.Called(ctx, hash, next)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) RevokeRefreshTokens(ctx context.Context, hash string) error {
+	args := m.Called(ctx, hash)
+	return args.Error(0)
+}
+
+func (m *StoreMock) CreateUser(ctx context.Context, usr *User) error {
+	args := m.Called(ctx, usr)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetUser(ctx context.Context, id int) (*User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) SetPassword(ctx context.Context, usrId int, pwdHash string) error {
+	args := m.Called(ctx, usrId, pwdHash)
+	return args.Error(0)
+}
+
+func (m *StoreMock) CreatePasswordReset(ctx context.Context, username string, reset *PasswordReset) (*User, error) {
+	args := m.Called(ctx, username, reset)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) ResetPassword(ctx context.Context, hash, pwdHash string) (*User, error) {
+	args := m.Called(ctx, hash, pwdHash)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) GetLoginFailures(ctx context.Context, key string) (int, time.Time, error) {
+	args := m.Called(ctx, key)
+	return args.Int(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *StoreMock) AddLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int, error) {
+	args := m.Called(ctx, key, now, window)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *StoreMock) ClearLoginFailures(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetIdempotentResponse(ctx context.Context, usrId int, key string, now time.Time) (*IdempotentResponse, error) {
+	args := m.Called(ctx, usrId, key, now)
+	return args.Get(0).(*IdempotentResponse), args.Error(1)
+}
+
+func (m *StoreMock) SaveIdempotentResponse(ctx context.Context, resp *IdempotentResponse, now time.Time) error {
+	args := m.Called(ctx, resp, now)
+	return args.Error(0)
+}
+
+func (m *StoreMock) FindIdentityUser(ctx context.Context, provider, subject string) (*User, error) {
+	args := m.Called(ctx, provider, subject)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) LinkIdentity(ctx context.Context, usrId int, provider, subject string) error {
+	args := m.Called(ctx, usrId, provider, subject)
+	return args.Error(0)
+}
+
+func (m *StoreMock) CreateIdentityUser(ctx context.Context, usr *User, provider, subject string) error {
+	args := m.Called(ctx, usr, provider, subject)
+	return args.Error(0)
+}
+
+func (m *StoreMock) ListUsers(ctx context.Context) ([]*User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*User), args.Error(1)
+}
+
+func (m *StoreMock) UpdateUser(ctx context.Context, id int, patch *UserPatch) (*User, error) {
+	args := m.Called(ctx, id, patch)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *StoreMock) ListSessions(ctx context.Context, usrId int) ([]*Session, error) {
+	args := m.Called(ctx, usrId)
+	return args.Get(0).([]*Session), args.Error(1)
+}
+
+func (m *StoreMock) RevokeSession(ctx context.Context, usrId, id int) error {
+	args := m.Called(ctx, usrId, id)
+	return args.Error(0)
+}
+
+func (m *StoreMock) RevokeSessions(ctx context.Context, usrId int) error {
+	args := m.Called(ctx, usrId)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetTwoFactor(ctx context.Context, usrId int) (*TwoFactor, error) {
+	args := m.Called(ctx, usrId)
+	return args.Get(0).(*TwoFactor), args.Error(1)
+}
+
+func (m *StoreMock) SaveTwoFactor(ctx context.Context, tf *TwoFactor) error {
+	args := m.Called(ctx, tf)
+	return args.Error(0)
+}
+
+func (m *StoreMock) EnableTwoFactor(ctx context.Context, usrId int, counter int64) error {
+	args := m.Called(ctx, usrId, counter)
+	return args.Error(0)
+}
+
+func (m *StoreMock) UseTOTP(ctx context.Context, usrId int, counter int64) error {
+	args := m.Called(ctx, usrId, counter)
+	return args.Error(0)
+}
+
+func (m *StoreMock) UseBackupCode(ctx context.Context, usrId int, hash string) error {
+	args := m.Called(ctx, usrId, hash)
+	return args.Error(0)
+}
+
+func (m *StoreMock) DisableTwoFactor(ctx context.Context, usrId int) error {
+	args := m.Called(ctx, usrId)
+	return args.Error(0)
+}
+
+func (m *StoreMock) GetShare(ctx context.Context, ownerId, usrId int) (*Share, error) {
+	args := m.Called(ctx, ownerId, usrId)
+	return args.Get(0).(*Share), args.Error(1)
+}
+
+func (m *StoreMock) ListShares(ctx context.Context, ownerId int) ([]*Share, error) {
+	args := m.Called(ctx, ownerId)
+	return args.Get(0).([]*Share), args.Error(1)
+}
+
+func (m *StoreMock) SaveShare(ctx context.Context, share *Share) error {
+	args := m.Called(ctx, share)
+	return args.Error(0)
+}
+
+func (m *StoreMock) DeleteShare(ctx context.Context, ownerId, usrId int) error {
+	args := m.Called(ctx, ownerId, usrId)
+	return args.Error(0)
+}
+
+func (m *StoreMock) AppendAudit(ctx context.Context, event *AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *StoreMock) ListAudit(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*AuditEvent), args.Error(1)
+}
@@ -0,0 +1,121 @@
+// Package throttle slows down guessing of passwords, logins fail fast once too many attempts
+// failed for the same username or from the same address
+package throttle
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// DefaultThreshold is how many failures in a row are tolerated by default
+	DefaultThreshold = 5
+	// DefaultDelay is the wait after the first failure over the threshold by default
+	DefaultDelay = 30 * time.Second
+	// DefaultMaxDelay bounds the waits by default
+	DefaultMaxDelay = 15 * time.Minute
+)
+
+// Throttler makes logins wait after threshold failures of a key, the wait doubles with every further
+// failure up to maxDelay. Failures are counted by the storage so that all instances share them and
+// they are forgotten once a key hasn't failed for twice maxDelay
+type Throttler struct {
+	store     storages.LoginAttemptStore
+	threshold int
+	delay     time.Duration
+	maxDelay  time.Duration
+	now       func() time.Time
+}
+
+// NewThrottler create new Throttler instance, non positive arguments give the defaults
+func NewThrottler(store storages.LoginAttemptStore, threshold int, delay, maxDelay time.Duration) *Throttler {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	if delay <= 0 {
+		delay = DefaultDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+	if maxDelay < delay {
+		maxDelay = delay
+	}
+	return &Throttler{
+		store:     store,
+		threshold: threshold,
+		delay:     delay,
+		maxDelay:  maxDelay,
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// UserKey returns the key counting failures of username
+func UserKey(username string) string {
+	return "user:" + username
+}
+
+// AddrKey returns the key counting failures from the address
+func AddrKey(addr string) string {
+	return "addr:" + addr
+}
+
+// ResetKey returns the key counting password resets asked for username, apart from its failed logins so
+// that resets don't slow those down
+func ResetKey(username string) string {
+	return "reset:" + username
+}
+
+// Wait returns how long logins of keys have to wait, zero if they may go on
+func (t *Throttler) Wait(ctx context.Context, keys ...string) (time.Duration, error) {
+	now := t.now()
+	var wait time.Duration
+	for _, key := range keys {
+		failures, lastAt, err := t.store.GetLoginFailures(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if until := lastAt.Add(t.backoff(failures)); until.Sub(now) > wait {
+			wait = until.Sub(now)
+		}
+	}
+	return wait, nil
+}
+
+// Fail counts a failed login of keys, errors are only logged since the login has failed anyway
+func (t *Throttler) Fail(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if _, err := t.store.AddLoginFailure(ctx, key, t.now(), 2*t.maxDelay); err != nil {
+			log.Println("counting login failure of", key, err)
+		}
+	}
+}
+
+// Succeed forgets the failures of keys after a successful login
+func (t *Throttler) Succeed(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := t.store.ClearLoginFailures(ctx, key); err != nil {
+			log.Println("clearing login failures of", key, err)
+		}
+	}
+}
+
+// backoff returns the wait after the last of failures
+func (t *Throttler) backoff(failures int) time.Duration {
+	if failures < t.threshold {
+		return 0
+	}
+	wait := t.delay
+	for i := t.threshold; i < failures && wait < t.maxDelay; i++ {
+		wait *= 2
+	}
+	if wait > t.maxDelay {
+		wait = t.maxDelay
+	}
+	return wait
+}
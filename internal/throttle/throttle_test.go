@@ -0,0 +1,54 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/manabie-com/togo/internal/storages/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottler(t *testing.T) {
+	requireTest := require.New(t)
+	store, err := memory.NewMemory()
+	requireTest.NoError(err)
+	ctx := context.Background()
+
+	now := time.Date(2020, 7, 29, 0, 0, 0, 0, time.UTC)
+	th := NewThrottler(store, 3, time.Second, 5*time.Second)
+	th.now = func() time.Time {
+		return now
+	}
+	keys := []string{UserKey("firstUser"), AddrKey("10.0.0.1")}
+
+	wait := func() time.Duration {
+		wait, err := th.Wait(ctx, keys...)
+		requireTest.NoError(err)
+		return wait
+	}
+
+	th.Fail(ctx, keys...)
+	th.Fail(ctx, keys...)
+	requireTest.Zero(wait())
+
+	// waits double with every failure over the threshold up to the max
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		th.Fail(ctx, keys...)
+		requireTest.Equal(expected, wait())
+	}
+	now = now.Add(2 * time.Second)
+	requireTest.Equal(3*time.Second, wait())
+
+	// a success forgets failures of the user but not of the address
+	th.Succeed(ctx, keys[0])
+	requireTest.Equal(3*time.Second, wait())
+	userWait, err := th.Wait(ctx, keys[0])
+	requireTest.NoError(err)
+	requireTest.Zero(userWait)
+
+	// failures are forgotten once the key hasn't failed for the window
+	now = now.Add(10 * time.Second)
+	th.Fail(ctx, keys...)
+	requireTest.Zero(wait())
+}
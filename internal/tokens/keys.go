@@ -0,0 +1,122 @@
+package tokens
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SigningKey is a key of a Signer identified by ID, the kid header of the tokens it signs. Key is the secret of
+// HS256 or the PEM encoded private key of RS256. Tokens are signed by the latest key whose NotBefore has passed
+// and verified by the key of their kid until its NotAfter, zero times are no bound. Keys are rotated by adding
+// the next one with a later NotBefore and retiring the previous one at least a token TTL after it
+type SigningKey struct {
+	ID        string    `json:"kid"`
+	Key       string    `json:"key"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+type signingKey struct {
+	id                 string
+	signKey, verifyKey interface{}
+	notBefore          time.Time
+	notAfter           time.Time
+}
+
+func (k *signingKey) retired(now time.Time) bool {
+	return !k.notAfter.IsZero() && !now.Before(k.notAfter)
+}
+
+// NewKeyedSigner returns a Signer of alg with keys which must have distinct ids, see SigningKey
+func NewKeyedSigner(alg string, keys []SigningKey, ttl time.Duration) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	var signer *Signer
+	ids := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.ID == "" || ids[k.ID] {
+			return nil, errors.Errorf("signing key id %q is empty or not unique", k.ID)
+		}
+		ids[k.ID] = true
+
+		s, err := NewSigner(alg, []byte(k.Key), ttl)
+		if err != nil {
+			return nil, errors.Wrap(err, k.ID)
+		}
+		key := s.keys[0]
+		key.id, key.notBefore, key.notAfter = k.ID, k.NotBefore, k.NotAfter
+		if signer == nil {
+			signer, s.keys = s, nil
+		}
+		signer.keys = append(signer.keys, key)
+	}
+
+	sort.SliceStable(signer.keys, func(i, j int) bool {
+		return signer.keys[i].notBefore.Before(signer.keys[j].notBefore)
+	})
+	return signer, nil
+}
+
+// currentKey returns the key which signs tokens at now, nil if none is active
+func (s *Signer) currentKey(now time.Time) *signingKey {
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if key := s.keys[i]; !key.notBefore.After(now) && !key.retired(now) {
+			return key
+		}
+	}
+	return nil
+}
+
+// verifyingKey returns the key of kid which is not retired at now, nil if there is none
+func (s *Signer) verifyingKey(kid string, now time.Time) *signingKey {
+	for _, key := range s.keys {
+		if key.id == kid && !key.retired(now) {
+			return key
+		}
+	}
+	return nil
+}
+
+// JWK is the public part of a RS256 key as a JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys which verify tokens of s, keys scheduled for later are included so dependents
+// know them before they sign tokens. Secrets of HS256 are never published, their set is empty
+func (s *Signer) JWKS() *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+	now := s.now()
+	for _, key := range s.keys {
+		public, ok := key.verifyKey.(*rsa.PublicKey)
+		if !ok || key.retired(now) {
+			continue
+		}
+		set.Keys = append(set.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: s.method.Alg(),
+			Kid: key.id,
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		})
+	}
+	return set
+}
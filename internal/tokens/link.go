@@ -0,0 +1,86 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultLinkTTL is how long share links are valid unless their owner tells
+	DefaultLinkTTL = 7 * 24 * time.Hour
+	// MaxLinkTTL is the longest a share link may be valid
+	MaxLinkTTL = 30 * 24 * time.Hour
+)
+
+var ErrInvalidLink = errors.New("share link is not valid")
+
+// ShareLink grants read-only access to the tasks of the user created on Date, formatted like "2006-01-02",
+// or to the tasks of the project of the user when ProjectId is set
+type ShareLink struct {
+	UsrId     int    `json:"sub"`
+	Date      string `json:"date,omitempty"`
+	ProjectId int    `json:"project_id,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// LinkSigner signs share links into tokens which are checked without the storage
+type LinkSigner struct {
+	key []byte
+	now func() time.Time
+}
+
+// NewLinkSigner create new LinkSigner instance, every instance of the service must share the key
+func NewLinkSigner(key []byte) *LinkSigner {
+	return &LinkSigner{key: key, now: time.Now}
+}
+
+// Sign returns the token of link which expires after ttl, from DefaultLinkTTL when ttl is not positive
+// up to MaxLinkTTL
+func (l *LinkSigner) Sign(link *ShareLink, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		ttl = DefaultLinkTTL
+	}
+	if ttl > MaxLinkTTL {
+		ttl = MaxLinkTTL
+	}
+	link.ExpiresAt = l.now().Add(ttl).Unix()
+	payload, err := json.Marshal(link)
+	if err != nil {
+		return "", errors.Wrap(err, "Marshal()")
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + l.sign(encoded), nil
+}
+
+// Parse returns the link of token, it returns ErrInvalidLink unless token is signed by the key and has not expired
+func (l *LinkSigner) Parse(token string) (*ShareLink, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(l.sign(parts[0]))) {
+		return nil, ErrInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+	link := &ShareLink{}
+	if err := json.Unmarshal(payload, link); err != nil {
+		return nil, ErrInvalidLink
+	}
+	if link.UsrId <= 0 || !l.now().Before(time.Unix(link.ExpiresAt, 0)) {
+		return nil, ErrInvalidLink
+	}
+	return link, nil
+}
+
+// sign tells links apart from other values signed by the same key
+func (l *LinkSigner) sign(encoded string) string {
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte("link:" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,31 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRefreshTTL is how long refresh tokens are valid by default
+const DefaultRefreshTTL = 30 * 24 * time.Hour
+
+// NewRefreshToken returns a random refresh token given to the user and the hash of it which is stored
+func NewRefreshToken() (secret, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", errors.Wrap(err, "Read()")
+	}
+	secret = base64.RawURLEncoding.EncodeToString(b)
+	return secret, HashRefreshToken(secret), nil
+}
+
+// HashRefreshToken returns the stored hash of a refresh token, tokens are random
+// so a fast hash does not make them any easier to guess
+func HashRefreshToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
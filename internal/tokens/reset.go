@@ -0,0 +1,17 @@
+package tokens
+
+import "time"
+
+// DefaultResetTTL is how long password reset tokens are valid by default
+const DefaultResetTTL = time.Hour
+
+// NewResetToken returns a random password reset token mailed to the user and the hash of it which is stored,
+// it's made like a refresh token
+func NewResetToken() (secret, hash string, err error) {
+	return NewRefreshToken()
+}
+
+// HashResetToken returns the stored hash of a password reset token
+func HashResetToken(secret string) string {
+	return HashRefreshToken(secret)
+}
@@ -0,0 +1,155 @@
+// Package tokens issues and verifies the signed JWTs which authenticate users,
+// verifying a token needs only the key so requests are authenticated without the storage
+package tokens
+
+import (
+	"crypto/rsa"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// DefaultTTL is how long tokens are valid by default
+const DefaultTTL = 15 * time.Minute
+
+var (
+	ErrInvalidToken = errors.New("auth token is not valid")
+	ErrUnknownAlg   = errors.New("unknown signing algorithm")
+	ErrNoSigningKey = errors.New("no signing key is active")
+)
+
+// Scopes limit what a token may be used for, tokens without scopes may be used for anything their user may do
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+	ScopeAdmin      = "admin"
+)
+
+// Claims are carried by tokens, UserId is the subject. Role is empty for ordinary users,
+// Scope is the space separated scopes of the token
+type Claims struct {
+	UserId    int    `json:"sub"`
+	MaxTodo   int    `json:"max_todo"`
+	Role      string `json:"role,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Scopes returns the scopes of the token, nil for a token without scopes
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// Valid rejects expired tokens and tokens issued in the future
+func (c *Claims) Valid() error {
+	return jwt.StandardClaims{IssuedAt: c.IssuedAt, ExpiresAt: c.ExpiresAt}.Valid()
+}
+
+// Signer signs tokens with a single algorithm and only accepts tokens of that algorithm,
+// so a token can't pick how it's verified. Keys are picked by the kid header, see NewKeyedSigner
+type Signer struct {
+	method jwt.SigningMethod
+	// keys are sorted by NotBefore
+	keys []*signingKey
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewHS256 returns a Signer of HMAC-SHA256 tokens, non positive ttl gives DefaultTTL
+func NewHS256(key []byte, ttl time.Duration) *Signer {
+	return newSigner(jwt.SigningMethodHS256, key, key, ttl)
+}
+
+// NewRS256 returns a Signer of RSA-SHA256 tokens, they are verified by the public part of key
+func NewRS256(key *rsa.PrivateKey, ttl time.Duration) *Signer {
+	return newSigner(jwt.SigningMethodRS256, key, &key.PublicKey, ttl)
+}
+
+// NewSigner returns a Signer of alg, HS256 takes the secret as key and RS256 a PEM encoded private key
+func NewSigner(alg string, key []byte, ttl time.Duration) (*Signer, error) {
+	switch alg {
+	case "", jwt.SigningMethodHS256.Alg():
+		return NewHS256(key, ttl), nil
+	case jwt.SigningMethodRS256.Alg():
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "ParseRSAPrivateKeyFromPEM()")
+		}
+		return NewRS256(privateKey, ttl), nil
+	default:
+		return nil, ErrUnknownAlg
+	}
+}
+
+func newSigner(method jwt.SigningMethod, signKey, verifyKey interface{}, ttl time.Duration) *Signer {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	key := &signingKey{signKey: signKey, verifyKey: verifyKey}
+	return &Signer{method: method, keys: []*signingKey{key}, ttl: ttl, now: time.Now}
+}
+
+// Alg returns the name of the signing algorithm
+func (s *Signer) Alg() string {
+	return s.method.Alg()
+}
+
+// TTL returns how long issued tokens are valid
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue returns a token of the user which expires after the ttl of s
+func (s *Signer) Issue(userId, maxTodo int, role string) (string, error) {
+	return s.IssueScoped(userId, maxTodo, role, nil, s.ttl)
+}
+
+// IssueScoped returns a token of the user limited to scopes which expires after ttl
+func (s *Signer) IssueScoped(userId, maxTodo int, role string, scopes []string, ttl time.Duration) (string, error) {
+	now := s.now()
+	claims := &Claims{
+		UserId:    userId,
+		MaxTodo:   maxTodo,
+		Role:      role,
+		Scope:     strings.Join(scopes, " "),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+	key := s.currentKey(now)
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(s.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+	signed, err := token.SignedString(key.signKey)
+	if err != nil {
+		return "", errors.Wrap(err, "SignedString()")
+	}
+	return signed, nil
+}
+
+// Verify returns the claims of token, it returns ErrInvalidToken unless token is signed
+// by the key of its kid and the algorithm of s and has not expired
+func (s *Signer) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != s.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		kid, _ := token.Header["kid"].(string)
+		key := s.verifyingKey(kid, s.now())
+		if key == nil {
+			return nil, ErrInvalidToken
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil || !parsed.Valid || claims.UserId == 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
@@ -0,0 +1,152 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerHS256(t *testing.T) {
+	requireTest := require.New(t)
+	signer := NewHS256([]byte("secret"), time.Minute)
+
+	token, err := signer.Issue(1, 5, "")
+	requireTest.NoError(err)
+	claims, err := signer.Verify(token)
+	requireTest.NoError(err)
+	requireTest.Equal(1, claims.UserId)
+	requireTest.Equal(5, claims.MaxTodo)
+
+	_, err = NewHS256([]byte("other"), time.Minute).Verify(token)
+	requireTest.Equal(ErrInvalidToken, err)
+
+	signer.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	expired, err := signer.Issue(1, 5, "")
+	requireTest.NoError(err)
+	_, err = signer.Verify(expired)
+	requireTest.Equal(ErrInvalidToken, err)
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, &Claims{UserId: 1, ExpiresAt: time.Now().Add(time.Hour).Unix()}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	requireTest.NoError(err)
+	_, err = signer.Verify(none)
+	requireTest.Equal(ErrInvalidToken, err)
+}
+
+func TestSignerRS256(t *testing.T) {
+	requireTest := require.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	requireTest.NoError(err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	signer, err := NewSigner("RS256", keyPEM, 0)
+	requireTest.NoError(err)
+	requireTest.Equal("RS256", signer.Alg())
+
+	token, err := signer.Issue(2, 3, "admin")
+	requireTest.NoError(err)
+	claims, err := signer.Verify(token)
+	requireTest.NoError(err)
+	requireTest.Equal(2, claims.UserId)
+	requireTest.Equal("admin", claims.Role)
+
+	// a HS256 token keyed by the public key must not pass for a RS256 one
+	publicPEM, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	requireTest.NoError(err)
+	forged, err := NewHS256(publicPEM, 0).Issue(2, 3, "")
+	requireTest.NoError(err)
+	_, err = signer.Verify(forged)
+	requireTest.Equal(ErrInvalidToken, err)
+
+	_, err = NewSigner("RS256", []byte("not a key"), 0)
+	requireTest.Error(err)
+	_, err = NewSigner("ES256", keyPEM, 0)
+	requireTest.Equal(ErrUnknownAlg, err)
+}
+
+func TestLinkSigner(t *testing.T) {
+	requireTest := require.New(t)
+	now := time.Date(2020, 7, 29, 0, 0, 0, 0, time.UTC)
+	signer := NewLinkSigner([]byte("key"))
+	signer.now = func() time.Time {
+		return now
+	}
+
+	token, err := signer.Sign(&ShareLink{UsrId: 1, Date: "2020-07-29"}, 0)
+	requireTest.NoError(err)
+	link, err := signer.Parse(token)
+	requireTest.NoError(err)
+	requireTest.Equal(&ShareLink{UsrId: 1, Date: "2020-07-29", ExpiresAt: now.Add(DefaultLinkTTL).Unix()}, link)
+
+	_, err = NewLinkSigner([]byte("other key")).Parse(token)
+	requireTest.Equal(ErrInvalidLink, err)
+	_, err = signer.Parse(token + "x")
+	requireTest.Equal(ErrInvalidLink, err)
+
+	token, err = signer.Sign(&ShareLink{UsrId: 1, ProjectId: 2}, 365*24*time.Hour)
+	requireTest.NoError(err)
+	now = now.Add(MaxLinkTTL - time.Second)
+	_, err = signer.Parse(token)
+	requireTest.NoError(err)
+	now = now.Add(time.Second)
+	_, err = signer.Parse(token)
+	requireTest.Equal(ErrInvalidLink, err)
+}
+
+func TestKeyedSigner(t *testing.T) {
+	requireTest := require.New(t)
+	newKey := func() string {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		requireTest.NoError(err)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	}
+	kid := func(token string) interface{} {
+		parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Claims{})
+		requireTest.NoError(err)
+		return parsed.Header["kid"]
+	}
+
+	now := time.Now()
+	keys := []SigningKey{
+		{ID: "2", Key: newKey(), NotBefore: now.Add(-time.Minute)},
+		{ID: "1", Key: newKey(), NotAfter: now.Add(time.Hour)},
+	}
+	signer, err := NewKeyedSigner("RS256", keys, time.Hour)
+	requireTest.NoError(err)
+	requireTest.Len(signer.JWKS().Keys, 2)
+
+	signer.now = func() time.Time { return now.Add(-2 * time.Minute) }
+	old, err := signer.Issue(1, 5, "")
+	requireTest.NoError(err)
+	requireTest.Equal("1", kid(old))
+
+	// the next key signs once it's active, tokens of the previous one remain valid until it's retired
+	signer.now = time.Now
+	token, err := signer.Issue(1, 5, "")
+	requireTest.NoError(err)
+	requireTest.Equal("2", kid(token))
+	_, err = signer.Verify(token)
+	requireTest.NoError(err)
+	_, err = signer.Verify(old)
+	requireTest.NoError(err)
+
+	signer.now = func() time.Time { return now.Add(time.Hour) }
+	_, err = signer.Verify(old)
+	requireTest.Equal(ErrInvalidToken, err)
+	_, err = signer.Verify(token)
+	requireTest.NoError(err)
+	requireTest.Len(signer.JWKS().Keys, 1)
+	requireTest.Equal("2", signer.JWKS().Keys[0].Kid)
+
+	_, err = NewKeyedSigner("RS256", []SigningKey{{ID: "1", Key: keys[0].Key}, {ID: "1", Key: keys[1].Key}}, 0)
+	requireTest.Error(err)
+	_, err = NewKeyedSigner("HS256", nil, 0)
+	requireTest.Equal(ErrNoSigningKey, err)
+	requireTest.Empty(NewHS256([]byte("secret"), 0).JWKS().Keys)
+}
@@ -0,0 +1,113 @@
+// Package totp implements the time-based one-time passwords of RFC 6238 which authenticator apps
+// generate, along with the single-use backup codes which replace them when the device is lost
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Period is how long a code is valid, Digits is its length
+	Period = 30 * time.Second
+	Digits = 6
+	// Skew is how many periods before and after the current one are accepted to allow for clock drift
+	Skew = 1
+	// BackupCodes is how many backup codes are given at enrollment
+	BackupCodes = 10
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random base32 encoded secret of 160 bits as RFC 4226 recommends
+func NewSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "Read()")
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// URL returns the otpauth:// URL of the secret of account, the payload authenticator apps read from QR codes
+func URL(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period/time.Second)))
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + issuer + ":" + account, RawQuery: params.Encode()}
+	return u.String()
+}
+
+// Counter returns the period of t
+func Counter(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of secret for the period counter
+func Code(secret string, counter int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", errors.Wrap(err, "DecodeString()")
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	_, _ = mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%1000000), nil
+}
+
+// Validate returns the period for which code of secret is valid at now, ok is false if it's valid for none
+func Validate(secret, code string, now time.Time) (counter int64, ok bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+	current := Counter(now)
+	for counter := current - Skew; counter <= current+Skew; counter++ {
+		want, err := Code(secret, counter)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return counter, true
+		}
+	}
+	return 0, false
+}
+
+// NewBackupCodes returns n random codes to give to the user and their hashes to store
+func NewBackupCodes(n int) (codes, hashes []string, err error) {
+	for i := 0; i < n; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, errors.Wrap(err, "Read()")
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes = append(codes, code)
+		hashes = append(hashes, HashBackupCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashBackupCode returns the hash under which code is stored, codes are random so a fast hash is enough
+func HashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,60 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	// test vectors of RFC 6238 for SHA1, truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		at   int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		code, err := Code(secret, Counter(time.Unix(tt.at, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tt.want, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	requireTest := require.New(t)
+	secret, err := NewSecret()
+	requireTest.NoError(err)
+
+	now := time.Unix(1600000000, 0)
+	code, err := Code(secret, Counter(now.Add(-Period)))
+	requireTest.NoError(err)
+	counter, ok := Validate(secret, code, now)
+	requireTest.True(ok)
+	requireTest.Equal(Counter(now)-1, counter)
+
+	_, ok = Validate(secret, code, now.Add(2*Period))
+	requireTest.False(ok)
+	_, ok = Validate(secret, "12345", now)
+	requireTest.False(ok)
+}
+
+func TestURL(t *testing.T) {
+	require.Equal(t,
+		"otpauth://totp/togo:firstUser?algorithm=SHA1&digits=6&issuer=togo&period=30&secret=JBSWY3DPEHPK3PXP",
+		URL("togo", "firstUser", "JBSWY3DPEHPK3PXP"))
+}
+
+func TestBackupCodes(t *testing.T) {
+	codes, hashes, err := NewBackupCodes(BackupCodes)
+	require.NoError(t, err)
+	require.Len(t, codes, BackupCodes)
+	require.Equal(t, hashes[0], HashBackupCode(" "+codes[0]+" "))
+	require.NotEqual(t, codes[0], codes[1])
+}
@@ -0,0 +1,100 @@
+// Package tracing sets up OpenTelemetry: spans of the service are exported to an OTLP collector and the trace
+// context of requests is propagated by the W3C traceparent and baggage headers
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlphttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// Config tells where spans are exported to
+type Config struct {
+	// Endpoint is the host:port of the collector, nothing is exported when it's empty
+	Endpoint string
+	// Protocol is grpc or http, grpc by default
+	Protocol string
+	Insecure bool
+	Headers  map[string]string
+
+	ServiceName string
+	// ServiceVersion is the version of the build spans are exported by
+	ServiceVersion string
+	// SampleRatio is the fraction of the traces started by the service that are sampled, traces of
+	// incoming requests are sampled as their callers tell
+	SampleRatio float64
+}
+
+// Setup installs the propagator and, if config has an endpoint, a tracer provider exporting to it as
+// the global ones. The returned func flushes the spans left and stops the exporter
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var driver otlp.ProtocolDriver
+	switch config.Protocol {
+	case "", "grpc":
+		opts := []otlpgrpc.Option{otlpgrpc.WithEndpoint(config.Endpoint), otlpgrpc.WithHeaders(config.Headers)}
+		if config.Insecure {
+			opts = append(opts, otlpgrpc.WithInsecure())
+		}
+		driver = otlpgrpc.NewDriver(opts...)
+	case "http":
+		opts := []otlphttp.Option{otlphttp.WithEndpoint(config.Endpoint), otlphttp.WithHeaders(config.Headers)}
+		if config.Insecure {
+			opts = append(opts, otlphttp.WithInsecure())
+		}
+		driver = otlphttp.NewDriver(opts...)
+	default:
+		return nil, fmt.Errorf("tracing: unknown protocol %q", config.Protocol)
+	}
+	exporter, err := otlp.NewExporter(ctx, driver)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "togo"
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(config.ServiceVersion),
+		)),
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio)),
+		}),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// ParseHeaders parses headers sent to the collector written as key=value pairs separated by commas
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			return nil, fmt.Errorf("tracing: invalid header %q", pair)
+		}
+		headers[key] = strings.TrimSpace(kv[1])
+	}
+	return headers, nil
+}
@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	requireTest := require.New(t)
+
+	// without an endpoint only the trace context is propagated
+	shutdown, err := Setup(context.Background(), Config{})
+	requireTest.NoError(err)
+	requireTest.NoError(shutdown(context.Background()))
+	requireTest.ElementsMatch([]string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+
+	_, err = Setup(context.Background(), Config{Endpoint: "localhost:4317", Protocol: "thrift"})
+	requireTest.Error(err)
+}
+
+func TestParseHeaders(t *testing.T) {
+	requireTest := require.New(t)
+
+	headers, err := ParseHeaders("api-key=secret, x-tenant = togo,")
+	requireTest.NoError(err)
+	requireTest.Equal(map[string]string{"api-key": "secret", "x-tenant": "togo"}, headers)
+
+	headers, err = ParseHeaders("")
+	requireTest.NoError(err)
+	requireTest.Empty(headers)
+
+	_, err = ParseHeaders("api-key")
+	requireTest.Error(err)
+}
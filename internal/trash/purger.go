@@ -0,0 +1,100 @@
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/manabie-com/togo/internal/storages"
+)
+
+const (
+	// DefaultRetention is how long deleted tasks are kept in the trash by default
+	DefaultRetention = 30 * 24 * time.Hour
+	// DefaultInterval is how often the trash is purged by default
+	DefaultInterval = time.Hour
+
+	// batchSize is how many tasks are purged per transaction
+	batchSize = 100
+)
+
+// Purger periodically deletes tasks which have been in the trash for longer than the retention
+// along with the blobs of their attachments, several instances may run against the same storage
+type Purger struct {
+	trasher   storages.TaskTrasher
+	blobs     blobs.Store
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewPurger create new Purger instance, blobs may be nil when attachments are disabled.
+// Non positive retention and interval give DefaultRetention and DefaultInterval
+func NewPurger(trasher storages.TaskTrasher, blobs blobs.Store, retention, interval time.Duration) *Purger {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Purger{
+		trasher:   trasher,
+		blobs:     blobs,
+		retention: retention,
+		interval:  interval,
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// Run purges the trash once immediately and then every interval until ctx is done
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	before := p.now().Add(-p.retention)
+	total := 0
+	for {
+		n, attachments, err := p.trasher.PurgeTrash(ctx, before, batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Println("purging trash failed", err)
+			}
+			break
+		}
+		total += n
+		p.deleteBlobs(ctx, attachments)
+		if n < batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("purged %d tasks from trash", total)
+	}
+}
+
+// deleteBlobs removes contents of purged attachments, a failure leaves an orphan blob which is only logged
+func (p *Purger) deleteBlobs(ctx context.Context, attachments []*storages.Attachment) {
+	if p.blobs == nil {
+		return
+	}
+	for _, attachment := range attachments {
+		if err := p.blobs.Delete(ctx, attachment.BlobKey); err != nil {
+			log.Println("deleting blob", attachment.BlobKey, "failed", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package trash
+
+import (
+	"context"
+	"github.com/manabie-com/togo/internal/blobs"
+	"github.com/manabie-com/togo/internal/blobs/local"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPurgerPurge(t *testing.T) {
+	requireTest := require.New(t)
+	now := time.Date(2020, 7, 29, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-DefaultRetention)
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "trash")
+	requireTest.NoError(err)
+	defer os.RemoveAll(dir)
+	store, err := local.NewLocal(dir)
+	requireTest.NoError(err)
+	requireTest.NoError(store.Put(ctx, "tasks/1/a", strings.NewReader("hello")))
+
+	// purging goes on while batches are full
+	db := new(storages.StoreMock)
+	db.On("PurgeTrash", ctx, before, batchSize).Return(batchSize, []*storages.Attachment{{TaskId: 1, BlobKey: "tasks/1/a"}}, nil).Once()
+	db.On("PurgeTrash", ctx, before, batchSize).Return(1, []*storages.Attachment{}, nil).Once()
+
+	p := NewPurger(db, store, 0, 0)
+	p.now = func() time.Time {
+		return now
+	}
+	p.purge(ctx)
+
+	_, err = store.Get(ctx, "tasks/1/a")
+	requireTest.Equal(blobs.ErrNotFound, err)
+	db.AssertExpectations(t)
+}
+
+func TestNewPurgerDefaults(t *testing.T) {
+	p := NewPurger(new(storages.StoreMock), nil, 0, -1)
+	require.Equal(t, DefaultRetention, p.retention)
+	require.Equal(t, DefaultInterval, p.interval)
+}
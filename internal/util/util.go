@@ -1,6 +1,11 @@
 package util
 
-import "os"
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
 
 func GetEnv(key string, defaultVal string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -9,3 +14,33 @@ func GetEnv(key string, defaultVal string) string {
 		return defaultVal
 	}
 }
+
+// GetEnvInt returns env value as int, defaultVal is returned if it's missing or invalid
+func GetEnvInt(key string, defaultVal int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid %s: %v, use %d", key, err, defaultVal)
+		return defaultVal
+	}
+	return i
+}
+
+// GetEnvDuration returns env value as time.Duration, defaultVal is returned if it's missing or invalid
+func GetEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %s: %v, use %s", key, err, defaultVal)
+		return defaultVal
+	}
+	return d
+}
@@ -0,0 +1,44 @@
+// Package version tells which build of the service is running. Version, Commit and BuildTime are set when
+// building by the linker, e.g.
+//
+//	go build -ldflags "-X github.com/manabie-com/togo/internal/version.Version=v1.2.0
+//	    -X github.com/manabie-com/togo/internal/version.Commit=$(git rev-parse HEAD)
+//	    -X github.com/manabie-com/togo/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version is the release of the build, the version of the module when it's built by go install and dev
+	// otherwise
+	Version = ""
+	// Commit is the hash of the commit built, BuildTime when it was built in RFC 3339
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info is the build of the service
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build of the service
+func Get() Info {
+	return Info{Version: current(), Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+}
+
+func current() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
@@ -0,0 +1,19 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	requireTest := require.New(t)
+	requireTest.Equal(Info{Version: "dev", GoVersion: runtime.Version()}, Get())
+
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+	Version, Commit, BuildTime = "v1.2.0", "0a1b2c3", "2021-03-01T08:00:00Z"
+	requireTest.Equal(Info{Version: "v1.2.0", Commit: "0a1b2c3", BuildTime: "2021-03-01T08:00:00Z", GoVersion: runtime.Version()}, Get())
+}
@@ -2,67 +2,891 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/manabie-com/togo/internal/accesslog"
+	"github.com/manabie-com/togo/internal/blobs"
+	_ "github.com/manabie-com/togo/internal/blobs/local"
+	_ "github.com/manabie-com/togo/internal/blobs/s3"
+	"github.com/manabie-com/togo/internal/config"
+	"github.com/manabie-com/togo/internal/logging"
+	"github.com/manabie-com/togo/internal/mailer"
+	"github.com/manabie-com/togo/internal/metrics"
+	"github.com/manabie-com/togo/internal/oidc"
+	"github.com/manabie-com/togo/internal/ratelimit"
+	"github.com/manabie-com/togo/internal/recurrence"
+	"github.com/manabie-com/togo/internal/reminders"
+	"github.com/manabie-com/togo/internal/reporting"
+	_ "github.com/manabie-com/togo/internal/secrets/awssm"
+	_ "github.com/manabie-com/togo/internal/secrets/vault"
 	"github.com/manabie-com/togo/internal/services"
+	"github.com/manabie-com/togo/internal/storages"
+	"github.com/manabie-com/togo/internal/storages/dualwrite"
+	_ "github.com/manabie-com/togo/internal/storages/dynamo"
+	_ "github.com/manabie-com/togo/internal/storages/memory"
+	_ "github.com/manabie-com/togo/internal/storages/mongo"
+	_ "github.com/manabie-com/togo/internal/storages/mysql"
 	"github.com/manabie-com/togo/internal/storages/postgres"
-	"github.com/manabie-com/togo/internal/util"
+	_ "github.com/manabie-com/togo/internal/storages/redis"
+	_ "github.com/manabie-com/togo/internal/storages/sqlite"
+	"github.com/manabie-com/togo/internal/throttle"
+	"github.com/manabie-com/togo/internal/tokens"
+	"github.com/manabie-com/togo/internal/tracing"
+	"github.com/manabie-com/togo/internal/trash"
+	"github.com/manabie-com/togo/internal/version"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
+	// the image has no zoneinfo for the timezones of users
+	_ "time/tzdata"
 )
 
 func main() {
+	// togo runs the subcommands of commands.go, serve when none is given
+	if err := run(os.Args[1:]); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+// serve serves the API until it's interrupted, seed tells the fixtures seeded on start if it isn't empty. It
+// returns the error which kept it from starting or stopped the servers
+func serve(cfg *config.Config, seed string) error {
+	// LOG_LEVEL and LOG_FORMAT (json or console) shape the structured logs, the standard logger writes to them too
+	logger, logLevel, err := logging.New(cfg.String("LOG_LEVEL"), cfg.String("LOG_FORMAT"))
+	if err != nil {
+		return fmt.Errorf("error building logger: %v", err)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	// What is logged at error level, panics and internal errors answered to requests among them, is reported to
+	// the Sentry-compatible service of SENTRY_DSN tagged by SENTRY_RELEASE, the version by default, and
+	// SENTRY_ENVIRONMENT
+	reporter, err := setupReporting(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("error setting up error reporting: %v", err)
+	}
+	if reporter != nil {
+		logger = logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, reporting.NewCore(reporter, zapcore.ErrorLevel))
+		}))
+		defer reporter.Flush(5 * time.Second)
+	}
+	// Every line carries the version of the build, see internal/version for setting it
+	build := version.Get()
+	logger = logger.With(zap.String("version", build.Version))
+	zap.ReplaceGlobals(logger)
+	zap.RedirectStdLog(logger)
+	logger.Info("starting togo", zap.String("commit", build.Commit), zap.String("build_time", build.BuildTime),
+		zap.String("go_version", build.GoVersion))
+	// The settings which aren't defaults are logged at start, secrets only as set
+	var settings []zap.Field
+	for _, setting := range cfg.Effective() {
+		if setting.Source != config.SourceDefault {
+			settings = append(settings, zap.String(setting.Name, setting.Value))
+		}
+	}
+	logger.Info("effective config", settings...)
+
+	// Spans are exported to the OTLP collector of OTEL_EXPORTER_OTLP_ENDPOINT by grpc or http as
+	// OTEL_EXPORTER_OTLP_PROTOCOL tells, OTEL_TRACES_SAMPLER_ARG of the traces started here are sampled
+	shutdownTracing, err := setupTracing(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("error setting up tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Println("error flushing spans", err)
+		}
+	}()
+
+	dbConfig, err := storageConfig(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("error reading storage config: %v", err)
+	}
+
+	// SIGTERM is how orchestrators such as docker and kubernetes stop the app
 	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
+	// New db instance, the storage driver is chosen from env
+	db, err := storages.Open(context.Background(), dbConfig)
+	if err != nil {
+		return fmt.Errorf("error opening db: %v", err)
+	}
+	// Close db once nothing uses it, this closes the pgx pool
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Println(err)
+		}
+		log.Println("|――db was shut down")
+	}()
 
-	// Postgres config from env
-	config := &postgres.Config{
-		Host: util.GetEnv("POSTGRES_HOST", "localhost"),
-		Port: util.GetEnv("POSTGRES_PORT", "5432"),
-		Usr:  util.GetEnv("POSTGRES_USER", "togo"),
-		Pwd:  util.GetEnv("POSTGRES_PASSWORD", "togo"),
-		Db:   util.GetEnv("POSTGRES_DB", "togo"),
+	// Tasks count towards limits by calendar day unless another policy is deployed, QUOTA_BURST such as 2/24h
+	// lets users go over them by a few tasks once in a while
+	if err := setQuota(cfg, db); err != nil {
+		return fmt.Errorf("error setting quota: %v", err)
 	}
+	// the quotas of both storages change on reload, dualwrite doesn't set them
+	quotaStores := []storages.Store{db}
 
-	// New postgres db instance
-	pg, err := postgres.NewPostgres(context.WithValue(context.Background(), "config", config))
+	if seed != "" {
+		if err := seedFixtures(context.Background(), db, seed); err != nil {
+			return fmt.Errorf("seeding failed: %v", err)
+		}
+	}
+
+	// Also write to secondary db while migrating to it, dualwrite only mirrors new tasks and forwards the rest
+	// to primary
+	if driver := cfg.String("STORAGE_SECONDARY_DRIVER"); driver != "" {
+		if dropped := dualwrite.Dropped(db); len(dropped) > 0 {
+			logger.Warn("dualwrite hides optional interfaces of primary db", zap.String("driver", dbConfig.Driver),
+				zap.Strings("dropped", dropped))
+		}
+		dsn, err := cfg.Secret(context.Background(), "STORAGE_SECONDARY_DSN")
+		if err != nil {
+			return fmt.Errorf("error reading secondary db dsn: %v", err)
+		}
+		secondary, err := storages.Open(context.Background(), &storages.Config{
+			Driver: driver,
+			DSN:    dsn,
+		})
+		if err != nil {
+			return fmt.Errorf("error opening secondary db: %v", err)
+		}
+		if err := setQuota(cfg, secondary); err != nil {
+			_ = secondary.Close()
+			return fmt.Errorf("error setting quota of secondary db: %v", err)
+		}
+		quotaStores = append(quotaStores, secondary)
+		db = dualwrite.Wrap(db, secondary, cfg.Bool("STORAGE_CONSISTENCY_CHECK"))
+	}
+
+	// Materialize recurring tasks and deliver reminders in background
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	// Stop recurrence scheduler, reminder dispatcher and trash purger before db is closed
+	defer func() {
+		stopScheduler()
+		background.Wait()
+		log.Println("|――recurrence scheduler, reminder dispatcher and trash purger were stopped")
+	}()
+	if recurrer, ok := db.(storages.TaskRecurrer); ok {
+		scheduler := recurrence.NewScheduler(recurrer, cfg.Duration("RECURRENCE_INTERVAL"))
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			scheduler.Run(schedulerCtx)
+		}()
+	}
+
+	// Deliver due reminders in background, by webhook if one is configured
+	if reminder, ok := db.(storages.TaskReminder); ok {
+		var notifier reminders.Notifier = reminders.LogNotifier
+		if url := cfg.String("REMINDER_WEBHOOK_URL"); url != "" {
+			secret, err := cfg.Secret(context.Background(), "REMINDER_WEBHOOK_SECRET")
+			if err != nil {
+				return fmt.Errorf("error reading reminder webhook secret: %v", err)
+			}
+			notifier = reminders.NewWebhook(url, secret)
+		}
+		dispatcher := reminders.NewDispatcher(reminder, notifier, cfg.Duration("REMINDER_INTERVAL"))
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			dispatcher.Run(schedulerCtx)
+		}()
+	}
+
+	// Admins change LOG_LEVEL at /admin/log-level, or log the requests of a user or a route at debug level for a while
+	opts := []services.Option{services.WithLogger(logger), services.WithLogLevel(logLevel)}
+	// Metrics are served at /metrics unless METRICS_ENABLED=false, along with the pool and statements of Postgres
+	if cfg.Bool("METRICS_ENABLED") {
+		m := metrics.New()
+		if stater, ok := db.(storages.PoolStater); ok {
+			if err := m.RegisterPool(stater); err != nil {
+				log.Println("error registering pool metrics", err)
+			}
+		}
+		if timer, ok := db.(storages.StatementTimer); ok {
+			timer.TimeStatements(m.ObserveStatement)
+		}
+		opts = append(opts, services.WithMetrics(m))
+	}
+	// Attachments are enabled by choosing a blob store
+	var blobStore blobs.Store
+	if driver := cfg.String("BLOB_DRIVER"); driver != "" {
+		store, err := blobs.Open(context.Background(), &blobs.Config{
+			Driver: driver,
+			DSN:    cfg.String("BLOB_DSN"),
+		})
+		if err != nil {
+			return fmt.Errorf("error opening blob store: %v", err)
+		}
+		blobStore = store
+		opts = append(opts, services.WithAttachments(store, int64(cfg.Int("ATTACHMENT_MAX_SIZE"))))
+	}
+
+	// Purge tasks kept in the trash longer than the retention period in background
+	if trasher, ok := db.(storages.TaskTrasher); ok {
+		purger := trash.NewPurger(trasher, blobStore, cfg.Duration("TRASH_RETENTION"), cfg.Duration("TRASH_PURGE_INTERVAL"))
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			purger.Run(schedulerCtx)
+		}()
+	}
+
+	// Tokens are signed by JWT_KEY (HS256) or by the private key of JWT_PRIVATE_KEY_FILE (RS256),
+	// or by the rotated keys of JWT_KEYS
+	key, err := cfg.Secret(context.Background(), "JWT_KEY")
 	if err != nil {
-		log.Println("error opening db", err)
-		return
+		return fmt.Errorf("error reading jwt key: %v", err)
+	}
+	jwtKey := []byte(key)
+	if file := cfg.String("JWT_PRIVATE_KEY_FILE"); file != "" {
+		if jwtKey, err = ioutil.ReadFile(file); err != nil {
+			return fmt.Errorf("error reading jwt private key: %v", err)
+		}
+	}
+	signer, err := tokenSigner(context.Background(), cfg, jwtKey)
+	if err != nil {
+		return fmt.Errorf("error creating jwt signer: %v", err)
+	}
+	opts = append(opts, services.WithTokenSigner(signer))
+	if ttl := cfg.Duration("REFRESH_TOKEN_TTL"); ttl > 0 {
+		opts = append(opts, services.WithRefreshTTL(ttl))
+	}
+	// Share links are signed by SHARE_LINK_KEY, by JWT_KEY unless it's set
+	if key, err := cfg.Secret(context.Background(), "SHARE_LINK_KEY"); err != nil {
+		return fmt.Errorf("error reading share link key: %v", err)
+	} else if key != "" {
+		opts = append(opts, services.WithShareLinkKey([]byte(key)))
+	}
+
+	// Browser origins of CORS_ALLOWED_ORIGINS may call the API, any origin may without credentials by default
+	if cors := corsPolicy(cfg); len(cors.AllowedOrigins) > 0 {
+		opts = append(opts, services.WithCORS(cors))
+	}
+
+	// Unversioned paths announce LEGACY_API_SUNSET, an RFC 3339 time, as the end of their support
+	if sunset := cfg.Time("LEGACY_API_SUNSET"); !sunset.IsZero() {
+		opts = append(opts, services.WithLegacySunset(sunset))
+	}
+
+	// Browsers get tokens as cookies too when AUTH_COOKIES is true, cookies are Secure unless AUTH_COOKIE_SECURE is false
+	if cfg.Bool("AUTH_COOKIES") {
+		sameSite := map[string]http.SameSite{
+			"strict": http.SameSiteStrictMode,
+			"none":   http.SameSiteNoneMode,
+		}[cfg.String("AUTH_COOKIE_SAMESITE")]
+		opts = append(opts, services.WithCookieAuth(services.CookieAuth{
+			Secure:   cfg.Bool("AUTH_COOKIE_SECURE"),
+			Domain:   cfg.String("AUTH_COOKIE_DOMAIN"),
+			SameSite: sameSite,
+		}))
+	}
+
+	// Security events are recorded by storages which keep an audit log
+	if auditLog, ok := db.(storages.AuditStore); ok {
+		opts = append(opts, services.WithAuditLog(auditLog))
+	}
+
+	// Logins wait exponentially longer after LOGIN_MAX_FAILURES failures of a username or an address
+	if attempts, ok := db.(storages.LoginAttemptStore); ok {
+		throttler := throttle.NewThrottler(attempts,
+			cfg.Int("LOGIN_MAX_FAILURES"),
+			cfg.Duration("LOGIN_LOCKOUT"),
+			cfg.Duration("LOGIN_MAX_LOCKOUT"),
+		)
+		opts = append(opts, services.WithLoginThrottle(throttler))
+	}
+
+	// Users may log in at the identity providers of AUTH_PROVIDERS, e.g. "google,github"
+	if names := cfg.List("AUTH_PROVIDERS"); len(names) > 0 {
+		providers, err := identityProviders(context.Background(), cfg, names)
+		if err != nil {
+			return fmt.Errorf("error configuring identity providers: %v", err)
+		}
+		stateKey, err := cfg.Secret(context.Background(), "AUTH_STATE_KEY")
+		if err != nil {
+			return fmt.Errorf("error reading auth state key: %v", err)
+		}
+		if stateKey == "" {
+			stateKey = string(jwtKey)
+		}
+		opts = append(opts, services.WithIdentityProviders(providers, []byte(stateKey)))
+	}
+
+	// Password resets are enabled once a mailer is configured, the log mailer is only meant for development
+	if cfg.String("PASSWORD_RESET_MAILER") == "log" {
+		opts = append(opts, services.WithMailer(mailer.Log))
+	}
+	if ttl := cfg.Duration("PASSWORD_RESET_TTL"); ttl > 0 {
+		opts = append(opts, services.WithResetTTL(ttl))
+	}
+	if ttl := cfg.Duration("IDEMPOTENCY_TTL"); ttl > 0 {
+		opts = append(opts, services.WithIdempotencyTTL(ttl))
+	}
+
+	// Limits of request bodies, of handlers and of connections
+	opts = append(opts,
+		services.WithMaxBodySize(int64(cfg.Int("HTTP_MAX_BODY_SIZE"))),
+		services.WithHandlerTimeout(cfg.Duration("HTTP_HANDLER_TIMEOUT")),
+		services.WithServerTimeouts(
+			cfg.Duration("HTTP_READ_HEADER_TIMEOUT"),
+			cfg.Duration("HTTP_READ_TIMEOUT"),
+			cfg.Duration("HTTP_WRITE_TIMEOUT"),
+			cfg.Duration("HTTP_IDLE_TIMEOUT"),
+		),
+	)
+	routeTimeouts, err := parseRouteTimeouts(cfg.List("HTTP_ROUTE_TIMEOUTS"))
+	if err != nil {
+		return fmt.Errorf("error reading HTTP_ROUTE_TIMEOUTS: %v", err)
+	}
+	opts = append(opts, routeTimeouts...)
+
+	// Requests are limited to RATE_LIMIT_ADDR per address and to RATE_LIMIT_USER per signed in user, such as
+	// "100/1m". Instances share their buckets in Redis at RATE_LIMIT_REDIS_URL, each keeps its own otherwise
+	rateLimitPool := redisPool(cfg.String("RATE_LIMIT_REDIS_URL"))
+	addrLimiter, userLimiter, err := rateLimiters(cfg, rateLimitPool)
+	if err != nil {
+		return fmt.Errorf("error configuring rate limits: %v", err)
+	}
+	opts = append(opts, services.WithRateLimit(addrLimiter, userLimiter))
+
+	// The HTTP API is served at HTTP_ADDR, over TLS once HTTP_TLS_CERT and HTTP_TLS_KEY are set
+	httpAddr := cfg.String("HTTP_ADDR")
+	if httpTLS, err := tlsConfig(cfg, "HTTP"); err != nil {
+		return fmt.Errorf("error configuring http tls: %v", err)
+	} else if httpTLS != nil {
+		opts = append(opts, services.WithTLS(httpTLS))
+	}
+	// Probes, metrics, profiles and the admin API are served at ADMIN_ADDR instead once it's set, over TLS once
+	// ADMIN_TLS_CERT and ADMIN_TLS_KEY are set, so that they're never exposed at the public port
+	if addr := cfg.String("ADMIN_ADDR"); addr != "" {
+		adminTLS, err := tlsConfig(cfg, "ADMIN")
+		if err != nil {
+			return fmt.Errorf("error configuring admin tls: %v", err)
+		}
+		opts = append(opts, services.WithAdminServer(addr, adminTLS))
+	}
+	// The gRPC API is served at GRPC_ADDR too, over TLS once GRPC_TLS_CERT and GRPC_TLS_KEY are set.
+	// It's served by the HTTP server when GRPC_ADDR is HTTP_ADDR
+	if addr := cfg.String("GRPC_ADDR"); addr != "" {
+		grpcTLS, err := tlsConfig(cfg, "GRPC")
+		if err != nil {
+			return fmt.Errorf("error configuring grpc tls: %v", err)
+		}
+		opts = append(opts, services.WithGRPC(addr, grpcTLS))
+	}
+	// Profiles and runtime variables are served at DEBUG_ADDR, e.g. localhost:6060, it's off by default
+	if addr := cfg.String("DEBUG_ADDR"); addr != "" {
+		opts = append(opts, services.WithDebugServer(addr))
+	}
+	// Every request is written to ACCESS_LOG_FILE as a line of ACCESS_LOG_FORMAT (combined, common or json).
+	// The file is rotated once it's ACCESS_LOG_MAX_SIZE bytes and every ACCESS_LOG_ROTATE, ACCESS_LOG_MAX_BACKUPS
+	// rotated files are kept
+	if path := cfg.String("ACCESS_LOG_FILE"); path != "" {
+		file, err := accesslog.OpenFile(accesslog.FileConfig{
+			Path:       path,
+			MaxSize:    int64(cfg.Int("ACCESS_LOG_MAX_SIZE")),
+			Interval:   cfg.Duration("ACCESS_LOG_ROTATE"),
+			MaxBackups: cfg.Int("ACCESS_LOG_MAX_BACKUPS"),
+		})
+		var accessLog *accesslog.Logger
+		if err == nil {
+			defer file.Close()
+			accessLog, err = accesslog.New(file, cfg.String("ACCESS_LOG_FORMAT"))
+		}
+		if err != nil {
+			return fmt.Errorf("error opening access log: %v", err)
+		}
+		opts = append(opts, services.WithAccessLog(accessLog))
 	}
 
 	// New togo service instance
-	s := services.NewToDoService("wqGyEBBfPK9w3Lxw", ":5050", pg)
+	s := services.NewToDoService(string(jwtKey), httpAddr, db, opts...)
 
-	// Release resources
+	// Release resources, the background jobs and db are stopped by the defers above once the servers are
 	defer func() {
 		log.Println("shutting down web app")
-		// Close http server
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		// Stop accepting requests and drain in-flight ones for SHUTDOWN_TIMEOUT,
+		// another signal meanwhile cancels those left
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Duration("SHUTDOWN_TIMEOUT"))
+		go func() {
+			select {
+			case <-interrupt:
+				log.Println("app interrupt, cancelling in-flight requests")
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
 		err := s.Shutdown(ctx)
 		cancel()
 		if err != nil {
-			log.Println(err)
-			return
+			log.Println("|――http and grpc servers were shut down before requests were done:", err)
+		} else {
+			log.Println("|――http and grpc servers were shut down")
 		}
-		log.Println("|――http server was shut down")
-
-		// Close db
-		pg.Close()
-		log.Println("|――db was shut down")
-
-		log.Println("web app was shut down ")
 	}()
 
+	// The settings marked Reloadable in internal/config, the log level, rate limits, CORS and quotas, are applied
+	// again on SIGHUP and once the config file changes, which is checked every CONFIG_WATCH
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	changes := cfg.Watch(watchCtx, cfg.Duration("CONFIG_WATCH"))
+	live := liveSettings{cfg: cfg, logLevel: logLevel, service: s, rateLimitPool: rateLimitPool, quotaStores: quotaStores}
+
 	for {
 		select {
 		case <-interrupt:
 			log.Println("app interrupt")
-			return
+			return nil
 		case err := <-s.HttpServerErr():
-			log.Println("ERR:", err.Error())
-			return
+			return fmt.Errorf("error serving: %v", err)
+		case <-hangup:
+			live.reload()
+		case <-changes:
+			live.reload()
+		}
+	}
+}
+
+// liveSettings applies the Reloadable settings of cfg to what uses them while the app runs
+type liveSettings struct {
+	cfg           *config.Config
+	logLevel      *logging.Level
+	service       *services.ToDoService
+	rateLimitPool *redis.Pool
+	quotaStores   []storages.Store
+}
+
+// reload reads the config file again and applies the Reloadable settings which changed, a file which isn't
+// valid is ignored. Changes of the other settings are only logged as they take effect on restart
+func (l *liveSettings) reload() {
+	reloaded, restart, err := l.cfg.Reload()
+	if err != nil {
+		zap.L().Error("error reloading config, settings are kept", zap.Error(err))
+		return
+	}
+	if len(restart) > 0 {
+		zap.L().Warn("config changes take effect on restart", zap.Strings("settings", restart))
+	}
+	if len(reloaded) == 0 {
+		return
+	}
+
+	if changedAny(reloaded, "LOG_LEVEL") {
+		if err := l.logLevel.UnmarshalText([]byte(l.cfg.String("LOG_LEVEL"))); err != nil {
+			zap.L().Error("error reloading LOG_LEVEL, the level is kept", zap.Error(err))
+		}
+	}
+	if changedAny(reloaded, "CORS_") {
+		l.service.SetCORS(corsPolicy(l.cfg))
+	}
+	// limiters kept in memory start over with full buckets
+	if changedAny(reloaded, "RATE_LIMIT_") {
+		addrLimiter, userLimiter, err := rateLimiters(l.cfg, l.rateLimitPool)
+		if err != nil {
+			zap.L().Error("error reloading rate limits, the limits are kept", zap.Error(err))
+		} else {
+			l.service.SetRateLimit(addrLimiter, userLimiter)
+		}
+	}
+	if changedAny(reloaded, "QUOTA_") {
+		if err := setQuota(l.cfg, l.quotaStores...); err != nil {
+			zap.L().Error("error reloading quota", zap.Error(err))
+		}
+	}
+	zap.L().Info("config reloaded", zap.Strings("settings", reloaded))
+}
+
+// changedAny reports whether any of the names starts with one of prefixes
+func changedAny(names []string, prefixes ...string) bool {
+	for _, name := range names {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// corsPolicy returns the CORS policy of the CORS_* settings
+func corsPolicy(cfg *config.Config) services.CORS {
+	return services.CORS{
+		AllowedOrigins:   cfg.List("CORS_ALLOWED_ORIGINS"),
+		AllowedMethods:   cfg.List("CORS_ALLOWED_METHODS"),
+		AllowedHeaders:   cfg.List("CORS_ALLOWED_HEADERS"),
+		ExposedHeaders:   cfg.List("CORS_EXPOSED_HEADERS"),
+		AllowCredentials: cfg.Bool("CORS_ALLOW_CREDENTIALS"),
+		MaxAge:           cfg.Duration("CORS_MAX_AGE"),
+	}
+}
+
+// setupReporting returns the reporter of the SENTRY_* settings, nil if SENTRY_DSN isn't set. The DSN is a
+// secret, see secrets.Lookup
+func setupReporting(ctx context.Context, cfg *config.Config) (reporting.Reporter, error) {
+	dsn, err := cfg.Secret(ctx, "SENTRY_DSN")
+	if err != nil || dsn == "" {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	release := cfg.String("SENTRY_RELEASE")
+	if release == "" {
+		release = version.Get().Version
+	}
+	sentry, err := reporting.NewSentry(reporting.SentryConfig{
+		DSN:         dsn,
+		Release:     release,
+		Environment: cfg.String("SENTRY_ENVIRONMENT"),
+		ServerName:  hostname,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sentry, nil
+}
+
+// setupTracing sets up tracing by the OTEL_* settings
+func setupTracing(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	headers, err := tracing.ParseHeaders(cfg.String("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, err
+	}
+	return tracing.Setup(ctx, tracing.Config{
+		Endpoint:       cfg.String("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		Protocol:       cfg.String("OTEL_EXPORTER_OTLP_PROTOCOL"),
+		Insecure:       cfg.Bool("OTEL_EXPORTER_OTLP_INSECURE"),
+		Headers:        headers,
+		ServiceName:    cfg.String("OTEL_SERVICE_NAME"),
+		ServiceVersion: version.Get().Version,
+		SampleRatio:    cfg.Float("OTEL_TRACES_SAMPLER_ARG"),
+	})
+}
+
+// storageConfig reads storage config from cfg, Postgres dsn is built from
+// POSTGRES_* settings unless STORAGE_DSN is given. Both are secrets, see secrets.Lookup
+func storageConfig(ctx context.Context, cfg *config.Config) (*storages.Config, error) {
+	dsn, err := cfg.Secret(ctx, "STORAGE_DSN")
+	if err != nil {
+		return nil, err
+	}
+	config := &storages.Config{
+		Driver: cfg.String("STORAGE_DRIVER"),
+		DSN:    dsn,
+	}
+
+	if config.Driver == "postgres" && config.DSN == "" {
+		pwd, err := cfg.Secret(ctx, "POSTGRES_PASSWORD")
+		if err != nil {
+			return nil, err
+		}
+		pgConfig := &postgres.Config{
+			Host: cfg.String("POSTGRES_HOST"),
+			Port: cfg.String("POSTGRES_PORT"),
+			Usr:  cfg.String("POSTGRES_USER"),
+			Pwd:  pwd,
+			Db:   cfg.String("POSTGRES_DB"),
+
+			MaxConns:          int32(cfg.Int("POSTGRES_MAX_CONNS")),
+			MinConns:          int32(cfg.Int("POSTGRES_MIN_CONNS")),
+			MaxConnLifetime:   cfg.Duration("POSTGRES_MAX_CONN_LIFETIME"),
+			MaxConnIdleTime:   cfg.Duration("POSTGRES_MAX_CONN_IDLE_TIME"),
+			HealthCheckPeriod: cfg.Duration("POSTGRES_HEALTH_CHECK_PERIOD"),
+			StatementTimeout:  cfg.Duration("POSTGRES_STATEMENT_TIMEOUT"),
+			OpTimeout:         cfg.Duration("POSTGRES_OP_TIMEOUT"),
+			MaxRetries:        cfg.Int("POSTGRES_MAX_RETRIES"),
+			SlowQuery:         cfg.Duration("POSTGRES_SLOW_QUERY"),
+			RecreateAfter:     cfg.Int("POSTGRES_RECREATE_AFTER"),
+			ConnectWait:       cfg.Duration("POSTGRES_CONNECT_WAIT"),
+
+			StatementCacheMode:     cfg.String("POSTGRES_STATEMENT_CACHE_MODE"),
+			StatementCacheCapacity: cfg.Int("POSTGRES_STATEMENT_CACHE_CAPACITY"),
+
+			SSLMode:     cfg.String("POSTGRES_SSLMODE"),
+			SSLRootCert: cfg.String("POSTGRES_SSLROOTCERT"),
+			SSLCert:     cfg.String("POSTGRES_SSLCERT"),
+			SSLKey:      cfg.String("POSTGRES_SSLKEY"),
+
+			Replicas: cfg.List("POSTGRES_REPLICAS"),
+		}
+		config.DSN = pgConfig.ConnString()
+	}
+
+	return config, nil
+}
+
+// tokenSigner returns the signer of JWT_ALG, keyed by the JSON list of tokens.SigningKey of JWT_KEYS when it's set
+// or else by key
+func tokenSigner(ctx context.Context, cfg *config.Config, key []byte) (*tokens.Signer, error) {
+	alg, ttl := cfg.String("JWT_ALG"), cfg.Duration("JWT_TTL")
+	keys, err := cfg.Secret(ctx, "JWT_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	if keys == "" {
+		return tokens.NewSigner(alg, key, ttl)
+	}
+
+	var signingKeys []tokens.SigningKey
+	if err := json.Unmarshal([]byte(keys), &signingKeys); err != nil {
+		return nil, errors.Wrap(err, "JWT_KEYS")
+	}
+	return tokens.NewKeyedSigner(alg, signingKeys, ttl)
+}
+
+// tlsConfig returns the TLS config of the server of prefix, such as GRPC, of the files of GRPC_TLS_CERT and
+// GRPC_TLS_KEY, nil if they are not set. Clients must present a certificate signed by the CAs of
+// GRPC_TLS_CLIENT_CA when it's set
+func tlsConfig(cfg *config.Config, prefix string) (*tls.Config, error) {
+	certFile, keyFile := cfg.String(prefix+"_TLS_CERT"), cfg.String(prefix+"_TLS_KEY")
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "LoadX509KeyPair()")
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if caFile := cfg.String(prefix + "_TLS_CLIENT_CA"); caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "ReadFile()")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificate in %s", caFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// identityProviders configures the providers of names from <NAME>_CLIENT_ID, <NAME>_CLIENT_SECRET and
+// <NAME>_ISSUER, github is the only one without an issuer and google's is known. Providers redirect
+// back to AUTH_CALLBACK_URL followed by /auth/{name}/callback
+func identityProviders(ctx context.Context, cfg *config.Config, names []string) (map[string]oidc.Provider, error) {
+	callbackURL := strings.TrimSuffix(cfg.String("AUTH_CALLBACK_URL"), "/")
+	providers := make(map[string]oidc.Provider, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		prefix := strings.ToUpper(name) + "_"
+		clientSecret, err := cfg.Secret(ctx, prefix+"CLIENT_SECRET")
+		if err != nil {
+			return nil, err
+		}
+		config := &oidc.Config{
+			ClientID:     cfg.String(prefix + "CLIENT_ID"),
+			ClientSecret: clientSecret,
+			RedirectURL:  callbackURL + "/auth/" + name + "/callback",
+		}
+		if name == "github" {
+			providers[name] = oidc.NewGitHub(config)
+			continue
+		}
+
+		issuer := cfg.String(prefix + "ISSUER")
+		if issuer == "" && name == "google" {
+			issuer = oidc.GoogleIssuer
+		}
+		if issuer == "" {
+			return nil, errors.Errorf("identity provider %q has no issuer", name)
+		}
+		provider, err := oidc.NewOpenID(ctx, issuer, config)
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		providers[name] = provider
+	}
+	return providers, nil
+}
+
+// runMigration runs migration command in standalone mode
+func runMigration(ctx context.Context, config *storages.Config, cmd string) error {
+	var dialect postgres.Dialect
+	switch config.Driver {
+	case "postgres":
+		dialect = postgres.DialectPostgres
+	case "cockroach":
+		dialect = postgres.DialectCockroach
+	default:
+		return fmt.Errorf("driver %q does not support migrations", config.Driver)
+	}
+
+	migrator, closeDb, err := postgres.OpenMigrator(ctx, config.DSN, dialect)
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("applied %d %s", m.Version, m.Name)
+		}
+		return err
+	case "down":
+		m, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("rolled back %d %s", m.Version, m.Name)
+		return nil
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			log.Printf("%d %s %s", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migration command %q", cmd)
+	}
+}
+
+// seedFixtures seeds db with fixtures loaded from path
+func seedFixtures(ctx context.Context, db storages.Store, path string) error {
+	seeder, ok := db.(storages.Seeder)
+	if !ok {
+		return fmt.Errorf("storage does not support seeding")
+	}
+
+	fixtures, err := storages.LoadFixturesFile(path)
+	if err != nil {
+		return err
+	}
+	return seeder.Seed(ctx, fixtures)
+}
+
+// setQuota makes stores count tasks by QUOTA_POLICY and let users go over their limits by QUOTA_BURST
+func setQuota(cfg *config.Config, stores ...storages.Store) error {
+	policy, err := storages.ParseQuotaPolicy(cfg.String("QUOTA_POLICY"))
+	if err != nil {
+		return errors.Wrap(err, "QUOTA_POLICY")
+	}
+	burst, err := storages.ParseBurst(cfg.String("QUOTA_BURST"))
+	if err != nil {
+		return errors.Wrap(err, "QUOTA_BURST")
+	}
+	for _, db := range stores {
+		if err := setQuotaPolicy(db, policy); err != nil {
+			return err
+		}
+		if err := setBurst(db, burst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setQuotaPolicy makes db count tasks by policy, storages which can't only count them by calendar day
+func setQuotaPolicy(db storages.Store, policy storages.QuotaPolicy) error {
+	if setter, ok := db.(storages.QuotaPolicySetter); ok {
+		setter.SetQuotaPolicy(policy)
+		return nil
+	}
+	if _, ok := policy.(storages.DailyQuota); !ok {
+		return errors.New("storage only supports the daily quota policy")
+	}
+	return nil
+}
+
+// redisPool returns the pool of the Redis at url, nil if url is empty
+func redisPool(url string) *redis.Pool {
+	if url == "" {
+		return nil
+	}
+	return &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 5 * time.Minute,
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialURL(url)
+		},
+	}
+}
+
+// rateLimiters returns the limiters of requests by the rates of RATE_LIMIT_ADDR and RATE_LIMIT_USER, either is
+// nil, unlimited, when empty. Buckets are kept in the Redis of pool unless it's nil
+func rateLimiters(cfg *config.Config, pool *redis.Pool) (ratelimit.Limiter, ratelimit.Limiter, error) {
+	limiter := func(s string) (ratelimit.Limiter, error) {
+		if s == "" {
+			return nil, nil
+		}
+		rate, err := ratelimit.ParseRate(s)
+		if err != nil {
+			return nil, err
+		}
+		if pool != nil {
+			return ratelimit.NewRedisLimiter(pool, "togo:ratelimit:", rate), nil
+		}
+		return ratelimit.NewMemoryLimiter(rate), nil
+	}
+
+	addrLimiter, err := limiter(cfg.String("RATE_LIMIT_ADDR"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "RATE_LIMIT_ADDR")
+	}
+	userLimiter, err := limiter(cfg.String("RATE_LIMIT_USER"))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "RATE_LIMIT_USER")
+	}
+	return addrLimiter, userLimiter, nil
+}
+
+// setBurst lets users of db add tasks over their limits by burst, storages which can't don't allow any burst
+func setBurst(db storages.Store, burst storages.Burst) error {
+	if setter, ok := db.(storages.BurstSetter); ok {
+		setter.SetBurst(burst)
+		return nil
+	}
+	if burst.Extra > 0 {
+		return errors.New("storage doesn't support quota bursts")
+	}
+	return nil
+}
+
+// parseRouteTimeouts returns the options of route timeouts such as "/tasks:batch=1m" and "/graphql=10s"
+func parseRouteTimeouts(items []string) ([]services.Option, error) {
+	var opts []services.Option
+	for _, item := range items {
+		i := strings.LastIndex(item, "=")
+		if i < 0 {
+			return nil, errors.Errorf("%q is not pattern=timeout", item)
+		}
+		timeout, err := time.ParseDuration(item[i+1:])
+		if err != nil {
+			return nil, errors.Wrap(err, item)
 		}
+		opts = append(opts, services.WithRouteTimeout(strings.TrimSpace(item[:i]), timeout))
 	}
+	return opts, nil
 }